@@ -0,0 +1,169 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// An AbsoluteRange is a single concrete period of time between two RFC 3339 timestamps, e.g. a four hour migration
+// window. It contains its Start but not its End.
+type AbsoluteRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+type yamlAbsoluteRange struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for AbsoluteRange.
+func (r *AbsoluteRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var y yamlAbsoluteRange
+	if err := unmarshal(&y); err != nil {
+		return err
+	}
+	if y.Start == "" || y.End == "" {
+		return errors.New("Absolute ranges require both a start and an end")
+	}
+	start, err := time.Parse(time.RFC3339, y.Start)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid RFC 3339 timestamp", y.Start)
+	}
+	end, err := time.Parse(time.RFC3339, y.End)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid RFC 3339 timestamp", y.End)
+	}
+	if !start.Before(end) {
+		return errors.New("Start timestamp must be before End timestamp")
+	}
+	r.Start, r.End = start, end
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for AbsoluteRange
+func (r AbsoluteRange) MarshalYAML() (interface{}, error) {
+	return yamlAbsoluteRange{Start: r.Start.Format(time.RFC3339), End: r.End.Format(time.RFC3339)}, nil
+}
+
+// endOfTime ends absolute ranges that would otherwise be open ended, as RFC 3339 timestamps can't go beyond the year
+// 9999.
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// absoluteRanges is a list of absolute ranges acting as a TransitionMatcher, so that transitions of an interval with
+// absolute ranges can be found from those of the rest of the interval and those of the ranges.
+type absoluteRanges []AbsoluteRange
+
+// ContainsTime returns true if any of the ranges contain the given time.
+func (rs absoluteRanges) ContainsTime(t time.Time) bool {
+	for _, r := range rs {
+		if !t.Before(r.Start) && t.Before(r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextTransition returns the earliest start or end of a range after t at which the ranges change state.
+func (rs absoluteRanges) NextTransition(t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, r := range rs {
+		for _, b := range []time.Time{r.Start, r.End} {
+			if b.After(t) && (!found || b.Before(next)) && isTransition(rs, b) {
+				next, found = b, true
+			}
+		}
+	}
+	if !found {
+		return time.Time{}, false
+	}
+	return next.In(t.Location()), true
+}
+
+// PreviousTransition returns the latest start or end of a range at or before t at which the ranges change state.
+func (rs absoluteRanges) PreviousTransition(t time.Time) (time.Time, bool) {
+	var prev time.Time
+	found := false
+	for _, r := range rs {
+		for _, b := range []time.Time{r.Start, r.End} {
+			if !b.After(t) && (!found || b.After(prev)) && isTransition(rs, b) {
+				prev, found = b, true
+			}
+		}
+	}
+	if !found {
+		return time.Time{}, false
+	}
+	return prev.In(t.Location()), true
+}
+
+// bounds returns the earliest start and the latest end of the ranges.
+func (rs absoluteRanges) bounds() (first, last time.Time) {
+	for i, r := range rs {
+		if i == 0 || r.Start.Before(first) {
+			first = r.Start
+		}
+		if i == 0 || r.End.After(last) {
+			last = r.End
+		}
+	}
+	return first, last
+}
+
+// clip returns the parts of the window that fall within the ranges, in chronological order.
+func (rs absoluteRanges) clip(w Window) []Window {
+	var parts []Window
+	for _, r := range rs {
+		part := clipWindow(w, r.Start, r.End)
+		if part.Start.Before(part.End) {
+			parts = append(parts, part)
+		}
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Start.Before(parts[j].Start) })
+	merged := parts[:0]
+	for _, p := range parts {
+		if n := len(merged); n > 0 && !p.Start.After(merged[n-1].End) {
+			if p.End.After(merged[n-1].End) {
+				merged[n-1].End = p.End
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// withoutAbsolute returns the interval with its absolute ranges removed.
+func (tp TimeInterval) withoutAbsolute() TimeInterval {
+	tp.Absolute = nil
+	return tp
+}
+
+// intersectAbsoluteRanges returns the periods covered by both lists, or whichever list is given if the other is nil.
+func intersectAbsoluteRanges(a, b []AbsoluteRange) []AbsoluteRange {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	out := []AbsoluteRange{}
+	for _, ra := range a {
+		for _, rb := range b {
+			start, end := ra.Start, ra.End
+			if rb.Start.After(start) {
+				start = rb.Start
+			}
+			if rb.End.Before(end) {
+				end = rb.End
+			}
+			if start.Before(end) {
+				out = append(out, AbsoluteRange{Start: start, End: end})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var absoluteTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// A four hour migration
+		in: "absolute: [{start: '2024-06-01T22:00:00Z', end: '2024-06-02T02:00:00Z'}]",
+		interval: TimeInterval{
+			Absolute: []AbsoluteRange{{Start: mustParseTime("2024-06-01T22:00:00Z"), End: mustParseTime("2024-06-02T02:00:00Z")}},
+		},
+		contains: []string{"2024-06-01T22:00:00Z", "2024-06-02T01:59:59Z", "2024-06-02T09:30:00+10:00"},
+		excludes: []string{"2024-06-01T21:59:59Z", "2024-06-02T02:00:00Z", "2025-06-01T23:00:00Z"},
+	},
+	{
+		// Only the weekday business hours within the range
+		in: `
+weekdays: ['monday:friday']
+times:
+  - start_time: '09:00'
+    end_time: '17:00'
+absolute:
+  - start: '2024-03-06T12:00:00Z'
+    end: '2024-03-11T12:00:00Z'`,
+		interval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Absolute: []AbsoluteRange{{Start: mustParseTime("2024-03-06T12:00:00Z"), End: mustParseTime("2024-03-11T12:00:00Z")}},
+		},
+		contains: []string{"2024-03-06T12:00:00Z", "2024-03-08T16:00:00Z", "2024-03-11T11:59:59Z"},
+		excludes: []string{"2024-03-06T11:59:59Z", "2024-03-09T12:00:00Z", "2024-03-11T12:00:00Z", "2024-03-13T12:00:00Z"},
+	},
+	{
+		in:          "absolute: [{start: '2024-06-02T02:00:00Z', end: '2024-06-01T22:00:00Z'}]",
+		expectError: true,
+	},
+	{
+		in:          "absolute: [{start: '2024-06-01 22:00', end: '2024-06-02T02:00:00Z'}]",
+		expectError: true,
+	},
+	{
+		in:          "absolute: [{start: '2024-06-01T22:00:00Z'}]",
+		expectError: true,
+	},
+}
+
+func TestAbsolute(t *testing.T) {
+	for _, tc := range absoluteTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestAbsoluteWindows(t *testing.T) {
+	migration := TimeInterval{
+		Absolute: []AbsoluteRange{{Start: mustParseTime("2024-06-01T22:00:00Z"), End: mustParseTime("2024-06-02T02:00:00Z")}},
+	}
+	got := migration.Windows(mustParseTime("2024-01-01T00:00:00Z"), mustParseTime("2025-01-01T00:00:00Z"))
+	want := []Window{{Start: mustParseTime("2024-06-01T22:00:00Z"), End: mustParseTime("2024-06-02T02:00:00Z")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows of the migration: want %v, got %v", want, got)
+	}
+	next, ok := migration.NextTransition(mustParseTime("2024-06-01T23:00:00Z"))
+	checkTransition(t, "migration", migration, "2024-06-02T02:00:00Z", next, ok)
+	prev, ok := migration.PreviousTransition(mustParseTime("2024-07-01T00:00:00Z"))
+	checkTransition(t, "migration", migration, "2024-06-02T02:00:00Z", prev, ok)
+	if _, ok := migration.NextTransition(mustParseTime("2024-06-02T02:00:00Z")); ok {
+		t.Errorf("Expected no transition after the migration")
+	}
+	if migration.IsEmpty() || migration.IsAlwaysActive() {
+		t.Errorf("Expected the migration to be neither empty nor always active")
+	}
+	weekend := TimeInterval{
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}},
+		Absolute: []AbsoluteRange{{Start: mustParseTime("2024-03-04T00:00:00Z"), End: mustParseTime("2024-03-09T00:00:00Z")}},
+	}
+	if !weekend.IsEmpty() {
+		t.Errorf("Expected Saturdays within a Monday to Friday range to be empty")
+	}
+	evening := TimeInterval{Times: []TimeRange{{StartMinute: 1200, EndMinute: 1440}}}
+	both, err := Intersect(migration, evening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = both.Windows(mustParseTime("2024-01-01T00:00:00Z"), mustParseTime("2025-01-01T00:00:00Z"))
+	want = []Window{{Start: mustParseTime("2024-06-01T22:00:00Z"), End: mustParseTime("2024-06-02T00:00:00Z")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows of the migration in the evening: want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,125 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// An AlertmanagerTimeInterval is an entry of the time_intervals, or older mute_time_intervals, section of an
+// Alertmanager configuration file. It names a list of intervals, and a time is in it if any of them contain it.
+type AlertmanagerTimeInterval struct {
+	Name          string      `yaml:"name"`
+	TimeIntervals IntervalSet `yaml:"time_intervals"`
+}
+
+// AlertmanagerConfig holds the sections of an Alertmanager configuration file that define time intervals, so that
+// schedules can be shared between Alertmanager and gotime. The rest of the file is ignored when it is unmarshalled,
+// and every field Alertmanager allows in an interval has the same name and meaning in gotime.
+type AlertmanagerConfig struct {
+	MuteTimeIntervals []AlertmanagerTimeInterval `yaml:"mute_time_intervals,omitempty"`
+	TimeIntervals     []AlertmanagerTimeInterval `yaml:"time_intervals,omitempty"`
+}
+
+// Definitions returns the named intervals of both sections of the configuration. As in Alertmanager, a name may only
+// be used once across both sections.
+func (c AlertmanagerConfig) Definitions() (Definitions, error) {
+	d := make(Definitions, len(c.MuteTimeIntervals)+len(c.TimeIntervals))
+	for _, named := range append(append([]AlertmanagerTimeInterval{}, c.MuteTimeIntervals...), c.TimeIntervals...) {
+		if named.Name == "" {
+			return nil, errors.New("Alertmanager time intervals must have a name")
+		}
+		if _, ok := d[named.Name]; ok {
+			return nil, fmt.Errorf("Alertmanager time interval %s is defined more than once", named.Name)
+		}
+		d[named.Name] = named.TimeIntervals
+	}
+	return d, nil
+}
+
+// NewAlertmanagerConfig returns the time_intervals section of an Alertmanager configuration file holding each of the
+// definitions, in order of name. The names, descriptions and labels of the intervals are left out, since they don't
+// affect which times they contain and Alertmanager doesn't allow them. An error is returned if any interval uses
+// fields that Alertmanager doesn't have, such as except, or has times that Alertmanager can't express, such as
+// those past midnight or with seconds.
+func NewAlertmanagerConfig(d Definitions) (AlertmanagerConfig, error) {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var c AlertmanagerConfig
+	for _, name := range names {
+		set := make(IntervalSet, len(d[name]))
+		for i, ti := range d[name] {
+			if err := ti.alertmanagerCompatible(); err != nil {
+				return AlertmanagerConfig{}, fmt.Errorf("Definition %s: %v", name, err)
+			}
+			ti.Name, ti.Description, ti.Labels = "", "", nil
+			ti.Weekdays = alertmanagerWeekdays(ti.Weekdays)
+			set[i] = ti
+		}
+		c.TimeIntervals = append(c.TimeIntervals, AlertmanagerTimeInterval{Name: name, TimeIntervals: set})
+	}
+	return c, nil
+}
+
+// alertmanagerCompatible returns an error if the interval can't be written in an Alertmanager configuration file.
+func (tp TimeInterval) alertmanagerCompatible() error {
+	for _, f := range []struct {
+		key   string
+		unset bool
+	}{
+		{"mode", tp.Mode == ModeAllow},
+		{"weekdays of the month", tp.WeekdaysOfMonth == nil},
+		{"weeks", tp.Weeks == nil},
+		{"quarters", tp.Quarters == nil},
+		{"relative days", tp.Relative == nil},
+		{"dates", tp.Dates == nil},
+		{"absolute times", tp.Absolute == nil},
+		{"fiscal year", tp.FiscalYearStart == 0},
+		{"cycle", tp.Cycle == nil},
+		{"calendar", tp.Calendar == nil},
+		{"holidays", tp.Holidays == nil},
+		{"expression", tp.Expression == nil},
+		{"coordinates", tp.Coordinates == nil},
+		{"dst", tp.DST == DSTPolicy{}},
+		{"except", tp.Except == nil},
+	} {
+		if !f.unset {
+			return fmt.Errorf("Unable to express the %s of an interval in Alertmanager", f.key)
+		}
+	}
+	for _, tr := range tp.Times {
+		switch {
+		case tr.StartSolar != nil || tr.EndSolar != nil:
+			return errors.New("Unable to express solar times in Alertmanager")
+		case tr.hasSeconds():
+			return errors.New("Unable to express times with seconds in Alertmanager")
+		case tr.StartMinute >= tr.EndMinute:
+			return errors.New("Unable to express times past midnight in Alertmanager")
+		}
+	}
+	return nil
+}
+
+// alertmanagerWeekdays returns the weekday ranges with any that end on ISO Sunday split in two, as Alertmanager only
+// allows days of the week to be given by name.
+func alertmanagerWeekdays(ranges []WeekdayRange) []WeekdayRange {
+	if ranges == nil {
+		return nil
+	}
+	out := make([]WeekdayRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.End != 7 {
+			out = append(out, r)
+			continue
+		}
+		if r.Begin <= int(time.Saturday) {
+			out = append(out, WeekdayRange{InclusiveRange{Begin: r.Begin, End: int(time.Saturday)}})
+		}
+		out = append(out, WeekdayRange{InclusiveRange{Begin: int(time.Sunday), End: int(time.Sunday)}})
+	}
+	return out
+}
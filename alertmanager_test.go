@@ -0,0 +1,152 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const alertmanagerTestConfig = `
+route:
+  receiver: default
+  routes:
+    - matchers: ['severity="warning"']
+      mute_time_intervals: [out_of_hours]
+      active_time_intervals: [business_hours]
+receivers:
+  - name: default
+mute_time_intervals:
+  - name: out_of_hours
+    time_intervals:
+      - weekdays: ['saturday', 'sunday']
+      - times:
+          - start_time: '00:00'
+            end_time: '09:00'
+          - start_time: '17:00'
+            end_time: '24:00'
+time_intervals:
+  - name: business_hours
+    time_intervals:
+      - times:
+          - start_time: '09:00'
+            end_time: '17:00'
+        weekdays: ['monday:friday']
+        days_of_month: ['1:-2']
+        months: ['january:november']
+        years: ['2024:2026']
+        location: 'Australia/Melbourne'
+`
+
+func TestAlertmanagerConfig(t *testing.T) {
+	var c AlertmanagerConfig
+	if err := yaml.Unmarshal([]byte(alertmanagerTestConfig), &c); err != nil {
+		t.Fatal(err)
+	}
+	defs, err := c.Definitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		time     string
+		contains bool
+	}{
+		{"out_of_hours", "2024-07-06T12:00:00Z", true},
+		{"out_of_hours", "2024-07-05T08:59:00Z", true},
+		{"out_of_hours", "2024-07-05T12:00:00Z", false},
+		// 10AM on a Friday in Melbourne.
+		{"business_hours", "2024-07-05T00:00:00Z", true},
+		{"business_hours", "2024-07-05T08:00:00Z", false},
+		{"business_hours", "2024-07-30T00:00:00Z", true},
+		{"business_hours", "2024-07-31T00:00:00Z", false},
+		{"business_hours", "2024-12-03T00:00:00Z", false},
+	} {
+		tm, _ := time.Parse(time.RFC3339, tc.time)
+		if defs[tc.name].ContainsTime(tm) != tc.contains {
+			t.Errorf("Expected %s ContainsTime(%s) to be %v", tc.name, tc.time, tc.contains)
+		}
+	}
+	out, err := NewAlertmanagerConfig(defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := yaml.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `time_intervals:
+- name: business_hours
+  time_intervals:
+  - times:
+    - start_time: "09:00"
+      end_time: "17:00"
+    weekdays: ['monday:friday']
+    days_of_month: ['1:-2']
+    months: ['january:november']
+    years: ['2024:2026']
+    location: Australia/Melbourne
+- name: out_of_hours
+  time_intervals:
+  - weekdays: [saturday, sunday]
+  - times:
+    - start_time: "00:00"
+      end_time: "09:00"
+    - start_time: "17:00"
+      end_time: "24:00"
+`
+	if string(b) != want {
+		t.Errorf("Marshalling gave\n%s\nexpected\n%s", b, want)
+	}
+}
+
+func TestAlertmanagerConfigDuplicateNames(t *testing.T) {
+	c := AlertmanagerConfig{
+		MuteTimeIntervals: []AlertmanagerTimeInterval{{Name: "weekends"}},
+		TimeIntervals:     []AlertmanagerTimeInterval{{Name: "weekends"}},
+	}
+	if _, err := c.Definitions(); err == nil {
+		t.Errorf("Expected an error for a name used in both sections")
+	}
+	if _, err := (AlertmanagerConfig{TimeIntervals: []AlertmanagerTimeInterval{{}}}).Definitions(); err == nil {
+		t.Errorf("Expected an error for an interval without a name")
+	}
+}
+
+func TestNewAlertmanagerConfigIncompatible(t *testing.T) {
+	for _, ti := range []TimeInterval{
+		{Mode: ModeDeny},
+		{Except: []TimeInterval{{}}},
+		{WeekdaysOfMonth: []WeekdayOfMonth{{N: 1, Weekday: time.Monday}}},
+		{Times: []TimeRange{{StartMinute: 1320, EndMinute: 360}}},
+		{Times: []TimeRange{{StartMinute: 540, StartSecond: 30, EndMinute: 600}}},
+	} {
+		if _, err := NewAlertmanagerConfig(Definitions{"a": {ti}}); err == nil {
+			t.Errorf("Expected an error converting %v", ti)
+		}
+	}
+	described := TimeInterval{Name: "a", Description: "Described", Labels: map[string]string{"k": "v"}}
+	c, err := NewAlertmanagerConfig(Definitions{"a": {described}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti := c.TimeIntervals[0].TimeIntervals[0]; ti.Name != "" || ti.Description != "" || ti.Labels != nil {
+		t.Errorf("Expected the name, description and labels to be left out, got %v", ti)
+	}
+}
+
+func TestNewAlertmanagerConfigSundays(t *testing.T) {
+	c, err := NewAlertmanagerConfig(Definitions{"a": {{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 7}}}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}, {InclusiveRange{Begin: 0, End: 0}}}
+	got := c.TimeIntervals[0].TimeIntervals[0].Weekdays
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Expected weekdays %v, got %v", want, got)
+	}
+	sunday := time.Date(2024, 7, 7, 12, 0, 0, 0, time.UTC)
+	if !c.TimeIntervals[0].TimeIntervals.ContainsTime(sunday) {
+		t.Errorf("Expected %v to contain Sunday", got)
+	}
+}
@@ -0,0 +1,239 @@
+package gotime
+
+import (
+	"sort"
+	"time"
+)
+
+// The Gregorian calendar repeats every 400 years, including the days of the week, so any day-level pattern
+// that holds for some year also holds for the year a multiple of 400 years away.
+const gregorianCycleYears = 400
+
+// IsAlwaysActive returns true if the TimeInterval matches every point in time. An empty TimeInterval is always active,
+// as is one whose every field covers the entirety of its domain (e.g. weekdays ['sunday:saturday']).
+func (tp TimeInterval) IsAlwaysActive() bool {
+	for _, ex := range tp.Except {
+		if !ex.IsEmpty() {
+			return false
+		}
+	}
+	if tp.Years != nil || tp.Dates != nil || tp.Absolute != nil || tp.Cycle != nil || tp.Holidays != nil ||
+		tp.Relative != nil || tp.Calendar != nil || tp.Expression != nil {
+		// A finite list of year ranges can never cover all of time, nor can a cycle that starts on some date, a
+		// calendar of holidays, days around a feast or dates in another calendar. Expressions are assumed to be false
+		// some of the time.
+		return false
+	}
+	if tp.hasSolarTimes() {
+		// Daylight changes in length over the year, so can't cover every day.
+		return false
+	}
+	for _, second := range tp.timeBoundaries() {
+		if !tp.containsSecond(second) && !tp.containsCarriedSecond(second) {
+			return false
+		}
+	}
+	// Each day-level field is independent of the others, so the interval covers every day only if each field
+	// does so on its own.
+	weekdays := TimeInterval{Weekdays: tp.Weekdays}
+	// 4 Jan 2015 is a Sunday, so this checks every day of the week in order.
+	for day := 4; day < 11; day++ {
+		if !weekdays.containsDay(time.Date(2015, time.January, day, 0, 0, 0, 0, time.UTC)) {
+			return false
+		}
+	}
+	for week := 1; week <= 53 && tp.Weeks != nil; week++ {
+		covered := false
+		for _, r := range tp.Weeks {
+			if week >= r.Begin && week <= r.End {
+				covered = true
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	months := TimeInterval{Months: tp.Months, Quarters: tp.Quarters, FiscalYearStart: tp.FiscalYearStart}
+	for month := time.January; month <= time.December; month++ {
+		if !months.containsDay(time.Date(2015, month, 1, 0, 0, 0, 0, time.UTC)) {
+			return false
+		}
+	}
+	daysOfMonth := TimeInterval{DaysOfMonth: tp.DaysOfMonth, WeekdaysOfMonth: tp.WeekdaysOfMonth}
+	if tp.WeekdaysOfMonth != nil {
+		// Weekdays of the month depend on how the month lines up with the week, which repeats every 28 years.
+		for day := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC); day.Year() < 2043; day = day.AddDate(0, 0, 1) {
+			if !daysOfMonth.containsDay(day) {
+				return false
+			}
+		}
+		return true
+	}
+	// Check a month of each possible length so that negative indices are resolved against every month boundary.
+	for _, monthStart := range []time.Time{
+		time.Date(2015, time.February, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2015, time.April, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		for day := monthStart; day.Month() == monthStart.Month(); day = day.AddDate(0, 0, 1) {
+			if !daysOfMonth.containsDay(day) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsEmpty returns true if the TimeInterval can never match any point in time. This is the case when a field is present
+// but contains no ranges, or when the ranges of different fields contradict each other (e.g. Saturdays within dates that
+// run from a Monday to the Friday after it).
+// An interval with exceptions is also empty if the exceptions cover every time it would otherwise match. Holidays are
+// assumed to be able to fall on any day, as are dates in other calendars, and expressions to hold at some time.
+func (tp TimeInterval) IsEmpty() bool {
+	if len(tp.Except) > 0 {
+		return tp.withoutExceptions().IsEmpty() || tp.exceptionsCoverAll()
+	}
+	// Which days are holidays isn't known in general, so assume that any day the rest of the interval allows may be one.
+	// Dates in other calendars move against the Gregorian calendar, so the same goes for them.
+	tp.Holidays = nil
+	tp.Expression = nil
+	if tp.Calendar != nil && len(tp.Calendar.Dates) == 0 {
+		return true
+	}
+	tp.Calendar = nil
+	if (tp.Times != nil && len(tp.Times) == 0) ||
+		(tp.Weekdays != nil && len(tp.Weekdays) == 0) ||
+		((tp.DaysOfMonth != nil || tp.WeekdaysOfMonth != nil) && len(tp.DaysOfMonth)+len(tp.WeekdaysOfMonth) == 0) ||
+		(tp.Weeks != nil && len(tp.Weeks) == 0) ||
+		(tp.Months != nil && len(tp.Months) == 0) ||
+		(tp.Quarters != nil && len(tp.Quarters) == 0) ||
+		(tp.Years != nil && len(tp.Years) == 0) ||
+		(tp.Relative != nil && len(tp.Relative) == 0) ||
+		(tp.Dates != nil && len(tp.Dates) == 0) ||
+		(tp.Absolute != nil && len(tp.Absolute) == 0) {
+		return true
+	}
+	if tp.Absolute != nil {
+		// The rest of the interval must match some time within the ranges, which can be searched for directly.
+		first, _ := absoluteRanges(tp.Absolute).bounds()
+		_, ok := tp.NextActiveTime(first)
+		return !ok
+	}
+	// Solar times are assumed to leave some time active on at least some days.
+	anyTime := tp.hasSolarTimes()
+	for _, second := range tp.timeBoundaries() {
+		if tp.containsSecond(second) || tp.containsCarriedSecond(second) {
+			anyTime = true
+			break
+		}
+	}
+	if !anyTime {
+		return true
+	}
+	if tp.Dates != nil {
+		return tp.datesAreEmpty()
+	}
+	if tp.Cycle != nil {
+		return tp.cycleIsEmpty()
+	}
+	for _, year := range tp.representativeYears() {
+		for month := time.January; month <= time.December; month++ {
+			for day := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC); day.Month() == month; day = day.AddDate(0, 0, 1) {
+				if tp.containsDay(day) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// representativeYears returns one year for every distinct position in the 400 year Gregorian cycle that the
+// interval's year ranges touch, with each representative mapped into the same year range it came from.
+func (tp TimeInterval) representativeYears() []int {
+	var years []int
+	seen := make(map[int]bool)
+	add := func(year int) {
+		cyclePos := ((year % gregorianCycleYears) + gregorianCycleYears) % gregorianCycleYears
+		if !seen[cyclePos] {
+			seen[cyclePos] = true
+			years = append(years, year)
+		}
+	}
+	if tp.Years == nil {
+		for year := 2000; year < 2000+gregorianCycleYears; year++ {
+			add(year)
+		}
+		return years
+	}
+	first := 0
+	if tp.fiscalStartMonth() != time.January {
+		// Fiscal years begin in the calendar year before the one they are named after.
+		first = -1
+	}
+	for _, yr := range tp.Years {
+		for year := yr.Begin + first; year <= yr.End && year < yr.Begin+gregorianCycleYears; year++ {
+			add(year)
+		}
+	}
+	return years
+}
+
+// WeeklyActiveDuration returns how long the TimeInterval is active during a canonical week, taking into account only
+// its times and weekdays. Fields tied to the calendar such as days of the month, months and years are ignored, as are
+// exceptions.
+func (tp TimeInterval) WeeklyActiveDuration() time.Duration {
+	var total time.Duration
+	for _, d := range tp.WeekdayActiveDurations() {
+		total += d
+	}
+	return total
+}
+
+// WeekdayActiveDurations returns how long the TimeInterval is active on each day of a canonical week, taking into
+// account only its times and weekdays, ignoring exceptions. Every day of the week is present in the returned map.
+// Overnight ranges count towards the day of the week that each portion of the range falls on. Solar times are
+// resolved for a week in early January.
+func (tp TimeInterval) WeekdayActiveDurations() map[time.Weekday]time.Duration {
+	weekdays := TimeInterval{Weekdays: tp.Weekdays}
+	durations := make(map[time.Weekday]time.Duration, 7)
+	// 4 Jan 2015 is a Sunday, so this visits every day of the week in order.
+	for day := 4; day < 11; day++ {
+		t := time.Date(2015, time.January, day, 0, 0, 0, 0, time.UTC)
+		matches := weekdays.containsDay(t)
+		previousMatches := weekdays.containsDay(t.AddDate(0, 0, -1))
+		today, yesterday := tp.onDay(t), tp.onDay(t.AddDate(0, 0, -1))
+		bounds := append(today.timeBoundaries(), yesterday.timeBoundaries()...)
+		sort.Ints(bounds)
+		activeSeconds := 0
+		for i, second := range bounds {
+			end := secondsPerDay
+			if i+1 < len(bounds) {
+				end = bounds[i+1]
+			}
+			if (matches && today.containsSecond(second)) || (previousMatches && yesterday.containsCarriedSecond(second)) {
+				activeSeconds += end - second
+			}
+		}
+		durations[t.Weekday()] = time.Duration(activeSeconds) * time.Second
+	}
+	return durations
+}
+
+// timeBoundaries returns the sorted seconds of the day at which the interval's time ranges start or end, beginning
+// with midnight. Whether a second of the day is covered by the ranges can only change at one of these boundaries.
+func (tp TimeInterval) timeBoundaries() []int {
+	bounds := []int{0}
+	for _, tr := range tp.Times {
+		bounds = append(bounds, tr.startSecond(), tr.endSecond())
+	}
+	sort.Ints(bounds)
+	out := bounds[:1]
+	for _, b := range bounds[1:] {
+		if b != out[len(out)-1] && b < secondsPerDay {
+			out = append(out, b)
+		}
+	}
+	return out
+}
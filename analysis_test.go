@@ -0,0 +1,226 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var intervalAnalysisTestCases = []struct {
+	timeInterval TimeInterval
+	alwaysActive bool
+	empty        bool
+}{
+	{
+		timeInterval: TimeInterval{},
+		alwaysActive: true,
+		empty:        false,
+	},
+	{
+		// Every field covers its whole domain
+		timeInterval: TimeInterval{
+			Times:       []TimeRange{{StartMinute: 0, EndMinute: 720}, {StartMinute: 720, EndMinute: 1440}},
+			Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 0, End: 6}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: -1}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 1, End: 6}}, {InclusiveRange{Begin: 7, End: 12}}},
+		},
+		alwaysActive: true,
+		empty:        false,
+	},
+	{
+		// Every ISO week
+		timeInterval: TimeInterval{Weeks: []WeekRange{{InclusiveRange{Begin: 1, End: 53}}}},
+		alwaysActive: true,
+		empty:        false,
+	},
+	{
+		timeInterval: TimeInterval{Weeks: []WeekRange{{InclusiveRange{Begin: 1, End: 4}}}},
+		alwaysActive: false,
+		empty:        false,
+	},
+	{
+		// Week 53 never falls in July
+		timeInterval: TimeInterval{
+			Weeks:  []WeekRange{{InclusiveRange{Begin: 53, End: 53}}},
+			Months: []MonthRange{{InclusiveRange{Begin: 7, End: 7}}},
+		},
+		alwaysActive: false,
+		empty:        true,
+	},
+	{
+		// 9am to 5pm, monday to friday
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+		alwaysActive: false,
+		empty:        false,
+	},
+	{
+		// Days 1 to 30 miss the 31st of long months
+		timeInterval: TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 30}}},
+		},
+		alwaysActive: false,
+		empty:        false,
+	},
+	{
+		// Years can never cover all of time
+		timeInterval: TimeInterval{
+			Years: []YearRange{{InclusiveRange{Begin: 0, End: 9999}}},
+		},
+		alwaysActive: false,
+		empty:        false,
+	},
+	{
+		// Present but empty field
+		timeInterval: emptyInterval(),
+		alwaysActive: false,
+		empty:        true,
+	},
+	{
+		// 1st of January 2021 was a Friday, not a Monday
+		timeInterval: TimeInterval{
+			Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 1}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 1, End: 1}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2021, End: 2021}}},
+		},
+		alwaysActive: false,
+		empty:        true,
+	},
+	{
+		// Start month after end month wraps the year
+		timeInterval: TimeInterval{
+			Months: []MonthRange{{InclusiveRange{Begin: 12, End: 1}}},
+		},
+		alwaysActive: false,
+		empty:        false,
+	},
+	{
+		// Months wrapping the year covering every month between them
+		timeInterval: TimeInterval{
+			Months: []MonthRange{{InclusiveRange{Begin: 7, End: 2}}, {InclusiveRange{Begin: 3, End: 6}}},
+		},
+		alwaysActive: true,
+		empty:        false,
+	},
+	{
+		// 29th of February 2024 was a Thursday
+		timeInterval: TimeInterval{
+			Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 4, End: 4}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 29, End: 29}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 2, End: 2}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2021, End: 2024}}},
+		},
+		alwaysActive: false,
+		empty:        false,
+	},
+	{
+		// 1st to 5th of January 2024 ran from Monday to Friday, so had no Saturday
+		timeInterval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}},
+			Dates: []DateRange{{
+				Begin: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		alwaysActive: false,
+		empty:        true,
+	},
+	{
+		// Start time equal to end time
+		timeInterval: TimeInterval{
+			Times: []TimeRange{{StartMinute: 540, EndMinute: 540}},
+		},
+		alwaysActive: false,
+		empty:        true,
+	},
+	{
+		// Overnight ranges covering the whole day between them
+		timeInterval: TimeInterval{
+			Times: []TimeRange{{StartMinute: 720, EndMinute: 0}, {StartMinute: 1320, EndMinute: 720}},
+		},
+		alwaysActive: true,
+		empty:        false,
+	},
+}
+
+func TestIsAlwaysActive(t *testing.T) {
+	for _, tc := range intervalAnalysisTestCases {
+		if got := tc.timeInterval.IsAlwaysActive(); got != tc.alwaysActive {
+			t.Errorf("IsAlwaysActive for %+v: want %v, got %v", tc.timeInterval, tc.alwaysActive, got)
+		}
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	for _, tc := range intervalAnalysisTestCases {
+		if got := tc.timeInterval.IsEmpty(); got != tc.empty {
+			t.Errorf("IsEmpty for %+v: want %v, got %v", tc.timeInterval, tc.empty, got)
+		}
+	}
+}
+
+var weeklyActiveDurationTestCases = []struct {
+	timeInterval TimeInterval
+	weekly       time.Duration
+	perWeekday   map[time.Weekday]time.Duration
+}{
+	{
+		timeInterval: TimeInterval{},
+		weekly:       7 * 24 * time.Hour,
+		perWeekday: map[time.Weekday]time.Duration{
+			time.Sunday: 24 * time.Hour, time.Monday: 24 * time.Hour, time.Tuesday: 24 * time.Hour, time.Wednesday: 24 * time.Hour,
+			time.Thursday: 24 * time.Hour, time.Friday: 24 * time.Hour, time.Saturday: 24 * time.Hour,
+		},
+	},
+	{
+		// 9am to 5pm, monday to friday, overlapping ranges are only counted once
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}, {StartMinute: 600, EndMinute: 660}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Months:   []MonthRange{{InclusiveRange{Begin: 2, End: 2}}},
+		},
+		weekly: 40 * time.Hour,
+		perWeekday: map[time.Weekday]time.Duration{
+			time.Sunday: 0, time.Monday: 8 * time.Hour, time.Tuesday: 8 * time.Hour, time.Wednesday: 8 * time.Hour,
+			time.Thursday: 8 * time.Hour, time.Friday: 8 * time.Hour, time.Saturday: 0,
+		},
+	},
+	{
+		// Friday and saturday nights, counted on the days each part of the night falls on
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}},
+		},
+		weekly: 16 * time.Hour,
+		perWeekday: map[time.Weekday]time.Duration{
+			time.Sunday: 6 * time.Hour, time.Monday: 0, time.Tuesday: 0, time.Wednesday: 0,
+			time.Thursday: 0, time.Friday: 2 * time.Hour, time.Saturday: 8 * time.Hour,
+		},
+	},
+	{
+		// Weekends only, 30 minutes a day
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 0, EndMinute: 30}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}},
+		},
+		weekly: time.Hour,
+		perWeekday: map[time.Weekday]time.Duration{
+			time.Sunday: 30 * time.Minute, time.Monday: 0, time.Tuesday: 0, time.Wednesday: 0,
+			time.Thursday: 0, time.Friday: 0, time.Saturday: 30 * time.Minute,
+		},
+	},
+}
+
+func TestWeeklyActiveDuration(t *testing.T) {
+	for _, tc := range weeklyActiveDurationTestCases {
+		if got := tc.timeInterval.WeeklyActiveDuration(); got != tc.weekly {
+			t.Errorf("WeeklyActiveDuration for %+v: want %v, got %v", tc.timeInterval, tc.weekly, got)
+		}
+		if got := tc.timeInterval.WeekdayActiveDurations(); !reflect.DeepEqual(got, tc.perWeekday) {
+			t.Errorf("WeekdayActiveDurations for %+v: want %v, got %v", tc.timeInterval, tc.perWeekday, got)
+		}
+	}
+}
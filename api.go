@@ -0,0 +1,137 @@
+package gotime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxAPIWindowsRange bounds the range of times whose windows the evaluation API will list in one request.
+const maxAPIWindowsRange = 366 * 24 * time.Hour
+
+type apiWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// APIHandler returns a handler that answers questions about the sets in a registry over HTTP with JSON, so that
+// services that aren't written in Go can use the same schedules. Each request names a set with the name parameter,
+// and times are given and returned in RFC 3339 format, defaulting to the time of the request. The endpoints are:
+//
+//	GET /v1/contains?name=business_hours&t=2024-07-01T12:00:00Z
+//	    {"name": "business_hours", "time": "...", "active": true}
+//	GET /v1/next?name=business_hours&t=2024-07-01T12:00:00Z
+//	    {"name": "business_hours", "time": "...", "active": true, "next_activation": "...", "next_deactivation": "..."}
+//	GET /v1/windows?name=business_hours&from=2024-07-01T00:00:00Z&to=2024-07-08T00:00:00Z
+//	    {"name": "business_hours", "windows": [{"start": "...", "end": "..."}]}
+//
+// The next times are left out if they don't occur within the search horizon, and windows may be listed for up to a
+// year at a time. Errors are returned with a status of 400, or 404 for an unknown name, and a body such as
+// {"error": "No schedule named maintenance"}.
+func APIHandler(r *Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/contains", apiEndpoint(r, func(name string, set IntervalSet, q apiQuery) (interface{}, error) {
+		t, err := q.time("t")
+		if err != nil {
+			return nil, err
+		}
+		return struct {
+			Name   string    `json:"name"`
+			Time   time.Time `json:"time"`
+			Active bool      `json:"active"`
+		}{name, t, set.ContainsTime(t)}, nil
+	}))
+	mux.HandleFunc("/v1/next", apiEndpoint(r, func(name string, set IntervalSet, q apiQuery) (interface{}, error) {
+		t, err := q.time("t")
+		if err != nil {
+			return nil, err
+		}
+		state := set.StateAt(t)
+		return struct {
+			Name             string     `json:"name"`
+			Time             time.Time  `json:"time"`
+			Active           bool       `json:"active"`
+			NextActivation   *time.Time `json:"next_activation,omitempty"`
+			NextDeactivation *time.Time `json:"next_deactivation,omitempty"`
+		}{name, t, state.Active, state.NextActivation, state.NextDeactivation}, nil
+	}))
+	mux.HandleFunc("/v1/windows", apiEndpoint(r, func(name string, set IntervalSet, q apiQuery) (interface{}, error) {
+		from, err := q.time("from")
+		if err != nil {
+			return nil, err
+		}
+		to, err := q.time("to")
+		if err != nil {
+			return nil, err
+		}
+		if to.Before(from) {
+			return nil, errors.New("Couldn't list windows, from is after to")
+		}
+		if to.Sub(from) > maxAPIWindowsRange {
+			return nil, errors.New("Couldn't list windows, the range can be at most a year")
+		}
+		windows := []apiWindow{}
+		for _, w := range set.Windows(from, to) {
+			windows = append(windows, apiWindow{w.Start, w.End})
+		}
+		return struct {
+			Name    string      `json:"name"`
+			Windows []apiWindow `json:"windows"`
+		}{name, windows}, nil
+	}))
+	return mux
+}
+
+// An apiQuery holds the parameters of a request to the evaluation API.
+type apiQuery struct {
+	req *http.Request
+	now time.Time
+}
+
+// time returns the time in the named parameter, or the time of the request if it isn't given.
+func (q apiQuery) time(param string) (time.Time, error) {
+	value := q.req.URL.Query().Get(param)
+	if value == "" {
+		return q.now, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Couldn't parse %s %s, expected an RFC 3339 time", param, value)
+	}
+	return t, nil
+}
+
+// apiEndpoint returns a handler that looks up the set named in a GET request and writes the result of fn as JSON.
+func apiEndpoint(r *Registry, fn func(string, IntervalSet, apiQuery) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeJSON(w, http.StatusMethodNotAllowed, apiError{http.StatusText(http.StatusMethodNotAllowed)})
+			return
+		}
+		name := req.URL.Query().Get("name")
+		set, ok := r.Lookup(name)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, apiError{fmt.Sprintf("No schedule named %s", name)})
+			return
+		}
+		out, err := fn(name, set, apiQuery{req, time.Now()})
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
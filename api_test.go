@@ -0,0 +1,57 @@
+package gotime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIHandler(t *testing.T) {
+	r := NewRegistry(Definitions{
+		"business_hours": IntervalSet{{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		}},
+	})
+	h := APIHandler(r)
+	cases := []struct {
+		method, url string
+		status      int
+		body        string
+	}{
+		{"GET", "/v1/contains?name=business_hours&t=2024-07-01T12:00:00Z", http.StatusOK,
+			`{"name":"business_hours","time":"2024-07-01T12:00:00Z","active":true}`},
+		{"GET", "/v1/contains?name=business_hours&t=2024-07-06T12:00:00Z", http.StatusOK,
+			`{"name":"business_hours","time":"2024-07-06T12:00:00Z","active":false}`},
+		{"GET", "/v1/next?name=business_hours&t=2024-07-05T12:00:00Z", http.StatusOK,
+			`{"name":"business_hours","time":"2024-07-05T12:00:00Z","active":true,` +
+				`"next_activation":"2024-07-08T09:00:00Z","next_deactivation":"2024-07-05T17:00:00Z"}`},
+		{"GET", "/v1/windows?name=business_hours&from=2024-07-05T00:00:00Z&to=2024-07-08T10:00:00Z", http.StatusOK,
+			`{"name":"business_hours","windows":[{"start":"2024-07-05T09:00:00Z","end":"2024-07-05T17:00:00Z"},` +
+				`{"start":"2024-07-08T09:00:00Z","end":"2024-07-08T10:00:00Z"}]}`},
+		{"GET", "/v1/windows?name=business_hours&from=2024-07-06T00:00:00Z&to=2024-07-07T00:00:00Z", http.StatusOK,
+			`{"name":"business_hours","windows":[]}`},
+		{"GET", "/v1/windows?name=business_hours&from=2024-07-08T00:00:00Z&to=2024-07-01T00:00:00Z",
+			http.StatusBadRequest, `{"error":"Couldn't list windows, from is after to"}`},
+		{"GET", "/v1/windows?name=business_hours&from=2024-07-08T00:00:00Z&to=2026-07-01T00:00:00Z",
+			http.StatusBadRequest, `{"error":"Couldn't list windows, the range can be at most a year"}`},
+		{"GET", "/v1/contains?name=business_hours&t=tomorrow", http.StatusBadRequest,
+			`{"error":"Couldn't parse t tomorrow, expected an RFC 3339 time"}`},
+		{"GET", "/v1/contains?name=maintenance", http.StatusNotFound, `{"error":"No schedule named maintenance"}`},
+		{"POST", "/v1/contains?name=business_hours", http.StatusMethodNotAllowed, `{"error":"Method Not Allowed"}`},
+		{"GET", "/v1/other", http.StatusNotFound, "404 page not found"},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(c.method, c.url, nil))
+		if body := strings.TrimSpace(rec.Body.String()); rec.Code != c.status || body != c.body {
+			t.Errorf("Expected %d %s from %s %s, got %d %s", c.status, c.body, c.method, c.url, rec.Code, body)
+		}
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/contains?name=business_hours", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"active"`) {
+		t.Errorf("Expected the time to default to now, got %d %s", rec.Code, rec.Body)
+	}
+}
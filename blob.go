@@ -0,0 +1,170 @@
+package gotime
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// emptySHA256 is the hex encoded SHA-256 hash of an empty request body.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// An S3Source is a Source that fetches a document from an object in an Amazon S3 bucket, or one in a store with the
+// same API. Requests are signed with AWS Signature Version 4, and are conditional on the object's ETag so that an
+// unchanged object isn't downloaded again. An S3Source is safe for concurrent use.
+type S3Source struct {
+	Bucket string
+	Key    string
+	Region string
+	// Endpoint, if set, is the base URL of an S3 compatible store, which is addressed with the bucket in the path
+	// rather than the host name.
+	Endpoint string
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials to sign requests with. If AccessKeyID is empty
+	// they are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables, and
+	// requests aren't signed if that is empty too, as for a public bucket.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Client makes the requests, or http.DefaultClient if nil.
+	Client *http.Client
+
+	mu   sync.Mutex
+	last conditionalGet
+}
+
+// Fetch implements the Source interface for S3Source.
+func (s *S3Source) Fetch(ctx context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.Region, awsURIEncode(s.Key))
+	if s.Endpoint != "" {
+		url = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(s.Endpoint, "/"), s.Bucket, awsURIEncode(s.Key))
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	id, secret, token := s.AccessKeyID, s.SecretAccessKey, s.SessionToken
+	if id == "" {
+		id, secret, token = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN")
+	}
+	s.last.setConditions(req)
+	if id != "" {
+		if token != "" {
+			req.Header.Set("X-Amz-Security-Token", token)
+		}
+		signV4(req, id, secret, s.Region, "s3", time.Now())
+	}
+	return s.last.do(s.Client, req.WithContext(ctx))
+}
+
+func (s *S3Source) String() string {
+	return fmt.Sprintf("s3://%s/%s", s.Bucket, s.Key)
+}
+
+// A GCSSource is a Source that fetches a document from an object in a Google Cloud Storage bucket. Requests are
+// conditional on the object's ETag so that an unchanged object isn't downloaded again. A GCSSource is safe for
+// concurrent use.
+type GCSSource struct {
+	Bucket string
+	Object string
+	// Token, if set, returns an OAuth 2.0 access token to authorize each request with, such as one from the metadata
+	// server or golang.org/x/oauth2/google. Requests aren't authorized if it is nil, as for a public bucket.
+	Token func(ctx context.Context) (string, error)
+	// Endpoint is the base URL of the storage API, or https://storage.googleapis.com if empty.
+	Endpoint string
+	// Client makes the requests, or http.DefaultClient if nil.
+	Client *http.Client
+
+	mu   sync.Mutex
+	last conditionalGet
+}
+
+// Fetch implements the Source interface for GCSSource.
+func (s *GCSSource) Fetch(ctx context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	url := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), s.Bucket, awsURIEncode(s.Object))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Token != nil {
+		token, err := s.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	s.last.setConditions(req)
+	return s.last.do(s.Client, req.WithContext(ctx))
+}
+
+func (s *GCSSource) String() string {
+	return fmt.Sprintf("gs://%s/%s", s.Bucket, s.Object)
+}
+
+// signV4 signs a request without a body with AWS Signature Version 4, covering its host and every header set on it.
+func signV4(req *http.Request, id, secret, region, service string, t time.Time) {
+	stamp := t.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", emptySHA256)
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+	request := strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonical.String(), signed,
+		emptySHA256}, "\n")
+	scope := stamp[:8] + "/" + region + "/" + service + "/aws4_request"
+	hash := sha256.Sum256([]byte(request))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{stamp[:8], region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		id, scope, signed, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsURIEncode percent encodes an object key for use in a URL path, leaving its slashes and unreserved characters as
+// they are.
+func awsURIEncode(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
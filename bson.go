@@ -0,0 +1,238 @@
+package gotime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// BSON element types.
+const (
+	bsonDouble   = 0x01
+	bsonString   = 0x02
+	bsonDocument = 0x03
+	bsonArray    = 0x04
+	bsonBool     = 0x08
+	bsonDateTime = 0x09
+	bsonNull     = 0x0a
+	bsonInt32    = 0x10
+	bsonInt64    = 0x12
+)
+
+var errInvalidBSON = errors.New("Couldn't parse BSON document, invalid format")
+
+// MarshalBSON implements the bson.Marshaler interface of the MongoDB driver for TimeInterval. The interval is stored
+// as a document with the same fields as its YAML form.
+func (tp TimeInterval) MarshalBSON() ([]byte, error) {
+	out, err := yaml.Marshal(tp)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeBSONDocument(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBSON implements the bson.Unmarshaler interface of the MongoDB driver for TimeInterval. The document is
+// validated in the same way as YAML, so invalid intervals are rejected when they are decoded.
+func (tp *TimeInterval) UnmarshalBSON(data []byte) error {
+	doc, rest, err := readBSONDocument(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errInvalidBSON
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var decoded TimeInterval
+	if err := yaml.Unmarshal(out, &decoded); err != nil {
+		return err
+	}
+	*tp = decoded
+	return nil
+}
+
+// writeBSONDocument writes a document whose values are those produced by unmarshalling YAML.
+func writeBSONDocument(buf *bytes.Buffer, doc yaml.MapSlice) error {
+	var body bytes.Buffer
+	for _, item := range doc {
+		if err := writeBSONElement(&body, fmt.Sprint(item.Key), item.Value); err != nil {
+			return err
+		}
+	}
+	writeBSONLength(buf, body.Len()+5)
+	buf.Write(body.Bytes())
+	buf.WriteByte(0)
+	return nil
+}
+
+func writeBSONElement(buf *bytes.Buffer, name string, value interface{}) error {
+	writeHeader := func(t byte) {
+		buf.WriteByte(t)
+		buf.WriteString(name)
+		buf.WriteByte(0)
+	}
+	switch v := value.(type) {
+	case nil:
+		writeHeader(bsonNull)
+	case bool:
+		writeHeader(bsonBool)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case int:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			writeHeader(bsonInt32)
+			writeBSONLength(buf, v)
+			break
+		}
+		writeHeader(bsonInt64)
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(v))
+		buf.Write(b[:])
+	case float64:
+		writeHeader(bsonDouble)
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+		buf.Write(b[:])
+	case string:
+		writeHeader(bsonString)
+		writeBSONLength(buf, len(v)+1)
+		buf.WriteString(v)
+		buf.WriteByte(0)
+	case yaml.MapSlice:
+		writeHeader(bsonDocument)
+		return writeBSONDocument(buf, v)
+	case []interface{}:
+		writeHeader(bsonArray)
+		// Arrays are documents whose keys are the indices of their elements.
+		array := make(yaml.MapSlice, len(v))
+		for i, element := range v {
+			array[i] = yaml.MapItem{Key: i, Value: element}
+		}
+		return writeBSONDocument(buf, array)
+	default:
+		return fmt.Errorf("Unable to convert %T into BSON", value)
+	}
+	return nil
+}
+
+func writeBSONLength(buf *bytes.Buffer, n int) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(int32(n)))
+	buf.Write(b[:])
+}
+
+// readBSONDocument reads a document from the start of data, returning it and whatever follows it.
+func readBSONDocument(data []byte) (yaml.MapSlice, []byte, error) {
+	if len(data) < 5 {
+		return nil, nil, errInvalidBSON
+	}
+	length := int(int32(binary.LittleEndian.Uint32(data)))
+	if length < 5 || length > len(data) || data[length-1] != 0 {
+		return nil, nil, errInvalidBSON
+	}
+	body, rest := data[4:length-1], data[length:]
+	doc := yaml.MapSlice{}
+	for len(body) > 0 {
+		t := body[0]
+		end := bytes.IndexByte(body[1:], 0)
+		if end < 0 {
+			return nil, nil, errInvalidBSON
+		}
+		name := string(body[1 : end+1])
+		body = body[end+2:]
+		var value interface{}
+		var err error
+		if value, body, err = readBSONValue(t, body); err != nil {
+			return nil, nil, err
+		}
+		doc = append(doc, yaml.MapItem{Key: name, Value: value})
+	}
+	return doc, rest, nil
+}
+
+// readBSONValue reads a value of the given element type from the start of data, returning it and whatever follows it.
+func readBSONValue(t byte, data []byte) (interface{}, []byte, error) {
+	fixed := func(n int) ([]byte, []byte, error) {
+		if len(data) < n {
+			return nil, nil, errInvalidBSON
+		}
+		return data[:n], data[n:], nil
+	}
+	switch t {
+	case bsonNull:
+		return nil, data, nil
+	case bsonBool:
+		b, rest, err := fixed(1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return b[0] != 0, rest, nil
+	case bsonInt32:
+		b, rest, err := fixed(4)
+		if err != nil {
+			return nil, nil, err
+		}
+		return int(int32(binary.LittleEndian.Uint32(b))), rest, nil
+	case bsonInt64:
+		b, rest, err := fixed(8)
+		if err != nil {
+			return nil, nil, err
+		}
+		return int(int64(binary.LittleEndian.Uint64(b))), rest, nil
+	case bsonDouble:
+		b, rest, err := fixed(8)
+		if err != nil {
+			return nil, nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), rest, nil
+	case bsonDateTime:
+		// Timestamps written by other applications are milliseconds since the Unix epoch, and become RFC 3339 strings.
+		b, rest, err := fixed(8)
+		if err != nil {
+			return nil, nil, err
+		}
+		ms := int64(binary.LittleEndian.Uint64(b))
+		return time.Unix(ms/1000, ms%1000*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano), rest, nil
+	case bsonString:
+		b, rest, err := fixed(4)
+		if err != nil {
+			return nil, nil, err
+		}
+		length := int(int32(binary.LittleEndian.Uint32(b)))
+		if length < 1 || length > len(rest) || rest[length-1] != 0 {
+			return nil, nil, errInvalidBSON
+		}
+		return string(rest[:length-1]), rest[length:], nil
+	case bsonDocument:
+		return readBSONDocument(data)
+	case bsonArray:
+		doc, rest, err := readBSONDocument(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		array := make([]interface{}, len(doc))
+		for i, item := range doc {
+			array[i] = item.Value
+		}
+		return array, rest, nil
+	}
+	return nil, nil, fmt.Errorf("Unable to convert BSON type 0x%02x into a value", t)
+}
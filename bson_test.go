@@ -0,0 +1,45 @@
+package gotime
+
+import (
+	"bytes"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestBSON(t *testing.T) {
+	checkRoundTrip(t, TimeInterval.MarshalBSON, (*TimeInterval).UnmarshalBSON)
+}
+
+func TestBSONFormat(t *testing.T) {
+	ti := TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}}
+	data, err := ti.MarshalBSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x29, 0x00, 0x00, 0x00,
+		0x04, 'w', 'e', 'e', 'k', 'd', 'a', 'y', 's', 0x00,
+		0x1a, 0x00, 0x00, 0x00,
+		0x02, '0', 0x00, 0x0e, 0x00, 0x00, 0x00, 'm', 'o', 'n', 'd', 'a', 'y', ':', 'f', 'r', 'i', 'd', 'a', 'y', 0x00,
+		0x00,
+		0x00,
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Encoding %+v: want % x, got % x", ti, want, data)
+	}
+	var invalidData bytes.Buffer
+	if err := writeBSONDocument(&invalidData, yaml.MapSlice{{Key: "weekdays", Value: []interface{}{"smarch"}}}); err != nil {
+		t.Fatal(err)
+	}
+	var invalid TimeInterval
+	for _, data := range [][]byte{invalidData.Bytes(), data[:10], {0x05, 0x00, 0x00, 0x00, 0x01}} {
+		if err := invalid.UnmarshalBSON(data); err == nil {
+			t.Errorf("Expected decoding % x to fail", data)
+		}
+	}
+}
+
+func TestBSONNeverActive(t *testing.T) {
+	checkNeverActive(t, TimeInterval.MarshalBSON, (*TimeInterval).UnmarshalBSON)
+}
@@ -0,0 +1,194 @@
+package gotime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A CalendarSystem converts days into dates of a calendar other than the Gregorian one, so that observances which move
+// against the Gregorian calendar every year can be matched. Date is given a time in the interval's location and should
+// return the year, month and day of the month of its calendar date. Months and days are numbered from 1.
+type CalendarSystem interface {
+	Date(day time.Time) (year, month, dayOfMonth int)
+}
+
+// The CalendarSystemFunc type is an adapter to allow the use of ordinary functions as CalendarSystems.
+type CalendarSystemFunc func(day time.Time) (year, month, dayOfMonth int)
+
+// Date calls f(day).
+func (f CalendarSystemFunc) Date(day time.Time) (year, month, dayOfMonth int) {
+	return f(day)
+}
+
+// CalendarDates matches days by their date in a CalendarSystem, which is represented by the name it was registered
+// under, e.g.
+//
+//	calendar:
+//	  system: hebrew
+//	  dates: ['1-15:1-22']
+type CalendarDates struct {
+	System string
+	CalendarSystem
+	Dates []CalendarDateRange
+}
+
+// A CalendarDate is a month and day of the month in a CalendarSystem. A Day of 0 stands for the whole month.
+type CalendarDate struct {
+	Month int
+	Day   int
+}
+
+// A CalendarDateRange is an inclusive range of dates in a CalendarSystem, written as 'month-day:month-day', e.g.
+// '9-25:10-2'. A month on its own covers every day of that month, so '9' is the whole of the ninth month. Ranges whose
+// end is before their beginning wrap around the end of the year.
+type CalendarDateRange struct {
+	Begin CalendarDate
+	End   CalendarDate
+}
+
+type yamlCalendarDates struct {
+	System string              `yaml:"system"`
+	Dates  []CalendarDateRange `yaml:"dates,flow"`
+}
+
+var (
+	calendarSystemsMu sync.RWMutex
+	calendarSystems   = map[string]CalendarSystem{}
+)
+
+// RegisterCalendarSystem makes a calendar available to intervals under the given name, replacing any existing
+// calendar with that name. The tabular Islamic calendar (islamic) and the Hebrew calendar (hebrew) are available
+// without being registered.
+func RegisterCalendarSystem(name string, cs CalendarSystem) {
+	calendarSystemsMu.Lock()
+	defer calendarSystemsMu.Unlock()
+	calendarSystems[strings.ToLower(name)] = cs
+}
+
+// lookupCalendarSystem returns the calendar registered under the given name.
+func lookupCalendarSystem(name string) (CalendarSystem, bool) {
+	calendarSystemsMu.RLock()
+	defer calendarSystemsMu.RUnlock()
+	cs, ok := calendarSystems[strings.ToLower(name)]
+	return cs, ok
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for CalendarDates.
+func (c *CalendarDates) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var y yamlCalendarDates
+	if err := unmarshal(&y); err != nil {
+		return err
+	}
+	cs, ok := lookupCalendarSystem(y.System)
+	if !ok {
+		return fmt.Errorf("%s is not a known calendar system", y.System)
+	}
+	c.System, c.CalendarSystem, c.Dates = strings.ToLower(y.System), cs, y.Dates
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for CalendarDates
+func (c CalendarDates) MarshalYAML() (interface{}, error) {
+	if c.System == "" {
+		return nil, fmt.Errorf("Unable to marshal a calendar system without a name")
+	}
+	return yamlCalendarDates{System: c.System, Dates: c.Dates}, nil
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for CalendarDateRange.
+func (r *CalendarDateRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	components := strings.Split(str, ":")
+	if len(components) > 2 {
+		return fmt.Errorf("Couldn't parse calendar date range %s, invalid format", str)
+	}
+	begin, err := parseCalendarDate(components[0])
+	if err != nil {
+		return err
+	}
+	end := begin
+	if len(components) == 2 {
+		if end, err = parseCalendarDate(components[1]); err != nil {
+			return err
+		}
+	}
+	r.Begin, r.End = begin, end
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for CalendarDateRange
+func (r CalendarDateRange) MarshalYAML() (interface{}, error) {
+	if r.Begin == r.End {
+		return interface{}(r.Begin.String()), nil
+	}
+	return interface{}(r.Begin.String() + ":" + r.End.String()), nil
+}
+
+// String returns the date as 'month-day', or just the month if it stands for the whole month.
+func (d CalendarDate) String() string {
+	if d.Day == 0 {
+		return strconv.Itoa(d.Month)
+	}
+	return fmt.Sprintf("%d-%d", d.Month, d.Day)
+}
+
+func parseCalendarDate(in string) (CalendarDate, error) {
+	in = strings.TrimSpace(in)
+	components := strings.Split(in, "-")
+	if len(components) > 2 {
+		return CalendarDate{}, fmt.Errorf("Couldn't parse calendar date %s, invalid format", in)
+	}
+	var d CalendarDate
+	var err error
+	if d.Month, err = strconv.Atoi(components[0]); err != nil {
+		return CalendarDate{}, fmt.Errorf("Couldn't parse calendar date %s, invalid format", in)
+	}
+	if d.Month < 1 || d.Month > 13 {
+		return CalendarDate{}, fmt.Errorf("%s is not a valid calendar date: month out of range", in)
+	}
+	if len(components) == 2 {
+		if d.Day, err = strconv.Atoi(components[1]); err != nil {
+			return CalendarDate{}, fmt.Errorf("Couldn't parse calendar date %s, invalid format", in)
+		}
+		if d.Day < 1 || d.Day > 30 {
+			return CalendarDate{}, fmt.Errorf("%s is not a valid calendar date: day out of range", in)
+		}
+	}
+	return d, nil
+}
+
+// containsDay returns true if the date of the given time in the calendar system falls within one of the ranges.
+func (c CalendarDates) containsDay(t time.Time) bool {
+	if c.CalendarSystem == nil {
+		return false
+	}
+	_, month, day := c.Date(t)
+	for _, r := range c.Dates {
+		if r.contains(month, day) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true if the given month and day fall within the range.
+func (r CalendarDateRange) contains(month, day int) bool {
+	// Dates are compared as month*100+day, with a whole month at the beginning of a range starting before its first day
+	// and one at the end finishing after its last.
+	begin := r.Begin.Month*100 + r.Begin.Day
+	end := r.End.Month*100 + r.End.Day
+	if r.End.Day == 0 {
+		end += 99
+	}
+	d := month*100 + day
+	if begin <= end {
+		return d >= begin && d <= end
+	}
+	return d >= begin || d <= end
+}
@@ -0,0 +1,131 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var calendarTestCases = []struct {
+	in          string
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// Passover, 15 to 22 Nisan
+		in:       "calendar: {system: 'hebrew', dates: ['1-15:1-22']}",
+		contains: []string{"2024-04-23T00:00:00Z", "2024-04-30T23:59:59Z", "2025-04-13T12:00:00Z"},
+		excludes: []string{"2024-04-22T23:59:59Z", "2024-05-01T00:00:00Z", "2025-04-23T12:00:00Z"},
+	},
+	{
+		// Hanukkah, spanning the end of Kislev
+		in:       "calendar: {system: 'Hebrew', dates: ['9-25:10-2']}",
+		contains: []string{"2024-12-26T12:00:00Z", "2025-01-02T12:00:00Z"},
+		excludes: []string{"2024-12-25T12:00:00Z", "2025-01-03T12:00:00Z"},
+	},
+	{
+		// Evenings during Ramadan
+		in: `
+calendar:
+  system: islamic
+  dates: ['9']
+times:
+  - start_time: '18:00'
+    end_time: '22:00'
+`,
+		contains: []string{"2024-03-11T19:00:00Z", "2024-04-09T19:00:00Z"},
+		excludes: []string{"2024-03-11T12:00:00Z", "2024-03-10T19:00:00Z", "2024-04-10T19:00:00Z"},
+	},
+	{
+		// Wrapping around the end of the Islamic year
+		in:       "calendar: {system: 'islamic', dates: ['12-30:1-1']}",
+		contains: []string{"2024-07-07T12:00:00Z", "2024-07-08T12:00:00Z"},
+		excludes: []string{"2024-07-06T12:00:00Z", "2024-07-09T12:00:00Z"},
+	},
+	{
+		in:          "calendar: {system: 'julian', dates: ['1']}",
+		expectError: true,
+	},
+	{
+		in:          "calendar: {system: 'hebrew', dates: ['14-1']}",
+		expectError: true,
+	},
+	{
+		in:          "calendar: {system: 'hebrew', dates: ['1-31']}",
+		expectError: true,
+	},
+	{
+		in:          "calendar: {system: 'hebrew', dates: ['nisan']}",
+		expectError: true,
+	},
+}
+
+func TestCalendar(t *testing.T) {
+	for _, tc := range calendarTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		// Functions are never deeply equal, so compare what the re-parsed interval marshals to instead.
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil {
+			t.Error(err)
+		}
+		if out2, err := yaml.Marshal(ti2); err != nil || string(out) != string(out2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestCalendarAnalysis(t *testing.T) {
+	// A calendar in which every month is a Gregorian month.
+	gregorian := CalendarSystemFunc(func(day time.Time) (int, int, int) {
+		return day.Year(), int(day.Month()), day.Day()
+	})
+	christmas := TimeInterval{Calendar: &CalendarDates{
+		System:         "gregorian",
+		CalendarSystem: gregorian,
+		Dates:          []CalendarDateRange{{Begin: CalendarDate{Month: 12, Day: 25}, End: CalendarDate{Month: 12, Day: 26}}},
+	}}
+	if christmas.IsAlwaysActive() || christmas.IsEmpty() {
+		t.Errorf("Expected calendar dates to be neither always active nor empty")
+	}
+	got := christmas.Windows(mustParseTime("2020-12-01T00:00:00Z"), mustParseTime("2021-02-01T00:00:00Z"))
+	want := []Window{{Start: mustParseTime("2020-12-25T00:00:00Z"), End: mustParseTime("2020-12-27T00:00:00Z")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows of calendar dates: want %v, got %v", want, got)
+	}
+	if _, err := Intersect(christmas, christmas); err != ErrNotRepresentable {
+		t.Errorf("Expected intersecting calendar dates to be unrepresentable, got %v", err)
+	}
+	if !(TimeInterval{Calendar: &CalendarDates{System: "gregorian", CalendarSystem: gregorian, Dates: []CalendarDateRange{}}}).IsEmpty() {
+		t.Errorf("Expected calendar dates without any ranges to be empty")
+	}
+	if _, err := yaml.Marshal(TimeInterval{Calendar: &CalendarDates{CalendarSystem: gregorian}}); err == nil {
+		t.Errorf("Expected marshalling an unnamed calendar system to fail")
+	}
+}
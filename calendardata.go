@@ -0,0 +1,148 @@
+package gotime
+
+import "time"
+
+// The conversions below follow Reingold and Dershowitz's Calendrical Calculations, counting days from 1 January 1 in
+// the proleptic Gregorian calendar, which is day 1.
+
+// rataDieUnixEpoch is the day number of 1 January 1970.
+const rataDieUnixEpoch = 719163
+
+func init() {
+	RegisterCalendarSystem("islamic", CalendarSystemFunc(islamicDate))
+	RegisterCalendarSystem("hebrew", CalendarSystemFunc(hebrewDate))
+}
+
+// rataDie returns the day number of the calendar date of t.
+func rataDie(t time.Time) int {
+	return int(date(t.Year(), t.Month(), t.Day()).Unix()/secondsPerDay) + rataDieUnixEpoch
+}
+
+// floorDiv returns a/b rounded towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// islamicEpoch is the day number of 1 Muharram 1 AH, 16 July 622 in the Julian calendar.
+const islamicEpoch = 227015
+
+// islamicDate returns the date of the given day in the tabular Islamic calendar, which approximates the months that
+// begin with sightings of the new moon using a 30 year cycle of 11 leap years. Observed dates may differ by a day.
+// Months are numbered from Muharram (1) to Dhu al-Hijjah (12), so Ramadan is the ninth month.
+func islamicDate(t time.Time) (year, month, day int) {
+	d := rataDie(t)
+	year = floorDiv(30*(d-islamicEpoch)+10646, 10631)
+	priorDays := d - fixedFromIslamic(year, 1, 1)
+	month = floorDiv(11*priorDays+330, 325)
+	day = d - fixedFromIslamic(year, month, 1) + 1
+	return year, month, day
+}
+
+func fixedFromIslamic(year, month, day int) int {
+	return day + 29*(month-1) + floorDiv(6*month-1, 11) + (year-1)*354 + floorDiv(3+11*year, 30) + islamicEpoch - 1
+}
+
+// hebrewEpoch is the day number of 1 Tishri 1 AM, 7 October 3761 BCE in the Julian calendar.
+const hebrewEpoch = -1373427
+
+// Months of the Hebrew calendar are numbered from Nisan, though the year begins in Tishri.
+const (
+	nisan  = 1
+	tishri = 7
+)
+
+// hebrewDate returns the date of the given day in the Hebrew calendar. Months are numbered from Nisan (1) to Adar (12),
+// with Adar II as the thirteenth month of leap years, so Tishri, in which the year begins, is the seventh.
+func hebrewDate(t time.Time) (year, month, day int) {
+	d := rataDie(t)
+	// Estimate the year from the average length of a year, then correct the estimate.
+	year = int(float64(d-hebrewEpoch)/(35975351.0/98496.0)) + 1
+	for hebrewNewYear(year+1) <= d {
+		year++
+	}
+	for hebrewNewYear(year) > d {
+		year--
+	}
+	month = nisan
+	if d < fixedFromHebrew(year, nisan, 1) {
+		month = tishri
+	}
+	for d > fixedFromHebrew(year, month, hebrewMonthLength(year, month)) {
+		month++
+	}
+	return year, month, d - fixedFromHebrew(year, month, 1) + 1
+}
+
+func fixedFromHebrew(year, month, day int) int {
+	d := hebrewNewYear(year) + day - 1
+	if month < tishri {
+		for m := tishri; m <= hebrewMonthsInYear(year); m++ {
+			d += hebrewMonthLength(year, m)
+		}
+		for m := nisan; m < month; m++ {
+			d += hebrewMonthLength(year, m)
+		}
+	} else {
+		for m := tishri; m < month; m++ {
+			d += hebrewMonthLength(year, m)
+		}
+	}
+	return d
+}
+
+func hebrewLeapYear(year int) bool {
+	return ((7*year+1)%19+19)%19 < 7
+}
+
+func hebrewMonthsInYear(year int) int {
+	if hebrewLeapYear(year) {
+		return 13
+	}
+	return 12
+}
+
+// hebrewElapsedDays returns the number of days from the epoch to the molad of Tishri of the given year, postponed
+// so that the year doesn't begin on a Sunday, Wednesday or Friday.
+func hebrewElapsedDays(year int) int {
+	monthsElapsed := floorDiv(235*year-234, 19)
+	partsElapsed := 12084 + 13753*monthsElapsed
+	days := 29*monthsElapsed + floorDiv(partsElapsed, 25920)
+	if (3*(days+1))%7 < 3 {
+		days++
+	}
+	return days
+}
+
+// hebrewNewYear returns the day number of 1 Tishri of the given year, after delaying it where needed to keep the
+// lengths of the surrounding years valid.
+func hebrewNewYear(year int) int {
+	ny0, ny1, ny2 := hebrewElapsedDays(year-1), hebrewElapsedDays(year), hebrewElapsedDays(year+1)
+	correction := 0
+	if ny2-ny1 == 356 {
+		correction = 2
+	} else if ny1-ny0 == 382 {
+		correction = 1
+	}
+	return hebrewEpoch + ny1 + correction
+}
+
+func hebrewMonthLength(year, month int) int {
+	daysInYear := hebrewNewYear(year+1) - hebrewNewYear(year)
+	switch {
+	case month == 2 || month == 4 || month == 6 || month == 10 || month == 13:
+		return 29
+	case month == 12 && !hebrewLeapYear(year):
+		return 29
+	case month == 8 && daysInYear%10 != 5:
+		// Marheshvan is long only in complete years of 355 or 385 days.
+		return 29
+	case month == 9 && daysInYear%10 == 3:
+		// Kislev is short in deficient years of 353 or 383 days.
+		return 29
+	}
+	return 30
+}
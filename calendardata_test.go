@@ -0,0 +1,41 @@
+package gotime
+
+import "testing"
+
+var calendarDataTestCases = []struct {
+	system string
+	day    string
+	year   int
+	month  int
+	date   int
+}{
+	// Rosh Hashanah 5785
+	{system: "hebrew", day: "2024-10-03", year: 5785, month: 7, date: 1},
+	// Yom Kippur 5784
+	{system: "hebrew", day: "2023-09-25", year: 5784, month: 7, date: 10},
+	// The first day of Passover 5784
+	{system: "hebrew", day: "2024-04-23", year: 5784, month: 1, date: 15},
+	// Adar II of the leap year 5784
+	{system: "hebrew", day: "2024-03-11", year: 5784, month: 13, date: 1},
+	// Purim in the common year 5785
+	{system: "hebrew", day: "2025-03-14", year: 5785, month: 12, date: 14},
+	// The first day of Ramadan 1445
+	{system: "islamic", day: "2024-03-11", year: 1445, month: 9, date: 1},
+	// Eid al-Fitr 1444, which the tabular calendar places a day after it was observed as Ramadan always has 30 days
+	{system: "islamic", day: "2023-04-22", year: 1444, month: 10, date: 1},
+	{system: "islamic", day: "2000-01-01", year: 1420, month: 9, date: 24},
+}
+
+func TestCalendarData(t *testing.T) {
+	for _, tc := range calendarDataTestCases {
+		cs, ok := lookupCalendarSystem(tc.system)
+		if !ok {
+			t.Fatalf("Calendar system %s isn't registered", tc.system)
+		}
+		year, month, date := cs.Date(mustParseTime(tc.day + "T12:00:00Z"))
+		if year != tc.year || month != tc.month || date != tc.date {
+			t.Errorf("%s date of %s: want %d-%d-%d, got %d-%d-%d", tc.system, tc.day, tc.year, tc.month, tc.date, year,
+				month, date)
+		}
+	}
+}
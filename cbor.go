@@ -0,0 +1,274 @@
+package gotime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// CBOR major types.
+const (
+	cborUnsigned = 0
+	cborNegative = 1
+	cborBytes    = 2
+	cborText     = 3
+	cborArray    = 4
+	cborMap      = 5
+	cborTag      = 6
+	cborSimple   = 7
+)
+
+var errInvalidCBOR = errors.New("Couldn't parse CBOR item, invalid format")
+
+// MarshalCBOR implements the cbor.Marshaler interface of github.com/fxamacker/cbor for TimeInterval. The interval is
+// encoded as a map with the same fields as its YAML form, so it can be embedded in compact binary configuration.
+func (tp TimeInterval) MarshalCBOR() ([]byte, error) {
+	out, err := yaml.Marshal(tp)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCBOR(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCBOR implements the cbor.Unmarshaler interface of github.com/fxamacker/cbor for TimeInterval. The map is
+// validated in the same way as YAML, so invalid intervals are rejected when they are decoded. Items of indefinite
+// length aren't supported.
+func (tp *TimeInterval) UnmarshalCBOR(data []byte) error {
+	value, rest, err := readCBOR(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errInvalidCBOR
+	}
+	if _, ok := value.(yaml.MapSlice); !ok {
+		return errors.New("Couldn't parse CBOR item, expected a map")
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var decoded TimeInterval
+	if err := yaml.Unmarshal(out, &decoded); err != nil {
+		return err
+	}
+	*tp = decoded
+	return nil
+}
+
+// writeCBOR writes a value produced by unmarshalling YAML.
+func writeCBOR(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(cborSimple<<5 | 22)
+	case bool:
+		if v {
+			buf.WriteByte(cborSimple<<5 | 21)
+		} else {
+			buf.WriteByte(cborSimple<<5 | 20)
+		}
+	case int:
+		if v >= 0 {
+			writeCBORHead(buf, cborUnsigned, uint64(v))
+		} else {
+			writeCBORHead(buf, cborNegative, uint64(-1-v))
+		}
+	case uint64:
+		writeCBORHead(buf, cborUnsigned, v)
+	case float64:
+		buf.WriteByte(cborSimple<<5 | 27)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+		buf.Write(b[:])
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+	case yaml.MapSlice:
+		writeCBORHead(buf, cborMap, uint64(len(v)))
+		for _, item := range v {
+			if err := writeCBOR(buf, item.Key); err != nil {
+				return err
+			}
+			if err := writeCBOR(buf, item.Value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, element := range v {
+			if err := writeCBOR(buf, element); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("Unable to convert %T into CBOR", value)
+	}
+	return nil
+}
+
+// writeCBORHead writes the initial bytes of an item, holding its major type and an argument in as few bytes as
+// possible.
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	var b [8]byte
+	switch {
+	case n < 24:
+		buf.WriteByte(major<<5 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		binary.BigEndian.PutUint16(b[:], uint16(n))
+		buf.Write(b[:2])
+	case n <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		binary.BigEndian.PutUint32(b[:], uint32(n))
+		buf.Write(b[:4])
+	default:
+		buf.WriteByte(major<<5 | 27)
+		binary.BigEndian.PutUint64(b[:], n)
+		buf.Write(b[:])
+	}
+}
+
+// readCBORHead reads the initial bytes of an item, returning its major type, its argument, its additional information
+// and whatever follows it.
+func readCBORHead(data []byte) (byte, uint64, byte, []byte, error) {
+	if len(data) < 1 {
+		return 0, 0, 0, nil, errInvalidCBOR
+	}
+	major, info, data := data[0]>>5, data[0]&0x1f, data[1:]
+	size := 0
+	switch {
+	case info < 24:
+		return major, uint64(info), info, data, nil
+	case info <= 27:
+		size = 1 << (info - 24)
+	default:
+		return 0, 0, 0, nil, errInvalidCBOR
+	}
+	if len(data) < size {
+		return 0, 0, 0, nil, errInvalidCBOR
+	}
+	var n uint64
+	for _, b := range data[:size] {
+		n = n<<8 | uint64(b)
+	}
+	return major, n, info, data[size:], nil
+}
+
+// readCBOR reads an item from the start of data, returning it as a value like those produced by unmarshalling YAML,
+// and whatever follows it.
+func readCBOR(data []byte) (interface{}, []byte, error) {
+	major, n, info, data, err := readCBORHead(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch major {
+	case cborUnsigned:
+		if n > math.MaxInt64 {
+			return n, data, nil
+		}
+		return int(n), data, nil
+	case cborNegative:
+		if n > math.MaxInt64 {
+			return nil, nil, errors.New("Couldn't parse CBOR integer, out of range")
+		}
+		return -1 - int(n), data, nil
+	case cborBytes, cborText:
+		if n > uint64(len(data)) {
+			return nil, nil, errInvalidCBOR
+		}
+		return string(data[:n]), data[n:], nil
+	case cborArray:
+		// Every element takes at least a byte, which bounds the length before anything is allocated.
+		if n > uint64(len(data)) {
+			return nil, nil, errInvalidCBOR
+		}
+		array := make([]interface{}, n)
+		for i := range array {
+			if array[i], data, err = readCBOR(data); err != nil {
+				return nil, nil, err
+			}
+		}
+		return array, data, nil
+	case cborMap:
+		if n > uint64(len(data))/2 {
+			return nil, nil, errInvalidCBOR
+		}
+		doc := make(yaml.MapSlice, n)
+		for i := range doc {
+			if doc[i].Key, data, err = readCBOR(data); err != nil {
+				return nil, nil, err
+			}
+			if doc[i].Value, data, err = readCBOR(data); err != nil {
+				return nil, nil, err
+			}
+		}
+		return doc, data, nil
+	case cborTag:
+		value, rest, err := readCBOR(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Epoch timestamps written by other applications become RFC 3339 strings. Other tags, such as those of
+		// RFC 3339 timestamps, are left out and their content kept.
+		if n == 1 {
+			switch v := value.(type) {
+			case int:
+				return time.Unix(int64(v), 0).UTC().Format(time.RFC3339Nano), rest, nil
+			case float64:
+				sec, frac := math.Modf(v)
+				return time.Unix(int64(sec), int64(frac*1e9)).UTC().Format(time.RFC3339Nano), rest, nil
+			}
+		}
+		return value, rest, nil
+	}
+	switch info {
+	case 20:
+		return false, data, nil
+	case 21:
+		return true, data, nil
+	case 22, 23:
+		return nil, data, nil
+	case 25:
+		return halfToFloat64(uint16(n)), data, nil
+	case 26:
+		return float64(math.Float32frombits(uint32(n))), data, nil
+	case 27:
+		return math.Float64frombits(n), data, nil
+	}
+	return nil, nil, fmt.Errorf("Unable to convert CBOR simple value %d into a value", n)
+}
+
+// halfToFloat64 converts an IEEE 754 half precision float, which CBOR encoders use for small floats.
+func halfToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp, frac := int(h>>10&0x1f), float64(h&0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	}
+	return sign * math.Ldexp(frac+1024, exp-25)
+}
@@ -0,0 +1,78 @@
+package gotime
+
+import (
+	"bytes"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestCBOR(t *testing.T) {
+	checkRoundTrip(t, TimeInterval.MarshalCBOR, (*TimeInterval).UnmarshalCBOR)
+}
+
+func TestCBORFormat(t *testing.T) {
+	ti := TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}}
+	data, err := ti.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xa1,
+		0x68, 'w', 'e', 'e', 'k', 'd', 'a', 'y', 's',
+		0x81, 0x6d, 'm', 'o', 'n', 'd', 'a', 'y', ':', 'f', 'r', 'i', 'd', 'a', 'y',
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Encoding %+v: want % x, got % x", ti, want, data)
+	}
+
+	// Items written by other encoders may use longer heads, half precision floats and tags.
+	var coords TimeInterval
+	err = coords.UnmarshalCBOR([]byte{
+		0xa2,
+		0x78, 0x08, 'l', 'o', 'c', 'a', 't', 'i', 'o', 'n', 0x63, 'U', 'T', 'C',
+		0x6b, 'c', 'o', 'o', 'r', 'd', 'i', 'n', 'a', 't', 'e', 's',
+		0xa2, 0x68, 'l', 'a', 't', 'i', 't', 'u', 'd', 'e', 0xf9, 0x3e, 0x00,
+		0x69, 'l', 'o', 'n', 'g', 'i', 't', 'u', 'd', 'e', 0x38, 0x63,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *coords.Coordinates != (Coordinates{Latitude: 1.5, Longitude: -100}) || coords.Location.String() != "UTC" {
+		t.Errorf("Decoded unexpected interval %+v", coords)
+	}
+	var absolute TimeInterval
+	err = absolute.UnmarshalCBOR([]byte{
+		0xa1, 0x68, 'a', 'b', 's', 'o', 'l', 'u', 't', 'e', 0x81,
+		0xa2, 0x65, 's', 't', 'a', 'r', 't', 0xc1, 0x1a, 0x65, 0x92, 0x00, 0x80,
+		0x63, 'e', 'n', 'd', 0xc1, 0x1a, 0x65, 0x92, 0x0e, 0x90,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := absolute.Absolute[0].End.Sub(absolute.Absolute[0].Start); got.Hours() != 1 {
+		t.Errorf("Expected tagged timestamps an hour apart, got %v", absolute.Absolute)
+	}
+
+	var invalidData bytes.Buffer
+	if err := writeCBOR(&invalidData, yaml.MapSlice{{Key: "weekdays", Value: []interface{}{"smarch"}}}); err != nil {
+		t.Fatal(err)
+	}
+	var invalid TimeInterval
+	for _, data := range [][]byte{
+		invalidData.Bytes(),
+		data[:10],
+		append(data, 0x00),
+		{0x81, 0x00},
+		{0xbf, 0xff},
+		{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	} {
+		if err := invalid.UnmarshalCBOR(data); err == nil {
+			t.Errorf("Expected decoding % x to fail", data)
+		}
+	}
+}
+
+func TestCBORNeverActive(t *testing.T) {
+	checkNeverActive(t, TimeInterval.MarshalCBOR, (*TimeInterval).UnmarshalCBOR)
+}
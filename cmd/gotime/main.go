@@ -0,0 +1,426 @@
+// Command gotime checks and converts schedule documents from the command line, so that they can be tested without
+// writing Go. Run it without arguments for usage.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benridley/gotime"
+	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const usage = `usage: gotime <command> [flags] [arguments]
+
+Commands:
+  calendar -f file [-n name] [-week] [time]  print the month, or week, around the time with active days marked
+  check -f file [-n name] [time]             exit 0 if the schedule is active at the time, or 1 if not
+  explain -f file [-n name] [time]           print which parts of the schedule match the time, and which don't
+  next -f file [-n name] [time]              print when the schedule next changes after the time
+  windows -f file [-n name] -from t -to t    print the schedule's active windows between two times
+  tui -f file [-n name] [time]               browse upcoming windows, switching intervals on and off
+  validate -f file                           check that a document is valid
+  convert -f file [-n name] -to format       convert a schedule to cron, ics, json or yaml, with ics events
+                                             between -from and -until
+
+Times are in RFC 3339 format, e.g. 2024-05-01T10:00:00Z, and default to now. A file holds a single interval, a list
+of intervals, or named definitions, of which -n picks one.
+`
+
+// exitError is the exit status for errors, as distinct from check's inactive result.
+const exitError = 2
+
+// now is the time that times default to, replaced in tests.
+var now = time.Now
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command given by args, writing its output to stdout and errors to stderr, and returns the exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	commands := map[string]func(*command) error{
+		"calendar": calendar,
+		"check":    check,
+		"explain":  explain,
+		"next":     next,
+		"windows":  windows,
+		"validate": validate,
+		"convert":  convert,
+		"tui":      tui,
+	}
+	fn, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "gotime: unknown command %s\n\n%s", args[0], usage)
+		return exitError
+	}
+	c := &command{flags: flag.NewFlagSet(args[0], flag.ContinueOnError), stdout: stdout}
+	c.flags.SetOutput(stderr)
+	c.flags.StringVar(&c.file, "f", "", "the schedule `file` to read")
+	c.flags.StringVar(&c.name, "n", "", "the `name` of the definition to use")
+	switch args[0] {
+	case "calendar":
+		c.flags.BoolVar(&c.week, "week", false, "show the week with each half hour marked, rather than the month")
+	case "windows":
+		c.flags.StringVar(&c.from, "from", "", "the `time` to list windows from, or now")
+		c.flags.StringVar(&c.to, "to", "", "the `time` to list windows until, or a year after -from")
+	case "convert":
+		c.flags.StringVar(&c.format, "to", "", "the `format` to convert to: cron, ics, json or yaml")
+		c.flags.StringVar(&c.from, "from", "", "the `time` that ics events start from, or now")
+		c.flags.StringVar(&c.to, "until", "", "the `time` that ics events recur until, or a year after -from")
+	}
+	if err := c.flags.Parse(args[1:]); err != nil {
+		return exitError
+	}
+	if err := fn(c); err != nil {
+		fmt.Fprintf(stderr, "gotime %s: %v\n", args[0], err)
+		return exitError
+	}
+	return c.status
+}
+
+// A command holds the flags and output of a command.
+type command struct {
+	flags      *flag.FlagSet
+	file, name string
+	from, to   string
+	format     string
+	week       bool
+	stdout     io.Writer
+	status     int
+}
+
+func calendar(c *command) error {
+	set, err := c.schedule()
+	if err != nil {
+		return err
+	}
+	t, err := c.time()
+	if err != nil {
+		return err
+	}
+	if c.week {
+		return gotime.RenderWeek(c.stdout, set, t)
+	}
+	return gotime.RenderMonth(c.stdout, set, t)
+}
+
+func check(c *command) error {
+	set, err := c.schedule()
+	if err != nil {
+		return err
+	}
+	t, err := c.time()
+	if err != nil {
+		return err
+	}
+	if set.ContainsTime(t) {
+		fmt.Fprintf(c.stdout, "active at %s\n", t.Format(time.RFC3339))
+		return nil
+	}
+	fmt.Fprintf(c.stdout, "inactive at %s\n", t.Format(time.RFC3339))
+	c.status = 1
+	return nil
+}
+
+func explain(c *command) error {
+	set, err := c.schedule()
+	if err != nil {
+		return err
+	}
+	t, err := c.time()
+	if err != nil {
+		return err
+	}
+	for _, e := range set.Explain(t) {
+		fmt.Fprint(c.stdout, e)
+	}
+	if len(set) > 1 {
+		fmt.Fprintf(c.stdout, "schedule at %s: %s\n", t.Format(time.RFC3339), activeText(set.ContainsTime(t)))
+	}
+	if !set.ContainsTime(t) {
+		c.status = 1
+	}
+	return nil
+}
+
+func next(c *command) error {
+	set, err := c.schedule()
+	if err != nil {
+		return err
+	}
+	t, err := c.time()
+	if err != nil {
+		return err
+	}
+	state := set.StateAt(t)
+	if state.NextActivation == nil && state.NextDeactivation == nil {
+		fmt.Fprintf(c.stdout, "%s and never changes\n", activeText(state.Active))
+		return nil
+	}
+	if state.Active {
+		fmt.Fprintf(c.stdout, "active until %s\n", state.NextDeactivation.Format(time.RFC3339))
+	} else {
+		fmt.Fprintf(c.stdout, "inactive until %s\n", state.NextActivation.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func windows(c *command) error {
+	set, err := c.schedule()
+	if err != nil {
+		return err
+	}
+	from, to, err := c.bounds()
+	if err != nil {
+		return err
+	}
+	for _, w := range set.Windows(from, to) {
+		fmt.Fprintf(c.stdout, "%s %s\n", w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func validate(c *command) error {
+	doc, err := c.document()
+	if err != nil {
+		return err
+	}
+	var warnings []string
+	for _, name := range doc.names() {
+		for i, ti := range doc.sets[name] {
+			if ti.IsEmpty() {
+				warnings = append(warnings, fmt.Sprintf("%s interval %d is never active", doc.label(name), i+1))
+			}
+		}
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(c.stdout, "warning: %s\n", w)
+	}
+	fmt.Fprintf(c.stdout, "%s is valid\n", c.file)
+	return nil
+}
+
+func convert(c *command) error {
+	switch c.format {
+	case "json", "yaml":
+		doc, err := c.document()
+		if err != nil {
+			return err
+		}
+		var v interface{} = doc.value
+		if c.name != "" {
+			if v, err = doc.set(c.name); err != nil {
+				return err
+			}
+		}
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if c.format == "json" {
+			// Round trip through yaml.v3, which decodes mappings with string keys as JSON needs.
+			var generic interface{}
+			if err := yamlv3.Unmarshal(out, &generic); err != nil {
+				return err
+			}
+			if out, err = json.MarshalIndent(generic, "", "  "); err != nil {
+				return err
+			}
+			out = append(out, '\n')
+		}
+		_, err = c.stdout.Write(out)
+		return err
+	case "cron":
+		set, err := c.schedule()
+		if err != nil {
+			return err
+		}
+		from := now()
+		for _, ti := range set {
+			if ti.Mode != gotime.ModeAllow {
+				return errors.New("Unable to express intervals that deny times as cron expressions")
+			}
+			schedule, err := ti.CronJob(from, from.AddDate(1, 0, 0))
+			if err != nil {
+				return err
+			}
+			for _, expr := range schedule.Schedules {
+				if schedule.TimeZone != "" {
+					expr = "CRON_TZ=" + schedule.TimeZone + " " + expr
+				}
+				fmt.Fprintln(c.stdout, expr)
+			}
+			if len(schedule.Suspensions) > 0 {
+				fmt.Fprintf(c.stdout, "# starts jobs at times within the next year that the interval excludes, "+
+					"such as %s\n", schedule.Suspensions[0].Start.Format(time.RFC3339))
+			}
+		}
+		return nil
+	case "ics":
+		set, err := c.schedule()
+		if err != nil {
+			return err
+		}
+		from, to, err := c.bounds()
+		if err != nil {
+			return err
+		}
+		out, err := set.ICS(from, to)
+		if err != nil {
+			return err
+		}
+		_, err = c.stdout.Write(out)
+		return err
+	case "":
+		return errors.New("-to is required")
+	}
+	return fmt.Errorf("%s is not a valid format, expected cron, ics, json or yaml", c.format)
+}
+
+// A document is a schedule file, holding either named definitions or a single unnamed set.
+type document struct {
+	sets  gotime.Definitions
+	named bool
+	value interface{}
+}
+
+func (d document) names() []string {
+	names := make([]string, 0, len(d.sets))
+	for name := range d.sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d document) label(name string) string {
+	if d.named {
+		return name
+	}
+	return "schedule"
+}
+
+// set returns the set with the given name, or the only set in the document if name is empty.
+func (d document) set(name string) (gotime.IntervalSet, error) {
+	if !d.named {
+		if name != "" {
+			return nil, errors.New("The document has no named definitions")
+		}
+		return d.sets[""], nil
+	}
+	if name == "" {
+		if len(d.sets) != 1 {
+			return nil, fmt.Errorf("The document has several definitions, pick one with -n: %s",
+				strings.Join(d.names(), ", "))
+		}
+		name = d.names()[0]
+	}
+	set, ok := d.sets[name]
+	if !ok {
+		return nil, fmt.Errorf("No definition named %s", name)
+	}
+	return set, nil
+}
+
+// document reads the file given by -f.
+func (c *command) document() (document, error) {
+	if c.file == "" {
+		return document{}, errors.New("-f is required")
+	}
+	data, err := ioutil.ReadFile(c.file)
+	if err != nil {
+		return document{}, err
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return document{}, err
+	}
+	if _, ok := raw.([]interface{}); ok {
+		var set gotime.IntervalSet
+		if err := yaml.UnmarshalStrict(data, &set); err != nil {
+			return document{}, err
+		}
+		return document{sets: gotime.Definitions{"": set}, value: set}, nil
+	}
+	var defs gotime.Definitions
+	defsErr := yaml.UnmarshalStrict(data, &defs)
+	if defsErr == nil {
+		return document{sets: defs, named: true, value: defs}, nil
+	}
+	// A mapping that isn't made of definitions may be a single interval.
+	var ti gotime.TimeInterval
+	if err := yaml.UnmarshalStrict(data, &ti); err == nil {
+		return document{sets: gotime.Definitions{"": {ti}}, value: ti}, nil
+	}
+	return document{}, defsErr
+}
+
+// schedule returns the set chosen by -f and -n.
+func (c *command) schedule() (gotime.IntervalSet, error) {
+	doc, err := c.document()
+	if err != nil {
+		return nil, err
+	}
+	return doc.set(c.name)
+}
+
+// time returns the time given as the command's argument, or now if there isn't one.
+func (c *command) time() (time.Time, error) {
+	switch c.flags.NArg() {
+	case 0:
+		return now(), nil
+	case 1:
+		return parseTime(c.flags.Arg(0))
+	}
+	return time.Time{}, errors.New("Expected at most one time")
+}
+
+// bounds returns the range of times given by the command's flags, which defaults to a year from now.
+func (c *command) bounds() (time.Time, time.Time, error) {
+	from, to := now(), time.Time{}
+	var err error
+	if c.from != "" {
+		if from, err = parseTime(c.from); err != nil {
+			return from, to, err
+		}
+	}
+	to = from.AddDate(1, 0, 0)
+	if c.to != "" {
+		if to, err = parseTime(c.to); err != nil {
+			return from, to, err
+		}
+	}
+	return from, to, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "now" {
+		return now(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("Couldn't parse time %s, expected RFC 3339 format", s)
+	}
+	return t, nil
+}
+
+func activeText(active bool) string {
+	if active {
+		return "active"
+	}
+	return "inactive"
+}
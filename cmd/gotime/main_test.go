@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"interval.yml": "weekdays: ['monday:friday']\ntimes:\n  - start_time: '09:00'\n    end_time: '17:00'\n",
+		"list.yml":     "- weekdays: ['saturday', 'sunday']\n",
+		"defs.yml": "business_hours:\n  weekdays: ['monday:friday']\n  times:\n    - start_time: '09:00'\n" +
+			"      end_time: '17:00'\nnever:\n  weekdays: []\n",
+		"bad.yml": "weekdays: ['someday']\n",
+	}
+	for name, doc := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(doc), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now = func() time.Time { return time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	cases := []struct {
+		args   string
+		status int
+		out    string
+	}{
+		{"check -f interval.yml 2024-05-01T10:00:00Z", 0, "active at 2024-05-01T10:00:00Z\n"},
+		{"check -f interval.yml 2024-05-04T10:00:00Z", 1, "inactive at 2024-05-04T10:00:00Z\n"},
+		{"check -f list.yml", 1, "inactive at 2024-07-01T12:00:00Z\n"},
+		{"check -f defs.yml -n business_hours", 0, "active at 2024-07-01T12:00:00Z\n"},
+		{"check -f defs.yml", 2, ""},
+		{"check -f defs.yml -n missing", 2, ""},
+		{"check -f interval.yml yesterday", 2, ""},
+		{"check -f bad.yml", 2, ""},
+		{"check", 2, ""},
+		{"explain -f interval.yml 2024-07-06T10:00:00Z", 1, "interval at 2024-07-06T10:00:00Z: inactive\n" +
+			"  times: matched\n    09:00-17:00: matched\n  weekdays: rejected\n    monday:friday: no match\n"},
+		{"explain -f list.yml 2024-07-06T10:00:00Z", 0, "interval at 2024-07-06T10:00:00Z: active\n" +
+			"  weekdays: matched\n    saturday: matched\n    sunday: no match\n"},
+		{"calendar -f list.yml 2024-09-01T00:00:00Z", 0, "      September 2024\n Mo  Tu  We  Th  Fr  Sa  Su\n" +
+			"                          1#\n  2.  3.  4.  5.  6.  7#  8#\n  9. 10. 11. 12. 13. 14# 15#\n" +
+			" 16. 17. 18. 19. 20. 21# 22#\n 23. 24. 25. 26. 27. 28# 29#\n 30.\n"},
+		{"calendar -f interval.yml -week 2024-07-06T00:00:00Z", 0,
+			"           00    03    06    09    12    15    18    21\n" +
+				"Mon 01 Jul ..................################..............\n" +
+				"Tue 02 Jul ..................################..............\n" +
+				"Wed 03 Jul ..................################..............\n" +
+				"Thu 04 Jul ..................################..............\n" +
+				"Fri 05 Jul ..................################..............\n" +
+				"Sat 06 Jul ................................................\n" +
+				"Sun 07 Jul ................................................\n"},
+		{"next -f interval.yml", 0, "active until 2024-07-01T17:00:00Z\n"},
+		{"next -f interval.yml 2024-07-05T18:00:00Z", 0, "inactive until 2024-07-08T09:00:00Z\n"},
+		{"next -f defs.yml -n never", 0, "inactive and never changes\n"},
+		{"windows -f interval.yml -from 2024-07-05T00:00:00Z -to 2024-07-08T10:00:00Z", 0,
+			"2024-07-05T09:00:00Z 2024-07-05T17:00:00Z\n2024-07-08T09:00:00Z 2024-07-08T10:00:00Z\n"},
+		{"validate -f defs.yml", 0, "warning: never interval 1 is never active\ndefs.yml is valid\n"},
+		{"validate -f bad.yml", 2, ""},
+		{"convert -f interval.yml -to cron", 0, "* 9-16 * * 1-5\n"},
+		{"convert -f defs.yml -n business_hours -to json", 0, "[\n  {\n    \"name\": \"business_hours\",\n" +
+			"    \"times\": [\n      {\n        \"end_time\": \"17:00\",\n        \"start_time\": \"09:00\"\n      }\n    ],\n" +
+			"    \"weekdays\": [\n      \"monday:friday\"\n    ]\n  }\n]\n"},
+		{"convert -f list.yml -to yaml", 0, "- weekdays: [saturday, sunday]\n"},
+		{"convert -f interval.yml -to xml", 2, ""},
+		{"convert -f interval.yml", 2, ""},
+		{"frobnicate", 2, ""},
+	}
+	for _, c := range cases {
+		args := strings.Fields(c.args)
+		for i, arg := range args {
+			if strings.HasSuffix(arg, ".yml") {
+				args[i] = filepath.Join(dir, arg)
+			}
+		}
+		var stdout, stderr bytes.Buffer
+		status := run(args, &stdout, &stderr)
+		out := strings.Replace(stdout.String(), dir+string(filepath.Separator), "", -1)
+		if status != c.status || out != c.out {
+			t.Errorf("Expected gotime %s to exit %d with %q, got %d with %q and errors %q", c.args, c.status, c.out,
+				status, out, stderr.String())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"convert", "-f", filepath.Join(dir, "interval.yml"), "-to", "ics"}, &stdout,
+		&stderr); status != 0 || !strings.Contains(stdout.String(), "BEGIN:VCALENDAR") {
+		t.Errorf("Expected an iCalendar file, got %d with %q and errors %q", status, stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benridley/gotime"
+)
+
+// stdin is where the tui command reads its input, replaced in tests.
+var stdin io.Reader = os.Stdin
+
+// Settings of the tui command.
+const (
+	// tuiPage is how many windows are shown at once.
+	tuiPage = 10
+	// tuiHorizon is how far after the cursor windows are looked for.
+	tuiHorizon = 366 * 24 * time.Hour
+	// clearScreen moves the cursor home and clears a terminal.
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+const tuiHelp = "n or enter: next page  p: previous page  g time: go to a time or date  t number: toggle an entry  " +
+	"q: quit"
+
+// A browser is the state of the tui command: the schedule's entries, which of them are switched on, and the time
+// from which windows are shown.
+type browser struct {
+	labels  []string
+	sets    []gotime.IntervalSet
+	enabled []bool
+	cursor  time.Time
+	// back holds the cursors of earlier pages, for going back to them.
+	back []time.Time
+	// shown is the last window of the current page.
+	shown *gotime.Window
+	msg   string
+}
+
+// newBrowser returns a browser of the definitions in the document, or of the intervals in the set picked by -n if the
+// document has a single set or -n is given.
+func newBrowser(doc document, name string, cursor time.Time) (*browser, error) {
+	b := &browser{cursor: cursor}
+	if doc.named && name == "" && len(doc.sets) > 1 {
+		for _, name := range doc.names() {
+			b.labels = append(b.labels, name)
+			b.sets = append(b.sets, doc.sets[name])
+		}
+	} else {
+		set, err := doc.set(name)
+		if err != nil {
+			return nil, err
+		}
+		for i, ti := range set {
+			b.labels = append(b.labels, intervalLabel(ti, i))
+			b.sets = append(b.sets, gotime.IntervalSet{ti})
+		}
+	}
+	b.enabled = make([]bool, len(b.sets))
+	for i := range b.enabled {
+		b.enabled[i] = true
+	}
+	return b, nil
+}
+
+// intervalLabel returns the text form of the i'th interval of a set, or its position if it has no text form. The
+// name and description that definitions give their intervals are left out.
+func intervalLabel(ti gotime.TimeInterval, i int) string {
+	ti.Name, ti.Description = "", ""
+	if text, err := ti.MarshalText(); err == nil && len(text) > 0 {
+		return string(text)
+	}
+	return fmt.Sprintf("interval %d", i+1)
+}
+
+// schedule returns the set made of the entries that are switched on.
+func (b *browser) schedule() gotime.IntervalSet {
+	var set gotime.IntervalSet
+	for i, s := range b.sets {
+		if b.enabled[i] {
+			set = append(set, s...)
+		}
+	}
+	return set
+}
+
+// draw writes the entries and the page of windows from the cursor.
+func (b *browser) draw(w io.Writer) {
+	for i, label := range b.labels {
+		mark := ' '
+		if b.enabled[i] {
+			mark = 'x'
+		}
+		fmt.Fprintf(w, "%2d [%c] %s\n", i+1, mark, label)
+	}
+	fmt.Fprintf(w, "\nWindows from %s:\n", b.cursor.Format("Mon 2006-01-02 15:04 MST"))
+	windows := b.schedule().Windows(b.cursor, b.cursor.Add(tuiHorizon))
+	if len(windows) > tuiPage {
+		windows = windows[:tuiPage]
+	}
+	b.shown = nil
+	for i, win := range windows {
+		fmt.Fprintf(w, "  %s - %s  %s\n", win.Start.Format("Mon 2006-01-02 15:04"),
+			win.End.Format("Mon 2006-01-02 15:04"), win.Duration())
+		if i == len(windows)-1 {
+			b.shown = &windows[i]
+		}
+	}
+	if len(windows) == 0 {
+		fmt.Fprintln(w, "  none within a year")
+	}
+	if b.msg != "" {
+		fmt.Fprintf(w, "\n%s\n", b.msg)
+		b.msg = ""
+	}
+	fmt.Fprintf(w, "\n%s\n> ", tuiHelp)
+}
+
+// do carries out a line of input, returning false if the browser should quit.
+func (b *browser) do(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		fields = []string{"n"}
+	}
+	switch fields[0] {
+	case "q", "quit":
+		return false
+	case "n":
+		if b.shown == nil {
+			b.msg = "No more windows within a year"
+			return true
+		}
+		b.back = append(b.back, b.cursor)
+		b.cursor = b.shown.End
+	case "p":
+		if len(b.back) == 0 {
+			b.msg = "Already at the first page"
+			return true
+		}
+		b.cursor = b.back[len(b.back)-1]
+		b.back = b.back[:len(b.back)-1]
+	case "g":
+		if len(fields) != 2 {
+			b.msg = "Expected a time or date to go to, e.g. g 2024-12-25"
+			return true
+		}
+		t, err := parseBrowserTime(fields[1], b.cursor.Location())
+		if err != nil {
+			b.msg = err.Error()
+			return true
+		}
+		b.back = append(b.back, b.cursor)
+		b.cursor = t
+	case "t":
+		n := 0
+		if len(fields) == 2 {
+			n, _ = strconv.Atoi(fields[1])
+		}
+		if n < 1 || n > len(b.enabled) {
+			b.msg = fmt.Sprintf("Expected an entry to toggle, from 1 to %d", len(b.enabled))
+			return true
+		}
+		b.enabled[n-1] = !b.enabled[n-1]
+	default:
+		b.msg = fmt.Sprintf("Unknown command %s", fields[0])
+	}
+	return true
+}
+
+// parseBrowserTime parses a time in RFC 3339 format, or a date, which is taken to be midnight in loc.
+func parseBrowserTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, nil
+	}
+	return parseTime(s)
+}
+
+func tui(c *command) error {
+	doc, err := c.document()
+	if err != nil {
+		return err
+	}
+	cursor, err := c.time()
+	if err != nil {
+		return err
+	}
+	b, err := newBrowser(doc, c.name, cursor)
+	if err != nil {
+		return err
+	}
+	if len(b.sets) == 0 {
+		return errors.New("The schedule has no intervals to browse")
+	}
+	clear := isTerminal(c.stdout)
+	in := bufio.NewScanner(stdin)
+	for {
+		if clear {
+			io.WriteString(c.stdout, clearScreen)
+		}
+		b.draw(c.stdout)
+		if !in.Scan() || !b.do(in.Text()) {
+			fmt.Fprintln(c.stdout)
+			return in.Err()
+		}
+	}
+}
+
+// isTerminal returns true if w is a terminal, which the screen is cleared on between pages.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTUI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defs := "mornings:\n  weekdays: ['monday:friday']\n  times:\n    - start_time: '09:00'\n      end_time: '12:00'\n" +
+		"weekends:\n  weekdays: ['saturday', 'sunday']\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "defs.yml"), []byte(defs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "defs.yml")
+	now = func() time.Time { return time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	defer func() { stdin = os.Stdin }()
+
+	cases := []struct {
+		input    string
+		args     []string
+		contains []string
+		excludes []string
+	}{
+		{
+			input: "q\n",
+			contains: []string{
+				" 1 [x] mornings\n 2 [x] weekends\n",
+				"Windows from Mon 2024-07-01 12:00 UTC:\n  Tue 2024-07-02 09:00 - Tue 2024-07-02 12:00  3h0m0s\n",
+				"  Sat 2024-07-06 00:00 - Mon 2024-07-08 00:00  48h0m0s\n",
+			},
+		},
+		{
+			// Switching weekends off leaves only mornings.
+			input:    "t 2\nq\n",
+			contains: []string{" 2 [ ] weekends\n", "  Mon 2024-07-08 09:00 - Mon 2024-07-08 12:00  3h0m0s\n"},
+		},
+		{
+			input: "g 2024-12-25\nt 3\nfrobnicate\nq\n",
+			contains: []string{"Windows from Wed 2024-12-25 00:00 UTC:\n", "Expected an entry to toggle, from 1 to 2",
+				"Unknown command frobnicate"},
+		},
+		{
+			// Paging forward and back returns to the first page.
+			input:    "n\np\np\n",
+			contains: []string{"Windows from Fri 2024-07-12 12:00 UTC:\n", "Already at the first page"},
+		},
+		{
+			input:    "q\n",
+			args:     []string{"-n", "mornings"},
+			contains: []string{" 1 [x] monday:friday 09:00-12:00\n"},
+			excludes: []string{"weekends"},
+		},
+	}
+	for _, c := range cases {
+		stdin = strings.NewReader(c.input)
+		var stdout, stderr bytes.Buffer
+		args := append([]string{"tui", "-f", file}, c.args...)
+		if status := run(args, &stdout, &stderr); status != 0 {
+			t.Errorf("Expected status 0 for input %q, got %d: %s", c.input, status, stderr.String())
+			continue
+		}
+		for _, want := range c.contains {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("Expected output for input %q to contain %q, got:\n%s", c.input, want, stdout.String())
+			}
+		}
+		for _, unwanted := range c.excludes {
+			if strings.Contains(stdout.String(), unwanted) {
+				t.Errorf("Expected output for input %q not to contain %q", c.input, unwanted)
+			}
+		}
+	}
+}
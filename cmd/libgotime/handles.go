@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/benridley/gotime"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Schedules parsed through the C API are held here and referred to by handle, since C code can't hold Go pointers.
+var (
+	mu        sync.Mutex
+	schedules = map[int64]gotime.IntervalSet{}
+	lastID    int64
+)
+
+// errUnknownHandle is returned for handles that were never issued or have been released.
+var errUnknownHandle = errors.New("Unknown schedule handle")
+
+// parse parses a YAML document holding a single interval or a list of intervals, and returns a handle to it.
+func parse(doc string) (int64, error) {
+	var node yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte(doc), &node); err != nil {
+		return 0, err
+	}
+	var set gotime.IntervalSet
+	if len(node.Content) > 0 && node.Content[0].Kind == yamlv3.MappingNode {
+		var ti gotime.TimeInterval
+		if err := gotime.Unmarshal([]byte(doc), &ti); err != nil {
+			return 0, err
+		}
+		set = gotime.IntervalSet{ti}
+	} else if err := gotime.Unmarshal([]byte(doc), &set); err != nil {
+		return 0, err
+	}
+	return store(set), nil
+}
+
+// parseSchedule parses a set of intervals in the schedule form, and returns a handle to it.
+func parseSchedule(schedule string) (int64, error) {
+	set, err := gotime.ParseSchedule(schedule)
+	if err != nil {
+		return 0, err
+	}
+	return store(set), nil
+}
+
+func store(set gotime.IntervalSet) int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	lastID++
+	schedules[lastID] = set
+	return lastID
+}
+
+func lookup(h int64) (gotime.IntervalSet, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	set, ok := schedules[h]
+	if !ok {
+		return nil, errUnknownHandle
+	}
+	return set, nil
+}
+
+func release(h int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(schedules, h)
+}
+
+// contains returns true if the schedule contains the time given in seconds since the Unix epoch. Times are matched in
+// UTC, rather than the local time zone of the process, unless an interval has a location of its own.
+func contains(h int64, unix int64) (bool, error) {
+	set, err := lookup(h)
+	if err != nil {
+		return false, err
+	}
+	return set.ContainsTime(time.Unix(unix, 0).UTC()), nil
+}
+
+// next returns when the schedule next starts or stops being active after the time given in seconds since the Unix
+// epoch, also in seconds since the epoch, or false if it doesn't change within the search horizon. Times are matched
+// in UTC as they are by contains.
+func next(h int64, unix int64) (int64, bool, error) {
+	set, err := lookup(h)
+	if err != nil {
+		return 0, false, err
+	}
+	t, ok := set.NextTransition(time.Unix(unix, 0).UTC())
+	if !ok {
+		return 0, false, nil
+	}
+	return t.Unix(), true, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHandles(t *testing.T) {
+	// Intervals without a location are matched in UTC, so the local time zone of the process mustn't matter.
+	loc, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := time.Local
+	time.Local = loc
+	defer func() { time.Local = local }()
+	monday := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC).Unix()
+	saturday := time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC).Unix()
+	cases := []struct {
+		parse    func() (int64, error)
+		active   []int64
+		inactive []int64
+		next     int64
+	}{
+		{
+			parse: func() (int64, error) {
+				return parse("weekdays: ['monday:friday']\ntimes:\n  - start_time: '09:00'\n    end_time: '17:00'\n")
+			},
+			active:   []int64{monday},
+			inactive: []int64{saturday},
+			next:     time.Date(2024, 7, 1, 17, 0, 0, 0, time.UTC).Unix(),
+		},
+		{
+			parse:    func() (int64, error) { return parse("- weekdays: ['saturday']\n- weekdays: ['sunday']\n") },
+			active:   []int64{saturday},
+			inactive: []int64{monday},
+			next:     time.Date(2024, 7, 6, 0, 0, 0, 0, time.UTC).Unix(),
+		},
+		{
+			parse:    func() (int64, error) { return parseSchedule("saturday; sunday") },
+			active:   []int64{saturday},
+			inactive: []int64{monday},
+			next:     time.Date(2024, 7, 6, 0, 0, 0, 0, time.UTC).Unix(),
+		},
+	}
+	for i, c := range cases {
+		h, err := c.parse()
+		if err != nil {
+			t.Fatalf("Unexpected error parsing case %d: %v", i, err)
+		}
+		for _, at := range c.active {
+			if ok, err := contains(h, at); err != nil || !ok {
+				t.Errorf("Expected case %d to contain %d, got %v, %v", i, at, ok, err)
+			}
+		}
+		for _, at := range c.inactive {
+			if ok, err := contains(h, at); err != nil || ok {
+				t.Errorf("Expected case %d not to contain %d, got %v, %v", i, at, ok, err)
+			}
+		}
+		if n, ok, err := next(h, monday); err != nil || !ok || n != c.next {
+			t.Errorf("Expected case %d to next change at %d, got %d, %v, %v", i, c.next, n, ok, err)
+		}
+		release(h)
+		if _, err := contains(h, monday); err != errUnknownHandle {
+			t.Errorf("Expected released handle to be unknown, got %v", err)
+		}
+	}
+
+	for _, doc := range []string{"weekdays: ['someday']\n", "weekdays: [\n", "- times: 9\n"} {
+		if _, err := parse(doc); err == nil {
+			t.Errorf("Expected error parsing %q", doc)
+		}
+	}
+	if _, err := parseSchedule("blursday"); err == nil {
+		t.Error("Expected error parsing schedule")
+	}
+	h, err := parse("weekdays: []\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := next(h, monday); err != nil || ok {
+		t.Errorf("Expected a schedule that is never active never to change, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,75 @@
+// Command libgotime is a C API for gotime, so that tools written in other languages, such as Python or Ruby, can use
+// the same schedule semantics as Go services rather than reimplementing them. Build it as a shared library with
+//
+//	go build -buildmode=c-shared -o libgotime.so ./cmd/libgotime
+//
+// which also writes the header libgotime.h. Schedules are parsed into handles, which are released with
+// gotime_release. Times are given and returned in seconds since the Unix epoch, and intervals without a location are
+// matched in UTC whatever the TZ of the calling process. Functions that fail return -1, and set *err to a message, if
+// err isn't NULL, which must be freed with gotime_free.
+package main
+
+// #include <stdlib.h>
+import "C"
+
+import "unsafe"
+
+func main() {}
+
+//export gotime_parse
+func gotime_parse(doc *C.char, err **C.char) C.longlong {
+	h, e := parse(C.GoString(doc))
+	return result(h, e, err)
+}
+
+//export gotime_parse_schedule
+func gotime_parse_schedule(schedule *C.char, err **C.char) C.longlong {
+	h, e := parseSchedule(C.GoString(schedule))
+	return result(h, e, err)
+}
+
+// gotime_contains returns 1 if the schedule contains the time and 0 if not. Intervals without a location are matched
+// in UTC.
+//
+//export gotime_contains
+func gotime_contains(h C.longlong, t C.longlong, err **C.char) C.int {
+	ok, e := contains(int64(h), int64(t))
+	if ok {
+		return C.int(result(1, e, err))
+	}
+	return C.int(result(0, e, err))
+}
+
+// gotime_next returns 1 and sets *next to when the schedule next changes, or returns 0 if it never does. Intervals
+// without a location are matched in UTC.
+//
+//export gotime_next
+func gotime_next(h C.longlong, t C.longlong, next_ *C.longlong, err **C.char) C.int {
+	n, ok, e := next(int64(h), int64(t))
+	if e != nil || !ok {
+		return C.int(result(0, e, err))
+	}
+	*next_ = C.longlong(n)
+	return 1
+}
+
+//export gotime_release
+func gotime_release(h C.longlong) {
+	release(int64(h))
+}
+
+//export gotime_free
+func gotime_free(p unsafe.Pointer) {
+	C.free(p)
+}
+
+// result returns v, or -1 after setting *err to the message of e if there is an error.
+func result(v int64, e error, err **C.char) C.longlong {
+	if e == nil {
+		return C.longlong(v)
+	}
+	if err != nil {
+		*err = C.CString(e.Error())
+	}
+	return -1
+}
@@ -0,0 +1,494 @@
+package gotime
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotRepresentable is returned when the result of combining intervals cannot be expressed as a single TimeInterval.
+var ErrNotRepresentable = errors.New("Result cannot be represented as a single TimeInterval")
+
+// An Intersection contains a time only if every one of its matchers does. It can express combinations of intervals
+// that Intersect cannot represent as a single TimeInterval. An empty Intersection contains every time.
+type Intersection []Matcher
+
+// And returns an Intersection of the given matchers.
+func And(matchers ...Matcher) Intersection {
+	return Intersection(matchers)
+}
+
+// ContainsTime returns true if every matcher in the Intersection contains the given time.
+func (in Intersection) ContainsTime(t time.Time) bool {
+	for _, m := range in {
+		if !m.ContainsTime(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// NextTransition returns the earliest time after t at which the Intersection either becomes active or stops being
+// active. It returns false if that doesn't happen within the search horizon.
+func (in Intersection) NextTransition(t time.Time) (time.Time, bool) {
+	return nextCompositeTransition(in, transitionMatchers(in), t)
+}
+
+// PreviousTransition returns the latest time at or before t at which the Intersection either became active or
+// stopped being active. It returns false if that didn't happen within the search horizon.
+func (in Intersection) PreviousTransition(t time.Time) (time.Time, bool) {
+	return previousCompositeTransition(in, transitionMatchers(in), t)
+}
+
+// NextActiveTime returns t if the Intersection contains it, otherwise the time at which it next becomes active. It
+// returns false if that doesn't happen within the search horizon.
+func (in Intersection) NextActiveTime(t time.Time) (time.Time, bool) {
+	return nextActiveTime(in, t)
+}
+
+// Windows returns the windows between from and to during which the Intersection is active, in chronological order and
+// clipped to the bounds.
+func (in Intersection) Windows(from, to time.Time) []Window {
+	return transitionWindows(in, from, to)
+}
+
+// A Union contains a time if any one of its matchers does. Unlike an IntervalSet it may hold any kind of Matcher. An
+// empty Union contains no time at all.
+type Union []Matcher
+
+// Or returns a Union of the given matchers.
+func Or(matchers ...Matcher) Union {
+	return Union(matchers)
+}
+
+// ContainsTime returns true if any matcher in the Union contains the given time.
+func (u Union) ContainsTime(t time.Time) bool {
+	for _, m := range u {
+		if m.ContainsTime(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextTransition returns the earliest time after t at which the Union either becomes active or stops being active. It
+// returns false if that doesn't happen within the search horizon.
+func (u Union) NextTransition(t time.Time) (time.Time, bool) {
+	return nextCompositeTransition(u, transitionMatchers(u), t)
+}
+
+// PreviousTransition returns the latest time at or before t at which the Union either became active or stopped being
+// active. It returns false if that didn't happen within the search horizon.
+func (u Union) PreviousTransition(t time.Time) (time.Time, bool) {
+	return previousCompositeTransition(u, transitionMatchers(u), t)
+}
+
+// NextActiveTime returns t if the Union contains it, otherwise the time at which it next becomes active. It returns
+// false if that doesn't happen within the search horizon.
+func (u Union) NextActiveTime(t time.Time) (time.Time, bool) {
+	return nextActiveTime(u, t)
+}
+
+// Windows returns the windows between from and to during which the Union is active, in chronological order and
+// clipped to the bounds.
+func (u Union) Windows(from, to time.Time) []Window {
+	return transitionWindows(u, from, to)
+}
+
+// transitionMatchers converts matchers into TransitionMatchers so that composites can locate their transitions.
+func transitionMatchers(matchers []Matcher) []TransitionMatcher {
+	children := make([]TransitionMatcher, len(matchers))
+	for i, m := range matchers {
+		children[i] = asTransitionMatcher(m)
+	}
+	return children
+}
+
+// Intersect returns a TimeInterval that contains only the times contained by both a and b. If the result can't be
+// expressed as a single TimeInterval, e.g. because it mixes positive and negative days of the month in a way that
+// depends on the length of the month, involves overnight or solar time ranges, different locations or different DST
+// policies, ErrNotRepresentable is returned and an Intersection should be used instead.
+func Intersect(a, b TimeInterval) (TimeInterval, error) {
+	// Whatever is left of both intervals once their exceptions are removed is what's left of their intersection once
+	// every exception is removed.
+	if len(a.Except) > 0 || len(b.Except) > 0 {
+		out, err := Intersect(a.withoutExceptions(), b.withoutExceptions())
+		if err != nil {
+			return TimeInterval{}, err
+		}
+		out.Except = append(append([]TimeInterval{}, a.exceptions()...), b.exceptions()...)
+		return out, nil
+	}
+	switch {
+	case a.IsAlwaysActive():
+		return b, nil
+	case b.IsAlwaysActive():
+		return a, nil
+	}
+	// The part of an overnight range after midnight depends on the previous day matching, which can't be combined with
+	// the days matched by another interval. Nor can intervals that see days in different locations.
+	// Solar times move from day to day, so can't be combined with other times either.
+	if a.hasOvernightTimes() || b.hasOvernightTimes() || a.locationName() != b.locationName() ||
+		a.hasSolarTimes() || b.hasSolarTimes() {
+		return TimeInterval{}, ErrNotRepresentable
+	}
+	// Each interval moves or skips times around clock changes by its own policy, which the result can only share.
+	if a.DST != b.DST {
+		return TimeInterval{}, ErrNotRepresentable
+	}
+	// Days of the month are a union with weekdays of the month, so they can only be combined if one side has neither.
+	if (a.WeekdaysOfMonth != nil && (b.DaysOfMonth != nil || b.WeekdaysOfMonth != nil)) ||
+		(b.WeekdaysOfMonth != nil && a.DaysOfMonth != nil) {
+		return TimeInterval{}, ErrNotRepresentable
+	}
+	if a.Cycle != nil && b.Cycle != nil && !a.Cycle.equal(*b.Cycle) {
+		return TimeInterval{}, ErrNotRepresentable
+	}
+	if a.Holidays != nil && b.Holidays != nil && (a.Holidays.Name == "" || a.Holidays.Name != b.Holidays.Name) {
+		return TimeInterval{}, ErrNotRepresentable
+	}
+	if a.Calendar != nil && b.Calendar != nil {
+		return TimeInterval{}, ErrNotRepresentable
+	}
+	expression := a.Expression
+	if expression == nil {
+		expression = b.Expression
+	} else if b.Expression != nil {
+		var err error
+		if expression, err = a.Expression.and(*b.Expression); err != nil {
+			return TimeInterval{}, err
+		}
+	}
+	feast, ok := commonFeast(a.Relative, b.Relative)
+	if !ok {
+		return TimeInterval{}, ErrNotRepresentable
+	}
+	// Years and quarters can only be combined if both intervals count them in the same way.
+	if a.usesFiscalYear() && b.usesFiscalYear() && a.fiscalStartMonth() != b.fiscalStartMonth() {
+		return TimeInterval{}, ErrNotRepresentable
+	}
+	out := TimeInterval{Location: a.Location, Cycle: a.Cycle, FiscalYearStart: a.FiscalYearStart, DST: a.DST}
+	if out.Cycle == nil {
+		out.Cycle = b.Cycle
+	}
+	out.Holidays = a.Holidays
+	if out.Holidays == nil {
+		out.Holidays = b.Holidays
+	}
+	out.Calendar = a.Calendar
+	if out.Calendar == nil {
+		out.Calendar = b.Calendar
+	}
+	out.Expression = expression
+	if !a.usesFiscalYear() {
+		out.FiscalYearStart = b.FiscalYearStart
+	}
+	if quarters := intersectRanges(quarterInclusiveRanges(a.Quarters), quarterInclusiveRanges(b.Quarters)); quarters != nil {
+		out.Quarters = make([]QuarterRange, len(quarters))
+		for i, r := range quarters {
+			out.Quarters[i] = QuarterRange{r}
+		}
+	}
+	if a.WeekdaysOfMonth != nil {
+		out.WeekdaysOfMonth = a.WeekdaysOfMonth
+	} else {
+		out.WeekdaysOfMonth = b.WeekdaysOfMonth
+	}
+	out.Times = intersectTimeRanges(a.Times, b.Times)
+	out.Dates = intersectDateRanges(a.Dates, b.Dates)
+	out.Absolute = intersectAbsoluteRanges(a.Absolute, b.Absolute)
+	if weekdays := intersectRanges(weekdayInclusiveRanges(a.Weekdays), weekdayInclusiveRanges(b.Weekdays)); weekdays != nil {
+		out.Weekdays = make([]WeekdayRange, len(weekdays))
+		for i, r := range weekdays {
+			out.Weekdays[i] = WeekdayRange{r}
+		}
+	}
+	if months := intersectRanges(monthInclusiveRanges(a.Months), monthInclusiveRanges(b.Months)); months != nil {
+		out.Months = make([]MonthRange, len(months))
+		for i, r := range months {
+			out.Months[i] = MonthRange{r}
+		}
+	}
+	if relative := intersectRanges(relativeInclusiveRanges(a.Relative), relativeInclusiveRanges(b.Relative)); relative != nil {
+		out.Relative = make([]RelativeRange, len(relative))
+		for i, r := range relative {
+			out.Relative[i] = RelativeRange{feast, r}
+		}
+	}
+	if weeks := intersectRanges(weekInclusiveRanges(a.Weeks), weekInclusiveRanges(b.Weeks)); weeks != nil {
+		out.Weeks = make([]WeekRange, len(weeks))
+		for i, r := range weeks {
+			out.Weeks[i] = WeekRange{r}
+		}
+	}
+	if years := intersectRanges(yearInclusiveRanges(a.Years), yearInclusiveRanges(b.Years)); years != nil {
+		out.Years = make([]YearRange, len(years))
+		for i, r := range years {
+			out.Years[i] = YearRange{r}
+		}
+	}
+	switch {
+	case a.DaysOfMonth == nil:
+		out.DaysOfMonth = b.DaysOfMonth
+	case b.DaysOfMonth == nil:
+		out.DaysOfMonth = a.DaysOfMonth
+	default:
+		out.DaysOfMonth = []DayOfMonthRange{}
+		for _, ra := range a.DaysOfMonth {
+			for _, rb := range b.DaysOfMonth {
+				begin, ok := laterDayOfMonth(ra.Begin, rb.Begin)
+				if !ok {
+					return TimeInterval{}, ErrNotRepresentable
+				}
+				end, ok := earlierDayOfMonth(ra.End, rb.End)
+				if !ok {
+					return TimeInterval{}, ErrNotRepresentable
+				}
+				earliest, ok := earlierDayOfMonth(begin, end)
+				if !ok {
+					return TimeInterval{}, ErrNotRepresentable
+				}
+				if earliest != begin {
+					// The range is empty in every month.
+					continue
+				}
+				out.DaysOfMonth = append(out.DaysOfMonth, DayOfMonthRange{InclusiveRange{Begin: begin, End: end}})
+			}
+		}
+	}
+	return out, nil
+}
+
+// hasOvernightTimes returns true if any of the interval's time ranges wrap past midnight.
+func (tp TimeInterval) hasOvernightTimes() bool {
+	for _, tr := range tp.Times {
+		if tr.isOvernight() {
+			return true
+		}
+	}
+	return false
+}
+
+// locationName returns the name of the interval's location, or an empty string if it matches times in their own
+// location.
+func (tp TimeInterval) locationName() string {
+	if tp.Location == nil || tp.Location.Location == nil {
+		return ""
+	}
+	return tp.Location.String()
+}
+
+// intersectTimeRanges returns the overlaps between two sets of time ranges, where a nil set covers the whole day.
+func intersectTimeRanges(a, b []TimeRange) []TimeRange {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	out := []TimeRange{}
+	for _, ra := range a {
+		for _, rb := range b {
+			start, end := ra.startSecond(), ra.endSecond()
+			if rb.startSecond() > start {
+				start = rb.startSecond()
+			}
+			if rb.endSecond() < end {
+				end = rb.endSecond()
+			}
+			if start < end {
+				out = append(out, timeRangeFromSeconds(start, end))
+			}
+		}
+	}
+	return out
+}
+
+// intersectRanges returns the overlaps between two sets of inclusive ranges, where a nil set covers every value.
+func intersectRanges(a, b []InclusiveRange) []InclusiveRange {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	out := []InclusiveRange{}
+	for _, ra := range a {
+		for _, rb := range b {
+			begin, end := ra.Begin, ra.End
+			if rb.Begin > begin {
+				begin = rb.Begin
+			}
+			if rb.End < end {
+				end = rb.End
+			}
+			if begin <= end {
+				out = append(out, InclusiveRange{Begin: begin, End: end})
+			}
+		}
+	}
+	return out
+}
+
+// earlierDayOfMonth returns whichever of two days of the month comes first in every month, or false if that depends on
+// the length of the month.
+func earlierDayOfMonth(a, b int) (int, bool) {
+	if (a < 0) == (b < 0) {
+		if a < b {
+			return a, true
+		}
+		return b, true
+	}
+	positive, negative := a, b
+	if positive < 0 {
+		positive, negative = negative, positive
+	}
+	// Negative days resolve to somewhere between their position in a 28 day month and a 31 day month.
+	switch {
+	case positive <= 29+negative:
+		return positive, true
+	case positive >= 32+negative:
+		return negative, true
+	}
+	return 0, false
+}
+
+// laterDayOfMonth returns whichever of two days of the month comes last in every month, or false if that depends on
+// the length of the month.
+func laterDayOfMonth(a, b int) (int, bool) {
+	earlier, ok := earlierDayOfMonth(a, b)
+	if !ok {
+		return 0, false
+	}
+	if earlier == a {
+		return b, true
+	}
+	return a, true
+}
+
+// weekdayInclusiveRanges splits weekday ranges ending on ISO Sunday into two, so that they can be compared directly.
+func weekdayInclusiveRanges(ranges []WeekdayRange) []InclusiveRange {
+	if ranges == nil {
+		return nil
+	}
+	out := make([]InclusiveRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.End == 7 {
+			out = append(out, InclusiveRange{Begin: r.Begin, End: 6}, InclusiveRange{Begin: 0, End: 0})
+			continue
+		}
+		out = append(out, r.InclusiveRange)
+	}
+	return out
+}
+
+// monthInclusiveRanges splits month ranges that wrap the year into two, so that they can be compared directly.
+func monthInclusiveRanges(ranges []MonthRange) []InclusiveRange {
+	if ranges == nil {
+		return nil
+	}
+	out := make([]InclusiveRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Begin > r.End {
+			out = append(out, InclusiveRange{Begin: r.Begin, End: 12}, InclusiveRange{Begin: 1, End: r.End})
+			continue
+		}
+		out = append(out, r.InclusiveRange)
+	}
+	return out
+}
+
+func weekInclusiveRanges(ranges []WeekRange) []InclusiveRange {
+	if ranges == nil {
+		return nil
+	}
+	out := make([]InclusiveRange, len(ranges))
+	for i, r := range ranges {
+		out[i] = r.InclusiveRange
+	}
+	return out
+}
+
+func quarterInclusiveRanges(ranges []QuarterRange) []InclusiveRange {
+	if ranges == nil {
+		return nil
+	}
+	out := make([]InclusiveRange, len(ranges))
+	for i, r := range ranges {
+		out[i] = r.InclusiveRange
+	}
+	return out
+}
+
+func relativeInclusiveRanges(ranges []RelativeRange) []InclusiveRange {
+	if ranges == nil {
+		return nil
+	}
+	out := make([]InclusiveRange, len(ranges))
+	for i, r := range ranges {
+		out[i] = r.InclusiveRange
+	}
+	return out
+}
+
+// commonFeast returns the feast that every relative range of both intervals is counted from, or false if they count
+// from different feasts and so can't be intersected.
+func commonFeast(a, b []RelativeRange) (string, bool) {
+	feast := ""
+	for _, r := range append(append([]RelativeRange{}, a...), b...) {
+		if feast != "" && r.Feast != feast {
+			return "", false
+		}
+		feast = r.Feast
+	}
+	return feast, true
+}
+
+func yearInclusiveRanges(ranges []YearRange) []InclusiveRange {
+	if ranges == nil {
+		return nil
+	}
+	out := make([]InclusiveRange, len(ranges))
+	for i, r := range ranges {
+		out[i] = r.InclusiveRange
+	}
+	return out
+}
+
+// A Complement contains exactly the times that its matcher doesn't, e.g. everything outside of business hours.
+type Complement struct {
+	Matcher Matcher
+}
+
+// Not returns a Complement containing every time that the given matcher doesn't contain.
+func Not(m Matcher) Complement {
+	return Complement{Matcher: m}
+}
+
+// ContainsTime returns true if the complemented matcher does not contain the given time.
+func (c Complement) ContainsTime(t time.Time) bool {
+	return !c.Matcher.ContainsTime(t)
+}
+
+// NextTransition returns the earliest time after t at which the Complement either becomes active or stops being
+// active. These are the same points in time at which the complemented matcher changes state.
+func (c Complement) NextTransition(t time.Time) (time.Time, bool) {
+	return asTransitionMatcher(c.Matcher).NextTransition(t)
+}
+
+// PreviousTransition returns the latest time at or before t at which the Complement either became active or stopped
+// being active. These are the same points in time at which the complemented matcher changed state.
+func (c Complement) PreviousTransition(t time.Time) (time.Time, bool) {
+	return asTransitionMatcher(c.Matcher).PreviousTransition(t)
+}
+
+// NextActiveTime returns t if the Complement contains it, otherwise the time at which the complemented matcher next
+// stops being active. It returns false if that doesn't happen within the search horizon.
+func (c Complement) NextActiveTime(t time.Time) (time.Time, bool) {
+	return nextActiveTime(c, t)
+}
+
+// Windows returns the windows between from and to during which the complemented matcher is not active, in
+// chronological order and clipped to the bounds.
+func (c Complement) Windows(from, to time.Time) []Window {
+	return transitionWindows(c, from, to)
+}
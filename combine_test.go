@@ -0,0 +1,204 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+)
+
+var intersectTestCases = []struct {
+	a, b         TimeInterval
+	intersection TimeInterval
+	expectError  bool
+	contains     []string
+	excludes     []string
+}{
+	{
+		// Business hours and not December
+		a: businessHours,
+		b: TimeInterval{
+			Months: []MonthRange{{InclusiveRange{Begin: 1, End: 11}}},
+		},
+		intersection: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Months:   []MonthRange{{InclusiveRange{Begin: 1, End: 11}}},
+		},
+		contains: []string{"2020-07-08T10:00:00Z"},
+		excludes: []string{"2020-12-08T10:00:00Z", "2020-07-08T18:00:00Z"},
+	},
+	{
+		// Winter, not including December
+		a: TimeInterval{Months: []MonthRange{{InclusiveRange{Begin: 11, End: 2}}}},
+		b: TimeInterval{Months: []MonthRange{{InclusiveRange{Begin: 1, End: 11}}}},
+		intersection: TimeInterval{
+			Months: []MonthRange{{InclusiveRange{Begin: 11, End: 11}}, {InclusiveRange{Begin: 1, End: 2}}},
+		},
+		contains: []string{"2020-11-08T10:00:00Z", "2021-02-08T10:00:00Z"},
+		excludes: []string{"2020-12-08T10:00:00Z", "2021-03-08T10:00:00Z"},
+	},
+	{
+		// A shared DST policy is kept, so times skipped by clocks going forward are still moved after the gap
+		a: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 90, EndMinute: 105}},
+			Location: mustLoadLocation("Europe/London"),
+			DST:      DSTPolicy{Gap: GapShift},
+		},
+		b: TimeInterval{
+			Months:   []MonthRange{{InclusiveRange{Begin: 3, End: 3}}},
+			Location: mustLoadLocation("Europe/London"),
+			DST:      DSTPolicy{Gap: GapShift},
+		},
+		intersection: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 90, EndMinute: 105}},
+			Months:   []MonthRange{{InclusiveRange{Begin: 3, End: 3}}},
+			Location: mustLoadLocation("Europe/London"),
+			DST:      DSTPolicy{Gap: GapShift},
+		},
+		contains: []string{"2024-03-31T01:35:00Z", "2024-03-30T01:35:00Z"},
+		excludes: []string{"2024-03-31T00:35:00Z", "2024-04-30T00:35:00Z"},
+	},
+	{
+		// Different DST policies can't be combined
+		a:           TimeInterval{Times: []TimeRange{{StartMinute: 90, EndMinute: 105}}, DST: DSTPolicy{Gap: GapShift}},
+		b:           TimeInterval{Months: []MonthRange{{InclusiveRange{Begin: 3, End: 3}}}},
+		expectError: true,
+	},
+	{
+		// ISO weekend with weekends starting on Sunday
+		a: TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 7}}}},
+		b: TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}}},
+		intersection: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}},
+		},
+		contains: []string{"2020-07-12T10:00:00Z"},
+		excludes: []string{"2020-07-11T10:00:00Z"},
+	},
+	{
+		// Overlapping times and weekdays
+		a: businessHours,
+		b: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 0, EndMinute: 600}, {StartMinute: 960, EndMinute: 1440}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}},
+		},
+		intersection: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 600}, {StartMinute: 960, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 5}}},
+		},
+		contains: []string{"2020-07-10T09:30:00Z", "2020-07-10T16:30:00Z"},
+		excludes: []string{"2020-07-10T12:00:00Z", "2020-07-09T09:30:00Z"},
+	},
+	{
+		// Disjoint years produce an empty interval
+		a: TimeInterval{Years: []YearRange{{InclusiveRange{Begin: 2020, End: 2021}}}},
+		b: TimeInterval{Years: []YearRange{{InclusiveRange{Begin: 2022, End: 2023}}}},
+		intersection: TimeInterval{
+			Years: []YearRange{},
+		},
+		excludes: []string{"2020-07-10T12:00:00Z", "2022-07-10T12:00:00Z"},
+	},
+	{
+		// First and last weeks of the month, where both ends are unambiguous
+		a: TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: -1}}}},
+		b: TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 7}}, {InclusiveRange{Begin: -7, End: -1}}}},
+		intersection: TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 7}}, {InclusiveRange{Begin: -7, End: -1}}},
+		},
+		contains: []string{"2020-07-03T12:00:00Z", "2020-07-30T12:00:00Z"},
+		excludes: []string{"2020-07-15T12:00:00Z"},
+	},
+	{
+		// The second last day of the month is never after the 30th
+		a: TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 30}}}},
+		b: TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: -2}}}},
+		intersection: TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: -2}}},
+		},
+		contains: []string{"2020-07-30T12:00:00Z", "2020-02-28T12:00:00Z"},
+		excludes: []string{"2020-06-30T12:00:00Z", "2020-07-31T12:00:00Z"},
+	},
+	{
+		// Whether the 29th comes before the second last day depends on the month
+		a:           TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 29}}}},
+		b:           TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: -2}}}},
+		expectError: true,
+		contains:    []string{"2020-07-29T12:00:00Z", "2020-02-28T12:00:00Z"},
+		excludes:    []string{"2020-02-29T12:00:00Z", "2020-07-30T12:00:00Z"},
+	},
+	{
+		// Overnight ranges depend on the previous day
+		a: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 5}}},
+		},
+		b:           TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}}},
+		expectError: true,
+		contains:    []string{"2020-07-11T03:00:00Z"},
+		excludes:    []string{"2020-07-10T23:00:00Z", "2020-07-11T23:00:00Z"},
+	},
+}
+
+func TestIntersect(t *testing.T) {
+	for _, tc := range intersectTestCases {
+		got, err := Intersect(tc.a, tc.b)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error intersecting %+v and %+v: %v", tc.a, tc.b, err)
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error intersecting %+v and %+v but didn't receive one", tc.a, tc.b)
+		} else if err == nil && !reflect.DeepEqual(got, tc.intersection) {
+			t.Errorf("Intersecting %+v and %+v: want %+v, got %+v", tc.a, tc.b, tc.intersection, got)
+		}
+		// Intersections must agree with the interval when one could be produced
+		matchers := []Intersection{{tc.a, tc.b}}
+		if err == nil {
+			matchers = append(matchers, Intersection{got})
+		}
+		for _, m := range matchers {
+			for _, ts := range tc.contains {
+				if !m.ContainsTime(mustParseTime(ts)) {
+					t.Errorf("Expected %+v to contain %s", m, ts)
+				}
+			}
+			for _, ts := range tc.excludes {
+				if m.ContainsTime(mustParseTime(ts)) {
+					t.Errorf("Expected %+v to exclude %s", m, ts)
+				}
+			}
+		}
+	}
+}
+
+func TestComplement(t *testing.T) {
+	outsideBusinessHours := Not(businessHours)
+	for _, ts := range []string{"2020-07-08T08:59:00Z", "2020-07-08T17:00:00Z", "2020-07-11T12:00:00Z"} {
+		if !outsideBusinessHours.ContainsTime(mustParseTime(ts)) {
+			t.Errorf("Expected %+v to contain %s", outsideBusinessHours, ts)
+		}
+	}
+	for _, ts := range []string{"2020-07-08T09:00:00Z", "2020-07-08T16:59:00Z"} {
+		if outsideBusinessHours.ContainsTime(mustParseTime(ts)) {
+			t.Errorf("Expected %+v to exclude %s", outsideBusinessHours, ts)
+		}
+	}
+
+	at := mustParseTime("2020-07-10T12:00:00Z")
+	next, ok := outsideBusinessHours.NextTransition(at)
+	checkTransition(t, "NextTransition", outsideBusinessHours, "2020-07-10T17:00:00Z", next, ok)
+	previous, ok := outsideBusinessHours.PreviousTransition(at)
+	checkTransition(t, "PreviousTransition", outsideBusinessHours, "2020-07-10T09:00:00Z", previous, ok)
+	nextActive, ok := outsideBusinessHours.NextActiveTime(at)
+	checkTransition(t, "NextActiveTime", outsideBusinessHours, "2020-07-10T17:00:00Z", nextActive, ok)
+
+	want := []Window{
+		{Start: mustParseTime("2020-07-10T17:00:00Z"), End: mustParseTime("2020-07-13T09:00:00Z")},
+		{Start: mustParseTime("2020-07-13T17:00:00Z"), End: mustParseTime("2020-07-13T18:00:00Z")},
+	}
+	if got := outsideBusinessHours.Windows(at, mustParseTime("2020-07-13T18:00:00Z")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows for %+v: want %v, got %v", outsideBusinessHours, want, got)
+	}
+
+	// The complement of an interval that is always active is never active
+	never := Not(TimeInterval{})
+	if _, ok := never.NextActiveTime(at); ok {
+		t.Errorf("Expected %+v to never become active", never)
+	}
+}
@@ -0,0 +1,213 @@
+package gotime
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// minSteppedRun is the fewest evenly spaced single values that are written as a stepped range when marshalling.
+const minSteppedRun = 3
+
+// yamlTimeInterval is the form in which a TimeInterval is marshalled, with its lists of ranges written as the compact
+// strings they can be given in.
+type yamlTimeInterval struct {
+	Name            string            `yaml:"name,omitempty"`
+	Description     string            `yaml:"description,omitempty"`
+	Labels          map[string]string `yaml:"labels,omitempty"`
+	Mode            Mode              `yaml:"mode,omitempty"`
+	Times           interface{}       `yaml:"times,omitempty"`
+	Weekdays        []interface{}     `yaml:"weekdays,flow,omitempty"`
+	DaysOfMonth     []interface{}     `yaml:"days_of_month,flow,omitempty"`
+	Weeks           []interface{}     `yaml:"weeks,flow,omitempty"`
+	Months          []interface{}     `yaml:"months,flow,omitempty"`
+	Quarters        []interface{}     `yaml:"quarters,flow,omitempty"`
+	Years           []interface{}     `yaml:"years,flow,omitempty"`
+	Relative        interface{}       `yaml:"relative,flow,omitempty"`
+	Dates           interface{}       `yaml:"dates,flow,omitempty"`
+	Absolute        interface{}       `yaml:"absolute,omitempty"`
+	FiscalYearStart FiscalYearStart   `yaml:"fiscal_year_start,omitempty"`
+	Cycle           *Cycle            `yaml:"cycle,omitempty"`
+	Calendar        *CalendarDates    `yaml:"calendar,omitempty"`
+	Holidays        *Holidays         `yaml:"holidays,omitempty"`
+	Expression      *Expression       `yaml:"expression,omitempty"`
+	Location        *Location         `yaml:"location,omitempty"`
+	Coordinates     *Coordinates      `yaml:"coordinates,omitempty"`
+	DST             DSTPolicy         `yaml:"dst,omitempty"`
+	Except          []TimeInterval    `yaml:"except,omitempty"`
+}
+
+// compact returns the interval in the form it is marshalled in. Weekdays of the month are written among the days of
+// the month, where they are usually given, and runs of evenly spaced single values are written as stepped ranges, so
+// that days_of_month: ['1:31/2'] is marshalled as it was written rather than as sixteen days. Empty lists, which match
+// nothing, are written as ['never'], since omitting them would leave a field that matches everything. Unmarshalling
+// the result gives back the same interval.
+func (tp TimeInterval) compact() (yamlTimeInterval, error) {
+	out := yamlTimeInterval{
+		Name:            tp.Name,
+		Description:     tp.Description,
+		Labels:          tp.Labels,
+		Mode:            tp.Mode,
+		FiscalYearStart: tp.FiscalYearStart,
+		Cycle:           tp.Cycle,
+		Calendar:        tp.Calendar,
+		Holidays:        tp.Holidays,
+		Expression:      tp.Expression,
+		Location:        tp.Location,
+		Coordinates:     tp.Coordinates,
+		DST:             tp.DST,
+		Except:          tp.Except,
+	}
+	weekdayName := func(v int) (string, bool) {
+		name, ok := daysOfWeekInv[v]
+		return name, ok
+	}
+	monthName := func(v int) (string, bool) {
+		name, ok := monthsInv[v]
+		return name, ok
+	}
+	// A nil slice mustn't be stored in an interface field, or omitempty won't drop it.
+	if tp.Times != nil {
+		out.Times = tp.Times
+	}
+	if tp.Relative != nil {
+		out.Relative = tp.Relative
+	}
+	if tp.Dates != nil {
+		out.Dates = tp.Dates
+	}
+	if tp.Absolute != nil {
+		// Absolute ranges can't be given as keywords, but an explicit empty list is kept by omitempty.
+		out.Absolute = tp.Absolute
+	}
+	var err error
+	if out.Weekdays, err = compactRanges(len(tp.Weekdays), func(i int) stepRange { return tp.Weekdays[i] }, weekdayName); err != nil {
+		return out, err
+	}
+	if out.DaysOfMonth, err = compactRanges(len(tp.DaysOfMonth), func(i int) stepRange { return tp.DaysOfMonth[i] }, nil); err != nil {
+		return out, err
+	}
+	for _, w := range tp.WeekdaysOfMonth {
+		v, err := w.MarshalYAML()
+		if err != nil {
+			return out, err
+		}
+		out.DaysOfMonth = append(out.DaysOfMonth, v)
+	}
+	if out.Weeks, err = compactRanges(len(tp.Weeks), func(i int) stepRange { return tp.Weeks[i] }, nil); err != nil {
+		return out, err
+	}
+	if out.Months, err = compactRanges(len(tp.Months), func(i int) stepRange { return tp.Months[i] }, monthName); err != nil {
+		return out, err
+	}
+	if out.Quarters, err = compactRanges(len(tp.Quarters), func(i int) stepRange { return tp.Quarters[i] }, nil); err != nil {
+		return out, err
+	}
+	if out.Years, err = compactRanges(len(tp.Years), func(i int) stepRange { return tp.Years[i] }, nil); err != nil {
+		return out, err
+	}
+	never := []interface{}{"never"}
+	for _, f := range []struct {
+		set   bool
+		n     int
+		field *interface{}
+	}{
+		{tp.Times != nil, len(tp.Times), &out.Times},
+		{tp.Relative != nil, len(tp.Relative), &out.Relative},
+		{tp.Dates != nil, len(tp.Dates), &out.Dates},
+	} {
+		if f.set && f.n == 0 {
+			*f.field = never
+		}
+	}
+	for _, f := range []struct {
+		set   bool
+		n     int
+		field *[]interface{}
+	}{
+		{tp.Weekdays != nil, len(tp.Weekdays), &out.Weekdays},
+		{tp.DaysOfMonth != nil || tp.WeekdaysOfMonth != nil, len(tp.DaysOfMonth) + len(tp.WeekdaysOfMonth),
+			&out.DaysOfMonth},
+		{tp.Weeks != nil, len(tp.Weeks), &out.Weeks},
+		{tp.Months != nil, len(tp.Months), &out.Months},
+		{tp.Quarters != nil, len(tp.Quarters), &out.Quarters},
+		{tp.Years != nil, len(tp.Years), &out.Years},
+	} {
+		if f.set && f.n == 0 {
+			*f.field = never
+		}
+	}
+	return out, nil
+}
+
+// A stepRange is a range that can be marshalled on its own and may be part of a stepped run of single values.
+type stepRange interface {
+	MarshalYAML() (interface{}, error)
+	bounds() (int, int)
+}
+
+func (ir InclusiveRange) bounds() (int, int) {
+	return ir.Begin, ir.End
+}
+
+// compactRanges returns the marshalled form of n ranges, writing runs of evenly spaced single values as stepped ranges.
+// name gives the name of a value when the field is written with names, and reports false for values that have none.
+func compactRanges(n int, at func(i int) stepRange, name func(v int) (string, bool)) ([]interface{}, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	format := func(v int) (string, bool) {
+		if name == nil {
+			return strconv.Itoa(v), true
+		}
+		return name(v)
+	}
+	out := make([]interface{}, 0, n)
+	for i := 0; i < n; {
+		if run := steppedRun(n, at, i); run >= minSteppedRun {
+			first, _ := at(i).bounds()
+			second, _ := at(i + 1).bounds()
+			last, _ := at(i + run - 1).bounds()
+			begin, beginOK := format(first)
+			end, endOK := format(last)
+			if beginOK && endOK && (first < 0) == (last < 0) {
+				out = append(out, fmt.Sprintf("%s:%s/%d", begin, end, second-first))
+				i += run
+				continue
+			}
+		}
+		v, err := at(i).MarshalYAML()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+		i++
+	}
+	return out, nil
+}
+
+// steppedRun returns how many ranges from index i onwards are single values that increase by the same step of at
+// least two, as a stepped range would be expanded into.
+func steppedRun(n int, at func(i int) stepRange, i int) int {
+	single := func(j int) (int, bool) {
+		begin, end := at(j).bounds()
+		return begin, begin == end
+	}
+	first, ok := single(i)
+	if !ok || i+1 >= n {
+		return 1
+	}
+	second, ok := single(i + 1)
+	step := second - first
+	if !ok || step < 2 {
+		return 1
+	}
+	run, prev := 2, second
+	for j := i + 2; j < n; j++ {
+		v, ok := single(j)
+		if !ok || v-prev != step {
+			break
+		}
+		run, prev = run+1, v
+	}
+	return run
+}
@@ -0,0 +1,157 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var compactTestCases = []struct {
+	in  string
+	out string
+}{
+	{
+		in:  "days_of_month: ['-7:-1', '1:31/2', '2nd tuesday']",
+		out: "days_of_month: ['-7:-1', '1:31/2', 2nd tuesday]\n",
+	},
+	{
+		in:  "days_of_month: ['last friday']",
+		out: "days_of_month: [last friday]\n",
+	},
+	{
+		in:  "{weekdays: ['mon:fri/2'], months: ['jan:dec/3'], years: ['2020:2030/5']}",
+		out: "weekdays: ['monday:friday/2']\nmonths: ['january:october/3']\nyears: ['2020:2030/5']\n",
+	},
+	{
+		// Steps that don't land on the end of the range are written up to the last value they reach.
+		in:  "{days_of_month: ['-30:-1/10'], weeks: ['1:10/4', '20']}",
+		out: "days_of_month: ['-30:-10/10']\nweeks: ['1:9/4', \"20\"]\n",
+	},
+	{
+		// Two values, and values one apart, are left as they are.
+		in:  "{weekdays: ['monday', 'wednesday', 'friday:saturday'], quarters: ['1', '2', '3']}",
+		out: "weekdays: [monday, wednesday, 'friday:saturday']\nquarters: [\"1\", \"2\", \"3\"]\n",
+	},
+	{
+		in:  "except: [{days_of_month: ['1:7', '2:28/13']}]",
+		out: "except:\n- days_of_month: [\"1:7\", '2:28/13']\n",
+	},
+	{
+		in:  "except: [{days_of_month: ['1:28/9']}]",
+		out: "except:\n- days_of_month: ['1:28/9']\n",
+	},
+	{
+		// Empty lists match nothing, so must be written rather than left out.
+		in:  "{weekdays: [], times: ['never'], absolute: []}",
+		out: "times:\n- never\nweekdays: [never]\nabsolute: []\n",
+	},
+}
+
+// neverActiveTestCases are intervals that are never active, which each encoding must keep that way.
+var neverActiveTestCases = []string{
+	"times: []",
+	"weekdays: []",
+	"days_of_month: ['never']",
+	"weekdays_of_month: []",
+	"weeks: []",
+	"months: ['never']",
+	"quarters: []",
+	"years: []",
+	"relative: []",
+	"dates: []",
+	"absolute: []",
+}
+
+// checkNeverActive round trips each of neverActiveTestCases through an encoding, and checks that none of them
+// becomes active.
+func checkNeverActive(t *testing.T, encode func(TimeInterval) ([]byte, error),
+	decode func(*TimeInterval, []byte) error) {
+	t.Helper()
+	for _, in := range neverActiveTestCases {
+		var ti TimeInterval
+		if err := yaml.Unmarshal([]byte(in), &ti); err != nil {
+			t.Fatalf("Received unexpected error: %v when parsing %s", err, in)
+		}
+		data, err := encode(ti)
+		if err != nil {
+			t.Errorf("Received unexpected error: %v when encoding %s", err, in)
+			continue
+		}
+		var ti2 TimeInterval
+		if err := decode(&ti2, data); err != nil {
+			t.Errorf("Received unexpected error: %v when decoding %s", err, in)
+			continue
+		}
+		times := []time.Time{{}, date(2021, time.March, 3), time.Date(2030, 12, 31, 23, 59, 0, 0, time.UTC)}
+		for _, tm := range times {
+			if ti2.ContainsTime(tm) {
+				t.Errorf("Decoding %s produced an interval that contains %v: %q", in, tm, data)
+			}
+		}
+	}
+}
+
+func TestCompactMarshal(t *testing.T) {
+	for _, tc := range compactTestCases {
+		var ti TimeInterval
+		if err := yaml.Unmarshal([]byte(tc.in), &ti); err != nil {
+			t.Fatalf("Received unexpected error: %v when parsing %s", err, tc.in)
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != tc.out {
+			t.Errorf("Marshalling %s: want %q, got %q", tc.in, tc.out, out)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+		if out2, err := yaml.Marshal(ti2); err != nil || string(out2) != string(out) {
+			t.Errorf("Re-marshalling %s wasn't stable: %q then %q", tc.in, out, out2)
+		}
+	}
+}
+
+func TestCompactRoundTrip(t *testing.T) {
+	// Every interval the other tests parse should marshal to output that is stable when parsed and marshalled again.
+	var sets []IntervalSet
+	for _, tc := range yamlUnmarshalTestCases {
+		if !tc.expectError {
+			sets = append(sets, tc.intervals)
+		}
+	}
+	for _, in := range protoTestCases {
+		var ti TimeInterval
+		if err := yaml.Unmarshal([]byte(in), &ti); err != nil {
+			t.Fatalf("Received unexpected error: %v when parsing %s", err, in)
+		}
+		sets = append(sets, IntervalSet{ti})
+	}
+	for _, set := range sets {
+		out, err := yaml.Marshal(set)
+		if err != nil {
+			t.Errorf("Received unexpected error: %v when marshalling %+v", err, set)
+			continue
+		}
+		var set2 IntervalSet
+		if err := yaml.Unmarshal(out, &set2); err != nil {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, out)
+			continue
+		}
+		if out2, err := yaml.Marshal(set2); err != nil || string(out2) != string(out) {
+			t.Errorf("Re-marshalling %+v wasn't stable: %q then %q", set, out, out2)
+		}
+	}
+}
+
+func TestCompactNeverActive(t *testing.T) {
+	checkNeverActive(t, func(ti TimeInterval) ([]byte, error) {
+		return yaml.Marshal(ti)
+	}, func(ti *TimeInterval, data []byte) error {
+		return yaml.Unmarshal(data, ti)
+	})
+}
@@ -0,0 +1,444 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Compile checks that the interval is valid and returns an immutable Matcher for it, with the lookups that
+// ContainsTime needs worked out once rather than on every call. It suits hot paths that check the same interval many
+// times. The interval is copied, so changing it afterwards has no effect on the Matcher, although fields held by
+// pointer, such as Cycle and Holidays, are shared. The Matcher is also a TransitionMatcher, and is safe for concurrent
+// use.
+//
+// Times, weekdays, days and weekdays of the month, weeks, months, quarters, years and locations are compiled into
+// lookups. Intervals with other fields are still validated and copied, but are matched as they would be by
+// TimeInterval. Intervals with only times on whole minutes and weekdays, such as business hours, and exceptions of
+// the same kind, are compiled into a bitmap of the minutes of the week, so that matching a time is a single lookup.
+func (tp TimeInterval) Compile() (Matcher, error) {
+	if err := tp.validate(); err != nil {
+		return nil, err
+	}
+	return compileInterval(tp.clone()), nil
+}
+
+// A compiledInterval is the result of TimeInterval.Compile.
+type compiledInterval struct {
+	interval TimeInterval
+	// fast is true if every field of the interval is compiled. Otherwise the interval is matched directly.
+	fast bool
+	loc  *time.Location
+	// spans are the merged periods of each day covered by time ranges starting that day, in seconds of the day, and
+	// carried is the second of the day until which overnight ranges from the day before cover. Neither is used if
+	// anyTime is set.
+	anyTime bool
+	spans   []secondSpan
+	carried int
+	// weekdays, months, weeks and quarters are bitmasks of the values matched, which match every value if the field
+	// is nil.
+	weekdays uint8
+	months   uint16
+	weeks    uint64
+	quarters uint8
+	// days holds a bitmask of the days of the month matched by DaysOfMonth for months of 28 to 31 days.
+	days            *[4]uint32
+	weekdaysOfMonth []WeekdayOfMonth
+	years           []YearRange
+	except          []*compiledInterval
+	// week, if set, holds a bit for each minute of the week from midnight on Sunday that the interval contains, and
+	// replaces all of the fields above.
+	week *weekBitmap
+}
+
+// minutesPerWeek is the number of minutes in a week without clock changes.
+const minutesPerWeek = 7 * secondsPerDay / 60
+
+// A weekBitmap is a bitmap of the minutes of a week.
+type weekBitmap [(minutesPerWeek + 63) / 64]uint64
+
+func (b *weekBitmap) set(minute int) {
+	b[minute/64] |= 1 << uint(minute%64)
+}
+
+func (b *weekBitmap) contains(minute int) bool {
+	return b[minute/64]&(1<<uint(minute%64)) != 0
+}
+
+// A secondSpan is a period of a day from start up to end, in seconds of the day.
+type secondSpan struct {
+	start, end int
+}
+
+// Bitmasks matching every day of the week, month, ISO week and quarter, which are numbered from 0, 1, 1 and 1.
+const (
+	allWeekdays = 1<<7 - 1
+	allMonths   = 1<<13 - 2
+	allWeeks    = 1<<54 - 2
+	allQuarters = 1<<5 - 2
+)
+
+func compileInterval(tp TimeInterval) *compiledInterval {
+	c := &compiledInterval{
+		interval:        tp,
+		fast:            tp.compilable(),
+		anyTime:         tp.Times == nil,
+		weekdays:        allWeekdays,
+		months:          allMonths,
+		weeks:           allWeeks,
+		quarters:        allQuarters,
+		weekdaysOfMonth: tp.WeekdaysOfMonth,
+		years:           tp.Years,
+	}
+	if tp.Location != nil {
+		c.loc = tp.Location.Location
+	}
+	if !c.fast {
+		return c
+	}
+	for _, ex := range tp.Except {
+		if ex.Coordinates == nil {
+			ex.Coordinates = tp.Coordinates
+		}
+		c.except = append(c.except, compileInterval(ex))
+	}
+	for _, tr := range tp.Times {
+		if tr.isOvernight() {
+			c.spans = append(c.spans, secondSpan{tr.startSecond(), secondsPerDay})
+			if tr.endSecond() > c.carried {
+				c.carried = tr.endSecond()
+			}
+		} else {
+			c.spans = append(c.spans, secondSpan{tr.startSecond(), tr.endSecond()})
+		}
+	}
+	c.spans = mergeSpans(c.spans)
+	if tp.Weekdays != nil {
+		c.weekdays = 0
+		for _, r := range tp.Weekdays {
+			for wd := time.Sunday; wd <= time.Saturday; wd++ {
+				if r.containsWeekday(wd) {
+					c.weekdays |= 1 << uint(wd)
+				}
+			}
+		}
+	}
+	if tp.Months != nil {
+		c.months = 0
+		for _, r := range tp.Months {
+			for m := time.January; m <= time.December; m++ {
+				if r.containsMonth(m) {
+					c.months |= 1 << uint(m)
+				}
+			}
+		}
+	}
+	if tp.Weeks != nil {
+		c.weeks = 0
+		for _, r := range tp.Weeks {
+			for w := r.Begin; w <= r.End; w++ {
+				c.weeks |= 1 << uint(w)
+			}
+		}
+	}
+	if tp.Quarters != nil {
+		c.quarters = 0
+		for _, r := range tp.Quarters {
+			for q := r.Begin; q <= r.End; q++ {
+				c.quarters |= 1 << uint(q)
+			}
+		}
+	}
+	if tp.DaysOfMonth != nil || tp.WeekdaysOfMonth != nil {
+		c.days = new([4]uint32)
+		for i := range c.days {
+			c.days[i] = dayOfMonthMask(tp.DaysOfMonth, 28+i)
+		}
+	}
+	if c.weekly(c.loc) {
+		week := new(weekBitmap)
+		for minute := 0; minute < minutesPerWeek; minute++ {
+			if c.containsMinuteOfWeek(minute) {
+				week.set(minute)
+			}
+		}
+		c.week = week
+	}
+	return c
+}
+
+// weekly returns true if whether the compiled interval contains a time depends only on the minute of the week in the
+// given location, which is the case when it has at most times of day on whole minutes and weekdays, and the same is
+// true of its exceptions.
+func (c *compiledInterval) weekly(loc *time.Location) bool {
+	tp := c.interval
+	if !c.fast || (c.loc != nil && c.loc != loc) || tp.DaysOfMonth != nil || tp.WeekdaysOfMonth != nil ||
+		tp.Months != nil || tp.Weeks != nil || tp.Quarters != nil || tp.Years != nil {
+		return false
+	}
+	for _, tr := range tp.Times {
+		if tr.hasSeconds() {
+			return false
+		}
+	}
+	for _, ex := range c.except {
+		if !ex.weekly(loc) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsMinuteOfWeek returns true if a weekly compiled interval contains the given minute of the week from midnight
+// on Sunday.
+func (c *compiledInterval) containsMinuteOfWeek(minute int) bool {
+	if c.week != nil {
+		return c.week.contains(minute)
+	}
+	day, second := minute/(secondsPerDay/60), minute%(secondsPerDay/60)*60
+	yesterday := (day + 6) % 7
+	if !(c.containsSecond(second) && c.weekdays&(1<<uint(day)) != 0) &&
+		!(second < c.carried && c.weekdays&(1<<uint(yesterday)) != 0) {
+		return false
+	}
+	for _, ex := range c.except {
+		if ex.containsMinuteOfWeek(minute) {
+			return false
+		}
+	}
+	return true
+}
+
+// compilable returns true if every field of the interval that affects which times it contains can be compiled.
+func (tp TimeInterval) compilable() bool {
+	return tp.Relative == nil && tp.Dates == nil && tp.Absolute == nil && tp.Cycle == nil && tp.Calendar == nil &&
+		tp.Holidays == nil && tp.Expression == nil && !tp.hasSolarTimes() && tp.DST == DSTPolicy{}
+}
+
+// mergeSpans sorts spans and merges those that overlap or touch.
+func mergeSpans(spans []secondSpan) []secondSpan {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var merged []secondSpan
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			if s.end > merged[n-1].end {
+				merged[n-1].end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// dayOfMonthMask returns a bitmask of the days matched by the ranges in a month of the given length, following
+// TimeInterval.containsDay.
+func dayOfMonthMask(ranges []DayOfMonthRange, days int) uint32 {
+	var mask uint32
+	for _, r := range ranges {
+		begin, end := r.Begin, r.End
+		if begin < 0 {
+			begin = days + begin + 1
+		}
+		if end < 0 {
+			end = days + end + 1
+		}
+		begin = clamp(begin, -1*days, days)
+		end = clamp(end, -1*days, days)
+		for d := 1; d <= days; d++ {
+			if d >= begin && d <= end {
+				mask |= 1 << uint(d)
+			}
+		}
+	}
+	return mask
+}
+
+// ContainsTime returns true if the compiled interval contains the given time, otherwise returns false.
+func (c *compiledInterval) ContainsTime(t time.Time) bool {
+	if !c.fast {
+		return c.interval.ContainsTime(t)
+	}
+	if c.loc != nil {
+		t = t.In(c.loc)
+	}
+	if c.week != nil {
+		hour, minute, _ := t.Clock()
+		return c.week.contains(int(t.Weekday())*(secondsPerDay/60) + hour*60 + minute)
+	}
+	second := secondOfDay(t)
+	// Overnight ranges carry over from the previous day, so only that day needs to match.
+	if !(c.containsSecond(second) && c.containsDay(t)) &&
+		!(second < c.carried && c.containsDay(t.AddDate(0, 0, -1))) {
+		return false
+	}
+	for _, ex := range c.except {
+		if ex.ContainsTime(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// NextTransition returns the earliest time after t at which the compiled interval either becomes active or stops
+// being active, as TimeInterval.NextTransition does.
+func (c *compiledInterval) NextTransition(t time.Time) (time.Time, bool) {
+	return c.interval.NextTransition(t)
+}
+
+// PreviousTransition returns the latest time at or before t at which the compiled interval either became active or
+// stopped being active, as TimeInterval.PreviousTransition does.
+func (c *compiledInterval) PreviousTransition(t time.Time) (time.Time, bool) {
+	return c.interval.PreviousTransition(t)
+}
+
+// containsSecond returns true if the given second of the day falls within one of the time ranges that started on the
+// same day.
+func (c *compiledInterval) containsSecond(second int) bool {
+	if c.anyTime {
+		return true
+	}
+	for _, s := range c.spans {
+		if second < s.start {
+			return false
+		}
+		if second < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// containsDay returns true if the calendar day of the given time, already in the interval's location, satisfies
+// every day-level field of the interval.
+func (c *compiledInterval) containsDay(t time.Time) bool {
+	year, month, day := t.Date()
+	if c.weekdays&(1<<uint(t.Weekday())) == 0 || c.months&(1<<uint(month)) == 0 {
+		return false
+	}
+	if c.days != nil {
+		length := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		in := c.days[length-28]&(1<<uint(day)) != 0
+		for i := 0; !in && i < len(c.weekdaysOfMonth); i++ {
+			in = c.weekdaysOfMonth[i].containsDay(t)
+		}
+		if !in {
+			return false
+		}
+	}
+	if c.weeks != allWeeks {
+		if _, week := t.ISOWeek(); c.weeks&(1<<uint(week)) == 0 {
+			return false
+		}
+	}
+	if c.quarters != allQuarters && c.quarters&(1<<uint(c.interval.quarter(t))) == 0 {
+		return false
+	}
+	if c.years != nil {
+		in := false
+		y := c.interval.year(t)
+		for _, r := range c.years {
+			if y >= r.Begin && y <= r.End {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a copy of the interval that shares no slices or maps with it.
+func (tp TimeInterval) clone() TimeInterval {
+	v := reflect.ValueOf(&tp).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() == reflect.Slice && !f.IsNil() {
+			copied := reflect.MakeSlice(f.Type(), f.Len(), f.Len())
+			reflect.Copy(copied, f)
+			f.Set(copied)
+		}
+	}
+	if tp.Labels != nil {
+		labels := make(map[string]string, len(tp.Labels))
+		for k, v := range tp.Labels {
+			labels[k] = v
+		}
+		tp.Labels = labels
+	}
+	for i, ex := range tp.Except {
+		tp.Except[i] = ex.clone()
+	}
+	return tp
+}
+
+// validate returns an error if any of the interval's fields are out of range, as they would be rejected when
+// unmarshalling it.
+func (tp TimeInterval) validate() error {
+	for _, tr := range tp.Times {
+		if tr.StartSolar == nil && (tr.startSecond() < 0 || tr.startSecond() >= secondsPerDay) {
+			return errors.New("Start time out of range")
+		}
+		if tr.EndSolar == nil && (tr.endSecond() < 0 || tr.endSecond() > secondsPerDay) {
+			return errors.New("End time out of range")
+		}
+		if tr.StartSolar == nil && tr.EndSolar == nil && tr.startSecond() == tr.endSecond() {
+			return errors.New("Start time cannot be equal to End time")
+		}
+	}
+	if tp.hasSolarTimes() && tp.Coordinates == nil {
+		return errNoCoordinates
+	}
+	for _, r := range tp.Weekdays {
+		if r.Begin < 0 || r.Begin > 6 {
+			return fmt.Errorf("%d is not a valid day of the week: out of range", r.Begin)
+		}
+		if r.End < 0 || r.End > 7 {
+			return fmt.Errorf("%d is not a valid day of the week: out of range", r.End)
+		}
+		if r.Begin > r.End {
+			return errors.New("Start day cannot be before End day")
+		}
+	}
+	for _, r := range tp.DaysOfMonth {
+		if r.Begin == 0 || r.Begin < -31 || r.Begin > 31 {
+			return fmt.Errorf("%d is not a valid day of the month: out of range", r.Begin)
+		}
+		if r.End == 0 || r.End < -31 || r.End > 31 {
+			return fmt.Errorf("%d is not a valid day of the month: out of range", r.End)
+		}
+	}
+	for _, r := range tp.Months {
+		if r.Begin < 1 || r.Begin > 12 || r.End < 1 || r.End > 12 {
+			return fmt.Errorf("%d:%d is not a valid month: out of range", r.Begin, r.End)
+		}
+	}
+	for _, r := range tp.Weeks {
+		if r.Begin < 1 || r.Begin > 53 || r.End < 1 || r.End > 53 {
+			return fmt.Errorf("%d:%d is not a valid week: out of range", r.Begin, r.End)
+		}
+		if r.Begin > r.End {
+			return errors.New("Start week cannot be before End week")
+		}
+	}
+	for _, r := range tp.Quarters {
+		if r.Begin < 1 || r.Begin > 4 || r.End < 1 || r.End > 4 {
+			return fmt.Errorf("%d:%d is not a valid quarter: out of range", r.Begin, r.End)
+		}
+	}
+	for _, r := range tp.Years {
+		if r.Begin > r.End {
+			return errors.New("Start year cannot be before End year")
+		}
+	}
+	for i, ex := range tp.Except {
+		if err := ex.validate(); err != nil {
+			return fmt.Errorf("Exception %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompile(t *testing.T) {
+	melbourne := mustLoadLocation("Australia/Melbourne")
+	holiday := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	intervals := []TimeInterval{
+		{},
+		{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}, {StartMinute: 600, EndMinute: 720}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Location: melbourne,
+		},
+		{
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}, {StartMinute: 60, EndMinute: 90, EndSecond: 30}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 7}}},
+		},
+		{
+			DaysOfMonth:     []DayOfMonthRange{{InclusiveRange{Begin: -3, End: -1}}, {InclusiveRange{Begin: 1, End: 1}}},
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 2, Weekday: time.Tuesday}},
+			Months:          []MonthRange{{InclusiveRange{Begin: 11, End: 2}}},
+		},
+		{
+			Weeks:           []WeekRange{{InclusiveRange{Begin: 1, End: 10}}, {InclusiveRange{Begin: 52, End: 53}}},
+			Quarters:        []QuarterRange{{InclusiveRange{Begin: 1, End: 1}}, {InclusiveRange{Begin: 4, End: 4}}},
+			Years:           []YearRange{{InclusiveRange{Begin: 2025, End: 2025}}},
+			FiscalYearStart: FiscalYearStart(time.July),
+		},
+		{
+			Weekdays: []WeekdayRange{},
+		},
+		{
+			Times: []TimeRange{{StartMinute: 480, EndMinute: 1080}},
+			Except: []TimeInterval{
+				{Times: []TimeRange{{StartMinute: 720, EndMinute: 780}}},
+				{Dates: []DateRange{{Begin: holiday, End: holiday}}},
+			},
+		},
+		{
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}},
+			Location: melbourne,
+			Except: []TimeInterval{{
+				Times:    []TimeRange{{StartMinute: 120, EndMinute: 180}},
+				Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}},
+			}},
+		},
+		{
+			// Dates aren't compiled, so the interval is matched directly.
+			Times: []TimeRange{{StartMinute: 1320, EndMinute: 120}},
+			Dates: []DateRange{{Begin: holiday, End: holiday}},
+		},
+	}
+	// Intervals with only whole minute times and weekdays are matched with a bitmap of the week.
+	weekly := []bool{true, true, false, false, false, true, false, true, false}
+	start := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	for i, ti := range intervals {
+		m, err := ti.Compile()
+		if err != nil {
+			t.Fatalf("Unexpected error compiling interval %d: %v", i, err)
+		}
+		if got := m.(*compiledInterval).week != nil; got != weekly[i] {
+			t.Errorf("Expected compiled interval %d to be weekly: %v, got %v", i, weekly[i], got)
+		}
+		if _, ok := m.(TransitionMatcher); !ok {
+			t.Errorf("Expected compiled interval %d to be a TransitionMatcher", i)
+		}
+		for at := start; at.Before(end); at = at.Add(17 * time.Minute) {
+			if got, expected := m.ContainsTime(at), ti.ContainsTime(at); got != expected {
+				t.Errorf("Expected compiled interval %d to give %v at %s, got %v", i, expected, at, got)
+				break
+			}
+		}
+	}
+
+	// Changing the interval after compiling it doesn't change the Matcher.
+	ti := TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}}}
+	m, err := ti.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ti.Weekdays[0].Begin, ti.Weekdays[0].End = 2, 2
+	monday := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	if !m.ContainsTime(monday) {
+		t.Error("Expected compiled interval to be unaffected by changes to the interval")
+	}
+	if next, ok := m.(TransitionMatcher).NextTransition(monday); !ok || !next.Equal(monday.Add(12*time.Hour)) {
+		t.Errorf("Expected compiled interval to next change at the end of Monday, got %s", next)
+	}
+
+	invalid := []struct {
+		interval TimeInterval
+		err      string
+	}{
+		{TimeInterval{Times: []TimeRange{{StartMinute: 1440, EndMinute: 1440}}}, "Start time out of range"},
+		{TimeInterval{Times: []TimeRange{{StartMinute: 60, EndMinute: 60}}}, "Start time cannot be equal to End time"},
+		{TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 1}}}}, "Start day cannot be before End day"},
+		{TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 0, End: 3}}}},
+			"0 is not a valid day of the month: out of range"},
+		{TimeInterval{Months: []MonthRange{{InclusiveRange{Begin: 1, End: 13}}}}, "1:13 is not a valid month: out of range"},
+		{TimeInterval{Weeks: []WeekRange{{InclusiveRange{Begin: 10, End: 2}}}}, "Start week cannot be before End week"},
+		{TimeInterval{Except: []TimeInterval{{Quarters: []QuarterRange{{InclusiveRange{Begin: 0, End: 1}}}}}},
+			"Exception 1: 0:1 is not a valid quarter: out of range"},
+		{TimeInterval{Times: []TimeRange{{StartSolar: &SolarTime{Event: Sunrise}, EndMinute: 720}}},
+			errNoCoordinates.Error()},
+	}
+	for _, c := range invalid {
+		if _, err := c.interval.Compile(); err == nil || err.Error() != c.err {
+			t.Errorf("Expected error %q, got %v", c.err, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package gotime
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// maxWait bounds how long a single wait for a transition lasts before the next transition is worked out again. Timers
+// run on the monotonic clock, so this lets waits catch up with changes to the wall clock.
+const maxWait = time.Hour
+
+// ErrNeverActive is returned when waiting for an interval that doesn't become active within the search horizon.
+var ErrNeverActive = errors.New("Interval never becomes active")
+
+// ErrNotActive is returned by ActiveContext when the interval isn't active.
+var ErrNotActive = errors.New("Interval is not active")
+
+// WaitUntilActive blocks until the interval is active or ctx is done, returning ctx.Err() in the latter case. It
+// returns at once if the interval is already active, and ErrNeverActive if it never becomes active. Rather than
+// checking the interval periodically, it sleeps until the interval's next transition.
+func WaitUntilActive(ctx context.Context, ti TimeInterval) error {
+	return waitUntilActive(ctx, ti)
+}
+
+func waitUntilActive(ctx context.Context, m TransitionMatcher) error {
+	for {
+		now := time.Now()
+		next, ok := nextActiveTime(m, now)
+		if !ok {
+			return ErrNeverActive
+		}
+		wait := next.Sub(now)
+		if wait <= 0 {
+			return nil
+		}
+		if wait > maxWait {
+			wait = maxWait
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// ActiveContext returns a copy of ctx whose deadline is the end of the interval's current active window, so that work
+// given the context stops when the window closes, along with a function that cancels it sooner. The function should
+// be called once the work is done to release the context's resources. ActiveContext returns ErrNotActive if the
+// interval isn't active. An interval that doesn't stop being active within the search horizon gives a context that
+// has no deadline.
+func ActiveContext(ctx context.Context, ti TimeInterval) (context.Context, context.CancelFunc, error) {
+	return activeContext(ctx, ti)
+}
+
+func activeContext(ctx context.Context, m TransitionMatcher) (context.Context, context.CancelFunc, error) {
+	now := time.Now()
+	if !m.ContainsTime(now) {
+		return nil, nil, ErrNotActive
+	}
+	end, ok := m.NextTransition(now)
+	if !ok {
+		active, cancel := context.WithCancel(ctx)
+		return active, cancel, nil
+	}
+	active, cancel := context.WithDeadline(ctx, end)
+	return active, cancel, nil
+}
+
+// sleep pauses for d or until ctx is done, returning ctx.Err() in the latter case.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+package gotime
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// startingIn returns an interval that becomes active after d and stays active for an hour.
+func startingIn(d time.Duration) TimeInterval {
+	start := time.Now().Add(d)
+	return TimeInterval{Absolute: []AbsoluteRange{{Start: start, End: start.Add(time.Hour)}}}
+}
+
+func TestWaitUntilActive(t *testing.T) {
+	if err := WaitUntilActive(context.Background(), TimeInterval{}); err != nil {
+		t.Errorf("Expected an active interval to return at once, got %v", err)
+	}
+	start := time.Now()
+	if err := WaitUntilActive(context.Background(), startingIn(100*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < 100*time.Millisecond || waited > 2*time.Second {
+		t.Errorf("Expected to wait about 100ms for the interval, waited %s", waited)
+	}
+}
+
+func TestWaitUntilActiveErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := WaitUntilActive(ctx, startingIn(time.Hour)); err != context.DeadlineExceeded {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+	if err := WaitUntilActive(context.Background(), TimeInterval{Weekdays: []WeekdayRange{}}); err != ErrNeverActive {
+		t.Errorf("Expected ErrNeverActive waiting for an empty interval, got %v", err)
+	}
+}
+
+func TestActiveContext(t *testing.T) {
+	now := time.Now()
+	ti := TimeInterval{Absolute: []AbsoluteRange{{Start: now.Add(-time.Minute), End: now.Add(100 * time.Millisecond)}}}
+	ctx, cancel, err := ActiveContext(context.Background(), ti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(ti.Absolute[0].End) {
+		t.Errorf("Expected the context to end with the window at %s, got %s", ti.Absolute[0].End, deadline)
+	}
+	select {
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			t.Errorf("Expected the context to pass its deadline, got %v", ctx.Err())
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("Expected the context to be cancelled when the window ended")
+	}
+
+	ctx, cancel, err = ActiveContext(context.Background(), TimeInterval{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Errorf("Expected an interval that is always active to give a context without a deadline")
+	}
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("Expected the context to be cancelled, got %v", ctx.Err())
+	}
+
+	if _, _, err := ActiveContext(context.Background(), startingIn(time.Hour)); err != ErrNotActive {
+		t.Errorf("Expected ErrNotActive outside the window, got %v", err)
+	}
+}
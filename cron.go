@@ -0,0 +1,208 @@
+package gotime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A cronField describes one of the fields of a cron expression, holding values from min to max. Values may also be
+// given by name if names is set.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute     = cronField{name: "minute", min: 0, max: 59}
+	cronHour       = cronField{name: "hour", min: 0, max: 23}
+	cronDayOfMonth = cronField{name: "day of the month", min: 1, max: 31}
+	cronMonth      = cronField{name: "month", min: 1, max: 12, names: months}
+	// Both 0 and 7 are Sunday.
+	cronWeekday = cronField{name: "day of the week", min: 0, max: 7, names: daysOfWeek}
+)
+
+// cronMacros holds the expressions that cron's @ shorthands stand for.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// FromCron converts a standard five field cron expression, such as '*/15 9-17 * * mon-fri', into a TimeInterval that
+// is active during each minute in which the cron job would start. Fields may hold lists, ranges, steps and the names
+// of months and days of the week, and the @daily style shorthands are also understood. As in cron, if both the day of
+// the month and the day of the week are restricted, a day matches if either does.
+func FromCron(expr string) (TimeInterval, error) {
+	str := strings.ToLower(strings.TrimSpace(expr))
+	if macro, ok := cronMacros[str]; ok {
+		str = macro
+	}
+	fields := strings.Fields(str)
+	if len(fields) != 5 {
+		return TimeInterval{}, fmt.Errorf("Couldn't parse cron expression %s, expected 5 fields", expr)
+	}
+	sets, err := parseCronFields(fields, []cronField{cronMinute, cronHour, cronDayOfMonth, cronMonth, cronWeekday})
+	if err != nil {
+		return TimeInterval{}, err
+	}
+	minutes, hours, days, months, weekdays := sets[0], sets[1], sets[2], sets[3], sets[4]
+	// Sunday may be written as either 0 or 7.
+	weekdays[0] = weekdays[0] || weekdays[7]
+	weekdays = weekdays[:7]
+	ti := TimeInterval{Times: cronTimes(hours, minutes, nil)}
+	for _, r := range cronRuns(months, cronMonth.min) {
+		ti.Months = append(ti.Months, MonthRange{r})
+	}
+	if !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*") {
+		// Cron matches either day field when both are restricted, which is every day except those that both rule out.
+		except := TimeInterval{}
+		for _, r := range cronRuns(complement(days), cronDayOfMonth.min) {
+			except.DaysOfMonth = append(except.DaysOfMonth, DayOfMonthRange{r})
+		}
+		for _, r := range cronRuns(complement(weekdays), cronWeekday.min) {
+			except.Weekdays = append(except.Weekdays, WeekdayRange{r})
+		}
+		if except.DaysOfMonth != nil && except.Weekdays != nil {
+			ti.Except = []TimeInterval{except}
+		}
+		return ti, nil
+	}
+	for _, r := range cronRuns(days, cronDayOfMonth.min) {
+		ti.DaysOfMonth = append(ti.DaysOfMonth, DayOfMonthRange{r})
+	}
+	for _, r := range cronRuns(weekdays, cronWeekday.min) {
+		ti.Weekdays = append(ti.Weekdays, WeekdayRange{r})
+	}
+	return ti, nil
+}
+
+// parseCronFields parses each field of a cron expression with the matching cronField.
+func parseCronFields(fields []string, specs []cronField) ([][]bool, error) {
+	sets := make([][]bool, len(fields))
+	for i, f := range specs {
+		set, err := f.parse(fields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+	return sets, nil
+}
+
+// parse returns which of the field's values, counting from min, are matched by a field of a cron expression.
+func (f cronField) parse(spec string) ([]bool, error) {
+	set := make([]bool, f.max-f.min+1)
+	for _, part := range strings.Split(spec, ",") {
+		base, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("Couldn't parse cron %s %s, invalid step", f.name, part)
+			}
+			base, step = part[:i], n
+		}
+		begin, end := f.min, f.max
+		if base != "*" {
+			bounds := strings.Split(base, "-")
+			if len(bounds) > 2 {
+				return nil, fmt.Errorf("Couldn't parse cron %s %s, invalid format", f.name, part)
+			}
+			var err error
+			if begin, err = f.value(bounds[0]); err != nil {
+				return nil, err
+			}
+			end = begin
+			if len(bounds) == 2 {
+				if end, err = f.value(bounds[1]); err != nil {
+					return nil, err
+				}
+			} else if strings.Contains(part, "/") {
+				// A single value with a step, such as 5/15, runs to the end of the field.
+				end = f.max
+			}
+		}
+		if begin > end {
+			return nil, fmt.Errorf("Couldn't parse cron %s %s, start cannot be after end", f.name, part)
+		}
+		for v := begin; v <= end; v += step {
+			set[v-f.min] = true
+		}
+	}
+	return set, nil
+}
+
+// value reads a single value of the field, given as a number or a name.
+func (f cronField) value(str string) (int, error) {
+	if v, ok := f.names[str]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a valid cron %s", str, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s is not a valid cron %s: out of range", str, f.name)
+	}
+	return v, nil
+}
+
+// cronTimes returns the time ranges covering each second matched by the hour, minute and second fields of a cron
+// expression, or nil if they match the whole day. If seconds is nil, every second of a matched minute is covered.
+func cronTimes(hours, minutes, seconds []bool) []TimeRange {
+	day := make([]bool, secondsPerDay)
+	for hour, h := range hours {
+		for minute, m := range minutes {
+			for second := 0; second < 60; second++ {
+				day[(hour*60+minute)*60+second] = h && m && (seconds == nil || seconds[second])
+			}
+		}
+	}
+	return timesOfSeconds(day)
+}
+
+// timesOfSeconds returns the time ranges covering the seconds of the day that are set in day, or nil if every second
+// is set.
+func timesOfSeconds(day []bool) []TimeRange {
+	var times []TimeRange
+	for _, r := range cronRuns(day, 0) {
+		times = append(times, timeRangeFromSeconds(r.Begin, r.End+1))
+	}
+	return times
+}
+
+// cronRuns returns the runs of consecutive values in set, where the first value is offset, or nil if every value is
+// in the set.
+func cronRuns(set []bool, offset int) []InclusiveRange {
+	var runs []InclusiveRange
+	all := true
+	for i := 0; i < len(set); i++ {
+		if !set[i] {
+			all = false
+			continue
+		}
+		begin := i
+		for i+1 < len(set) && set[i+1] {
+			i++
+		}
+		runs = append(runs, InclusiveRange{Begin: begin + offset, End: i + offset})
+	}
+	if all {
+		return nil
+	}
+	return runs
+}
+
+// complement returns the values that aren't in set.
+func complement(set []bool) []bool {
+	out := make([]bool, len(set))
+	for i, v := range set {
+		out[i] = !v
+	}
+	return out
+}
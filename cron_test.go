@@ -0,0 +1,141 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var fromCronTestCases = []struct {
+	expr        string
+	interval    TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		expr: "* 9-16 * * mon-fri",
+		interval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+		contains: []string{"2020-07-10T09:00:00Z", "2020-07-10T16:59:00Z"},
+		excludes: []string{"2020-07-10T17:00:00Z", "2020-07-11T10:00:00Z"},
+	},
+	{
+		expr: "*/20 0 1,15 jan-mar,dec *",
+		interval: TimeInterval{
+			Times: []TimeRange{
+				{StartMinute: 0, EndMinute: 1},
+				{StartMinute: 20, EndMinute: 21},
+				{StartMinute: 40, EndMinute: 41},
+			},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 1}}, {InclusiveRange{Begin: 15, End: 15}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 1, End: 3}}, {InclusiveRange{Begin: 12, End: 12}}},
+		},
+		contains: []string{"2020-01-15T00:20:30Z"},
+		excludes: []string{"2020-01-15T00:21:00Z", "2020-04-01T00:00:00Z", "2020-01-02T00:00:00Z"},
+	},
+	{
+		expr: "@weekly",
+		interval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 0, EndMinute: 1}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}},
+		},
+	},
+	{
+		expr:     "* * * * *",
+		interval: TimeInterval{},
+	},
+	{
+		// Sunday may be 7, and 5/15 runs from 5 to the end of the hour.
+		expr: "5/15 12 * * 7",
+		interval: TimeInterval{
+			Times: []TimeRange{
+				{StartMinute: 725, EndMinute: 726},
+				{StartMinute: 740, EndMinute: 741},
+				{StartMinute: 755, EndMinute: 756},
+				{StartMinute: 770, EndMinute: 771},
+			},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}},
+		},
+	},
+	{
+		// Both day fields are restricted, so either matches: the 1st of the month or any Monday.
+		expr: "0 * 1 * mon",
+		interval: TimeInterval{
+			Times: []TimeRange{
+				{StartMinute: 0, EndMinute: 1}, {StartMinute: 60, EndMinute: 61}, {StartMinute: 120, EndMinute: 121},
+				{StartMinute: 180, EndMinute: 181}, {StartMinute: 240, EndMinute: 241}, {StartMinute: 300, EndMinute: 301},
+				{StartMinute: 360, EndMinute: 361}, {StartMinute: 420, EndMinute: 421}, {StartMinute: 480, EndMinute: 481},
+				{StartMinute: 540, EndMinute: 541}, {StartMinute: 600, EndMinute: 601}, {StartMinute: 660, EndMinute: 661},
+				{StartMinute: 720, EndMinute: 721}, {StartMinute: 780, EndMinute: 781}, {StartMinute: 840, EndMinute: 841},
+				{StartMinute: 900, EndMinute: 901}, {StartMinute: 960, EndMinute: 961}, {StartMinute: 1020, EndMinute: 1021},
+				{StartMinute: 1080, EndMinute: 1081}, {StartMinute: 1140, EndMinute: 1141}, {StartMinute: 1200, EndMinute: 1201},
+				{StartMinute: 1260, EndMinute: 1261}, {StartMinute: 1320, EndMinute: 1321}, {StartMinute: 1380, EndMinute: 1381},
+			},
+			Except: []TimeInterval{{
+				DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 2, End: 31}}},
+				Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 2, End: 6}}},
+			}},
+		},
+		// 1 July 2020 is a Wednesday and 6 July a Monday.
+		contains: []string{"2020-07-01T10:00:00Z", "2020-07-06T10:00:00Z"},
+		excludes: []string{"2020-07-02T10:00:00Z", "2020-07-06T10:01:00Z"},
+	},
+	{
+		expr:        "0 9 * *",
+		expectError: true,
+	},
+	{
+		expr:        "60 * * * *",
+		expectError: true,
+	},
+	{
+		expr:        "0 17-9 * * *",
+		expectError: true,
+	},
+	{
+		expr:        "0 0 * foo *",
+		expectError: true,
+	},
+	{
+		expr:        "*/0 * * * *",
+		expectError: true,
+	},
+	{
+		expr:        "@reboot",
+		expectError: true,
+	},
+}
+
+func TestFromCron(t *testing.T) {
+	for _, tc := range fromCronTestCases {
+		ti, err := FromCron(tc.expr)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected an error converting %s, got %v", tc.expr, ti)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error converting %s: %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Converting %s gave %v, expected %v", tc.expr, ti, tc.interval)
+		}
+		for _, c := range tc.contains {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if !ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to contain %s", tc.expr, c)
+			}
+		}
+		for _, c := range tc.excludes {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to exclude %s", tc.expr, c)
+			}
+		}
+	}
+}
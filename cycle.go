@@ -0,0 +1,177 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A CycleUnit is the length of each period in a Cycle.
+type CycleUnit int
+
+const (
+	// CycleDays counts a Cycle in whole days.
+	CycleDays CycleUnit = 1
+	// CycleWeeks counts a Cycle in whole weeks.
+	CycleWeeks CycleUnit = 7
+)
+
+// A Cycle matches one period in every Every periods, counting from the period that begins on the Anchor date. For
+// example, a Cycle anchored on Monday 1 January 2024 with Every 2 and Unit CycleWeeks matches every second week from
+// that Monday onwards. In YAML it may also be given under the repeat key, with every written as a duration in whole
+// days such as '72h'. Days before the anchor never match. Only the date of the Anchor is used, and days are compared
+// in the interval's location.
+type Cycle struct {
+	Anchor time.Time
+	Every  int
+	Unit   CycleUnit
+}
+
+type yamlCycle struct {
+	Anchor string `yaml:"anchor"`
+	Every  string `yaml:"every"`
+}
+
+// CycleDateLayout specifies the layout of a cycle's anchor date
+const CycleDateLayout = "2006-01-02"
+
+var cycleEveryRE = regexp.MustCompile(`^([1-9][0-9]*)\s*([dw])$`)
+
+var cycleUnits = map[string]CycleUnit{
+	"d": CycleDays,
+	"w": CycleWeeks,
+}
+
+var cycleUnitsInv = map[CycleUnit]string{
+	CycleDays:  "d",
+	CycleWeeks: "w",
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for Cycle.
+func (c *Cycle) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var y yamlCycle
+	if err := unmarshal(&y); err != nil {
+		return err
+	}
+	if y.Anchor == "" || y.Every == "" {
+		return errors.New("Both anchor and every must be provided")
+	}
+	anchor, err := time.Parse(CycleDateLayout, y.Anchor)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid anchor date: %v", y.Anchor, err)
+	}
+	m := cycleEveryRE.FindStringSubmatch(y.Every)
+	if m == nil {
+		// Lengths may also be given as a duration, as long as it is a whole number of days.
+		d, err := time.ParseDuration(y.Every)
+		if err != nil {
+			return fmt.Errorf("Couldn't parse cycle length %s, invalid format", y.Every)
+		}
+		if d <= 0 || d%(24*time.Hour) != 0 {
+			return fmt.Errorf("%s is not a valid cycle length: must be a whole number of days", y.Every)
+		}
+		c.Anchor, c.Every, c.Unit = anchor, int(d/(24*time.Hour)), CycleDays
+		return nil
+	}
+	every, err := strconv.Atoi(m[1])
+	if err != nil {
+		return err
+	}
+	c.Anchor, c.Every, c.Unit = anchor, every, cycleUnits[m[2]]
+	return nil
+}
+
+// renameRepeat renames the repeat key of an interval's fields to cycle, which it is another name for, returning false
+// if there isn't one.
+func renameRepeat(fields yaml.MapSlice) (bool, error) {
+	if !hasKey(fields, "repeat") {
+		return false, nil
+	}
+	if hasKey(fields, "cycle") {
+		return false, errors.New("Only one of repeat and cycle may be provided")
+	}
+	for i, field := range fields {
+		if field.Key == "repeat" {
+			fields[i].Key = "cycle"
+		}
+	}
+	return true, nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for Cycle
+func (c Cycle) MarshalYAML() (interface{}, error) {
+	unit, ok := cycleUnitsInv[c.Unit]
+	if !ok {
+		return nil, fmt.Errorf("Unable to convert %d into cycle unit", c.Unit)
+	}
+	return interface{}(yamlCycle{
+		Anchor: c.Anchor.Format(CycleDateLayout),
+		Every:  fmt.Sprintf("%d%s", c.Every, unit),
+	}), nil
+}
+
+// containsDay returns true if the calendar day of the given time falls in an active period of the cycle.
+func (c Cycle) containsDay(t time.Time) bool {
+	days := c.daysSinceAnchor(t)
+	if days < 0 || c.Every < 1 || c.Unit < 1 {
+		return false
+	}
+	return (days/int(c.Unit))%c.Every == 0
+}
+
+// daysSinceAnchor returns the number of calendar days from the anchor date to the date of t.
+func (c Cycle) daysSinceAnchor(t time.Time) int {
+	anchor := time.Date(c.Anchor.Year(), c.Anchor.Month(), c.Anchor.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(anchor).Hours() / 24)
+}
+
+// equal returns true if both cycles match the same days.
+func (c Cycle) equal(other Cycle) bool {
+	return c.Every == other.Every && c.Unit == other.Unit && c.daysSinceAnchor(other.Anchor) == 0
+}
+
+// length returns the number of days before the cycle repeats.
+func (c Cycle) length() int {
+	return c.Every * int(c.Unit)
+}
+
+// cycleIsEmpty returns true if no day matched by the interval's cycle satisfies the rest of its day-level fields. Every
+// combination of the cycle and the calendar repeats within the least common multiple of their lengths, so only the
+// active days within that span need to be checked.
+func (tp TimeInterval) cycleIsEmpty() bool {
+	c := *tp.Cycle
+	if c.Every < 1 || c.Unit < 1 {
+		return true
+	}
+	lastYear, yearBounded := tp.lastYear()
+	span := lcm(c.length(), gregorianCycleDays)
+	anchor := time.Date(c.Anchor.Year(), c.Anchor.Month(), c.Anchor.Day(), 0, 0, 0, 0, time.UTC)
+	for start := 0; start < span; start += c.length() {
+		for offset := 0; offset < int(c.Unit); offset++ {
+			day := anchor.AddDate(0, 0, start+offset)
+			if yearBounded && day.Year() > lastYear {
+				return true
+			}
+			if tp.containsDay(day) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// gregorianCycleDays is the number of days in the 400 year Gregorian cycle.
+const gregorianCycleDays = 146097
+
+func lcm(a, b int) int {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
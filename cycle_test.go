@@ -0,0 +1,144 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var cycleTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// Every second week starting on a Monday
+		in: "cycle: {anchor: '2024-01-01', every: '2w'}",
+		interval: TimeInterval{
+			Cycle: &Cycle{Anchor: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Every: 2, Unit: CycleWeeks},
+		},
+		contains: []string{"2024-01-01T00:00:00Z", "2024-01-07T23:59:59Z", "2024-01-15T12:00:00Z", "2025-12-29T12:00:00Z"},
+		excludes: []string{"2023-12-31T12:00:00Z", "2024-01-08T00:00:00Z", "2024-01-14T12:00:00Z"},
+	},
+	{
+		// Every third day during business hours
+		in: "{cycle: {anchor: '2020-07-06', every: '3d'}, weekdays: ['monday:friday'], times: [{start_time: '09:00', end_time: '17:00'}]}",
+		interval: TimeInterval{
+			Cycle:    &Cycle{Anchor: time.Date(2020, time.July, 6, 0, 0, 0, 0, time.UTC), Every: 3, Unit: CycleDays},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+		},
+		contains: []string{"2020-07-06T10:00:00Z", "2020-07-09T10:00:00Z", "2020-07-15T10:00:00Z"},
+		excludes: []string{"2020-07-07T10:00:00Z", "2020-07-12T10:00:00Z", "2020-07-09T08:00:00Z"},
+	},
+	{
+		// Every third day, given as a duration under the repeat key
+		in: "repeat: {anchor: '2023-05-01', every: '72h'}",
+		interval: TimeInterval{
+			Cycle: &Cycle{Anchor: time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC), Every: 3, Unit: CycleDays},
+		},
+		contains: []string{"2023-05-01T00:00:00Z", "2023-05-04T12:00:00Z", "2023-05-31T23:59:59Z"},
+		excludes: []string{"2023-04-28T12:00:00Z", "2023-05-02T00:00:00Z", "2023-05-03T23:59:59Z"},
+	},
+	{
+		in:          "repeat: {anchor: '2023-05-01', every: '36h'}",
+		expectError: true,
+	},
+	{
+		in:          "{repeat: {anchor: '2023-05-01', every: '72h'}, cycle: {anchor: '2023-05-01', every: '3d'}}",
+		expectError: true,
+	},
+	{
+		in:          "cycle: {anchor: '2024-01-01'}",
+		expectError: true,
+	},
+	{
+		in:          "cycle: {anchor: '2024-13-01', every: '2w'}",
+		expectError: true,
+	},
+	{
+		in:          "cycle: {anchor: '2024-01-01', every: '0w'}",
+		expectError: true,
+	},
+	{
+		in:          "cycle: {anchor: '2024-01-01', every: '2m'}",
+		expectError: true,
+	},
+}
+
+func TestCycle(t *testing.T) {
+	for _, tc := range cycleTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestCycleAnalysis(t *testing.T) {
+	fortnightly := TimeInterval{
+		Cycle: &Cycle{Anchor: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Every: 2, Unit: CycleWeeks},
+	}
+	if fortnightly.IsAlwaysActive() || fortnightly.IsEmpty() {
+		t.Errorf("Expected a fortnightly cycle to be neither always active nor empty")
+	}
+	// Every week of the cycle starts on a Monday and lasts one day, so it never reaches a weekend.
+	weekendMondays := TimeInterval{
+		Cycle:    &Cycle{Anchor: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Every: 7, Unit: CycleDays},
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 7}}},
+	}
+	if !weekendMondays.IsEmpty() {
+		t.Errorf("Expected a weekly cycle of Mondays restricted to weekends to be empty")
+	}
+	beforeAnchor := TimeInterval{
+		Cycle: fortnightly.Cycle,
+		Years: []YearRange{{InclusiveRange{Begin: 2020, End: 2023}}},
+	}
+	if !beforeAnchor.IsEmpty() {
+		t.Errorf("Expected a cycle restricted to years before its anchor to be empty")
+	}
+	windows := fortnightly.Windows(mustParseTime("2024-01-01T00:00:00Z"), mustParseTime("2024-02-01T00:00:00Z"))
+	want := []Window{
+		{Start: mustParseTime("2024-01-01T00:00:00Z"), End: mustParseTime("2024-01-08T00:00:00Z")},
+		{Start: mustParseTime("2024-01-15T00:00:00Z"), End: mustParseTime("2024-01-22T00:00:00Z")},
+		{Start: mustParseTime("2024-01-29T00:00:00Z"), End: mustParseTime("2024-02-01T00:00:00Z")},
+	}
+	if !reflect.DeepEqual(windows, want) {
+		t.Errorf("Windows of a fortnightly cycle: want %v, got %v", want, windows)
+	}
+	if _, err := Intersect(fortnightly, TimeInterval{Cycle: weekendMondays.Cycle}); err != ErrNotRepresentable {
+		t.Errorf("Expected intersecting different cycles to be unrepresentable, got %v", err)
+	}
+}
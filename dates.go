@@ -0,0 +1,133 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateLayout specifies the layout of the dates in a DateRange
+const DateLayout = "2006-01-02"
+
+// A DateRange is an inclusive range of calendar dates, e.g. '2024-12-24:2025-01-02' for a freeze over the new year.
+// Only the dates of Begin and End are used, and days are compared in the interval's location.
+type DateRange struct {
+	Begin time.Time
+	End   time.Time
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for DateRange.
+func (r *DateRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	components := strings.Split(str, ":")
+	if len(components) > 2 {
+		return fmt.Errorf("Couldn't parse date range %s, invalid format", str)
+	}
+	begin, err := time.Parse(DateLayout, strings.TrimSpace(components[0]))
+	if err != nil {
+		return fmt.Errorf("%s is not a valid date: %v", components[0], err)
+	}
+	end := begin
+	if len(components) == 2 {
+		end, err = time.Parse(DateLayout, strings.TrimSpace(components[1]))
+		if err != nil {
+			return fmt.Errorf("%s is not a valid date: %v", components[1], err)
+		}
+	}
+	if begin.After(end) {
+		return errors.New("Start date cannot be before End date")
+	}
+	r.Begin, r.End = begin, end
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for DateRange
+func (r DateRange) MarshalYAML() (interface{}, error) {
+	begin, end := r.Begin.Format(DateLayout), r.End.Format(DateLayout)
+	if begin == end {
+		return interface{}(begin), nil
+	}
+	return interface{}(begin + ":" + end), nil
+}
+
+// containsDay returns true if the calendar day of the given time falls within the range.
+func (r DateRange) containsDay(t time.Time) bool {
+	day := date(t.Year(), t.Month(), t.Day())
+	return !day.Before(dateOf(r.Begin)) && !day.After(dateOf(r.End))
+}
+
+// dateOf returns midnight UTC on the calendar date of t.
+func dateOf(t time.Time) time.Time {
+	return date(t.Year(), t.Month(), t.Day())
+}
+
+// datesAreEmpty returns true if no day within the interval's date ranges satisfies the rest of its day-level fields.
+// Only the first Gregorian cycle of each range is checked, as every combination of the calendar repeats after it.
+func (tp TimeInterval) datesAreEmpty() bool {
+	for _, r := range tp.Dates {
+		last := dateOf(r.End)
+		if limit := dateOf(r.Begin).AddDate(gregorianCycleYears, 0, 0); limit.Before(last) && tp.Cycle == nil {
+			last = limit
+		}
+		for day := dateOf(r.Begin); !day.After(last); day = day.AddDate(0, 0, 1) {
+			if tp.containsDay(day) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// intersectDateRanges returns the date ranges covered by both lists, or whichever list is given if the other is nil.
+func intersectDateRanges(a, b []DateRange) []DateRange {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	out := []DateRange{}
+	for _, ra := range a {
+		for _, rb := range b {
+			begin, end := dateOf(ra.Begin), dateOf(ra.End)
+			if rb := dateOf(rb.Begin); rb.After(begin) {
+				begin = rb
+			}
+			if rb := dateOf(rb.End); rb.Before(end) {
+				end = rb
+			}
+			if !begin.After(end) {
+				out = append(out, DateRange{Begin: begin, End: end})
+			}
+		}
+	}
+	return out
+}
+
+// yearlyDates returns intervals that together match every day from the first day of the first month to the last day
+// of the last month each year, continuing into the following year if the last month comes before the first. Negative
+// days count back from the end of their month.
+func yearlyDates(firstMonth, firstDay, lastMonth, lastDay int) IntervalSet {
+	if firstMonth == lastMonth {
+		return IntervalSet{{
+			Months:      []MonthRange{{InclusiveRange{Begin: firstMonth, End: firstMonth}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: firstDay, End: lastDay}}},
+		}}
+	}
+	// Match the rest of the first month, any months in between and the start of the last month.
+	set := IntervalSet{{
+		Months:      []MonthRange{{InclusiveRange{Begin: firstMonth, End: firstMonth}}},
+		DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: firstDay, End: -1}}},
+	}}
+	if next, prev := firstMonth%12+1, (lastMonth+10)%12+1; next != lastMonth {
+		set = append(set, TimeInterval{Months: []MonthRange{{InclusiveRange{Begin: next, End: prev}}}})
+	}
+	return append(set, TimeInterval{
+		Months:      []MonthRange{{InclusiveRange{Begin: lastMonth, End: lastMonth}}},
+		DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: lastDay}}},
+	})
+}
@@ -0,0 +1,116 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var datesTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// A freeze over the new year
+		in: "dates: ['2024-12-24:2025-01-02']",
+		interval: TimeInterval{
+			Dates: []DateRange{{Begin: date(2024, 12, 24), End: date(2025, 1, 2)}},
+		},
+		contains: []string{"2024-12-24T00:00:00Z", "2024-12-31T23:59:59Z", "2025-01-02T23:59:59Z"},
+		excludes: []string{"2024-12-23T23:59:59Z", "2025-01-03T00:00:00Z", "2025-12-25T12:00:00Z"},
+	},
+	{
+		// A single date alongside weekdays
+		in: "{dates: ['2024-03-01', '2024-03-04:2024-03-10'], weekdays: ['monday:friday']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Dates:    []DateRange{{Begin: date(2024, 3, 1), End: date(2024, 3, 1)}, {Begin: date(2024, 3, 4), End: date(2024, 3, 10)}},
+		},
+		contains: []string{"2024-03-01T12:00:00Z", "2024-03-08T12:00:00Z"},
+		excludes: []string{"2024-03-02T12:00:00Z", "2024-03-09T12:00:00Z", "2024-03-11T12:00:00Z"},
+	},
+	{
+		in:          "dates: ['2025-01-02:2024-12-24']",
+		expectError: true,
+	},
+	{
+		in:          "dates: ['2024-02-30']",
+		expectError: true,
+	},
+	{
+		in:          "dates: ['24-12-2024']",
+		expectError: true,
+	},
+}
+
+func TestDates(t *testing.T) {
+	for _, tc := range datesTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestDatesWindows(t *testing.T) {
+	freeze := TimeInterval{Dates: []DateRange{{Begin: date(2024, 12, 24), End: date(2025, 1, 2)}}}
+	got := freeze.Windows(mustParseTime("2024-01-01T00:00:00Z"), mustParseTime("2030-01-01T00:00:00Z"))
+	want := []Window{{Start: mustParseTime("2024-12-24T00:00:00Z"), End: mustParseTime("2025-01-03T00:00:00Z")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows of the freeze: want %v, got %v", want, got)
+	}
+	if next, ok := freeze.NextTransition(mustParseTime("2025-06-01T00:00:00Z")); ok {
+		t.Errorf("Expected no transition after the freeze, got %v", next)
+	}
+	if freeze.IsEmpty() || freeze.IsAlwaysActive() {
+		t.Errorf("Expected the freeze to be neither empty nor always active")
+	}
+	sundays := TimeInterval{
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}},
+		Dates:    []DateRange{{Begin: date(2024, 3, 4), End: date(2024, 3, 9)}},
+	}
+	if !sundays.IsEmpty() {
+		t.Errorf("Expected a Monday to Saturday date range restricted to Sundays to be empty")
+	}
+	january := TimeInterval{Dates: []DateRange{{Begin: date(2025, 1, 1), End: date(2025, 1, 31)}}}
+	both, err := Intersect(freeze, january)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDates := []DateRange{{Begin: date(2025, 1, 1), End: date(2025, 1, 2)}}
+	if !reflect.DeepEqual(both.Dates, wantDates) {
+		t.Errorf("Intersecting date ranges: want %v, got %v", wantDates, both.Dates)
+	}
+}
@@ -0,0 +1,93 @@
+package gotime
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A ScheduleState is the state of a set of intervals at some time, for inspecting long running services.
+type ScheduleState struct {
+	// Schedule is the text form of the set, or YAML if it has no text form.
+	Schedule         string     `json:"schedule"`
+	Active           bool       `json:"active"`
+	NextActivation   *time.Time `json:"next_activation,omitempty"`
+	NextDeactivation *time.Time `json:"next_deactivation,omitempty"`
+}
+
+// StateAt returns the state of the set at t. The times at which it next becomes active and stops being active are
+// nil if they don't occur within the search horizon.
+func (is IntervalSet) StateAt(t time.Time) ScheduleState {
+	state := ScheduleState{Active: is.ContainsTime(t)}
+	if text, err := is.MarshalText(); err == nil {
+		state.Schedule = string(text)
+	} else if out, err := yaml.Marshal(is); err == nil {
+		state.Schedule = string(out)
+	}
+	var next, after *time.Time
+	if n, ok := is.NextTransition(t); ok {
+		next = &n
+		if a, ok := is.NextTransition(n); ok {
+			after = &a
+		}
+	}
+	state.NextActivation, state.NextDeactivation = next, after
+	if state.Active {
+		state.NextActivation, state.NextDeactivation = after, next
+	}
+	return state
+}
+
+// States returns the state of each definition at t.
+func (d Definitions) States(t time.Time) map[string]ScheduleState {
+	states := make(map[string]ScheduleState, len(d))
+	for name, set := range d {
+		states[name] = set.StateAt(t)
+	}
+	return states
+}
+
+// PublishExpvar publishes the state of each definition under the given name with the expvar package, so that it is
+// served at /debug/vars along with the rest of the process's variables. The state is worked out each time it's read,
+// but the definitions are fixed, so reloaded definitions should be kept in a Registry and published with its
+// PublishExpvar instead. Like expvar.Publish, it panics if the name is already in use, so it should be called once per
+// name in a process, e.g. from main; expvar.Get can be used to check whether a name is taken first.
+func PublishExpvar(name string, d Definitions) {
+	publishExpvar(name, func() Definitions { return d })
+}
+
+// PublishExpvar publishes the state of each definition in the registry at the time it's read, as the package's
+// PublishExpvar does, and panics in the same way if the name is already in use.
+func (r *Registry) PublishExpvar(name string) {
+	publishExpvar(name, r.Definitions)
+}
+
+func publishExpvar(name string, defs func() Definitions) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return defs().States(time.Now())
+	}))
+}
+
+// DebugHandler returns a handler that serves the state of each definition as JSON, for mounting at a path such as
+// /debug/gotime. The definitions are fixed when the handler is made, as they are for MetricsHandler.
+func DebugHandler(d Definitions) http.Handler {
+	return debugHandler(func() Definitions { return d })
+}
+
+// DebugHandler returns a handler that serves the state of each definition in the registry at the time of the request,
+// as the package's DebugHandler does.
+func (r *Registry) DebugHandler() http.Handler {
+	return debugHandler(r.Definitions)
+}
+
+func debugHandler(defs func() Definitions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(defs().States(time.Now()))
+	})
+}
@@ -0,0 +1,98 @@
+package gotime
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStateAt(t *testing.T) {
+	set := IntervalSet{{
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+	}}
+	// 2024-07-01 is a Monday.
+	at := time.Date(2024, time.July, 1, 10, 0, 0, 0, time.UTC)
+	state := set.StateAt(at)
+	if !state.Active || state.Schedule != "monday:friday 09:00-17:00" {
+		t.Errorf("Expected an active business hours schedule, got %+v", state)
+	}
+	if want := time.Date(2024, time.July, 1, 17, 0, 0, 0, time.UTC); state.NextDeactivation == nil ||
+		!state.NextDeactivation.Equal(want) {
+		t.Errorf("Expected the schedule to stop being active at %s, got %v", want, state.NextDeactivation)
+	}
+	if want := time.Date(2024, time.July, 2, 9, 0, 0, 0, time.UTC); state.NextActivation == nil ||
+		!state.NextActivation.Equal(want) {
+		t.Errorf("Expected the schedule to become active at %s, got %v", want, state.NextActivation)
+	}
+	state = IntervalSet{{Except: []TimeInterval{{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}}}}}}.
+		StateAt(at)
+	if state.Schedule != "- except:\n  - weekdays: [sunday]\n" {
+		t.Errorf("Expected a schedule without a text form to be given as YAML, got %q", state.Schedule)
+	}
+	if state := (IntervalSet{{}}).StateAt(at); state.NextActivation != nil || state.NextDeactivation != nil {
+		t.Errorf("Expected no transitions for a schedule that is always active, got %+v", state)
+	}
+}
+
+// expvarTestRuns counts the runs of tests that publish with expvar.
+var expvarTestRuns int
+
+func TestDebugHandler(t *testing.T) {
+	d := Definitions{"always": IntervalSet{{}}, "never": IntervalSet{{Weekdays: []WeekdayRange{}}}}
+	rec := httptest.NewRecorder()
+	DebugHandler(d).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/gotime", nil))
+	var states map[string]ScheduleState
+	if err := json.Unmarshal(rec.Body.Bytes(), &states); err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 2 || !states["always"].Active || states["never"].Active {
+		t.Errorf("Expected the state of each definition, got %s", rec.Body)
+	}
+
+	// Names can only be published once in a process, so each run of the test needs its own.
+	expvarTestRuns++
+	name := fmt.Sprintf("gotime_test_%d", expvarTestRuns)
+	PublishExpvar(name, d)
+	var published map[string]ScheduleState
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &published); err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 2 || !published["always"].Active {
+		t.Errorf("Expected the state of each definition to be published, got %v", published)
+	}
+}
+
+func TestRegistryDebug(t *testing.T) {
+	r := NewRegistry(Definitions{"always": IntervalSet{{}}})
+	expvarTestRuns++
+	name := fmt.Sprintf("gotime_registry_test_%d", expvarTestRuns)
+	r.PublishExpvar(name)
+	h := r.DebugHandler()
+	read := func() (served, published map[string]ScheduleState) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/gotime", nil))
+		if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(expvar.Get(name).String()), &published); err != nil {
+			t.Fatal(err)
+		}
+		return served, published
+	}
+	served, published := read()
+	if len(served) != 1 || !served["always"].Active || len(published) != 1 || !published["always"].Active {
+		t.Errorf("Expected the registered definition, got %v and %v", served, published)
+	}
+	// Definitions swapped in later are seen the next time the state is read.
+	r.Swap(Definitions{"never": IntervalSet{{Weekdays: []WeekdayRange{}}}})
+	served, published = read()
+	for _, states := range []map[string]ScheduleState{served, published} {
+		if _, ok := states["never"]; len(states) != 1 || !ok {
+			t.Errorf("Expected the swapped definitions, got %v", states)
+		}
+	}
+}
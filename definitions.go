@@ -0,0 +1,127 @@
+package gotime
+
+import (
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Definitions holds named sets of intervals, e.g. a document mapping names such as business_hours and holidays to
+// lists of intervals. In YAML, a definition may refer to another by giving its name in place of an interval, either
+// in its own list or in the except list of any of its intervals, and references are resolved when the document is
+// parsed. A definition may also be a single interval rather than a list. Intervals without a name of their own are
+// named after the definition they are written in. A version key at the top of the document gives the DocumentVersion
+// it was written for, and applies to every interval in it.
+type Definitions map[string]IntervalSet
+
+// UnmarshalYAML implements the Unmarshaller interface for Definitions.
+func (d *Definitions) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw yaml.MapSlice
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	version, raw, err := takeVersion(raw, DocumentVersion)
+	if err != nil {
+		return err
+	}
+	r := definitionResolver{raw: make(map[string]interface{}, len(raw)), resolved: make(map[string][]interface{})}
+	for _, item := range raw {
+		name, ok := item.Key.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a valid definition name", item.Key)
+		}
+		r.raw[name], _ = migrateDefinition(item.Value, name, version)
+	}
+	decode := decoder(isStrict(unmarshal))
+	out := make(Definitions, len(raw))
+	for name := range r.raw {
+		list, err := r.resolve(name, nil)
+		if err != nil {
+			return err
+		}
+		b, err := yaml.Marshal(list)
+		if err != nil {
+			return err
+		}
+		var set IntervalSet
+		if err := decode(b, &set); err != nil {
+			return fmt.Errorf("Definition %s: %v", name, err)
+		}
+		out[name] = set
+	}
+	*d = out
+	return nil
+}
+
+// A definitionResolver replaces references between definitions with the intervals they refer to.
+type definitionResolver struct {
+	raw      map[string]interface{}
+	resolved map[string][]interface{}
+}
+
+// resolve returns the intervals of the named definition with every reference replaced. The names of the definitions
+// being resolved further up are given in stack, so that references that lead back to them can be reported.
+func (r definitionResolver) resolve(name string, stack []string) ([]interface{}, error) {
+	if list, ok := r.resolved[name]; ok {
+		return list, nil
+	}
+	for _, s := range stack {
+		if s == name {
+			return nil, fmt.Errorf("Definition %s refers to itself", name)
+		}
+	}
+	value, ok := r.raw[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a defined interval", name)
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		list = []interface{}{value}
+	}
+	named := make([]interface{}, len(list))
+	for i, v := range list {
+		named[i] = v
+		if interval, ok := v.(yaml.MapSlice); ok && !hasKey(interval, "name") {
+			named[i] = append(yaml.MapSlice{{Key: "name", Value: name}}, interval...)
+		}
+	}
+	list = named
+	out, err := r.resolveList(list, append(stack, name))
+	if err != nil {
+		return nil, err
+	}
+	r.resolved[name] = out
+	return out, nil
+}
+
+// resolveList replaces each reference in a list of intervals with the intervals it refers to, and resolves the
+// except lists of the rest.
+func (r definitionResolver) resolveList(list []interface{}, stack []string) ([]interface{}, error) {
+	out := make([]interface{}, 0, len(list))
+	for _, v := range list {
+		switch v := v.(type) {
+		case string:
+			intervals, err := r.resolve(v, stack)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, intervals...)
+		case yaml.MapSlice:
+			interval := make(yaml.MapSlice, len(v))
+			for i, field := range v {
+				interval[i] = field
+				if except, ok := field.Value.([]interface{}); ok && field.Key == "except" {
+					resolved, err := r.resolveList(except, stack)
+					if err != nil {
+						return nil, err
+					}
+					interval[i].Value = resolved
+				}
+			}
+			out = append(out, interval)
+		default:
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,95 @@
+package gotime
+
+import (
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var definitionsTestCases = []struct {
+	in          string
+	contains    map[string][]string
+	excludes    map[string][]string
+	expectError bool
+}{
+	{
+		in: `
+holidays:
+  - months: ['december']
+    days_of_month: ['25:26']
+  - months: ['january']
+    days_of_month: ['1']
+business_hours:
+  weekdays: ['monday:friday']
+  times: [{start_time: '09:00', end_time: '17:00'}]
+  except: ['holidays']
+support:
+  - business_hours
+  - weekdays: ['saturday']
+    times: [{start_time: '10:00', end_time: '14:00'}]
+    except: ['holidays']
+`,
+		contains: map[string][]string{
+			"holidays":       {"2020-12-25T10:00:00Z"},
+			"business_hours": {"2020-12-24T10:00:00Z"},
+			"support":        {"2020-12-24T10:00:00Z", "2020-12-19T11:00:00Z"},
+		},
+		excludes: map[string][]string{
+			"business_hours": {"2020-12-25T10:00:00Z", "2020-12-19T11:00:00Z"},
+			"support":        {"2020-12-25T10:00:00Z", "2020-12-26T11:00:00Z"},
+		},
+	},
+	{
+		in:          "a: ['b']\nb: ['a']",
+		expectError: true,
+	},
+	{
+		in:          "a: [{except: ['missing']}]",
+		expectError: true,
+	},
+	{
+		in:          "a: [{weekdays: ['funday']}]",
+		expectError: true,
+	},
+}
+
+func TestDefinitions(t *testing.T) {
+	for _, tc := range definitionsTestCases {
+		var defs Definitions
+		err := yaml.Unmarshal([]byte(tc.in), &defs)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		for name, times := range tc.contains {
+			for _, ts := range times {
+				if !defs[name].ContainsTime(mustParseTime(ts)) {
+					t.Errorf("Expected %s to contain %s", name, ts)
+				}
+			}
+		}
+		for name, times := range tc.excludes {
+			for _, ts := range times {
+				if defs[name].ContainsTime(mustParseTime(ts)) {
+					t.Errorf("Expected %s to exclude %s", name, ts)
+				}
+			}
+		}
+	}
+}
+
+func TestDefinitionNames(t *testing.T) {
+	var defs Definitions
+	in := "holidays: [{name: 'christmas', months: ['december'], days_of_month: ['25']}, {months: ['january'], days_of_month: ['1']}]"
+	if err := yaml.Unmarshal([]byte(in), &defs); err != nil {
+		t.Fatal(err)
+	}
+	if defs["holidays"][0].Name != "christmas" || defs["holidays"][1].Name != "holidays" {
+		t.Errorf("Expected intervals without a name to be named after their definition, got %+v", defs["holidays"])
+	}
+}
@@ -0,0 +1,324 @@
+package gotime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Describe returns an English sentence describing the times an interval contains, such as 'Active Monday through
+// Friday from 09:00 to 17:00, during January–March, in 2020–2025', for showing schedules in review tools and audit
+// logs. Intervals whose Mode is ModeDeny are described as inactive, as that is their effect in an IntervalSet.
+// Holiday calendars and calendar systems are described by the names they are registered under. Intervals that can
+// never match are described as never active.
+func (tp TimeInterval) Describe() string {
+	prefix := "Active"
+	if tp.Mode == ModeDeny {
+		prefix = "Inactive"
+	}
+	if tp.IsEmpty() {
+		return "Never " + strings.ToLower(prefix)
+	}
+	clauses := tp.describeClauses()
+	if len(clauses) == 0 {
+		return prefix + " at all times"
+	}
+	return prefix + " " + strings.Join(clauses, ", ")
+}
+
+// Describe returns a description of each interval in the set in turn. Where intervals deny times, later intervals
+// take precedence over earlier ones.
+func (is IntervalSet) Describe() string {
+	if len(is) == 0 {
+		return "Never active"
+	}
+	sentences := make([]string, len(is))
+	for i, ti := range is {
+		sentences[i] = ti.Describe()
+		if i > 0 {
+			sentences[i] = "then " + strings.ToLower(sentences[i][:1]) + sentences[i][1:]
+		}
+	}
+	return strings.Join(sentences, "; ")
+}
+
+// describeClauses returns the parts of an interval's description, which are joined by commas. The days of the week
+// and month and the times of day make up the first clause, as in 'Monday through Friday from 09:00 to 17:00'.
+func (tp TimeInterval) describeClauses() []string {
+	var head []string
+	if tp.Weekdays != nil {
+		head = append(head, describeWeekdays(tp.Weekdays))
+	}
+	if tp.DaysOfMonth != nil || tp.WeekdaysOfMonth != nil {
+		head = append(head, tp.describeDaysOfMonth())
+	}
+	if tp.Times != nil {
+		head = append(head, describeTimes(tp.Times))
+	}
+	var clauses []string
+	if head != nil {
+		clauses = append(clauses, strings.Join(head, " "))
+	}
+	if tp.Weeks != nil {
+		weeks := make([]string, len(tp.Weeks))
+		for i, r := range tp.Weeks {
+			weeks[i] = describeRange(r.InclusiveRange, func(week int) string { return fmt.Sprint(week) })
+		}
+		clauses = append(clauses, plural("in week", len(tp.Weeks) > 1 || tp.Weeks[0].Begin != tp.Weeks[0].End)+" "+
+			joinEnglish(weeks, "and"))
+	}
+	if tp.Months != nil {
+		months := make([]string, len(tp.Months))
+		for i, r := range tp.Months {
+			months[i] = describeRange(r.InclusiveRange, func(month int) string { return time.Month(month).String() })
+		}
+		clauses = append(clauses, "during "+joinEnglish(months, "and"))
+	}
+	fiscal := tp.usesFiscalYear() && tp.fiscalStartMonth() != time.January
+	if tp.Quarters != nil {
+		quarters := make([]string, len(tp.Quarters))
+		for i, r := range tp.Quarters {
+			quarters[i] = describeRange(r.InclusiveRange, func(quarter int) string { return fmt.Sprintf("Q%d", quarter) })
+		}
+		clause := "in " + joinEnglish(quarters, "and")
+		if fiscal && tp.Years == nil {
+			clause += " of the fiscal year"
+		}
+		clauses = append(clauses, clause)
+	}
+	if tp.Years != nil {
+		years := make([]string, len(tp.Years))
+		for i, r := range tp.Years {
+			years[i] = describeRange(r.InclusiveRange, func(year int) string { return fmt.Sprint(year) })
+		}
+		clause := "in "
+		if fiscal {
+			clause += plural("fiscal year", len(tp.Years) > 1 || tp.Years[0].Begin != tp.Years[0].End) + " "
+		}
+		clauses = append(clauses, clause+joinEnglish(years, "and"))
+	}
+	if fiscal {
+		clauses = append(clauses, "with fiscal years starting in "+tp.fiscalStartMonth().String())
+	}
+	if tp.Relative != nil {
+		days := make([]string, len(tp.Relative))
+		for i, r := range tp.Relative {
+			days[i] = r.describe()
+		}
+		clauses = append(clauses, joinEnglish(days, "and"))
+	}
+	if tp.Dates != nil {
+		dates := make([]string, len(tp.Dates))
+		for i, r := range tp.Dates {
+			dates[i] = "on " + r.Begin.Format(DateLayout)
+			if rataDie(r.End) != rataDie(r.Begin) {
+				dates[i] = "between " + r.Begin.Format(DateLayout) + " and " + r.End.Format(DateLayout)
+			}
+		}
+		clauses = append(clauses, joinEnglish(dates, "or"))
+	}
+	if tp.Absolute != nil {
+		periods := make([]string, len(tp.Absolute))
+		for i, r := range tp.Absolute {
+			periods[i] = "from " + r.Start.Format(time.RFC3339) + " until " + r.End.Format(time.RFC3339)
+		}
+		clauses = append(clauses, joinEnglish(periods, "or"))
+	}
+	if tp.Cycle != nil {
+		clauses = append(clauses, tp.Cycle.describe())
+	}
+	if tp.Calendar != nil {
+		dates := make([]string, len(tp.Calendar.Dates))
+		for i, r := range tp.Calendar.Dates {
+			dates[i] = r.Begin.String()
+			if r.End != r.Begin {
+				dates[i] += " through " + r.End.String()
+			}
+		}
+		clauses = append(clauses, "on "+joinEnglish(dates, "and")+" of the "+tp.Calendar.System+" calendar")
+	}
+	if tp.Holidays != nil {
+		clauses = append(clauses, "on "+tp.Holidays.Name+" holidays")
+	}
+	if tp.Expression != nil {
+		clauses = append(clauses, "when "+tp.Expression.String())
+	}
+	if tp.Location != nil {
+		clauses = append(clauses, tp.Location.String()+" time")
+	}
+	if tp.Coordinates != nil {
+		clauses = append(clauses, fmt.Sprintf("with the sun at %g, %g", tp.Coordinates.Latitude,
+			tp.Coordinates.Longitude))
+	}
+	if tp.DST.Gap == GapShift {
+		clauses = append(clauses, "moving times skipped by clocks going forward to after the gap")
+	}
+	switch tp.DST.Overlap {
+	case OverlapFirst:
+		clauses = append(clauses, "matching only the first of times repeated by clocks going back")
+	case OverlapSecond:
+		clauses = append(clauses, "matching only the second of times repeated by clocks going back")
+	}
+	for _, exception := range tp.Except {
+		except := exception.describeClauses()
+		if len(except) == 0 {
+			except = []string{"at all times"}
+		}
+		clause := "except " + strings.Join(except, ", ")
+		if len(except) > 1 {
+			clause = "except (" + strings.Join(except, ", ") + ")"
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses
+}
+
+// describeRange describes an inclusive range using name to describe each end, as in 'January–March'.
+func describeRange(r InclusiveRange, name func(int) string) string {
+	if r.Begin == r.End {
+		return name(r.Begin)
+	}
+	return name(r.Begin) + "–" + name(r.End)
+}
+
+func describeWeekdays(weekdays []WeekdayRange) string {
+	days := make([]string, len(weekdays))
+	for i, r := range weekdays {
+		days[i] = time.Weekday(r.Begin % 7).String()
+		if r.End != r.Begin {
+			days[i] += " through " + time.Weekday(r.End%7).String()
+		}
+	}
+	return joinEnglish(days, "and")
+}
+
+func describeTimes(times []TimeRange) string {
+	if len(times) == 0 {
+		return "at no time"
+	}
+	ranges := make([]string, len(times))
+	for i, tr := range times {
+		start, end := formatTime(tr.startSecond()), formatTime(tr.endSecond())
+		if tr.StartSolar != nil {
+			start = tr.StartSolar.describe()
+		}
+		if tr.EndSolar != nil {
+			end = tr.EndSolar.describe()
+		}
+		ranges[i] = start + " to " + end
+		if tr.StartSolar == nil && tr.EndSolar == nil && tr.isOvernight() {
+			ranges[i] += " the next day"
+		}
+	}
+	return "from " + joinEnglish(ranges, "and")
+}
+
+// describeDaysOfMonth describes the days of the month and weekdays of the month of an interval together, as either
+// may match.
+func (tp TimeInterval) describeDaysOfMonth() string {
+	var days []string
+	for _, r := range tp.DaysOfMonth {
+		day := describeDayOfMonth(r.Begin)
+		if r.End != r.Begin {
+			day += " to " + describeDayOfMonth(r.End)
+		}
+		days = append(days, day)
+	}
+	for _, w := range tp.WeekdaysOfMonth {
+		day := "weekday"
+		if !w.AnyWeekday {
+			day = w.Weekday.String()
+		}
+		occurrence := "last"
+		if w.N != lastOccurrence {
+			occurrence = ordinal(w.N)
+		}
+		days = append(days, "the "+occurrence+" "+day)
+	}
+	return "on " + joinEnglish(days, "or") + " of the month"
+}
+
+// describeDayOfMonth describes a day of the month, counting back from the end of the month if it is negative.
+func describeDayOfMonth(day int) string {
+	switch {
+	case day == -1:
+		return "the last day"
+	case day < 0:
+		return "the " + ordinal(-day) + " to last day"
+	}
+	return "the " + ordinal(day)
+}
+
+// ordinal returns the number followed by its English ordinal suffix, as in '1st' or '22nd'.
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+func (r RelativeRange) describe() string {
+	feast := strings.ToUpper(r.Feast[:1]) + r.Feast[1:]
+	if r.Begin == r.End {
+		return "on " + describeFeastOffset(feast, r.Begin)
+	}
+	return "from " + describeFeastOffset(feast, r.Begin) + " to " + describeFeastOffset(feast, r.End)
+}
+
+// describeFeastOffset describes a day a number of days from a feast, as in '2 days before Easter'.
+func describeFeastOffset(feast string, offset int) string {
+	switch {
+	case offset < 0:
+		return plural(fmt.Sprintf("%d day", -offset), offset < -1) + " before " + feast
+	case offset > 0:
+		return plural(fmt.Sprintf("%d day", offset), offset > 1) + " after " + feast
+	}
+	return feast
+}
+
+func (c Cycle) describe() string {
+	unit := "day"
+	if c.Unit == CycleWeeks {
+		unit = "week"
+	}
+	every := "every " + unit
+	if c.Every > 1 {
+		every = fmt.Sprintf("every %d %ss", c.Every, unit)
+	}
+	return every + " counting from " + c.Anchor.Format(DateLayout)
+}
+
+func (st SolarTime) describe() string {
+	event := solarEventsInv[st.Event]
+	offset := SolarTime{Offset: st.Offset}.String()
+	switch {
+	case st.Offset < 0:
+		return strings.TrimPrefix(offset, "-") + " before " + event
+	case st.Offset > 0:
+		return strings.TrimPrefix(offset, "+") + " after " + event
+	}
+	return event
+}
+
+// joinEnglish joins words into a list such as 'a, b and c', using the given conjunction.
+func joinEnglish(words []string, conjunction string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " " + conjunction + " " + words[len(words)-1]
+}
+
+// plural adds an s to the word if many is true.
+func plural(word string, many bool) string {
+	if many {
+		return word + "s"
+	}
+	return word
+}
@@ -0,0 +1,129 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	for _, c := range []struct {
+		ti   TimeInterval
+		want string
+	}{
+		{TimeInterval{}, "Active at all times"},
+		{TimeInterval{Weekdays: []WeekdayRange{}}, "Never active"},
+		{TimeInterval{Mode: ModeDeny, Times: []TimeRange{}}, "Never inactive"},
+		{
+			TimeInterval{
+				Dates:    []DateRange{{Begin: date(2024, time.March, 1), End: date(2024, time.March, 1)}},
+				Absolute: []AbsoluteRange{{Start: date(2025, time.January, 1), End: date(2025, time.January, 2)}},
+			},
+			"Never active",
+		},
+		{
+			TimeInterval{
+				Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+				Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+				Months:   []MonthRange{{InclusiveRange{Begin: 1, End: 3}}},
+				Years:    []YearRange{{InclusiveRange{Begin: 2020, End: 2025}}},
+			},
+			"Active Monday through Friday from 09:00 to 17:00, during January–March, in 2020–2025",
+		},
+		{
+			TimeInterval{
+				Mode: ModeDeny,
+				Times: []TimeRange{
+					{StartMinute: 1320, EndMinute: 360},
+					{StartMinute: 720, StartSecond: 30, EndMinute: 780},
+				},
+				Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 7}}, {InclusiveRange{Begin: 3, End: 3}}},
+			},
+			"Inactive Saturday through Sunday and Wednesday from 22:00 to 06:00 the next day and 12:00:30 to 13:00",
+		},
+		{
+			TimeInterval{
+				DaysOfMonth:     []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 7}}, {InclusiveRange{Begin: -2, End: -1}}},
+				WeekdaysOfMonth: []WeekdayOfMonth{{N: 2, Weekday: time.Tuesday}, {N: -1, AnyWeekday: true}},
+				Weeks:           []WeekRange{{InclusiveRange{Begin: 10, End: 10}}},
+				Location:        mustLoadLocation("Europe/London"),
+			},
+			"Active on the 1st to the 7th, the 2nd to last day to the last day, the 2nd Tuesday or the last weekday " +
+				"of the month, in week 10, Europe/London time",
+		},
+		{
+			TimeInterval{
+				Quarters:        []QuarterRange{{InclusiveRange{Begin: 1, End: 2}}},
+				Years:           []YearRange{{InclusiveRange{Begin: 2024, End: 2024}}},
+				FiscalYearStart: FiscalYearStart(time.July),
+			},
+			"Active in Q1–Q2, in fiscal year 2024, with fiscal years starting in July",
+		},
+		{
+			TimeInterval{
+				Relative: []RelativeRange{{Feast: "easter", InclusiveRange: InclusiveRange{Begin: -2, End: 1}}},
+				Cycle:    &Cycle{Anchor: date(2024, time.January, 1), Every: 2, Unit: CycleWeeks},
+			},
+			"Active from 2 days before Easter to 1 day after Easter, every 2 weeks counting from 2024-01-01",
+		},
+		{
+			TimeInterval{
+				Dates: []DateRange{
+					{Begin: date(2024, time.December, 24), End: date(2025, time.January, 2)},
+					{Begin: date(2025, time.March, 1), End: date(2025, time.March, 1)},
+				},
+				Absolute: []AbsoluteRange{{
+					Start: time.Date(2024, time.December, 31, 22, 0, 0, 0, time.UTC),
+					End:   time.Date(2025, time.January, 1, 2, 0, 0, 0, time.UTC),
+				}},
+			},
+			"Active between 2024-12-24 and 2025-01-02 or on 2025-03-01, from 2024-12-31T22:00:00Z until " +
+				"2025-01-01T02:00:00Z",
+		},
+		{
+			TimeInterval{
+				Times: []TimeRange{{
+					StartSolar: &SolarTime{Event: Sunrise, Offset: -30 * time.Minute},
+					EndSolar:   &SolarTime{Event: Sunset, Offset: time.Hour},
+				}},
+				Coordinates: &Coordinates{Latitude: 51.5074, Longitude: -0.1278},
+				DST:         DSTPolicy{Gap: GapShift, Overlap: OverlapFirst},
+			},
+			"Active from 30m before sunrise to 1h after sunset, with the sun at 51.5074, -0.1278, moving times " +
+				"skipped by clocks going forward to after the gap, matching only the first of times repeated by clocks " +
+				"going back",
+		},
+		{
+			TimeInterval{
+				Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+				Holidays: &Holidays{Name: "us-federal", HolidayProvider: newRuleCalendar(usFederalHolidays)},
+				Except: []TimeInterval{
+					{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 3, End: 3}}}},
+					{
+						Months:      []MonthRange{{InclusiveRange{Begin: 12, End: 12}}},
+						DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 25, End: 25}}},
+					},
+				},
+			},
+			"Active Monday through Friday, on us-federal holidays, except Wednesday, except (on the 25th of the month, " +
+				"during December)",
+		},
+	} {
+		if got := c.ti.Describe(); got != c.want {
+			t.Errorf("Expected %q, got %q", c.want, got)
+		}
+	}
+}
+
+func TestIntervalSetDescribe(t *testing.T) {
+	set := IntervalSet{
+		{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}},
+		{Mode: ModeDeny, Months: []MonthRange{{InclusiveRange{Begin: 12, End: 12}}}},
+	}
+	want := "Active Monday through Friday; then inactive during December"
+	if got := set.Describe(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := (IntervalSet{}).Describe(); got != "Never active" {
+		t.Errorf("Expected an empty set to be never active, got %q", got)
+	}
+}
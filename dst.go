@@ -0,0 +1,202 @@
+package gotime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A DSTPolicy controls how an interval's times of day are matched on days when clocks change for daylight saving
+// time. The zero value matches wall clock time, so times of day skipped when clocks go forward never match and times
+// of day repeated when clocks go back match both times they occur.
+type DSTPolicy struct {
+	Gap     GapPolicy     `yaml:"gap,omitempty"`
+	Overlap OverlapPolicy `yaml:"overlap,omitempty"`
+}
+
+// A GapPolicy decides what happens to times of day that don't exist because clocks have gone forward.
+type GapPolicy int
+
+const (
+	// GapSkip ignores times of day that don't exist, so a time range lying entirely within the gap never matches.
+	GapSkip GapPolicy = iota
+	// GapShift moves times of day that don't exist forward by the length of the gap, so time ranges keep their
+	// duration. For example, when clocks go forward from 02:00 to 03:00 a range from 02:30 to 02:45 matches from
+	// 03:30 to 03:45 instead.
+	GapShift
+)
+
+// An OverlapPolicy decides which occurrences of a time of day are matched when it occurs twice because clocks have
+// gone back.
+type OverlapPolicy int
+
+const (
+	// OverlapBoth matches both occurrences of a repeated time of day.
+	OverlapBoth OverlapPolicy = iota
+	// OverlapFirst resolves repeated times of day to their first occurrence. A time range ending within the repeated
+	// times therefore ends the first time its end is reached.
+	OverlapFirst
+	// OverlapSecond resolves repeated times of day to their second occurrence. A time range starting within the
+	// repeated times therefore starts the second time its start is reached.
+	OverlapSecond
+)
+
+var gapPolicies = map[string]GapPolicy{
+	"skip":  GapSkip,
+	"shift": GapShift,
+}
+
+var gapPoliciesInv = map[GapPolicy]string{
+	GapSkip:  "skip",
+	GapShift: "shift",
+}
+
+var overlapPolicies = map[string]OverlapPolicy{
+	"both":   OverlapBoth,
+	"first":  OverlapFirst,
+	"second": OverlapSecond,
+}
+
+var overlapPoliciesInv = map[OverlapPolicy]string{
+	OverlapBoth:   "both",
+	OverlapFirst:  "first",
+	OverlapSecond: "second",
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for GapPolicy.
+func (p *GapPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	policy, ok := gapPolicies[strings.ToLower(str)]
+	if !ok {
+		return fmt.Errorf("%s is not a valid DST gap policy", str)
+	}
+	*p = policy
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for GapPolicy
+func (p GapPolicy) MarshalYAML() (interface{}, error) {
+	str, ok := gapPoliciesInv[p]
+	if !ok {
+		return nil, fmt.Errorf("Unable to convert %d into DST gap policy", p)
+	}
+	return interface{}(str), nil
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for OverlapPolicy.
+func (p *OverlapPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	policy, ok := overlapPolicies[strings.ToLower(str)]
+	if !ok {
+		return fmt.Errorf("%s is not a valid DST overlap policy", str)
+	}
+	*p = policy
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for OverlapPolicy
+func (p OverlapPolicy) MarshalYAML() (interface{}, error) {
+	str, ok := overlapPoliciesInv[p]
+	if !ok {
+		return nil, fmt.Errorf("Unable to convert %d into DST overlap policy", p)
+	}
+	return interface{}(str), nil
+}
+
+// containsSecondAt returns true if the given second of the day, read from the wall clock at t, falls within one of
+// the interval's time ranges that started on the same day. On days when clocks go back and the interval only matches
+// one occurrence of repeated times, each range instead covers the continuous period between the chosen occurrences of
+// its start and end.
+func (tp TimeInterval) containsSecondAt(t time.Time, second int) bool {
+	if tp.Times == nil || tp.DST.Overlap == OverlapBoth {
+		return tp.containsSecond(second)
+	}
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	next := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	_, offset := day.Zone()
+	_, nextOffset := next.Zone()
+	if offset <= nextOffset {
+		return tp.containsSecond(second)
+	}
+	for _, tr := range tp.Times {
+		start := resolveWallTime(day, tr.startSecond(), tp.DST.Overlap)
+		end := next
+		if !tr.isOvernight() {
+			end = resolveWallTime(day, tr.endSecond(), tp.DST.Overlap)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveWallTime returns the instant at which the given second of the day occurs on the day starting at the given
+// midnight, choosing between occurrences according to the overlap policy if that time of day occurs twice.
+func resolveWallTime(day time.Time, second int, policy OverlapPolicy) time.Time {
+	t := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, second, 0, day.Location())
+	other, repeated := otherOccurrence(t)
+	if !repeated {
+		return t
+	}
+	if (policy == OverlapFirst) == other.Before(t) {
+		return other
+	}
+	return t
+}
+
+// shiftedSecond returns the second of the day that t would have had if clocks hadn't gone forward earlier in the day,
+// or false if clocks haven't gone forward or that second exists anyway.
+func shiftedSecond(t time.Time, second int) (int, bool) {
+	_, offset := t.Zone()
+	_, previousOffset := t.Add(-24 * time.Hour).Zone()
+	if offset <= previousOffset {
+		return 0, false
+	}
+	shifted := second - (offset - previousOffset)
+	if shifted < 0 {
+		return 0, false
+	}
+	wall := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, shifted, 0, t.Location())
+	if secondOfDay(wall) == shifted && wall.Day() == t.Day() {
+		return 0, false
+	}
+	return shifted, true
+}
+
+// otherOccurrence returns the other instant at which the wall clock shows the same time as at t, or false if the
+// clocks don't go back around t.
+func otherOccurrence(t time.Time) (time.Time, bool) {
+	_, offset := t.Zone()
+	for _, probe := range []time.Time{t.Add(-24 * time.Hour), t.Add(24 * time.Hour)} {
+		_, probeOffset := probe.Zone()
+		if probeOffset == offset {
+			continue
+		}
+		other := t.Add(time.Duration(offset-probeOffset) * time.Second)
+		if !other.Equal(t) && sameWallTime(t, other) {
+			return other, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func sameWallTime(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd && a.Hour() == b.Hour() && a.Minute() == b.Minute() && a.Second() == b.Second()
+}
+
+// isClockChangeDay returns true if the clocks change at some point during the day starting at the given midnight.
+func isClockChangeDay(day time.Time) bool {
+	next := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+	_, offset := day.Zone()
+	_, nextOffset := next.Zone()
+	return offset != nextOffset
+}
@@ -0,0 +1,172 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// In New York, clocks went forward from 02:00 to 03:00 on 14 March 2021 and back from 02:00 to 01:00 on
+// 7 November 2021.
+var dstTestCases = []struct {
+	timeInterval TimeInterval
+	contains     []string
+	excludes     []string
+	from, to     string
+	windows      [][2]string
+}{
+	{
+		// Skipped times never happen by default
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 150, EndMinute: 165}},
+			Location: mustLoadLocation("America/New_York"),
+		},
+		excludes: []string{"2021-03-14T07:30:00Z", "2021-03-14T06:59:00Z"},
+		from:     "2021-03-14T05:00:00Z",
+		to:       "2021-03-15T04:00:00Z",
+		windows:  nil,
+	},
+	{
+		// Skipped times can be shifted forward by the length of the gap
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 150, EndMinute: 165}},
+			Location: mustLoadLocation("America/New_York"),
+			DST:      DSTPolicy{Gap: GapShift},
+		},
+		contains: []string{"2021-03-14T07:30:00Z", "2021-03-14T07:44:00Z", "2021-03-15T06:30:00Z"},
+		excludes: []string{"2021-03-14T07:45:00Z", "2021-03-14T08:30:00Z"},
+		from:     "2021-03-14T05:00:00Z",
+		to:       "2021-03-15T04:00:00Z",
+		windows: [][2]string{
+			{"2021-03-14T07:30:00Z", "2021-03-14T07:45:00Z"},
+		},
+	},
+	{
+		// A range straddling the gap keeps its duration when shifted
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 90, EndMinute: 150}},
+			Location: mustLoadLocation("America/New_York"),
+			DST:      DSTPolicy{Gap: GapShift},
+		},
+		from: "2021-03-14T05:00:00Z",
+		to:   "2021-03-15T04:00:00Z",
+		windows: [][2]string{
+			{"2021-03-14T06:30:00Z", "2021-03-14T07:30:00Z"},
+		},
+	},
+	{
+		// Repeated times match twice by default
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 90, EndMinute: 105}},
+			Location: mustLoadLocation("America/New_York"),
+		},
+		contains: []string{"2021-11-07T05:30:00Z", "2021-11-07T06:30:00Z"},
+		from:     "2021-11-07T04:00:00Z",
+		to:       "2021-11-08T05:00:00Z",
+		windows: [][2]string{
+			{"2021-11-07T05:30:00Z", "2021-11-07T05:45:00Z"},
+			{"2021-11-07T06:30:00Z", "2021-11-07T06:45:00Z"},
+		},
+	},
+	{
+		// Only the first occurrence of repeated times
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 90, EndMinute: 105}},
+			Location: mustLoadLocation("America/New_York"),
+			DST:      DSTPolicy{Overlap: OverlapFirst},
+		},
+		contains: []string{"2021-11-07T05:30:00Z"},
+		excludes: []string{"2021-11-07T06:30:00Z"},
+		from:     "2021-11-07T04:00:00Z",
+		to:       "2021-11-08T05:00:00Z",
+		windows: [][2]string{
+			{"2021-11-07T05:30:00Z", "2021-11-07T05:45:00Z"},
+		},
+	},
+	{
+		// Ranges ending after the repeated times are continuous
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 60, EndMinute: 180}},
+			Location: mustLoadLocation("America/New_York"),
+			DST:      DSTPolicy{Overlap: OverlapFirst},
+		},
+		contains: []string{"2021-11-07T05:30:00Z", "2021-11-07T06:30:00Z"},
+		from:     "2021-11-07T04:00:00Z",
+		to:       "2021-11-08T05:00:00Z",
+		windows: [][2]string{
+			{"2021-11-07T05:00:00Z", "2021-11-07T08:00:00Z"},
+		},
+	},
+	{
+		// Only the second occurrence of repeated times
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 90, EndMinute: 105}},
+			Location: mustLoadLocation("America/New_York"),
+			DST:      DSTPolicy{Overlap: OverlapSecond},
+		},
+		contains: []string{"2021-11-07T06:30:00Z"},
+		excludes: []string{"2021-11-07T05:30:00Z"},
+		from:     "2021-11-07T04:00:00Z",
+		to:       "2021-11-08T05:00:00Z",
+		windows: [][2]string{
+			{"2021-11-07T06:30:00Z", "2021-11-07T06:45:00Z"},
+		},
+	},
+	{
+		// Ranges starting before the repeated times are continuous
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 30, EndMinute: 105}},
+			Location: mustLoadLocation("America/New_York"),
+			DST:      DSTPolicy{Overlap: OverlapSecond},
+		},
+		contains: []string{"2021-11-07T05:30:00Z", "2021-11-07T06:30:00Z"},
+		from:     "2021-11-07T04:00:00Z",
+		to:       "2021-11-08T05:00:00Z",
+		windows: [][2]string{
+			{"2021-11-07T04:30:00Z", "2021-11-07T06:45:00Z"},
+		},
+	},
+}
+
+func TestDSTPolicy(t *testing.T) {
+	for _, tc := range dstTestCases {
+		for _, ts := range tc.contains {
+			if !tc.timeInterval.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %+v to contain %s", tc.timeInterval.DST, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if tc.timeInterval.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %+v to exclude %s", tc.timeInterval.DST, ts)
+			}
+		}
+		var want []Window
+		for _, w := range tc.windows {
+			want = append(want, Window{Start: mustParseTime(w[0]), End: mustParseTime(w[1])})
+		}
+		if got := tc.timeInterval.Windows(mustParseTime(tc.from), mustParseTime(tc.to)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Windows for %+v with %+v: want %v, got %v", tc.timeInterval.Times, tc.timeInterval.DST, want, got)
+		}
+	}
+}
+
+func TestDSTPolicyYaml(t *testing.T) {
+	var ti TimeInterval
+	if err := yaml.Unmarshal([]byte("{dst: {gap: shift, overlap: first}}"), &ti); err != nil {
+		t.Fatalf("Received unexpected error: %v", err)
+	}
+	if want := (DSTPolicy{Gap: GapShift, Overlap: OverlapFirst}); ti.DST != want {
+		t.Errorf("Want %+v, got %+v", want, ti.DST)
+	}
+	if err := yaml.Unmarshal([]byte("{dst: {gap: leap}}"), &ti); err == nil {
+		t.Errorf("Expected error for invalid gap policy but didn't receive one")
+	}
+	out, err := yaml.Marshal(TimeInterval{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "{}\n" {
+		t.Errorf("Expected the default policy to be omitted, got %s", out)
+	}
+}
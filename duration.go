@@ -0,0 +1,67 @@
+package gotime
+
+import "time"
+
+// ActiveDuration returns the amount of time between from and to during which the TimeInterval is active, e.g. the
+// business hours elapsed between a ticket being opened and closed. Partially covered windows at either end only count
+// the covered portion. If to is before from, the result is negative.
+func (tp TimeInterval) ActiveDuration(from, to time.Time) time.Duration {
+	if to.Before(from) {
+		return -tp.ActiveDuration(to, from)
+	}
+	var total time.Duration
+	for _, w := range tp.Windows(from, to) {
+		total += w.Duration()
+	}
+	return total
+}
+
+// AddActiveDuration returns the time at which d of active time will have elapsed since start, counting only time
+// during which the TimeInterval is active, e.g. the deadline four business hours from now. If start falls outside the
+// interval, counting begins at the start of the next window. A non-positive d returns start unchanged. If the interval
+// does not accumulate d of active time within the search horizon, the zero Time is returned.
+func (tp TimeInterval) AddActiveDuration(start time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return start
+	}
+	remaining := d
+	var deadline time.Time
+	tp.walkWindows(start, start.AddDate(maxSearchYears, 0, 0), func(w Window) bool {
+		if w.Start.Before(start) {
+			w.Start = start
+		}
+		if w.Duration() >= remaining {
+			deadline = w.Start.Add(remaining)
+			return false
+		}
+		remaining -= w.Duration()
+		return true
+	})
+	return deadline
+}
+
+// ActiveDays returns the number of calendar days between from and to on which the TimeInterval is active at least
+// once. Only the portions of the first and last day that fall within the bounds are considered.
+func (tp TimeInterval) ActiveDays(from, to time.Time) int {
+	return len(tp.ActiveDates(from, to))
+}
+
+// ActiveDates returns midnight of each calendar day between from and to on which the TimeInterval is active at least
+// once, in chronological order. Days are evaluated in the interval's location if it has one, otherwise in the
+// location of from.
+func (tp TimeInterval) ActiveDates(from, to time.Time) []time.Time {
+	var dates []time.Time
+	loc := tp.inLocation(from).Location()
+	for _, w := range tp.Windows(from, to) {
+		start := w.Start.In(loc)
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		if len(dates) > 0 && dates[len(dates)-1].Equal(day) {
+			day = day.AddDate(0, 0, 1)
+		}
+		for day.Before(w.End) {
+			dates = append(dates, day)
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	return dates
+}
@@ -0,0 +1,171 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var activeDurationTestCases = []struct {
+	timeInterval TimeInterval
+	from         string
+	to           string
+	duration     time.Duration
+}{
+	{
+		// Ticket opened Friday afternoon and closed Monday morning
+		timeInterval: businessHours,
+		from:         "2020-07-10T15:30:00Z",
+		to:           "2020-07-13T09:45:00Z",
+		duration:     2*time.Hour + 15*time.Minute,
+	},
+	{
+		// Entirely outside business hours
+		timeInterval: businessHours,
+		from:         "2020-07-11T10:00:00Z",
+		to:           "2020-07-12T10:00:00Z",
+		duration:     0,
+	},
+	{
+		// A full working week
+		timeInterval: businessHours,
+		from:         "2020-07-06T00:00:00Z",
+		to:           "2020-07-13T00:00:00Z",
+		duration:     40 * time.Hour,
+	},
+	{
+		// Reversed bounds give a negative duration
+		timeInterval: businessHours,
+		from:         "2020-07-06T10:00:00Z",
+		to:           "2020-07-06T09:00:00Z",
+		duration:     -time.Hour,
+	},
+	{
+		timeInterval: TimeInterval{},
+		from:         "2020-07-06T10:00:00Z",
+		to:           "2020-07-08T09:00:00Z",
+		duration:     47 * time.Hour,
+	},
+}
+
+func TestActiveDuration(t *testing.T) {
+	for _, tc := range activeDurationTestCases {
+		got := tc.timeInterval.ActiveDuration(mustParseTime(tc.from), mustParseTime(tc.to))
+		if got != tc.duration {
+			t.Errorf("ActiveDuration for %+v between %s and %s: want %v, got %v", tc.timeInterval, tc.from, tc.to, tc.duration, got)
+		}
+	}
+}
+
+var addActiveDurationTestCases = []struct {
+	timeInterval TimeInterval
+	start        string
+	duration     time.Duration
+	deadline     string
+}{
+	{
+		// Four business hours from Friday afternoon lands on Monday morning
+		timeInterval: businessHours,
+		start:        "2020-07-10T15:00:00Z",
+		duration:     4 * time.Hour,
+		deadline:     "2020-07-13T11:00:00Z",
+	},
+	{
+		// Starting outside business hours waits for the next window
+		timeInterval: businessHours,
+		start:        "2020-07-11T12:00:00Z",
+		duration:     30 * time.Minute,
+		deadline:     "2020-07-13T09:30:00Z",
+	},
+	{
+		// Exactly filling a window ends at the window's end
+		timeInterval: businessHours,
+		start:        "2020-07-10T09:00:00Z",
+		duration:     8 * time.Hour,
+		deadline:     "2020-07-10T17:00:00Z",
+	},
+	{
+		timeInterval: businessHours,
+		start:        "2020-07-10T09:00:00Z",
+		duration:     0,
+		deadline:     "2020-07-10T09:00:00Z",
+	},
+	{
+		// Not enough active time before the interval's final year
+		timeInterval: TimeInterval{
+			Years: []YearRange{{InclusiveRange{Begin: 2020, End: 2020}}},
+		},
+		start:    "2020-12-31T00:00:00Z",
+		duration: 48 * time.Hour,
+		deadline: "",
+	},
+}
+
+func TestAddActiveDuration(t *testing.T) {
+	for _, tc := range addActiveDurationTestCases {
+		var want time.Time
+		if tc.deadline != "" {
+			want = mustParseTime(tc.deadline)
+		}
+		got := tc.timeInterval.AddActiveDuration(mustParseTime(tc.start), tc.duration)
+		if !got.Equal(want) {
+			t.Errorf("AddActiveDuration for %+v from %s by %v: want %v, got %v", tc.timeInterval, tc.start, tc.duration, want, got)
+		}
+	}
+}
+
+var activeDatesTestCases = []struct {
+	timeInterval TimeInterval
+	from         string
+	to           string
+	dates        []string
+}{
+	{
+		// Weekdays in a week running Wednesday to Wednesday
+		timeInterval: businessHours,
+		from:         "2020-07-08T00:00:00Z",
+		to:           "2020-07-15T00:00:00Z",
+		dates: []string{
+			"2020-07-08T00:00:00Z", "2020-07-09T00:00:00Z", "2020-07-10T00:00:00Z", "2020-07-13T00:00:00Z", "2020-07-14T00:00:00Z",
+		},
+	},
+	{
+		// Business hours have already ended on the first day and not yet begun on the last
+		timeInterval: businessHours,
+		from:         "2020-07-08T18:00:00Z",
+		to:           "2020-07-10T08:00:00Z",
+		dates:        []string{"2020-07-09T00:00:00Z"},
+	},
+	{
+		// A window spanning several days counts each of them
+		timeInterval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}},
+		},
+		from:  "2020-07-08T00:00:00Z",
+		to:    "2020-07-15T00:00:00Z",
+		dates: []string{"2020-07-11T00:00:00Z", "2020-07-12T00:00:00Z"},
+	},
+	{
+		timeInterval: emptyInterval(),
+		from:         "2020-07-08T00:00:00Z",
+		to:           "2020-07-15T00:00:00Z",
+		dates:        nil,
+	},
+}
+
+func TestActiveDates(t *testing.T) {
+	for _, tc := range activeDatesTestCases {
+		from, to := mustParseTime(tc.from), mustParseTime(tc.to)
+		var want []time.Time
+		for _, d := range tc.dates {
+			want = append(want, mustParseTime(d))
+		}
+		got := tc.timeInterval.ActiveDates(from, to)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ActiveDates for %+v between %s and %s: want %v, got %v", tc.timeInterval, tc.from, tc.to, want, got)
+		}
+		if n := tc.timeInterval.ActiveDays(from, to); n != len(want) {
+			t.Errorf("ActiveDays for %+v between %s and %s: want %d, got %d", tc.timeInterval, tc.from, tc.to, len(want), n)
+		}
+	}
+}
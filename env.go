@@ -0,0 +1,62 @@
+package gotime
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// IntervalSetSeparator separates the intervals of an IntervalSet in its text form.
+const IntervalSetSeparator = ";"
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for IntervalSet. Intervals are given in the text
+// form of a TimeInterval and separated by semicolons or new lines, e.g. 'mon-fri 09:00-17:00; sat 10:00-14:00'. Empty
+// intervals between separators are ignored, so an empty string is a set that contains no times.
+func (is *IntervalSet) UnmarshalText(text []byte) error {
+	set := IntervalSet{}
+	for _, line := range strings.Split(string(text), "\n") {
+		for _, part := range strings.Split(line, IntervalSetSeparator) {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			var ti TimeInterval
+			if err := ti.UnmarshalText([]byte(part)); err != nil {
+				return err
+			}
+			set = append(set, ti)
+		}
+	}
+	*is = set
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for IntervalSet, separating the text forms of its
+// intervals with semicolons. An interval that is always active has an empty text form, so it is written as 'always'.
+func (is IntervalSet) MarshalText() ([]byte, error) {
+	parts := make([]string, len(is))
+	for i, ti := range is {
+		text, err := ti.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = string(text)
+		if parts[i] == "" {
+			parts[i] = "always"
+		}
+	}
+	return []byte(strings.Join(parts, IntervalSetSeparator+" ")), nil
+}
+
+// FromEnv loads an IntervalSet from the environment variable with the given name, e.g. GOTIME_SCHEDULE, which holds
+// intervals in their text form separated by semicolons or new lines. It returns an error if the variable isn't set.
+func FromEnv(name string) (IntervalSet, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("%s is not set", name)
+	}
+	var set IntervalSet
+	if err := set.UnmarshalText([]byte(value)); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return set, nil
+}
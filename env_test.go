@@ -0,0 +1,61 @@
+package gotime
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	const name = "GOTIME_TEST_SCHEDULE"
+	defer os.Unsetenv(name)
+
+	os.Setenv(name, "mon-fri 09:00-17:00; sat 10:00-14:00\nmode=deny months=dec days_of_month=25;")
+	set, err := FromEnv(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := IntervalSet{
+		{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+		{
+			Times:    []TimeRange{{StartMinute: 600, EndMinute: 840}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}},
+		},
+		{
+			Mode:        ModeDeny,
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 25, End: 25}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 12, End: 12}}},
+		},
+	}
+	if !reflect.DeepEqual(set, want) {
+		t.Errorf("Loading %s: want %+v, got %+v", name, want, set)
+	}
+	text, err := set.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantText := "monday:friday 09:00-17:00; saturday 10:00-14:00; mode=deny days_of_month=25 december"
+	if string(text) != wantText {
+		t.Errorf("Marshalling %s: want %q, got %q", name, wantText, text)
+	}
+
+	os.Setenv(name, "")
+	if set, err := FromEnv(name); err != nil || set == nil || len(set) != 0 {
+		t.Errorf("Expected an empty variable to give an empty set, got %+v, %v", set, err)
+	}
+	if text, err := (IntervalSet{{}}).MarshalText(); err != nil || string(text) != "always" {
+		t.Errorf("Expected an always active interval to be written as always, got %q, %v", text, err)
+	}
+
+	os.Setenv(name, "mon-fri; smarch")
+	if _, err := FromEnv(name); err == nil {
+		t.Errorf("Expected an error loading an invalid schedule")
+	}
+	os.Unsetenv(name)
+	if _, err := FromEnv(name); err == nil {
+		t.Errorf("Expected an error loading an unset variable")
+	}
+}
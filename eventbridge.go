@@ -0,0 +1,91 @@
+package gotime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventBridge allows years up to 2199, further than Quartz does.
+var eventBridgeYear = cronField{name: "year", min: 1970, max: 2199}
+
+var eventBridgeExpressionRE = regexp.MustCompile(`^(cron|rate)\((.*)\)$`)
+var eventBridgeRateRE = regexp.MustCompile(`^([1-9][0-9]*)\s+(minutes?|hours?|days?)$`)
+
+var eventBridgeRateUnits = map[string]time.Duration{
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+	"hour":    time.Hour,
+	"hours":   time.Hour,
+	"day":     24 * time.Hour,
+	"days":    24 * time.Hour,
+}
+
+// FromEventBridge converts an AWS EventBridge schedule expression into a TimeInterval that is active during each
+// minute in which the schedule would fire. Schedules are evaluated in UTC, so the interval's location is UTC.
+//
+// Cron expressions such as 'cron(0/15 9-17 ? * MON-FRI *)' have fields for minutes, hours, the day of the month, the
+// month, the day of the week and the year, read in the same way as by FromQuartz. Rate expressions such as
+// 'rate(5 minutes)' fire every so often from when the schedule was created, which is given by created. They are only
+// active from the day the schedule was created, and a rate in minutes or hours must divide a day evenly so that it
+// fires at the same times every day. created is ignored for cron expressions.
+func FromEventBridge(expr string, created time.Time) (TimeInterval, error) {
+	m := eventBridgeExpressionRE.FindStringSubmatch(strings.ToLower(strings.TrimSpace(expr)))
+	if m == nil {
+		return TimeInterval{}, fmt.Errorf("Couldn't parse EventBridge expression %s, invalid format", expr)
+	}
+	var ti TimeInterval
+	var err error
+	if m[1] == "cron" {
+		fields := strings.Fields(m[2])
+		if len(fields) != 6 {
+			return TimeInterval{}, fmt.Errorf("Couldn't parse EventBridge expression %s, expected 6 fields", expr)
+		}
+		// EventBridge has no field for seconds, so every second of the minute matches.
+		ti, err = fromQuartzFields(append([]string{"*"}, fields...), eventBridgeYear)
+	} else {
+		ti, err = fromEventBridgeRate(strings.TrimSpace(m[2]), created.In(time.UTC))
+	}
+	if err != nil {
+		return TimeInterval{}, err
+	}
+	ti.Location = &Location{time.UTC}
+	return ti, nil
+}
+
+// fromEventBridgeRate converts the body of an EventBridge rate expression, such as '5 minutes', into a TimeInterval
+// that is active during each minute the rate fires in, counting from created.
+func fromEventBridgeRate(rate string, created time.Time) (TimeInterval, error) {
+	m := eventBridgeRateRE.FindStringSubmatch(rate)
+	if m == nil {
+		return TimeInterval{}, fmt.Errorf("Couldn't parse EventBridge rate %s, invalid format", rate)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return TimeInterval{}, err
+	}
+	every := time.Duration(n) * eventBridgeRateUnits[m[2]]
+	anchor := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)
+	firstMinute := created.Hour()*60 + created.Minute()
+	day := make([]bool, secondsPerDay)
+	cycle := Cycle{Anchor: anchor, Every: 1, Unit: CycleDays}
+	if every >= 24*time.Hour {
+		cycle.Every = int(every / (24 * time.Hour))
+		for s := 0; s < 60; s++ {
+			day[firstMinute*60+s] = true
+		}
+	} else {
+		if (24*time.Hour)%every != 0 {
+			return TimeInterval{}, fmt.Errorf("%s is not a supported EventBridge rate: it must divide a day evenly", rate)
+		}
+		minutes := int(every / time.Minute)
+		for minute := firstMinute % minutes; minute < 24*60; minute += minutes {
+			for s := 0; s < 60; s++ {
+				day[minute*60+s] = true
+			}
+		}
+	}
+	return TimeInterval{Times: timesOfSeconds(day), Cycle: &cycle}, nil
+}
@@ -0,0 +1,139 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var fromEventBridgeTestCases = []struct {
+	expr        string
+	created     string
+	interval    *TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		expr: "cron(0/30 9-10 ? * MON-FRI *)",
+		interval: &TimeInterval{
+			Times: []TimeRange{
+				{StartMinute: 540, EndMinute: 541},
+				{StartMinute: 570, EndMinute: 571},
+				{StartMinute: 600, EndMinute: 601},
+				{StartMinute: 630, EndMinute: 631},
+			},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Location: &Location{time.UTC},
+		},
+		contains: []string{"2020-07-10T09:30:59Z", "2020-07-10T19:30:00+10:00"},
+		excludes: []string{"2020-07-10T09:31:00Z", "2020-07-10T09:30:00+10:00"},
+	},
+	{
+		expr: "cron(0 12 L * ? 2020-2150)",
+		interval: &TimeInterval{
+			Times:       []TimeRange{{StartMinute: 720, EndMinute: 721}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -1, End: -1}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2020, End: 2150}}},
+			Location:    &Location{time.UTC},
+		},
+	},
+	{
+		expr: "cron(15 10 ? * 6#3 *)",
+		interval: &TimeInterval{
+			Times:           []TimeRange{{StartMinute: 615, EndMinute: 616}},
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 3, Weekday: time.Friday}},
+			Location:        &Location{time.UTC},
+		},
+	},
+	{
+		expr:    "rate(6 hours)",
+		created: "2020-07-10T08:15:30Z",
+		interval: &TimeInterval{
+			Times: []TimeRange{
+				{StartMinute: 135, EndMinute: 136},
+				{StartMinute: 495, EndMinute: 496},
+				{StartMinute: 855, EndMinute: 856},
+				{StartMinute: 1215, EndMinute: 1216},
+			},
+			Cycle:    &Cycle{Anchor: time.Date(2020, 7, 10, 0, 0, 0, 0, time.UTC), Every: 1, Unit: CycleDays},
+			Location: &Location{time.UTC},
+		},
+		contains: []string{"2020-07-10T14:15:00Z", "2020-07-11T02:15:00Z"},
+		excludes: []string{"2020-07-09T14:15:00Z", "2020-07-10T14:16:00Z"},
+	},
+	{
+		expr:    "rate(1 minute)",
+		created: "2020-07-10T08:15:30Z",
+		interval: &TimeInterval{
+			Cycle:    &Cycle{Anchor: time.Date(2020, 7, 10, 0, 0, 0, 0, time.UTC), Every: 1, Unit: CycleDays},
+			Location: &Location{time.UTC},
+		},
+	},
+	{
+		expr:     "rate(3 days)",
+		created:  "2020-07-10T18:15:00+10:00",
+		contains: []string{"2020-07-10T08:15:00Z", "2020-07-13T08:15:00Z"},
+		excludes: []string{"2020-07-11T08:15:00Z", "2020-07-13T08:16:00Z"},
+	},
+	{
+		expr:        "rate(7 minutes)",
+		created:     "2020-07-10T08:15:30Z",
+		expectError: true,
+	},
+	{
+		expr:        "rate(0 minutes)",
+		expectError: true,
+	},
+	{
+		expr:        "rate(5 weeks)",
+		expectError: true,
+	},
+	{
+		expr:        "cron(0 12 * * MON *)",
+		expectError: true,
+	},
+	{
+		expr:        "cron(0 12 * * ?)",
+		expectError: true,
+	},
+	{
+		expr:        "0 12 * * ? *",
+		expectError: true,
+	},
+}
+
+func TestFromEventBridge(t *testing.T) {
+	for _, tc := range fromEventBridgeTestCases {
+		var created time.Time
+		if tc.created != "" {
+			created, _ = time.Parse(time.RFC3339, tc.created)
+		}
+		ti, err := FromEventBridge(tc.expr, created)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected an error converting %s, got %v", tc.expr, ti)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error converting %s: %v", tc.expr, err)
+			continue
+		}
+		if tc.interval != nil && !reflect.DeepEqual(ti, *tc.interval) {
+			t.Errorf("Converting %s gave %v, expected %v", tc.expr, ti, *tc.interval)
+		}
+		for _, c := range tc.contains {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if !ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to contain %s", tc.expr, c)
+			}
+		}
+		for _, c := range tc.excludes {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to exclude %s", tc.expr, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package gotime
+
+import (
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// withoutExceptions returns the interval with its exceptions removed.
+func (tp TimeInterval) withoutExceptions() TimeInterval {
+	tp.Except = nil
+	return tp
+}
+
+// exceptions returns the interval's exceptions as an IntervalSet, with those that have no location of their own given
+// the interval's.
+func (tp TimeInterval) exceptions() IntervalSet {
+	set := make(IntervalSet, len(tp.Except))
+	for i, ex := range tp.Except {
+		if ex.Location == nil {
+			ex.Location = tp.Location
+		}
+		if ex.Coordinates == nil {
+			ex.Coordinates = tp.Coordinates
+		}
+		set[i] = ex
+	}
+	return set
+}
+
+// subtractExceptions returns the parts of the window that none of the interval's exceptions match, in chronological
+// order.
+func (tp TimeInterval) subtractExceptions(w Window) []Window {
+	if len(tp.Except) == 0 {
+		return []Window{w}
+	}
+	var parts []Window
+	start := w.Start
+	for _, hole := range tp.exceptions().Windows(w.Start, w.End) {
+		if hole.Start.After(start) {
+			parts = append(parts, Window{Start: start, End: hole.Start})
+		}
+		start = hole.End
+	}
+	if w.End.After(start) {
+		parts = append(parts, Window{Start: start, End: w.End})
+	}
+	return parts
+}
+
+// exceptionsCoverAll returns true if the interval's exceptions leave none of it active. The search begins at the start
+// of the first year the interval can be active in and looks as far as one Gregorian cycle ahead.
+func (tp TimeInterval) exceptionsCoverAll() bool {
+	for _, ex := range tp.Except {
+		if ex.IsAlwaysActive() {
+			return true
+		}
+	}
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if year, ok := tp.firstYear(); ok {
+		start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if tp.Cycle != nil && tp.Cycle.Anchor.After(start) {
+		start = tp.Cycle.Anchor
+	}
+	_, ok := tp.NextActiveTime(start)
+	return !ok
+}
+
+// exceptionMatchers returns the interval without its exceptions followed by each of the exceptions, since the state
+// of the interval can only change when one of these does.
+func (tp TimeInterval) exceptionMatchers() []TransitionMatcher {
+	return append([]TransitionMatcher{tp.withoutExceptions()}, tp.exceptions().matchers()...)
+}
+
+// inheritSettings copies the locale, weekend and coordinates keys of an interval into each of its exceptions that doesn't set its
+// own, so that exceptions are written in the same terms as the interval they belong to. It returns false if nothing
+// was copied.
+func inheritSettings(fields yaml.MapSlice) bool {
+	var settings yaml.MapSlice
+	for _, field := range fields {
+		if key, _ := field.Key.(string); key == "locale" || key == "weekend" || key == "coordinates" {
+			settings = append(settings, field)
+		}
+	}
+	if len(settings) == 0 {
+		return false
+	}
+	inherited := false
+	for i, field := range fields {
+		if key, _ := field.Key.(string); key != "except" {
+			continue
+		}
+		list, ok := field.Value.([]interface{})
+		if !ok {
+			continue
+		}
+		out := make([]interface{}, len(list))
+		for j, v := range list {
+			out[j] = v
+			child, ok := v.(yaml.MapSlice)
+			if !ok {
+				continue
+			}
+			for _, setting := range settings {
+				if !hasKey(child, setting.Key) {
+					child = append(yaml.MapSlice{setting}, child...)
+					inherited = true
+				}
+			}
+			out[j] = child
+		}
+		fields[i].Value = out
+	}
+	return inherited
+}
+
+func hasKey(fields yaml.MapSlice, key interface{}) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var exceptTestCases = []struct {
+	in          string
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// Business hours except the last Friday of the month and lunch
+		in: `
+weekdays: ['monday:friday']
+times: [{start_time: '09:00', end_time: '17:00'}]
+except:
+  - days_of_month: ['last friday']
+  - times: [{start_time: '12:00', end_time: '13:00'}]
+`,
+		contains: []string{"2020-07-24T10:00:00Z", "2020-07-30T16:59:59Z"},
+		excludes: []string{"2020-07-31T10:00:00Z", "2020-07-24T12:30:00Z", "2020-07-25T10:00:00Z"},
+	},
+	{
+		// Exceptions share the locale and weekend of their interval
+		in: `
+locale: 'fr'
+weekend: ['vendredi:samedi']
+months: ['juillet']
+except:
+  - weekdays: ['weekend']
+`,
+		contains: []string{"2020-07-05T10:00:00Z", "2020-07-09T10:00:00Z"},
+		excludes: []string{"2020-07-10T10:00:00Z", "2020-07-11T10:00:00Z", "2020-08-05T10:00:00Z"},
+	},
+	{
+		// Exceptions may have exceptions of their own
+		in: `
+months: ['july']
+except:
+  - weekdays: ['saturday', 'sunday']
+    except:
+      - days_of_month: ['4']
+`,
+		contains: []string{"2020-07-04T10:00:00Z", "2020-07-06T10:00:00Z"},
+		excludes: []string{"2020-07-05T10:00:00Z", "2020-07-11T10:00:00Z"},
+	},
+	{
+		in:          "except: [{weekdays: ['funday']}]",
+		expectError: true,
+	},
+}
+
+func TestExcept(t *testing.T) {
+	for _, tc := range exceptTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestExceptWindows(t *testing.T) {
+	withoutLunch := businessHours
+	withoutLunch.Except = []TimeInterval{{Times: []TimeRange{{StartMinute: 720, EndMinute: 780}}}}
+	got := withoutLunch.Windows(mustParseTime("2020-07-06T00:00:00Z"), mustParseTime("2020-07-07T00:00:00Z"))
+	want := []Window{
+		{Start: mustParseTime("2020-07-06T09:00:00Z"), End: mustParseTime("2020-07-06T12:00:00Z")},
+		{Start: mustParseTime("2020-07-06T13:00:00Z"), End: mustParseTime("2020-07-06T17:00:00Z")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows of business hours without lunch: want %v, got %v", want, got)
+	}
+	next, ok := withoutLunch.NextTransition(mustParseTime("2020-07-06T10:00:00Z"))
+	checkTransition(t, "business hours without lunch", withoutLunch, "2020-07-06T12:00:00Z", next, ok)
+	prev, ok := withoutLunch.PreviousTransition(mustParseTime("2020-07-06T14:00:00Z"))
+	checkTransition(t, "business hours without lunch", withoutLunch, "2020-07-06T13:00:00Z", prev, ok)
+	if withoutLunch.IsAlwaysActive() || withoutLunch.IsEmpty() {
+		t.Errorf("Expected business hours without lunch to be neither always active nor empty")
+	}
+	nothing := businessHours
+	nothing.Except = []TimeInterval{{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}}}
+	if !nothing.IsEmpty() {
+		t.Errorf("Expected business hours except weekdays to be empty")
+	}
+	got2, err := Intersect(withoutLunch, TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got2.ContainsTime(mustParseTime("2020-07-06T10:00:00Z")) || got2.ContainsTime(mustParseTime("2020-07-06T12:30:00Z")) ||
+		got2.ContainsTime(mustParseTime("2020-07-07T10:00:00Z")) {
+		t.Errorf("Unexpected intersection of business hours without lunch with Mondays: %+v", got2)
+	}
+}
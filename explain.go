@@ -0,0 +1,192 @@
+package gotime
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// explainContextFields are the fields of a TimeInterval that don't restrict times themselves but affect how the
+// fields that do are evaluated, so they are kept when each field is explained on its own.
+var explainContextFields = map[string]bool{
+	"location": true, "coordinates": true, "dst": true, "fiscal_year_start": true,
+}
+
+// explainSkippedFields are the fields of a TimeInterval that aren't explained.
+var explainSkippedFields = map[string]bool{
+	"name": true, "description": true, "labels": true, "mode": true, "except": true,
+}
+
+// An Explanation breaks down why an interval does or doesn't contain a time, field by field, e.g. to find out why an
+// alert wasn't muted.
+type Explanation struct {
+	Interval TimeInterval
+	// Time is the time explained, in the interval's location.
+	Time   time.Time
+	Active bool
+	// Fields explains each field of the interval that is set, in the order they are declared in TimeInterval.
+	Fields []FieldExplanation
+	// Except explains each of the interval's exceptions, any one of which contains the time if the interval is
+	// otherwise active but contains it anyway.
+	Except []Explanation
+}
+
+// A FieldExplanation says whether one field of an interval allows a time on its own, and which of its ranges
+// matched it. The days_of_month and weekdays_of_month fields allow a day that either matches, so their Matched
+// reflects both.
+type FieldExplanation struct {
+	// Field is the field's YAML key, e.g. weekdays.
+	Field   string
+	Matched bool
+	Ranges  []RangeExplanation
+}
+
+// A RangeExplanation says whether one of the ranges of a field matched a time.
+type RangeExplanation struct {
+	// Range is the range in its text form, e.g. monday:friday.
+	Range   string
+	Matched bool
+}
+
+// Explain returns an explanation of whether the interval contains t. Each field is evaluated on its own in the
+// interval's location, so a field with overnight time ranges may match the part of a range carried over from the
+// previous day even if the other fields only allow that day. Active is always the same as ContainsTime.
+func (tp TimeInterval) Explain(t time.Time) Explanation {
+	e := Explanation{Interval: tp, Time: tp.inLocation(t), Active: tp.ContainsTime(t)}
+	v := reflect.ValueOf(tp)
+	typ := v.Type()
+	base := reflect.New(typ).Elem()
+	var fields []int
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("yaml"), ",")[0]
+		switch {
+		case explainContextFields[name]:
+			base.Field(i).Set(v.Field(i))
+		case !explainSkippedFields[name] && !isUnset(v.Field(i)):
+			fields = append(fields, i)
+		}
+	}
+	for _, i := range fields {
+		field := typ.Field(i)
+		// Days of the month and weekdays of the month are alternatives, so they are evaluated together.
+		alone := withFields(base, v, i)
+		if field.Name == "DaysOfMonth" || field.Name == "WeekdaysOfMonth" {
+			alone = withFields(base, v, fieldIndex(typ, "DaysOfMonth"), fieldIndex(typ, "WeekdaysOfMonth"))
+		}
+		fe := FieldExplanation{
+			Field:   strings.Split(field.Tag.Get("yaml"), ",")[0],
+			Matched: alone.Interface().(TimeInterval).ContainsTime(t),
+		}
+		value := v.Field(i)
+		if value.Kind() != reflect.Slice {
+			fe.Ranges = []RangeExplanation{{Range: explainValue(value.Interface()), Matched: fe.Matched}}
+		}
+		for j := 0; value.Kind() == reflect.Slice && j < value.Len(); j++ {
+			single := withFields(base, v)
+			single.Field(i).Set(value.Slice(j, j+1))
+			fe.Ranges = append(fe.Ranges, RangeExplanation{
+				Range:   explainValue(value.Index(j).Interface()),
+				Matched: single.Interface().(TimeInterval).ContainsTime(t),
+			})
+		}
+		e.Fields = append(e.Fields, fe)
+	}
+	for _, ex := range tp.Except {
+		if ex.Coordinates == nil {
+			ex.Coordinates = tp.Coordinates
+		}
+		if ex.Location == nil {
+			ex.Location = tp.Location
+		}
+		e.Except = append(e.Except, ex.Explain(t))
+	}
+	return e
+}
+
+// Explain returns an explanation of whether each of the set's intervals contains t. Where intervals deny times, the
+// last interval that contains t decides whether the set does.
+func (is IntervalSet) Explain(t time.Time) []Explanation {
+	explanations := make([]Explanation, len(is))
+	for i, ti := range is {
+		explanations[i] = ti.Explain(t)
+	}
+	return explanations
+}
+
+// String returns the explanation as indented lines of text, one for the interval and each of its fields and ranges.
+func (e Explanation) String() string {
+	var b strings.Builder
+	e.write(&b, "")
+	return b.String()
+}
+
+func (e Explanation) write(b *strings.Builder, indent string) {
+	name := e.Interval.Name
+	if name == "" {
+		name = "interval"
+	}
+	if e.Interval.Mode == ModeDeny {
+		name += " (deny)"
+	}
+	fmt.Fprintf(b, "%s%s at %s: %s\n", indent, name, e.Time.Format(time.RFC3339), explainResult(e.Active, "active",
+		"inactive"))
+	for _, f := range e.Fields {
+		fmt.Fprintf(b, "%s  %s: %s\n", indent, f.Field, explainResult(f.Matched, "matched", "rejected"))
+		for _, r := range f.Ranges {
+			fmt.Fprintf(b, "%s    %s: %s\n", indent, r.Range, explainResult(r.Matched, "matched", "no match"))
+		}
+	}
+	for i, ex := range e.Except {
+		fmt.Fprintf(b, "%s  except %d:\n", indent, i+1)
+		ex.write(b, indent+"    ")
+	}
+}
+
+func explainResult(ok bool, yes, no string) string {
+	if ok {
+		return yes
+	}
+	return no
+}
+
+// explainValue returns the text form of a field's value, or its YAML on one line if it has no text form.
+func explainValue(v interface{}) string {
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.Replace(strings.TrimSpace(string(out)), "\n", "; ", -1)
+}
+
+// isUnset returns true if a field of a TimeInterval is nil or its zero value.
+func isUnset(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Ptr, reflect.Map:
+		return v.IsNil()
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// withFields returns a copy of base with the given fields set from v.
+func withFields(base, v reflect.Value, fields ...int) reflect.Value {
+	out := reflect.New(base.Type()).Elem()
+	out.Set(base)
+	for _, i := range fields {
+		out.Field(i).Set(v.Field(i))
+	}
+	return out
+}
+
+func fieldIndex(typ reflect.Type, name string) int {
+	f, _ := typ.FieldByName(name)
+	return f.Index[0]
+}
@@ -0,0 +1,83 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	ti := TimeInterval{
+		Name:            "business_hours",
+		Times:           []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+		Weekdays:        []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		DaysOfMonth:     []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 7}}},
+		WeekdaysOfMonth: []WeekdayOfMonth{{N: 1, Weekday: time.Saturday}},
+		Location:        mustLoadLocation("Australia/Melbourne"),
+		Except:          []TimeInterval{{Name: "lunch", Times: []TimeRange{{StartMinute: 720, EndMinute: 780}}}},
+	}
+	cases := []struct {
+		at     string
+		active bool
+		fields []FieldExplanation
+		except []bool
+	}{
+		{
+			at:     "2024-07-01T00:00:00Z",
+			active: true,
+			fields: []FieldExplanation{
+				{Field: "times", Matched: true, Ranges: []RangeExplanation{{"09:00-17:00", true}}},
+				{Field: "weekdays", Matched: true, Ranges: []RangeExplanation{{"monday:friday", true}}},
+				{Field: "days_of_month", Matched: true, Ranges: []RangeExplanation{{"1:7", true}}},
+				{Field: "weekdays_of_month", Matched: true, Ranges: []RangeExplanation{{"saturday#1", false}}},
+			},
+			except: []bool{false},
+		},
+		{
+			// Lunchtime on the first Saturday.
+			at: "2024-07-06T02:30:00Z",
+			fields: []FieldExplanation{
+				{Field: "times", Matched: true, Ranges: []RangeExplanation{{"09:00-17:00", true}}},
+				{Field: "weekdays", Matched: false, Ranges: []RangeExplanation{{"monday:friday", false}}},
+				{Field: "days_of_month", Matched: true, Ranges: []RangeExplanation{{"1:7", true}}},
+				{Field: "weekdays_of_month", Matched: true, Ranges: []RangeExplanation{{"saturday#1", true}}},
+			},
+			except: []bool{true},
+		},
+	}
+	for _, c := range cases {
+		at, _ := time.Parse(time.RFC3339, c.at)
+		e := ti.Explain(at)
+		if e.Active != c.active || e.Active != ti.ContainsTime(at) {
+			t.Errorf("Expected %s to be active: %t, got %t", c.at, c.active, e.Active)
+		}
+		if !reflect.DeepEqual(e.Fields, c.fields) {
+			t.Errorf("Expected fields %v at %s, got %v", c.fields, c.at, e.Fields)
+		}
+		var except []bool
+		for _, ex := range e.Except {
+			except = append(except, ex.Active)
+		}
+		if !reflect.DeepEqual(except, c.except) {
+			t.Errorf("Expected exceptions %v at %s, got %v", c.except, c.at, except)
+		}
+	}
+
+	expected := `business_hours at 2024-07-06T12:30:00+10:00: inactive
+  times: matched
+    09:00-17:00: matched
+  weekdays: rejected
+    monday:friday: no match
+  except 1:
+    lunch at 2024-07-06T12:30:00+10:00: active
+      times: matched
+        12:00-13:00: matched
+`
+	ti.DaysOfMonth, ti.WeekdaysOfMonth = nil, nil
+	if s := ti.Explain(time.Date(2024, 7, 6, 2, 30, 0, 0, time.UTC)).String(); s != expected {
+		t.Errorf("Expected explanation:\n%s\ngot:\n%s", expected, s)
+	}
+	if n := len(IntervalSet{ti, {}}.Explain(time.Now())); n != 2 {
+		t.Errorf("Expected an explanation of each interval in a set, got %d", n)
+	}
+}
@@ -0,0 +1,478 @@
+package gotime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// An Expression is a condition on the time being matched, for rules that the other fields of an interval can't
+// express, e.g. expression: 'day % 2 == 0 && hour >= 9'. Expressions are written in a small language of integers and
+// booleans with the operators of Go: || && == != < <= > >= + - * / % and unary ! and -, grouped with parentheses.
+// The time is described by these variables, taken in the interval's location:
+//
+//	year, month, day     the date, with months from 1 to 12
+//	weekday              the day of the week, from 0 for Sunday to 6 for Saturday
+//	hour, minute, second the time of day
+//	yearday              the day of the year, from 1
+//	week                 the ISO 8601 week of the year
+//	days_in_month        the number of days in the month
+//
+// Weekday and month names stand for their numbers, e.g. 'weekday == friday && month != december', and true and false
+// are booleans. Division by zero makes the whole expression false. Expressions are compiled when they're parsed, so
+// mistakes such as unknown variables or comparing a boolean with a number are reported then.
+type Expression struct {
+	source string
+	root   exprNode
+	// precision is the variable with the shortest period that the expression uses, which decides how often its value
+	// can change.
+	precision int
+}
+
+// ParseExpression compiles the source of an expression, returning an error if it isn't valid or isn't a condition.
+func ParseExpression(source string) (*Expression, error) {
+	p := exprParser{source: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	root, typ, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %s", p.tokens[p.pos].text)
+	}
+	if typ != exprBool {
+		return nil, fmt.Errorf("Couldn't parse expression %s, expected a condition but it gives a number", source)
+	}
+	return &Expression{source: source, root: root, precision: p.precision}, nil
+}
+
+// String returns the source of the expression.
+func (e Expression) String() string {
+	return e.source
+}
+
+// Matches returns true if the expression holds at t, taking the variables in t's location.
+func (e Expression) Matches(t time.Time) bool {
+	if e.root == nil {
+		return false
+	}
+	vars := exprValues(t)
+	v, ok := e.root.eval(vars)
+	return ok && v != 0
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for Expression.
+func (e *Expression) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	parsed, err := ParseExpression(str)
+	if err != nil {
+		return err
+	}
+	*e = *parsed
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for Expression
+func (e Expression) MarshalYAML() (interface{}, error) {
+	if e.root == nil {
+		return nil, fmt.Errorf("Unable to marshal an expression that hasn't been parsed")
+	}
+	return interface{}(e.source), nil
+}
+
+// and returns an expression that holds when both e and other do.
+func (e Expression) and(other Expression) (*Expression, error) {
+	return ParseExpression("(" + e.source + ") && (" + other.source + ")")
+}
+
+// nextChange returns the earliest time after t at which the value of the expression might differ from its value at t,
+// which is the start of the next day, hour, minute or second depending on the variables it uses.
+func (e Expression) nextChange(t time.Time) time.Time {
+	var next time.Time
+	switch e.precision {
+	case exprSecond:
+		next = t.Truncate(time.Second).Add(time.Second)
+	case exprMinute:
+		next = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+	case exprHour:
+		next = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	default:
+		next = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	if !next.After(t) {
+		// Clocks going back can repeat a minute or hour, so fall back to a fixed step.
+		next = t.Add(time.Minute)
+	}
+	return next
+}
+
+// clipWindows returns the parts of the windows during which the expression holds, checking it once for each day, hour,
+// minute or second that the windows cover depending on the variables it uses.
+func (e Expression) clipWindows(windows []Window) []Window {
+	var out []Window
+	for _, w := range windows {
+		for start := w.Start; start.Before(w.End); {
+			end := e.nextChange(start)
+			if end.After(w.End) {
+				end = w.End
+			}
+			if e.Matches(start) {
+				if n := len(out); n > 0 && out[n-1].End.Equal(start) {
+					out[n-1].End = end
+				} else {
+					out = append(out, Window{Start: start, End: end})
+				}
+			}
+			start = end
+		}
+	}
+	return out
+}
+
+// Indexes of the variables of an expression, in order of decreasing period.
+const (
+	exprYear = iota
+	exprMonth
+	exprDay
+	exprWeekday
+	exprYearday
+	exprWeek
+	exprDaysInMonth
+	exprHour
+	exprMinute
+	exprSecond
+	exprVariableCount
+)
+
+// exprVariables maps the names of an expression's variables to their indexes.
+var exprVariables = map[string]int{
+	"year":          exprYear,
+	"month":         exprMonth,
+	"day":           exprDay,
+	"weekday":       exprWeekday,
+	"yearday":       exprYearday,
+	"week":          exprWeek,
+	"days_in_month": exprDaysInMonth,
+	"hour":          exprHour,
+	"minute":        exprMinute,
+	"second":        exprSecond,
+}
+
+// exprValues returns the values of an expression's variables at t.
+func exprValues(t time.Time) [exprVariableCount]int {
+	_, week := t.ISOWeek()
+	return [exprVariableCount]int{
+		exprYear:        t.Year(),
+		exprMonth:       int(t.Month()),
+		exprDay:         t.Day(),
+		exprWeekday:     int(t.Weekday()),
+		exprYearday:     t.YearDay(),
+		exprWeek:        week,
+		exprDaysInMonth: daysInMonthOf(t),
+		exprHour:        t.Hour(),
+		exprMinute:      t.Minute(),
+		exprSecond:      t.Second(),
+	}
+}
+
+// The types of the values in an expression.
+const (
+	exprInt = iota
+	exprBool
+)
+
+// An exprNode is a compiled part of an expression. Booleans are evaluated as 1 for true and 0 for false. Evaluation
+// returns false if it divides by zero. The variables are passed by value, as a pointer passed through the interface
+// would make them escape to the heap on every evaluation.
+type exprNode interface {
+	eval(vars [exprVariableCount]int) (int, bool)
+}
+
+type exprLiteral int
+
+func (n exprLiteral) eval([exprVariableCount]int) (int, bool) {
+	return int(n), true
+}
+
+type exprVariable int
+
+func (n exprVariable) eval(vars [exprVariableCount]int) (int, bool) {
+	return vars[n], true
+}
+
+type exprUnary struct {
+	op string
+	x  exprNode
+}
+
+func (n exprUnary) eval(vars [exprVariableCount]int) (int, bool) {
+	x, ok := n.x.eval(vars)
+	if !ok {
+		return 0, false
+	}
+	if n.op == "-" {
+		return -x, true
+	}
+	return boolInt(x == 0), true
+}
+
+type exprBinary struct {
+	op   string
+	x, y exprNode
+}
+
+func (n exprBinary) eval(vars [exprVariableCount]int) (int, bool) {
+	x, ok := n.x.eval(vars)
+	if !ok {
+		return 0, false
+	}
+	// The right hand side of && and || is only evaluated if it's needed, as in Go.
+	switch {
+	case n.op == "&&" && x == 0:
+		return 0, true
+	case n.op == "||" && x != 0:
+		return 1, true
+	}
+	y, ok := n.y.eval(vars)
+	if !ok {
+		return 0, false
+	}
+	switch n.op {
+	case "&&", "||":
+		return boolInt(y != 0), true
+	case "==":
+		return boolInt(x == y), true
+	case "!=":
+		return boolInt(x != y), true
+	case "<":
+		return boolInt(x < y), true
+	case "<=":
+		return boolInt(x <= y), true
+	case ">":
+		return boolInt(x > y), true
+	case ">=":
+		return boolInt(x >= y), true
+	case "+":
+		return x + y, true
+	case "-":
+		return x - y, true
+	case "*":
+		return x * y, true
+	case "/", "%":
+		if y == 0 {
+			return 0, false
+		}
+		if n.op == "/" {
+			return x / y, true
+		}
+		// Remainders are never negative, so that e.g. 'day % 7' is always a day of the week.
+		return floorMod(x, y), true
+	}
+	return 0, false
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// exprOperators holds the operators of the expression language, with those of two characters first so that they are
+// matched before their prefixes.
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")"}
+
+// exprLevels holds the binary operators of each level of precedence, from loosest to tightest, and the types of
+// their operands.
+var exprLevels = []struct {
+	ops     []string
+	operand int
+}{
+	{[]string{"||"}, exprBool},
+	{[]string{"&&"}, exprBool},
+	{[]string{"==", "!=", "<", "<=", ">", ">="}, exprInt},
+	{[]string{"+", "-"}, exprInt},
+	{[]string{"*", "/", "%"}, exprInt},
+}
+
+type exprToken struct {
+	text string
+	pos  int
+}
+
+// An exprParser compiles an expression by recursive descent, checking the types of its operands as it goes.
+type exprParser struct {
+	source    string
+	tokens    []exprToken
+	pos       int
+	precision int
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("Couldn't parse expression %s, %s", p.source, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) tokenize() error {
+	src := p.source
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case unicode.IsDigit(c) || unicode.IsLetter(c) || c == '_':
+			end := i
+			for end < len(src) && (unicode.IsDigit(rune(src[end])) || unicode.IsLetter(rune(src[end])) || src[end] == '_') {
+				end++
+			}
+			p.tokens = append(p.tokens, exprToken{text: src[i:end], pos: i})
+			i = end
+			continue
+		}
+		matched := false
+		for _, op := range exprOperators {
+			if strings.HasPrefix(src[i:], op) {
+				p.tokens = append(p.tokens, exprToken{text: op, pos: i})
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return p.errorf("unexpected %q at position %d", src[i], i+1)
+		}
+	}
+	return nil
+}
+
+// accept moves past the next token if it is one of the given operators, returning the operator.
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseOr() (exprNode, int, error) {
+	return p.parseLevel(0)
+}
+
+// parseLevel parses operands joined by the binary operators of the given level of precedence, which associate to the
+// left. Comparisons don't chain, so 'a < b < c' is an error.
+func (p *exprParser) parseLevel(level int) (exprNode, int, error) {
+	if level == len(exprLevels) {
+		return p.parseUnary()
+	}
+	l := exprLevels[level]
+	x, typ, err := p.parseLevel(level + 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	for {
+		op, ok := p.accept(l.ops...)
+		if !ok {
+			return x, typ, nil
+		}
+		y, ytyp, err := p.parseLevel(level + 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		if typ != l.operand || ytyp != l.operand {
+			return nil, 0, p.errorf("%s needs %s on both sides", op, exprTypeName(l.operand))
+		}
+		x, typ = exprBinary{op: op, x: x, y: y}, exprInt
+		if op == "&&" || op == "||" || level == 2 {
+			typ = exprBool
+		}
+		if level == 2 {
+			if _, ok := p.accept(l.ops...); ok {
+				return nil, 0, p.errorf("comparisons can't be chained, use && between them")
+			}
+			return x, typ, nil
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, int, error) {
+	op, ok := p.accept("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+	x, typ, err := p.parseUnary()
+	if err != nil {
+		return nil, 0, err
+	}
+	want := exprInt
+	if op == "!" {
+		want = exprBool
+	}
+	if typ != want {
+		return nil, 0, p.errorf("%s needs %s", op, exprTypeName(want))
+	}
+	return exprUnary{op: op, x: x}, typ, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, int, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, 0, p.errorf("unexpected end of expression")
+	}
+	if _, ok := p.accept("("); ok {
+		x, typ, err := p.parseOr()
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, 0, p.errorf("expected ')'")
+		}
+		return x, typ, nil
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	name := strings.ToLower(tok.text)
+	switch {
+	case unicode.IsDigit(rune(name[0])):
+		n, err := strconv.Atoi(name)
+		if err != nil {
+			return nil, 0, p.errorf("%s is not a valid number", tok.text)
+		}
+		return exprLiteral(n), exprInt, nil
+	case name == "true" || name == "false":
+		return exprLiteral(boolInt(name == "true")), exprBool, nil
+	}
+	if v, ok := exprVariables[name]; ok {
+		if v > p.precision {
+			p.precision = v
+		}
+		return exprVariable(v), exprInt, nil
+	}
+	if wd, ok := daysOfWeek[name]; ok {
+		return exprLiteral(wd), exprInt, nil
+	}
+	if m, ok := months[name]; ok {
+		return exprLiteral(m), exprInt, nil
+	}
+	if r := rune(name[0]); !unicode.IsLetter(r) && r != '_' {
+		return nil, 0, p.errorf("unexpected %s", tok.text)
+	}
+	return nil, 0, p.errorf("%s is not a known variable", tok.text)
+}
+
+func exprTypeName(typ int) string {
+	if typ == exprBool {
+		return "conditions"
+	}
+	return "numbers"
+}
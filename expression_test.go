@@ -0,0 +1,153 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestExpressionMatches(t *testing.T) {
+	// 2024-02-29 is a Thursday in ISO week 9.
+	at := time.Date(2024, time.February, 29, 9, 30, 15, 0, time.UTC)
+	for _, c := range []struct {
+		expr  string
+		match bool
+	}{
+		{"true", true},
+		{"!false && true", true},
+		{"year == 2024 && month == february && day == 29", true},
+		{"weekday == thursday", true},
+		{"weekday == Friday || weekday == saturday", false},
+		{"yearday == 60 && week == 9 && days_in_month == 29", true},
+		{"hour * 60 + minute >= 570", true},
+		{"hour * 60 + minute > 570", false},
+		{"second == 15", true},
+		{"day % 2 == 1", true},
+		{"-day % 7 == 6", true},
+		{"(day - 1) / 7 + 1 == 5", true},
+		{"day / 0 == 0", false},
+		{"day % 0 == 0 || true", false},
+		{"true || day / 0 == 0", true},
+		{"1 + 2 * 3 == 7 && !(1 > 2)", true},
+	} {
+		e, err := ParseExpression(c.expr)
+		if err != nil {
+			t.Errorf("Error parsing %s: %v", c.expr, err)
+			continue
+		}
+		if got := e.Matches(at); got != c.match {
+			t.Errorf("Expected %s to match %s: %t, got %t", c.expr, at, c.match, got)
+		}
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"day",
+		"day + 1",
+		"daytime == 1",
+		"day == true",
+		"!day",
+		"-(day > 1)",
+		"true && 1",
+		"1 < day < 3",
+		"(day == 1",
+		"day == 1)",
+		"day == 1 = 2",
+		"day == 99999999999999999999",
+		"day = 1",
+	} {
+		if e, err := ParseExpression(expr); err == nil {
+			t.Errorf("Expected an error parsing %q, got %v", expr, e)
+		}
+	}
+}
+
+func TestExpressionInterval(t *testing.T) {
+	var ti TimeInterval
+	in := `
+weekdays: ['monday:friday']
+times:
+  - start_time: '22:00'
+    end_time: '02:00'
+expression: 'day % 2 == 0 && (hour != 23 || minute < 30)'
+location: 'Australia/Melbourne'
+`
+	if err := yaml.UnmarshalStrict([]byte(in), &ti); err != nil {
+		t.Fatal(err)
+	}
+	loc := mustLoadLocation("Australia/Melbourne").Location
+	for _, c := range []struct {
+		at    time.Time
+		match bool
+	}{
+		{time.Date(2024, time.July, 2, 22, 15, 0, 0, loc), true},
+		{time.Date(2024, time.July, 2, 23, 15, 0, 0, loc), true},
+		{time.Date(2024, time.July, 2, 23, 45, 0, 0, loc), false},
+		{time.Date(2024, time.July, 3, 22, 15, 0, 0, loc), false},
+		// The expression is checked at the time being matched, so the hours after midnight need an even day too.
+		{time.Date(2024, time.July, 3, 1, 0, 0, 0, loc), false},
+		{time.Date(2024, time.July, 4, 1, 0, 0, 0, loc), true},
+		{time.Date(2024, time.July, 5, 1, 0, 0, 0, loc), false},
+		{time.Date(2024, time.July, 6, 1, 0, 0, 0, loc), true},
+	} {
+		if got := ti.ContainsTime(c.at); got != c.match {
+			t.Errorf("Expected %s to match: %t, got %t", c.at, c.match, got)
+		}
+	}
+	from := time.Date(2024, time.July, 1, 0, 0, 0, 0, loc)
+	want := []Window{
+		{Start: time.Date(2024, time.July, 2, 0, 0, 0, 0, loc), End: time.Date(2024, time.July, 2, 2, 0, 0, 0, loc)},
+		{Start: time.Date(2024, time.July, 2, 22, 0, 0, 0, loc), End: time.Date(2024, time.July, 2, 23, 30, 0, 0, loc)},
+		{Start: time.Date(2024, time.July, 4, 0, 0, 0, 0, loc), End: time.Date(2024, time.July, 4, 2, 0, 0, 0, loc)},
+		{Start: time.Date(2024, time.July, 4, 22, 0, 0, 0, loc), End: time.Date(2024, time.July, 4, 23, 30, 0, 0, loc)},
+		{Start: time.Date(2024, time.July, 6, 0, 0, 0, 0, loc), End: time.Date(2024, time.July, 6, 2, 0, 0, 0, loc)},
+	}
+	got := ti.Windows(from, from.AddDate(0, 0, 7))
+	if len(got) != len(want) {
+		t.Fatalf("Expected windows %v, got %v", want, got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("Expected window %v, got %v", want[i], got[i])
+		}
+	}
+	next, ok := ti.NextTransition(want[1].Start)
+	if !ok || !next.Equal(want[1].End) {
+		t.Errorf("Expected the next transition after %s to be %s, got %s", want[1].Start, want[1].End, next)
+	}
+	if ti.IsAlwaysActive() || ti.IsEmpty() {
+		t.Errorf("Expected an interval with an expression to be neither always active nor empty")
+	}
+	out, err := yaml.Marshal(ti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back TimeInterval
+	if err := yaml.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Expression == nil || back.Expression.String() != ti.Expression.String() {
+		t.Errorf("Expected the expression to survive marshalling, got\n%s", out)
+	}
+	if err := yaml.Unmarshal([]byte("expression: 'hour >'"), &back); err == nil {
+		t.Errorf("Expected an error unmarshalling an invalid expression")
+	}
+}
+
+func TestIntersectExpressions(t *testing.T) {
+	a, _ := ParseExpression("day % 2 == 0")
+	b, _ := ParseExpression("hour < 12 || hour > 20")
+	out, err := Intersect(TimeInterval{Expression: a}, TimeInterval{Expression: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Expression.String(); got != "(day % 2 == 0) && (hour < 12 || hour > 20)" {
+		t.Errorf("Expected the expressions to be joined with &&, got %s", got)
+	}
+	if out.ContainsTime(time.Date(2024, time.July, 2, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the intersection to hold only when both expressions do")
+	}
+}
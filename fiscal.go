@@ -0,0 +1,108 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A FiscalYearStart is the month in which an interval's fiscal year begins. When it is set to any month but January,
+// the interval's years and quarters are fiscal rather than calendar ones. Fiscal years are named after the calendar
+// year in which they end, so with a fiscal year starting in July, July 2023 to June 2024 is fiscal year 2024 and July
+// to September 2023 is its first quarter. The zero value means calendar years.
+type FiscalYearStart time.Month
+
+// A QuarterRange is an inclusive range between [1, 4] of the quarters of the year, which are fiscal quarters if the
+// interval has a FiscalYearStart
+type QuarterRange struct {
+	InclusiveRange
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for FiscalYearStart.
+func (f *FiscalYearStart) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	var r MonthRange
+	month, err := r.memberFromString(strings.ToLower(str))
+	if err != nil {
+		return err
+	}
+	if month < 1 || month > 12 {
+		return fmt.Errorf("%s is not a valid month: out of range", str)
+	}
+	*f = FiscalYearStart(month)
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for FiscalYearStart
+func (f FiscalYearStart) MarshalYAML() (interface{}, error) {
+	str, ok := monthsInv[int(f)]
+	if !ok {
+		return nil, fmt.Errorf("Unable to convert %d into month", f)
+	}
+	return interface{}(str), nil
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for QuarterRange.
+func (r *QuarterRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	if err := stringableRangeFromString(str, r); err != nil {
+		return err
+	}
+	if r.Begin < 1 || r.Begin > 4 {
+		return fmt.Errorf("%s is not a valid quarter: out of range", str)
+	}
+	if r.End < 1 || r.End > 4 {
+		return fmt.Errorf("%s is not a valid quarter: out of range", str)
+	}
+	if r.Begin > r.End {
+		return errors.New("Start quarter cannot be before End quarter")
+	}
+	return nil
+}
+
+func (r *QuarterRange) memberFromString(in string) (int, error) {
+	return r.InclusiveRange.memberFromString(strings.TrimPrefix(in, "q"))
+}
+
+// usesFiscalYear returns true if any of the interval's fields depend on when its year starts.
+func (tp TimeInterval) usesFiscalYear() bool {
+	return tp.Years != nil || tp.Quarters != nil
+}
+
+// fiscalStartMonth returns the month in which the interval's years begin.
+func (tp TimeInterval) fiscalStartMonth() time.Month {
+	if tp.FiscalYearStart < 1 || tp.FiscalYearStart > 12 {
+		return time.January
+	}
+	return time.Month(tp.FiscalYearStart)
+}
+
+// year returns the year of the interval that t falls in, which is the fiscal year if the interval has one.
+func (tp TimeInterval) year(t time.Time) int {
+	start := tp.fiscalStartMonth()
+	if start != time.January && t.Month() >= start {
+		return t.Year() + 1
+	}
+	return t.Year()
+}
+
+// quarter returns the quarter of the interval's year that t falls in, from 1 to 4.
+func (tp TimeInterval) quarter(t time.Time) int {
+	return int((t.Month()-tp.fiscalStartMonth()+12)%12)/3 + 1
+}
+
+// yearStart returns midnight at the start of the interval's year containing the given day.
+func (tp TimeInterval) yearStart(day time.Time) time.Time {
+	start := time.Date(day.Year(), tp.fiscalStartMonth(), 1, 0, 0, 0, 0, day.Location())
+	if start.After(day) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start
+}
@@ -0,0 +1,126 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var fiscalTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// Calendar quarters
+		in: "quarters: ['q1', '3:4']",
+		interval: TimeInterval{
+			Quarters: []QuarterRange{{InclusiveRange{Begin: 1, End: 1}}, {InclusiveRange{Begin: 3, End: 4}}},
+		},
+		contains: []string{"2020-03-31T23:59:59Z", "2020-07-01T00:00:00Z", "2020-12-31T12:00:00Z"},
+		excludes: []string{"2020-04-01T00:00:00Z", "2020-06-30T12:00:00Z"},
+	},
+	{
+		// The first quarter of fiscal year 2024, starting in July 2023
+		in: "{fiscal_year_start: 'july', quarters: ['Q1'], years: ['2024']}",
+		interval: TimeInterval{
+			FiscalYearStart: FiscalYearStart(time.July),
+			Quarters:        []QuarterRange{{InclusiveRange{Begin: 1, End: 1}}},
+			Years:           []YearRange{{InclusiveRange{Begin: 2024, End: 2024}}},
+		},
+		contains: []string{"2023-07-01T00:00:00Z", "2023-09-30T12:00:00Z"},
+		excludes: []string{"2023-06-30T12:00:00Z", "2023-10-01T00:00:00Z", "2024-07-01T12:00:00Z", "2024-01-15T12:00:00Z"},
+	},
+	{
+		// A fiscal year starting in October, given as a number
+		in: "{fiscal_year_start: '10', years: ['2021:2022']}",
+		interval: TimeInterval{
+			FiscalYearStart: FiscalYearStart(time.October),
+			Years:           []YearRange{{InclusiveRange{Begin: 2021, End: 2022}}},
+		},
+		contains: []string{"2020-10-01T00:00:00Z", "2022-09-30T23:59:59Z"},
+		excludes: []string{"2020-09-30T23:59:59Z", "2022-10-01T00:00:00Z"},
+	},
+	{
+		in:          "quarters: ['5']",
+		expectError: true,
+	},
+	{
+		in:          "quarters: ['q3:q2']",
+		expectError: true,
+	},
+	{
+		in:          "fiscal_year_start: 'smarch'",
+		expectError: true,
+	},
+}
+
+func TestFiscal(t *testing.T) {
+	for _, tc := range fiscalTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestFiscalWindows(t *testing.T) {
+	fy2024 := TimeInterval{
+		FiscalYearStart: FiscalYearStart(time.July),
+		Years:           []YearRange{{InclusiveRange{Begin: 2024, End: 2024}}},
+	}
+	got := fy2024.Windows(mustParseTime("2020-01-01T00:00:00Z"), mustParseTime("2030-01-01T00:00:00Z"))
+	want := []Window{{Start: mustParseTime("2023-07-01T00:00:00Z"), End: mustParseTime("2024-07-01T00:00:00Z")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows of fiscal year 2024: want %v, got %v", want, got)
+	}
+	prev, ok := fy2024.PreviousTransition(mustParseTime("2024-01-01T00:00:00Z"))
+	checkTransition(t, "fiscal year 2024", fy2024, "2023-07-01T00:00:00Z", prev, ok)
+	if fy2024.IsEmpty() {
+		t.Errorf("Expected fiscal year 2024 not to be empty")
+	}
+	firstHalf := TimeInterval{
+		FiscalYearStart: FiscalYearStart(time.July),
+		Quarters:        []QuarterRange{{InclusiveRange{Begin: 1, End: 2}}},
+		Months:          []MonthRange{{InclusiveRange{Begin: 1, End: 6}}},
+	}
+	if !firstHalf.IsEmpty() {
+		t.Errorf("Expected the first half of a fiscal year starting in July to exclude January to June")
+	}
+	calendar := TimeInterval{Quarters: []QuarterRange{{InclusiveRange{Begin: 1, End: 1}}}}
+	if _, err := Intersect(fy2024, calendar); err != ErrNotRepresentable {
+		t.Errorf("Expected intersecting fiscal and calendar quarters to be unrepresentable, got %v", err)
+	}
+}
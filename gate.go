@@ -0,0 +1,113 @@
+package gotime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A Gate admits work only while a schedule is active, e.g. to keep batch jobs within approved windows. Work can check
+// the gate with Allow, wait for it to open with Acquire, or watch for it to open or close with Changed. A Gate is
+// safe for concurrent use.
+type Gate struct {
+	m TransitionMatcher
+
+	mu      sync.Mutex
+	changed chan struct{}
+	next    time.Time
+}
+
+// NewGate returns a gate that is open while m is active. m is usually a TimeInterval or IntervalSet, but may be any
+// Matcher, in which case its transitions are found by checking it every minute.
+func NewGate(m Matcher) *Gate {
+	return &Gate{m: asTransitionMatcher(m)}
+}
+
+// Allow returns true if the gate is open now.
+func (g *Gate) Allow() bool {
+	return g.m.ContainsTime(time.Now())
+}
+
+// Acquire blocks until the gate is open or ctx is done, returning ctx.Err() in the latter case. It returns at once if
+// the gate is already open, and ErrNeverActive if the gate never opens. Nothing needs to be released afterwards, and
+// the gate may close while the caller is still working, which Changed can be used to watch for.
+func (g *Gate) Acquire(ctx context.Context) error {
+	return waitUntilActive(ctx, g.m)
+}
+
+// Check returns nil if the gate is open now, or an *InactiveError saying when it next opens if it's closed.
+func (g *Gate) Check() error {
+	now := time.Now()
+	if g.m.ContainsTime(now) {
+		return nil
+	}
+	next, _ := g.m.NextTransition(now)
+	return &InactiveError{At: now, Next: next}
+}
+
+// Changed returns a channel that is closed the next time the gate opens or closes. Every caller waiting at the time
+// is notified by the same channel, so Changed should be called again afterwards to wait for the following change.
+// The channel is never closed if the gate doesn't change within the search horizon.
+func (g *Gate) Changed() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.changed == nil {
+		g.changed = make(chan struct{})
+		var ok bool
+		if g.next, ok = g.m.NextTransition(time.Now()); ok {
+			g.arm()
+		}
+	}
+	return g.changed
+}
+
+// arm starts a timer that notifies waiters of the next change, waking at least every maxWait to allow for changes to
+// the wall clock. g.mu must be held.
+func (g *Gate) arm() {
+	wait := time.Until(g.next)
+	if wait > maxWait {
+		wait = maxWait
+	}
+	time.AfterFunc(wait, g.fire)
+}
+
+func (g *Gate) fire() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if time.Now().Before(g.next) {
+		g.arm()
+		return
+	}
+	close(g.changed)
+	g.changed = nil
+}
+
+// An InactiveError reports that a gate was closed when it was checked, and when it next opens, so that servers can
+// tell clients when to retry. It matches ErrNotActive with errors.Is.
+type InactiveError struct {
+	// At is when the gate was checked.
+	At time.Time
+	// Next is when the gate next opens, or the zero time if it doesn't within the search horizon.
+	Next time.Time
+}
+
+func (e *InactiveError) Error() string {
+	if e.Next.IsZero() {
+		return "Schedule is not active and won't become active"
+	}
+	return fmt.Sprintf("Schedule is not active until %s", e.Next.Format(time.RFC3339))
+}
+
+// Is returns true if target is ErrNotActive.
+func (e *InactiveError) Is(target error) bool {
+	return target == ErrNotActive
+}
+
+// RetryAfter returns how long after it was checked the gate opens, or false if it doesn't.
+func (e *InactiveError) RetryAfter() (time.Duration, bool) {
+	if e.Next.IsZero() {
+		return 0, false
+	}
+	return e.Next.Sub(e.At), true
+}
@@ -0,0 +1,88 @@
+package gotime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGate(t *testing.T) {
+	now := time.Now()
+	start, end := now.Add(100*time.Millisecond), now.Add(200*time.Millisecond)
+	g := NewGate(TimeInterval{Absolute: []AbsoluteRange{{Start: start, End: end}}})
+	if g.Allow() {
+		t.Errorf("Expected the gate to be closed before the window")
+	}
+	opened := g.Changed()
+	if g.Changed() != opened {
+		t.Errorf("Expected callers waiting for the same change to share a channel")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := g.Acquire(context.Background()); err != nil {
+				t.Error(err)
+			}
+			if !g.Allow() {
+				t.Errorf("Expected the gate to be open once acquired")
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case <-opened:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected to be notified when the gate opened")
+	}
+	if time.Now().Before(start) {
+		t.Errorf("Expected the gate to open at %s, opened at %s", start, time.Now())
+	}
+	select {
+	case <-g.Changed():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected to be notified when the gate closed")
+	}
+	if g.Allow() || time.Now().Before(end) {
+		t.Errorf("Expected the gate to close at %s", end)
+	}
+}
+
+func TestGateMatcher(t *testing.T) {
+	g := NewGate(MatcherFunc(func(time.Time) bool { return true }))
+	if !g.Allow() {
+		t.Errorf("Expected a gate on an always active matcher to be open")
+	}
+	closed := NewGate(MatcherFunc(func(time.Time) bool { return false }))
+	if err := closed.Acquire(context.Background()); err != ErrNeverActive {
+		t.Errorf("Expected ErrNeverActive acquiring a gate that never opens, got %v", err)
+	}
+}
+
+func TestGateCheck(t *testing.T) {
+	if err := NewGate(TimeInterval{}).Check(); err != nil {
+		t.Errorf("Expected an open gate to pass the check, got %v", err)
+	}
+	later := startingIn(time.Hour)
+	err := NewGate(later).Check()
+	var inactive *InactiveError
+	if !errors.As(err, &inactive) || !errors.Is(err, ErrNotActive) {
+		t.Fatalf("Expected an InactiveError matching ErrNotActive, got %v", err)
+	}
+	if retry, ok := inactive.RetryAfter(); !ok || retry < 59*time.Minute || retry > time.Hour {
+		t.Errorf("Expected to retry in an hour, got %s", retry)
+	}
+	if !inactive.Next.Equal(later.Absolute[0].Start) {
+		t.Errorf("Expected the gate to open at %s, got %s", later.Absolute[0].Start, inactive.Next)
+	}
+	err = NewGate(TimeInterval{Weekdays: []WeekdayRange{}}).Check()
+	if !errors.As(err, &inactive) {
+		t.Fatalf("Expected an InactiveError, got %v", err)
+	}
+	if _, ok := inactive.RetryAfter(); ok {
+		t.Errorf("Expected no time to retry at for a gate that never opens, got %s", inactive.Next)
+	}
+}
@@ -2,4 +2,7 @@ module github.com/benridley/gotime
 
 go 1.14
 
-require gopkg.in/yaml.v2 v2.3.0
+require (
+	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -7,23 +7,67 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 // TimeInterval describes intervals of time. ContainsTime will tell you if a golang time is contained
 // within the interval.
 type TimeInterval struct {
+	// Name, Description and Labels describe the interval to people and other systems, and have no effect on which
+	// times it contains.
+	Name        string            `yaml:"name,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	// Mode decides whether the interval adds times to an IntervalSet or takes them away.
+	Mode        Mode              `yaml:"mode,omitempty"`
 	Times       []TimeRange       `yaml:"times,omitempty"`
 	Weekdays    []WeekdayRange    `yaml:"weekdays,flow,omitempty"`
 	DaysOfMonth []DayOfMonthRange `yaml:"days_of_month,flow,omitempty"`
-	Months      []MonthRange      `yaml:"months,flow,omitempty"`
-	Years       []YearRange       `yaml:"years,flow,omitempty"`
+	// WeekdaysOfMonth may also be given in the days_of_month field. A day matches if it is in any of the ranges of
+	// DaysOfMonth or is any of the WeekdaysOfMonth.
+	WeekdaysOfMonth []WeekdayOfMonth `yaml:"weekdays_of_month,flow,omitempty"`
+	Weeks           []WeekRange      `yaml:"weeks,flow,omitempty"`
+	Months          []MonthRange     `yaml:"months,flow,omitempty"`
+	Quarters        []QuarterRange   `yaml:"quarters,flow,omitempty"`
+	Years           []YearRange      `yaml:"years,flow,omitempty"`
+	Relative        []RelativeRange  `yaml:"relative,flow,omitempty"`
+	Dates           []DateRange      `yaml:"dates,flow,omitempty"`
+	Absolute        []AbsoluteRange  `yaml:"absolute,omitempty"`
+	FiscalYearStart FiscalYearStart  `yaml:"fiscal_year_start,omitempty"`
+	Cycle           *Cycle           `yaml:"cycle,omitempty"`
+	Calendar        *CalendarDates   `yaml:"calendar,omitempty"`
+	Holidays        *Holidays        `yaml:"holidays,omitempty"`
+	Expression      *Expression      `yaml:"expression,omitempty"`
+	Location        *Location        `yaml:"location,omitempty"`
+	Coordinates     *Coordinates     `yaml:"coordinates,omitempty"`
+	DST             DSTPolicy        `yaml:"dst,omitempty"`
+	// Except holds intervals that carve holes out of this one: a time is contained only if this interval matches it
+	// and none of the exceptions do. Exceptions without a Location are matched in this interval's location.
+	Except []TimeInterval `yaml:"except,omitempty"`
+}
+
+// Location wraps a time.Location so that it can be represented by its IANA time zone name, e.g. Australia/Melbourne.
+// When an interval has a Location, times are converted into it before being matched.
+type Location struct {
+	*time.Location
 }
 
 /* TimeRange represents a range of minutes within a 1440 minute day, exclusive of the End minute. A day consists of 1440 minutes.
-   For example, 5:00PM to End of the day would Begin at 1020 and End at 1440. */
+   For example, 5:00PM to End of the day would Begin at 1020 and End at 1440.
+   A range whose End is before its Start wraps past midnight, continuing into the following day. For example, 10:00PM to 6:00AM
+   would Begin at 1320 and End at 360, and is active overnight after each day the rest of the interval matches.
+   Times are precise to the minute by default. StartSecond and EndSecond optionally add seconds to the Start and End
+   minutes, so 9:00:30AM would Begin at minute 540 and second 30. */
 type TimeRange struct {
 	StartMinute int
 	EndMinute   int
+	StartSecond int
+	EndSecond   int
+	// StartSolar and EndSolar, when set, replace the start or end of the range with a time relative to sunrise or
+	// sunset, which is resolved for each day at the interval's Coordinates.
+	StartSolar *SolarTime
+	EndSolar   *SolarTime
 }
 
 // InclusiveRange is used to hold the Beginning and End values of many time interval components
@@ -32,7 +76,8 @@ type InclusiveRange struct {
 	End   int
 }
 
-// A WeekdayRange is an inclusive range between [0, 6] where 0 = Sunday
+// A WeekdayRange is an inclusive range between [0, 6] where 0 = Sunday. Following ISO 8601 numbering, an End of 7 also
+// represents Sunday, so that ranges can finish at the end of a week that starts on Monday.
 type WeekdayRange struct {
 	InclusiveRange
 }
@@ -42,11 +87,17 @@ type DayOfMonthRange struct {
 	InclusiveRange
 }
 
-// A MonthRange is an inclusive range between [1, 12] where 1 = January
+// A MonthRange is an inclusive range between [1, 12] where 1 = January. A range whose End is before its Begin wraps
+// around the end of the year, e.g. November to February.
 type MonthRange struct {
 	InclusiveRange
 }
 
+// A WeekRange is an inclusive range between [1, 53] of ISO 8601 week numbers, as returned by time.Time.ISOWeek
+type WeekRange struct {
+	InclusiveRange
+}
+
 // A YearRange is a positive inclusive range
 type YearRange struct {
 	InclusiveRange
@@ -84,7 +135,10 @@ func (ir *InclusiveRange) memberFromString(in string) (out int, err error) {
 func (r *WeekdayRange) memberFromString(in string) (out int, err error) {
 	out, ok := daysOfWeek[in]
 	if !ok {
-		return -1, fmt.Errorf("%s is not a valid weekday", in)
+		out, err = strconv.Atoi(in)
+		if err != nil {
+			return -1, fmt.Errorf("%s is not a valid weekday", in)
+		}
 	}
 	return out, nil
 }
@@ -108,6 +162,18 @@ var daysOfWeek = map[string]int{
 	"thursday":  4,
 	"friday":    5,
 	"saturday":  6,
+	// Abbreviations commonly emitted by other systems
+	"sun":   0,
+	"mon":   1,
+	"tue":   2,
+	"tues":  2,
+	"wed":   3,
+	"weds":  3,
+	"thu":   4,
+	"thur":  4,
+	"thurs": 4,
+	"fri":   5,
+	"sat":   6,
 }
 var daysOfWeekInv = map[int]string{
 	0: "sunday",
@@ -132,6 +198,19 @@ var months = map[string]int{
 	"october":   10,
 	"november":  11,
 	"december":  12,
+	// Abbreviations commonly emitted by other systems
+	"jan":  1,
+	"feb":  2,
+	"mar":  3,
+	"apr":  4,
+	"jun":  6,
+	"jul":  7,
+	"aug":  8,
+	"sep":  9,
+	"sept": 9,
+	"oct":  10,
+	"nov":  11,
+	"dec":  12,
 }
 
 var monthsInv = map[int]string{
@@ -149,6 +228,106 @@ var monthsInv = map[int]string{
 	12: "december",
 }
 
+// UnmarshalYAML implements the Unmarshaller interface for TimeInterval. Before the fields are parsed, names in a
+// language given by the locale key are translated and keywords in the interval's lists are expanded: 'always' in any
+// list matches every value of that field, 'never' matches none, and the weekdays field also understands 'weekdays' and
+// 'weekend'. '*' may be used in place of 'always'. The weekend key changes which days those two keywords cover, and is Saturday and Sunday by default.
+// Stepped ranges such as '1:31/2' are then replaced by every value they step through. Exceptions share the locale and
+// weekend of the interval unless they set their own. Intervals with a version key older than DocumentVersion are
+// upgraded first, and an error is returned for a days key in an interval of the current version.
+func (tp *TimeInterval) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain TimeInterval
+	var fields yaml.MapSlice
+	if err := unmarshal(&fields); err != nil {
+		return err
+	}
+	versioned := hasKey(fields, "version")
+	version, fields, err := takeVersion(fields, DocumentVersion)
+	if err != nil {
+		return err
+	}
+	fields, _ = migrateInterval(fields, "", version)
+	if hasKey(fields, "days") {
+		// Without a version, a legacy days key would otherwise be dropped, leaving an interval that is always active.
+		return fmt.Errorf("The days key was removed in version %d, use weekdays and days_of_month or upgrade the "+
+			"document with Migrate", DocumentVersion)
+	}
+	inherited := inheritSettings(fields)
+	renamed, err := renameRepeat(fields)
+	if err != nil {
+		return err
+	}
+	locale, fields, err := takeLocale(fields)
+	if err != nil {
+		return err
+	}
+	weekend, fields, hasWeekend, err := takeWeekend(fields, locale)
+	if err != nil {
+		return err
+	}
+	strict := isStrict(unmarshal)
+	if strict {
+		if err := checkIntervalFields(fields); err != nil {
+			return err
+		}
+	}
+	rewritten := locale != nil || hasWeekend || inherited || renamed || versioned
+	for i, field := range fields {
+		key, _ := field.Key.(string)
+		list, ok := field.Value.([]interface{})
+		if !ok {
+			continue
+		}
+		if locale != nil {
+			list = locale.translate(key, list)
+			fields[i].Value = list
+		}
+		if !keywordFields[key] {
+			continue
+		}
+		values, ok := expandKeywords(key, list, weekend)
+		if ok {
+			fields[i].Value = values
+			rewritten = true
+		}
+		if list, ok = values.([]interface{}); !ok {
+			continue
+		}
+		if list, ok, err = expandSteps(key, list); err != nil {
+			return err
+		} else if ok {
+			fields[i].Value = list
+			rewritten = true
+		}
+	}
+	if split, ok := splitWeekdaysOfMonth(fields); ok {
+		fields = split
+		rewritten = true
+	}
+	if !rewritten {
+		err = unmarshal((*plain)(tp))
+	} else {
+		var out []byte
+		if out, err = yaml.Marshal(fields); err == nil {
+			err = decoder(strict)(out, (*plain)(tp))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if tp.hasSolarTimes() && tp.Coordinates == nil {
+		return errNoCoordinates
+	}
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for TimeInterval. Ranges are written in the compact forms they
+// are given in, with weekdays of the month among the days of the month and evenly spaced values as stepped ranges, so
+// that marshalling the result of unmarshalling the output gives the same output again.
+func (tp TimeInterval) MarshalYAML() (interface{}, error) {
+	return tp.compact()
+}
+
 // UnmarshalYAML implements the Unmarshaller interface for WeekdayRange.
 func (r *WeekdayRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var str string
@@ -156,13 +335,17 @@ func (r *WeekdayRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 	err := stringableRangeFromString(str, r)
+	// A lone ISO Sunday is the same day as Sunday 0, it only needs to stay 7 when it ends a range.
+	if r.Begin == 7 && r.End == 7 {
+		r.Begin, r.End = 0, 0
+	}
 	if r.Begin > r.End {
 		return errors.New("Start day cannot be before End day")
 	}
 	if r.Begin < 0 || r.Begin > 6 {
 		return fmt.Errorf("%s is not a valid day of the week: out of range", str)
 	}
-	if r.End < 0 || r.End > 6 {
+	if r.End < 0 || r.End > 7 {
 		return fmt.Errorf("%s is not a valid day of the week: out of range", str)
 	}
 	return err
@@ -170,6 +353,10 @@ func (r *WeekdayRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 // MarshalYAML implements the yaml.Marshaler interface for WeekdayRange
 func (r WeekdayRange) MarshalYAML() (interface{}, error) {
+	if r.End == 7 {
+		// Sunday can't end a range by name, so fall back to ISO numbering.
+		return interface{}(fmt.Sprintf("%d:%d", r.Begin, r.End)), nil
+	}
 	beginStr, ok := daysOfWeekInv[r.Begin]
 	if !ok {
 		return nil, fmt.Errorf("Unable to convert %d into weekday string", r.Begin)
@@ -219,9 +406,8 @@ func (r *MonthRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err := unmarshal(&str); err != nil {
 		return err
 	}
-	err := stringableRangeFromString(str, r)
-	if r.Begin > r.End {
-		return errors.New("Start month cannot be before End month")
+	if err := stringableRangeFromString(str, r); err != nil {
+		return err
 	}
 	if r.Begin < 1 || r.Begin > 12 {
 		return fmt.Errorf("%s is not a valid month: out of range", str)
@@ -229,7 +415,7 @@ func (r *MonthRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if r.End < 1 || r.End > 12 {
 		return fmt.Errorf("%s is not a valid month: out of range", str)
 	}
-	return err
+	return nil
 }
 
 // MarshalYAML implements the yaml.Marshaler interface for DayOfMonthRange
@@ -249,6 +435,44 @@ func (r MonthRange) MarshalYAML() (interface{}, error) {
 	return interface{}(rangeStr), nil
 }
 
+// containsWeekday returns true if the given day of the week falls within the range, accounting for ranges ending on
+// ISO Sunday.
+func (r WeekdayRange) containsWeekday(wd time.Weekday) bool {
+	if r.End == 7 && wd == time.Sunday {
+		return true
+	}
+	return wd >= time.Weekday(r.Begin) && wd <= time.Weekday(r.End)
+}
+
+// containsMonth returns true if the given month falls within the range, accounting for ranges that wrap the year.
+func (r MonthRange) containsMonth(m time.Month) bool {
+	if r.Begin > r.End {
+		return m >= time.Month(r.Begin) || m <= time.Month(r.End)
+	}
+	return m >= time.Month(r.Begin) && m <= time.Month(r.End)
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for WeekRange.
+func (r *WeekRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	if err := stringableRangeFromString(str, r); err != nil {
+		return err
+	}
+	if r.Begin < 1 || r.Begin > 53 {
+		return fmt.Errorf("%s is not a valid week: out of range", str)
+	}
+	if r.End < 1 || r.End > 53 {
+		return fmt.Errorf("%s is not a valid week: out of range", str)
+	}
+	if r.Begin > r.End {
+		return errors.New("Start week cannot be before End week")
+	}
+	return nil
+}
+
 // UnmarshalYAML implements the Unmarshaller interface for YearRange.
 func (r *YearRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var str string
@@ -271,6 +495,17 @@ func (tr *TimeRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if y.EndTime == "" || y.StartTime == "" {
 		return errors.New("Both start and End times must be provided")
 	}
+	startSolar, isStartSolar, err := parseSolarTime(y.StartTime)
+	if err != nil {
+		return err
+	}
+	endSolar, isEndSolar, err := parseSolarTime(y.EndTime)
+	if err != nil {
+		return err
+	}
+	if isStartSolar || isEndSolar {
+		return tr.unmarshalSolar(y, startSolar, isStartSolar, endSolar, isEndSolar)
+	}
 	start, err := parseTime(y.StartTime)
 	if err != nil {
 		return nil
@@ -279,30 +514,28 @@ func (tr *TimeRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err != nil {
 		return err
 	}
-	if start < 0 {
+	if start < 0 || start >= secondsPerDay {
 		return errors.New("Start time out of range")
 	}
-	if End > 1440 {
+	if End > secondsPerDay {
 		return errors.New("End time out of range")
 	}
-	if start >= End {
-		return errors.New("Start time cannot be equal or greater than End time")
+	if start == End {
+		return errors.New("Start time cannot be equal to End time")
 	}
-	tr.StartMinute, tr.EndMinute = start, End
+	*tr = timeRangeFromSeconds(start, End)
 	return nil
 }
 
 //MarshalYAML implements the yaml.Marshaler interface for TimeRange
 func (tr TimeRange) MarshalYAML() (out interface{}, err error) {
-	startHr := tr.StartMinute / 60
-	endHr := tr.EndMinute / 60
-	startMin := tr.StartMinute % 60
-	endMin := tr.EndMinute % 60
-
-	startStr := fmt.Sprintf("%02d:%02d", startHr, startMin)
-	endStr := fmt.Sprintf("%02d:%02d", endHr, endMin)
-
-	yTr := yamlTimeRange{startStr, endStr}
+	yTr := yamlTimeRange{formatTime(tr.startSecond()), formatTime(tr.endSecond())}
+	if tr.StartSolar != nil {
+		yTr.StartTime = tr.StartSolar.String()
+	}
+	if tr.EndSolar != nil {
+		yTr.EndTime = tr.EndSolar.String()
+	}
 	return interface{}(yTr), err
 }
 
@@ -315,10 +548,32 @@ func (ir InclusiveRange) MarshalYAML() (interface{}, error) {
 	return interface{}(out), nil
 }
 
+// UnmarshalYAML implements the Unmarshaller interface for Location.
+func (l *Location) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(str)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid location: %v", str, err)
+	}
+	l.Location = loc
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for Location
+func (l Location) MarshalYAML() (interface{}, error) {
+	return interface{}(l.String()), nil
+}
+
 // TimeLayout specifies the layout to be used in time.Parse() calls for time intervals
 const TimeLayout = "15:04"
 
-var validTime string = "^((([01][0-9])|(2[0-3])):[0-5][0-9])$|(^24:00$)"
+// secondsPerDay is the number of seconds in a day without clock changes.
+const secondsPerDay = 86400
+
+var validTime string = "^((([01][0-9])|(2[0-3])):[0-5][0-9](:[0-5][0-9])?)$|(^24:00(:00)?$)"
 var validTimeRE *regexp.Regexp = regexp.MustCompile(validTime)
 
 // Given a time, determines the number of days in the month that time occurs in.
@@ -341,20 +596,131 @@ func clamp(n, min, max int) int {
 
 // ContainsTime returns true if the TimeInterval contains the given time, otherwise returns false
 func (tp TimeInterval) ContainsTime(t time.Time) bool {
-	if tp.Times != nil {
-		in := false
-		for _, validMinutes := range tp.Times {
-			if (t.Hour()*60+t.Minute()) >= validMinutes.StartMinute && (t.Hour()*60+t.Minute()) < validMinutes.EndMinute {
-				in = true
-				break
-			}
+	if tp.Absolute != nil && !absoluteRanges(tp.Absolute).ContainsTime(t) {
+		return false
+	}
+	t = tp.inLocation(t)
+	yesterday := t.AddDate(0, 0, -1)
+	// Solar times are resolved into buffers on the stack, so that matching doesn't allocate.
+	var todayTimes, yesterdayTimes [solarTimesBuffer]TimeRange
+	// Overnight ranges carry over from the previous day, so only that day needs to match.
+	if !tp.onDayInto(t, todayTimes[:]).containsSameDayTime(t) &&
+		!(tp.onDayInto(yesterday, yesterdayTimes[:]).containsCarriedSecond(secondOfDay(t)) &&
+			tp.containsDay(yesterday)) {
+		return false
+	}
+	if tp.Expression != nil && !tp.Expression.Matches(t) {
+		return false
+	}
+	for _, ex := range tp.Except {
+		if ex.Coordinates == nil {
+			ex.Coordinates = tp.Coordinates
 		}
-		if !in {
+		if ex.ContainsTime(t) {
 			return false
 		}
 	}
-	if tp.DaysOfMonth != nil {
+	return true
+}
+
+// containsSameDayTime returns true if t, already in the interval's location, is matched by the interval without
+// considering overnight ranges carried over from the previous day.
+func (tp TimeInterval) containsSameDayTime(t time.Time) bool {
+	if !tp.containsDay(t) {
+		return false
+	}
+	second := secondOfDay(t)
+	if tp.containsSecondAt(t, second) {
+		return true
+	}
+	if tp.DST.Gap == GapShift && tp.Times != nil {
+		if shifted, ok := shiftedSecond(t, second); ok {
+			return tp.containsSecond(shifted)
+		}
+	}
+	return false
+}
+
+// inLocation converts t into the interval's location, if it has one.
+func (tp TimeInterval) inLocation(t time.Time) time.Time {
+	if tp.Location != nil && tp.Location.Location != nil {
+		return t.In(tp.Location.Location)
+	}
+	return t
+}
+
+// containsSecond returns true if the given second of the day falls within one of the interval's time ranges that
+// started on the same day.
+func (tp TimeInterval) containsSecond(second int) bool {
+	if tp.Times == nil {
+		return true
+	}
+	for _, tr := range tp.Times {
+		if tr.isOvernight() {
+			if second >= tr.startSecond() {
+				return true
+			}
+		} else if second >= tr.startSecond() && second < tr.endSecond() {
+			return true
+		}
+	}
+	return false
+}
+
+// containsCarriedSecond returns true if the given second of the day falls within one of the interval's overnight time
+// ranges that started on the previous day.
+func (tp TimeInterval) containsCarriedSecond(second int) bool {
+	for _, tr := range tp.Times {
+		if tr.isOvernight() && second < tr.endSecond() {
+			return true
+		}
+	}
+	return false
+}
+
+// isOvernight returns true if the range wraps past midnight into the following day.
+func (tr TimeRange) isOvernight() bool {
+	return tr.startSecond() > tr.endSecond()
+}
+
+// startSecond returns the second of the day at which the range starts.
+func (tr TimeRange) startSecond() int {
+	return tr.StartMinute*60 + tr.StartSecond
+}
+
+// endSecond returns the second of the day at which the range ends.
+func (tr TimeRange) endSecond() int {
+	return tr.EndMinute*60 + tr.EndSecond
+}
+
+// hasSeconds returns true if either end of the range falls part way through a minute.
+func (tr TimeRange) hasSeconds() bool {
+	return tr.StartSecond != 0 || tr.EndSecond != 0
+}
+
+// timeRangeFromSeconds returns the TimeRange between two seconds of the day.
+func timeRangeFromSeconds(start, end int) TimeRange {
+	return TimeRange{StartMinute: start / 60, StartSecond: start % 60, EndMinute: end / 60, EndSecond: end % 60}
+}
+
+// secondOfDay returns the number of seconds elapsed since midnight on the wall clock at t.
+func secondOfDay(t time.Time) int {
+	return t.Hour()*3600 + t.Minute()*60 + t.Second()
+}
+
+// containsDay returns true if the calendar day of the given time satisfies every day-level field of the interval.
+func (tp TimeInterval) containsDay(t time.Time) bool {
+	if tp.Cycle != nil && !tp.Cycle.containsDay(t) {
+		return false
+	}
+	if tp.DaysOfMonth != nil || tp.WeekdaysOfMonth != nil {
 		in := false
+		for _, validWeekdays := range tp.WeekdaysOfMonth {
+			if validWeekdays.containsDay(t) {
+				in = true
+				break
+			}
+		}
 		for _, validDates := range tp.DaysOfMonth {
 			var Begin, End int
 			daysInMonth := daysInMonth(t)
@@ -383,7 +749,7 @@ func (tp TimeInterval) ContainsTime(t time.Time) bool {
 	if tp.Months != nil {
 		in := false
 		for _, validMonths := range tp.Months {
-			if t.Month() >= time.Month(validMonths.Begin) && t.Month() <= time.Month(validMonths.End) {
+			if validMonths.containsMonth(t.Month()) {
 				in = true
 				break
 			}
@@ -395,7 +761,33 @@ func (tp TimeInterval) ContainsTime(t time.Time) bool {
 	if tp.Weekdays != nil {
 		in := false
 		for _, validDays := range tp.Weekdays {
-			if t.Weekday() >= time.Weekday(validDays.Begin) && t.Weekday() <= time.Weekday(validDays.End) {
+			if validDays.containsWeekday(t.Weekday()) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+	if tp.Weeks != nil {
+		in := false
+		_, week := t.ISOWeek()
+		for _, validWeeks := range tp.Weeks {
+			if week >= validWeeks.Begin && week <= validWeeks.End {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+	if tp.Quarters != nil {
+		in := false
+		quarter := tp.quarter(t)
+		for _, validQuarters := range tp.Quarters {
+			if quarter >= validQuarters.Begin && quarter <= validQuarters.End {
 				in = true
 				break
 			}
@@ -406,8 +798,9 @@ func (tp TimeInterval) ContainsTime(t time.Time) bool {
 	}
 	if tp.Years != nil {
 		in := false
+		year := tp.year(t)
 		for _, validYears := range tp.Years {
-			if t.Year() >= validYears.Begin && t.Year() <= validYears.End {
+			if year >= validYears.Begin && year <= validYears.End {
 				in = true
 				break
 			}
@@ -416,16 +809,46 @@ func (tp TimeInterval) ContainsTime(t time.Time) bool {
 			return false
 		}
 	}
+	if tp.Relative != nil {
+		in := false
+		for _, validDays := range tp.Relative {
+			if validDays.containsDay(t) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+	if tp.Dates != nil {
+		in := false
+		for _, validDates := range tp.Dates {
+			if validDates.containsDay(t) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			return false
+		}
+	}
+	if tp.Calendar != nil && !tp.Calendar.containsDay(t) {
+		return false
+	}
+	if tp.Holidays != nil && !tp.Holidays.containsDay(t) {
+		return false
+	}
 	return true
 }
 
-// Converts a string of the form "HH:MM" into a TimeRange
-func parseTime(in string) (mins int, err error) {
+// Converts a string of the form "HH:MM" or "HH:MM:SS" into the number of seconds elapsed in the day
+func parseTime(in string) (secs int, err error) {
 	if !validTimeRE.MatchString(in) {
 		return 0, fmt.Errorf("Couldn't parse timestamp %s, invalid format", in)
 	}
 	timestampComponents := strings.Split(in, ":")
-	if len(timestampComponents) != 2 {
+	if len(timestampComponents) != 2 && len(timestampComponents) != 3 {
 		return 0, fmt.Errorf("Invalid timestamp format: %s", in)
 	}
 	timeStampHours, err := strconv.Atoi(timestampComponents[0])
@@ -436,12 +859,28 @@ func parseTime(in string) (mins int, err error) {
 	if err != nil {
 		return 0, err
 	}
-	if timeStampHours < 0 || timeStampHours > 24 || timeStampMinutes < 0 || timeStampMinutes > 60 {
+	timeStampSeconds := 0
+	if len(timestampComponents) == 3 {
+		timeStampSeconds, err = strconv.Atoi(timestampComponents[2])
+		if err != nil {
+			return 0, err
+		}
+	}
+	if timeStampHours < 0 || timeStampHours > 24 || timeStampMinutes < 0 || timeStampMinutes > 60 ||
+		timeStampSeconds < 0 || timeStampSeconds > 60 {
 		return 0, fmt.Errorf("Timestamp %s out of range", in)
 	}
-	// Timestamps are stored as minutes elapsed in the day, so multiply hours by 60
-	mins = timeStampHours*60 + timeStampMinutes
-	return mins, nil
+	secs = timeStampHours*3600 + timeStampMinutes*60 + timeStampSeconds
+	return secs, nil
+}
+
+// formatTime converts a number of seconds elapsed in the day into a string of the form "HH:MM", or "HH:MM:SS" if it
+// doesn't fall on a whole minute.
+func formatTime(secs int) string {
+	if secs%60 != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%02d:%02d", secs/3600, secs/60%60)
 }
 
 // Converts a range that can be represented as strings (e.g. monday:wednesday) into an equivalent integer-represented range
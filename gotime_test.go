@@ -59,6 +59,42 @@ var timeIntervalTestCases = []struct {
 			"07 Apr 20 00:00 MST",
 		},
 	},
+	{
+		// Overnight from friday and saturday nights into the following morning
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}},
+		},
+		validTimeStrings: []string{
+			"08 May 20 22:00 MST",
+			"09 May 20 05:59 MST",
+			"09 May 20 23:30 MST",
+			"10 May 20 03:00 MST",
+		},
+		invalidTimeStrings: []string{
+			"08 May 20 06:00 MST",
+			"08 May 20 03:00 MST",
+			"10 May 20 22:00 MST",
+			"09 May 20 21:59 MST",
+		},
+	},
+	{
+		// Winter, wrapping around the end of the year
+		timeInterval: TimeInterval{
+			Months: []MonthRange{{InclusiveRange{Begin: 11, End: 2}}},
+		},
+		validTimeStrings: []string{
+			"01 Nov 20 00:00 MST",
+			"31 Dec 20 23:59 MST",
+			"01 Jan 21 00:00 MST",
+			"28 Feb 21 12:00 MST",
+		},
+		invalidTimeStrings: []string{
+			"31 Oct 20 23:59 MST",
+			"01 Mar 21 00:00 MST",
+			"15 Jul 21 12:00 MST",
+		},
+	},
 	{
 		// Check negative days of month, last 3 days of each month
 		timeInterval: TimeInterval{
@@ -131,10 +167,10 @@ var timeStringTestCases = []struct {
 		expectError: true,
 	},
 	{
-		// Error: Start time greater than End time
-		timeString:  "{'start_time': '09:30', 'end_time': '07:41'}",
-		TimeRange:   TimeRange{},
-		expectError: true,
+		// Start time greater than End time wraps past midnight
+		timeString:  "{'start_time': '22:00', 'end_time': '06:00'}",
+		TimeRange:   TimeRange{StartMinute: 1320, EndMinute: 360},
+		expectError: false,
 	},
 	{
 		// Error: Start time out of range and greater than End time
@@ -142,6 +178,29 @@ var timeStringTestCases = []struct {
 		TimeRange:   TimeRange{},
 		expectError: true,
 	},
+	{
+		// Times may include seconds
+		timeString:  "{'start_time': '09:00:30', 'end_time': '17:00:00'}",
+		TimeRange:   TimeRange{StartMinute: 540, StartSecond: 30, EndMinute: 1020},
+		expectError: false,
+	},
+	{
+		timeString:  "{'start_time': '00:00:00', 'end_time': '24:00:00'}",
+		TimeRange:   TimeRange{StartMinute: 0, EndMinute: 1440},
+		expectError: false,
+	},
+	{
+		// Error: Seconds out of range
+		timeString:  "{'start_time': '09:00', 'end_time': '17:00:60'}",
+		TimeRange:   TimeRange{},
+		expectError: true,
+	},
+	{
+		// Error: End time out of range by a second
+		timeString:  "{'start_time': '12:30', 'end_time': '24:00:01'}",
+		TimeRange:   TimeRange{},
+		expectError: true,
+	},
 	{
 		// Error: No range specified
 		timeString:  "{'start_time': '14:03'}",
@@ -160,6 +219,61 @@ var dayOfWeekStringTestCases = []struct {
 		ranges:      []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}, {InclusiveRange{Begin: 6, End: 6}}},
 		expectError: false,
 	},
+	{
+		dowString:   "['mon:fri', 'Sat', 'sun']",
+		ranges:      []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}, {InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange{Begin: 0, End: 0}}},
+		expectError: false,
+	},
+	{
+		dowString:   "['tues:thurs', 'weds', 'thu']",
+		ranges:      []WeekdayRange{{InclusiveRange{Begin: 2, End: 4}}, {InclusiveRange{Begin: 3, End: 3}}, {InclusiveRange{Begin: 4, End: 4}}},
+		expectError: false,
+	},
+	{
+		dowString:   "['1:5', '0', '7', 'saturday:7']",
+		ranges:      []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}, {InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 7}}},
+		expectError: false,
+	},
+	{
+		dowString:   "['8']",
+		ranges:      []WeekdayRange{{}},
+		expectError: true,
+	},
+	{
+		dowString:   "['7:1']",
+		ranges:      []WeekdayRange{{}},
+		expectError: true,
+	},
+	{
+		dowString:   "['mo']",
+		ranges:      []WeekdayRange{{}},
+		expectError: true,
+	},
+}
+
+var monthStringTestCases = []struct {
+	monthString string
+	ranges      []MonthRange
+	expectError bool
+}{
+	{
+		monthString: "['jan:mar', 'december', '7']",
+		ranges:      []MonthRange{{InclusiveRange{Begin: 1, End: 3}}, {InclusiveRange{Begin: 12, End: 12}}, {InclusiveRange{Begin: 7, End: 7}}},
+		expectError: false,
+	},
+	{
+		monthString: "['Sept:Nov', 'may']",
+		ranges:      []MonthRange{{InclusiveRange{Begin: 9, End: 11}}, {InclusiveRange{Begin: 5, End: 5}}},
+		expectError: false,
+	},
+	{
+		monthString: "['janu']",
+		expectError: true,
+	},
+	{
+		monthString: "['jan:13']",
+		expectError: true,
+	},
 }
 
 var yamlUnmarshalTestCases = []struct {
@@ -260,6 +374,114 @@ var yamlUnmarshalTestCases = []struct {
 		in: `
 ---
 - days_of_month: ['-50:-20']
+`,
+		expectError: true,
+	},
+	{
+		// Numeric weekdays, with ISO numbering ending the week on Sunday 7
+		in: `
+---
+- weekdays: ['1:3', '6:7']
+`,
+		intervals: []TimeInterval{
+			{
+				Weekdays: []WeekdayRange{{InclusiveRange{1, 3}}, {InclusiveRange{6, 7}}},
+			},
+		},
+		contains: []string{
+			"06 Jul 20 12:00 MST",
+			"11 Jul 20 12:00 MST",
+			"12 Jul 20 12:00 MST",
+		},
+		excludes: []string{
+			"09 Jul 20 12:00 MST",
+		},
+		expectError: false,
+	},
+	{
+		// ISO weeks at either end of the year
+		in: `
+---
+- weeks: ['1:2', '52:53']
+`,
+		intervals: []TimeInterval{
+			{
+				Weeks: []WeekRange{{InclusiveRange{1, 2}}, {InclusiveRange{52, 53}}},
+			},
+		},
+		contains: []string{
+			// 3 Jan 2021 is a Sunday at the end of ISO week 53 of 2020
+			"03 Jan 21 12:00 MST",
+			"04 Jan 21 12:00 MST",
+			"17 Jan 21 12:00 MST",
+			"28 Dec 20 12:00 MST",
+		},
+		excludes: []string{
+			"18 Jan 21 12:00 MST",
+			"20 Dec 20 12:00 MST",
+		},
+		expectError: false,
+	},
+	{
+		// Invalid week
+		in: `
+---
+- weeks: ['50:54']
+`,
+		expectError: true,
+	},
+	{
+		// Month ranges may wrap the year
+		in: `
+---
+- months: ['november:february']
+`,
+		intervals: []TimeInterval{
+			{
+				Months: []MonthRange{{InclusiveRange{11, 2}}},
+			},
+		},
+		contains: []string{
+			"15 Dec 20 12:00 MST",
+			"15 Jan 21 12:00 MST",
+		},
+		excludes: []string{
+			"15 Mar 21 12:00 MST",
+		},
+		expectError: false,
+	},
+	{
+		// Business hours in Melbourne, given times in UTC
+		in: `
+---
+- weekdays: ['monday:friday']
+  location: 'Australia/Melbourne'
+  times:
+    - start_time: '09:00'
+      end_time: '17:00'
+`,
+		intervals: []TimeInterval{
+			{
+				Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+				Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+				Location: mustLoadLocation("Australia/Melbourne"),
+			},
+		},
+		contains: []string{
+			"07 Jul 20 23:00 UTC", // Wednesday 9am in Melbourne
+			"08 Jul 20 06:59 UTC",
+		},
+		excludes: []string{
+			"08 Jul 20 09:00 UTC",
+			"10 Jul 20 23:00 UTC", // Saturday morning in Melbourne
+		},
+		expectError: false,
+	},
+	{
+		// Unknown location
+		in: `
+---
+- location: 'Middle/Earth'
 `,
 		expectError: true,
 	},
@@ -292,6 +514,26 @@ var yamlUnmarshalTestCases = []struct {
 `,
 		expectError: true,
 	},
+	{
+		// Metadata is kept but doesn't affect matching
+		in: `
+---
+- name: 'maintenance'
+  description: 'Weekly maintenance window'
+  labels: {team: 'platform', severity: 'low'}
+  weekdays: ['sunday']
+`,
+		intervals: []TimeInterval{
+			{
+				Name:        "maintenance",
+				Description: "Weekly maintenance window",
+				Labels:      map[string]string{"team": "platform", "severity": "low"},
+				Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}},
+			},
+		},
+		contains: []string{"12 Jul 20 10:00 MST"},
+		excludes: []string{"13 Jul 20 10:00 MST"},
+	},
 }
 
 func TestYamlUnmarshal(t *testing.T) {
@@ -366,6 +608,28 @@ func TestParseTimeString(t *testing.T) {
 	}
 }
 
+func TestSecondPrecision(t *testing.T) {
+	ti := TimeInterval{Times: []TimeRange{{StartMinute: 540, StartSecond: 30, EndMinute: 600, EndSecond: 15}}}
+	for ts, want := range map[string]bool{
+		"2020-07-08T09:00:29Z": false,
+		"2020-07-08T09:00:30Z": true,
+		"2020-07-08T10:00:14Z": true,
+		"2020-07-08T10:00:15Z": false,
+	} {
+		if got := ti.ContainsTime(mustParseTime(ts)); got != want {
+			t.Errorf("ContainsTime(%s): want %t, got %t", ts, want, got)
+		}
+	}
+	out, err := yaml.Marshal(ti.Times[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tr TimeRange
+	if err := yaml.Unmarshal(out, &tr); err != nil || tr != ti.Times[0] {
+		t.Errorf("Re-marshalling %+v produced %+v: %v", ti.Times[0], tr, err)
+	}
+}
+
 func TestParseWeek(t *testing.T) {
 	for _, tc := range dayOfWeekStringTestCases {
 		var wr []WeekdayRange
@@ -380,6 +644,20 @@ func TestParseWeek(t *testing.T) {
 	}
 }
 
+func TestParseMonth(t *testing.T) {
+	for _, tc := range monthStringTestCases {
+		var mr []MonthRange
+		err := yaml.Unmarshal([]byte(tc.monthString), &mr)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %v", err, tc.monthString)
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error for invalid string %s but didn't receive one", tc.monthString)
+		} else if err == nil && !reflect.DeepEqual(mr, tc.ranges) {
+			t.Errorf("Error parsing month string %s: Want %+v, got %+v", tc.monthString, tc.ranges, mr)
+		}
+	}
+}
+
 func TestYamlMarshal(t *testing.T) {
 	for _, tc := range yamlUnmarshalTestCases {
 		if tc.expectError {
@@ -402,6 +680,14 @@ func TestYamlMarshal(t *testing.T) {
 	}
 }
 
+func mustLoadLocation(name string) *Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return &Location{loc}
+}
+
 func emptyInterval() TimeInterval {
 	return TimeInterval{
 		Times:       []TimeRange{},
@@ -411,3 +697,80 @@ func emptyInterval() TimeInterval {
 		Years:       []YearRange{},
 	}
 }
+
+// allocationTestCases cover each of the paths ContainsTime can take, which must not allocate.
+var allocationTestCases = []struct {
+	name, doc string
+}{
+	{"business_hours", "weekdays: ['monday:friday']\ntimes:\n  - start_time: '09:00'\n    end_time: '17:00'\n" +
+		"location: Australia/Melbourne\n"},
+	{"overnight", "times:\n  - start_time: '22:00'\n    end_time: '06:00:30'\n"},
+	{"days", "days_of_month: ['-3:-1', 'monday#2']\nmonths: ['nov:feb']\nweeks: ['1:10']\nyears: ['2024:2025']\n" +
+		"quarters: ['q1']\n"},
+	{"except", "times:\n  - start_time: '09:00'\n    end_time: '17:00'\nexcept:\n  - times:\n" +
+		"      - start_time: '12:00'\n        end_time: '13:00'\n"},
+	{"dates", "dates: ['2024-12-24:2025-01-02']\n"},
+	{"absolute", "absolute:\n  - start: '2024-06-01T22:00:00Z'\n    end: '2024-06-02T02:00:00Z'\n"},
+	{"relative", "relative: ['easter-2:easter+1']\n"},
+	{"calendar", "calendar:\n  system: hebrew\n  dates: ['9-25:10-2']\n"},
+	{"holidays", "holidays: 'us-federal'\n"},
+	{"cycle", "cycle: {anchor: '2024-01-01', every: '2w'}\n"},
+	{"expression", "expression: 'yearday % 2 == 0 && hour >= 9 && (hour < 16 || minute < 30) && hour < 17'\n"},
+	{"solar", "coordinates: {latitude: -37.8, longitude: 144.9}\ntimes:\n  - start_time: sunset\n" +
+		"    end_time: sunrise\n"},
+	{"dst", "times:\n  - start_time: '02:30'\n    end_time: '03:00'\ndst: {gap: shift, overlap: first}\n" +
+		"location: Australia/Melbourne\n"},
+}
+
+// allocationTestTimes include days on which clocks change in Melbourne.
+var allocationTestTimes = []time.Time{
+	time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC),
+	time.Date(2024, 4, 6, 15, 30, 0, 0, time.UTC),
+	time.Date(2024, 10, 5, 16, 30, 0, 0, time.UTC),
+}
+
+func TestContainsTimeAllocations(t *testing.T) {
+	for _, c := range allocationTestCases {
+		var ti TimeInterval
+		if err := yaml.UnmarshalStrict([]byte(c.doc), &ti); err != nil {
+			t.Fatalf("Unexpected error parsing %s: %v", c.name, err)
+		}
+		compiled, err := ti.Compile()
+		if err != nil {
+			t.Fatalf("Unexpected error compiling %s: %v", c.name, err)
+		}
+		matchers := map[string]Matcher{"interval": ti, "set": IntervalSet{ti}, "compiled": compiled}
+		for kind, m := range matchers {
+			for _, at := range allocationTestTimes {
+				if allocs := testing.AllocsPerRun(10, func() { m.ContainsTime(at) }); allocs != 0 {
+					t.Errorf("Expected ContainsTime of %s %s at %s not to allocate, got %v allocations", c.name, kind,
+						at, allocs)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkContainsTime(b *testing.B) {
+	for _, c := range allocationTestCases {
+		var ti TimeInterval
+		if err := yaml.UnmarshalStrict([]byte(c.doc), &ti); err != nil {
+			b.Fatal(err)
+		}
+		compiled, err := ti.Compile()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, kind := range []struct {
+			name string
+			m    Matcher
+		}{{"interval", ti}, {"compiled", compiled}} {
+			b.Run(c.name+"/"+kind.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					kind.m.ContainsTime(allocationTestTimes[i%len(allocationTestTimes)])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package gotime
+
+import (
+	"fmt"
+	"sort"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// GrafanaProvisioning is a Grafana Alerting provisioning file holding mute timings, in the format Grafana reads from
+// its provisioning directory and returns from its export API. It can be marshalled as either JSON or YAML.
+type GrafanaProvisioning struct {
+	APIVersion int                 `json:"apiVersion" yaml:"apiVersion"`
+	MuteTimes  []GrafanaMuteTiming `json:"muteTimes" yaml:"muteTimes"`
+}
+
+// A GrafanaMuteTiming is a named list of intervals during which Grafana Alerting mutes notifications, as sent to
+// Grafana's mute timings provisioning API. OrgID is only used in provisioning files.
+type GrafanaMuteTiming struct {
+	OrgID         int64                 `json:"orgId,omitempty" yaml:"orgId,omitempty"`
+	Name          string                `json:"name" yaml:"name"`
+	TimeIntervals []GrafanaTimeInterval `json:"time_intervals" yaml:"time_intervals"`
+}
+
+// A GrafanaTimeInterval is one of the intervals of a mute timing, written in the same way as in Alertmanager.
+type GrafanaTimeInterval struct {
+	Times       []GrafanaTimeRange `json:"times,omitempty" yaml:"times,omitempty"`
+	Weekdays    []string           `json:"weekdays,omitempty" yaml:"weekdays,omitempty"`
+	DaysOfMonth []string           `json:"days_of_month,omitempty" yaml:"days_of_month,omitempty"`
+	Months      []string           `json:"months,omitempty" yaml:"months,omitempty"`
+	Years       []string           `json:"years,omitempty" yaml:"years,omitempty"`
+	Location    string             `json:"location,omitempty" yaml:"location,omitempty"`
+}
+
+// A GrafanaTimeRange is a range of times of day in a mute timing, such as 09:00 to 17:00.
+type GrafanaTimeRange struct {
+	StartTime string `json:"start_time" yaml:"start_time"`
+	EndTime   string `json:"end_time" yaml:"end_time"`
+}
+
+// NewGrafanaProvisioning returns a provisioning file with a mute timing in the given organisation for each of the
+// definitions, in order of name.
+func NewGrafanaProvisioning(orgID int64, d Definitions) (GrafanaProvisioning, error) {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	p := GrafanaProvisioning{APIVersion: 1, MuteTimes: []GrafanaMuteTiming{}}
+	for _, name := range names {
+		mt, err := NewGrafanaMuteTiming(name, d[name])
+		if err != nil {
+			return GrafanaProvisioning{}, fmt.Errorf("Definition %s: %v", name, err)
+		}
+		mt.OrgID = orgID
+		p.MuteTimes = append(p.MuteTimes, mt)
+	}
+	return p, nil
+}
+
+// NewGrafanaMuteTiming converts a set of intervals into a Grafana mute timing with the given name, which mutes
+// notifications whenever the set contains the time. Grafana's mute timings have the same fields as Alertmanager's
+// time intervals, so an error is returned for intervals using fields that Alertmanager doesn't have.
+func NewGrafanaMuteTiming(name string, set IntervalSet) (GrafanaMuteTiming, error) {
+	mt := GrafanaMuteTiming{Name: name, TimeIntervals: make([]GrafanaTimeInterval, len(set))}
+	for i, ti := range set {
+		if err := ti.alertmanagerCompatible(); err != nil {
+			return GrafanaMuteTiming{}, err
+		}
+		for _, f := range []struct {
+			key   string
+			empty bool
+		}{
+			{"times", ti.Times != nil && len(ti.Times) == 0},
+			{"weekdays", ti.Weekdays != nil && len(ti.Weekdays) == 0},
+			{"days of the month", ti.DaysOfMonth != nil && len(ti.DaysOfMonth) == 0},
+			{"months", ti.Months != nil && len(ti.Months) == 0},
+			{"years", ti.Years != nil && len(ti.Years) == 0},
+		} {
+			// Grafana treats an empty list as though it were left out, which would match everything.
+			if f.empty {
+				return GrafanaMuteTiming{}, fmt.Errorf("Unable to express an empty list of %s in Grafana", f.key)
+			}
+		}
+		gi := GrafanaTimeInterval{}
+		for _, tr := range ti.Times {
+			gi.Times = append(gi.Times, GrafanaTimeRange{
+				StartTime: formatTime(tr.startSecond()),
+				EndTime:   formatTime(tr.endSecond()),
+			})
+		}
+		var err error
+		add := func(values *[]string, n int, item func(i int) yaml.Marshaler) {
+			for j := 0; j < n && err == nil; j++ {
+				var text []byte
+				if text, err = textOf(item(j)); err == nil {
+					*values = append(*values, string(text))
+				}
+			}
+		}
+		weekdays := alertmanagerWeekdays(ti.Weekdays)
+		add(&gi.Weekdays, len(weekdays), func(j int) yaml.Marshaler { return weekdays[j] })
+		add(&gi.DaysOfMonth, len(ti.DaysOfMonth), func(j int) yaml.Marshaler { return ti.DaysOfMonth[j] })
+		add(&gi.Months, len(ti.Months), func(j int) yaml.Marshaler { return ti.Months[j] })
+		add(&gi.Years, len(ti.Years), func(j int) yaml.Marshaler { return ti.Years[j] })
+		if err != nil {
+			return GrafanaMuteTiming{}, err
+		}
+		if ti.Location != nil && ti.Location.Location != nil {
+			gi.Location = ti.Location.String()
+		}
+		mt.TimeIntervals[i] = gi
+	}
+	return mt, nil
+}
@@ -0,0 +1,54 @@
+package gotime
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewGrafanaProvisioning(t *testing.T) {
+	d := Definitions{
+		"weekends": {{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 7}}}}},
+		"out_of_hours": {{
+			Name:        "out_of_hours",
+			Times:       []TimeRange{{StartMinute: 0, EndMinute: 540}, {StartMinute: 1020, EndMinute: 1440}},
+			Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 5}}, {InclusiveRange{Begin: -3, End: -1}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 1, End: 3}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2024, End: 2024}}},
+			Location:    mustLoadLocation("Europe/Berlin"),
+		}},
+	}
+	p, err := NewGrafanaProvisioning(1, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"apiVersion":1,"muteTimes":[` +
+		`{"orgId":1,"name":"out_of_hours","time_intervals":[{` +
+		`"times":[{"start_time":"00:00","end_time":"09:00"},{"start_time":"17:00","end_time":"24:00"}],` +
+		`"weekdays":["monday:friday"],"days_of_month":["1:5","-3:-1"],"months":["january:march"],` +
+		`"years":["2024"],"location":"Europe/Berlin"}]},` +
+		`{"orgId":1,"name":"weekends","time_intervals":[{"weekdays":["saturday","sunday"]}]}]}`
+	if string(out) != want {
+		t.Errorf("Marshalling gave\n%s\nexpected\n%s", out, want)
+	}
+}
+
+func TestNewGrafanaMuteTimingErrors(t *testing.T) {
+	for _, set := range []IntervalSet{
+		{{Except: []TimeInterval{{}}}},
+		{{Times: []TimeRange{{StartMinute: 1320, EndMinute: 360}}}},
+		{{Relative: []RelativeRange{{}}}},
+		{{Weekdays: []WeekdayRange{}}},
+	} {
+		if mt, err := NewGrafanaMuteTiming("a", set); err == nil {
+			t.Errorf("Expected an error converting %v, got %v", set, mt)
+		}
+	}
+	if _, err := NewGrafanaProvisioning(1, Definitions{"a": {{Mode: ModeDeny}}}); err == nil {
+		t.Errorf("Expected an error converting an interval that denies times")
+	}
+}
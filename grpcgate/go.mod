@@ -0,0 +1,22 @@
+module github.com/benridley/gotime/grpcgate
+
+go 1.25.0
+
+require (
+	github.com/benridley/gotime v0.1.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	gopkg.in/yaml.v2 v2.3.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// Modules using this one build against the required release of gotime. The replacement only applies when working on
+// this module itself, so changes to both can be tested together.
+replace github.com/benridley/gotime => ../
@@ -0,0 +1,55 @@
+// Package grpcgate limits gRPC servers to a schedule with interceptors that turn calls away while a gotime.Gate is
+// closed. It is a module of its own so that gotime doesn't depend on gRPC.
+package grpcgate
+
+import (
+	"context"
+	"errors"
+
+	"github.com/benridley/gotime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// UnaryServerInterceptor returns an interceptor that fails unary calls with codes.Unavailable while the gate is
+// closed. The status carries a RetryInfo detail giving how long until the gate next opens, if it does.
+func UnaryServerInterceptor(gate *gotime.Gate) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if err := check(gate); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that fails streams with codes.Unavailable while the gate is closed,
+// as UnaryServerInterceptor does for unary calls. Streams are only checked as they start, so a stream may outlast the
+// schedule that admitted it.
+func StreamServerInterceptor(gate *gotime.Gate) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := check(gate); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// check returns nil if the gate is open, or an Unavailable status error saying when to retry if it's closed.
+func check(gate *gotime.Gate) error {
+	err := gate.Check()
+	var inactive *gotime.InactiveError
+	if !errors.As(err, &inactive) {
+		return err
+	}
+	st := status.New(codes.Unavailable, err.Error())
+	if retry, ok := inactive.RetryAfter(); ok {
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retry)}); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
@@ -0,0 +1,87 @@
+package grpcgate
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/benridley/gotime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial starts a server with the health service behind interceptors for gate, returning a client for it.
+func dial(t *testing.T, gate *gotime.Gate) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(gate)),
+		grpc.StreamInterceptor(StreamServerInterceptor(gate)),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestInterceptors(t *testing.T) {
+	now := time.Now()
+	// Opens in an hour, so calls are turned away until then.
+	later := gotime.TimeInterval{
+		Absolute: []gotime.AbsoluteRange{{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}},
+	}
+	for _, tc := range []struct {
+		name  string
+		gate  *gotime.Gate
+		code  codes.Code
+		retry bool
+	}{
+		{"open", gotime.NewGate(gotime.TimeInterval{}), codes.OK, false},
+		{"closed", gotime.NewGate(later), codes.Unavailable, true},
+		{"never open", gotime.NewGate(gotime.TimeInterval{Weekdays: []gotime.WeekdayRange{}}), codes.Unavailable, false},
+	} {
+		client := dial(t, tc.gate)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_, unaryErr := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, streamErr := stream.Recv()
+		cancel()
+		for kind, err := range map[string]error{"unary": unaryErr, "stream": streamErr} {
+			st := status.Convert(err)
+			if st.Code() != tc.code {
+				t.Errorf("%s: expected %s call to return %s, got %v", tc.name, kind, tc.code, err)
+				continue
+			}
+			var retry *errdetails.RetryInfo
+			for _, d := range st.Details() {
+				if r, ok := d.(*errdetails.RetryInfo); ok {
+					retry = r
+				}
+			}
+			if (retry != nil) != tc.retry {
+				t.Errorf("%s: expected RetryInfo on the %s call: %t, got %v", tc.name, kind, tc.retry, st.Details())
+			} else if retry != nil {
+				if d := retry.RetryDelay.AsDuration(); d <= 59*time.Minute || d > time.Hour {
+					t.Errorf("%s: expected a retry delay of about an hour on the %s call, got %s", tc.name, kind, d)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,557 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// hclListBlocks are the fields whose blocks are collected into a list even when only one is given.
+var hclListBlocks = map[string]bool{"times": true, "absolute": true, "except": true}
+
+// FromHCL reads the interval blocks of a file in the native syntax of HCL, as used by Terraform and other HashiCorp
+// style configuration, returning a definition for each block label. Fields are written as attributes with the same
+// names and values as in YAML, and fields holding intervals or ranges with several parts, such as times, except and
+// cycle, may also be written as nested blocks:
+//
+//	interval "business_hours" {
+//	  weekdays = ["monday:friday"]
+//	  times {
+//	    start_time = "09:00"
+//	    end_time   = "17:00"
+//	  }
+//	  except = ["holidays"]
+//	}
+//
+// Several blocks with the same label add intervals to the same definition, which may refer to others by name as in
+// Definitions. Attributes in a block written on one line may be separated by commas. Other blocks and attributes at
+// the top of the file are skipped without being evaluated, so schedules can be embedded in an application's own
+// configuration whatever expressions it uses. Values in interval blocks must be literals, as expressions such as
+// variables, functions and template interpolation aren't evaluated.
+func FromHCL(data []byte) (Definitions, error) {
+	p := hclParser{src: string(data), line: 1, col: 1}
+	items, err := p.parseBody(true)
+	if err != nil {
+		return nil, err
+	}
+	doc := yaml.MapSlice{}
+	index := map[string]int{}
+	for _, item := range items {
+		if item.body == nil || item.name != "interval" {
+			continue
+		}
+		if len(item.labels) != 1 {
+			return nil, &ParseError{Line: item.line, Column: item.col, Err: errors.New("An interval block must have a name")}
+		}
+		interval, err := hclValue(item.body)
+		if err != nil {
+			return nil, err
+		}
+		name := item.labels[0]
+		if i, ok := index[name]; ok {
+			doc[i].Value = append(doc[i].Value.([]interface{}), interval)
+			continue
+		}
+		index[name] = len(doc)
+		doc = append(doc, yaml.MapItem{Key: name, Value: []interface{}{interval}})
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var d Definitions
+	if err := yaml.Unmarshal(out, &d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// An hclItem is an attribute or block of an HCL body. Blocks have a body, which may be empty, and attributes a value.
+type hclItem struct {
+	name      string
+	labels    []string
+	value     interface{}
+	body      []hclItem
+	line, col int
+}
+
+// hclValue converts the items of a block body into a mapping with the same structure as YAML, collecting nested
+// blocks of the same type into lists.
+func hclValue(body []hclItem) (yaml.MapSlice, error) {
+	out := yaml.MapSlice{}
+	index := map[string]int{}
+	for _, item := range body {
+		i, seen := index[item.name]
+		if item.body == nil {
+			if seen {
+				return nil, &ParseError{Line: item.line, Column: item.col, Field: item.name,
+					Err: errors.New("Attribute is defined more than once")}
+			}
+			index[item.name] = len(out)
+			out = append(out, yaml.MapItem{Key: item.name, Value: item.value})
+			continue
+		}
+		if len(item.labels) != 0 {
+			return nil, &ParseError{Line: item.line, Column: item.col, Field: item.name,
+				Err: errors.New("Nested blocks don't take labels")}
+		}
+		value, err := hclValue(item.body)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !seen && hclListBlocks[item.name]:
+			index[item.name] = len(out)
+			out = append(out, yaml.MapItem{Key: item.name, Value: []interface{}{value}})
+		case !seen:
+			index[item.name] = len(out)
+			out = append(out, yaml.MapItem{Key: item.name, Value: value})
+		default:
+			list, ok := out[i].Value.([]interface{})
+			if !ok || !hclListBlocks[item.name] {
+				return nil, &ParseError{Line: item.line, Column: item.col, Field: item.name,
+					Err: errors.New("Block is defined more than once")}
+			}
+			out[i].Value = append(list, value)
+		}
+	}
+	return out, nil
+}
+
+// An hclParser reads the native syntax of HCL, tracking its position for errors.
+type hclParser struct {
+	src       string
+	pos       int
+	line, col int
+}
+
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Line: p.line, Column: p.col, Err: fmt.Errorf(format, args...)}
+}
+
+// advance moves past n bytes of the source.
+func (p *hclParser) advance(n int) {
+	for _, r := range p.src[p.pos : p.pos+n] {
+		if r == '\n' {
+			p.line, p.col = p.line+1, 1
+		} else {
+			p.col++
+		}
+	}
+	p.pos += n
+}
+
+// skip moves past spaces and comments, and past new lines if newlines is set. It returns true if it moved past a new
+// line.
+func (p *hclParser) skip(newlines bool) bool {
+	crossed := false
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r':
+			p.advance(1)
+		case rest[0] == '\n':
+			if !newlines {
+				return crossed
+			}
+			crossed = true
+			p.advance(1)
+		case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			p.advance(end)
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				p.advance(len(rest))
+				return crossed
+			}
+			crossed = crossed || strings.Contains(rest[:end+4], "\n")
+			p.advance(end + 4)
+		default:
+			return crossed
+		}
+	}
+	return crossed
+}
+
+func (p *hclParser) peek() byte {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// expect moves past the given character, returning an error if it isn't next.
+func (p *hclParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("Expected %q", c)
+	}
+	p.advance(1)
+	return nil
+}
+
+// parseBody reads attributes and blocks until the end of the source, if top is set, or the closing brace of a block.
+func (p *hclParser) parseBody(top bool) ([]hclItem, error) {
+	var items []hclItem
+	for {
+		p.skip(true)
+		if p.pos >= len(p.src) {
+			if !top {
+				return nil, p.errorf("Expected '}' before the end of the file")
+			}
+			return items, nil
+		}
+		if p.peek() == '}' && !top {
+			p.advance(1)
+			return items, nil
+		}
+		item := hclItem{line: p.line, col: p.col}
+		name, ok := p.identifier()
+		if !ok {
+			return nil, p.errorf("Expected an attribute or block")
+		}
+		item.name = name
+		p.skip(false)
+		if top && (name != "interval" || p.peek() == '=') {
+			// Only interval blocks are read, so anything else may hold expressions that can't be evaluated here.
+			if err := p.skipItem(); err != nil {
+				return nil, err
+			}
+		} else if p.peek() == '=' {
+			p.advance(1)
+			p.skip(false)
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			item.value = value
+		} else {
+			for p.peek() != '{' {
+				label, err := p.parseLabel()
+				if err != nil {
+					return nil, err
+				}
+				item.labels = append(item.labels, label)
+				p.skip(false)
+			}
+			p.advance(1)
+			body, err := p.parseBody(false)
+			if err != nil {
+				return nil, err
+			}
+			item.body = append([]hclItem{}, body...)
+		}
+		if !top || name == "interval" {
+			items = append(items, item)
+		}
+		// Each attribute or block ends its line, unless it's followed by a comma in a block written on one line.
+		crossed := p.skip(false)
+		if !top && !crossed && p.peek() == ',' {
+			p.advance(1)
+			continue
+		}
+		if !crossed && p.pos < len(p.src) && p.peek() != '\n' && p.peek() != '}' {
+			return nil, p.errorf("Expected a new line")
+		}
+	}
+}
+
+// skipItem moves past the rest of an attribute or block after its name without evaluating it.
+func (p *hclParser) skipItem() error {
+	if p.peek() == '=' {
+		p.advance(1)
+		return p.skipTokens(false)
+	}
+	for p.peek() != '{' {
+		if _, err := p.parseLabel(); err != nil {
+			return err
+		}
+		p.skip(false)
+	}
+	p.advance(1)
+	return p.skipTokens(true)
+}
+
+// skipTokens moves past the tokens of an expression up to the end of its line, or, if block is set, past the brace
+// closing the current block or template interpolation. Brackets are matched, so expressions may span lines inside
+// them, and strings and heredocs are skipped whole.
+func (p *hclParser) skipTokens(block bool) error {
+	depth := 0
+	for {
+		p.skip(block || depth > 0)
+		if p.pos >= len(p.src) {
+			if block || depth > 0 {
+				return p.errorf("Expected '}' before the end of the file")
+			}
+			return nil
+		}
+		rest := p.src[p.pos:]
+		switch c := rest[0]; {
+		case c == '\n':
+			return nil
+		case c == '(' || c == '[' || c == '{':
+			depth++
+			p.advance(1)
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				if block && c == '}' {
+					p.advance(1)
+					return nil
+				}
+				return p.errorf("Unexpected %q", c)
+			}
+			depth--
+			p.advance(1)
+		case c == '"':
+			if err := p.skipString(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(rest, "<<"):
+			if err := p.skipHeredoc(); err != nil {
+				return err
+			}
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			p.advance(size)
+		}
+	}
+}
+
+// skipString moves past a quoted string, including any templates in it.
+func (p *hclParser) skipString() error {
+	p.advance(1)
+	for {
+		if p.pos >= len(p.src) || p.peek() == '\n' {
+			return p.errorf("Expected '\"' to end the string")
+		}
+		rest := p.src[p.pos:]
+		switch {
+		case rest[0] == '"':
+			p.advance(1)
+			return nil
+		case strings.HasPrefix(rest, "$${") || strings.HasPrefix(rest, "%%{"):
+			p.advance(3)
+		case strings.HasPrefix(rest, "${") || strings.HasPrefix(rest, "%{"):
+			p.advance(2)
+			if err := p.skipTokens(true); err != nil {
+				return err
+			}
+		case rest[0] == '\\' && len(rest) > 1:
+			p.advance(2)
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			p.advance(size)
+		}
+	}
+}
+
+// skipHeredoc moves past a heredoc string such as <<EOT or <<-EOT, up to the end of the line holding its delimiter.
+func (p *hclParser) skipHeredoc() error {
+	line, col := p.line, p.col
+	p.advance(2)
+	if p.peek() == '-' {
+		p.advance(1)
+	}
+	delimiter, ok := p.identifier()
+	if !ok {
+		return p.errorf("Expected a heredoc delimiter")
+	}
+	for {
+		end := strings.IndexByte(p.src[p.pos:], '\n')
+		if end < 0 {
+			return &ParseError{Line: line, Column: col, Err: fmt.Errorf("Expected %s to end the heredoc", delimiter)}
+		}
+		p.advance(end + 1)
+		line := p.src[p.pos:]
+		if end := strings.IndexByte(line, '\n'); end >= 0 {
+			line = line[:end]
+		}
+		if strings.TrimSpace(line) == delimiter {
+			p.advance(len(line))
+			return nil
+		}
+	}
+}
+
+func (p *hclParser) identifier() (string, bool) {
+	end := p.pos
+	for end < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[end:])
+		if !(unicode.IsLetter(r) || r == '_' || (end > p.pos && (unicode.IsDigit(r) || r == '-'))) {
+			break
+		}
+		end += size
+	}
+	if end == p.pos {
+		return "", false
+	}
+	name := p.src[p.pos:end]
+	p.advance(end - p.pos)
+	return name, true
+}
+
+func (p *hclParser) parseLabel() (string, error) {
+	if p.peek() == '"' {
+		return p.parseString()
+	}
+	if label, ok := p.identifier(); ok {
+		return label, nil
+	}
+	return "", p.errorf("Expected a block label or '{'")
+}
+
+// parseValue reads a literal value: a string, number, bool, null, tuple or object.
+func (p *hclParser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '"':
+		return p.parseString()
+	case c == '[':
+		p.advance(1)
+		list := []interface{}{}
+		for {
+			p.skip(true)
+			if p.peek() == ']' {
+				p.advance(1)
+				return list, nil
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+			p.skip(true)
+			if p.peek() == ',' {
+				p.advance(1)
+			} else if p.peek() != ']' {
+				return nil, p.errorf("Expected ',' or ']'")
+			}
+		}
+	case c == '{':
+		p.advance(1)
+		object := yaml.MapSlice{}
+		for {
+			p.skip(true)
+			if p.peek() == '}' {
+				p.advance(1)
+				return object, nil
+			}
+			key, err := p.parseLabel()
+			if err != nil {
+				return nil, err
+			}
+			p.skip(false)
+			if p.peek() != '=' && p.peek() != ':' {
+				return nil, p.errorf("Expected '=' or ':'")
+			}
+			p.advance(1)
+			p.skip(false)
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, yaml.MapItem{Key: key, Value: value})
+			// Items of an object are separated by commas or new lines.
+			if !p.skip(false) && p.peek() == ',' {
+				p.advance(1)
+			}
+		}
+	case c == '-' || (c >= '0' && c <= '9'):
+		end := p.pos + 1
+		for end < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[end]) >= 0 {
+			end++
+		}
+		text := p.src[p.pos:end]
+		if n, err := strconv.Atoi(text); err == nil {
+			p.advance(end - p.pos)
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, p.errorf("%s is not a valid number", text)
+		}
+		p.advance(end - p.pos)
+		return f, nil
+	}
+	if name, ok := p.identifier(); ok {
+		switch name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return nil, p.errorf("Unable to evaluate %s, only literal values are supported", name)
+	}
+	if strings.HasPrefix(p.src[p.pos:], "<<") {
+		return nil, p.errorf("Unable to read heredoc strings, only quoted strings are supported")
+	}
+	return nil, p.errorf("Expected a value")
+}
+
+// parseString reads a quoted string, replacing its escape sequences.
+func (p *hclParser) parseString() (string, error) {
+	if err := p.expect('"'); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) || p.peek() == '\n' {
+			return "", p.errorf("Expected '\"' to end the string")
+		}
+		rest := p.src[p.pos:]
+		switch {
+		case rest[0] == '"':
+			p.advance(1)
+			return b.String(), nil
+		case strings.HasPrefix(rest, "${") || strings.HasPrefix(rest, "%{"):
+			return "", p.errorf("Unable to evaluate templates, only literal values are supported")
+		case strings.HasPrefix(rest, "$${") || strings.HasPrefix(rest, "%%{"):
+			b.WriteString(rest[1:3])
+			p.advance(3)
+		case rest[0] == '\\':
+			if len(rest) < 2 {
+				return "", p.errorf("Expected '\"' to end the string")
+			}
+			n := 2
+			switch rest[1] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(rest[1])
+			case 'u', 'U':
+				n = 6
+				if rest[1] == 'U' {
+					n = 10
+				}
+				if len(rest) < n {
+					return "", p.errorf("%s is not a valid escape sequence", rest)
+				}
+				r, err := strconv.ParseUint(rest[2:n], 16, 32)
+				if err != nil {
+					return "", p.errorf("%s is not a valid escape sequence", rest[:n])
+				}
+				b.WriteRune(rune(r))
+			default:
+				return "", p.errorf("%s is not a valid escape sequence", rest[:2])
+			}
+			p.advance(n)
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			b.WriteString(rest[:size])
+			p.advance(size)
+		}
+	}
+}
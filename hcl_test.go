@@ -0,0 +1,198 @@
+package gotime
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const hclConfig = `# Application configuration with embedded schedules
+listen = "0.0.0.0:8080"
+
+interval "holidays" {
+  months        = ["december"]
+  days_of_month = ["25:26"]
+}
+
+interval "business_hours" {
+  description = "Open \"business\" hours – weekdays"
+  weekdays    = ["monday:friday"]
+  times {
+    start_time = "09:00"
+    end_time   = "12:00"
+  }
+  times {
+    start_time = "13:00" // after lunch
+    end_time   = "17:00"
+  }
+  location = "Europe/London"
+  except   = ["holidays"]
+}
+
+/* Saturday mornings are added to business hours
+   by a second block with the same name. */
+interval "business_hours" {
+  weekdays = ["saturday"]
+  times    = [{ start_time = "09:00", end_time = "12:00" }]
+  cycle {
+    anchor = "2024-01-06"
+    every  = "2w"
+  }
+}
+
+interval "freeze" {
+  absolute {
+    start = "2024-12-20T00:00:00Z"
+    end   = "2025-01-06T00:00:00Z"
+  }
+  except {}
+}
+
+backend "s3" {
+  bucket = "schedules"
+}
+`
+
+func TestFromHCL(t *testing.T) {
+	d, err := FromHCL([]byte(hclConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) != 3 || len(d["business_hours"]) != 2 {
+		t.Fatalf("Expected 3 definitions with 2 business hours intervals, got %v", d)
+	}
+	if got := d["business_hours"][0].Description; got != "Open \"business\" hours – weekdays" {
+		t.Errorf("Expected escape sequences to be replaced, got %q", got)
+	}
+	if d["business_hours"][1].Name != "business_hours" {
+		t.Errorf("Expected intervals to be named after their block, got %+v", d["business_hours"][1])
+	}
+	for _, c := range []struct {
+		name  string
+		at    string
+		match bool
+	}{
+		{"business_hours", "2024-07-01T09:00:00+01:00", true},
+		{"business_hours", "2024-07-01T12:30:00+01:00", false},
+		{"business_hours", "2024-07-01T16:59:00+01:00", true},
+		{"business_hours", "2024-12-25T10:00:00Z", false},
+		{"business_hours", "2024-01-06T10:00:00Z", true},
+		{"business_hours", "2024-01-13T10:00:00Z", false},
+		{"holidays", "2024-12-26T10:00:00Z", true},
+		{"freeze", "2024-12-25T10:00:00Z", false},
+	} {
+		at, err := time.Parse(time.RFC3339, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d[c.name].ContainsTime(at); got != c.match {
+			t.Errorf("Expected %s to contain %s: %t, got %t", c.name, c.at, c.match, got)
+		}
+	}
+}
+
+func TestFromHCLErrors(t *testing.T) {
+	for _, c := range []struct {
+		config string
+		line   int
+	}{
+		{"interval {\n  weekdays = [\"monday\"]\n}\n", 1},
+		{"interval \"a\" {\n  weekdays = [\"monday\"]\n  weekdays = [\"friday\"]\n}\n", 3},
+		{"interval \"a\" {\n  cycle {\n    every = \"2w\"\n  }\n  cycle {\n    every = \"3w\"\n  }\n}\n", 5},
+		{"interval \"a\" {\n  times \"b\" {\n  }\n}\n", 2},
+		{"interval \"a\" {\n  weekdays = var.days\n}\n", 2},
+		{"interval \"a\" {\n  description = \"${var.name}\"\n}\n", 2},
+		{"interval \"a\" {\n  description = <<EOT\nhello\nEOT\n}\n", 2},
+		{"interval \"a\" {\n  description = \"unterminated\n}\n", 2},
+		{"interval \"a\" {\n  weekdays = [\"monday\" \"friday\"]\n}\n", 2},
+		{"interval \"a\" {\n  weekdays = [\"monday\"] months = [\"may\"]\n}\n", 2},
+		{"interval \"a\" {\n  weekdays = [\"monday\"]\n", 3},
+		{"locals {\n  a = \"${var.b\"\n}\n", 2},
+		{"locals {\n  a = <<EOT\nhello\n}\n", 2},
+		{"locals {\n  a = [1, 2\n}\n", 4},
+	} {
+		d, err := FromHCL([]byte(c.config))
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Expected a ParseError parsing\n%s\ngot %v, %v", c.config, d, err)
+			continue
+		}
+		if parseErr.Line != c.line {
+			t.Errorf("Expected an error on line %d parsing\n%s\ngot %v", c.line, c.config, err)
+		}
+	}
+	for _, config := range []string{
+		"interval \"a\" {\n  weekdays = [\"someday\"]\n}\n",
+		"interval \"a\" {\n  except = [\"missing\"]\n}\n",
+	} {
+		if d, err := FromHCL([]byte(config)); err == nil {
+			t.Errorf("Expected an error parsing\n%s\ngot %v", config, d)
+		}
+	}
+}
+
+const hclMixedConfig = `terraform {
+  required_providers {
+    aws = { source = "hashicorp/aws", version = "~> 5.0" }
+  }
+}
+
+variable "env" {
+  default = "prod"
+}
+
+locals {
+  name   = "${var.env}-${lookup(var.names, "app", "default")}"
+  tags   = merge(var.tags, { Team = "sre" })
+  ports  = [for p in var.ports : p + 1 if p > 0]
+  banner = <<-EOT
+    Maintenance } windows ]
+    for ${var.env}
+    EOT
+  ready  = var.enabled ? "yes" : "no"
+}
+
+resource "aws_instance" "web" {
+  count         = length(var.zones)
+  instance_type = var.size
+  user_data     = <<EOF
+#!/bin/sh
+echo "{"
+EOF
+}
+
+owner = var.owner
+
+interval "maintenance" { weekdays = ["sunday"], times = [{ start_time = "02:00", end_time = "04:00" }] }
+
+interval "weekends" { weekdays = ["saturday", "sunday"] }
+`
+
+func TestFromHCLMixedContent(t *testing.T) {
+	d, err := FromHCL([]byte(hclMixedConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) != 2 {
+		t.Fatalf("Expected 2 definitions, got %v", d)
+	}
+	for _, c := range []struct {
+		name  string
+		at    string
+		match bool
+	}{
+		{"maintenance", "2024-06-02T03:00:00Z", true},
+		{"maintenance", "2024-06-02T05:00:00Z", false},
+		{"maintenance", "2024-06-03T03:00:00Z", false},
+		{"weekends", "2024-06-01T12:00:00Z", true},
+		{"weekends", "2024-06-03T12:00:00Z", false},
+	} {
+		at, err := time.Parse(time.RFC3339, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d[c.name].ContainsTime(at); got != c.match {
+			t.Errorf("Expected %s to contain %s: %t, got %t", c.name, c.at, c.match, got)
+		}
+	}
+}
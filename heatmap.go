@@ -0,0 +1,128 @@
+package gotime
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sort"
+	"time"
+)
+
+// Dimensions of heatmaps, in pixels.
+const (
+	heatmapCell   = 16
+	heatmapLabel  = 40
+	heatmapHeader = 36
+	heatmapGap    = 12
+)
+
+var (
+	heatmapActive   = color.RGBA{0x2e, 0x7d, 0x32, 0xff}
+	heatmapInactive = color.RGBA{0xee, 0xee, 0xee, 0xff}
+)
+
+// weekCoverage returns the fraction of each hour of each day of the week starting on the Monday on or before t, in
+// t's location, during which m is active.
+func weekCoverage(m Matcher, t time.Time) [7][24]float64 {
+	tm := asTransitionMatcher(m)
+	monday := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	monday = monday.AddDate(0, 0, -int((monday.Weekday()+6)%7))
+	var coverage [7][24]float64
+	for d := range coverage {
+		date := monday.AddDate(0, 0, d)
+		windows := transitionWindows(tm, date, date.AddDate(0, 0, 1))
+		for h := range coverage[d] {
+			start := time.Date(date.Year(), date.Month(), date.Day(), h, 0, 0, 0, date.Location())
+			end := start.Add(time.Hour)
+			coverage[d][h] = float64(activeWithin(windows, start, end)) / float64(time.Hour)
+		}
+	}
+	return coverage
+}
+
+// sortedNames returns the names of the definitions in order.
+func (d Definitions) sortedNames() []string {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderHeatmapSVG writes an SVG image with a heatmap of the week starting on the Monday on or before t, in t's
+// location, for each definition in order of name. Each heatmap has a row for each day and a column for each hour,
+// shaded by how much of the hour the definition is active, for embedding in dashboards and runbooks.
+func RenderHeatmapSVG(w io.Writer, d Definitions, t time.Time) error {
+	names := d.sortedNames()
+	width := heatmapLabel + 24*heatmapCell
+	panel := heatmapHeader + 7*heatmapCell + heatmapGap
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" `+
+		`font-size="10">`+"\n", width, panel*len(names))
+	for i, name := range names {
+		coverage := weekCoverage(d[name], t)
+		top := i * panel
+		fmt.Fprintf(bw, `<text x="0" y="%d" font-size="12" font-weight="bold">%s</text>`+"\n", top+12,
+			html.EscapeString(name))
+		for h := 0; h < 24; h += 3 {
+			fmt.Fprintf(bw, `<text x="%d" y="%d">%02d</text>`+"\n", heatmapLabel+h*heatmapCell, top+heatmapHeader-4, h)
+		}
+		for day, hours := range coverage {
+			y := top + heatmapHeader + day*heatmapCell
+			fmt.Fprintf(bw, `<text x="0" y="%d">%s</text>`+"\n", y+heatmapCell-4, time.Weekday((day + 1) % 7).String()[:3])
+			for h, active := range hours {
+				x := heatmapLabel + h*heatmapCell
+				fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s %02d:00 %d%%</title></rect>`+
+					"\n", x, y, heatmapCell-1, heatmapCell-1, hexColor(heatmapShade(active)),
+					time.Weekday((day+1)%7), h, int(active*100+0.5))
+			}
+		}
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// RenderHeatmapPNG writes the heatmaps of RenderHeatmapSVG as a PNG image. The image has no text, so the heatmaps
+// are in order of name, with days from Monday at the top and hours from midnight at the left.
+func RenderHeatmapPNG(w io.Writer, d Definitions, t time.Time) error {
+	names := d.sortedNames()
+	width := 24 * heatmapCell
+	panel := 7*heatmapCell + heatmapGap
+	img := image.NewRGBA(image.Rect(0, 0, width, panel*len(names)))
+	for i, name := range names {
+		for day, hours := range weekCoverage(d[name], t) {
+			for h, active := range hours {
+				shade := heatmapShade(active)
+				x, y := h*heatmapCell, i*panel+day*heatmapCell
+				for py := y; py < y+heatmapCell-1; py++ {
+					for px := x; px < x+heatmapCell-1; px++ {
+						img.Set(px, py, shade)
+					}
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// heatmapShade blends the inactive and active colours by the fraction of a cell that is active.
+func heatmapShade(active float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*active + 0.5)
+	}
+	return color.RGBA{
+		mix(heatmapInactive.R, heatmapActive.R),
+		mix(heatmapInactive.G, heatmapActive.G),
+		mix(heatmapInactive.B, heatmapActive.B),
+		0xff,
+	}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
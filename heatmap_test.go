@@ -0,0 +1,79 @@
+package gotime
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeatmap(t *testing.T) {
+	holiday := time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC)
+	d := Definitions{
+		"business_hours": {{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1005}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Except:   []TimeInterval{{Dates: []DateRange{{Begin: holiday, End: holiday}}}},
+		}},
+		"saturdays": {{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}}}},
+	}
+	coverage := weekCoverage(d["business_hours"], holiday)
+	cases := []struct {
+		day, hour int
+		expected  float64
+	}{
+		{0, 8, 0},
+		{0, 9, 1},
+		{0, 16, 0.75},
+		{3, 12, 0},
+		{4, 12, 1},
+		{5, 12, 0},
+	}
+	for _, c := range cases {
+		if got := coverage[c.day][c.hour]; got != c.expected {
+			t.Errorf("Expected coverage of %v on day %d, hour %d, got %v", c.expected, c.day, c.hour, got)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := RenderHeatmapSVG(&b, d, holiday); err != nil {
+		t.Fatalf("Unexpected error rendering SVG: %v", err)
+	}
+	svg := b.String()
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="424" height="320"`,
+		`>business_hours</text>`,
+		`>saturdays</text>`,
+		`fill="#2e7d32"><title>Monday 09:00 100%</title>`,
+		`<title>Monday 16:00 75%</title>`,
+		`fill="#eeeeee"><title>Thursday 12:00 0%</title>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected SVG to contain %q", want)
+		}
+	}
+	if strings.Index(svg, "business_hours") > strings.Index(svg, "saturdays") {
+		t.Error("Expected heatmaps in order of name")
+	}
+
+	b.Reset()
+	if err := RenderHeatmapPNG(&b, d, holiday); err != nil {
+		t.Fatalf("Unexpected error rendering PNG: %v", err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 384 || size.Y != 248 {
+		t.Errorf("Expected a 384x248 image, got %dx%d", size.X, size.Y)
+	}
+	// Saturday midday in the second heatmap is active, Friday midday isn't.
+	panel := 7*heatmapCell + heatmapGap
+	if r, _, _, _ := img.At(12*heatmapCell, panel+5*heatmapCell).RGBA(); uint8(r>>8) != heatmapActive.R {
+		t.Errorf("Expected Saturday midday to be shaded active")
+	}
+	if r, _, _, _ := img.At(12*heatmapCell, panel+4*heatmapCell).RGBA(); uint8(r>>8) != heatmapInactive.R {
+		t.Errorf("Expected Friday midday to be shaded inactive")
+	}
+}
@@ -0,0 +1,209 @@
+package gotime
+
+import (
+	"sync"
+	"time"
+)
+
+// A ruleCalendar is a HolidayProvider whose holidays are computed from the rules that set them each year. The
+// holidays of each year are computed once, the first time a day in that year is asked about.
+type ruleCalendar struct {
+	holidays func(year int) []time.Time
+	mu       sync.RWMutex
+	years    map[int]map[int]bool
+}
+
+func newRuleCalendar(holidays func(year int) []time.Time) *ruleCalendar {
+	return &ruleCalendar{holidays: holidays, years: make(map[int]map[int]bool)}
+}
+
+// IsHoliday returns true if the date of the given time is one of the calendar's holidays.
+func (c *ruleCalendar) IsHoliday(day time.Time) bool {
+	c.mu.RLock()
+	days, ok := c.years[day.Year()]
+	c.mu.RUnlock()
+	if !ok {
+		days = make(map[int]bool)
+		for _, h := range c.holidays(day.Year()) {
+			if h.Year() == day.Year() {
+				days[h.YearDay()] = true
+			}
+		}
+		c.mu.Lock()
+		c.years[day.Year()] = days
+		c.mu.Unlock()
+	}
+	return days[day.YearDay()]
+}
+
+func init() {
+	// These calendars give the days on which holidays are observed, so a holiday falling on a weekend is replaced by
+	// the weekday that is taken off in its place. They follow the rules in force today, and may not match the dates of
+	// years before those rules were introduced.
+	RegisterHolidayProvider("us-federal", newRuleCalendar(usFederalHolidays))
+	RegisterHolidayProvider("uk-bank", newRuleCalendar(ukBankHolidays))
+	RegisterHolidayProvider("au-national", newRuleCalendar(auNationalHolidays))
+	RegisterHolidayProvider("eu-target", newRuleCalendar(euTargetHolidays))
+}
+
+// usFederalHolidays returns the days on which the federal holidays of the United States are observed in the given
+// year. New Year's Day may be observed on the last day of the previous year.
+func usFederalHolidays(year int) []time.Time {
+	var days []time.Time
+	for _, y := range []int{year, year + 1} {
+		days = append(days,
+			nearestWeekday(date(y, time.January, 1)),
+			nthWeekday(y, time.January, 3, time.Monday),  // Birthday of Martin Luther King, Jr.
+			nthWeekday(y, time.February, 3, time.Monday), // Washington's Birthday
+			nthWeekday(y, time.May, -1, time.Monday),     // Memorial Day
+			nearestWeekday(date(y, time.July, 4)),
+			nthWeekday(y, time.September, 1, time.Monday),  // Labor Day
+			nthWeekday(y, time.October, 2, time.Monday),    // Columbus Day
+			nearestWeekday(date(y, time.November, 11)),     // Veterans Day
+			nthWeekday(y, time.November, 4, time.Thursday), // Thanksgiving Day
+			nearestWeekday(date(y, time.December, 25)),
+		)
+		if y >= 2021 {
+			days = append(days, nearestWeekday(date(y, time.June, 19))) // Juneteenth
+		}
+	}
+	return days
+}
+
+// ukSpecialBankHolidays holds bank holidays in England and Wales that were declared for a single year, and the usual
+// bank holidays that were moved to make way for them.
+var ukSpecialBankHolidays = map[int]struct{ add, remove []time.Time }{
+	1999: {add: []time.Time{date(1999, time.December, 31)}},
+	2002: {add: []time.Time{date(2002, time.June, 3), date(2002, time.June, 4)}, remove: []time.Time{date(2002, time.May, 27)}},
+	2011: {add: []time.Time{date(2011, time.April, 29)}},
+	2012: {add: []time.Time{date(2012, time.June, 4), date(2012, time.June, 5)}, remove: []time.Time{date(2012, time.May, 28)}},
+	2020: {add: []time.Time{date(2020, time.May, 8)}, remove: []time.Time{date(2020, time.May, 4)}},
+	2022: {
+		add:    []time.Time{date(2022, time.June, 2), date(2022, time.June, 3), date(2022, time.September, 19)},
+		remove: []time.Time{date(2022, time.May, 30)},
+	},
+	2023: {add: []time.Time{date(2023, time.May, 8)}},
+}
+
+// ukBankHolidays returns the bank holidays of England and Wales in the given year.
+func ukBankHolidays(year int) []time.Time {
+	easter := easterSunday(year)
+	days := []time.Time{
+		nextWeekday(date(year, time.January, 1)),
+		easter.AddDate(0, 0, -2),                       // Good Friday
+		easter.AddDate(0, 0, 1),                        // Easter Monday
+		nthWeekday(year, time.May, 1, time.Monday),     // Early May bank holiday
+		nthWeekday(year, time.May, -1, time.Monday),    // Spring bank holiday
+		nthWeekday(year, time.August, -1, time.Monday), // Summer bank holiday
+	}
+	days = append(days, christmasHolidays(year)...)
+	special := ukSpecialBankHolidays[year]
+	out := special.add
+	for _, d := range days {
+		removed := false
+		for _, r := range special.remove {
+			removed = removed || d.Equal(r)
+		}
+		if !removed {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// auNationalHolidays returns the public holidays observed throughout Australia in the given year. Holidays set by
+// the states and territories, such as the King's Birthday, are not included.
+func auNationalHolidays(year int) []time.Time {
+	easter := easterSunday(year)
+	days := []time.Time{
+		nextWeekday(date(year, time.January, 1)),
+		nextWeekday(date(year, time.January, 26)), // Australia Day
+		easter.AddDate(0, 0, -2),                  // Good Friday
+		easter.AddDate(0, 0, 1),                   // Easter Monday
+		date(year, time.April, 25),                // Anzac Day
+	}
+	return append(days, christmasHolidays(year)...)
+}
+
+// euTargetHolidays returns the closing days of the Eurosystem's TARGET payment system in the given year, which are
+// widely used as the holidays of the euro area.
+func euTargetHolidays(year int) []time.Time {
+	easter := easterSunday(year)
+	return []time.Time{
+		date(year, time.January, 1),
+		easter.AddDate(0, 0, -2), // Good Friday
+		easter.AddDate(0, 0, 1),  // Easter Monday
+		date(year, time.May, 1),  // Labour Day
+		date(year, time.December, 25),
+		date(year, time.December, 26),
+	}
+}
+
+// christmasHolidays returns the days on which Christmas Day and Boxing Day are observed in the given year, moving
+// either that falls on a weekend to the following weekdays.
+func christmasHolidays(year int) []time.Time {
+	christmas := date(year, time.December, 25)
+	switch christmas.Weekday() {
+	case time.Friday:
+		return []time.Time{christmas, christmas.AddDate(0, 0, 3)}
+	case time.Saturday:
+		return []time.Time{date(year, time.December, 27), date(year, time.December, 28)}
+	case time.Sunday:
+		return []time.Time{date(year, time.December, 26), date(year, time.December, 27)}
+	}
+	return []time.Time{christmas, christmas.AddDate(0, 0, 1)}
+}
+
+// easterSunday returns the date of Easter Sunday in the Gregorian calendar for the given year.
+func easterSunday(year int) time.Time {
+	// The anonymous Gregorian algorithm, also known as the Meeus/Jones/Butcher algorithm.
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
+
+// nthWeekday returns the nth occurrence of a weekday in the given month, or the last occurrence if n is -1.
+func nthWeekday(year int, month time.Month, n int, wd time.Weekday) time.Time {
+	if n == lastOccurrence {
+		last := date(year, month+1, 0)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(wd) + 7) % 7))
+	}
+	first := date(year, month, 1)
+	return first.AddDate(0, 0, (int(wd)-int(first.Weekday())+7)%7+7*(n-1))
+}
+
+// nearestWeekday moves a date falling on a Saturday back to the Friday, and one falling on a Sunday forward to the
+// Monday.
+func nearestWeekday(t time.Time) time.Time {
+	switch t.Weekday() {
+	case time.Saturday:
+		return t.AddDate(0, 0, -1)
+	case time.Sunday:
+		return t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// nextWeekday moves a date falling on a weekend forward to the following Monday.
+func nextWeekday(t time.Time) time.Time {
+	switch t.Weekday() {
+	case time.Saturday:
+		return t.AddDate(0, 0, 2)
+	case time.Sunday:
+		return t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,69 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+)
+
+var holidayDataTestCases = []struct {
+	calendar string
+	holidays []string
+	excludes []string
+}{
+	{
+		calendar: "us-federal",
+		holidays: []string{
+			"2021-01-01", "2021-01-18", "2021-02-15", "2021-05-31", "2021-06-18", "2021-07-05", "2021-09-06",
+			"2021-10-11", "2021-11-11", "2021-11-25", "2021-12-24", "2021-12-31",
+		},
+		excludes: []string{"2021-07-04", "2021-12-25", "2020-06-19", "2021-01-19"},
+	},
+	{
+		calendar: "uk-bank",
+		holidays: []string{
+			"2020-01-01", "2020-04-10", "2020-04-13", "2020-05-08", "2020-05-25", "2020-08-31", "2020-12-25", "2020-12-28",
+			"2022-01-03", "2022-06-02", "2022-06-03", "2022-09-19", "2022-12-26", "2022-12-27",
+		},
+		excludes: []string{"2020-05-04", "2020-12-26", "2022-05-30", "2022-12-25"},
+	},
+	{
+		calendar: "au-national",
+		holidays: []string{"2021-01-01", "2021-01-26", "2021-04-02", "2021-04-05", "2021-04-25", "2021-12-27", "2021-12-28"},
+		excludes: []string{"2021-04-26", "2021-12-25", "2021-06-14"},
+	},
+	{
+		calendar: "eu-target",
+		holidays: []string{"2024-01-01", "2024-03-29", "2024-04-01", "2024-05-01", "2024-12-25", "2024-12-26"},
+		excludes: []string{"2024-03-31", "2024-12-24", "2024-12-27"},
+	},
+}
+
+func TestHolidayData(t *testing.T) {
+	for _, tc := range holidayDataTestCases {
+		p, ok := lookupHolidayProvider(tc.calendar)
+		if !ok {
+			t.Errorf("Expected %s to be a built-in holiday calendar", tc.calendar)
+			continue
+		}
+		for _, d := range tc.holidays {
+			day, _ := time.Parse(CycleDateLayout, d)
+			if !p.IsHoliday(day) {
+				t.Errorf("Expected %s to be a holiday in %s", d, tc.calendar)
+			}
+		}
+		for _, d := range tc.excludes {
+			day, _ := time.Parse(CycleDateLayout, d)
+			if p.IsHoliday(day) {
+				t.Errorf("Expected %s not to be a holiday in %s", d, tc.calendar)
+			}
+		}
+	}
+}
+
+func TestEasterSunday(t *testing.T) {
+	for year, want := range map[int]string{2019: "2019-04-21", 2024: "2024-03-31", 2038: "2038-04-25", 2285: "2285-03-22"} {
+		if got := easterSunday(year).Format(CycleDateLayout); got != want {
+			t.Errorf("Easter Sunday %d: want %s, got %s", year, want, got)
+		}
+	}
+}
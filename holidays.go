@@ -0,0 +1,80 @@
+package gotime
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A HolidayProvider decides which days are holidays. IsHoliday is given a time in the interval's location and should
+// report whether its calendar date is a holiday.
+type HolidayProvider interface {
+	IsHoliday(day time.Time) bool
+}
+
+// The HolidayProviderFunc type is an adapter to allow the use of ordinary functions as HolidayProviders.
+type HolidayProviderFunc func(day time.Time) bool
+
+// IsHoliday calls f(day).
+func (f HolidayProviderFunc) IsHoliday(day time.Time) bool {
+	return f(day)
+}
+
+// Holidays wraps a HolidayProvider so that it can be represented by the name it was registered under, e.g.
+// holidays: 'us-federal'. When an interval has Holidays it only matches days that are holidays, so holidays are
+// excluded from an interval by listing them among its exceptions.
+type Holidays struct {
+	Name string
+	HolidayProvider
+}
+
+var (
+	holidayProvidersMu sync.RWMutex
+	holidayProviders   = map[string]HolidayProvider{}
+)
+
+// RegisterHolidayProvider makes a holiday calendar available to intervals under the given name, replacing any
+// existing calendar with that name. The holidays of the United States federal government (us-federal), bank holidays
+// in England and Wales (uk-bank), Australian national public holidays (au-national) and closing days of the euro
+// area's TARGET payment system (eu-target) are available without being registered.
+func RegisterHolidayProvider(name string, p HolidayProvider) {
+	holidayProvidersMu.Lock()
+	defer holidayProvidersMu.Unlock()
+	holidayProviders[strings.ToLower(name)] = p
+}
+
+// lookupHolidayProvider returns the holiday calendar registered under the given name.
+func lookupHolidayProvider(name string) (HolidayProvider, bool) {
+	holidayProvidersMu.RLock()
+	defer holidayProvidersMu.RUnlock()
+	p, ok := holidayProviders[strings.ToLower(name)]
+	return p, ok
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for Holidays.
+func (h *Holidays) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	p, ok := lookupHolidayProvider(str)
+	if !ok {
+		return fmt.Errorf("%s is not a known holiday calendar", str)
+	}
+	h.Name, h.HolidayProvider = strings.ToLower(str), p
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for Holidays
+func (h Holidays) MarshalYAML() (interface{}, error) {
+	if h.Name == "" {
+		return nil, fmt.Errorf("Unable to marshal holidays without a name")
+	}
+	return interface{}(h.Name), nil
+}
+
+// containsDay returns true if the calendar day of the given time is a holiday.
+func (h Holidays) containsDay(t time.Time) bool {
+	return h.HolidayProvider != nil && h.IsHoliday(t)
+}
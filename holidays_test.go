@@ -0,0 +1,105 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// testHolidays treats Christmas Day and New Year's Day as holidays.
+var testHolidays = HolidayProviderFunc(func(day time.Time) bool {
+	return (day.Month() == time.December && day.Day() == 25) || (day.Month() == time.January && day.Day() == 1)
+})
+
+func init() {
+	RegisterHolidayProvider("test", testHolidays)
+}
+
+var holidayTestCases = []struct {
+	in          string
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		in:       "holidays: 'test'",
+		contains: []string{"2020-12-25T10:00:00Z", "2021-01-01T00:00:00Z"},
+		excludes: []string{"2020-12-24T23:59:59Z", "2020-12-26T00:00:00Z"},
+	},
+	{
+		// Business hours except holidays
+		in: `
+weekdays: ['monday:friday']
+times: [{start_time: '09:00', end_time: '17:00'}]
+except: [{holidays: 'Test'}]
+`,
+		contains: []string{"2020-12-24T10:00:00Z", "2020-12-28T10:00:00Z"},
+		excludes: []string{"2020-12-25T10:00:00Z", "2021-01-01T10:00:00Z"},
+	},
+	{
+		in:          "holidays: 'nowhere'",
+		expectError: true,
+	},
+}
+
+func TestHolidays(t *testing.T) {
+	for _, tc := range holidayTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		// Functions are never deeply equal, so compare what the re-parsed interval marshals to instead.
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil {
+			t.Error(err)
+		}
+		if out2, err := yaml.Marshal(ti2); err != nil || string(out) != string(out2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestHolidaysAnalysis(t *testing.T) {
+	holidays := TimeInterval{Holidays: &Holidays{Name: "test", HolidayProvider: testHolidays}}
+	if holidays.IsAlwaysActive() || holidays.IsEmpty() {
+		t.Errorf("Expected holidays to be neither always active nor empty")
+	}
+	got := holidays.Windows(mustParseTime("2020-12-01T00:00:00Z"), mustParseTime("2021-02-01T00:00:00Z"))
+	want := []Window{
+		{Start: mustParseTime("2020-12-25T00:00:00Z"), End: mustParseTime("2020-12-26T00:00:00Z")},
+		{Start: mustParseTime("2021-01-01T00:00:00Z"), End: mustParseTime("2021-01-02T00:00:00Z")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Windows of holidays: want %v, got %v", want, got)
+	}
+	other := TimeInterval{Holidays: &Holidays{Name: "other", HolidayProvider: testHolidays}}
+	if _, err := Intersect(holidays, other); err != ErrNotRepresentable {
+		t.Errorf("Expected intersecting different holiday calendars to be unrepresentable, got %v", err)
+	}
+	if _, err := yaml.Marshal(TimeInterval{Holidays: &Holidays{HolidayProvider: testHolidays}}); err == nil {
+		t.Errorf("Expected marshalling unnamed holidays to fail")
+	}
+}
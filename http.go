@@ -0,0 +1,128 @@
+package gotime
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// An HTTPGate is net/http middleware that serves requests only while its Matcher is active, such as during business
+// hours. Requests at other times are turned away with a status of 503 Service Unavailable by default, and a
+// Retry-After header giving the number of seconds until the Matcher is next active, if it ever is. To turn requests
+// away during a maintenance window instead, use Not(window) as the Matcher.
+type HTTPGate struct {
+	Matcher Matcher
+	// StatusCode is the status of requests that are turned away, or 503 if it is zero.
+	StatusCode int
+	// Rejected, if set, writes the response to requests that are turned away in place of the default plain text
+	// message. The Retry-After header is set before it is called. Responses are sent with StatusCode unless Rejected
+	// writes a status of its own, which takes precedence.
+	Rejected http.Handler
+}
+
+// Middleware returns a function that wraps handlers in an HTTPGate for m with the default response, for use with
+// routers that take middleware in that form.
+func Middleware(m Matcher) func(http.Handler) http.Handler {
+	return HTTPGate{Matcher: m}.Wrap
+}
+
+// Wrap returns a handler that passes requests to next while the gate's Matcher is active and turns them away at other
+// times.
+func (g HTTPGate) Wrap(next http.Handler) http.Handler {
+	tm := asTransitionMatcher(g.Matcher)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		if tm.ContainsTime(now) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		status := g.StatusCode
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		msg := http.StatusText(status)
+		if at, ok := nextActiveTime(tm, now); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(at.Sub(now).Seconds()))))
+			msg = fmt.Sprintf("%s until %s", msg, at.UTC().Format(time.RFC3339))
+		}
+		if g.Rejected != nil {
+			sw := &statusWriter{ResponseWriter: w, status: status}
+			g.Rejected.ServeHTTP(sw, r)
+			if !sw.wroteHeader {
+				sw.WriteHeader(status)
+			}
+			return
+		}
+		http.Error(w, msg, status)
+	})
+}
+
+// statusWriter is an http.ResponseWriter that sends status with the response unless another is written first.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader implements the http.ResponseWriter interface for statusWriter.
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write implements the http.ResponseWriter interface for statusWriter.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(w.status)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// A ReadinessHandler is an HTTP readiness check that reports a service as ready only while its Matcher is active, so
+// that batch services can advertise availability in line with their operating hours. It responds with 200 OK while
+// ready and 503 Service Unavailable otherwise.
+type ReadinessHandler struct {
+	Matcher Matcher
+	// Lead is how long before the Matcher becomes active the service is reported ready, e.g. to warm up.
+	Lead time.Duration
+	// Grace is how long after the Matcher stops being active the service is still reported ready, e.g. to drain.
+	Grace time.Duration
+}
+
+// Ready returns true if the service should be reported ready at t.
+func (h ReadinessHandler) Ready(t time.Time) bool {
+	tm := asTransitionMatcher(h.Matcher)
+	if tm.ContainsTime(t) {
+		return true
+	}
+	// While the Matcher is inactive, the last transition was it stopping and the next is it starting.
+	if h.Grace > 0 {
+		if last, ok := tm.PreviousTransition(t); ok && t.Sub(last) < h.Grace {
+			return true
+		}
+	}
+	if h.Lead > 0 {
+		if next, ok := tm.NextTransition(t); ok && next.Sub(t) <= h.Lead {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeHTTP implements the http.Handler interface for ReadinessHandler.
+func (h ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	if h.Ready(time.Now()) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("ready\n"))
+		return
+	}
+	http.Error(w, "not ready", http.StatusServiceUnavailable)
+}
@@ -0,0 +1,106 @@
+package gotime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPGate(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	serve := func(h http.Handler) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		return rec
+	}
+
+	if rec := serve(Middleware(TimeInterval{})(ok)); rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Errorf("Expected requests to be served while the interval is active, got %d %s", rec.Code, rec.Body)
+	}
+
+	later := startingIn(90 * time.Minute)
+	rec := serve(Middleware(later)(ok))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 outside the interval, got %d", rec.Code)
+	}
+	if retry, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || retry < 5399 || retry > 5400 {
+		t.Errorf("Expected to be told to retry in 5400 seconds, got %q", rec.Header().Get("Retry-After"))
+	}
+	if want := later.Absolute[0].Start.UTC().Format(time.RFC3339); !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("Expected the response to say the service is back at %s, got %s", want, rec.Body)
+	}
+
+	never := TimeInterval{Weekdays: []WeekdayRange{}}
+	rejected := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	rec = serve(HTTPGate{Matcher: Not(TimeInterval{}), Rejected: rejected}.Wrap(ok))
+	if rec.Code != http.StatusTeapot || rec.Header().Get("Retry-After") != "" {
+		t.Errorf("Expected the rejected handler to respond without Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+	rec = serve(HTTPGate{Matcher: never, StatusCode: http.StatusForbidden}.Wrap(ok))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected the configured status, got %d", rec.Code)
+	}
+
+	// A rejected handler that doesn't write a status of its own responds with the configured one.
+	body := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("closed\n"))
+	})
+	for _, h := range []http.Handler{body, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})} {
+		rec = serve(HTTPGate{Matcher: never, StatusCode: http.StatusForbidden, Rejected: h}.Wrap(ok))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("Expected the configured status from the rejected handler, got %d", rec.Code)
+		}
+	}
+	rec = serve(HTTPGate{Matcher: never, StatusCode: http.StatusForbidden, Rejected: rejected}.Wrap(ok))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("Expected the status written by the rejected handler, got %d", rec.Code)
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	// Business hours on 2024-07-01, a Monday.
+	h := ReadinessHandler{
+		Matcher: TimeInterval{Times: []TimeRange{{StartMinute: 540, EndMinute: 1020}}},
+		Lead:    15 * time.Minute,
+		Grace:   5 * time.Minute,
+	}
+	for _, c := range []struct {
+		clock string
+		ready bool
+	}{
+		{"08:44:59", false},
+		{"08:45:00", true},
+		{"12:00:00", true},
+		{"17:04:59", true},
+		{"17:05:00", false},
+	} {
+		at, err := time.Parse(time.RFC3339, "2024-07-01T"+c.clock+"Z")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := h.Ready(at); got != c.ready {
+			t.Errorf("Expected the service to be ready at %s: %t, got %t", c.clock, c.ready, got)
+		}
+	}
+	for _, c := range []struct {
+		h    ReadinessHandler
+		code int
+	}{
+		{ReadinessHandler{Matcher: TimeInterval{}}, http.StatusOK},
+		{ReadinessHandler{Matcher: startingIn(time.Hour)}, http.StatusServiceUnavailable},
+		{ReadinessHandler{Matcher: startingIn(time.Minute), Lead: 2 * time.Minute}, http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		c.h.ServeHTTP(rec, httptest.NewRequest("GET", "/ready", nil))
+		if rec.Code != c.code {
+			t.Errorf("Expected status %d, got %d", c.code, rec.Code)
+		}
+	}
+}
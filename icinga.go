@@ -0,0 +1,296 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An IcingaTimePeriod is an Icinga 2 TimePeriod object using the legacy time period implementation, whose ranges map
+// days such as 'monday', 'day -1' or '2024-12-25' to the times of those days it includes, such as '09:00-17:00'. A
+// time is in the period if any of its ranges include it. String returns the object in the Icinga 2 DSL.
+type IcingaTimePeriod struct {
+	Name        string
+	DisplayName string
+	Ranges      map[string]string
+}
+
+// An IcingaScheduledDowntime is an Icinga 2 ScheduledDowntime object, which puts a host or, if ServiceName is set, one
+// of its services into downtime during each of its ranges. Ranges are written in the same way as in a TimePeriod.
+// String returns the object in the Icinga 2 DSL.
+type IcingaScheduledDowntime struct {
+	Name        string
+	HostName    string
+	ServiceName string
+	Author      string
+	Comment     string
+	Fixed       bool
+	Ranges      map[string]string
+}
+
+var icingaEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+
+// NewIcingaTimePeriod converts a set of intervals into an Icinga 2 TimePeriod with the given name, displayed with the
+// description of the first interval that has one.
+//
+// Icinga matches ranges in its own time zone, so an error is returned if the intervals are in different locations.
+// An error is also returned for intervals that can't be written as ranges, such as those with exceptions, times past
+// midnight or weekdays only in some months.
+func NewIcingaTimePeriod(name string, set IntervalSet) (IcingaTimePeriod, error) {
+	ranges, err := icingaRanges(set)
+	if err != nil {
+		return IcingaTimePeriod{}, err
+	}
+	tp := IcingaTimePeriod{Name: name, Ranges: ranges}
+	for _, ti := range set {
+		if ti.Description != "" {
+			tp.DisplayName = ti.Description
+			break
+		}
+	}
+	return tp, nil
+}
+
+// NewIcingaScheduledDowntime converts a set of intervals into a fixed Icinga 2 ScheduledDowntime with the given name,
+// in the same way as NewIcingaTimePeriod. The host and service it applies to, and the author and comment that Icinga
+// requires, are left for the caller to fill in.
+func NewIcingaScheduledDowntime(name string, set IntervalSet) (IcingaScheduledDowntime, error) {
+	ranges, err := icingaRanges(set)
+	if err != nil {
+		return IcingaScheduledDowntime{}, err
+	}
+	return IcingaScheduledDowntime{Name: name, Fixed: true, Ranges: ranges}, nil
+}
+
+// String implements the fmt.Stringer interface for IcingaTimePeriod.
+func (tp IcingaTimePeriod) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "object TimePeriod %s {\n", icingaString(tp.Name))
+	if tp.DisplayName != "" {
+		fmt.Fprintf(&b, "  display_name = %s\n", icingaString(tp.DisplayName))
+	}
+	writeIcingaRanges(&b, tp.Ranges)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// String implements the fmt.Stringer interface for IcingaScheduledDowntime.
+func (sd IcingaScheduledDowntime) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "object ScheduledDowntime %s {\n", icingaString(sd.Name))
+	fmt.Fprintf(&b, "  host_name = %s\n", icingaString(sd.HostName))
+	if sd.ServiceName != "" {
+		fmt.Fprintf(&b, "  service_name = %s\n", icingaString(sd.ServiceName))
+	}
+	fmt.Fprintf(&b, "  author = %s\n", icingaString(sd.Author))
+	fmt.Fprintf(&b, "  comment = %s\n", icingaString(sd.Comment))
+	fmt.Fprintf(&b, "  fixed = %t\n", sd.Fixed)
+	writeIcingaRanges(&b, sd.Ranges)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// writeIcingaRanges writes the ranges attribute of an object, in order of day.
+func writeIcingaRanges(b *strings.Builder, ranges map[string]string) {
+	days := make([]string, 0, len(ranges))
+	for day := range ranges {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	b.WriteString("  ranges = {\n")
+	for _, day := range days {
+		fmt.Fprintf(b, "    %s = %s\n", icingaString(day), icingaString(ranges[day]))
+	}
+	b.WriteString("  }\n")
+}
+
+// icingaString returns a string literal in the Icinga 2 DSL.
+func icingaString(s string) string {
+	return `"` + icingaEscaper.Replace(s) + `"`
+}
+
+// icingaRanges returns the ranges of an Icinga 2 time period or scheduled downtime that include the same times as the
+// set. Times of intervals that include the same days are listed together.
+func icingaRanges(set IntervalSet) (map[string]string, error) {
+	ranges := map[string]string{}
+	location := ""
+	for i, ti := range set {
+		if err := ti.icingaCompatible(); err != nil {
+			return nil, err
+		}
+		if i > 0 && ti.locationName() != location {
+			return nil, errors.New("Unable to express intervals in different locations in Icinga")
+		}
+		location = ti.locationName()
+		if ti.Times != nil && len(ti.Times) == 0 {
+			continue
+		}
+		times := []string{"00:00-24:00"}
+		if ti.Times != nil {
+			times = make([]string, len(ti.Times))
+			for j, tr := range ti.Times {
+				times[j] = formatTime(tr.startSecond()) + "-" + formatTime(tr.endSecond())
+			}
+		}
+		days, err := ti.icingaDays()
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			if existing, ok := ranges[day]; ok {
+				ranges[day] = existing + "," + strings.Join(times, ",")
+			} else {
+				ranges[day] = strings.Join(times, ",")
+			}
+		}
+	}
+	return ranges, nil
+}
+
+// icingaCompatible returns an error if the interval uses fields that the ranges of an Icinga 2 time period can't
+// express.
+func (tp TimeInterval) icingaCompatible() error {
+	for _, f := range []struct {
+		key   string
+		unset bool
+	}{
+		{"mode", tp.Mode == ModeAllow},
+		{"weeks", tp.Weeks == nil},
+		{"quarters", tp.Quarters == nil},
+		{"years", tp.Years == nil},
+		{"relative days", tp.Relative == nil},
+		{"absolute times", tp.Absolute == nil},
+		{"fiscal year", tp.FiscalYearStart == 0},
+		{"calendar", tp.Calendar == nil},
+		{"holidays", tp.Holidays == nil},
+		{"expression", tp.Expression == nil},
+		{"coordinates", tp.Coordinates == nil},
+		{"dst", tp.DST == DSTPolicy{}},
+		{"except", tp.Except == nil},
+	} {
+		if !f.unset {
+			return fmt.Errorf("Unable to express the %s of an interval in Icinga", f.key)
+		}
+	}
+	for _, tr := range tp.Times {
+		switch {
+		case tr.StartSolar != nil || tr.EndSolar != nil:
+			return errors.New("Unable to express solar times in Icinga")
+		case tr.isOvernight():
+			return errors.New("Unable to express times past midnight in Icinga")
+		}
+	}
+	return nil
+}
+
+// icingaDays returns the days of the ranges that together include the days the interval matches.
+func (tp TimeInterval) icingaDays() ([]string, error) {
+	monthDays := tp.DaysOfMonth != nil || tp.WeekdaysOfMonth != nil
+	switch {
+	case tp.Dates != nil || tp.Cycle != nil:
+		if tp.Weekdays != nil || tp.Months != nil || monthDays {
+			return nil, errors.New("Unable to express dates or cycles combined with other days in Icinga")
+		}
+		return tp.icingaDates()
+	case tp.Weekdays != nil:
+		if tp.Months != nil || monthDays {
+			return nil, errors.New("Unable to express weekdays combined with other days in Icinga")
+		}
+		var days []string
+		for _, r := range tp.Weekdays {
+			for day := r.Begin; day <= r.End; day++ {
+				days = append(days, daysOfWeekInv[day%7])
+			}
+		}
+		return days, nil
+	case tp.Months == nil && !monthDays:
+		days := make([]string, 7)
+		for day := range days {
+			days[day] = daysOfWeekInv[day]
+		}
+		return days, nil
+	}
+	// Days of the month are written after the month they are in, or after 'day' if they are in every month, and
+	// weekdays of the month are written before the month they are in.
+	var monthNames []string
+	for _, r := range tp.Months {
+		for month := r.Begin; ; month = month%12 + 1 {
+			monthNames = append(monthNames, monthsInv[month])
+			if month == r.End {
+				break
+			}
+		}
+	}
+	var days []string
+	if !monthDays {
+		for _, month := range monthNames {
+			days = append(days, month+" 1 - -1")
+		}
+		return days, nil
+	}
+	for _, w := range tp.WeekdaysOfMonth {
+		if w.AnyWeekday {
+			return nil, errors.New("Unable to express occurrences of any weekday in Icinga")
+		}
+		day := fmt.Sprintf("%s %d", daysOfWeekInv[int(w.Weekday)], w.N)
+		if monthNames == nil {
+			days = append(days, day)
+		}
+		for _, month := range monthNames {
+			days = append(days, day+" "+month)
+		}
+	}
+	prefixes := monthNames
+	if prefixes == nil {
+		prefixes = []string{"day"}
+	}
+	for _, r := range tp.DaysOfMonth {
+		day := fmt.Sprintf("%d", r.Begin)
+		if r.End != r.Begin {
+			day = fmt.Sprintf("%d - %d", r.Begin, r.End)
+		}
+		for _, prefix := range prefixes {
+			days = append(days, prefix+" "+day)
+		}
+	}
+	return days, nil
+}
+
+// icingaDates returns the days of ranges including the interval's dates, stepping through them if it has a cycle.
+func (tp TimeInterval) icingaDates() ([]string, error) {
+	step := ""
+	if tp.Cycle != nil {
+		if tp.Cycle.Unit != CycleDays {
+			return nil, errors.New("Unable to express cycles of weeks in Icinga")
+		}
+		if every := tp.Cycle.Every; every > 1 {
+			step = fmt.Sprintf(" / %d", every)
+		}
+		if tp.Dates == nil {
+			return []string{tp.Cycle.Anchor.Format(DateLayout) + step}, nil
+		}
+	}
+	var days []string
+	for _, r := range tp.Dates {
+		begin, end := r.Begin, r.End
+		if tp.Cycle != nil {
+			// Icinga steps through days from the start of the range, so start from the first day the cycle matches.
+			anchor := tp.Cycle.Anchor
+			if gap := rataDie(anchor) - rataDie(begin); gap > 0 {
+				begin = anchor
+			} else {
+				begin = begin.AddDate(0, 0, floorMod(gap, tp.Cycle.Every))
+			}
+			if rataDie(begin) > rataDie(end) {
+				continue
+			}
+		}
+		day := begin.Format(DateLayout)
+		if rataDie(end) != rataDie(begin) {
+			day += " - " + end.Format(DateLayout)
+		}
+		days = append(days, day+step)
+	}
+	return days, nil
+}
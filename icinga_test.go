@@ -0,0 +1,124 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewIcingaTimePeriod(t *testing.T) {
+	set := IntervalSet{
+		{
+			Description: "Business \"hours\"",
+			Times:       []TimeRange{{StartMinute: 540, EndMinute: 720}, {StartMinute: 780, EndMinute: 1020}},
+			Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+		{
+			Times:    []TimeRange{{StartMinute: 1080, EndMinute: 1200}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 7}}},
+		},
+		{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -1, End: -1}}}},
+		{
+			Times:       []TimeRange{{StartMinute: 0, StartSecond: 30, EndMinute: 60}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 12, End: 1}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 7}}},
+		},
+		{Months: []MonthRange{{InclusiveRange{Begin: 7, End: 7}}}},
+		{WeekdaysOfMonth: []WeekdayOfMonth{{N: -1, Weekday: time.Friday}}},
+		{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 4, Weekday: time.Thursday}},
+			Months:          []MonthRange{{InclusiveRange{Begin: 11, End: 11}}},
+		},
+		{Dates: []DateRange{{Begin: date(2024, time.December, 24), End: date(2025, time.January, 2)}}},
+		{
+			Dates: []DateRange{{Begin: date(2024, time.January, 1), End: date(2024, time.March, 31)}},
+			Cycle: &Cycle{Anchor: date(2023, time.December, 30), Every: 3, Unit: CycleDays},
+		},
+		{Cycle: &Cycle{Anchor: date(2024, time.June, 1), Every: 14, Unit: CycleDays}},
+		{Times: []TimeRange{}},
+	}
+	tp, err := NewIcingaTimePeriod("business-hours", set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `object TimePeriod "business-hours" {
+  display_name = "Business \"hours\""
+  ranges = {
+    "2024-01-02 - 2024-03-31 / 3" = "00:00-24:00"
+    "2024-06-01 / 14" = "00:00-24:00"
+    "2024-12-24 - 2025-01-02" = "00:00-24:00"
+    "day -1" = "00:00-24:00"
+    "december 1 - 7" = "00:00:30-01:00"
+    "friday" = "09:00-12:00,13:00-17:00,18:00-20:00"
+    "friday -1" = "00:00-24:00"
+    "january 1 - 7" = "00:00:30-01:00"
+    "july 1 - -1" = "00:00-24:00"
+    "monday" = "09:00-12:00,13:00-17:00"
+    "saturday" = "18:00-20:00"
+    "sunday" = "18:00-20:00"
+    "thursday" = "09:00-12:00,13:00-17:00"
+    "thursday 4 november" = "00:00-24:00"
+    "tuesday" = "09:00-12:00,13:00-17:00"
+    "wednesday" = "09:00-12:00,13:00-17:00"
+  }
+}
+`
+	if got := tp.String(); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestNewIcingaScheduledDowntime(t *testing.T) {
+	sd, err := NewIcingaScheduledDowntime("backups", IntervalSet{{
+		Times:    []TimeRange{{StartMinute: 120, EndMinute: 240}},
+		Location: mustLoadLocation("Europe/Berlin"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd.HostName, sd.ServiceName, sd.Author, sd.Comment = "db1", "backup", "ops", "Nightly backups"
+	want := `object ScheduledDowntime "backups" {
+  host_name = "db1"
+  service_name = "backup"
+  author = "ops"
+  comment = "Nightly backups"
+  fixed = true
+  ranges = {
+    "friday" = "02:00-04:00"
+    "monday" = "02:00-04:00"
+    "saturday" = "02:00-04:00"
+    "sunday" = "02:00-04:00"
+    "thursday" = "02:00-04:00"
+    "tuesday" = "02:00-04:00"
+    "wednesday" = "02:00-04:00"
+  }
+}
+`
+	if got := sd.String(); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestNewIcingaTimePeriodErrors(t *testing.T) {
+	for _, set := range []IntervalSet{
+		{{Except: []TimeInterval{{}}}},
+		{{Mode: ModeDeny}},
+		{{Years: []YearRange{{InclusiveRange{Begin: 2024, End: 2024}}}}},
+		{{Times: []TimeRange{{StartMinute: 1320, EndMinute: 360}}}},
+		{{Times: []TimeRange{{StartSolar: &SolarTime{Event: Sunrise}, EndMinute: 720}}}},
+		{{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}},
+			Months:   []MonthRange{{InclusiveRange{Begin: 1, End: 1}}},
+		}},
+		{{
+			Dates:    []DateRange{{Begin: date(2024, time.January, 1), End: date(2024, time.January, 1)}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}},
+		}},
+		{{Cycle: &Cycle{Anchor: date(2024, time.January, 1), Every: 2, Unit: CycleWeeks}}},
+		{{WeekdaysOfMonth: []WeekdayOfMonth{{N: 1, AnyWeekday: true}}}},
+		{{Location: mustLoadLocation("Europe/Berlin")}, {}},
+	} {
+		if tp, err := NewIcingaTimePeriod("a", set); err == nil {
+			t.Errorf("Expected an error converting %v, got %v", set, tp)
+		}
+	}
+}
@@ -0,0 +1,612 @@
+package gotime
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICSProductID identifies gotime as the product that created an iCalendar file.
+const ICSProductID = "-//benridley//gotime//EN"
+
+// icsMaxLineLength is the length in octets beyond which lines of an iCalendar file are folded.
+const icsMaxLineLength = 75
+
+const (
+	icsDateLayout     = "20060102"
+	icsDateTimeLayout = "20060102T150405"
+)
+
+var icsWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// An icsEvent is a VEVENT of an iCalendar file, holding its properties other than UID and DTSTAMP.
+type icsEvent struct {
+	summary     string
+	description string
+	properties  []string
+}
+
+// ICS returns an iCalendar file with an event for each period the interval is active between from and to, so that
+// its windows can be subscribed to in a calendar client. Where possible, each of the interval's time ranges becomes
+// a single event that recurs with an RRULE until to. Intervals with fields that recurrence rules can't express, such
+// as dates, cycles or exceptions, have one event per window instead. Events are named after the interval and stamped
+// with from, so that the file only changes when the interval or the horizon does.
+//
+// Recurring events of an interval with a Location use its time zone name as their TZID, without a VTIMEZONE, which
+// most calendar clients accept. Those of an interval without one use floating times, which are matched in the
+// calendar client's time zone as the interval would be.
+func (tp TimeInterval) ICS(from, to time.Time) ([]byte, error) {
+	if !from.Before(to) {
+		return nil, errors.New("The start of an iCalendar horizon must be before its end")
+	}
+	return icsCalendar(tp.icsEvents(from, to), from), nil
+}
+
+// ICS returns an iCalendar file with events for each of the set's intervals, as TimeInterval's ICS does. Sets with
+// intervals that deny times have one event per window of the whole set instead.
+func (is IntervalSet) ICS(from, to time.Time) ([]byte, error) {
+	if !from.Before(to) {
+		return nil, errors.New("The start of an iCalendar horizon must be before its end")
+	}
+	var events []icsEvent
+	for _, ti := range is {
+		if ti.Mode != ModeAllow {
+			return icsCalendar(icsWindowEvents(is.Windows(from, to), "", ""), from), nil
+		}
+	}
+	for _, ti := range is {
+		events = append(events, ti.icsEvents(from, to)...)
+	}
+	return icsCalendar(events, from), nil
+}
+
+// icsEvents returns the events for each period the interval is active between from and to, recurring if possible.
+func (tp TimeInterval) icsEvents(from, to time.Time) []icsEvent {
+	rules, ok := tp.icsRules()
+	if !ok {
+		return icsWindowEvents(tp.Windows(from, to), tp.Name, tp.Description)
+	}
+	loc := from.Location()
+	if tp.Location != nil && tp.Location.Location != nil {
+		loc = tp.Location.Location
+	}
+	var events []icsEvent
+	for _, r := range rules {
+		start, ok := r.first(from.In(loc), to)
+		if !ok {
+			continue
+		}
+		e := icsEvent{summary: tp.Name, description: tp.Description}
+		if r.times == nil {
+			until := to.Add(-time.Second).In(loc).Format(icsDateLayout)
+			e.properties = []string{
+				"DTSTART;VALUE=DATE:" + start.Format(icsDateLayout),
+				"DTEND;VALUE=DATE:" + start.AddDate(0, 0, 1).Format(icsDateLayout),
+				r.rrule + ";UNTIL=" + until,
+			}
+		} else {
+			end := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, r.times.endSecond(), 0, loc)
+			if r.times.isOvernight() {
+				end = end.AddDate(0, 0, 1)
+			}
+			e.properties = []string{
+				"DTSTART" + icsDateTime(start, tp.Location),
+				"DTEND" + icsDateTime(end, tp.Location),
+				r.rrule + ";UNTIL=" + icsUntil(to, loc, tp.Location),
+			}
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// An icsRule is a recurrence rule for one of an interval's time ranges, or for the whole day if times is nil. days
+// holds the day-level fields of the interval that the rule matches.
+type icsRule struct {
+	rrule string
+	times *TimeRange
+	days  TimeInterval
+}
+
+// first returns the start of the rule's first occurrence between from, in the interval's location, and to.
+func (r icsRule) first(from, to time.Time) (time.Time, bool) {
+	second := 0
+	if r.times != nil {
+		second = r.times.startSecond()
+	}
+	for day := from.AddDate(0, 0, -1); day.Before(to); day = day.AddDate(0, 0, 1) {
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, second, 0, from.Location())
+		if !start.Before(from) && start.Before(to) && r.days.containsDay(start) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// icsRules returns a recurrence rule for each of the interval's time ranges and each set of days that can be written
+// as one rule, or false if the interval can't be written with recurrence rules.
+func (tp TimeInterval) icsRules() ([]icsRule, bool) {
+	if tp.Weeks != nil || tp.Quarters != nil || tp.Years != nil || tp.Relative != nil || tp.Dates != nil ||
+		tp.Absolute != nil || tp.Cycle != nil || tp.Calendar != nil || tp.Holidays != nil || tp.Except != nil ||
+		tp.Expression != nil || tp.DST != (DSTPolicy{}) || tp.hasSolarTimes() || tp.IsEmpty() {
+		return nil, false
+	}
+	byMonth := ""
+	if tp.Months != nil {
+		months := make([]bool, 12)
+		for _, r := range tp.Months {
+			for month := r.Begin; month <= r.End; month++ {
+				months[month-1] = true
+			}
+		}
+		byMonth = ";BYMONTH=" + icsList(months, 1)
+	}
+	weekdays := make([]bool, 7)
+	for wd := range weekdays {
+		weekdays[wd] = tp.Weekdays == nil
+	}
+	for _, r := range tp.Weekdays {
+		for wd := r.Begin; wd <= r.End; wd++ {
+			weekdays[wd] = true
+		}
+	}
+	byDay := ""
+	if tp.Weekdays != nil {
+		var days []string
+		for wd, ok := range weekdays {
+			if ok {
+				days = append(days, icsWeekdays[wd])
+			}
+		}
+		byDay = ";BYDAY=" + strings.Join(days, ",")
+	}
+	base := TimeInterval{Weekdays: tp.Weekdays, Months: tp.Months}
+	var days []icsRule
+	if tp.DaysOfMonth != nil || tp.WeekdaysOfMonth == nil {
+		// A daily rule's BYMONTHDAY, BYDAY and BYMONTH each limit the days it matches, as the interval's fields do.
+		rule := icsRule{rrule: "RRULE:FREQ=DAILY" + byMonth + byDay, days: base}
+		if tp.DaysOfMonth != nil {
+			var values []string
+			for _, r := range tp.DaysOfMonth {
+				if (r.Begin < 0) != (r.End < 0) {
+					return nil, false
+				}
+				for day := r.Begin; day <= r.End; day++ {
+					values = append(values, strconv.Itoa(day))
+				}
+			}
+			rule.rrule += ";BYMONTHDAY=" + strings.Join(values, ",")
+			rule.days.DaysOfMonth = tp.DaysOfMonth
+		}
+		days = append(days, rule)
+	}
+	for _, w := range tp.WeekdaysOfMonth {
+		if w.AnyWeekday {
+			return nil, false
+		}
+		if !weekdays[w.Weekday] {
+			continue
+		}
+		rule := icsRule{
+			rrule: fmt.Sprintf("RRULE:FREQ=MONTHLY%s;BYDAY=%d%s", byMonth, w.N, icsWeekdays[w.Weekday]),
+			days:  base,
+		}
+		rule.days.WeekdaysOfMonth = []WeekdayOfMonth{w}
+		days = append(days, rule)
+	}
+	if tp.Times == nil {
+		return days, true
+	}
+	var rules []icsRule
+	for i := range tp.Times {
+		for _, r := range days {
+			r.times = &tp.Times[i]
+			rules = append(rules, r)
+		}
+	}
+	return rules, true
+}
+
+// icsWindowEvents returns an event for each of the windows.
+func icsWindowEvents(windows []Window, summary, description string) []icsEvent {
+	events := make([]icsEvent, len(windows))
+	for i, w := range windows {
+		events[i] = icsEvent{summary: summary, description: description, properties: []string{
+			"DTSTART:" + w.Start.UTC().Format(icsDateTimeLayout) + "Z",
+			"DTEND:" + w.End.UTC().Format(icsDateTimeLayout) + "Z",
+		}}
+	}
+	return events
+}
+
+// icsCalendar writes the events as an iCalendar file, stamped with the given time.
+func icsCalendar(events []icsEvent, stamp time.Time) []byte {
+	var buf bytes.Buffer
+	write := func(line string) {
+		buf.WriteString(foldICSLine(line))
+		buf.WriteString("\r\n")
+	}
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:" + ICSProductID)
+	for _, e := range events {
+		summary := e.summary
+		if summary == "" {
+			summary = "Active"
+		}
+		// The UID only depends on the content of the event, so that it is stable across exports.
+		uid := sha1.Sum([]byte(summary + "\n" + strings.Join(e.properties, "\n")))
+		write("BEGIN:VEVENT")
+		write(fmt.Sprintf("UID:%x@gotime", uid))
+		write("DTSTAMP:" + stamp.UTC().Format(icsDateTimeLayout) + "Z")
+		for _, p := range e.properties {
+			write(p)
+		}
+		write("SUMMARY:" + escapeICSText(summary))
+		if e.description != "" {
+			write("DESCRIPTION:" + escapeICSText(e.description))
+		}
+		write("END:VEVENT")
+	}
+	write("END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// icsDateTime writes t as the value of a date-time property, including the parameters and colon that come before it.
+// Times are written in UTC or with a TZID if the interval has a location, and as floating times if it doesn't.
+func icsDateTime(t time.Time, loc *Location) string {
+	switch {
+	case loc == nil || loc.Location == nil:
+		return ":" + t.Format(icsDateTimeLayout)
+	case loc.Location == time.UTC:
+		return ":" + t.UTC().Format(icsDateTimeLayout) + "Z"
+	}
+	return ";TZID=" + loc.String() + ":" + t.Format(icsDateTimeLayout)
+}
+
+// icsUntil returns the UNTIL of a recurrence rule that ends before to, which must be in UTC unless the rule's start
+// is a floating time.
+func icsUntil(to time.Time, wall *time.Location, loc *Location) string {
+	last := to.Add(-time.Second)
+	if loc == nil || loc.Location == nil {
+		return last.In(wall).Format(icsDateTimeLayout)
+	}
+	return last.UTC().Format(icsDateTimeLayout) + "Z"
+}
+
+// icsList writes the values in set, where the first value is offset, as a list in a recurrence rule.
+func icsList(set []bool, offset int) string {
+	var values []string
+	for i, ok := range set {
+		if ok {
+			values = append(values, strconv.Itoa(i+offset))
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// escapeICSText escapes the characters that have a meaning in the text values of iCalendar properties.
+func escapeICSText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// foldICSLine splits a line longer than icsMaxLineLength octets into several, each after the first starting with a
+// space, without splitting UTF-8 characters.
+func foldICSLine(line string) string {
+	var b strings.Builder
+	length := 0
+	for _, r := range line {
+		n := len(string(r))
+		if length+n > icsMaxLineLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += n
+	}
+	return b.String()
+}
+
+// An icsProperty is a content line of an iCalendar file, such as 'DTSTART;TZID=Europe/London:20240101T090000'.
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// FromICS reads the events of an iCalendar file, such as an export of a shared change freeze calendar, into an
+// IntervalSet with an interval for each event. A single event becomes an interval with one absolute range, while a
+// recurring one is converted with FromRRule, so its RRULE must be one that FromRRule understands. Dates excluded
+// with EXDATE become exceptions, dates added with RDATE become absolute ranges of their own, and changes to single
+// occurrences of a recurring event replace the occurrence they change. Cancelled events and events without a length
+// are left out, and each interval is named after its event's SUMMARY.
+//
+// Times with a TZID are read in that IANA time zone, and floating times and dates in loc, or in UTC if loc is nil.
+// Time zones are only looked up by name, so VTIMEZONE components are ignored.
+func FromICS(data []byte, loc *time.Location) (IntervalSet, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	props, err := parseICSLines(data)
+	if err != nil {
+		return nil, err
+	}
+	var events [][]icsProperty
+	var stack []string
+	for _, p := range props {
+		switch p.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(p.value))
+			if len(stack) == 1 && stack[0] != "VCALENDAR" {
+				return nil, fmt.Errorf("Couldn't parse iCalendar file, expected BEGIN:VCALENDAR, got BEGIN:%s", p.value)
+			}
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				events = append(events, nil)
+			}
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(p.value) {
+				return nil, fmt.Errorf("Couldn't parse iCalendar file, unexpected END:%s", p.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				events[len(events)-1] = append(events[len(events)-1], p)
+			}
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("Couldn't parse iCalendar file, missing END:%s", stack[len(stack)-1])
+	}
+	var set IntervalSet
+	// masters holds the indices in set of the intervals for each recurring event, by UID.
+	masters := map[string][]int{}
+	durations := map[string]time.Duration{}
+	var overrides [][]icsProperty
+	for _, e := range events {
+		if icsFirst(e, "RECURRENCE-ID") != nil {
+			overrides = append(overrides, e)
+			continue
+		}
+		if status := icsFirst(e, "STATUS"); status != nil && strings.EqualFold(status.value, "CANCELLED") {
+			continue
+		}
+		start, duration, err := icsEventTimes(e, loc)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			continue
+		}
+		base := icsDescribed(e)
+		ti := base
+		ti.Absolute = []AbsoluteRange{{Start: start, End: start.Add(duration)}}
+		if rule := icsFirst(e, "RRULE"); rule != nil {
+			if ti, err = FromRRule(rule.value, start, duration); err != nil {
+				return nil, err
+			}
+			ti.Name, ti.Description = base.Name, base.Description
+			for _, p := range icsAll(e, "EXDATE") {
+				times, err := icsTimes(p, loc)
+				if err != nil {
+					return nil, err
+				}
+				for _, t := range times {
+					ti.Except = append(ti.Except, TimeInterval{Absolute: []AbsoluteRange{{Start: t, End: t.Add(duration)}}})
+				}
+			}
+			if uid := icsFirst(e, "UID"); uid != nil {
+				masters[uid.value] = append(masters[uid.value], len(set))
+				durations[uid.value] = duration
+			}
+		}
+		set = append(set, ti)
+		for _, p := range icsAll(e, "RDATE") {
+			if strings.EqualFold(p.params["VALUE"], "PERIOD") {
+				return nil, errors.New("RDATE periods are not supported")
+			}
+			times, err := icsTimes(p, loc)
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range times {
+				extra := base
+				extra.Absolute = []AbsoluteRange{{Start: t, End: t.Add(duration)}}
+				set = append(set, extra)
+			}
+		}
+	}
+	for _, e := range overrides {
+		uid := icsFirst(e, "UID")
+		if uid == nil {
+			continue
+		}
+		// The occurrence that was changed no longer happens when it would have.
+		replaced, err := icsTimes(*icsFirst(e, "RECURRENCE-ID"), loc)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range masters[uid.value] {
+			for _, t := range replaced {
+				except := TimeInterval{Absolute: []AbsoluteRange{{Start: t, End: t.Add(durations[uid.value])}}}
+				set[i].Except = append(set[i].Except, except)
+			}
+		}
+		if status := icsFirst(e, "STATUS"); status != nil && strings.EqualFold(status.value, "CANCELLED") {
+			continue
+		}
+		start, duration, err := icsEventTimes(e, loc)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			continue
+		}
+		ti := icsDescribed(e)
+		ti.Absolute = []AbsoluteRange{{Start: start, End: start.Add(duration)}}
+		set = append(set, ti)
+	}
+	return set, nil
+}
+
+// icsDescribed returns an interval named and described by an event's SUMMARY and DESCRIPTION.
+func icsDescribed(event []icsProperty) TimeInterval {
+	var ti TimeInterval
+	if p := icsFirst(event, "SUMMARY"); p != nil {
+		ti.Name = unescapeICSText(p.value)
+	}
+	if p := icsFirst(event, "DESCRIPTION"); p != nil {
+		ti.Description = unescapeICSText(p.value)
+	}
+	return ti
+}
+
+// icsEventTimes returns the start and length of an event. Events without an end or duration last for a day if they
+// start on a date, and have no length otherwise.
+func icsEventTimes(event []icsProperty, loc *time.Location) (time.Time, time.Duration, error) {
+	p := icsFirst(event, "DTSTART")
+	if p == nil {
+		return time.Time{}, 0, errors.New("Couldn't parse iCalendar event, missing DTSTART")
+	}
+	starts, err := icsTimes(*p, loc)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	start := starts[0]
+	if end := icsFirst(event, "DTEND"); end != nil {
+		ends, err := icsTimes(*end, loc)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+		return start, ends[0].Sub(start), nil
+	}
+	if d := icsFirst(event, "DURATION"); d != nil {
+		duration, err := parseICSDuration(d.value)
+		return start, duration, err
+	}
+	if isICSDate(*p) {
+		return start, 24 * time.Hour, nil
+	}
+	return start, 0, nil
+}
+
+// icsTimes reads the date or date-time values of a property.
+func icsTimes(p icsProperty, loc *time.Location) ([]time.Time, error) {
+	if tzid, ok := p.params["TZID"]; ok {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return nil, fmt.Errorf("%s is not a supported time zone", tzid)
+		}
+	}
+	var times []time.Time
+	for _, v := range strings.Split(p.value, ",") {
+		var t time.Time
+		var err error
+		switch {
+		case isICSDate(p) || len(v) == len(icsDateLayout):
+			t, err = time.ParseInLocation(icsDateLayout, v, loc)
+		case strings.HasSuffix(v, "Z"):
+			t, err = time.Parse(icsDateTimeLayout+"Z", v)
+		default:
+			t, err = time.ParseInLocation(icsDateTimeLayout, v, loc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid iCalendar %s", v, p.name)
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// isICSDate returns true if the value of a property is a date rather than a date-time.
+func isICSDate(p icsProperty) bool {
+	return strings.EqualFold(p.params["VALUE"], "DATE") || len(p.value) == len(icsDateLayout)
+}
+
+var icsDurationRE = regexp.MustCompile(`^([+-])?P(?:([0-9]+)W|(?:([0-9]+)D)?` +
+	`(?:T(?:([0-9]+)H)?(?:([0-9]+)M)?(?:([0-9]+)S)?)?)$`)
+
+// parseICSDuration reads an iCalendar duration such as 'PT1H30M' or 'P1D'. Days are taken to be 24 hours long.
+func parseICSDuration(str string) (time.Duration, error) {
+	m := icsDurationRE.FindStringSubmatch(str)
+	if m == nil || str == "P" || strings.HasSuffix(str, "T") {
+		return 0, fmt.Errorf("%s is not a valid iCalendar DURATION", str)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseICSLines unfolds the lines of an iCalendar file and splits each into its name, parameters and value.
+func parseICSLines(data []byte) ([]icsProperty, error) {
+	text := strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(string(data))
+	var props []icsProperty
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		// The value starts after the first colon that isn't in a quoted parameter value.
+		colon, quoted := -1, false
+		for i, c := range line {
+			if c == '"' {
+				quoted = !quoted
+			} else if c == ':' && !quoted {
+				colon = i
+				break
+			}
+		}
+		if colon < 0 {
+			return nil, fmt.Errorf("Couldn't parse iCalendar line %s, invalid format", line)
+		}
+		parts := strings.Split(line[:colon], ";")
+		p := icsProperty{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[colon+1:]}
+		for _, param := range parts[1:] {
+			kv := strings.SplitN(param, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("Couldn't parse iCalendar parameter %s, invalid format", param)
+			}
+			p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+		props = append(props, p)
+	}
+	return props, nil
+}
+
+// icsFirst returns the first of an event's properties with the given name, or nil if it has none.
+func icsFirst(event []icsProperty, name string) *icsProperty {
+	for i := range event {
+		if event[i].name == name {
+			return &event[i]
+		}
+	}
+	return nil
+}
+
+// icsAll returns each of an event's properties with the given name.
+func icsAll(event []icsProperty, name string) []icsProperty {
+	var props []icsProperty
+	for _, p := range event {
+		if p.name == name {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// unescapeICSText reverses escapeICSText.
+func unescapeICSText(text string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(text)
+}
@@ -0,0 +1,329 @@
+package gotime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var icsTestCases = []struct {
+	interval TimeInterval
+	from     string
+	to       string
+	// events holds the lines of each expected event other than UID and DTSTAMP.
+	events [][]string
+}{
+	{
+		interval: TimeInterval{
+			Name:     "Business hours, UK",
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Location: mustLoadLocation("Europe/London"),
+		},
+		from: "2024-01-01T12:00:00Z",
+		to:   "2024-07-01T00:00:00Z",
+		events: [][]string{{
+			"DTSTART;TZID=Europe/London:20240102T090000",
+			"DTEND;TZID=Europe/London:20240102T170000",
+			"RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20240630T235959Z",
+			`SUMMARY:Business hours\, UK`,
+		}},
+	},
+	{
+		interval: TimeInterval{
+			Description:     "Patching",
+			Times:           []TimeRange{{StartMinute: 1320, EndMinute: 120}},
+			DaysOfMonth:     []DayOfMonthRange{{InclusiveRange{Begin: -1, End: -1}}},
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 2, Weekday: time.Tuesday}},
+			Months:          []MonthRange{{InclusiveRange{Begin: 1, End: 2}}},
+		},
+		from: "2024-01-01T00:00:00Z",
+		to:   "2025-01-01T00:00:00Z",
+		events: [][]string{
+			{
+				"DTSTART:20240131T220000",
+				"DTEND:20240201T020000",
+				"RRULE:FREQ=DAILY;BYMONTH=1,2;BYMONTHDAY=-1;UNTIL=20241231T235959",
+				"SUMMARY:Active",
+				"DESCRIPTION:Patching",
+			},
+			{
+				"DTSTART:20240109T220000",
+				"DTEND:20240110T020000",
+				"RRULE:FREQ=MONTHLY;BYMONTH=1,2;BYDAY=2TU;UNTIL=20241231T235959",
+				"SUMMARY:Active",
+				"DESCRIPTION:Patching",
+			},
+		},
+	},
+	{
+		interval: TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}}},
+		from:     "2024-01-01T00:00:00Z",
+		to:       "2024-02-01T00:00:00Z",
+		events: [][]string{{
+			"DTSTART;VALUE=DATE:20240106",
+			"DTEND;VALUE=DATE:20240107",
+			"RRULE:FREQ=DAILY;BYDAY=SA;UNTIL=20240131",
+			"SUMMARY:Active",
+		}},
+	},
+	{
+		// Dates can't be written as a recurrence rule, so each window is its own event.
+		interval: TimeInterval{
+			Name:  "Freeze",
+			Times: []TimeRange{{StartMinute: 0, EndMinute: 60}},
+			Dates: []DateRange{{
+				Begin: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		from: "2024-01-01T00:00:00Z",
+		to:   "2024-02-01T00:00:00Z",
+		events: [][]string{
+			{"DTSTART:20240104T000000Z", "DTEND:20240104T010000Z", "SUMMARY:Freeze"},
+			{"DTSTART:20240105T000000Z", "DTEND:20240105T010000Z", "SUMMARY:Freeze"},
+		},
+	},
+}
+
+func TestICS(t *testing.T) {
+	for _, tc := range icsTestCases {
+		from, _ := time.Parse(time.RFC3339, tc.from)
+		to, _ := time.Parse(time.RFC3339, tc.to)
+		out, err := tc.interval.ICS(from, to)
+		if err != nil {
+			t.Errorf("Unexpected error exporting %v: %v", tc.interval, err)
+			continue
+		}
+		events := icsTestEvents(t, string(out))
+		if len(events) != len(tc.events) {
+			t.Errorf("Exporting %v gave %d events, expected %d:\n%s", tc.interval, len(events), len(tc.events), out)
+			continue
+		}
+		for i, e := range events {
+			if strings.Join(e, "\n") != strings.Join(tc.events[i], "\n") {
+				t.Errorf("Exporting %v gave event\n%s\nexpected\n%s", tc.interval, strings.Join(e, "\n"),
+					strings.Join(tc.events[i], "\n"))
+			}
+		}
+	}
+}
+
+func TestIntervalSetICS(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	set := IntervalSet{
+		{Times: []TimeRange{{StartMinute: 540, EndMinute: 1020}}},
+		{Mode: ModeDeny, Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}}},
+	}
+	out, err := set.ICS(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Monday to Friday are left once the weekend is denied.
+	events := icsTestEvents(t, string(out))
+	if len(events) != 5 || events[0][0] != "DTSTART:20240101T090000Z" || events[4][1] != "DTEND:20240105T170000Z" {
+		t.Errorf("Unexpected events exporting %v:\n%s", set, out)
+	}
+	set[1].Mode = ModeAllow
+	out, err = set.ICS(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events := icsTestEvents(t, string(out)); len(events) != 2 {
+		t.Errorf("Expected an event for each interval exporting %v:\n%s", set, out)
+	}
+	if _, err := set.ICS(to, from); err == nil {
+		t.Errorf("Expected an error exporting a horizon that ends before it starts")
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("é", 60)
+	folded := foldICSLine(line)
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > icsMaxLineLength {
+			t.Errorf("%q is longer than %d octets", part, icsMaxLineLength)
+		}
+	}
+	if strings.Replace(folded, "\r\n ", "", -1) != line {
+		t.Errorf("Unfolding %q didn't give back %q", folded, line)
+	}
+}
+
+// icsTestEvents returns the unfolded lines of each event in an iCalendar file, other than its UID and DTSTAMP.
+func icsTestEvents(t *testing.T, ics string) [][]string {
+	if !strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(ics, "END:VCALENDAR\r\n") {
+		t.Errorf("%q isn't an iCalendar file", ics)
+	}
+	var events [][]string
+	var cur []string
+	for _, line := range strings.Split(strings.Replace(ics, "\r\n ", "", -1), "\r\n") {
+		switch {
+		case line == "BEGIN:VEVENT":
+			cur = []string{}
+		case line == "END:VEVENT":
+			events = append(events, cur)
+			cur = nil
+		case cur != nil && !strings.HasPrefix(line, "UID:") && !strings.HasPrefix(line, "DTSTAMP:"):
+			cur = append(cur, line)
+		}
+	}
+	return events
+}
+
+const icsTestFile = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Calendar//EN\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/London\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:freeze@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20241220\r\n" +
+	"DTEND;VALUE=DATE:20250103\r\n" +
+	"SUMMARY:Change freeze\\, year end\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:patching@example.com\r\n" +
+	"DTSTART;TZID=Europe/London:20240109T220000\r\n" +
+	"DURATION:PT4H\r\n" +
+	"RRULE:FREQ=MONTHLY;BYDAY=2TU\r\n" +
+	"EXDATE;TZID=Europe/London:20240213T220000\r\n" +
+	"SUMMARY:Patch\r\n" +
+	"  Tuesday\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:patching@example.com\r\n" +
+	"RECURRENCE-ID;TZID=Europe/London:20240312T220000\r\n" +
+	"DTSTART;TZID=Europe/London:20240314T220000\r\n" +
+	"DTEND;TZID=Europe/London:20240315T020000\r\n" +
+	"SUMMARY:Patch Tuesday (moved)\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled@example.com\r\n" +
+	"DTSTART:20240101T000000Z\r\n" +
+	"DTEND:20240102T000000Z\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestFromICS(t *testing.T) {
+	set, err := FromICS([]byte(icsTestFile), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != 3 {
+		t.Fatalf("Expected 3 intervals, got %v", set)
+	}
+	if set[0].Name != "Change freeze, year end" || set[1].Name != "Patch Tuesday" || set[1].Description != "" {
+		t.Errorf("Unexpected names %q, %q and description %q", set[0].Name, set[1].Name, set[1].Description)
+	}
+	for _, tc := range []struct {
+		time     string
+		contains bool
+	}{
+		{"2024-12-19T23:59:59Z", false},
+		{"2024-12-20T00:00:00Z", true},
+		{"2025-01-02T23:59:59Z", true},
+		{"2025-01-03T00:00:00Z", false},
+		// The second Tuesday of January, in London.
+		{"2024-01-09T23:00:00Z", true},
+		{"2024-01-10T02:00:00Z", false},
+		{"2024-01-02T23:00:00Z", false},
+		// February's is excluded, and March's moved to the Thursday.
+		{"2024-02-13T23:00:00Z", false},
+		{"2024-03-12T23:00:00Z", false},
+		{"2024-03-14T23:00:00Z", true},
+		// In April, London is an hour ahead of UTC.
+		{"2024-04-09T21:00:00Z", true},
+		{"2024-04-09T20:59:59Z", false},
+		{"2024-01-01T12:00:00Z", false},
+	} {
+		tm, _ := time.Parse(time.RFC3339, tc.time)
+		if set.ContainsTime(tm) != tc.contains {
+			t.Errorf("Expected ContainsTime(%s) to be %v", tc.time, tc.contains)
+		}
+	}
+}
+
+func TestFromICSErrors(t *testing.T) {
+	for _, in := range []string{
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20240101T000000Z\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART 20240101T000000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTEND:20240101T000000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;TZID=Nowhere:20240101T000000\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20240101T000000Z\r\nDURATION:PT\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20240101T000000Z\r\nDURATION:PT1H\r\nRRULE:FREQ=HOURLY\r\n" +
+			"END:VEVENT\r\nEND:VCALENDAR\r\n",
+		// Events must be inside a calendar.
+		"BEGIN:VCALNDAR\r\nBEGIN:VEVENT\r\nDTSTART:20240101T000000Z\r\nEND:VEVENT\r\nEND:VCALNDAR\r\n",
+		"BEGIN:VEVENT\r\nDTSTART:20240101T000000Z\r\nEND:VEVENT\r\n",
+	} {
+		if set, err := FromICS([]byte(in), time.UTC); err == nil {
+			t.Errorf("Expected an error reading %q, got %v", in, set)
+		}
+	}
+}
+
+func TestFromICSNilLocation(t *testing.T) {
+	// Floating times are read in UTC when no location is given.
+	in := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20240101T090000\r\nDURATION:PT1H\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	set, err := FromICS([]byte(in), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	during, after := time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC), time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	if !set.ContainsTime(during) || set.ContainsTime(after) {
+		t.Errorf("Expected a floating event to be read in UTC, got %v", set)
+	}
+}
+
+func TestICSRoundTrip(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+	for _, tc := range icsTestCases {
+		out, err := tc.interval.ICS(from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set, err := FromICS(out, time.UTC)
+		if err != nil {
+			t.Errorf("Unexpected error reading back %v: %v", tc.interval, err)
+			continue
+		}
+		got, want := set.Windows(from, to), tc.interval.Windows(from, to)
+		same := len(got) == len(want)
+		for i := 0; same && i < len(got); i++ {
+			same = got[i].Start.Equal(want[i].Start) && got[i].End.Equal(want[i].End)
+		}
+		if !same {
+			t.Errorf("Reading back %v gave windows %v, expected %v", tc.interval, got, want)
+		}
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1D":     24 * time.Hour,
+		"P2W":     14 * 24 * time.Hour,
+		"P1DT12H": 36 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+	} {
+		got, err := parseICSDuration(in)
+		if err != nil || got != want {
+			t.Errorf("Expected %s to be %v, got %v, %v", in, want, got, err)
+		}
+	}
+	for _, in := range []string{"P", "PT", "1H", "P1H", "PT1D"} {
+		if _, err := parseICSDuration(in); err == nil {
+			t.Errorf("Expected an error parsing %s", in)
+		}
+	}
+}
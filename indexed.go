@@ -0,0 +1,347 @@
+package gotime
+
+import (
+	"sort"
+	"time"
+)
+
+// hoursPerWeek is the number of hours in a week without clock changes.
+const hoursPerWeek = 7 * 24
+
+// An IndexedSet is an IntervalSet with an index that narrows down which of its intervals might contain a time before
+// any are checked, for sets of hundreds or thousands of intervals, such as mute rules, where checking each in turn
+// is too slow. It gives the same results as the IntervalSet it was built from, and is safe for concurrent use.
+//
+// Intervals are indexed by the hours of the week their times and weekdays allow, in their own location, and those
+// bounded in time by absolute ranges, dates or years are also kept in an interval tree of their bounds. Only the
+// intervals that both indexes allow at a time are checked, so a time is checked against each interval that might
+// contain it and few others.
+type IndexedSet struct {
+	set     IntervalSet
+	entries []indexEntry
+	// locations group the intervals that aren't bounded in time by the location they are matched in, with a list of
+	// them for each hour of the week.
+	locations []*locationIndex
+	bounded   *spanNode
+}
+
+// An indexEntry holds what the index knows about an interval of the set.
+type indexEntry struct {
+	matcher Matcher
+	loc     *time.Location
+	// hours has a bit for each hour of the week from midnight on Sunday in which the interval might be active.
+	hours [(hoursPerWeek + 63) / 64]uint64
+	// start and end bound the times the interval might contain, if bounded is set.
+	bounded    bool
+	start, end time.Time
+}
+
+// A locationIndex lists the unbounded intervals matched in a location by the hours of the week they might be active,
+// in order of their position in the set. A nil location stands for intervals without one, which are matched in the
+// location of the time.
+type locationIndex struct {
+	loc   *time.Location
+	hours [hoursPerWeek][]int
+}
+
+// NewIndexedSet builds an index of the intervals in is. The set is copied, so later changes to it aren't seen.
+// Intervals are compiled where they can be, to make checking them faster too.
+func NewIndexedSet(is IntervalSet) *IndexedSet {
+	s := &IndexedSet{set: make(IntervalSet, len(is)), entries: make([]indexEntry, len(is))}
+	var bounded []int
+	for i, ti := range is {
+		ti = ti.clone()
+		s.set[i] = ti
+		s.entries[i] = newIndexEntry(ti)
+		if e := s.entries[i]; e.bounded {
+			// Intervals bounded to an empty period are never active, so needn't be indexed at all.
+			if e.start.Before(e.end) {
+				bounded = append(bounded, i)
+			}
+			continue
+		}
+		li := s.locationIndex(s.entries[i].loc)
+		for h := 0; h < hoursPerWeek; h++ {
+			if s.entries[i].mightContainHour(h) {
+				li.hours[h] = append(li.hours[h], i)
+			}
+		}
+	}
+	s.bounded = newSpanNode(s.entries, bounded)
+	return s
+}
+
+func newIndexEntry(ti TimeInterval) indexEntry {
+	e := indexEntry{matcher: ti}
+	if m, err := ti.Compile(); err == nil {
+		e.matcher = m
+	}
+	if ti.Location != nil {
+		e.loc = ti.Location.Location
+	}
+	e.hours = ti.weekHours()
+	e.start, e.end, e.bounded = ti.bounds()
+	return e
+}
+
+// locationIndex returns the index of the intervals matched in loc, adding it if there isn't one.
+func (s *IndexedSet) locationIndex(loc *time.Location) *locationIndex {
+	for _, li := range s.locations {
+		if li.loc == loc {
+			return li
+		}
+	}
+	li := &locationIndex{loc: loc}
+	s.locations = append(s.locations, li)
+	return li
+}
+
+func (e *indexEntry) mightContainHour(hour int) bool {
+	return e.hours[hour/64]&(1<<uint(hour%64)) != 0
+}
+
+// hourOfWeek returns the hour of the week from midnight on Sunday of t in loc, or in t's location if loc is nil.
+func hourOfWeek(t time.Time, loc *time.Location) int {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// weekHours returns a bitmap of the hours of the week from midnight on Sunday during which the interval's times and
+// weekdays allow it to be active. Its other fields can only rule out more, so aren't considered.
+func (tp TimeInterval) weekHours() (hours [(hoursPerWeek + 63) / 64]uint64) {
+	set := func(hour int) {
+		hours[hour/64] |= 1 << uint(hour%64)
+	}
+	// Solar times and DST policies can move times to any hour of the day, or past midnight.
+	moved := tp.Times != nil && (tp.hasSolarTimes() || tp.DST != (DSTPolicy{}))
+	for day := 0; day < 7; day++ {
+		today, yesterday := tp.allowsWeekday(time.Weekday(day)), tp.allowsWeekday(time.Weekday((day+6)%7))
+		for hour := 0; hour < 24; hour++ {
+			if tp.Times == nil || moved {
+				if today || (moved && yesterday) {
+					set(day*24 + hour)
+				}
+				continue
+			}
+			from, to := hour*3600, (hour+1)*3600
+			for _, tr := range tp.Times {
+				start, end := tr.startSecond(), tr.endSecond()
+				if tr.isOvernight() {
+					if (today && start < to) || (yesterday && from < end) {
+						set(day*24 + hour)
+					}
+				} else if today && start < to && from < end {
+					set(day*24 + hour)
+				}
+			}
+		}
+	}
+	return hours
+}
+
+// allowsWeekday returns true if the interval's weekdays include wd.
+func (tp TimeInterval) allowsWeekday(wd time.Weekday) bool {
+	if tp.Weekdays == nil {
+		return true
+	}
+	for _, r := range tp.Weekdays {
+		if r.containsWeekday(wd) {
+			return true
+		}
+	}
+	return false
+}
+
+// bounds returns the period from start up to end outside which the interval is never active, or false if its
+// absolute ranges, dates and years don't bound it. Dates and years are widened by a few days, so that the bounds hold
+// in any location and for overnight times and fiscal years.
+func (tp TimeInterval) bounds() (start, end time.Time, bounded bool) {
+	narrow := func(s, e time.Time) {
+		if !bounded || s.After(start) {
+			start = s
+		}
+		if !bounded || e.Before(end) {
+			end = e
+		}
+		bounded = true
+	}
+	if tp.Absolute != nil {
+		var s, e time.Time
+		for i, r := range tp.Absolute {
+			if i == 0 || r.Start.Before(s) {
+				s = r.Start
+			}
+			if i == 0 || r.End.After(e) {
+				e = r.End
+			}
+		}
+		narrow(s, e)
+	}
+	if tp.Dates != nil {
+		var s, e time.Time
+		for i, r := range tp.Dates {
+			if i == 0 || dateOf(r.Begin).Before(s) {
+				s = dateOf(r.Begin)
+			}
+			if i == 0 || dateOf(r.End).After(e) {
+				e = dateOf(r.End)
+			}
+		}
+		narrow(s.AddDate(0, 0, -2), e.AddDate(0, 0, 3))
+	}
+	if tp.Years != nil {
+		first, last := 0, 0
+		for i, r := range tp.Years {
+			if i == 0 || r.Begin < first {
+				first = r.Begin
+			}
+			if i == 0 || r.End > last {
+				last = r.End
+			}
+		}
+		narrow(date(first-1, time.January, 1), date(last+2, time.January, 1))
+	}
+	if bounded && !start.Before(end) {
+		end = start
+	}
+	return start, end, bounded
+}
+
+// ContainsTime returns true if the set contains the given time, otherwise returns false.
+func (s *IndexedSet) ContainsTime(t time.Time) bool {
+	var buf [32]int
+	candidates := s.candidates(t, buf[:0])
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if s.entries[candidates[i]].matcher.ContainsTime(t) {
+			return s.set[candidates[i]].Mode == ModeAllow
+		}
+	}
+	return false
+}
+
+// Matching returns the intervals in the set that contain the given time whatever their mode, in the order they
+// appear in the set, as IntervalSet.Matching does.
+func (s *IndexedSet) Matching(t time.Time) []TimeInterval {
+	var buf [32]int
+	var matching []TimeInterval
+	for _, i := range s.candidates(t, buf[:0]) {
+		if s.entries[i].matcher.ContainsTime(t) {
+			matching = append(matching, s.set[i])
+		}
+	}
+	return matching
+}
+
+// NextTransition returns the earliest time after t at which the set either becomes active or stops being active, as
+// IntervalSet.NextTransition does.
+func (s *IndexedSet) NextTransition(t time.Time) (time.Time, bool) {
+	return s.set.NextTransition(t)
+}
+
+// PreviousTransition returns the latest time at or before t at which the set either became active or stopped being
+// active, as IntervalSet.PreviousTransition does.
+func (s *IndexedSet) PreviousTransition(t time.Time) (time.Time, bool) {
+	return s.set.PreviousTransition(t)
+}
+
+// candidates appends the positions of the intervals that might contain t to buf, in order.
+func (s *IndexedSet) candidates(t time.Time, buf []int) []int {
+	for _, li := range s.locations {
+		buf = append(buf, li.hours[hourOfWeek(t, li.loc)]...)
+	}
+	buf = s.bounded.stab(t, s.entries, buf)
+	// The lists from each location come in order, but not together or with those from the tree.
+	sortInts(buf)
+	return buf
+}
+
+// sortInts sorts a short, mostly ordered list of ints in place. Unlike sort.Ints it doesn't pass the list through an
+// interface, so the list can stay on the stack.
+func sortInts(a []int) {
+	for i := 1; i < len(a); i++ {
+		for j := i; j > 0 && a[j] < a[j-1]; j-- {
+			a[j], a[j-1] = a[j-1], a[j]
+		}
+	}
+}
+
+// A spanNode is a node of a centered interval tree over the bounds of bounded intervals. It holds the intervals whose
+// bounds include its center, sorted by start and by end, and subtrees of those that end before or start after it.
+type spanNode struct {
+	center      time.Time
+	byStart     []int
+	byEnd       []int
+	left, right *spanNode
+}
+
+// newSpanNode builds a tree of the bounds of the given entries.
+func newSpanNode(entries []indexEntry, positions []int) *spanNode {
+	if len(positions) == 0 {
+		return nil
+	}
+	points := make([]time.Time, 0, 2*len(positions))
+	for _, i := range positions {
+		points = append(points, entries[i].start, entries[i].end)
+	}
+	sort.Slice(points, func(a, b int) bool { return points[a].Before(points[b]) })
+	n := &spanNode{center: points[len(points)/2]}
+	var left, right []int
+	for _, i := range positions {
+		switch e := entries[i]; {
+		case !e.end.After(n.center):
+			left = append(left, i)
+		case e.start.After(n.center):
+			right = append(right, i)
+		default:
+			n.byStart = append(n.byStart, i)
+		}
+	}
+	if len(left) == len(positions) || len(right) == len(positions) {
+		// Keep intervals that can't be split at the center here, rather than splitting them forever.
+		n.byStart, left, right = positions, nil, nil
+	}
+	n.byEnd = append([]int(nil), n.byStart...)
+	sort.SliceStable(n.byStart, func(a, b int) bool {
+		return entries[n.byStart[a]].start.Before(entries[n.byStart[b]].start)
+	})
+	sort.SliceStable(n.byEnd, func(a, b int) bool {
+		return entries[n.byEnd[a]].end.After(entries[n.byEnd[b]].end)
+	})
+	n.left, n.right = newSpanNode(entries, left), newSpanNode(entries, right)
+	return n
+}
+
+// stab appends the positions of the entries whose bounds include t, and whose hours of the week allow t, to buf.
+func (n *spanNode) stab(t time.Time, entries []indexEntry, buf []int) []int {
+	for n != nil {
+		if t.Before(n.center) {
+			// Every interval here ends after the center, so includes t if it starts by then.
+			for _, i := range n.byStart {
+				if entries[i].start.After(t) {
+					break
+				}
+				buf = appendIfHour(buf, i, t, &entries[i])
+			}
+			n = n.left
+		} else {
+			// Every interval here starts by the center, so includes t if it ends after it.
+			for _, i := range n.byEnd {
+				if !entries[i].end.After(t) {
+					break
+				}
+				buf = appendIfHour(buf, i, t, &entries[i])
+			}
+			n = n.right
+		}
+	}
+	return buf
+}
+
+func appendIfHour(buf []int, i int, t time.Time, e *indexEntry) []int {
+	if e.mightContainHour(hourOfWeek(t, e.loc)) {
+		buf = append(buf, i)
+	}
+	return buf
+}
@@ -0,0 +1,125 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestIndexedSet(t *testing.T) {
+	doc := `
+- weekdays: ['monday:friday']
+  times:
+    - start_time: '09:00'
+      end_time: '17:00'
+  location: Australia/Melbourne
+- mode: deny
+  weekdays: ['wednesday']
+  times:
+    - start_time: '12:00'
+      end_time: '13:00'
+  location: Australia/Melbourne
+- weekdays: ['saturday']
+  times:
+    - start_time: '22:00'
+      end_time: '02:00'
+- dates: ['2024-12-24:2025-01-02']
+- mode: deny
+  dates: ['2024-12-25']
+  location: America/New_York
+- absolute:
+    - start: '2024-11-05T22:00:00Z'
+      end: '2024-11-06T02:00:00Z'
+- years: ['2025']
+  months: ['february']
+- coordinates: {latitude: -37.8, longitude: 144.9}
+  weekdays: ['sunday']
+  times:
+    - start_time: sunset
+      end_time: sunrise
+- times: []
+- dates: ['2024-10-01']
+  absolute:
+    - start: '2024-11-01T00:00:00Z'
+      end: '2024-11-02T00:00:00Z'
+`
+	var set IntervalSet
+	if err := yaml.UnmarshalStrict([]byte(doc), &set); err != nil {
+		t.Fatal(err)
+	}
+	indexed := NewIndexedSet(set)
+	start := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for at := start; at.Before(end); at = at.Add(23 * time.Minute) {
+		if got, expected := indexed.ContainsTime(at), set.ContainsTime(at); got != expected {
+			t.Fatalf("Expected indexed set to give %v at %s, got %v", expected, at, got)
+		}
+		if got, expected := indexed.Matching(at), set.Matching(at); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Expected indexed set to match %v at %s, got %v", expected, at, got)
+		}
+	}
+
+	// Changing the set after indexing it doesn't change the index.
+	set[0].Weekdays[0].Begin = 2
+	monday := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	if !indexed.ContainsTime(monday) {
+		t.Error("Expected indexed set to be unaffected by changes to the set")
+	}
+}
+
+// manyWindows returns business hours followed by a thousand one-off windows, each an hour long and a day apart.
+func manyWindows() IntervalSet {
+	set := IntervalSet{{
+		Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+	}}
+	first := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		start := first.AddDate(0, 0, i)
+		set = append(set, TimeInterval{Absolute: []AbsoluteRange{{Start: start, End: start.Add(time.Hour)}}})
+	}
+	return set
+}
+
+func TestIndexedSetCandidates(t *testing.T) {
+	indexed := NewIndexedSet(manyWindows())
+	cases := []struct {
+		at       time.Time
+		expected []int
+		active   bool
+	}{
+		{time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC), []int{0}, true},
+		{time.Date(2024, 3, 4, 20, 30, 0, 0, time.UTC), []int{64}, true},
+		{time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC), nil, false},
+		{time.Date(2030, 3, 4, 20, 30, 0, 0, time.UTC), nil, false},
+	}
+	for _, c := range cases {
+		if got := indexed.candidates(c.at, nil); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected candidates %v at %s, got %v", c.expected, c.at, got)
+		}
+		if got := indexed.ContainsTime(c.at); got != c.active {
+			t.Errorf("Expected indexed set to give %v at %s, got %v", c.active, c.at, got)
+		}
+		if allocs := testing.AllocsPerRun(10, func() { indexed.ContainsTime(c.at) }); allocs != 0 {
+			t.Errorf("Expected ContainsTime at %s not to allocate, got %v allocations", c.at, allocs)
+		}
+	}
+}
+
+func BenchmarkIndexedSet(b *testing.B) {
+	set := manyWindows()
+	at := time.Date(2024, 3, 4, 20, 30, 0, 0, time.UTC)
+	for _, m := range []struct {
+		name string
+		m    Matcher
+	}{{"set", set}, {"indexed", NewIndexedSet(set)}} {
+		b.Run(m.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.m.ContainsTime(at)
+			}
+		})
+	}
+}
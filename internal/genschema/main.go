@@ -0,0 +1,25 @@
+// Command genschema writes the JSON Schema for gotime's interval documents to the file named by its argument.
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/benridley/gotime"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: genschema <file>")
+		os.Exit(2)
+	}
+	out, err := gotime.JSONSchema()
+	if err == nil {
+		err = ioutil.WriteFile(os.Args[1], append(out, '\n'), 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
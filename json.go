@@ -0,0 +1,147 @@
+package gotime
+
+import (
+	"bytes"
+	"encoding/json"
+
+	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// MarshalJSON implements the json.Marshaler interface for TimeInterval, encoding it as an object with the same fields
+// and values as in YAML, e.g. {"weekdays": ["monday:friday"]}. Without it, encoding/json would use the text form,
+// which can't express every interval.
+func (tp TimeInterval) MarshalJSON() ([]byte, error) {
+	return marshalJSONAsYAML(tp)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for TimeInterval. Objects are read as they are in YAML, and
+// strings in the text form.
+func (tp *TimeInterval) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		return nil
+	}
+	if s, ok := jsonString(data); ok {
+		return tp.UnmarshalText([]byte(s))
+	}
+	var parsed TimeInterval
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*tp = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for IntervalSet, encoding it as an array of intervals in the
+// same form as TimeInterval.MarshalJSON rather than as text.
+func (is IntervalSet) MarshalJSON() ([]byte, error) {
+	if is == nil {
+		return []byte("[]"), nil
+	}
+	return marshalJSONAsYAML(is)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for IntervalSet. Arrays of intervals are read as they are in
+// YAML, and strings in the text form of a set.
+func (is *IntervalSet) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		return nil
+	}
+	if s, ok := jsonString(data); ok {
+		return is.UnmarshalText([]byte(s))
+	}
+	var parsed IntervalSet
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	if parsed == nil {
+		parsed = IntervalSet{}
+	}
+	*is = parsed
+	return nil
+}
+
+// marshalJSONAsYAML marshals v as YAML and converts the document to JSON, keeping the order of its keys.
+func marshalJSONAsYAML(v interface{}) ([]byte, error) {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, &doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSONNode writes a YAML node as JSON. Scalars are written as the value YAML decodes them to.
+func writeJSONNode(buf *bytes.Buffer, node *yamlv3.Node) error {
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSONNode(buf, node.Content[0])
+	case yamlv3.AliasNode:
+		return writeJSONNode(buf, node.Alias)
+	case yamlv3.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSONNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case yamlv3.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONNode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(out)
+	return nil
+}
+
+// isJSONNull returns true if data is the JSON null, which json.Unmarshaler implementations should ignore.
+func isJSONNull(data []byte) bool {
+	return string(bytes.TrimSpace(data)) == "null"
+}
+
+// jsonString returns the value of data if it is a JSON string.
+func jsonString(data []byte) (string, bool) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
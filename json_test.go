@@ -0,0 +1,86 @@
+package gotime
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestIntervalJSON(t *testing.T) {
+	in := `{name: on call, location: Europe/London, weekdays: ['monday:friday'],
+times: [{start_time: '09:00', end_time: '17:00'}], cycle: {anchor: '2024-01-01', every: 2w},
+absolute: [{start: '2024-01-01T00:00:00Z', end: '2025-01-01T00:00:00Z'}],
+except: [{name: lunch, times: [{start_time: '12:00', end_time: '13:00'}]}, {months: [december]}]}`
+	var ti TimeInterval
+	if err := yaml.Unmarshal([]byte(in), &ti); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(ti)
+	if err != nil {
+		t.Fatalf("Received unexpected error: %v when marshalling %+v", err, ti)
+	}
+	var ti2 TimeInterval
+	if err := json.Unmarshal(out, &ti2); err != nil {
+		t.Fatalf("Received unexpected error: %v when unmarshalling %s", err, out)
+	}
+	if !reflect.DeepEqual(ti, ti2) {
+		t.Errorf("Unmarshalling %s: want %+v, got %+v", out, ti, ti2)
+	}
+
+	simple := TimeInterval{Name: "weekdays", Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}}
+	if out, err := json.Marshal(simple); err != nil || string(out) != `{"name":"weekdays","weekdays":["monday:friday"]}` {
+		t.Errorf("Expected the YAML fields as a JSON object, got %s, %v", out, err)
+	}
+
+	// Intervals may also be given in the text form, and null leaves an interval as it is.
+	if err := json.Unmarshal([]byte(`"mon-fri 09:00-17:00"`), &ti2); err != nil {
+		t.Fatal(err)
+	} else if len(ti2.Weekdays) != 1 || len(ti2.Times) != 1 {
+		t.Errorf("Expected the text form to be read, got %+v", ti2)
+	}
+	if err := json.Unmarshal([]byte(`null`), &ti2); err != nil || len(ti2.Weekdays) != 1 {
+		t.Errorf("Expected null to leave the interval unchanged, got %+v, %v", ti2, err)
+	}
+	for _, in := range []string{`{"weekdays": ["someday"]}`, `"mon-fri nonsense"`, `42`} {
+		if err := json.Unmarshal([]byte(in), &ti2); err == nil {
+			t.Errorf("Expected an error unmarshalling %s", in)
+		}
+	}
+}
+
+func TestIntervalSetJSON(t *testing.T) {
+	var set IntervalSet
+	in := `[{"name": "weekdays", "weekdays": ["monday:friday"], "except": [{"months": ["december"]}]},
+		{"mode": "deny", "times": [{"start_time": "12:00", "end_time": "13:00"}]}]`
+	if err := json.Unmarshal([]byte(in), &set); err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != 2 || set[0].Name != "weekdays" || len(set[0].Except) != 1 || set[1].Mode != ModeDeny {
+		t.Fatalf("Unexpected set %+v", set)
+	}
+	out, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("Received unexpected error: %v when marshalling %+v", err, set)
+	}
+	var set2 IntervalSet
+	if err := json.Unmarshal(out, &set2); err != nil || !reflect.DeepEqual(set, set2) {
+		t.Errorf("Unmarshalling %s: want %+v, got %+v, %v", out, set, set2, err)
+	}
+
+	// Sets held in other values are encoded the same way.
+	d := Definitions{"empty": IntervalSet{}, "weekdays": set[:1]}
+	if out, err := json.Marshal(d); err != nil {
+		t.Error(err)
+	} else if want := `{"empty":[],"weekdays":[{"name":"weekdays","weekdays":["monday:friday"],` +
+		`"except":[{"months":["december"]}]}]}`; string(out) != want {
+		t.Errorf("Expected %s, got %s", want, out)
+	}
+	if err := json.Unmarshal([]byte(`"mon-fri; sat 09:00-12:00"`), &set2); err != nil || len(set2) != 2 {
+		t.Errorf("Expected the text form to be read, got %+v, %v", set2, err)
+	}
+	if err := json.Unmarshal([]byte(`{"weekdays": ["monday"]}`), &set2); err == nil {
+		t.Errorf("Expected an error unmarshalling an object into a set")
+	}
+}
@@ -0,0 +1,127 @@
+package gotime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// keywordFields lists the fields of a TimeInterval whose lists may contain keywords.
+var keywordFields = map[string]bool{
+	"times":             true,
+	"weekdays":          true,
+	"days_of_month":     true,
+	"weekdays_of_month": true,
+	"weeks":             true,
+	"months":            true,
+	"quarters":          true,
+	"years":             true,
+	"relative":          true,
+	"dates":             true,
+}
+
+// defaultWeekend holds the days of the weekend used by the weekend and weekdays keywords, unless an interval gives its
+// own with the weekend key.
+var defaultWeekend = map[time.Weekday]bool{time.Saturday: true, time.Sunday: true}
+
+// weekdayKeyword returns the weekday ranges that a keyword in the weekdays field stands for, given the days of the
+// weekend, or false if it isn't a weekday keyword.
+func weekdayKeyword(keyword string, weekend map[time.Weekday]bool) ([]interface{}, bool) {
+	var wantWeekend bool
+	switch keyword {
+	case "weekend", "weekends":
+		wantWeekend = true
+	case "weekdays", "weekday":
+		wantWeekend = false
+	default:
+		return nil, false
+	}
+	// Collect runs of consecutive days so that e.g. Monday to Friday becomes a single range.
+	days := []interface{}{}
+	for begin := time.Sunday; begin <= time.Saturday; begin++ {
+		if weekend[begin] != wantWeekend {
+			continue
+		}
+		end := begin
+		for end < time.Saturday && weekend[end+1] == wantWeekend {
+			end++
+		}
+		if begin == end {
+			days = append(days, daysOfWeekInv[int(begin)])
+		} else {
+			days = append(days, daysOfWeekInv[int(begin)]+":"+daysOfWeekInv[int(end)])
+		}
+		begin = end
+	}
+	return days, true
+}
+
+// takeWeekend removes the weekend key from an interval's fields, returning the days it names or the default weekend
+// if there isn't one.
+func takeWeekend(fields yaml.MapSlice, locale *Locale) (map[time.Weekday]bool, yaml.MapSlice, bool, error) {
+	for i, field := range fields {
+		if key, _ := field.Key.(string); key != "weekend" {
+			continue
+		}
+		list, ok := field.Value.([]interface{})
+		if !ok {
+			return nil, nil, false, fmt.Errorf("%v is not a valid weekend: expected a list of weekdays", field.Value)
+		}
+		if locale != nil {
+			list = locale.translate("weekdays", list)
+		}
+		out, err := yaml.Marshal(list)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		var ranges []WeekdayRange
+		if err := yaml.Unmarshal(out, &ranges); err != nil {
+			return nil, nil, false, err
+		}
+		weekend := make(map[time.Weekday]bool)
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			for _, r := range ranges {
+				if r.containsWeekday(wd) {
+					weekend[wd] = true
+				}
+			}
+		}
+		rest := append(yaml.MapSlice{}, fields[:i]...)
+		return weekend, append(rest, fields[i+1:]...), true, nil
+	}
+	return defaultWeekend, fields, false, nil
+}
+
+// expandKeywords replaces any keywords in the list of values given for a field, returning false if there were none.
+// A list containing 'always' or '*' is replaced by nil, since a missing field matches everything.
+func expandKeywords(field string, list []interface{}, weekend map[time.Weekday]bool) (interface{}, bool) {
+	out := make([]interface{}, 0, len(list))
+	found := false
+	for _, v := range list {
+		str, ok := v.(string)
+		if !ok {
+			out = append(out, v)
+			continue
+		}
+		keyword := strings.ToLower(strings.TrimSpace(str))
+		// '*' follows cron in meaning every value.
+		if keyword == "always" || keyword == "*" {
+			return nil, true
+		}
+		if keyword == "never" {
+			found = true
+			continue
+		}
+		if field == "weekdays" {
+			if days, ok := weekdayKeyword(keyword, weekend); ok {
+				out = append(out, days...)
+				found = true
+				continue
+			}
+		}
+		out = append(out, v)
+	}
+	return out, found
+}
@@ -0,0 +1,95 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var keywordTestCases = []struct {
+	in       string
+	interval TimeInterval
+}{
+	{
+		in: "weekdays: ['weekdays']",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+	},
+	{
+		in: "weekdays: ['Weekend', 'wednesday']",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange{Begin: 3, End: 3}}},
+		},
+	},
+	{
+		// A weekend of Friday and Saturday
+		in: "{weekend: ['friday:saturday'], weekdays: ['weekdays']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 4}}},
+		},
+	},
+	{
+		in: "{weekend: ['friday'], weekdays: ['weekend']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 5}}},
+		},
+	},
+	{
+		in: "{weekend: ['sunday', 'wednesday'], weekdays: ['weekday']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 2}}, {InclusiveRange{Begin: 4, End: 6}}},
+		},
+	},
+	{
+		in: "{locale: 'fr', weekend: ['vendredi:samedi'], weekdays: ['weekend']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}},
+		},
+	},
+	{
+		in:       "{weekdays: ['*'], days_of_month: ['*'], weeks: ['*'], quarters: ['*'], times: ['*']}",
+		interval: TimeInterval{},
+	},
+	{
+		in:       "{months: ['always'], years: ['2020', 'always']}",
+		interval: TimeInterval{},
+	},
+	{
+		in: "{weekdays: ['monday'], times: ['never']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}},
+			Times:    []TimeRange{},
+		},
+	},
+	{
+		in: "days_of_month: ['never', '1:7']",
+		interval: TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 7}}},
+		},
+	},
+}
+
+func TestKeywords(t *testing.T) {
+	for _, tc := range keywordTestCases {
+		var ti TimeInterval
+		if err := yaml.Unmarshal([]byte(tc.in), &ti); err != nil {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+	}
+	var ti TimeInterval
+	if err := yaml.Unmarshal([]byte("months: ['weekend']"), &ti); err == nil {
+		t.Errorf("Expected weekday keywords to be rejected in months")
+	}
+	if err := yaml.Unmarshal([]byte("weekend: 'friday'"), &ti); err == nil {
+		t.Errorf("Expected a weekend that isn't a list to be rejected")
+	}
+	if err := yaml.Unmarshal([]byte("weekend: ['funday']"), &ti); err == nil {
+		t.Errorf("Expected an invalid weekend day to be rejected")
+	}
+}
@@ -0,0 +1,217 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A CronJobSchedule approximates an interval with the settings of a Kubernetes CronJob. Each of the cron expressions
+// starts the job every minute of the part of the interval that cron can express, which is its times, weekdays or days
+// of the month, and months. The job should be suspended for each of the suspensions, which cover the times the
+// expressions start it while the rest of the interval, such as its exceptions, holidays or absolute times, rules it
+// out. A CronJob has one schedule, so a separate CronJob is needed for each expression.
+type CronJobSchedule struct {
+	Schedules []string
+	// TimeZone is the name of the interval's location, for the CronJob's timeZone, or empty if it has no location.
+	TimeZone string
+	// Suspensions are the times, between the bounds given to CronJob, at which the job should be suspended. The job
+	// should be resumed at the end of each.
+	Suspensions []Window
+}
+
+// CronJob returns the Kubernetes CronJob settings that start a job each minute the interval is active, with the
+// suspensions needed between from and to. The job starts at the beginning of each minute, so a minute only counts if
+// the interval is active at its start.
+func (tp TimeInterval) CronJob(from, to time.Time) (CronJobSchedule, error) {
+	if tp.IsEmpty() {
+		return CronJobSchedule{}, errors.New("Unable to express an interval that is never active as a CronJob")
+	}
+	schedule := CronJobSchedule{Schedules: tp.cronExpressions()}
+	if tp.Location != nil {
+		schedule.TimeZone = tp.Location.String()
+	}
+	cron := make(IntervalSet, len(schedule.Schedules))
+	for i, expr := range schedule.Schedules {
+		var err error
+		if cron[i], err = FromCron(expr); err != nil {
+			return CronJobSchedule{}, err
+		}
+		cron[i].Location = tp.Location
+	}
+	for _, w := range cronConflicts(cron, tp, from, to) {
+		if _, ok := firstMinute(w); ok {
+			schedule.Suspensions = append(schedule.Suspensions, w)
+		}
+	}
+	return schedule, nil
+}
+
+// VerifyCron checks that a standard five field cron expression only starts a job between from and to while the
+// interval is active, returning an error giving the first time it starts one while it isn't. The expression is
+// evaluated in the interval's location, as a CronJob given the interval's time zone would be.
+func (tp TimeInterval) VerifyCron(expr string, from, to time.Time) error {
+	cron, err := FromCron(expr)
+	if err != nil {
+		return err
+	}
+	cron.Location = tp.Location
+	for _, w := range cronConflicts(cron, tp, from, to) {
+		if at, ok := firstMinute(w); ok {
+			return fmt.Errorf("Cron expression %s starts a job at %s, when the interval is not active", expr,
+				at.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// cronConflicts returns the windows between from and to during which cron is active but tp isn't. Unlike the windows
+// of an Intersection, the search for transitions stops at to.
+func cronConflicts(cron, tp TransitionMatcher, from, to time.Time) []Window {
+	var windows []Window
+	for cur := from; cur.Before(to); {
+		next := to
+		for _, m := range []TransitionMatcher{cron, tp} {
+			if t, ok := m.NextTransition(cur); ok && t.Before(next) {
+				next = t
+			}
+		}
+		if cron.ContainsTime(cur) && !tp.ContainsTime(cur) {
+			if n := len(windows); n > 0 && windows[n-1].End.Equal(cur) {
+				windows[n-1].End = next
+			} else {
+				windows = append(windows, Window{Start: cur, End: next})
+			}
+		}
+		cur = next
+	}
+	return windows
+}
+
+// firstMinute returns the first start of a minute within the window, when a cron job would start.
+func firstMinute(w Window) (time.Time, bool) {
+	at := w.Start.Truncate(time.Minute)
+	if at.Before(w.Start) {
+		at = at.Add(time.Minute)
+	}
+	return at, at.Before(w.End)
+}
+
+// cronExpressions returns cron expressions that between them match every minute at whose start the interval may be
+// active, judging by its times, weekdays, days of the month and months. Fields that cron can't express are left out,
+// so the expressions may match more than the interval.
+func (tp TimeInterval) cronExpressions() []string {
+	today, tomorrow := make([]bool, secondsPerDay/60), make([]bool, secondsPerDay/60)
+	if tp.Times == nil || tp.hasSolarTimes() {
+		for m := range today {
+			today[m] = true
+		}
+	}
+	for _, tr := range tp.Times {
+		start, end := tr.startSecond(), tr.endSecond()
+		for m := range today {
+			s := m * 60
+			if tr.isOvernight() {
+				today[m] = today[m] || s >= start
+				tomorrow[m] = tomorrow[m] || s < end
+			} else {
+				today[m] = today[m] || s >= start && s < end
+			}
+		}
+	}
+	weekdays := make([]bool, 7)
+	for _, r := range weekdayInclusiveRanges(tp.Weekdays) {
+		for d := r.Begin; d <= r.End; d++ {
+			weekdays[d] = true
+		}
+	}
+	days := make([]bool, 31)
+	for _, r := range tp.DaysOfMonth {
+		if r.Begin < 1 || r.End < 1 {
+			days = nil
+			break
+		}
+		for d := r.Begin; d <= r.End && d <= 31; d++ {
+			days[d-1] = true
+		}
+	}
+	if tp.Weekdays == nil {
+		weekdays = nil
+	}
+	// Cron matches either day field when both are restricted, so only the weekdays can be kept.
+	if tp.DaysOfMonth == nil || weekdays != nil {
+		days = nil
+	}
+	months := make([]bool, 12)
+	for _, r := range monthInclusiveRanges(tp.Months) {
+		for m := r.Begin; m <= r.End; m++ {
+			months[m-1] = true
+		}
+	}
+	if tp.Months == nil {
+		months = nil
+	}
+	exprs := cronMinuteExpressions(today, cronDayFields(days, months, weekdays))
+	if weekdays != nil {
+		// The part of an overnight range after midnight falls on the day after each of the interval's weekdays.
+		next := make([]bool, 7)
+		for d, ok := range weekdays {
+			next[(d+1)%7] = ok
+		}
+		weekdays = next
+	}
+	// The day after one of the days of the month may fall in the next month, so neither can be kept.
+	return append(exprs, cronMinuteExpressions(tomorrow, cronDayFields(nil, nil, weekdays))...)
+}
+
+// cronDayFields returns the day of the month, month and day of the week fields of a cron expression matching the given
+// sets, where a nil set matches everything.
+func cronDayFields(days, months, weekdays []bool) string {
+	return strings.Join([]string{cronList(days, 1), cronList(months, 1), cronList(weekdays, 0)}, " ")
+}
+
+// cronMinuteExpressions returns cron expressions with the given day fields that match the minutes of the day that
+// are set, grouping together hours that match the same minutes.
+func cronMinuteExpressions(minutes []bool, dayFields string) []string {
+	var exprs, patterns []string
+	hours := map[string][]bool{}
+	for h := 0; h < 24; h++ {
+		set := minutes[h*60 : (h+1)*60]
+		active := false
+		for _, ok := range set {
+			active = active || ok
+		}
+		if !active {
+			continue
+		}
+		pattern := cronList(set, 0)
+		if hours[pattern] == nil {
+			hours[pattern] = make([]bool, 24)
+			patterns = append(patterns, pattern)
+		}
+		hours[pattern][h] = true
+	}
+	for _, pattern := range patterns {
+		exprs = append(exprs, pattern+" "+cronList(hours[pattern], 0)+" "+dayFields)
+	}
+	return exprs
+}
+
+// cronList returns a cron field matching the values in set, where the first value is offset, or '*' if set is nil or
+// holds every value.
+func cronList(set []bool, offset int) string {
+	runs := cronRuns(set, offset)
+	if runs == nil {
+		return "*"
+	}
+	parts := make([]string, len(runs))
+	for i, r := range runs {
+		parts[i] = strconv.Itoa(r.Begin)
+		if r.End != r.Begin {
+			parts[i] += "-" + strconv.Itoa(r.End)
+		}
+	}
+	return strings.Join(parts, ",")
+}
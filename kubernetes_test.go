@@ -0,0 +1,112 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCronJob(t *testing.T) {
+	weekdays := []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}
+	// The first week of July 2024, which starts on a Monday.
+	from, to := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+	at := func(s string) time.Time {
+		t, _ := time.Parse(time.RFC3339, s)
+		return t
+	}
+	holiday := at("2024-07-04T00:00:00Z")
+	cases := []struct {
+		interval    TimeInterval
+		schedules   []string
+		timeZone    string
+		suspensions []Window
+	}{
+		{
+			interval:  TimeInterval{Times: []TimeRange{{StartMinute: 540, EndMinute: 1020}}, Weekdays: weekdays},
+			schedules: []string{"* 9-16 * * 1-5"},
+		},
+		{
+			interval:  TimeInterval{Times: []TimeRange{{StartMinute: 570, EndMinute: 1020, EndSecond: 30}}},
+			schedules: []string{"30-59 9 * * *", "* 10-16 * * *", "0 17 * * *"},
+		},
+		{
+			interval: TimeInterval{
+				Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+				Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}},
+				Location: mustLoadLocation("Europe/London"),
+			},
+			schedules: []string{"* 22-23 * * 5-6", "* 0-5 * * 0,6"},
+			timeZone:  "Europe/London",
+		},
+		{
+			interval: TimeInterval{
+				DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 7}}},
+				Months:      []MonthRange{{InclusiveRange{Begin: 1, End: 1}}, {InclusiveRange{Begin: 7, End: 7}}},
+			},
+			schedules: []string{"* * 1-7 1,7 *"},
+		},
+		{
+			// The second Monday of the month, which cron can't express.
+			interval: TimeInterval{
+				Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}},
+				DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 8, End: 14}}},
+			},
+			schedules:   []string{"* * * * 1"},
+			suspensions: []Window{{Start: at("2024-07-01T00:00:00Z"), End: at("2024-07-02T00:00:00Z")}},
+		},
+		{
+			interval: TimeInterval{
+				Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+				Weekdays: weekdays,
+				Except:   []TimeInterval{{Dates: []DateRange{{Begin: holiday, End: holiday}}}},
+			},
+			schedules:   []string{"* 9-16 * * 1-5"},
+			suspensions: []Window{{Start: at("2024-07-04T09:00:00Z"), End: at("2024-07-04T17:00:00Z")}},
+		},
+	}
+	for _, c := range cases {
+		schedule, err := c.interval.CronJob(from, to)
+		if err != nil {
+			t.Errorf("Unexpected error converting %v: %v", c.interval, err)
+			continue
+		}
+		if !reflect.DeepEqual(schedule.Schedules, c.schedules) || schedule.TimeZone != c.timeZone {
+			t.Errorf("Expected schedules %q in %q, got %q in %q", c.schedules, c.timeZone, schedule.Schedules,
+				schedule.TimeZone)
+		}
+		if !reflect.DeepEqual(schedule.Suspensions, c.suspensions) {
+			t.Errorf("Expected suspensions %v, got %v", c.suspensions, schedule.Suspensions)
+		}
+	}
+	if _, err := (TimeInterval{Weekdays: []WeekdayRange{}}).CronJob(from, to); err == nil {
+		t.Errorf("Expected an error converting an interval that is never active")
+	}
+}
+
+func TestVerifyCron(t *testing.T) {
+	ti := TimeInterval{
+		Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		Location: mustLoadLocation("America/New_York"),
+	}
+	from, to := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		expr        string
+		expectError bool
+	}{
+		{"*/15 9-16 * * 1-5", false},
+		{"0 9 * * mon-fri", false},
+		{"0 17 * * 1-5", true},
+		{"0 12 * * *", true},
+		{"not a cron expression", true},
+	}
+	for _, c := range cases {
+		if err := ti.VerifyCron(c.expr, from, to); (err != nil) != c.expectError {
+			t.Errorf("Expected an error verifying %s: %t, got %v", c.expr, c.expectError, err)
+		}
+	}
+	// Only the bounds are checked.
+	if err := ti.VerifyCron("0 12 * * *", from, from.Add(24*time.Hour)); err != nil {
+		t.Errorf("Unexpected error verifying over a Monday: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package gotime
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A Locale holds the names of weekdays and months in a language other than English, keyed by their lower case form.
+// Intervals opt into a locale with the locale key, e.g. locale: 'fr', after which weekdays: ['lundi:vendredi'] is
+// understood. English names remain valid in every locale.
+type Locale struct {
+	Weekdays map[string]time.Weekday
+	Months   map[string]time.Month
+}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]Locale{
+		"fr": {
+			Weekdays: map[string]time.Weekday{
+				"dimanche": time.Sunday, "lundi": time.Monday, "mardi": time.Tuesday, "mercredi": time.Wednesday,
+				"jeudi": time.Thursday, "vendredi": time.Friday, "samedi": time.Saturday,
+				"dim": time.Sunday, "lun": time.Monday, "mar": time.Tuesday, "mer": time.Wednesday, "jeu": time.Thursday,
+				"ven": time.Friday, "sam": time.Saturday,
+			},
+			Months: map[string]time.Month{
+				"janvier": time.January, "février": time.February, "fevrier": time.February, "mars": time.March,
+				"avril": time.April, "mai": time.May, "juin": time.June, "juillet": time.July, "août": time.August,
+				"aout": time.August, "septembre": time.September, "octobre": time.October, "novembre": time.November,
+				"décembre": time.December, "decembre": time.December,
+			},
+		},
+		"de": {
+			Weekdays: map[string]time.Weekday{
+				"sonntag": time.Sunday, "montag": time.Monday, "dienstag": time.Tuesday, "mittwoch": time.Wednesday,
+				"donnerstag": time.Thursday, "freitag": time.Friday, "samstag": time.Saturday, "sonnabend": time.Saturday,
+				"so": time.Sunday, "mo": time.Monday, "di": time.Tuesday, "mi": time.Wednesday, "do": time.Thursday,
+				"fr": time.Friday, "sa": time.Saturday,
+			},
+			Months: map[string]time.Month{
+				"januar": time.January, "jänner": time.January, "februar": time.February, "märz": time.March,
+				"maerz": time.March, "april": time.April, "mai": time.May, "juni": time.June, "juli": time.July,
+				"august": time.August, "september": time.September, "oktober": time.October, "november": time.November,
+				"dezember": time.December,
+			},
+		},
+		"es": {
+			Weekdays: map[string]time.Weekday{
+				"domingo": time.Sunday, "lunes": time.Monday, "martes": time.Tuesday, "miércoles": time.Wednesday,
+				"miercoles": time.Wednesday, "jueves": time.Thursday, "viernes": time.Friday, "sábado": time.Saturday,
+				"sabado": time.Saturday,
+			},
+			Months: map[string]time.Month{
+				"enero": time.January, "febrero": time.February, "marzo": time.March, "abril": time.April,
+				"mayo": time.May, "junio": time.June, "julio": time.July, "agosto": time.August,
+				"septiembre": time.September, "setiembre": time.September, "octubre": time.October,
+				"noviembre": time.November, "diciembre": time.December,
+			},
+		},
+		"it": {
+			Weekdays: map[string]time.Weekday{
+				"domenica": time.Sunday, "lunedì": time.Monday, "lunedi": time.Monday, "martedì": time.Tuesday,
+				"martedi": time.Tuesday, "mercoledì": time.Wednesday, "mercoledi": time.Wednesday,
+				"giovedì": time.Thursday, "giovedi": time.Thursday, "venerdì": time.Friday, "venerdi": time.Friday,
+				"sabato": time.Saturday,
+			},
+			Months: map[string]time.Month{
+				"gennaio": time.January, "febbraio": time.February, "marzo": time.March, "aprile": time.April,
+				"maggio": time.May, "giugno": time.June, "luglio": time.July, "agosto": time.August,
+				"settembre": time.September, "ottobre": time.October, "novembre": time.November,
+				"dicembre": time.December,
+			},
+		},
+	}
+)
+
+// RegisterLocale makes a locale available to intervals under the given name, replacing any existing locale with that
+// name. French (fr), German (de), Spanish (es) and Italian (it) are available without being registered.
+func RegisterLocale(name string, l Locale) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[strings.ToLower(name)] = l
+}
+
+// lookupLocale returns the locale registered under the given name.
+func lookupLocale(name string) (Locale, bool) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	l, ok := locales[strings.ToLower(name)]
+	return l, ok
+}
+
+// takeLocale removes the locale key from an interval's fields, returning the locale it names or nil if there isn't one.
+func takeLocale(fields yaml.MapSlice) (*Locale, yaml.MapSlice, error) {
+	for i, field := range fields {
+		if key, _ := field.Key.(string); key != "locale" {
+			continue
+		}
+		name, ok := field.Value.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("%v is not a valid locale", field.Value)
+		}
+		l, ok := lookupLocale(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s is not a known locale", name)
+		}
+		rest := append(yaml.MapSlice{}, fields[:i]...)
+		return &l, append(rest, fields[i+1:]...), nil
+	}
+	return nil, fields, nil
+}
+
+// translate replaces weekday and month names in the list of values given for a field with their English equivalents.
+// Values that aren't names in the locale are left unchanged.
+func (l Locale) translate(field string, list []interface{}) []interface{} {
+	var name func(string) (string, bool)
+	switch field {
+	case "weekdays":
+		name = func(s string) (string, bool) {
+			wd, ok := l.Weekdays[s]
+			return daysOfWeekInv[int(wd)], ok
+		}
+	case "months":
+		name = func(s string) (string, bool) {
+			m, ok := l.Months[s]
+			return monthsInv[int(m)], ok
+		}
+	default:
+		return list
+	}
+	out := make([]interface{}, len(list))
+	for i, v := range list {
+		out[i] = v
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		components := strings.Split(strings.ToLower(str), ":")
+		translated := false
+		for j, c := range components {
+			if english, ok := name(strings.TrimSpace(c)); ok {
+				components[j] = english
+				translated = true
+			}
+		}
+		if translated {
+			out[i] = strings.Join(components, ":")
+		}
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var localeTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	expectError bool
+}{
+	{
+		in: "{locale: 'fr', weekdays: ['lundi:vendredi'], months: ['Janvier:mars', 'août']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Months:   []MonthRange{{InclusiveRange{Begin: 1, End: 3}}, {InclusiveRange{Begin: 8, End: 8}}},
+		},
+	},
+	{
+		// English names and keywords still work
+		in: "{locale: 'de', weekdays: ['montag:freitag', 'saturday', 'weekend'], months: ['dezember']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}, {InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}},
+			Months:   []MonthRange{{InclusiveRange{Begin: 12, End: 12}}},
+		},
+	},
+	{
+		// Names are only understood in the interval's locale
+		in:          "weekdays: ['lundi']",
+		expectError: true,
+	},
+	{
+		in:          "{locale: 'xx', weekdays: ['monday']}",
+		expectError: true,
+	},
+}
+
+func TestLocale(t *testing.T) {
+	for _, tc := range localeTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+		} else if err == nil && !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+	}
+}
+
+func TestRegisterLocale(t *testing.T) {
+	RegisterLocale("NL", Locale{
+		Weekdays: map[string]time.Weekday{"maandag": time.Monday, "vrijdag": time.Friday},
+	})
+	var ti TimeInterval
+	if err := yaml.Unmarshal([]byte("{locale: 'nl', weekdays: ['maandag:vrijdag']}"), &ti); err != nil {
+		t.Fatal(err)
+	}
+	want := []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}
+	if !reflect.DeepEqual(ti.Weekdays, want) {
+		t.Errorf("Want %+v, got %+v", want, ti.Weekdays)
+	}
+}
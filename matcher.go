@@ -0,0 +1,68 @@
+package gotime
+
+import "time"
+
+// scanHorizon bounds how far transition queries will scan a Matcher that can't locate its own transitions.
+const scanHorizon = 7 * 24 * time.Hour
+
+// A Matcher decides whether points in time fall within a schedule. TimeInterval implements Matcher, and the And, Or
+// and Not combinators can compose it with any other implementation, such as a holiday calendar or a feature flag.
+type Matcher interface {
+	ContainsTime(t time.Time) bool
+}
+
+// The MatcherFunc type is an adapter to allow the use of ordinary functions as Matchers.
+type MatcherFunc func(t time.Time) bool
+
+// ContainsTime calls f(t).
+func (f MatcherFunc) ContainsTime(t time.Time) bool {
+	return f(t)
+}
+
+// A TransitionMatcher is a Matcher that can also locate the points in time at which it starts or stops containing
+// time. Every Matcher provided by this package is a TransitionMatcher.
+type TransitionMatcher interface {
+	Matcher
+	// NextTransition returns the earliest time after t at which the matcher changes state, or false if there is none
+	// within the search horizon.
+	NextTransition(t time.Time) (time.Time, bool)
+	// PreviousTransition returns the latest time at or before t at which the matcher changed state, or false if there
+	// is none within the search horizon.
+	PreviousTransition(t time.Time) (time.Time, bool)
+}
+
+// asTransitionMatcher returns m as a TransitionMatcher, falling back to scanning for transitions one minute at a time
+// if m can't locate them itself.
+func asTransitionMatcher(m Matcher) TransitionMatcher {
+	if tm, ok := m.(TransitionMatcher); ok {
+		return tm
+	}
+	return scanningMatcher{m}
+}
+
+// A scanningMatcher locates the transitions of a Matcher by evaluating it at the start of every minute, giving up
+// once scanHorizon has been searched.
+type scanningMatcher struct {
+	Matcher
+}
+
+func (sm scanningMatcher) NextTransition(t time.Time) (time.Time, bool) {
+	state := sm.ContainsTime(t)
+	limit := t.Add(scanHorizon)
+	for cur := t.Truncate(time.Minute).Add(time.Minute); !cur.After(limit); cur = cur.Add(time.Minute) {
+		if sm.ContainsTime(cur) != state {
+			return cur, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (sm scanningMatcher) PreviousTransition(t time.Time) (time.Time, bool) {
+	limit := t.Add(-scanHorizon)
+	for cur := t.Truncate(time.Minute); !cur.Before(limit); cur = cur.Add(-time.Minute) {
+		if isTransition(sm, cur) {
+			return cur, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,84 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+)
+
+// A custom matcher for the 4th of July that doesn't know how to find its own transitions.
+var independenceDay = MatcherFunc(func(t time.Time) bool {
+	return t.Month() == time.July && t.Day() == 4
+})
+
+var combinatorTestCases = []struct {
+	matcher  TransitionMatcher
+	contains []string
+	excludes []string
+	at       string
+	next     string
+	previous string
+}{
+	{
+		// Business hours, except on the 4th of July
+		matcher:  And(businessHours, Not(independenceDay)),
+		contains: []string{"2019-07-03T10:00:00Z", "2019-07-05T10:00:00Z"},
+		excludes: []string{"2019-07-04T10:00:00Z", "2019-07-03T18:00:00Z"},
+		at:       "2019-07-03T18:00:00Z",
+		next:     "2019-07-05T09:00:00Z",
+		previous: "2019-07-03T17:00:00Z",
+	},
+	{
+		// Business hours, or all day on the 4th of July
+		matcher:  Or(businessHours, independenceDay),
+		contains: []string{"2020-07-03T10:00:00Z", "2020-07-04T22:00:00Z"},
+		excludes: []string{"2020-07-03T18:00:00Z", "2020-07-05T10:00:00Z"},
+		at:       "2020-07-03T10:00:00Z",
+		next:     "2020-07-03T17:00:00Z",
+		previous: "2020-07-03T09:00:00Z",
+	},
+	{
+		// Business hours, or all day on the 4th of July
+		matcher:  Or(businessHours, independenceDay),
+		at:       "2020-07-03T18:00:00Z",
+		next:     "2020-07-04T00:00:00Z",
+		previous: "2020-07-03T17:00:00Z",
+	},
+	{
+		matcher:  Not(Or(businessHours, Not(businessHours))),
+		excludes: []string{"2020-07-03T10:00:00Z", "2020-07-03T18:00:00Z"},
+		at:       "2020-07-03T18:00:00Z",
+		next:     "",
+		previous: "",
+	},
+	{
+		// An empty Intersection matches everything, and an empty Union nothing
+		matcher:  And(),
+		contains: []string{"2020-07-03T10:00:00Z"},
+		at:       "2020-07-03T18:00:00Z",
+	},
+	{
+		matcher:  Or(),
+		excludes: []string{"2020-07-03T10:00:00Z"},
+		at:       "2020-07-03T18:00:00Z",
+	},
+}
+
+func TestCombinators(t *testing.T) {
+	for _, tc := range combinatorTestCases {
+		for _, ts := range tc.contains {
+			if !tc.matcher.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %+v to contain %s", tc.matcher, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if tc.matcher.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %+v to exclude %s", tc.matcher, ts)
+			}
+		}
+		at := mustParseTime(tc.at)
+		next, ok := tc.matcher.NextTransition(at)
+		checkTransition(t, "NextTransition", tc.matcher, tc.next, next, ok)
+		previous, ok := tc.matcher.PreviousTransition(at)
+		checkTransition(t, "PreviousTransition", tc.matcher, tc.previous, previous, ok)
+	}
+}
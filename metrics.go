@@ -0,0 +1,88 @@
+package gotime
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricFamilies are the metrics written by WriteMetrics, with their help text.
+var metricFamilies = []struct {
+	name, help string
+}{
+	{"gotime_interval_active", "Whether the named interval is active, 1 if it is and 0 if not."},
+	{"gotime_interval_next_activation_timestamp_seconds",
+		"When the named interval next becomes active, in seconds since the Unix epoch."},
+	{"gotime_interval_next_deactivation_timestamp_seconds",
+		"When the named interval next stops being active, in seconds since the Unix epoch."},
+}
+
+// WriteMetrics writes the state of each definition at t in the Prometheus text exposition format: a gauge saying
+// whether it's active, and the times at which it next becomes active and stops being active, e.g.
+//
+//	gotime_interval_active{name="maintenance"} 0
+//	gotime_interval_next_activation_timestamp_seconds{name="maintenance"} 1719907200
+//
+// Times that don't occur within the search horizon are left out. Definitions are written in order of name.
+func WriteMetrics(w io.Writer, d Definitions, t time.Time) error {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([][]string, len(metricFamilies))
+	for _, name := range names {
+		label := fmt.Sprintf(`{name="%s"}`, escapeLabel(name))
+		state := d[name].StateAt(t)
+		values[0] = append(values[0], fmt.Sprintf("%s %d", label, boolInt(state.Active)))
+		if state.NextActivation != nil {
+			values[1] = append(values[1], label+" "+unixSeconds(*state.NextActivation))
+		}
+		if state.NextDeactivation != nil {
+			values[2] = append(values[2], label+" "+unixSeconds(*state.NextDeactivation))
+		}
+	}
+	bw := bufio.NewWriter(w)
+	for i, family := range metricFamilies {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", family.name, family.help, family.name)
+		for _, v := range values[i] {
+			fmt.Fprintf(bw, "%s%s\n", family.name, v)
+		}
+	}
+	return bw.Flush()
+}
+
+// MetricsHandler returns a handler that serves the state of each definition at the time of the request in the
+// Prometheus text exposition format, for Prometheus to scrape directly. The definitions are fixed when the handler is
+// made, so definitions that are reloaded should be kept in a Registry and served with its MetricsHandler instead.
+func MetricsHandler(d Definitions) http.Handler {
+	return metricsHandler(func() Definitions { return d })
+}
+
+// MetricsHandler returns a handler that serves the state of each definition in the registry at the time of the
+// request, as the package's MetricsHandler does, so that changes to the registry are seen by the next scrape.
+func (r *Registry) MetricsHandler() http.Handler {
+	return metricsHandler(r.Definitions)
+}
+
+func metricsHandler(defs func() Definitions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteMetrics(w, defs(), time.Now())
+	})
+}
+
+// unixSeconds formats t as seconds since the Unix epoch.
+func unixSeconds(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
+
+// escapeLabel escapes a label value for the Prometheus text exposition format.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,67 @@
+package gotime
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	d := Definitions{
+		"business_hours": IntervalSet{{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+		}},
+		`say "hi"`: IntervalSet{{}},
+		"never":     IntervalSet{{Weekdays: []WeekdayRange{}}},
+	}
+	// 2024-07-01 is a Monday.
+	at := time.Date(2024, time.July, 1, 10, 0, 0, 0, time.UTC)
+	var b strings.Builder
+	if err := WriteMetrics(&b, d, at); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP gotime_interval_active Whether the named interval is active, 1 if it is and 0 if not.
+# TYPE gotime_interval_active gauge
+gotime_interval_active{name="business_hours"} 1
+gotime_interval_active{name="never"} 0
+gotime_interval_active{name="say \"hi\""} 1
+# HELP gotime_interval_next_activation_timestamp_seconds When the named interval next becomes active, in seconds since the Unix epoch.
+# TYPE gotime_interval_next_activation_timestamp_seconds gauge
+gotime_interval_next_activation_timestamp_seconds{name="business_hours"} 1719910800
+# HELP gotime_interval_next_deactivation_timestamp_seconds When the named interval next stops being active, in seconds since the Unix epoch.
+# TYPE gotime_interval_next_deactivation_timestamp_seconds gauge
+gotime_interval_next_deactivation_timestamp_seconds{name="business_hours"} 1719853200
+`
+	if b.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, b.String())
+	}
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(d).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the text exposition format, got %s", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `gotime_interval_active{name="never"} 0`) {
+		t.Errorf("Expected the handler to serve metrics, got\n%s", rec.Body)
+	}
+}
+
+func TestRegistryMetricsHandler(t *testing.T) {
+	r := NewRegistry(Definitions{"always": IntervalSet{{}}})
+	h := r.MetricsHandler()
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		return rec.Body.String()
+	}
+	if body := scrape(); !strings.Contains(body, `gotime_interval_active{name="always"} 1`) {
+		t.Errorf("Expected the registered definition to be served, got\n%s", body)
+	}
+	r.Swap(Definitions{"never": IntervalSet{{Weekdays: []WeekdayRange{}}}})
+	body := scrape()
+	if strings.Contains(body, `name="always"`) || !strings.Contains(body, `gotime_interval_active{name="never"} 0`) {
+		t.Errorf("Expected the swapped definitions to be served, got\n%s", body)
+	}
+}
@@ -0,0 +1,292 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DocumentVersion is the version of the document format read and written by this package. A document may give the
+// version it was written for with a version key, either at the top of a document of Definitions or in an interval,
+// and documents of older versions are upgraded as they are parsed. Documents without a version are read as the
+// current version. Version 1 is the format before versions were introduced, which allowed a days key holding both
+// weekdays and days of the month, so unversioned documents that still use it must be upgraded with Migrate or given
+// a version key.
+const DocumentVersion = 2
+
+// migrations upgrade the fields of an interval from the version at their index plus one to the next, returning the
+// changes they made. Changes are given relative to the interval.
+var migrations = []func(fields yaml.MapSlice) (yaml.MapSlice, []MigrationChange){
+	migrateDays,
+}
+
+// A MigrationChange describes a change made to a document while upgrading it to the current version.
+type MigrationChange struct {
+	// Path locates the changed value, e.g. business_hours[0].except[1].days.
+	Path    string
+	Message string
+}
+
+func (c MigrationChange) String() string {
+	if c.Path == "" {
+		return c.Message
+	}
+	return fmt.Sprintf("%s: %s", c.Path, c.Message)
+}
+
+// Migrate upgrades a YAML document holding Definitions, a list of intervals or a single interval to the current
+// version, returning the upgraded document along with each change made. Documents without a version key are taken to
+// be version 1, and an error is returned for any written for a newer version than this package understands. Comments
+// and formatting aren't kept.
+func Migrate(data []byte) ([]byte, []MigrationChange, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	// Mappings are read again as MapSlices to keep the order of their keys.
+	switch doc.(type) {
+	case []interface{}:
+		var list []migrationItem
+		if err := yaml.Unmarshal(data, &list); err != nil {
+			return nil, nil, err
+		}
+		items := make([]interface{}, len(list))
+		for i, item := range list {
+			items[i] = item.value
+		}
+		doc = items
+	case map[interface{}]interface{}:
+		var fields yaml.MapSlice
+		if err := yaml.Unmarshal(data, &fields); err != nil {
+			return nil, nil, err
+		}
+		doc = fields
+	}
+	var changes []MigrationChange
+	switch v := doc.(type) {
+	case []interface{}:
+		changes = migrateList(v, "", 1)
+	case yaml.MapSlice:
+		version, fields, err := takeVersion(v, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isIntervalDocument(fields) {
+			fields, changes = migrateInterval(fields, "", version)
+		} else {
+			for i, item := range fields {
+				var c []MigrationChange
+				fields[i].Value, c = migrateDefinition(item.Value, fmt.Sprint(item.Key), version)
+				changes = append(changes, c...)
+			}
+		}
+		if version < DocumentVersion {
+			changes = append(changes, MigrationChange{
+				Message: fmt.Sprintf("Upgraded from version %d to version %d", version, DocumentVersion),
+			})
+		}
+		doc = append(yaml.MapSlice{{Key: "version", Value: DocumentVersion}}, fields...)
+	default:
+		return nil, nil, errors.New("Couldn't migrate document, expected a mapping or a list of intervals")
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, changes, nil
+}
+
+// A migrationItem is an item of a list of intervals, holding a MapSlice if the item is a mapping.
+type migrationItem struct {
+	value interface{}
+}
+
+func (m *migrationItem) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var fields yaml.MapSlice
+	if err := unmarshal(&fields); err == nil {
+		m.value = fields
+		return nil
+	}
+	return unmarshal(&m.value)
+}
+
+// takeVersion removes the version key from a document or interval's fields, returning the version it gives or def if
+// there isn't one.
+func takeVersion(fields yaml.MapSlice, def int) (int, yaml.MapSlice, error) {
+	for i, field := range fields {
+		if key, _ := field.Key.(string); key != "version" {
+			continue
+		}
+		version, ok := field.Value.(int)
+		if !ok || version < 1 {
+			return 0, nil, fmt.Errorf("%v is not a valid document version", field.Value)
+		}
+		if version > DocumentVersion {
+			return 0, nil, fmt.Errorf("Unable to read version %d documents, the latest supported version is %d",
+				version, DocumentVersion)
+		}
+		rest := append(yaml.MapSlice{}, fields[:i]...)
+		return version, append(rest, fields[i+1:]...), nil
+	}
+	return def, fields, nil
+}
+
+// isIntervalDocument returns true if every key of a mapping is a field or setting of an interval, rather than the
+// name of a definition.
+func isIntervalDocument(fields yaml.MapSlice) bool {
+	for _, field := range fields {
+		key, _ := field.Key.(string)
+		switch key {
+		case "days", "locale", "weekend", "repeat":
+			continue
+		}
+		if !intervalFields[key] {
+			return false
+		}
+	}
+	return len(fields) > 0
+}
+
+// migrateDefinition upgrades the intervals of a definition, which may be a list or a single interval. References to
+// other definitions are left as they are.
+func migrateDefinition(value interface{}, path string, version int) (interface{}, []MigrationChange) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, migrateList(v, path, version)
+	case yaml.MapSlice:
+		return migrateInterval(v, path, version)
+	}
+	return value, nil
+}
+
+// migrateList upgrades each interval of a list in place.
+func migrateList(list []interface{}, path string, version int) []MigrationChange {
+	var changes []MigrationChange
+	for i, item := range list {
+		fields, ok := item.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		var c []MigrationChange
+		list[i], c = migrateInterval(fields, fmt.Sprintf("%s[%d]", path, i), version)
+		changes = append(changes, c...)
+	}
+	return changes
+}
+
+// migrateInterval upgrades an interval and its exceptions from the given version to the current one. An interval or
+// exception may give a version of its own, which takes the place of the version of the document around it.
+func migrateInterval(fields yaml.MapSlice, path string, version int) (yaml.MapSlice, []MigrationChange) {
+	version, rest, err := takeVersion(fields, version)
+	if err != nil {
+		// Invalid versions are left for the interval to report when it's parsed.
+		return fields, nil
+	}
+	fields = rest
+	var changes []MigrationChange
+	for v := version; v < DocumentVersion; v++ {
+		var c []MigrationChange
+		fields, c = migrations[v-1](fields)
+		for _, change := range c {
+			change.Path = joinPath(path, change.Path)
+			changes = append(changes, change)
+		}
+	}
+	for _, field := range fields {
+		if key, _ := field.Key.(string); key == "except" {
+			if list, ok := field.Value.([]interface{}); ok {
+				changes = append(changes, migrateList(list, joinPath(path, "except"), version)...)
+			}
+		}
+	}
+	return fields, changes
+}
+
+func joinPath(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+// migrateDays moves the values of the days key, which version 1 documents used for both weekdays and days of the
+// month, into the weekdays and days_of_month fields. Numbers, including negative days and ranges of them, and weekdays
+// of the month such as 'tue#2' are days of the month, and anything else is taken to be a weekday name, range or
+// keyword.
+func migrateDays(fields yaml.MapSlice) (yaml.MapSlice, []MigrationChange) {
+	if !hasKey(fields, "days") {
+		return fields, nil
+	}
+	var days, weekdays, daysOfMonth []interface{}
+	out := yaml.MapSlice{}
+	for _, field := range fields {
+		if key, _ := field.Key.(string); key != "days" {
+			out = append(out, field)
+			continue
+		}
+		if days, _ = field.Value.([]interface{}); days == nil && field.Value != nil {
+			days = []interface{}{field.Value}
+		}
+	}
+	for _, day := range days {
+		if isDayOfMonthValue(day) {
+			daysOfMonth = append(daysOfMonth, day)
+		} else {
+			weekdays = append(weekdays, day)
+		}
+	}
+	var changes []MigrationChange
+	for _, move := range []struct {
+		key    string
+		values []interface{}
+	}{{"weekdays", weekdays}, {"days_of_month", daysOfMonth}} {
+		if len(move.values) == 0 {
+			continue
+		}
+		out = appendToList(out, move.key, move.values)
+		changes = append(changes, MigrationChange{
+			Path:    "days",
+			Message: fmt.Sprintf("Moved %s to %s", formatValues(move.values), move.key),
+		})
+	}
+	if len(days) == 0 {
+		changes = append(changes, MigrationChange{Path: "days", Message: "Removed empty days"})
+	}
+	return out, changes
+}
+
+// isDayOfMonthValue returns true if a value of the legacy days key is a day of the month rather than a weekday.
+func isDayOfMonthValue(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return true
+	}
+	s = strings.TrimSpace(s)
+	return s != "" && (strings.IndexAny(s[:1], "-0123456789") == 0 || isWeekdayOfMonth(s))
+}
+
+// appendToList adds values to the end of the list held by the given key, adding the key if there isn't one.
+func appendToList(fields yaml.MapSlice, key string, values []interface{}) yaml.MapSlice {
+	for i, field := range fields {
+		if field.Key != key {
+			continue
+		}
+		list, ok := field.Value.([]interface{})
+		if !ok && field.Value != nil {
+			list = []interface{}{field.Value}
+		}
+		fields[i].Value = append(list, values...)
+		return fields
+	}
+	return append(fields, yaml.MapItem{Key: key, Value: values})
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
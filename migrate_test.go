@@ -0,0 +1,125 @@
+package gotime
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestMigrate(t *testing.T) {
+	for _, c := range []struct {
+		in      string
+		out     string
+		changes []string
+	}{
+		{
+			in: "business_hours:\n- days: [monday:friday, 1, '-7:-1']\n  times: [{start_time: '09:00', end_time: '17:00'}]\n" +
+				"  except:\n  - days: [weekend, tue#2]\n    weekdays: [friday]\n",
+			out: "version: 2\nbusiness_hours:\n- times:\n  - start_time: \"09:00\"\n    end_time: \"17:00\"\n" +
+				"  except:\n  - weekdays:\n    - friday\n    - weekend\n    days_of_month:\n    - tue#2\n" +
+				"  weekdays:\n  - monday:friday\n  days_of_month:\n  - 1\n  - -7:-1\n",
+			changes: []string{
+				"business_hours[0].days: Moved monday:friday to weekdays",
+				"business_hours[0].days: Moved 1, -7:-1 to days_of_month",
+				"business_hours[0].except[0].days: Moved weekend to weekdays",
+				"business_hours[0].except[0].days: Moved tue#2 to days_of_month",
+				"Upgraded from version 1 to version 2",
+			},
+		},
+		{
+			in:      "days: [saturday]\nlocation: UTC\n",
+			out:     "version: 2\nlocation: UTC\nweekdays:\n- saturday\n",
+			changes: []string{"days: Moved saturday to weekdays", "Upgraded from version 1 to version 2"},
+		},
+		{
+			in:      "- days: [15]\n- weekdays: [monday]\n",
+			out:     "- days_of_month:\n  - 15\n- weekdays:\n  - monday\n",
+			changes: []string{"[0].days: Moved 15 to days_of_month"},
+		},
+		{
+			in:  "version: 2\nholidays:\n  months: [december]\n",
+			out: "version: 2\nholidays:\n  months:\n  - december\n",
+		},
+	} {
+		out, changes, err := Migrate([]byte(c.in))
+		if err != nil {
+			t.Errorf("Error migrating\n%s\n%v", c.in, err)
+			continue
+		}
+		if string(out) != c.out {
+			t.Errorf("Expected\n%s\nto migrate to\n%s\ngot\n%s", c.in, c.out, out)
+		}
+		var got []string
+		for _, change := range changes {
+			got = append(got, change.String())
+		}
+		if !reflect.DeepEqual(got, c.changes) {
+			t.Errorf("Expected changes %q migrating\n%s\ngot %q", c.changes, c.in, got)
+		}
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	for _, in := range []string{
+		"version: 3\nholidays:\n  months: [december]\n",
+		"version: latest\n",
+		"version: 0\n",
+		"just a string\n",
+	} {
+		if out, _, err := Migrate([]byte(in)); err == nil {
+			t.Errorf("Expected an error migrating\n%s\ngot\n%s", in, out)
+		}
+	}
+}
+
+func TestUnmarshalVersioned(t *testing.T) {
+	var d Definitions
+	doc := "version: 1\nweekends:\n- days: [saturday, sunday, 1]\n  except:\n  - days: [sunday]\n"
+	if err := yaml.UnmarshalStrict([]byte(doc), &d); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		at    string
+		match bool
+	}{
+		{"2024-06-01T10:00:00Z", true},
+		{"2024-06-08T10:00:00Z", false},
+		{"2024-09-01T10:00:00Z", false},
+	} {
+		at, err := time.Parse(time.RFC3339, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d["weekends"].ContainsTime(at); got != c.match {
+			t.Errorf("Expected weekends to contain %s: %t, got %t", c.at, c.match, got)
+		}
+	}
+	var ti TimeInterval
+	if err := yaml.UnmarshalStrict([]byte("version: 1\ndays: [monday]\n"), &ti); err != nil {
+		t.Fatal(err)
+	}
+	if len(ti.Weekdays) != 1 || ti.Weekdays[0].Begin != 1 {
+		t.Errorf("Expected days to be read as weekdays, got %+v", ti)
+	}
+	if err := yaml.UnmarshalStrict([]byte("version: 2\nweekdays: [monday]\n"), &ti); err != nil {
+		t.Errorf("Expected current versions to be accepted, got %v", err)
+	}
+	for _, doc := range []string{"days: [monday]\n", "version: 2\ndays: [monday]\n", "version: 5\nweekdays: [monday]\n"} {
+		if err := yaml.UnmarshalStrict([]byte(doc), &ti); err == nil {
+			t.Errorf("Expected an error unmarshalling\n%s", doc)
+		}
+	}
+	// Unversioned legacy documents mustn't lose their days key when unmarshalled without checking unknown fields.
+	for _, doc := range []string{"days: [saturday]\n", "except:\n- days: [sunday]\n"} {
+		var legacy TimeInterval
+		if err := yaml.Unmarshal([]byte(doc), &legacy); err == nil || !strings.Contains(err.Error(), "Migrate") {
+			t.Errorf("Expected an error naming Migrate unmarshalling\n%s\ngot %v, %+v", doc, err, legacy)
+		}
+	}
+	if err := yaml.Unmarshal([]byte("weekends:\n- days: [saturday, sunday]\n"), &d); err == nil {
+		t.Errorf("Expected an error unmarshalling unversioned Definitions with a days key")
+	}
+}
@@ -0,0 +1,50 @@
+package gotime
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Mode decides whether an interval in an IntervalSet adds the times it contains to the set or takes them away.
+// Intervals on their own contain the same times whatever their mode.
+type Mode int
+
+const (
+	// ModeAllow adds the times an interval contains to its set.
+	ModeAllow Mode = iota
+	// ModeDeny takes the times an interval contains away from its set, unless a later interval allows them again.
+	ModeDeny
+)
+
+var modes = map[string]Mode{
+	"allow": ModeAllow,
+	"deny":  ModeDeny,
+}
+
+var modesInv = map[Mode]string{
+	ModeAllow: "allow",
+	ModeDeny:  "deny",
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for Mode.
+func (m *Mode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	mode, ok := modes[strings.ToLower(str)]
+	if !ok {
+		return fmt.Errorf("%s is not a valid mode", str)
+	}
+	*m = mode
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for Mode
+func (m Mode) MarshalYAML() (interface{}, error) {
+	str, ok := modesInv[m]
+	if !ok {
+		return nil, fmt.Errorf("Unable to convert %d into mode", m)
+	}
+	return interface{}(str), nil
+}
@@ -0,0 +1,378 @@
+package gotime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// MessagePack formats, other than the fixed ones that hold their value or length in the first byte.
+const (
+	msgpackNil      = 0xc0
+	msgpackFalse    = 0xc2
+	msgpackTrue     = 0xc3
+	msgpackBin8     = 0xc4
+	msgpackBin16    = 0xc5
+	msgpackBin32    = 0xc6
+	msgpackExt8     = 0xc7
+	msgpackExt16    = 0xc8
+	msgpackExt32    = 0xc9
+	msgpackFloat32  = 0xca
+	msgpackFloat64  = 0xcb
+	msgpackUint8    = 0xcc
+	msgpackUint16   = 0xcd
+	msgpackUint32   = 0xce
+	msgpackUint64   = 0xcf
+	msgpackInt8     = 0xd0
+	msgpackInt16    = 0xd1
+	msgpackInt32    = 0xd2
+	msgpackInt64    = 0xd3
+	msgpackFixExt1  = 0xd4
+	msgpackFixExt16 = 0xd8
+	msgpackStr8     = 0xd9
+	msgpackStr16    = 0xda
+	msgpackStr32    = 0xdb
+	msgpackArray16  = 0xdc
+	msgpackArray32  = 0xdd
+	msgpackMap16    = 0xde
+	msgpackMap32    = 0xdf
+)
+
+// msgpackTimestamp is the extension type of MessagePack timestamps.
+const msgpackTimestamp = -1
+
+var errInvalidMsgpack = errors.New("Couldn't parse MessagePack value, invalid format")
+
+// MarshalMsgpack implements the msgpack.Marshaler interface of github.com/vmihailenco/msgpack for TimeInterval. The
+// interval is encoded as a map with the same fields as its YAML form.
+func (tp TimeInterval) MarshalMsgpack() ([]byte, error) {
+	out, err := yaml.Marshal(tp)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeMsgpack(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMsgpack implements the msgpack.Unmarshaler interface of github.com/vmihailenco/msgpack for TimeInterval.
+// The map is validated in the same way as YAML, so invalid intervals are rejected when they are decoded.
+func (tp *TimeInterval) UnmarshalMsgpack(data []byte) error {
+	value, rest, err := readMsgpack(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errInvalidMsgpack
+	}
+	if _, ok := value.(yaml.MapSlice); !ok {
+		return errors.New("Couldn't parse MessagePack value, expected a map")
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var decoded TimeInterval
+	if err := yaml.Unmarshal(out, &decoded); err != nil {
+		return err
+	}
+	*tp = decoded
+	return nil
+}
+
+// writeMsgpack writes a value produced by unmarshalling YAML, in the smallest format that holds it.
+func writeMsgpack(buf *bytes.Buffer, value interface{}) error {
+	var b [8]byte
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(msgpackNil)
+	case bool:
+		if v {
+			buf.WriteByte(msgpackTrue)
+		} else {
+			buf.WriteByte(msgpackFalse)
+		}
+	case int:
+		switch {
+		case v >= -32 && v <= math.MaxInt8:
+			buf.WriteByte(byte(int8(v)))
+		case v >= 0 && v <= math.MaxUint8:
+			buf.WriteByte(msgpackUint8)
+			buf.WriteByte(byte(v))
+		case v >= 0 && v <= math.MaxUint16:
+			buf.WriteByte(msgpackUint16)
+			binary.BigEndian.PutUint16(b[:], uint16(v))
+			buf.Write(b[:2])
+		case v >= 0 && v <= math.MaxUint32:
+			buf.WriteByte(msgpackUint32)
+			binary.BigEndian.PutUint32(b[:], uint32(v))
+			buf.Write(b[:4])
+		case v >= math.MinInt8 && v < 0:
+			buf.WriteByte(msgpackInt8)
+			buf.WriteByte(byte(int8(v)))
+		case v >= math.MinInt16 && v < 0:
+			buf.WriteByte(msgpackInt16)
+			binary.BigEndian.PutUint16(b[:], uint16(int16(v)))
+			buf.Write(b[:2])
+		case v >= math.MinInt32 && v < 0:
+			buf.WriteByte(msgpackInt32)
+			binary.BigEndian.PutUint32(b[:], uint32(int32(v)))
+			buf.Write(b[:4])
+		default:
+			buf.WriteByte(msgpackInt64)
+			binary.BigEndian.PutUint64(b[:], uint64(v))
+			buf.Write(b[:])
+		}
+	case uint64:
+		buf.WriteByte(msgpackUint64)
+		binary.BigEndian.PutUint64(b[:], v)
+		buf.Write(b[:])
+	case float64:
+		buf.WriteByte(msgpackFloat64)
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+		buf.Write(b[:])
+	case string:
+		writeMsgpackHead(buf, len(v), 0xa0, 32, msgpackStr8, msgpackStr16, msgpackStr32)
+		buf.WriteString(v)
+	case yaml.MapSlice:
+		writeMsgpackHead(buf, len(v), 0x80, 16, 0, msgpackMap16, msgpackMap32)
+		for _, item := range v {
+			if err := writeMsgpack(buf, item.Key); err != nil {
+				return err
+			}
+			if err := writeMsgpack(buf, item.Value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		writeMsgpackHead(buf, len(v), 0x90, 16, 0, msgpackArray16, msgpackArray32)
+		for _, element := range v {
+			if err := writeMsgpack(buf, element); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("Unable to convert %T into MessagePack", value)
+	}
+	return nil
+}
+
+// writeMsgpackHead writes the format and length of a string, array or map, using the fixed format if the length is
+// below fixedLimit and otherwise the smallest of the given formats, where a format of 0 isn't available.
+func writeMsgpackHead(buf *bytes.Buffer, n int, fixed byte, fixedLimit int, format8, format16, format32 byte) {
+	var b [4]byte
+	switch {
+	case n < fixedLimit:
+		buf.WriteByte(fixed | byte(n))
+	case format8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(format8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(format16)
+		binary.BigEndian.PutUint16(b[:], uint16(n))
+		buf.Write(b[:2])
+	default:
+		buf.WriteByte(format32)
+		binary.BigEndian.PutUint32(b[:], uint32(n))
+		buf.Write(b[:])
+	}
+}
+
+// readMsgpack reads a value from the start of data, returning it as a value like those produced by unmarshalling
+// YAML, and whatever follows it.
+func readMsgpack(data []byte) (interface{}, []byte, error) {
+	if len(data) < 1 {
+		return nil, nil, errInvalidMsgpack
+	}
+	format, data := data[0], data[1:]
+	fixed := func(n int) ([]byte, error) {
+		if len(data) < n {
+			return nil, errInvalidMsgpack
+		}
+		b := data[:n]
+		data = data[n:]
+		return b, nil
+	}
+	// length reads a length of the given number of bytes.
+	length := func(size int) (int, error) {
+		b, err := fixed(size)
+		if err != nil {
+			return 0, err
+		}
+		n := 0
+		for _, c := range b {
+			n = n<<8 | int(c)
+		}
+		return n, nil
+	}
+	var n int
+	var err error
+	switch {
+	case format <= 0x7f:
+		return int(format), data, nil
+	case format >= 0xe0:
+		return int(int8(format)), data, nil
+	case format >= 0xa0 && format <= 0xbf:
+		return readMsgpackString(data, int(format&0x1f))
+	case format >= 0x90 && format <= 0x9f:
+		return readMsgpackArray(data, int(format&0x0f))
+	case format >= 0x80 && format <= 0x8f:
+		return readMsgpackMap(data, int(format&0x0f))
+	}
+	switch format {
+	case msgpackNil:
+		return nil, data, nil
+	case msgpackFalse:
+		return false, data, nil
+	case msgpackTrue:
+		return true, data, nil
+	case msgpackUint8, msgpackUint16, msgpackUint32, msgpackUint64:
+		b, err := fixed(1 << (format - msgpackUint8))
+		if err != nil {
+			return nil, nil, err
+		}
+		var u uint64
+		for _, c := range b {
+			u = u<<8 | uint64(c)
+		}
+		if u > math.MaxInt64 {
+			return u, data, nil
+		}
+		return int(u), data, nil
+	case msgpackInt8, msgpackInt16, msgpackInt32, msgpackInt64:
+		size := 1 << (format - msgpackInt8)
+		b, err := fixed(size)
+		if err != nil {
+			return nil, nil, err
+		}
+		var u uint64
+		for _, c := range b {
+			u = u<<8 | uint64(c)
+		}
+		// Sign extend from the size of the value.
+		shift := uint(64 - 8*size)
+		return int(int64(u<<shift) >> shift), data, nil
+	case msgpackFloat32:
+		b, err := fixed(4)
+		if err != nil {
+			return nil, nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), data, nil
+	case msgpackFloat64:
+		b, err := fixed(8)
+		if err != nil {
+			return nil, nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), data, nil
+	case msgpackStr8, msgpackBin8:
+		n, err = length(1)
+	case msgpackStr16, msgpackBin16:
+		n, err = length(2)
+	case msgpackStr32, msgpackBin32:
+		n, err = length(4)
+	case msgpackArray16:
+		if n, err = length(2); err == nil {
+			return readMsgpackArray(data, n)
+		}
+	case msgpackArray32:
+		if n, err = length(4); err == nil {
+			return readMsgpackArray(data, n)
+		}
+	case msgpackMap16:
+		if n, err = length(2); err == nil {
+			return readMsgpackMap(data, n)
+		}
+	case msgpackMap32:
+		if n, err = length(4); err == nil {
+			return readMsgpackMap(data, n)
+		}
+	case msgpackExt8, msgpackExt16, msgpackExt32:
+		if n, err = length(1 << (format - msgpackExt8)); err == nil {
+			return readMsgpackExt(data, n)
+		}
+	default:
+		if format >= msgpackFixExt1 && format <= msgpackFixExt16 {
+			return readMsgpackExt(data, 1<<(format-msgpackFixExt1))
+		}
+		return nil, nil, fmt.Errorf("Unable to convert MessagePack format 0x%02x into a value", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return readMsgpackString(data, n)
+}
+
+func readMsgpackString(data []byte, n int) (interface{}, []byte, error) {
+	if n > len(data) {
+		return nil, nil, errInvalidMsgpack
+	}
+	return string(data[:n]), data[n:], nil
+}
+
+func readMsgpackArray(data []byte, n int) (interface{}, []byte, error) {
+	// Every element takes at least a byte, which bounds the length before anything is allocated.
+	if n > len(data) {
+		return nil, nil, errInvalidMsgpack
+	}
+	array := make([]interface{}, n)
+	for i := range array {
+		var err error
+		if array[i], data, err = readMsgpack(data); err != nil {
+			return nil, nil, err
+		}
+	}
+	return array, data, nil
+}
+
+func readMsgpackMap(data []byte, n int) (interface{}, []byte, error) {
+	if n > len(data)/2 {
+		return nil, nil, errInvalidMsgpack
+	}
+	doc := make(yaml.MapSlice, n)
+	for i := range doc {
+		var err error
+		if doc[i].Key, data, err = readMsgpack(data); err != nil {
+			return nil, nil, err
+		}
+		if doc[i].Value, data, err = readMsgpack(data); err != nil {
+			return nil, nil, err
+		}
+	}
+	return doc, data, nil
+}
+
+// readMsgpackExt reads an extension value of n bytes. Timestamps written by other applications become RFC 3339
+// strings, and other extensions aren't understood.
+func readMsgpackExt(data []byte, n int) (interface{}, []byte, error) {
+	if len(data) < n+1 {
+		return nil, nil, errInvalidMsgpack
+	}
+	typ, b, rest := int8(data[0]), data[1:n+1], data[n+1:]
+	if typ != msgpackTimestamp {
+		return nil, nil, fmt.Errorf("Unable to convert MessagePack extension type %d into a value", typ)
+	}
+	var t time.Time
+	switch n {
+	case 4:
+		t = time.Unix(int64(binary.BigEndian.Uint32(b)), 0)
+	case 8:
+		u := binary.BigEndian.Uint64(b)
+		t = time.Unix(int64(u&(1<<34-1)), int64(u>>34))
+	case 12:
+		t = time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b)))
+	default:
+		return nil, nil, errInvalidMsgpack
+	}
+	return t.UTC().Format(time.RFC3339Nano), rest, nil
+}
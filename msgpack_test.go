@@ -0,0 +1,79 @@
+package gotime
+
+import (
+	"bytes"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestMsgpack(t *testing.T) {
+	checkRoundTrip(t, TimeInterval.MarshalMsgpack, (*TimeInterval).UnmarshalMsgpack)
+}
+
+func TestMsgpackFormat(t *testing.T) {
+	ti := TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}}
+	data, err := ti.MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x81,
+		0xa8, 'w', 'e', 'e', 'k', 'd', 'a', 'y', 's',
+		0x91, 0xad, 'm', 'o', 'n', 'd', 'a', 'y', ':', 'f', 'r', 'i', 'd', 'a', 'y',
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Encoding %+v: want % x, got % x", ti, want, data)
+	}
+
+	// Values written by other encoders may use larger formats, single precision floats and timestamps.
+	var coords TimeInterval
+	err = coords.UnmarshalMsgpack([]byte{
+		0xde, 0x00, 0x02,
+		0xd9, 0x08, 'l', 'o', 'c', 'a', 't', 'i', 'o', 'n', 0xc4, 0x03, 'U', 'T', 'C',
+		0xab, 'c', 'o', 'o', 'r', 'd', 'i', 'n', 'a', 't', 'e', 's',
+		0x82, 0xa8, 'l', 'a', 't', 'i', 't', 'u', 'd', 'e', 0xca, 0x3f, 0xc0, 0x00, 0x00,
+		0xa9, 'l', 'o', 'n', 'g', 'i', 't', 'u', 'd', 'e', 0xd1, 0xff, 0x9c,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *coords.Coordinates != (Coordinates{Latitude: 1.5, Longitude: -100}) || coords.Location.String() != "UTC" {
+		t.Errorf("Decoded unexpected interval %+v", coords)
+	}
+	var absolute TimeInterval
+	err = absolute.UnmarshalMsgpack([]byte{
+		0x81, 0xa8, 'a', 'b', 's', 'o', 'l', 'u', 't', 'e', 0x91,
+		0x82, 0xa5, 's', 't', 'a', 'r', 't', 0xd6, 0xff, 0x65, 0x92, 0x00, 0x80,
+		0xa3, 'e', 'n', 'd', 0xd7, 0xff, 0x00, 0x00, 0x00, 0x00, 0x65, 0x92, 0x0e, 0x90,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := absolute.Absolute[0].End.Sub(absolute.Absolute[0].Start); got.Hours() != 1 {
+		t.Errorf("Expected timestamps an hour apart, got %v", absolute.Absolute)
+	}
+
+	var invalidData bytes.Buffer
+	if err := writeMsgpack(&invalidData, yaml.MapSlice{{Key: "weekdays", Value: []interface{}{"smarch"}}}); err != nil {
+		t.Fatal(err)
+	}
+	var invalid TimeInterval
+	for _, data := range [][]byte{
+		invalidData.Bytes(),
+		data[:10],
+		append(data, 0x00),
+		{0x91, 0x00},
+		{0xc1},
+		{0xd4, 0x01, 0x00},
+		{0xdd, 0xff, 0xff, 0xff, 0xff},
+	} {
+		if err := invalid.UnmarshalMsgpack(data); err == nil {
+			t.Errorf("Expected decoding % x to fail", data)
+		}
+	}
+}
+
+func TestMsgpackNeverActive(t *testing.T) {
+	checkNeverActive(t, TimeInterval.MarshalMsgpack, (*TimeInterval).UnmarshalMsgpack)
+}
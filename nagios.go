@@ -0,0 +1,453 @@
+package gotime
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Nagios checks its types of date exception in this order, and the first exception whose days include a time decides
+// whether the timeperiod contains it. Weekday directives only apply on days that no exception includes.
+const (
+	nagiosCalendarDate = iota
+	nagiosMonthDate
+	nagiosMonthDay
+	nagiosMonthWeekday
+	nagiosWeekday
+	nagiosExceptionTypes
+)
+
+var nagiosTimesRE = regexp.MustCompile(`[0-9]{1,2}:[0-9]{2}\s*-\s*[0-9]{1,2}:[0-9]{2}`)
+var nagiosTimeRangeRE = regexp.MustCompile(`^([0-9]{1,2}):([0-9]{2})\s*-\s*([0-9]{1,2}):([0-9]{2})$`)
+var nagiosRangeSeparatorRE = regexp.MustCompile(`\s+-\s+`)
+
+// A nagiosTimeperiod holds the directives of a timeperiod object, with its date exceptions grouped by type in the
+// order they were written.
+type nagiosTimeperiod struct {
+	name, alias string
+	excludes    []string
+	weekdays    IntervalSet
+	exceptions  [nagiosExceptionTypes][]nagiosException
+}
+
+// A nagiosException is a date exception of a timeperiod, holding intervals matching the whole of each day it includes
+// and the times it contains on those days.
+type nagiosException struct {
+	days  IntervalSet
+	times []TimeRange
+}
+
+// FromNagios reads the timeperiod objects of a Nagios or Icinga 1.x object configuration file, returning a definition
+// for each named after its timeperiod_name and described by its alias. Weekday directives such as 'monday
+// 09:00-17:00,18:00-20:00' and date exceptions such as 'day -1', 'thursday -1 november', 'december 25' or
+// '2024-01-01 - 2024-03-31 / 3' are converted into intervals, with exceptions taking precedence over weekdays and
+// each other as they do in Nagios, and the timeperiods named in exclude are taken away. Objects of other types and
+// templates that aren't registered are skipped, and times are matched in loc.
+//
+// An error is returned for exceptions that can't be expressed by an interval, such as ranges between weekdays of the
+// month, and for timeperiods that inherit from templates with use.
+func FromNagios(data []byte, loc *time.Location) (Definitions, error) {
+	periods, err := parseNagiosTimeperiods(data)
+	if err != nil {
+		return nil, err
+	}
+	c := nagiosConverter{periods: periods, loc: loc, d: make(Definitions, len(periods))}
+	for name := range periods {
+		if _, err := c.convert(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return c.d, nil
+}
+
+// parseNagiosTimeperiods returns the registered timeperiod objects of a configuration file by name.
+func parseNagiosTimeperiods(data []byte) (map[string]*nagiosTimeperiod, error) {
+	periods := map[string]*nagiosTimeperiod{}
+	var tp *nagiosTimeperiod
+	inObject, register := false, true
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !inObject {
+			fields := strings.Fields(strings.TrimSuffix(line, "{"))
+			if len(fields) < 2 || fields[0] != "define" || !strings.HasSuffix(line, "{") {
+				return nil, fmt.Errorf("Couldn't parse Nagios object definition %s, invalid format", line)
+			}
+			inObject, register, tp = true, true, nil
+			if fields[1] == "timeperiod" {
+				tp = &nagiosTimeperiod{}
+			}
+			continue
+		}
+		if line == "}" {
+			inObject = false
+			if tp == nil || !register {
+				continue
+			}
+			if tp.name == "" {
+				return nil, errors.New("Nagios timeperiods must have a timeperiod_name")
+			}
+			if _, ok := periods[tp.name]; ok {
+				return nil, fmt.Errorf("Nagios timeperiod %s is defined more than once", tp.name)
+			}
+			periods[tp.name] = tp
+			continue
+		}
+		if tp == nil {
+			continue
+		}
+		key, value := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			key, value = line[:i], strings.TrimSpace(line[i:])
+		}
+		switch key {
+		case "timeperiod_name":
+			tp.name = value
+		case "alias":
+			tp.alias = value
+		case "name":
+		case "register":
+			register = value != "0"
+		case "use":
+			return nil, fmt.Errorf("Nagios timeperiod templates are not supported, found use %s", value)
+		case "exclude":
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					tp.excludes = append(tp.excludes, name)
+				}
+			}
+		default:
+			if err := tp.parseDirective(line); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if inObject {
+		return nil, errors.New("Couldn't parse Nagios object definition, missing }")
+	}
+	return periods, nil
+}
+
+// parseDirective adds a weekday directive or date exception, such as 'monday 09:00-17:00' or 'day 1 - 7 00:00-24:00',
+// to the timeperiod.
+func (tp *nagiosTimeperiod) parseDirective(line string) error {
+	at := nagiosTimesRE.FindStringIndex(line)
+	if at == nil {
+		return fmt.Errorf("Couldn't parse Nagios timeperiod directive %s, missing times", line)
+	}
+	spec := strings.ToLower(strings.TrimSpace(line[:at[0]]))
+	times, err := parseNagiosTimes(line[at[0]:])
+	if err != nil {
+		return err
+	}
+	if day, ok := daysOfWeek[spec]; ok {
+		ti := TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: day, End: day}}}, Times: times}
+		if times == nil || len(times) > 0 {
+			tp.weekdays = append(tp.weekdays, ti)
+		}
+		return nil
+	}
+	kind, days, err := parseNagiosDays(spec)
+	if err != nil {
+		return err
+	}
+	tp.exceptions[kind] = append(tp.exceptions[kind], nagiosException{days: days, times: times})
+	return nil
+}
+
+// parseNagiosTimes converts a comma separated list of times such as '09:00-12:00,13:00-17:00' into time ranges. The
+// ranges are nil if they cover the whole day, and empty if they cover none of it.
+func parseNagiosTimes(in string) ([]TimeRange, error) {
+	times := []TimeRange{}
+	for _, str := range strings.Split(in, ",") {
+		str = strings.TrimSpace(str)
+		m := nagiosTimeRangeRE.FindStringSubmatch(str)
+		if m == nil {
+			return nil, fmt.Errorf("Couldn't parse Nagios time range %s, invalid format", str)
+		}
+		var bounds [4]int
+		for i := range bounds {
+			bounds[i], _ = strconv.Atoi(m[i+1])
+		}
+		start, end := (bounds[0]*60+bounds[1])*60, (bounds[2]*60+bounds[3])*60
+		if bounds[1] > 59 || bounds[3] > 59 || end > secondsPerDay || start > end {
+			return nil, fmt.Errorf("%s is not a valid Nagios time range: out of range", str)
+		}
+		if start == 0 && end == secondsPerDay {
+			return nil, nil
+		}
+		if start < end {
+			times = append(times, timeRangeFromSeconds(start, end))
+		}
+	}
+	return times, nil
+}
+
+// parseNagiosDays returns the type of a date exception, such as '2024-12-25', 'july 10 - 15', 'day -1',
+// 'thursday -1 november' or 'monday 3', along with intervals matching the days it includes.
+func parseNagiosDays(spec string) (int, IntervalSet, error) {
+	step := 0
+	if m := stepRE.FindStringSubmatch(spec); m != nil {
+		step, _ = strconv.Atoi(m[2])
+		spec = m[1]
+	}
+	halves := nagiosRangeSeparatorRE.Split(spec, -1)
+	if len(halves) > 2 {
+		return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+	}
+	begin := strings.Fields(halves[0])
+	var end []string
+	if len(halves) == 2 {
+		end = strings.Fields(halves[1])
+	}
+	if len(begin) == 1 {
+		return parseNagiosCalendarDates(spec, begin[0], end, step)
+	}
+	if len(begin) < 2 || len(begin) > 3 {
+		return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+	}
+	n, err := strconv.Atoi(begin[1])
+	if err != nil {
+		return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+	}
+	if month, ok := months[begin[0]]; ok && len(begin) == 2 {
+		return parseNagiosMonthDates(spec, month, n, end, step)
+	}
+	if begin[0] == "day" && len(begin) == 2 {
+		last := n
+		if end != nil {
+			if len(end) != 1 {
+				return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+			}
+			if last, err = strconv.Atoi(end[0]); err != nil {
+				return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+			}
+		}
+		ti, err := nagiosDaysOfMonth(spec, n, last, step)
+		return nagiosMonthDay, IntervalSet{ti}, err
+	}
+	weekday, ok := daysOfWeek[begin[0]]
+	if !ok {
+		return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+	}
+	if end != nil || step != 0 {
+		return 0, nil, fmt.Errorf("Unable to express ranges between weekdays of the month in an interval, as in %s", spec)
+	}
+	if n != lastOccurrence && (n < 1 || n > 5) {
+		return 0, nil, fmt.Errorf("%d is not a valid occurrence of a weekday within the month", n)
+	}
+	ti := TimeInterval{WeekdaysOfMonth: []WeekdayOfMonth{{N: n, Weekday: time.Weekday(weekday)}}}
+	if len(begin) == 2 {
+		return nagiosWeekday, IntervalSet{ti}, nil
+	}
+	month, ok := months[begin[2]]
+	if !ok {
+		return 0, nil, fmt.Errorf("%s is not a valid month", begin[2])
+	}
+	ti.Months = []MonthRange{{InclusiveRange{Begin: month, End: month}}}
+	return nagiosMonthWeekday, IntervalSet{ti}, nil
+}
+
+// parseNagiosCalendarDates returns intervals matching a date exception such as '2024-12-25' or
+// '2024-01-01 - 2024-03-31 / 3'. A date stepped through without an end date repeats forever.
+func parseNagiosCalendarDates(spec, begin string, end []string, step int) (int, IntervalSet, error) {
+	first, err := time.Parse(DateLayout, begin)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+	}
+	last := first
+	if end != nil {
+		if len(end) != 1 {
+			return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+		}
+		if last, err = time.Parse(DateLayout, end[0]); err != nil {
+			return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+		}
+		if last.Before(first) {
+			return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, end date is before start date", spec)
+		}
+	}
+	var ti TimeInterval
+	if step > 0 {
+		ti.Cycle = &Cycle{Anchor: first, Every: step, Unit: CycleDays}
+	}
+	if step == 0 || end != nil {
+		ti.Dates = []DateRange{{Begin: first, End: last}}
+	}
+	return nagiosCalendarDate, IntervalSet{ti}, nil
+}
+
+// parseNagiosMonthDates returns intervals matching a date exception such as 'july 10 - 15', 'february -1' or
+// 'december 20 - january 5' every year.
+func parseNagiosMonthDates(spec string, month, day int, end []string, step int) (int, IntervalSet, error) {
+	lastMonth, lastDay := month, day
+	var err error
+	switch len(end) {
+	case 0:
+	case 1:
+		lastDay, err = strconv.Atoi(end[0])
+	case 2:
+		var ok bool
+		if lastMonth, ok = months[end[0]]; !ok {
+			return 0, nil, fmt.Errorf("%s is not a valid month", end[0])
+		}
+		lastDay, err = strconv.Atoi(end[1])
+	default:
+		err = errors.New("invalid format")
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("Couldn't parse Nagios date exception %s, invalid format", spec)
+	}
+	if lastMonth == month {
+		ti, err := nagiosDaysOfMonth(spec, day, lastDay, step)
+		ti.Months = []MonthRange{{InclusiveRange{Begin: month, End: month}}}
+		return nagiosMonthDate, IntervalSet{ti}, err
+	}
+	if step != 0 {
+		return 0, nil, fmt.Errorf("Unable to express steps across months in an interval, as in %s", spec)
+	}
+	for _, day := range []int{day, lastDay} {
+		if day == 0 || day < -31 || day > 31 {
+			return 0, nil, fmt.Errorf("%d is not a valid day of the month: out of range", day)
+		}
+	}
+	return nagiosMonthDate, yearlyDates(month, day, lastMonth, lastDay), nil
+}
+
+// nagiosDaysOfMonth returns an interval matching every step days of the month from first to last, or every day if
+// step is 0. Negative days count back from the end of the month.
+func nagiosDaysOfMonth(spec string, first, last, step int) (TimeInterval, error) {
+	for _, day := range []int{first, last} {
+		if day == 0 || day < -31 || day > 31 {
+			return TimeInterval{}, fmt.Errorf("%d is not a valid day of the month: out of range", day)
+		}
+	}
+	if (first < 0) == (last < 0) && first > last {
+		return TimeInterval{}, fmt.Errorf("Couldn't parse Nagios date exception %s, end day is before start day", spec)
+	}
+	if step <= 1 {
+		return TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: first, End: last}}}}, nil
+	}
+	if first < 0 && last > 0 {
+		return TimeInterval{}, fmt.Errorf("Unable to express steps from the end of the month in an interval, as in %s", spec)
+	}
+	// Step through the days counting from the first. A last day counted from the end of the month can't be stepped
+	// through, so days after it are taken away instead.
+	to := last
+	if first > 0 && last < 0 {
+		to = 31
+	}
+	var ti TimeInterval
+	for day := first; day <= to; day += step {
+		ti.DaysOfMonth = append(ti.DaysOfMonth, DayOfMonthRange{InclusiveRange{Begin: day, End: day}})
+	}
+	if to != last && last != -1 {
+		ti.Except = []TimeInterval{{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: last + 1, End: -1}}}}}
+	}
+	return ti, nil
+}
+
+// A nagiosConverter converts timeperiods into definitions, along with the timeperiods they exclude.
+type nagiosConverter struct {
+	periods map[string]*nagiosTimeperiod
+	loc     *time.Location
+	d       Definitions
+}
+
+// convert returns the intervals of the named timeperiod, converting it and the timeperiods it excludes if they
+// haven't been already. The names of the timeperiods being converted further up are given in stack, so that
+// exclusions that lead back to them can be reported.
+func (c nagiosConverter) convert(name string, stack []string) (IntervalSet, error) {
+	if set, ok := c.d[name]; ok {
+		return set, nil
+	}
+	for _, s := range stack {
+		if s == name {
+			return nil, fmt.Errorf("Definition %s refers to itself", name)
+		}
+	}
+	tp, ok := c.periods[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a defined interval", name)
+	}
+	// Exceptions are ordered from the first Nagios checks, with later exceptions of a type checked before earlier ones.
+	var exceptions []nagiosException
+	for kind := range tp.exceptions {
+		for i := len(tp.exceptions[kind]) - 1; i >= 0; i-- {
+			exceptions = append(exceptions, tp.exceptions[kind][i])
+		}
+	}
+	set := IntervalSet{}
+	// earlier holds the days of the exceptions checked so far, which take precedence over the rest.
+	var earlier []TimeInterval
+	for _, e := range exceptions {
+		if e.times == nil || len(e.times) > 0 {
+			for _, ti := range e.days {
+				ti.Times = e.times
+				ti.Except = append(append([]TimeInterval{}, ti.Except...), earlier...)
+				set = append(set, ti)
+			}
+		}
+		earlier = append(earlier, e.days...)
+	}
+	for _, ti := range tp.weekdays {
+		if len(earlier) > 0 {
+			ti.Except = earlier
+		}
+		set = append(set, ti)
+	}
+	for i := range set {
+		set[i].Name, set[i].Description = tp.name, tp.alias
+		if c.loc != nil {
+			set[i].Location = &Location{c.loc}
+		}
+	}
+	for _, excluded := range tp.excludes {
+		other, err := c.convert(excluded, append(stack, name))
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, excluding(other)...)
+	}
+	c.d[name] = set
+	return set, nil
+}
+
+// excluding returns intervals that take away the times contained by a set, which must list any intervals with
+// ModeDeny after those that allow times. Each of them denies the times one of the allowing intervals contains, except
+// those that the set denies.
+func excluding(set IntervalSet) IntervalSet {
+	var denied []TimeInterval
+	for _, ti := range set {
+		if ti.Mode == ModeDeny {
+			ti.Mode = ModeAllow
+			denied = append(denied, ti)
+		}
+	}
+	var out IntervalSet
+	for _, ti := range set {
+		if ti.Mode == ModeAllow {
+			ti.Mode = ModeDeny
+			if len(denied) > 0 {
+				ti.Except = append(append([]TimeInterval{}, ti.Except...), denied...)
+			}
+			out = append(out, ti)
+		}
+	}
+	return out
+}
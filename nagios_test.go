@@ -0,0 +1,177 @@
+package gotime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const nagiosConfig = `# Timeperiods exported from Nagios
+define timeperiod {
+	timeperiod_name  workhours
+	alias            Normal Work Hours
+	monday           09:00-17:00
+	tuesday          09:00-17:00
+	wednesday        09:00-12:00, 13:00-17:00 ; an hour for lunch
+	thursday         09:00-17:00
+	friday           09:00-17:00
+	exclude          us-holidays
+}
+
+define timeperiod{
+	timeperiod_name      us-holidays
+	alias                U.S. Holidays
+	january 1            00:00-24:00
+	monday -1 may        00:00-24:00
+	july 4               00:00-24:00
+	thursday 4 november  00:00-24:00
+	december 25          00:00-24:00
+}
+
+define timeperiod {
+	timeperiod_name               backups
+	sunday                        01:00-03:00
+	day -1                        22:00-24:00
+	day 1 - 7                     00:00-00:00
+	2024-12-29                    00:00-00:00
+	2024-01-01 - 2024-01-31 / 3   12:00-13:00
+}
+
+define timeperiod {
+	name       weekday-template
+	register   0
+	monday     00:00-24:00
+}
+
+define host {
+	host_name     web
+	check_period  workhours
+}
+`
+
+func TestFromNagios(t *testing.T) {
+	d, err := FromNagios([]byte(nagiosConfig), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) != 3 {
+		t.Errorf("Expected 3 definitions, got %v", d)
+	}
+	if d["workhours"][0].Name != "workhours" || d["workhours"][0].Description != "Normal Work Hours" {
+		t.Errorf("Expected intervals to be named after their timeperiod, got %+v", d["workhours"][0])
+	}
+	for _, c := range []struct {
+		name  string
+		at    string
+		match bool
+	}{
+		{"workhours", "2024-01-02T10:00:00Z", true},
+		{"workhours", "2024-01-02T17:00:00Z", false},
+		{"workhours", "2024-01-03T12:30:00Z", false},
+		{"workhours", "2024-01-03T13:00:00Z", true},
+		{"workhours", "2024-01-06T10:00:00Z", false},
+		// Holidays are excluded.
+		{"workhours", "2024-01-01T10:00:00Z", false},
+		{"workhours", "2024-05-20T10:00:00Z", true},
+		{"workhours", "2024-05-27T10:00:00Z", false},
+		{"workhours", "2024-11-21T10:00:00Z", true},
+		{"workhours", "2024-11-28T10:00:00Z", false},
+		{"us-holidays", "2024-07-04T23:59:59Z", true},
+		{"us-holidays", "2024-07-05T00:00:00Z", false},
+		// Dates take precedence over days of the month, which take precedence over weekdays.
+		{"backups", "2024-01-07T12:30:00Z", true},
+		{"backups", "2024-01-07T02:00:00Z", false},
+		{"backups", "2024-01-14T02:00:00Z", true},
+		{"backups", "2024-01-14T12:30:00Z", false},
+		{"backups", "2024-01-31T12:30:00Z", true},
+		{"backups", "2024-01-31T23:00:00Z", false},
+		{"backups", "2024-02-29T23:00:00Z", true},
+		{"backups", "2024-03-31T23:00:00Z", true},
+		{"backups", "2024-03-31T02:00:00Z", false},
+		{"backups", "2024-02-04T02:00:00Z", false},
+		{"backups", "2024-12-22T02:00:00Z", true},
+		{"backups", "2024-12-29T02:00:00Z", false},
+	} {
+		at, err := time.Parse(time.RFC3339, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d[c.name].ContainsTime(at); got != c.match {
+			t.Errorf("Expected %s to contain %s: %t, got %t", c.name, c.at, c.match, got)
+		}
+	}
+}
+
+func TestFromNagiosExceptions(t *testing.T) {
+	for _, c := range []struct {
+		exception string
+		in        []string
+		out       []string
+	}{
+		{"2024-12-25", []string{"2024-12-25"}, []string{"2024-12-24", "2025-12-25"}},
+		{"2024-12-24 - 2025-01-02", []string{"2024-12-24", "2025-01-02"}, []string{"2024-12-23", "2025-01-03"}},
+		{"2024-01-01 / 7", []string{"2024-01-01", "2024-01-08", "2030-01-07"}, []string{"2023-12-25", "2024-01-02"}},
+		{"july 10 - 15", []string{"2024-07-10", "2025-07-15"}, []string{"2024-07-09", "2024-07-16", "2024-08-10"}},
+		{"february -1", []string{"2024-02-29", "2023-02-28"}, []string{"2024-02-28", "2024-01-31"}},
+		{"december 20 - january 5", []string{"2024-12-20", "2024-12-31", "2025-01-05"},
+			[]string{"2024-12-19", "2025-01-06"}},
+		{"october 30 - december 2", []string{"2024-10-31", "2024-11-15", "2024-12-02"},
+			[]string{"2024-10-29", "2024-12-03"}},
+		{"may 1 - 10 / 3", []string{"2024-05-01", "2024-05-04", "2024-05-10"}, []string{"2024-05-02", "2024-06-01"}},
+		{"day 1 - 15", []string{"2024-03-01", "2024-03-15"}, []string{"2024-03-16"}},
+		{"day -3 - -1", []string{"2024-02-27", "2024-02-29"}, []string{"2024-02-26"}},
+		{"day 1 - -1 / 2", []string{"2024-03-01", "2024-03-31", "2024-02-29"}, []string{"2024-03-02", "2024-03-30"}},
+		{"day 2 - -3 / 2", []string{"2024-03-02", "2024-03-28"}, []string{"2024-03-30", "2024-02-28"}},
+		{"day -1", []string{"2024-04-30"}, []string{"2024-04-29"}},
+		{"tuesday 2", []string{"2024-03-12"}, []string{"2024-03-05", "2024-03-13"}},
+		{"friday -1 june", []string{"2024-06-28"}, []string{"2024-06-21", "2024-05-31"}},
+	} {
+		config := fmt.Sprintf("define timeperiod {\n  timeperiod_name t\n  %s 00:00-24:00\n}\n", c.exception)
+		d, err := FromNagios([]byte(config), time.UTC)
+		if err != nil {
+			t.Errorf("Error converting %s: %v", c.exception, err)
+			continue
+		}
+		for _, days := range []struct {
+			dates []string
+			match bool
+		}{{c.in, true}, {c.out, false}} {
+			for _, date := range days.dates {
+				at, err := time.Parse(DateLayout, date)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := d["t"].ContainsTime(at.Add(12 * time.Hour)); got != days.match {
+					t.Errorf("Expected %s to contain %s: %t, got %t", c.exception, date, days.match, got)
+				}
+			}
+		}
+	}
+}
+
+func TestFromNagiosErrors(t *testing.T) {
+	for _, config := range []string{
+		"define timeperiod {\n  timeperiod_name t\n  monday 09:00-17:00\n",
+		"timeperiod_name t\n",
+		"define timeperiod {\n  alias t\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n}\ndefine timeperiod {\n  timeperiod_name t\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  use other\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  monday\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  monday 17:00-09:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  monday 09:00-24:30\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  someday 09:00-17:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  day 32 09:00-17:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  day 15 - 1 09:00-17:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  day -3 - 5 / 2 09:00-17:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  monday 6 09:00-17:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  monday 3 - thursday 4 09:00-17:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  april 10 - may 15 / 2 09:00-17:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  2024-02-01 - 2024-01-01 09:00-17:00\n}\n",
+		"define timeperiod {\n  timeperiod_name t\n  exclude missing\n}\n",
+		"define timeperiod {\n  timeperiod_name a\n  exclude b\n}\ndefine timeperiod {\n  timeperiod_name b\n  exclude a\n}\n",
+	} {
+		if d, err := FromNagios([]byte(config), time.UTC); err == nil {
+			t.Errorf("Expected an error converting\n%s\ngot %v", config, d)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package gotime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// An OpsgenieSchedule is an on-call schedule as returned by Opsgenie's schedule API with its rotations expanded.
+type OpsgenieSchedule struct {
+	Name      string             `json:"name"`
+	Timezone  string             `json:"timezone"`
+	Rotations []OpsgenieRotation `json:"rotations"`
+}
+
+// An OpsgenieRotation hands a schedule between its participants in turn, each turn lasting Length hours, days or
+// weeks depending on Type, starting from StartDate. A rotation with a TimeRestriction only puts participants on call
+// during the restricted times of their turns.
+type OpsgenieRotation struct {
+	Name            string                   `json:"name"`
+	StartDate       time.Time                `json:"startDate"`
+	EndDate         *time.Time               `json:"endDate,omitempty"`
+	Type            string                   `json:"type"`
+	Length          int                      `json:"length"`
+	Participants    []OpsgenieParticipant    `json:"participants"`
+	TimeRestriction *OpsgenieTimeRestriction `json:"timeRestriction,omitempty"`
+}
+
+// An OpsgenieParticipant takes turns in a rotation. Type is user, team, escalation or none, the last of which leaves
+// its turns without anyone on call.
+type OpsgenieParticipant struct {
+	Type     string `json:"type"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// An OpsgenieTimeRestriction limits a rotation to a time of day every day, when Type is time-of-day, or to the
+// periods of the week in Restrictions, when Type is weekday-and-time-of-day.
+type OpsgenieTimeRestriction struct {
+	Type         string                `json:"type"`
+	Restriction  *OpsgenieRestriction  `json:"restriction,omitempty"`
+	Restrictions []OpsgenieRestriction `json:"restrictions,omitempty"`
+}
+
+// An OpsgenieRestriction is a period of the day, or of the week if it has days, such as Monday 08:00 to Friday 18:00.
+type OpsgenieRestriction struct {
+	StartDay  string `json:"startDay,omitempty"`
+	StartHour int    `json:"startHour"`
+	StartMin  int    `json:"startMin"`
+	EndDay    string `json:"endDay,omitempty"`
+	EndHour   int    `json:"endHour"`
+	EndMin    int    `json:"endMin"`
+}
+
+var opsgenieTurnLengths = map[string]int{
+	"hourly": 3600,
+	"daily":  secondsPerDay,
+	"weekly": 7 * secondsPerDay,
+}
+
+// ParseOpsgenieSchedule reads an Opsgenie schedule from the JSON returned by its schedule API, either on its own or
+// in the data field of the response.
+func ParseOpsgenieSchedule(data []byte) (OpsgenieSchedule, error) {
+	var envelope struct {
+		Data *OpsgenieSchedule `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return OpsgenieSchedule{}, err
+	}
+	if envelope.Data != nil {
+		return *envelope.Data, nil
+	}
+	var s OpsgenieSchedule
+	err := json.Unmarshal(data, &s)
+	return s, err
+}
+
+// OnCall returns the times each participant of the schedule is on call, keyed by the username of users and the name
+// of teams and escalations, or their ID if they have neither. A participant is on call whenever any of the rotations
+// they take part in gives them a turn. Turns are counted in the schedule's time zone, so they hand over at the same
+// time of day all year round. Overrides aren't part of a schedule, so are left out.
+func (s OpsgenieSchedule) OnCall() (map[string]IntervalSet, error) {
+	loc := time.UTC
+	if s.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return nil, fmt.Errorf("%s is not a valid Opsgenie time zone", s.Timezone)
+		}
+	}
+	onCall := map[string]IntervalSet{}
+	for _, r := range s.Rotations {
+		restriction, err := r.TimeRestriction.intervals()
+		if err != nil {
+			return nil, fmt.Errorf("Rotation %s: %v", r.Name, err)
+		}
+		for i, p := range r.Participants {
+			if strings.EqualFold(p.Type, "none") || strings.EqualFold(p.Type, "noone") {
+				continue
+			}
+			key := p.Username
+			if key == "" {
+				key = p.Name
+			}
+			if key == "" {
+				key = p.ID
+			}
+			turns, err := r.turns(i, loc)
+			if err != nil {
+				return nil, fmt.Errorf("Rotation %s: %v", r.Name, err)
+			}
+			for _, turn := range turns {
+				if restriction == nil {
+					onCall[key] = append(onCall[key], turn)
+					continue
+				}
+				for _, allowed := range restriction {
+					allowed.Location = turn.Location
+					in, err := Intersect(turn, allowed)
+					if err != nil {
+						return nil, fmt.Errorf("Rotation %s: %v", r.Name, err)
+					}
+					if !in.IsEmpty() {
+						onCall[key] = append(onCall[key], in)
+					}
+				}
+			}
+		}
+	}
+	return onCall, nil
+}
+
+// turns returns intervals matching the turns of the participant at the given index, with days seen in loc. Turns
+// repeat once every participant has had one, which is a whole number of days later once it has happened enough times,
+// so there is an interval for each day of that period with a cycle repeating it.
+func (r OpsgenieRotation) turns(participant int, loc *time.Location) (IntervalSet, error) {
+	unit, ok := opsgenieTurnLengths[r.Type]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a valid Opsgenie rotation type", r.Type)
+	}
+	if r.Length < 1 {
+		return nil, fmt.Errorf("%d is not a valid Opsgenie rotation length", r.Length)
+	}
+	turn := unit * r.Length
+	period := turn * len(r.Participants)
+	days := lcm(period, secondsPerDay) / secondsPerDay
+	start := r.StartDate.In(loc)
+	end := endOfTime
+	if r.EndDate != nil {
+		end = *r.EndDate
+	}
+	anchor := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	offset := secondOfDay(start)
+	var turns IntervalSet
+	for day := 0; day < days; day++ {
+		// The seconds of this day, counted from the start of the rotation.
+		first := day*secondsPerDay - offset
+		var times []TimeRange
+		for k := floorDiv(first, turn); k*turn < first+secondsPerDay; k++ {
+			if floorMod(k, len(r.Participants)) != participant {
+				continue
+			}
+			from, to := k*turn, (k+1)*turn
+			if from < first {
+				from = first
+			}
+			if to > first+secondsPerDay {
+				to = first + secondsPerDay
+			}
+			times = append(times, timeRangeFromSeconds(from-first, to-first))
+		}
+		if times == nil {
+			continue
+		}
+		ti := TimeInterval{
+			Cycle:    &Cycle{Anchor: anchor.AddDate(0, 0, day), Every: days, Unit: CycleDays},
+			Location: &Location{loc},
+			Absolute: []AbsoluteRange{{Start: r.StartDate, End: end}},
+		}
+		if len(times) > 1 || times[0].startSecond() != 0 || times[0].endSecond() != secondsPerDay {
+			ti.Times = times
+		}
+		turns = append(turns, ti)
+	}
+	return turns, nil
+}
+
+// intervals returns the times the restriction allows, or nil if there isn't one. None of the times are overnight, so
+// that they can be intersected with the turns of a rotation.
+func (tr *OpsgenieTimeRestriction) intervals() (IntervalSet, error) {
+	if tr == nil {
+		return nil, nil
+	}
+	switch tr.Type {
+	case "time-of-day":
+		if tr.Restriction == nil {
+			return nil, errors.New("A time-of-day restriction must have a restriction")
+		}
+		start, end, err := tr.Restriction.seconds()
+		if err != nil {
+			return nil, err
+		}
+		if start < end {
+			return IntervalSet{{Times: []TimeRange{timeRangeFromSeconds(start, end)}}}, nil
+		}
+		return IntervalSet{
+			{Times: []TimeRange{timeRangeFromSeconds(start, secondsPerDay)}},
+			{Times: []TimeRange{timeRangeFromSeconds(0, end)}},
+		}, nil
+	case "weekday-and-time-of-day":
+		var set IntervalSet
+		for _, r := range tr.Restrictions {
+			days, err := r.intervals()
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, days...)
+		}
+		if set == nil {
+			// No restrictions allow no times at all.
+			set = IntervalSet{{Times: []TimeRange{}}}
+		}
+		return set, nil
+	}
+	return nil, fmt.Errorf("%s is not a valid Opsgenie time restriction type", tr.Type)
+}
+
+// intervals returns an interval for each day of the week the restriction covers part of, from its start day to its
+// end day. A restriction that ends at or before its start on the same day covers the whole week.
+func (r OpsgenieRestriction) intervals() (IntervalSet, error) {
+	startDay, ok := daysOfWeek[strings.ToLower(r.StartDay)]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a valid day of the week", r.StartDay)
+	}
+	endDay, ok := daysOfWeek[strings.ToLower(r.EndDay)]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a valid day of the week", r.EndDay)
+	}
+	start, end, err := r.seconds()
+	if err != nil {
+		return nil, err
+	}
+	// Count the days from the start to the end, going round the week if the end comes first.
+	length := (endDay - startDay + 7) % 7
+	if length == 0 && end <= start {
+		length = 7
+	}
+	var set IntervalSet
+	for i := 0; i <= length; i++ {
+		from, to := 0, secondsPerDay
+		if i == 0 {
+			from = start
+		}
+		if i == length {
+			to = end
+		}
+		if from >= to {
+			continue
+		}
+		day := (startDay + i) % 7
+		ti := TimeInterval{Weekdays: []WeekdayRange{{InclusiveRange{Begin: day, End: day}}}}
+		if from != 0 || to != secondsPerDay {
+			ti.Times = []TimeRange{timeRangeFromSeconds(from, to)}
+		}
+		set = append(set, ti)
+	}
+	return set, nil
+}
+
+// seconds returns the seconds of the day at which the restriction starts and ends.
+func (r OpsgenieRestriction) seconds() (int, int, error) {
+	for _, v := range []struct {
+		value, max int
+	}{{r.StartHour, 24}, {r.EndHour, 24}, {r.StartMin, 59}, {r.EndMin, 59}} {
+		if v.value < 0 || v.value > v.max {
+			return 0, 0, fmt.Errorf("%d is not a valid Opsgenie restriction time: out of range", v.value)
+		}
+	}
+	return (r.StartHour*60 + r.StartMin) * 60, (r.EndHour*60 + r.EndMin) * 60, nil
+}
+
+// floorMod returns the remainder of a divided by b, which is never negative.
+func floorMod(a, b int) int {
+	return (a%b + b) % b
+}
@@ -0,0 +1,161 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+)
+
+const opsgenieExport = `{
+	"data": {
+		"id": "d875alp4-9b4e-4219-a803-0c26936d18de",
+		"name": "ops_schedule",
+		"timezone": "Europe/London",
+		"enabled": true,
+		"rotations": [
+			{
+				"name": "weekly",
+				"startDate": "2024-01-01T09:00:00Z",
+				"type": "weekly",
+				"length": 1,
+				"participants": [
+					{"type": "user", "id": "b3d6f3b0", "username": "alice@example.com"},
+					{"type": "user", "id": "c4e7a4c1", "username": "bob@example.com"}
+				]
+			},
+			{
+				"name": "nights",
+				"startDate": "2024-01-01T00:00:00Z",
+				"endDate": "2024-02-01T00:00:00Z",
+				"type": "daily",
+				"length": 1,
+				"participants": [
+					{"type": "team", "id": "e5f8b5d2", "name": "night_team"},
+					{"type": "none"}
+				],
+				"timeRestriction": {
+					"type": "time-of-day",
+					"restriction": {"startHour": 22, "startMin": 0, "endHour": 6, "endMin": 0}
+				}
+			},
+			{
+				"name": "business_hours",
+				"startDate": "2024-01-01T06:00:00Z",
+				"type": "hourly",
+				"length": 8,
+				"participants": [
+					{"type": "escalation", "id": "f6a9c6e3"},
+					{"type": "user", "id": "a7b0d7f4", "username": "carol@example.com"},
+					{"type": "noone"}
+				],
+				"timeRestriction": {
+					"type": "weekday-and-time-of-day",
+					"restrictions": [
+						{"startDay": "monday", "startHour": 8, "startMin": 0, "endDay": "friday", "endHour": 18, "endMin": 0}
+					]
+				}
+			}
+		]
+	},
+	"took": 0.031,
+	"requestId": "4a1b2c3d"
+}`
+
+func TestOpsgenieScheduleOnCall(t *testing.T) {
+	s, err := ParseOpsgenieSchedule([]byte(opsgenieExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	onCall, err := s.OnCall()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(onCall) != 5 {
+		t.Errorf("Expected 5 participants on call, got %v", onCall)
+	}
+	for _, c := range []struct {
+		who   string
+		at    string
+		match bool
+	}{
+		// Weekly turns handing over on Monday mornings.
+		{"alice@example.com", "2023-12-31T12:00:00Z", false},
+		{"alice@example.com", "2024-01-01T08:59:59Z", false},
+		{"alice@example.com", "2024-01-01T09:00:00Z", true},
+		{"alice@example.com", "2024-01-05T03:00:00Z", true},
+		{"alice@example.com", "2024-01-08T08:59:59Z", true},
+		{"alice@example.com", "2024-01-08T09:00:00Z", false},
+		{"bob@example.com", "2024-01-08T09:00:00Z", true},
+		{"bob@example.com", "2024-01-15T08:59:59Z", true},
+		{"bob@example.com", "2024-01-15T09:00:00Z", false},
+		{"alice@example.com", "2024-01-15T09:00:00Z", true},
+		// The handover stays at 09:00 UTC in winter and moves to 08:00 UTC in British Summer Time.
+		{"alice@example.com", "2030-01-07T09:00:00Z", true},
+		{"bob@example.com", "2030-01-07T08:59:59Z", true},
+		{"alice@example.com", "2024-07-01T08:00:00Z", true},
+		{"bob@example.com", "2024-07-01T07:59:59Z", true},
+		// Night turns every other day until the end of January, with no one on call on the days in between.
+		{"night_team", "2024-01-01T05:59:59Z", true},
+		{"night_team", "2024-01-01T06:00:00Z", false},
+		{"night_team", "2024-01-01T22:00:00Z", true},
+		{"night_team", "2024-01-02T01:00:00Z", false},
+		{"night_team", "2024-01-03T23:00:00Z", true},
+		{"night_team", "2024-01-31T23:00:00Z", true},
+		{"night_team", "2024-02-02T23:00:00Z", false},
+		// Eight hour turns limited to the working week from Monday morning to Friday evening, keyed by ID when there is
+		// no name.
+		{"f6a9c6e3", "2024-01-01T07:59:59Z", false},
+		{"f6a9c6e3", "2024-01-01T08:00:00Z", true},
+		{"f6a9c6e3", "2024-01-01T13:59:59Z", true},
+		{"carol@example.com", "2024-01-01T14:00:00Z", true},
+		{"carol@example.com", "2024-01-01T21:59:59Z", true},
+		{"carol@example.com", "2024-01-01T22:00:00Z", false},
+		{"carol@example.com", "2024-01-05T17:59:59Z", true},
+		{"carol@example.com", "2024-01-05T18:00:00Z", false},
+		{"f6a9c6e3", "2024-01-02T06:00:00Z", true},
+		{"f6a9c6e3", "2024-01-06T10:00:00Z", false},
+	} {
+		at, err := time.Parse(time.RFC3339, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := onCall[c.who].ContainsTime(at); got != c.match {
+			t.Errorf("Expected %s to be on call at %s: %t, got %t", c.who, c.at, c.match, got)
+		}
+	}
+}
+
+func TestParseOpsgenieSchedule(t *testing.T) {
+	s, err := ParseOpsgenieSchedule([]byte(`{"name": "bare", "timezone": "UTC", "rotations": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "bare" || s.Timezone != "UTC" {
+		t.Errorf("Parsing a schedule without a data field gave %+v", s)
+	}
+	if _, err := ParseOpsgenieSchedule([]byte(`{"data": [`)); err == nil {
+		t.Errorf("Expected an error parsing invalid JSON")
+	}
+}
+
+func TestOpsgenieScheduleOnCallErrors(t *testing.T) {
+	user := []OpsgenieParticipant{{Type: "user", Username: "a"}}
+	for _, s := range []OpsgenieSchedule{
+		{Timezone: "Nowhere/Special"},
+		{Rotations: []OpsgenieRotation{{Type: "monthly", Length: 1, Participants: user}}},
+		{Rotations: []OpsgenieRotation{{Type: "daily", Length: 0, Participants: user}}},
+		{Rotations: []OpsgenieRotation{{Type: "daily", Length: 1, Participants: user,
+			TimeRestriction: &OpsgenieTimeRestriction{Type: "time-of-day"}}}},
+		{Rotations: []OpsgenieRotation{{Type: "daily", Length: 1, Participants: user,
+			TimeRestriction: &OpsgenieTimeRestriction{Type: "weekday-and-time-of-day",
+				Restrictions: []OpsgenieRestriction{{StartDay: "someday", EndDay: "friday"}}}}}},
+		{Rotations: []OpsgenieRotation{{Type: "daily", Length: 1, Participants: user,
+			TimeRestriction: &OpsgenieTimeRestriction{Type: "time-of-day",
+				Restriction: &OpsgenieRestriction{StartHour: 25}}}}},
+		{Rotations: []OpsgenieRotation{{Type: "daily", Length: 1, Participants: user,
+			TimeRestriction: &OpsgenieTimeRestriction{Type: "anytime"}}}},
+	} {
+		if onCall, err := s.OnCall(); err == nil {
+			t.Errorf("Expected an error converting %+v, got %v", s, onCall)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package gotime
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// A ParseError is an error found while parsing a YAML document with Unmarshal, along with the position in the
+// document of the value responsible for it.
+type ParseError struct {
+	// Line and Column give the position of the value, counting from 1.
+	Line   int
+	Column int
+	// Field is the key of the mapping the value belongs to, such as weekdays, or empty if it is not inside one.
+	Field string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %v", e.Line, e.Column, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal parses a YAML document into v, which should be a pointer to a TimeInterval, IntervalSet, Definitions or
+// any other value that can be unmarshalled with yaml.Unmarshal. If the document is invalid, the error is a *ParseError
+// giving the line, column and field of the value responsible.
+func Unmarshal(data []byte, v interface{}) error {
+	return unmarshalLocated(data, v, yaml.Unmarshal)
+}
+
+// unmarshalLocated parses data into v with decode, and locates the value responsible for any error it returns.
+func unmarshalLocated(data []byte, v interface{}, decode func([]byte, interface{}) error) error {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	err := decode(data, v)
+	if err == nil || len(doc.Content) == 0 {
+		return err
+	}
+	target := reflect.TypeOf(v).Elem()
+	fails := func() bool {
+		out, err := yamlv3.Marshal(&doc)
+		if err != nil {
+			return true
+		}
+		return decode(out, reflect.New(target).Interface()) != nil
+	}
+	node, field := locateError(doc.Content[0], fails)
+	return &ParseError{Line: node.Line, Column: node.Column, Field: field, Err: err}
+}
+
+// locateError narrows down which node of a document is responsible for it failing to decode. Each value of a mapping
+// is removed in turn, and if the document then decodes the search continues within that value. Each item of a
+// sequence is tried on its own, and if the document still fails with only that item the search continues within it.
+// The search stops at the first node that can't be narrowed down any further, which is returned with the mapping key
+// it was found under.
+func locateError(node *yamlv3.Node, fails func() bool) (*yamlv3.Node, string) {
+	field := ""
+	for {
+		var next *yamlv3.Node
+		content := node.Content
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(content); i += 2 {
+				node.Content = append(append([]*yamlv3.Node{}, content[:i]...), content[i+2:]...)
+				fixed := !fails()
+				node.Content = content
+				if fixed {
+					field, next = content[i].Value, content[i+1]
+					break
+				}
+			}
+		case yamlv3.SequenceNode:
+			for _, item := range content {
+				node.Content = []*yamlv3.Node{item}
+				failed := fails()
+				node.Content = content
+				if failed {
+					next = item
+					break
+				}
+			}
+		}
+		if next == nil {
+			return node, field
+		}
+		node = next
+	}
+}
+
+// UnmarshalStrict is like Unmarshal, except that fields that don't belong to the values they are given for, such as
+// a misspelt weekday in place of weekdays, are reported as errors rather than ignored. Documents can also be
+// decoded strictly with yaml.UnmarshalStrict, which reports the same errors without their position.
+func UnmarshalStrict(data []byte, v interface{}) error {
+	return unmarshalLocated(data, v, yaml.UnmarshalStrict)
+}
+
+// isStrict returns true if unmarshal belongs to a decoder started by yaml.UnmarshalStrict. Decoding a mapping into an
+// empty struct only fails when unknown fields are rejected, so unmarshal must be for a mapping with at least one key.
+func isStrict(unmarshal func(interface{}) error) bool {
+	return unmarshal(&struct{}{}) != nil
+}
+
+// decoder returns the function to decode YAML with, rejecting unknown fields if strict is true. Values that rewrite
+// their fields before decoding them again use it to keep to the mode they were decoded in.
+func decoder(strict bool) func([]byte, interface{}) error {
+	if strict {
+		return yaml.UnmarshalStrict
+	}
+	return yaml.Unmarshal
+}
+
+// intervalFields holds the names of the fields of a TimeInterval once settings such as locale have been taken out.
+var intervalFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(TimeInterval{})
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]; name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// checkIntervalFields returns an error for the first field of an interval that isn't known.
+func checkIntervalFields(fields yaml.MapSlice) error {
+	for _, field := range fields {
+		if key, ok := field.Key.(string); !ok || !intervalFields[key] {
+			return fmt.Errorf("%v is not a valid interval field", field.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package gotime
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var parseErrorTestCases = []struct {
+	in     string
+	line   int
+	column int
+	field  string
+}{
+	{
+		in: `
+- weekdays: ['monday:friday']
+- weekdays: ['saturday']
+  days_of_month: ['1', '10:5']
+`,
+		line:   4,
+		column: 24,
+		field:  "days_of_month",
+	},
+	{
+		in: `
+- weekdays: ['friday:monday']
+`,
+		line:   2,
+		column: 14,
+		field:  "weekdays",
+	},
+	{
+		in: `
+- weekdays: ['monday:friday']
+  except:
+    - days_of_month: ['1']
+    - years: ['2020:notayear']
+`,
+		line:   5,
+		column: 15,
+		field:  "years",
+	},
+	{
+		in: `
+- times:
+    - start_time: '09:00'
+      end_time: '17:00'
+    - start_time: '12:00'
+`,
+		line:   5,
+		column: 7,
+		field:  "times",
+	},
+	{
+		in: `
+- location: 'Nowhere/Special'
+`,
+		line:   2,
+		column: 13,
+		field:  "location",
+	},
+}
+
+func TestUnmarshalLocatesErrors(t *testing.T) {
+	for _, tc := range parseErrorTestCases {
+		var set IntervalSet
+		err := Unmarshal([]byte(tc.in), &set)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Expected a ParseError for %s, got %v", tc.in, err)
+			continue
+		}
+		if pe.Line != tc.line || pe.Column != tc.column || pe.Field != tc.field {
+			t.Errorf("Error %v in %s was located at line %d, column %d, field %s, expected line %d, column %d, field %s",
+				pe.Err, tc.in, pe.Line, pe.Column, pe.Field, tc.line, tc.column, tc.field)
+		}
+	}
+}
+
+func TestUnmarshalMatchesYAML(t *testing.T) {
+	for _, tc := range yamlUnmarshalTestCases {
+		var got []TimeInterval
+		err := Unmarshal([]byte(tc.in), &got)
+		var pe *ParseError
+		if tc.expectError {
+			if !errors.As(err, &pe) {
+				t.Errorf("Expected a ParseError for %s, got %v", tc.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error unmarshalling %s: %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.intervals) {
+			t.Errorf("Unmarshal of %s gave %v, expected %v", tc.in, got, tc.intervals)
+		}
+	}
+}
+
+func TestUnmarshalSyntaxError(t *testing.T) {
+	var set IntervalSet
+	err := Unmarshal([]byte("- weekdays: ['monday'\n"), &set)
+	if err == nil {
+		t.Errorf("Expected an error for an unterminated list")
+	}
+}
+
+var strictTestCases = []struct {
+	in          string
+	expectError bool
+	line        int
+	field       string
+}{
+	{
+		in: `
+- weekday: ['monday:friday']
+`,
+		expectError: true,
+		line:        2,
+		field:       "weekday",
+	},
+	{
+		in: `
+- weekdays: ['lundi:vendredi']
+  locale: 'fr'
+  weekend: ['samedi', 'dimanche']
+  repeat: {anchor: '2024-01-01', every: '2w'}
+  except:
+    - weekdays: ['weekend']
+`,
+	},
+	{
+		in: `
+- weekdays: ['monday:friday']
+  times:
+    - start_time: '09:00'
+      end_time: '17:00'
+      ends: '18:00'
+`,
+		expectError: true,
+		line:        6,
+		field:       "ends",
+	},
+	{
+		in: `
+- weekdays: ['monday:friday']
+  locale: 'de'
+  except:
+    - month: ['december']
+`,
+		expectError: true,
+		line:        5,
+		field:       "month",
+	},
+	{
+		in: `
+- weekdays: ['monday:friday']
+  cycle: {anchor: '2024-01-01', every: '2w', offset: 1}
+`,
+		expectError: true,
+		line:        3,
+		field:       "offset",
+	},
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	for _, tc := range strictTestCases {
+		var set, lenient IntervalSet
+		err := UnmarshalStrict([]byte(tc.in), &set)
+		if !tc.expectError {
+			if err != nil {
+				t.Errorf("Unexpected error unmarshalling %s strictly: %v", tc.in, err)
+			} else if Unmarshal([]byte(tc.in), &lenient) != nil || !reflect.DeepEqual(set, lenient) {
+				t.Errorf("Strict unmarshal of %s gave %v, expected %v", tc.in, set, lenient)
+			}
+			continue
+		}
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Expected a ParseError for %s, got %v", tc.in, err)
+			continue
+		}
+		if pe.Line != tc.line || pe.Field != tc.field {
+			t.Errorf("Error %v in %s was located at line %d, field %s, expected line %d, field %s",
+				pe.Err, tc.in, pe.Line, pe.Field, tc.line, tc.field)
+		}
+		if err := Unmarshal([]byte(tc.in), &lenient); err != nil {
+			t.Errorf("Unexpected error unmarshalling %s leniently: %v", tc.in, err)
+		}
+	}
+}
+
+func TestUnmarshalStrictDefinitions(t *testing.T) {
+	in := `
+holidays:
+  - months: ['december']
+    days_of_month: ['25:26']
+support:
+  - weekdays: ['monday:friday']
+    except: ['holidays']
+  - weekdys: ['saturday']
+`
+	var d Definitions
+	if err := UnmarshalStrict([]byte(in), &d); err == nil {
+		t.Errorf("Expected an error for an unknown field in a definition")
+	}
+	if err := Unmarshal([]byte(in), &d); err != nil {
+		t.Errorf("Unexpected error unmarshalling definitions leniently: %v", err)
+	}
+}
+
+func TestUnmarshalStrictRotation(t *testing.T) {
+	in := `
+anchor: '2024-01-01'
+every: '1w'
+shifts:
+  - weekdays: ['monday:friday']
+  - weekdays: ['saturday', 'sunday']
+`
+	var r Rotation
+	if err := UnmarshalStrict([]byte(in), &r); err != nil {
+		t.Errorf("Unexpected error unmarshalling a rotation strictly: %v", err)
+	}
+	if err := UnmarshalStrict([]byte(in+"offset: 1\n"), &r); err == nil {
+		t.Errorf("Expected an error for an unknown field in a rotation")
+	}
+}
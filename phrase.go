@@ -0,0 +1,807 @@
+package gotime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A PhraseWarning reports part of a phrase that ParsePhrase had to guess the meaning of, or didn't understand and
+// left out.
+type PhraseWarning struct {
+	// Text is the part of the phrase the warning is about, as it was written.
+	Text    string
+	Message string
+}
+
+func (w PhraseWarning) String() string {
+	return fmt.Sprintf("%q: %s", w.Text, w.Message)
+}
+
+type phraseTokenKind int
+
+const (
+	phraseWord phraseTokenKind = iota
+	phraseNumber
+	phraseOrdinal
+	phraseTime
+	phraseISODate
+	phraseSlashDate
+	phraseZone
+	phrasePunctuation
+)
+
+// A phraseToken is a word, number, time, date or punctuation mark in a phrase. Text is in lower case, apart from
+// the names of time zones.
+type phraseToken struct {
+	kind phraseTokenKind
+	text string
+	raw  string
+}
+
+var phraseTokenRE = regexp.MustCompile(`(?i)` +
+	`([0-9]{4}-[0-9]{1,2}-[0-9]{1,2})` +
+	`|([0-9]{1,2}/[0-9]{1,2}(?:/[0-9]{2,4})?)` +
+	`|([a-z]+(?:/[a-z_+-]+)+)` +
+	`|([0-9]{1,2}(?::[0-9]{2})?\s*(?:am|pm|a\.m\.|p\.m\.)|[0-9]{1,2}:[0-9]{2})` +
+	`|([0-9]+(?:st|nd|rd|th))` +
+	`|([0-9]+)` +
+	`|([a-z]+)` +
+	`|([-,;&])`)
+
+// phraseTokenGroups gives the kind of token matched by each group of phraseTokenRE.
+var phraseTokenGroups = []phraseTokenKind{phraseISODate, phraseSlashDate, phraseZone, phraseTime, phraseOrdinal,
+	phraseNumber, phraseWord, phrasePunctuation}
+
+var phraseTimeRE = regexp.MustCompile(`^([0-9]{1,2})(?::([0-9]{2}))?\s*(am|pm|a\.m\.|p\.m\.)?$`)
+
+// phraseOrdinals are the words for the occurrences of a weekday within a month.
+var phraseOrdinals = map[string]int{
+	"first":  1,
+	"second": 2,
+	"third":  3,
+	"fourth": 4,
+	"fifth":  5,
+	"last":   lastOccurrence,
+}
+
+// phraseRangeWords separate the ends of a range.
+var phraseRangeWords = map[string]bool{"-": true, "to": true, "through": true, "thru": true, "until": true,
+	"till": true, "til": true}
+
+// phraseExceptWords begin the part of a phrase describing times that are left out.
+var phraseExceptWords = map[string]bool{"except": true, "excluding": true, "excl": true, "not": true,
+	"without": true, "besides": true}
+
+// phraseFillerWords join the parts of a phrase without changing its meaning.
+var phraseFillerWords = map[string]bool{"every": true, "each": true, "on": true, "from": true, "at": true,
+	"the": true, "and": true, "or": true, "of": true, "during": true, "in": true, "between": true, "active": true,
+	"open": true, "only": true, "a": true, "an": true, "is": true, "are": true, "day": true, "days": true,
+	"month": true, "months": true, "year": true, "years": true, "time": true, "hours": true, "also": true,
+	"plus": true, "then": true, "but": true, "apart": true, "other": true, "than": true, "for": true, "local": true,
+	",": true, "-": true, "&": true}
+
+// phraseZoneAbbreviations are time zone abbreviations that are left out because they are ambiguous or don't say
+// whether daylight saving time applies.
+var phraseZoneAbbreviations = map[string]bool{"est": true, "edt": true, "cst": true, "cdt": true, "mst": true,
+	"mdt": true, "pst": true, "pdt": true, "bst": true, "ist": true, "cet": true, "cest": true, "eet": true,
+	"eest": true, "aest": true, "aedt": true, "jst": true}
+
+// ParsePhrase makes a best effort at understanding a schedule written in English, such as 'weekdays 9am to 5pm except
+// December 24-26' or 'the first Monday of every month from 10:00 to 11:30 in Europe/London', for people to type
+// schedules directly. It understands weekdays, times of day, months, days of the month such as 'the 15th', weekdays of
+// the month such as 'last friday', dates with or without a year, years and IANA time zone names. Times and days
+// written after except or excluding are left out, and phrases separated by semicolons become separate intervals.
+//
+// Guesses, such as whether '9 to 5' means 09:00 to 17:00, and words that weren't understood are reported as warnings
+// so that the intervals can be checked before they are used. An error is returned if none of the phrase was
+// understood.
+func ParsePhrase(phrase string) (IntervalSet, []PhraseWarning, error) {
+	p := &phraseParser{}
+	for _, m := range phraseTokenRE.FindAllStringSubmatch(phrase, -1) {
+		for group, kind := range phraseTokenGroups {
+			if m[group+1] == "" {
+				continue
+			}
+			tok := phraseToken{kind: kind, text: strings.ToLower(m[group+1]), raw: m[group+1]}
+			if kind == phraseZone {
+				tok.text = tok.raw
+			}
+			p.tokens = append(p.tokens, tok)
+			break
+		}
+	}
+	var set IntervalSet
+	for p.i < len(p.tokens) {
+		if p.is(0, ";") {
+			p.i++
+			continue
+		}
+		intervals, understood := p.parseInterval()
+		if understood {
+			set = append(set, intervals...)
+		}
+	}
+	if set == nil {
+		return nil, p.warnings, fmt.Errorf("Couldn't understand %q as a schedule", phrase)
+	}
+	return set, p.warnings, nil
+}
+
+// A phraseParser reads the tokens of a phrase in order. The location given in the phrase for the interval being read
+// is kept apart from its parts, as it applies to its exceptions too.
+type phraseParser struct {
+	tokens   []phraseToken
+	i        int
+	warnings []PhraseWarning
+	location *Location
+}
+
+// A phrasePart collects the fields of an interval, or of its exceptions, from the parts of a phrase. Fields that are
+// lists of alternatives in an interval, such as weekdays, are added to base, while dates that can only be matched by
+// several intervals, such as 'december 24 - january 2', are added to days, each of which is combined with base.
+type phrasePart struct {
+	base       TimeInterval
+	days       IntervalSet
+	understood bool
+}
+
+func (p *phraseParser) tok(k int) (phraseToken, bool) {
+	if p.i+k >= len(p.tokens) {
+		return phraseToken{}, false
+	}
+	return p.tokens[p.i+k], true
+}
+
+// is returns true if the token k places ahead has one of the given texts.
+func (p *phraseParser) is(k int, texts ...string) bool {
+	tok, ok := p.tok(k)
+	if !ok {
+		return false
+	}
+	for _, text := range texts {
+		if tok.text == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *phraseParser) isRange(k int) bool {
+	tok, ok := p.tok(k)
+	return ok && phraseRangeWords[tok.text]
+}
+
+// raw returns the text of the n tokens from k places ahead as written.
+func (p *phraseParser) raw(k, n int) string {
+	words := make([]string, 0, n)
+	for j := p.i + k; j < p.i+k+n && j < len(p.tokens); j++ {
+		words = append(words, p.tokens[j].raw)
+	}
+	return strings.Join(words, " ")
+}
+
+func (p *phraseParser) warn(k, n int, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, PhraseWarning{Text: p.raw(k, n), Message: fmt.Sprintf(format, args...)})
+}
+
+// parseInterval reads a phrase up to the next semicolon, returning the intervals it describes and whether any of it
+// was understood.
+func (p *phraseParser) parseInterval() (IntervalSet, bool) {
+	main := &phrasePart{}
+	var except []*phrasePart
+	part := main
+	p.location = nil
+	for p.i < len(p.tokens) && !p.is(0, ";") {
+		tok := p.tokens[p.i]
+		switch {
+		case (tok.text == "apart" && p.is(1, "from")) || (tok.text == "other" && p.is(1, "than")) ||
+			(tok.text == "but" && p.is(1, "not")):
+			p.i++
+			fallthrough
+		case phraseExceptWords[tok.text]:
+			part = &phrasePart{}
+			except = append(except, part)
+			p.i++
+		case p.parseLocation():
+			main.understood = true
+		case p.parseClause(part):
+			part.understood = true
+		case phraseFillerWords[tok.text]:
+			p.i++
+		default:
+			p.warn(0, 1, "Didn't understand this, so it was left out")
+			p.i++
+		}
+	}
+	intervals := p.combine(main)
+	var exceptions []TimeInterval
+	for _, part := range except {
+		if part.understood {
+			exceptions = append(exceptions, p.combine(part)...)
+		}
+	}
+	for i := range intervals {
+		intervals[i].Location, intervals[i].Except = p.location, exceptions
+	}
+	return intervals, main.understood || len(exceptions) > 0
+}
+
+// combine returns the intervals described by a part of a phrase.
+func (p *phraseParser) combine(part *phrasePart) IntervalSet {
+	if part.days == nil {
+		return IntervalSet{part.base}
+	}
+	base := part.base
+	var out IntervalSet
+	for _, days := range part.days {
+		if base.Months == nil && base.DaysOfMonth == nil && base.WeekdaysOfMonth == nil && base.Dates == nil {
+			ti := base
+			ti.Months, ti.DaysOfMonth, ti.Dates = days.Months, days.DaysOfMonth, days.Dates
+			out = append(out, ti)
+			continue
+		}
+		ti, err := Intersect(base, days)
+		if err != nil {
+			text, _ := days.MarshalText()
+			p.warnings = append(p.warnings, PhraseWarning{Text: string(text),
+				Message: "Unable to combine these days with the rest of the phrase, so they were left out"})
+			continue
+		}
+		out = append(out, ti)
+	}
+	return out
+}
+
+// parseClause reads a clause of the phrase, such as a range of weekdays or times, into the part, returning false if
+// the next tokens aren't one it understands.
+func (p *phraseParser) parseClause(part *phrasePart) bool {
+	tok := p.tokens[p.i]
+	switch {
+	case tok.text == "24/7" || tok.text == "always" || tok.text == "anytime" || tok.text == "everyday" ||
+		tok.text == "daily" || (tok.text == "all" && p.is(1, "day")) || (tok.text == "any" && p.is(1, "time")) ||
+		(tok.text == "every" && p.is(1, "day")) || (tok.text == "around" && p.is(1, "the") && p.is(2, "clock")):
+		for !p.is(0, "24/7", "always", "anytime", "everyday", "daily", "day", "time", "clock") {
+			p.i++
+		}
+		p.i++
+		return true
+	case p.is(1, "hours") && (tok.text == "business" || tok.text == "office" || tok.text == "working" ||
+		tok.text == "work"):
+		p.warn(0, 2, "Assumed Monday to Friday from 09:00 to 17:00")
+		part.base.Weekdays = append(part.base.Weekdays, WeekdayRange{InclusiveRange{Begin: 1, End: 5}})
+		part.base.Times = append(part.base.Times, TimeRange{StartMinute: 540, EndMinute: 1020})
+		p.i += 2
+		return true
+	}
+	for _, parse := range []func(*phrasePart) bool{
+		p.parseDate,
+		p.parseTimes,
+		p.parseWeekdayOfMonth,
+		p.parseWeekdays,
+		p.parseMonths,
+		p.parseYears,
+		p.parseDaysOfMonth,
+	} {
+		if parse(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// phraseClock is a time of day as written in a phrase.
+type phraseClock struct {
+	seconds  int
+	meridiem string
+	// ambiguous is true if the time could be in the morning or the afternoon, and twentyFour is true if it is clearly
+	// written with the 24 hour clock, as in '09:00' or '17:00'.
+	ambiguous  bool
+	twentyFour bool
+}
+
+// clock returns the time of day written k places ahead. Numbers on their own are only taken to be hours if bare is
+// set.
+func (p *phraseParser) clock(k int, bare bool) (phraseClock, bool) {
+	tok, ok := p.tok(k)
+	if !ok {
+		return phraseClock{}, false
+	}
+	switch {
+	case tok.text == "noon" || tok.text == "midday":
+		return phraseClock{seconds: 12 * 3600}, true
+	case tok.text == "midnight":
+		return phraseClock{}, true
+	case tok.kind == phraseTime || (tok.kind == phraseNumber && bare):
+	default:
+		return phraseClock{}, false
+	}
+	m := phraseTimeRE.FindStringSubmatch(tok.text)
+	if m == nil {
+		return phraseClock{}, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	c := phraseClock{seconds: (hour*60 + minute) * 60, meridiem: strings.Replace(m[3], ".", "", -1)}
+	if minute > 59 || hour > 24 || (c.meridiem != "" && (hour < 1 || hour > 12)) || (hour == 24 && minute > 0) {
+		return phraseClock{}, false
+	}
+	c.twentyFour = c.meridiem == "" && (hour == 0 || hour > 12 || strings.HasPrefix(m[1], "0"))
+	c.ambiguous = c.meridiem == "" && !c.twentyFour
+	return c, true
+}
+
+// in returns the seconds of the day at the time with the given meridiem.
+func (c phraseClock) in(meridiem string) int {
+	switch {
+	case meridiem == "am" && c.seconds >= 12*3600:
+		return c.seconds - 12*3600
+	case meridiem == "pm" && c.seconds < 12*3600:
+		return c.seconds + 12*3600
+	}
+	return c.seconds
+}
+
+// parseTimes reads a range of times such as '9am to 5pm', '09:00-17:00' or 'from 9 to 5'.
+func (p *phraseParser) parseTimes(part *phrasePart) bool {
+	k := 0
+	context := p.is(0, "from", "between")
+	if context {
+		k++
+	}
+	start, ok := p.clock(k, true)
+	if !ok || !(p.isRange(k+1) || (p.is(0, "between") && p.is(k+1, "and"))) {
+		return false
+	}
+	end, ok := p.clock(k+2, true)
+	if !ok {
+		return false
+	}
+	from, to := start.seconds, end.seconds
+	switch {
+	case start.meridiem != "" && end.meridiem != "":
+		from, to = start.in(start.meridiem), end.in(end.meridiem)
+	case end.meridiem != "":
+		// '9 to 5pm' is 09:00 to 17:00 and '11 to 2pm' is 11:00 to 14:00.
+		to = end.in(end.meridiem)
+		if start.ambiguous {
+			if from = start.in(end.meridiem); from > to {
+				from = start.in("am")
+			}
+		}
+	case start.meridiem != "":
+		// '9am to 5' is 09:00 to 17:00.
+		from = start.in(start.meridiem)
+		if end.ambiguous {
+			if to = end.in(start.meridiem); to <= from {
+				to = end.in("pm")
+			}
+		}
+	case !start.twentyFour && !end.twentyFour && end.ambiguous && to <= from && to+12*3600 > from:
+		// Times that would otherwise run past midnight, as in '9 to 5', are taken to end in the afternoon.
+		to += 12 * 3600
+		p.warn(k, 3, "Assumed %s to %s, give am or pm to be sure", formatTime(from), formatTime(to))
+	}
+	if to == 0 {
+		to = secondsPerDay
+	}
+	if from == to || from >= secondsPerDay {
+		p.warn(0, k+3, "A range of times must have different start and end times, so it was left out")
+	} else {
+		part.base.Times = append(part.base.Times, timeRangeFromSeconds(from, to))
+	}
+	p.i += k + 3
+	return true
+}
+
+// weekday returns the day of the week named k places ahead, allowing plurals such as 'mondays'.
+func (p *phraseParser) weekday(k int) (int, bool) {
+	tok, ok := p.tok(k)
+	if !ok || tok.kind != phraseWord {
+		return 0, false
+	}
+	if day, ok := daysOfWeek[tok.text]; ok {
+		return day, true
+	}
+	day, ok := daysOfWeek[strings.TrimSuffix(tok.text, "s")]
+	return day, ok && len(tok.text) > 4
+}
+
+// parseWeekdays reads days of the week such as 'weekdays', 'weekends', 'mondays' or 'monday through friday'.
+func (p *phraseParser) parseWeekdays(part *phrasePart) bool {
+	switch {
+	case p.is(0, "weekdays", "weekday", "workdays", "workday") || (p.is(0, "business") && p.is(1, "days")):
+		part.base.Weekdays = append(part.base.Weekdays, WeekdayRange{InclusiveRange{Begin: 1, End: 5}})
+		p.i++
+		return true
+	case p.is(0, "weekends", "weekend"):
+		part.base.Weekdays = append(part.base.Weekdays, WeekdayRange{InclusiveRange{Begin: 6, End: 7}})
+		p.i++
+		return true
+	}
+	begin, ok := p.weekday(0)
+	if !ok {
+		return false
+	}
+	end, n := begin, 1
+	if p.isRange(1) {
+		if day, ok := p.weekday(2); ok {
+			end, n = day, 3
+		}
+	}
+	part.base.Weekdays = append(part.base.Weekdays, weekdayRanges(begin, end)...)
+	p.i += n
+	return true
+}
+
+// weekdayRanges returns ranges covering the days of the week from begin to end, which may wrap past Sunday.
+func weekdayRanges(begin, end int) []WeekdayRange {
+	switch {
+	case begin <= end:
+		return []WeekdayRange{{InclusiveRange{Begin: begin, End: end}}}
+	case end == 0:
+		return []WeekdayRange{{InclusiveRange{Begin: begin, End: 7}}}
+	}
+	return []WeekdayRange{{InclusiveRange{Begin: begin, End: 7}}, {InclusiveRange{Begin: 1, End: end}}}
+}
+
+// parseWeekdayOfMonth reads occurrences of a weekday within the month, such as 'the first monday', 'last friday of
+// every month' or '2nd tuesday of march', and the first or last day of the month.
+func (p *phraseParser) parseWeekdayOfMonth(part *phrasePart) bool {
+	tok, _ := p.tok(0)
+	n, ok := phraseOrdinals[tok.text]
+	if !ok && tok.kind == phraseOrdinal {
+		n, _ = strconv.Atoi(strings.TrimRight(tok.text, "stndrh"))
+		ok = n >= 1 && n <= 5
+	}
+	if !ok {
+		return false
+	}
+	k := 2
+	weekday, isWeekday := p.weekday(1)
+	switch {
+	case isWeekday:
+		part.base.WeekdaysOfMonth = append(part.base.WeekdaysOfMonth, WeekdayOfMonth{N: n, Weekday: time.Weekday(weekday)})
+	case p.is(1, "weekday", "workday") || (p.is(1, "business") && p.is(2, "day")):
+		if p.is(1, "business") {
+			k++
+		}
+		part.base.WeekdaysOfMonth = append(part.base.WeekdaysOfMonth, WeekdayOfMonth{N: n, AnyWeekday: true})
+	case p.is(1, "day") && (n == 1 || n == lastOccurrence):
+		part.base.DaysOfMonth = append(part.base.DaysOfMonth, DayOfMonthRange{InclusiveRange{Begin: n, End: n}})
+	default:
+		return false
+	}
+	// A month given afterwards, as in 'the last monday of may', limits the occurrences to that month.
+	if p.is(k, "of", "in") {
+		j := k + 1
+		for p.is(j, "the", "every", "each") {
+			j++
+		}
+		if month, ok := p.month(j); ok {
+			part.base.Months = append(part.base.Months, MonthRange{InclusiveRange{Begin: month, End: month}})
+			k = j + 1
+		}
+	}
+	p.i += k
+	return true
+}
+
+// month returns the month named k places ahead.
+func (p *phraseParser) month(k int) (int, bool) {
+	tok, ok := p.tok(k)
+	if !ok || tok.kind != phraseWord {
+		return 0, false
+	}
+	month, ok := months[tok.text]
+	return month, ok
+}
+
+// dayOfMonth returns the day of the month written k places ahead, such as '24' or '24th'.
+func (p *phraseParser) dayOfMonth(k int) (int, bool) {
+	tok, ok := p.tok(k)
+	if !ok || (tok.kind != phraseNumber && tok.kind != phraseOrdinal) {
+		return 0, false
+	}
+	day, err := strconv.Atoi(strings.TrimRight(tok.text, "stndrh"))
+	return day, err == nil && day >= 1 && day <= 31
+}
+
+// year returns the year written k places ahead.
+func (p *phraseParser) year(k int) (int, bool) {
+	tok, ok := p.tok(k)
+	if !ok || tok.kind != phraseNumber || len(tok.text) != 4 {
+		return 0, false
+	}
+	year, err := strconv.Atoi(tok.text)
+	return year, err == nil
+}
+
+// parseMonths reads months such as 'january', 'jan-mar' or 'october through march'.
+func (p *phraseParser) parseMonths(part *phrasePart) bool {
+	begin, ok := p.month(0)
+	if !ok {
+		return false
+	}
+	end, n := begin, 1
+	if p.isRange(1) {
+		if month, ok := p.month(2); ok {
+			end, n = month, 3
+		}
+	}
+	part.base.Months = append(part.base.Months, MonthRange{InclusiveRange{Begin: begin, End: end}})
+	p.i += n
+	return true
+}
+
+// parseYears reads years such as '2024' or '2020-2025'.
+func (p *phraseParser) parseYears(part *phrasePart) bool {
+	begin, ok := p.year(0)
+	if !ok {
+		return false
+	}
+	end, n := begin, 1
+	if p.isRange(1) {
+		if year, ok := p.year(2); ok {
+			end, n = year, 3
+		}
+	}
+	if end < begin {
+		p.warn(0, n, "A range of years must not end before it begins, so it was left out")
+	} else {
+		part.base.Years = append(part.base.Years, YearRange{InclusiveRange{Begin: begin, End: end}})
+	}
+	p.i += n
+	return true
+}
+
+// parseDaysOfMonth reads days of the month written as ordinals, such as 'the 1st' or 'the 1st to the 7th'.
+func (p *phraseParser) parseDaysOfMonth(part *phrasePart) bool {
+	tok, _ := p.tok(0)
+	begin, ok := p.dayOfMonth(0)
+	if !ok || tok.kind != phraseOrdinal {
+		return false
+	}
+	end, n := begin, 1
+	if p.isRange(1) {
+		k := 2
+		if p.is(k, "the") {
+			k++
+		}
+		if day, ok := p.dayOfMonth(k); ok && p.tokens[p.i+k].kind == phraseOrdinal && day >= begin {
+			end, n = day, k+1
+		}
+	}
+	part.base.DaysOfMonth = append(part.base.DaysOfMonth, DayOfMonthRange{InclusiveRange{Begin: begin, End: end}})
+	p.i += n
+	return true
+}
+
+// parseLocation reads an IANA time zone name such as 'Europe/London', or UTC.
+func (p *phraseParser) parseLocation() bool {
+	tok, _ := p.tok(0)
+	var loc *time.Location
+	switch {
+	case tok.text == "utc" || tok.text == "gmt":
+		loc = time.UTC
+	case phraseZoneAbbreviations[tok.text]:
+		p.warn(0, 1, "Time zone abbreviations are ambiguous, so it was left out; give a name such as Europe/London")
+		p.i++
+		return true
+	case tok.kind == phraseZone:
+		var err error
+		if loc, err = time.LoadLocation(tok.text); err != nil {
+			p.warn(0, 1, "Not a known time zone, so it was left out")
+			p.i++
+			return true
+		}
+	default:
+		return false
+	}
+	if p.location != nil && p.location.String() != loc.String() {
+		p.warn(0, 1, "Only one time zone may be given, so it was left out")
+	} else {
+		p.location = &Location{loc}
+	}
+	p.i++
+	return true
+}
+
+// parseDate reads dates such as 'december 24-26', '24 december', 'dec 24 - jan 2, 2025', '2024-12-24' or '12/24'.
+// Dates without a year are matched every year.
+func (p *phraseParser) parseDate(part *phrasePart) bool {
+	tok, _ := p.tok(0)
+	switch tok.kind {
+	case phraseISODate:
+		return p.parseNumericDate(part, p.isoDate)
+	case phraseSlashDate:
+		return p.parseNumericDate(part, p.slashDate)
+	}
+	// Read the month and day in either order, e.g. 'december 24' or '24th of december'.
+	var month, day, k int
+	if m, ok := p.month(0); ok {
+		d, ok := p.dayOfMonth(1)
+		if !ok {
+			return false
+		}
+		month, day, k = m, d, 2
+	} else if d, ok := p.dayOfMonth(0); ok {
+		j := 1
+		if p.is(j, "of") {
+			j++
+		}
+		m, ok := p.month(j)
+		if !ok {
+			// The month may come after the end of a range, as in '24-26 december'.
+			if !p.isRange(1) {
+				return false
+			}
+			last, ok := p.dayOfMonth(2)
+			j = 3
+			if p.is(j, "of") {
+				j++
+			}
+			if m, ok2 := p.month(j); ok && ok2 {
+				return p.addDates(part, m, d, m, last, j+1)
+			}
+			return false
+		}
+		month, day, k = m, d, j+1
+	} else {
+		return false
+	}
+	lastMonth, lastDay := month, day
+	if p.isRange(k) {
+		if m, ok := p.month(k + 1); ok {
+			if d, ok := p.dayOfMonth(k + 2); ok {
+				lastMonth, lastDay, k = m, d, k+3
+			}
+		} else if d, ok := p.dayOfMonth(k + 1); ok {
+			j := k + 2
+			if p.is(j, "of") {
+				j++
+			}
+			if m, ok := p.month(j); ok {
+				lastMonth, lastDay, k = m, d, j+1
+			} else {
+				lastDay, k = d, k+2
+			}
+		}
+	}
+	return p.addDates(part, month, day, lastMonth, lastDay, k)
+}
+
+// addDates adds the days from one month and day to another, each year or in the year written k places ahead, and
+// any other days of the last month listed afterwards, as in 'december 24, 25 and 31'.
+func (p *phraseParser) addDates(part *phrasePart, month, day, lastMonth, lastDay, k int) bool {
+	extra := []int{}
+	for {
+		j := k
+		for p.is(j, ",", "and", "&") {
+			j++
+		}
+		d, ok := p.dayOfMonth(j)
+		if !ok || j == k || p.isRange(j+1) {
+			break
+		}
+		extra = append(extra, d)
+		k = j + 1
+	}
+	j := k
+	if p.is(j, ",") {
+		j++
+	}
+	if year, ok := p.year(j); ok {
+		k = j + 1
+		firstYear := year
+		if lastMonth < month || (lastMonth == month && lastDay < day) {
+			firstYear--
+		}
+		begin := time.Date(firstYear, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		end := time.Date(year, time.Month(lastMonth), lastDay, 0, 0, 0, 0, time.UTC)
+		if begin.Day() != day || end.Day() != lastDay {
+			p.warn(0, k, "Not a valid date, so it was left out")
+			p.i += k
+			return true
+		}
+		part.days = append(part.days, TimeInterval{Dates: []DateRange{{Begin: begin, End: end}}})
+		for _, d := range extra {
+			date := time.Date(year, time.Month(lastMonth), d, 0, 0, 0, 0, time.UTC)
+			part.days = append(part.days, TimeInterval{Dates: []DateRange{{Begin: date, End: date}}})
+		}
+		p.i += k
+		return true
+	}
+	if lastMonth == month && lastDay < day {
+		p.warn(0, k, "A range of days must not end before it begins, so it was left out")
+		p.i += k
+		return true
+	}
+	part.days = append(part.days, yearlyDates(month, day, lastMonth, lastDay)...)
+	for _, d := range extra {
+		part.days = append(part.days, yearlyDates(lastMonth, d, lastMonth, d)...)
+	}
+	p.i += k
+	return true
+}
+
+// parseNumericDate reads a date written in numbers, or a range of them, using date to read each one.
+func (p *phraseParser) parseNumericDate(part *phrasePart, date func(k int) (TimeInterval, bool)) bool {
+	first, ok := date(0)
+	if !ok {
+		p.warn(0, 1, "Not a valid date, so it was left out")
+		p.i++
+		return true
+	}
+	n := 1
+	if tok, ok := p.tok(2); ok && p.isRange(1) && tok.kind == p.tokens[p.i].kind {
+		if last, ok := date(2); ok {
+			switch {
+			case first.Dates != nil && last.Dates != nil:
+				first.Dates[0].End = last.Dates[0].Begin
+				n = 3
+			case first.Dates == nil && last.Dates == nil:
+				begin, end := first.DaysOfMonth[0].Begin, last.DaysOfMonth[0].Begin
+				set := yearlyDates(first.Months[0].Begin, begin, last.Months[0].Begin, end)
+				part.days = append(part.days, set...)
+				p.i += 3
+				return true
+			}
+		}
+	}
+	if first.Dates != nil && first.Dates[0].End.Before(first.Dates[0].Begin) {
+		p.warn(0, n, "A range of dates must not end before it begins, so it was left out")
+	} else {
+		part.days = append(part.days, first)
+	}
+	p.i += n
+	return true
+}
+
+// isoDate returns an interval matching the date written as YYYY-MM-DD k places ahead.
+func (p *phraseParser) isoDate(k int) (TimeInterval, bool) {
+	tok, _ := p.tok(k)
+	var year, month, day int
+	if _, err := fmt.Sscanf(tok.text, "%d-%d-%d", &year, &month, &day); err != nil {
+		return TimeInterval{}, false
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return TimeInterval{}, false
+	}
+	return TimeInterval{Dates: []DateRange{{Begin: date, End: date}}}, true
+}
+
+// slashDate returns an interval matching the date written as MM/DD or MM/DD/YYYY k places ahead, warning if it could
+// also be read as DD/MM.
+func (p *phraseParser) slashDate(k int) (TimeInterval, bool) {
+	tok, _ := p.tok(k)
+	parts := strings.Split(tok.text, "/")
+	month, _ := strconv.Atoi(parts[0])
+	day, _ := strconv.Atoi(parts[1])
+	if month > 12 && day <= 12 {
+		month, day = day, month
+	} else if month <= 12 && day <= 12 && month != day {
+		p.warn(k, 1, "Assumed this is written month first, as %s %d", monthsInv[month], day)
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return TimeInterval{}, false
+	}
+	if len(parts) == 2 {
+		return TimeInterval{
+			Months:      []MonthRange{{InclusiveRange{Begin: month, End: month}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: day, End: day}}},
+		}, true
+	}
+	year, _ := strconv.Atoi(parts[2])
+	if len(parts[2]) == 2 {
+		year += 2000
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return TimeInterval{}, false
+	}
+	return TimeInterval{Dates: []DateRange{{Begin: date, End: date}}}, true
+}
@@ -0,0 +1,135 @@
+package gotime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePhrase(t *testing.T) {
+	for _, c := range []struct {
+		phrase   string
+		in       []string
+		out      []string
+		warnings int
+	}{
+		{
+			phrase: "weekdays 9am to 5pm except December 24-26",
+			in:     []string{"2024-12-23T09:00:00Z", "2024-12-27T16:59:59Z"},
+			out: []string{"2024-12-23T08:59:59Z", "2024-12-23T17:00:00Z", "2024-12-24T12:00:00Z", "2024-12-26T12:00:00Z",
+				"2024-12-28T12:00:00Z"},
+		},
+		{
+			phrase:   "from 9 to 5",
+			in:       []string{"2024-12-28T09:00:00Z", "2024-12-28T16:59:59Z"},
+			out:      []string{"2024-12-28T05:00:00Z", "2024-12-28T17:00:00Z"},
+			warnings: 1,
+		},
+		{
+			phrase: "mon-fri 9-5pm",
+			in:     []string{"2024-12-23T09:00:00Z", "2024-12-27T16:59:59Z"},
+			out:    []string{"2024-12-23T17:00:00Z", "2024-12-28T12:00:00Z"},
+		},
+		{
+			phrase: "the first monday of every month 10:00-11:30 in Europe/London",
+			in:     []string{"2024-07-01T09:00:00Z", "2024-12-02T11:29:59Z"},
+			out:    []string{"2024-07-01T10:30:00Z", "2024-07-08T09:00:00Z"},
+		},
+		{
+			phrase: "last friday of may",
+			in:     []string{"2024-05-31T12:00:00Z"},
+			out:    []string{"2024-05-24T12:00:00Z", "2024-06-28T12:00:00Z"},
+		},
+		{
+			phrase: "december 24 - january 2",
+			in:     []string{"2024-12-24T00:00:00Z", "2024-12-31T12:00:00Z", "2025-01-02T23:59:59Z"},
+			out:    []string{"2024-12-23T12:00:00Z", "2025-01-03T00:00:00Z", "2024-11-24T12:00:00Z"},
+		},
+		{
+			phrase: "2024-12-24 to 2025-01-02",
+			in:     []string{"2024-12-24T00:00:00Z", "2025-01-02T23:59:59Z"},
+			out:    []string{"2025-12-24T12:00:00Z", "2025-01-03T00:00:00Z"},
+		},
+		{
+			phrase: "24th of december, 2024 and 31 dec",
+			in:     []string{"2024-12-24T12:00:00Z", "2024-12-31T12:00:00Z", "2025-12-31T12:00:00Z"},
+			out:    []string{"2025-12-24T12:00:00Z"},
+		},
+		{
+			phrase:   "12/03",
+			in:       []string{"2024-12-03T12:00:00Z"},
+			out:      []string{"2024-03-12T12:00:00Z"},
+			warnings: 1,
+		},
+		{
+			phrase:   "weekends between noon and 1 during jan-mar 2020-2025; 24/7 in 2030",
+			in:       []string{"2024-01-06T12:00:00Z", "2024-03-31T12:59:59Z", "2030-06-05T03:00:00Z"},
+			out:      []string{"2024-01-06T13:00:00Z", "2024-04-06T12:00:00Z", "2026-01-03T12:00:00Z"},
+			warnings: 1,
+		},
+		{
+			phrase: "the 1st to the 7th, 10pm to 6am, not on sundays",
+			in:     []string{"2024-01-01T23:00:00Z", "2024-01-02T05:00:00Z"},
+			out:    []string{"2024-01-07T23:00:00Z", "2024-01-08T23:00:00Z", "2024-01-03T12:00:00Z"},
+		},
+		{
+			phrase:   "business hours EST",
+			in:       []string{"2024-01-02T09:00:00Z"},
+			out:      []string{"2024-01-02T17:00:00Z"},
+			warnings: 2,
+		},
+		{
+			phrase:   "mondays and wibbly wednesdays",
+			in:       []string{"2024-01-01T12:00:00Z", "2024-01-03T12:00:00Z"},
+			out:      []string{"2024-01-02T12:00:00Z"},
+			warnings: 1,
+		},
+	} {
+		set, warnings, err := ParsePhrase(c.phrase)
+		if err != nil {
+			t.Errorf("Error parsing %q: %v", c.phrase, err)
+			continue
+		}
+		if len(warnings) != c.warnings {
+			t.Errorf("Expected %d warnings parsing %q, got %v", c.warnings, c.phrase, warnings)
+		}
+		for _, times := range []struct {
+			ats   []string
+			match bool
+		}{{c.in, true}, {c.out, false}} {
+			for _, s := range times.ats {
+				at, err := time.Parse(time.RFC3339, s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := set.ContainsTime(at); got != times.match {
+					t.Errorf("Expected %q to contain %s: %t, got %t", c.phrase, s, times.match, got)
+				}
+			}
+		}
+	}
+}
+
+func TestParsePhraseWarnings(t *testing.T) {
+	_, warnings, err := ParsePhrase("weekdays 9 to 5 in EST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", warnings)
+	}
+	if warnings[0].Text != "9 to 5" || !strings.Contains(warnings[0].Message, "09:00 to 17:00") {
+		t.Errorf("Expected a warning about the times, got %v", warnings[0])
+	}
+	if warnings[1].Text != "EST" {
+		t.Errorf("Expected a warning about the time zone, got %v", warnings[1])
+	}
+}
+
+func TestParsePhraseErrors(t *testing.T) {
+	for _, phrase := range []string{"", "whenever you like", ";;"} {
+		if set, _, err := ParsePhrase(phrase); err == nil {
+			t.Errorf("Expected an error parsing %q, got %v", phrase, set)
+		}
+	}
+}
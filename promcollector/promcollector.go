@@ -0,0 +1,65 @@
+// Package promcollector exports the state of the schedules in a gotime.Registry as Prometheus metrics, with a
+// prometheus.Collector that can be registered alongside a service's other metrics. It is a module of its own so that
+// gotime doesn't depend on the Prometheus client library.
+package promcollector
+
+import (
+	"time"
+
+	"github.com/benridley/gotime"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	activeDesc = prometheus.NewDesc("gotime_interval_active",
+		"Whether the named interval is active, 1 if it is and 0 if not.", []string{"name"}, nil)
+	nextActivationDesc = prometheus.NewDesc("gotime_interval_next_activation_timestamp_seconds",
+		"When the named interval next becomes active, in seconds since the Unix epoch.", []string{"name"}, nil)
+	nextDeactivationDesc = prometheus.NewDesc("gotime_interval_next_deactivation_timestamp_seconds",
+		"When the named interval next stops being active, in seconds since the Unix epoch.", []string{"name"}, nil)
+)
+
+// A Collector collects the same metrics as gotime.WriteMetrics for each definition in a registry: whether it's active,
+// and when it next becomes active and stops being active. The registry is read each time metrics are collected, so
+// definitions that are added, replaced or removed are reflected in the next scrape.
+type Collector struct {
+	registry *gotime.Registry
+}
+
+// NewCollector returns a collector for the definitions in r.
+func NewCollector(r *gotime.Registry) *Collector {
+	return &Collector{registry: r}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeDesc
+	ch <- nextActivationDesc
+	ch <- nextDeactivationDesc
+}
+
+// Collect implements prometheus.Collector. Times that don't occur within the search horizon are left out.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	for name, set := range c.registry.Definitions() {
+		state := set.StateAt(now)
+		active := 0.0
+		if state.Active {
+			active = 1
+		}
+		ch <- prometheus.MustNewConstMetric(activeDesc, prometheus.GaugeValue, active, name)
+		if state.NextActivation != nil {
+			ch <- prometheus.MustNewConstMetric(nextActivationDesc, prometheus.GaugeValue,
+				unixSeconds(*state.NextActivation), name)
+		}
+		if state.NextDeactivation != nil {
+			ch <- prometheus.MustNewConstMetric(nextDeactivationDesc, prometheus.GaugeValue,
+				unixSeconds(*state.NextDeactivation), name)
+		}
+	}
+}
+
+// unixSeconds returns t as seconds since the Unix epoch.
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
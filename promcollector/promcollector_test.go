@@ -0,0 +1,52 @@
+package promcollector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benridley/gotime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	r := gotime.NewRegistry(gotime.Definitions{"always": gotime.IntervalSet{{}}})
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(NewCollector(r)); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP gotime_interval_active Whether the named interval is active, 1 if it is and 0 if not.
+# TYPE gotime_interval_active gauge
+gotime_interval_active{name="always"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+	// Reloaded definitions are seen by the next scrape.
+	r.Swap(gotime.Definitions{
+		"never":  gotime.IntervalSet{{Weekdays: []gotime.WeekdayRange{}}},
+		"always": gotime.IntervalSet{{Mode: gotime.ModeDeny}},
+	})
+	want = `# HELP gotime_interval_active Whether the named interval is active, 1 if it is and 0 if not.
+# TYPE gotime_interval_active gauge
+gotime_interval_active{name="always"} 0
+gotime_interval_active{name="never"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorTransitions(t *testing.T) {
+	r := gotime.NewRegistry(gotime.Definitions{"weekdays": gotime.IntervalSet{{
+		Weekdays: []gotime.WeekdayRange{{InclusiveRange: gotime.InclusiveRange{Begin: 1, End: 5}}},
+	}}})
+	c := NewCollector(r)
+	// Whichever day it is, a weekday interval next starts and stops within the search horizon.
+	for _, name := range []string{"gotime_interval_active", "gotime_interval_next_activation_timestamp_seconds",
+		"gotime_interval_next_deactivation_timestamp_seconds"} {
+		if n := testutil.CollectAndCount(c, name); n != 1 {
+			t.Errorf("Expected one %s, got %d", name, n)
+		}
+	}
+}
@@ -0,0 +1,725 @@
+package gotime
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Field numbers of the TimeInterval message in proto/gotime.proto.
+const (
+	protoName            = 1
+	protoDescription     = 2
+	protoLabels          = 3
+	protoMode            = 4
+	protoTimes           = 5
+	protoWeekdays        = 6
+	protoDaysOfMonth     = 7
+	protoWeekdaysOfMonth = 8
+	protoWeeks           = 9
+	protoMonths          = 10
+	protoQuarters        = 11
+	protoYears           = 12
+	protoRelative        = 13
+	protoDates           = 14
+	protoAbsolute        = 15
+	protoFiscalYearStart = 16
+	protoCycle           = 17
+	protoCalendar        = 18
+	protoHolidays        = 19
+	protoLocation        = 20
+	protoCoordinates     = 21
+	protoDST             = 22
+	protoExcept          = 23
+	protoEmptyFields     = 24
+	protoExpression      = 25
+)
+
+// Wire types of the protocol buffer encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errInvalidProto = errors.New("Couldn't parse protocol buffer, invalid format")
+
+// ToProto encodes the TimeInterval as a TimeInterval message, defined in proto/gotime.proto, in the protocol buffer
+// wire format. Every field is kept, so FromProto gives back an identical interval, though holidays and calendar
+// systems are carried by name and so must be registered wherever the message is decoded.
+func (tp TimeInterval) ToProto() ([]byte, error) {
+	var w protoWriter
+	if err := tp.writeProto(&w); err != nil {
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+// FromProto decodes a TimeInterval message, defined in proto/gotime.proto, from the protocol buffer wire format.
+func FromProto(data []byte) (TimeInterval, error) {
+	var tp TimeInterval
+	if err := tp.readProto(data); err != nil {
+		return TimeInterval{}, err
+	}
+	return tp, nil
+}
+
+func (tp TimeInterval) writeProto(w *protoWriter) error {
+	w.string(protoName, tp.Name)
+	w.string(protoDescription, tp.Description)
+	keys := make([]string, 0, len(tp.Labels))
+	for k := range tp.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.message(protoLabels, func(m *protoWriter) {
+			m.string(1, k)
+			m.string(2, tp.Labels[k])
+		})
+	}
+	w.int(protoMode, int64(tp.Mode))
+	var empty []int64
+	// list records a list field that is present but empty, which proto3 would otherwise lose.
+	list := func(field int, isNil bool, n int) {
+		if !isNil && n == 0 {
+			empty = append(empty, int64(field))
+		}
+	}
+	list(protoTimes, tp.Times == nil, len(tp.Times))
+	for _, tr := range tp.Times {
+		tr := tr
+		w.message(protoTimes, func(m *protoWriter) {
+			m.int(1, int64(tr.startSecond()))
+			m.int(2, int64(tr.endSecond()))
+			for i, s := range []*SolarTime{tr.StartSolar, tr.EndSolar} {
+				if s != nil {
+					s := s
+					m.message(3+i, func(sm *protoWriter) {
+						sm.int(1, int64(s.Event))
+						sm.int(2, int64(s.Offset))
+					})
+				}
+			}
+		})
+	}
+	list(protoWeekdays, tp.Weekdays == nil, len(tp.Weekdays))
+	for _, r := range tp.Weekdays {
+		w.inclusiveRange(protoWeekdays, r.InclusiveRange)
+	}
+	list(protoDaysOfMonth, tp.DaysOfMonth == nil, len(tp.DaysOfMonth))
+	for _, r := range tp.DaysOfMonth {
+		w.inclusiveRange(protoDaysOfMonth, r.InclusiveRange)
+	}
+	list(protoWeekdaysOfMonth, tp.WeekdaysOfMonth == nil, len(tp.WeekdaysOfMonth))
+	for _, wom := range tp.WeekdaysOfMonth {
+		wom := wom
+		w.message(protoWeekdaysOfMonth, func(m *protoWriter) {
+			m.sint(1, int64(wom.N))
+			m.int(2, int64(wom.Weekday))
+			m.bool(3, wom.AnyWeekday)
+		})
+	}
+	list(protoWeeks, tp.Weeks == nil, len(tp.Weeks))
+	for _, r := range tp.Weeks {
+		w.inclusiveRange(protoWeeks, r.InclusiveRange)
+	}
+	list(protoMonths, tp.Months == nil, len(tp.Months))
+	for _, r := range tp.Months {
+		w.inclusiveRange(protoMonths, r.InclusiveRange)
+	}
+	list(protoQuarters, tp.Quarters == nil, len(tp.Quarters))
+	for _, r := range tp.Quarters {
+		w.inclusiveRange(protoQuarters, r.InclusiveRange)
+	}
+	list(protoYears, tp.Years == nil, len(tp.Years))
+	for _, r := range tp.Years {
+		w.inclusiveRange(protoYears, r.InclusiveRange)
+	}
+	list(protoRelative, tp.Relative == nil, len(tp.Relative))
+	for _, r := range tp.Relative {
+		r := r
+		w.message(protoRelative, func(m *protoWriter) {
+			m.string(1, r.Feast)
+			m.sint(2, int64(r.Begin))
+			m.sint(3, int64(r.End))
+		})
+	}
+	list(protoDates, tp.Dates == nil, len(tp.Dates))
+	for _, r := range tp.Dates {
+		r := r
+		w.message(protoDates, func(m *protoWriter) {
+			m.date(1, r.Begin)
+			m.date(2, r.End)
+		})
+	}
+	list(protoAbsolute, tp.Absolute == nil, len(tp.Absolute))
+	for _, r := range tp.Absolute {
+		r := r
+		w.message(protoAbsolute, func(m *protoWriter) {
+			m.timestamp(1, r.Start)
+			m.timestamp(2, r.End)
+			_, startOffset := r.Start.Zone()
+			_, endOffset := r.End.Zone()
+			m.int(3, int64(startOffset))
+			m.int(4, int64(endOffset))
+		})
+	}
+	w.int(protoFiscalYearStart, int64(tp.FiscalYearStart))
+	if tp.Cycle != nil {
+		w.message(protoCycle, func(m *protoWriter) {
+			m.date(1, tp.Cycle.Anchor)
+			m.int(2, int64(tp.Cycle.Every))
+			m.int(3, int64(tp.Cycle.Unit))
+		})
+	}
+	if tp.Calendar != nil {
+		if tp.Calendar.System == "" {
+			return fmt.Errorf("Unable to marshal a calendar system without a name")
+		}
+		w.message(protoCalendar, func(m *protoWriter) {
+			m.string(1, tp.Calendar.System)
+			for _, r := range tp.Calendar.Dates {
+				r := r
+				m.message(2, func(rm *protoWriter) {
+					rm.int(1, int64(r.Begin.Month))
+					rm.int(2, int64(r.Begin.Day))
+					rm.int(3, int64(r.End.Month))
+					rm.int(4, int64(r.End.Day))
+				})
+			}
+		})
+	}
+	if tp.Holidays != nil {
+		if tp.Holidays.Name == "" {
+			return fmt.Errorf("Unable to marshal holidays without a name")
+		}
+		w.string(protoHolidays, tp.Holidays.Name)
+	}
+	if tp.Expression != nil {
+		w.string(protoExpression, tp.Expression.String())
+	}
+	if tp.Location != nil {
+		w.string(protoLocation, tp.Location.String())
+	}
+	if tp.Coordinates != nil {
+		w.message(protoCoordinates, func(m *protoWriter) {
+			m.double(1, tp.Coordinates.Latitude)
+			m.double(2, tp.Coordinates.Longitude)
+		})
+	}
+	if tp.DST != (DSTPolicy{}) {
+		w.message(protoDST, func(m *protoWriter) {
+			m.int(1, int64(tp.DST.Gap))
+			m.int(2, int64(tp.DST.Overlap))
+		})
+	}
+	list(protoExcept, tp.Except == nil, len(tp.Except))
+	for _, ex := range tp.Except {
+		var m protoWriter
+		if err := ex.writeProto(&m); err != nil {
+			return err
+		}
+		w.bytes(protoExcept, m.buf)
+	}
+	w.packed(protoEmptyFields, empty)
+	return nil
+}
+
+func (tp *TimeInterval) readProto(data []byte) error {
+	var empty []int
+	err := readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+		var err error
+		switch field {
+		case protoName:
+			tp.Name = string(data)
+		case protoDescription:
+			tp.Description = string(data)
+		case protoLabels:
+			var k, val string
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+				switch field {
+				case 1:
+					k = string(data)
+				case 2:
+					val = string(data)
+				}
+				return nil
+			})
+			if tp.Labels == nil {
+				tp.Labels = make(map[string]string)
+			}
+			tp.Labels[k] = val
+		case protoMode:
+			tp.Mode = Mode(v)
+		case protoTimes:
+			var tr TimeRange
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+				switch field {
+				case 1:
+					tr.StartMinute, tr.StartSecond = int(v)/60, int(v)%60
+				case 2:
+					tr.EndMinute, tr.EndSecond = int(v)/60, int(v)%60
+				case 3, 4:
+					var s SolarTime
+					if err := readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+						switch field {
+						case 1:
+							s.Event = SolarEvent(v)
+						case 2:
+							s.Offset = time.Duration(v)
+						}
+						return nil
+					}); err != nil {
+						return err
+					}
+					if field == 3 {
+						tr.StartSolar = &s
+					} else {
+						tr.EndSolar = &s
+					}
+				}
+				return nil
+			})
+			tp.Times = append(tp.Times, tr)
+		case protoWeekdays:
+			var r InclusiveRange
+			r, err = readInclusiveRange(data)
+			tp.Weekdays = append(tp.Weekdays, WeekdayRange{r})
+		case protoDaysOfMonth:
+			var r InclusiveRange
+			r, err = readInclusiveRange(data)
+			tp.DaysOfMonth = append(tp.DaysOfMonth, DayOfMonthRange{r})
+		case protoWeekdaysOfMonth:
+			var wom WeekdayOfMonth
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+				switch field {
+				case 1:
+					wom.N = int(zigzag(v))
+				case 2:
+					wom.Weekday = time.Weekday(v)
+				case 3:
+					wom.AnyWeekday = v != 0
+				}
+				return nil
+			})
+			tp.WeekdaysOfMonth = append(tp.WeekdaysOfMonth, wom)
+		case protoWeeks:
+			var r InclusiveRange
+			r, err = readInclusiveRange(data)
+			tp.Weeks = append(tp.Weeks, WeekRange{r})
+		case protoMonths:
+			var r InclusiveRange
+			r, err = readInclusiveRange(data)
+			tp.Months = append(tp.Months, MonthRange{r})
+		case protoQuarters:
+			var r InclusiveRange
+			r, err = readInclusiveRange(data)
+			tp.Quarters = append(tp.Quarters, QuarterRange{r})
+		case protoYears:
+			var r InclusiveRange
+			r, err = readInclusiveRange(data)
+			tp.Years = append(tp.Years, YearRange{r})
+		case protoRelative:
+			var r RelativeRange
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+				switch field {
+				case 1:
+					r.Feast = string(data)
+				case 2:
+					r.Begin = int(zigzag(v))
+				case 3:
+					r.End = int(zigzag(v))
+				}
+				return nil
+			})
+			tp.Relative = append(tp.Relative, r)
+		case protoDates:
+			var r DateRange
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) (err error) {
+				switch field {
+				case 1:
+					r.Begin, err = readDate(data)
+				case 2:
+					r.End, err = readDate(data)
+				}
+				return err
+			})
+			tp.Dates = append(tp.Dates, r)
+		case protoAbsolute:
+			var r AbsoluteRange
+			var startOffset, endOffset int
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) (err error) {
+				switch field {
+				case 1:
+					r.Start, err = readTimestamp(data)
+				case 2:
+					r.End, err = readTimestamp(data)
+				case 3:
+					startOffset = int(int32(v))
+				case 4:
+					endOffset = int(int32(v))
+				}
+				return err
+			})
+			r.Start, r.End = inOffset(r.Start, startOffset), inOffset(r.End, endOffset)
+			tp.Absolute = append(tp.Absolute, r)
+		case protoFiscalYearStart:
+			tp.FiscalYearStart = FiscalYearStart(v)
+		case protoCycle:
+			c := &Cycle{}
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) (err error) {
+				switch field {
+				case 1:
+					c.Anchor, err = readDate(data)
+				case 2:
+					c.Every = int(v)
+				case 3:
+					c.Unit = CycleUnit(v)
+				}
+				return err
+			})
+			tp.Cycle = c
+		case protoCalendar:
+			c := &CalendarDates{}
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+				switch field {
+				case 1:
+					c.System = string(data)
+				case 2:
+					var r CalendarDateRange
+					if err := readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+						switch field {
+						case 1:
+							r.Begin.Month = int(v)
+						case 2:
+							r.Begin.Day = int(v)
+						case 3:
+							r.End.Month = int(v)
+						case 4:
+							r.End.Day = int(v)
+						}
+						return nil
+					}); err != nil {
+						return err
+					}
+					c.Dates = append(c.Dates, r)
+				}
+				return nil
+			})
+			cs, ok := lookupCalendarSystem(c.System)
+			if !ok {
+				return fmt.Errorf("%s is not a known calendar system", c.System)
+			}
+			c.CalendarSystem = cs
+			tp.Calendar = c
+		case protoHolidays:
+			p, ok := lookupHolidayProvider(string(data))
+			if !ok {
+				return fmt.Errorf("%s is not a known holiday calendar", data)
+			}
+			tp.Holidays = &Holidays{Name: string(data), HolidayProvider: p}
+		case protoExpression:
+			tp.Expression, err = ParseExpression(string(data))
+		case protoLocation:
+			loc, err := time.LoadLocation(string(data))
+			if err != nil {
+				return fmt.Errorf("%s is not a valid location: %v", data, err)
+			}
+			tp.Location = &Location{loc}
+		case protoCoordinates:
+			c := &Coordinates{}
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+				switch field {
+				case 1:
+					c.Latitude = math.Float64frombits(v)
+				case 2:
+					c.Longitude = math.Float64frombits(v)
+				}
+				return nil
+			})
+			tp.Coordinates = c
+		case protoDST:
+			err = readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+				switch field {
+				case 1:
+					tp.DST.Gap = GapPolicy(v)
+				case 2:
+					tp.DST.Overlap = OverlapPolicy(v)
+				}
+				return nil
+			})
+		case protoExcept:
+			var ex TimeInterval
+			err = ex.readProto(data)
+			tp.Except = append(tp.Except, ex)
+		case protoEmptyFields:
+			if wire == wireVarint {
+				empty = append(empty, int(v))
+				break
+			}
+			err = readPackedVarints(data, func(v uint64) {
+				empty = append(empty, int(v))
+			})
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, field := range empty {
+		switch field {
+		case protoTimes:
+			tp.Times = []TimeRange{}
+		case protoWeekdays:
+			tp.Weekdays = []WeekdayRange{}
+		case protoDaysOfMonth:
+			tp.DaysOfMonth = []DayOfMonthRange{}
+		case protoWeekdaysOfMonth:
+			tp.WeekdaysOfMonth = []WeekdayOfMonth{}
+		case protoWeeks:
+			tp.Weeks = []WeekRange{}
+		case protoMonths:
+			tp.Months = []MonthRange{}
+		case protoQuarters:
+			tp.Quarters = []QuarterRange{}
+		case protoYears:
+			tp.Years = []YearRange{}
+		case protoRelative:
+			tp.Relative = []RelativeRange{}
+		case protoDates:
+			tp.Dates = []DateRange{}
+		case protoAbsolute:
+			tp.Absolute = []AbsoluteRange{}
+		case protoExcept:
+			tp.Except = []TimeInterval{}
+		}
+	}
+	return nil
+}
+
+// A protoWriter appends fields to a message in the protocol buffer wire format. As in proto3, scalar fields with their
+// zero value are left out.
+type protoWriter struct {
+	buf []byte
+}
+
+func (w *protoWriter) uvarint(v uint64) {
+	for v >= 0x80 {
+		w.buf = append(w.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	w.buf = append(w.buf, byte(v))
+}
+
+func (w *protoWriter) tag(field, wire int) {
+	w.uvarint(uint64(field)<<3 | uint64(wire))
+}
+
+// int writes an int32, int64 or enum field.
+func (w *protoWriter) int(field int, v int64) {
+	if v != 0 {
+		w.tag(field, wireVarint)
+		w.uvarint(uint64(v))
+	}
+}
+
+// sint writes a sint32 or sint64 field, which are zigzag encoded so that small negative numbers stay short.
+func (w *protoWriter) sint(field int, v int64) {
+	if v != 0 {
+		w.tag(field, wireVarint)
+		w.uvarint(uint64(v<<1) ^ uint64(v>>63))
+	}
+}
+
+func (w *protoWriter) bool(field int, v bool) {
+	if v {
+		w.int(field, 1)
+	}
+}
+
+func (w *protoWriter) double(field int, v float64) {
+	if v != 0 {
+		w.tag(field, wireFixed64)
+		w.buf = append(w.buf, make([]byte, 8)...)
+		binary.LittleEndian.PutUint64(w.buf[len(w.buf)-8:], math.Float64bits(v))
+	}
+}
+
+func (w *protoWriter) bytes(field int, b []byte) {
+	w.tag(field, wireBytes)
+	w.uvarint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *protoWriter) string(field int, s string) {
+	if s != "" {
+		w.bytes(field, []byte(s))
+	}
+}
+
+// message writes an embedded message, which is written even if all of its fields are empty.
+func (w *protoWriter) message(field int, encode func(m *protoWriter)) {
+	var m protoWriter
+	encode(&m)
+	w.bytes(field, m.buf)
+}
+
+// packed writes a repeated varint field in the packed encoding used by default in proto3.
+func (w *protoWriter) packed(field int, vs []int64) {
+	if len(vs) == 0 {
+		return
+	}
+	var m protoWriter
+	for _, v := range vs {
+		m.uvarint(uint64(v))
+	}
+	w.bytes(field, m.buf)
+}
+
+func (w *protoWriter) inclusiveRange(field int, r InclusiveRange) {
+	w.message(field, func(m *protoWriter) {
+		m.sint(1, int64(r.Begin))
+		m.sint(2, int64(r.End))
+	})
+}
+
+// date writes the calendar date of t as a Date message.
+func (w *protoWriter) date(field int, t time.Time) {
+	w.message(field, func(m *protoWriter) {
+		m.int(1, int64(t.Year()))
+		m.int(2, int64(t.Month()))
+		m.int(3, int64(t.Day()))
+	})
+}
+
+// timestamp writes t as a google.protobuf.Timestamp message.
+func (w *protoWriter) timestamp(field int, t time.Time) {
+	w.message(field, func(m *protoWriter) {
+		m.int(1, t.Unix())
+		m.int(2, int64(t.Nanosecond()))
+	})
+}
+
+// readProtoFields calls fn for each field of a message in the protocol buffer wire format. Varint and fixed width
+// values are passed in v and length delimited values in data.
+func readProtoFields(data []byte, fn func(field, wire int, v uint64, data []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errInvalidProto
+		}
+		data = data[n:]
+		field, wire := int(key>>3), int(key&7)
+		var v uint64
+		var value []byte
+		switch wire {
+		case wireVarint:
+			if v, n = binary.Uvarint(data); n <= 0 {
+				return errInvalidProto
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return errInvalidProto
+			}
+			v, data = binary.LittleEndian.Uint64(data), data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return errInvalidProto
+			}
+			v, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errInvalidProto
+			}
+			value, data = data[n:n+int(length)], data[n+int(length):]
+		default:
+			return errInvalidProto
+		}
+		if err := fn(field, wire, v, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPackedVarints(data []byte, fn func(v uint64)) error {
+	for len(data) > 0 {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errInvalidProto
+		}
+		fn(v)
+		data = data[n:]
+	}
+	return nil
+}
+
+// zigzag decodes a sint32 or sint64 value.
+func zigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func readInclusiveRange(data []byte) (InclusiveRange, error) {
+	var r InclusiveRange
+	err := readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			r.Begin = int(zigzag(v))
+		case 2:
+			r.End = int(zigzag(v))
+		}
+		return nil
+	})
+	return r, err
+}
+
+// readDate reads a Date message as midnight UTC on that date.
+func readDate(data []byte) (time.Time, error) {
+	var year, month, day int
+	err := readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			year = int(int32(v))
+		case 2:
+			month = int(v)
+		case 3:
+			day = int(v)
+		}
+		return nil
+	})
+	return date(year, time.Month(month), day), err
+}
+
+// readTimestamp reads a google.protobuf.Timestamp message.
+func readTimestamp(data []byte) (time.Time, error) {
+	var seconds, nanos int64
+	err := readProtoFields(data, func(field, wire int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			seconds = int64(v)
+		case 2:
+			nanos = int64(int32(v))
+		}
+		return nil
+	})
+	return time.Unix(seconds, nanos).UTC(), err
+}
+
+// inOffset returns t in a location with the given offset from UTC, in the same way time.Parse gives the location of a
+// timestamp with a numeric offset.
+func inOffset(t time.Time, offset int) time.Time {
+	if offset == 0 {
+		return t
+	}
+	if _, localOffset := t.In(time.Local).Zone(); localOffset == offset {
+		return t.In(time.Local)
+	}
+	return t.In(time.FixedZone("", offset))
+}
@@ -0,0 +1,127 @@
+package gotime
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var protoTestCases = []string{
+	`
+name: 'business hours'
+description: 'When the office is open'
+labels: {team: 'sre', tier: '1'}
+weekdays: ['monday:friday']
+times:
+  - start_time: '09:00'
+    end_time: '17:00:30'
+  - start_time: '22:00'
+    end_time: '06:00'
+days_of_month: ['-7:-1', '2nd tuesday', 'last weekday']
+weeks: ['1:2']
+months: ['november:february']
+years: ['2020:2025']
+location: 'Australia/Melbourne'
+dst: {gap: shift, overlap: second}
+except:
+  - days_of_month: ['25']
+    months: ['december']
+  - times:
+      - start_time: '12:00'
+        end_time: '13:00'
+`,
+	`
+mode: deny
+fiscal_year_start: 'july'
+quarters: ['q1', '3:4']
+relative: ['easter-2:easter+1']
+dates: ['2024-12-24:2025-01-02']
+absolute:
+  - start: '2024-06-01T22:00:00+10:00'
+    end: '2024-06-02T02:00:00Z'
+cycle: {anchor: '2024-01-01', every: '2w'}
+holidays: 'us-federal'
+expression: 'day % 2 == 0 && !(hour < 9)'
+`,
+	`
+coordinates: {latitude: -37.8136, longitude: 144.9631}
+times:
+  - start_time: 'sunset-30m'
+    end_time: 'sunrise'
+calendar: {system: 'hebrew', dates: ['1-15:1-22', '9']}
+`,
+	"weekdays: []",
+	"{}",
+}
+
+// checkRoundTrip encodes and decodes each of protoTestCases with an encoding, and checks that the result is the same
+// interval.
+func checkRoundTrip(t *testing.T, encode func(TimeInterval) ([]byte, error),
+	decode func(*TimeInterval, []byte) error) {
+	t.Helper()
+	for _, in := range protoTestCases {
+		var ti TimeInterval
+		if err := yaml.Unmarshal([]byte(in), &ti); err != nil {
+			t.Fatalf("Received unexpected error: %v when parsing %s", err, in)
+		}
+		data, err := encode(ti)
+		if err != nil {
+			t.Errorf("Received unexpected error: %v when encoding %s", err, in)
+			continue
+		}
+		var ti2 TimeInterval
+		if err := decode(&ti2, data); err != nil {
+			t.Errorf("Received unexpected error: %v when decoding %s", err, in)
+			continue
+		}
+		// Calendar systems are functions, which are never deeply equal, so compare them by what they marshal to.
+		want, _ := yaml.Marshal(ti)
+		got, _ := yaml.Marshal(ti2)
+		if ti.Calendar == nil && !reflect.DeepEqual(ti, ti2) || !bytes.Equal(want, got) {
+			t.Errorf("Decoding %s produced a different TimeInterval: %s", in, got)
+		}
+	}
+}
+
+func TestProto(t *testing.T) {
+	checkRoundTrip(t, TimeInterval.ToProto, func(ti *TimeInterval, data []byte) error {
+		var err error
+		*ti, err = FromProto(data)
+		return err
+	})
+}
+
+func TestProtoWireFormat(t *testing.T) {
+	ti := TimeInterval{
+		Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -1, End: -1}}},
+		Location:    mustLoadLocation("UTC"),
+	}
+	data, err := ti.ToProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x32, 0x04, 0x08, 0x02, 0x10, 0x0a, // weekdays {begin: 1, end: 5}
+		0x3a, 0x04, 0x08, 0x01, 0x10, 0x01, // days_of_month {begin: -1, end: -1}
+		0xa2, 0x01, 0x03, 'U', 'T', 'C', // location
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Encoding %+v: want % x, got % x", ti, want, data)
+	}
+	// Repeated scalars may also be sent unpacked.
+	unpacked := []byte{0xc0, 0x01, 0x06}
+	if ti, err := FromProto(unpacked); err != nil || ti.Weekdays == nil || len(ti.Weekdays) != 0 {
+		t.Errorf("Expected an unpacked empty field to give an empty list of weekdays, got %+v, %v", ti, err)
+	}
+	for _, data := range [][]byte{{0x32, 0x05, 0x08}, {0xa2}, {0x0b}} {
+		if _, err := FromProto(data); err == nil {
+			t.Errorf("Expected decoding % x to fail", data)
+		}
+	}
+	if _, err := FromProto([]byte{0x9a, 0x01, 0x03, 'n', 'o', 'w'}); err == nil {
+		t.Errorf("Expected decoding an unknown holiday calendar to fail")
+	}
+}
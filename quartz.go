@@ -0,0 +1,187 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	quartzSecond = cronField{name: "second", min: 0, max: 59}
+	// Quartz numbers the days of the week from 1 (Sunday) to 7 (Saturday).
+	quartzWeekday = cronField{name: "day of the week", min: 1, max: 7, names: quartzWeekdayNames}
+	quartzYear    = cronField{name: "year", min: 1970, max: 2099}
+)
+
+var quartzWeekdayNames = func() map[string]int {
+	names := make(map[string]int, len(daysOfWeek))
+	for name, day := range daysOfWeek {
+		names[name] = day + 1
+	}
+	return names
+}()
+
+var quartzLastDayRE = regexp.MustCompile(`^l(?:-([0-9]+))?$`)
+var quartzNearestWeekdayRE = regexp.MustCompile(`^([0-9]+)w$`)
+var quartzNthWeekdayRE = regexp.MustCompile(`^([a-z]+|[0-9]+)#([0-9]+)$`)
+var quartzLastWeekdayRE = regexp.MustCompile(`^([a-z]+|[0-9]+)l$`)
+
+// quartzMaxNearestWeekday is the largest day of the month whose nearest weekday can be matched. The nearest weekday to
+// a later day depends on the length of the month.
+const quartzMaxNearestWeekday = 27
+
+// FromQuartz converts a Quartz cron expression, such as '0 0/30 9-17 ? * MON-FRI', into a TimeInterval that is active
+// during each second in which the job would fire. The expression has fields for seconds, minutes, hours, the day of
+// the month, the month, the day of the week and optionally the year, and one of the two day fields must be '?'. Days
+// of the week are numbered from 1 (Sunday) to 7 (Saturday) as in Quartz. The L, W and # tokens are understood:
+// 'L' and 'L-3' are the last and third to last days of the month, '15W' the weekday nearest the 15th and 'LW' the last
+// weekday of the month, while '6L' is the last Friday of the month and '6#3' the third.
+func FromQuartz(expr string) (TimeInterval, error) {
+	fields := strings.Fields(strings.ToLower(expr))
+	if len(fields) != 6 && len(fields) != 7 {
+		return TimeInterval{}, fmt.Errorf("Couldn't parse Quartz expression %s, expected 6 or 7 fields", expr)
+	}
+	return fromQuartzFields(fields, quartzYear)
+}
+
+// fromQuartzFields converts the lower case fields of a Quartz expression into a TimeInterval, reading the year field,
+// if there is one, with years.
+func fromQuartzFields(fields []string, years cronField) (TimeInterval, error) {
+	sets, err := parseCronFields([]string{fields[0], fields[1], fields[2], fields[4]},
+		[]cronField{quartzSecond, cronMinute, cronHour, cronMonth})
+	if err != nil {
+		return TimeInterval{}, err
+	}
+	ti := TimeInterval{Times: cronTimes(sets[2], sets[1], sets[0])}
+	for _, r := range cronRuns(sets[3], cronMonth.min) {
+		ti.Months = append(ti.Months, MonthRange{r})
+	}
+	if len(fields) == 7 {
+		set, err := years.parse(fields[6])
+		if err != nil {
+			return TimeInterval{}, err
+		}
+		for _, r := range cronRuns(set, years.min) {
+			ti.Years = append(ti.Years, YearRange{r})
+		}
+	}
+	dom, dow := fields[3], fields[5]
+	if (dom == "?") == (dow == "?") {
+		return TimeInterval{}, errors.New("One of the day of the month and day of the week must be '?'")
+	}
+	if dom != "?" {
+		err = ti.setQuartzDaysOfMonth(dom)
+	} else {
+		err = ti.setQuartzWeekdays(dow)
+	}
+	if err != nil {
+		return TimeInterval{}, err
+	}
+	return ti, nil
+}
+
+// setQuartzDaysOfMonth sets the days of the month that the day of the month field of a Quartz expression matches.
+func (tp *TimeInterval) setQuartzDaysOfMonth(spec string) error {
+	if spec == "lw" {
+		tp.WeekdaysOfMonth = []WeekdayOfMonth{{N: lastOccurrence, AnyWeekday: true}}
+		return nil
+	}
+	if m := quartzLastDayRE.FindStringSubmatch(spec); m != nil {
+		before := 0
+		if m[1] != "" {
+			before, _ = strconv.Atoi(m[1])
+		}
+		if before > 30 {
+			return fmt.Errorf("%s is not a valid Quartz day of the month: out of range", spec)
+		}
+		tp.DaysOfMonth = []DayOfMonthRange{{InclusiveRange{Begin: -1 - before, End: -1 - before}}}
+		return nil
+	}
+	if m := quartzNearestWeekdayRE.FindStringSubmatch(spec); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		if day < 1 || day > quartzMaxNearestWeekday {
+			return fmt.Errorf("%s is not a supported Quartz day of the month: the nearest weekday is only "+
+				"supported up to the %dth", spec, quartzMaxNearestWeekday)
+		}
+		tp.setNearestWeekday(day)
+		return nil
+	}
+	days, err := cronDayOfMonth.parse(spec)
+	if err != nil {
+		return err
+	}
+	for _, r := range cronRuns(days, cronDayOfMonth.min) {
+		tp.DaysOfMonth = append(tp.DaysOfMonth, DayOfMonthRange{r})
+	}
+	return nil
+}
+
+// setNearestWeekday restricts the interval to the weekday nearest to the given day of the month, within the same
+// month. The day itself matches if it is a weekday, the Friday before if it is a Saturday, or the Monday after if it
+// is a Sunday. When the 1st is a Saturday, the Monday after is the 3rd.
+func (tp *TimeInterval) setNearestWeekday(day int) {
+	// only returns an exception for the given day of the month on every day of the week but those given.
+	only := func(dayOfMonth int, weekdays ...time.Weekday) TimeInterval {
+		allowed := make([]bool, 7)
+		for _, wd := range weekdays {
+			allowed[wd] = true
+		}
+		except := TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: dayOfMonth, End: dayOfMonth}}}}
+		for _, r := range cronRuns(complement(allowed), 0) {
+			except.Weekdays = append(except.Weekdays, WeekdayRange{r})
+		}
+		return except
+	}
+	first, last := day-1, day+1
+	if day == 1 {
+		first, last = 1, 3
+	}
+	tp.DaysOfMonth = []DayOfMonthRange{{InclusiveRange{Begin: first, End: last}}}
+	tp.Except = append(tp.Except, only(day, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday))
+	if day == 1 {
+		tp.Except = append(tp.Except, only(2, time.Monday), only(3, time.Monday))
+		return
+	}
+	tp.Except = append(tp.Except, only(day-1, time.Friday), only(day+1, time.Monday))
+}
+
+// setQuartzWeekdays sets the days of the week that the day of the week field of a Quartz expression matches.
+func (tp *TimeInterval) setQuartzWeekdays(spec string) error {
+	if spec == "l" {
+		// On its own, L is the last day of the week.
+		tp.Weekdays = []WeekdayRange{{InclusiveRange{Begin: int(time.Saturday), End: int(time.Saturday)}}}
+		return nil
+	}
+	if m := quartzLastWeekdayRE.FindStringSubmatch(spec); m != nil {
+		day, err := quartzWeekday.value(m[1])
+		if err != nil {
+			return err
+		}
+		tp.WeekdaysOfMonth = []WeekdayOfMonth{{N: lastOccurrence, Weekday: time.Weekday(day - 1)}}
+		return nil
+	}
+	if m := quartzNthWeekdayRE.FindStringSubmatch(spec); m != nil {
+		day, err := quartzWeekday.value(m[1])
+		if err != nil {
+			return err
+		}
+		n, _ := strconv.Atoi(m[2])
+		if n < 1 || n > 5 {
+			return fmt.Errorf("%s is not a valid Quartz day of the week: occurrence out of range", spec)
+		}
+		tp.WeekdaysOfMonth = []WeekdayOfMonth{{N: n, Weekday: time.Weekday(day - 1)}}
+		return nil
+	}
+	days, err := quartzWeekday.parse(spec)
+	if err != nil {
+		return err
+	}
+	// The first value of the field is Sunday, which is weekday 0.
+	for _, r := range cronRuns(days, 0) {
+		tp.Weekdays = append(tp.Weekdays, WeekdayRange{r})
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var fromQuartzTestCases = []struct {
+	expr        string
+	interval    *TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		expr: "0 0/30 9-10 ? * MON-FRI",
+		interval: &TimeInterval{
+			Times: []TimeRange{
+				{StartMinute: 540, EndMinute: 540, EndSecond: 1},
+				{StartMinute: 570, EndMinute: 570, EndSecond: 1},
+				{StartMinute: 600, EndMinute: 600, EndSecond: 1},
+				{StartMinute: 630, EndMinute: 630, EndSecond: 1},
+			},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+		contains: []string{"2020-07-10T09:30:00Z"},
+		excludes: []string{"2020-07-10T09:30:01Z", "2020-07-11T09:30:00Z"},
+	},
+	{
+		expr: "* * * L * ? 2024-2025",
+		interval: &TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -1, End: -1}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2024, End: 2025}}},
+		},
+		contains: []string{"2024-02-29T12:00:00Z"},
+		excludes: []string{"2024-02-28T12:00:00Z", "2026-01-31T12:00:00Z"},
+	},
+	{
+		expr: "* * * L-2 * ?",
+		interval: &TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -3, End: -3}}},
+		},
+	},
+	{
+		expr: "* * * LW * ?",
+		interval: &TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: lastOccurrence, AnyWeekday: true}},
+		},
+		// The last day of May 2020 is a Sunday.
+		contains: []string{"2020-05-29T12:00:00Z"},
+		excludes: []string{"2020-05-31T12:00:00Z"},
+	},
+	{
+		expr: "* * * ? * 6L",
+		interval: &TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: lastOccurrence, Weekday: time.Friday}},
+		},
+	},
+	{
+		expr: "* * * ? * fri#3",
+		interval: &TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 3, Weekday: time.Friday}},
+		},
+		contains: []string{"2020-07-17T12:00:00Z"},
+		excludes: []string{"2020-07-10T12:00:00Z"},
+	},
+	{
+		expr: "* * * ? * 1,7",
+		interval: &TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}},
+		},
+	},
+	{
+		// 15 August 2020 is a Saturday, 15 November 2020 a Sunday and 15 July 2020 a Wednesday.
+		expr:     "* * * 15W * ?",
+		contains: []string{"2020-08-14T12:00:00Z", "2020-11-16T12:00:00Z", "2020-07-15T12:00:00Z"},
+		excludes: []string{
+			"2020-08-15T12:00:00Z", "2020-11-15T12:00:00Z", "2020-11-13T12:00:00Z", "2020-07-14T12:00:00Z",
+			"2020-07-16T12:00:00Z",
+		},
+	},
+	{
+		// 1 August 2020 is a Saturday, 1 November 2020 a Sunday.
+		expr:     "* * * 1W * ?",
+		contains: []string{"2020-08-03T12:00:00Z", "2020-11-02T12:00:00Z", "2020-07-01T12:00:00Z"},
+		excludes: []string{"2020-08-01T12:00:00Z", "2020-07-31T12:00:00Z", "2020-11-03T12:00:00Z", "2020-07-02T12:00:00Z"},
+	},
+	{
+		expr:        "0 0 12 * * MON",
+		expectError: true,
+	},
+	{
+		expr:        "0 0 12 ? * ?",
+		expectError: true,
+	},
+	{
+		expr:        "0 0 12 30W * ?",
+		expectError: true,
+	},
+	{
+		expr:        "0 0 12 ? * 8",
+		expectError: true,
+	},
+	{
+		expr:        "0 0 12 ? * 2#6",
+		expectError: true,
+	},
+	{
+		expr:        "0 0 12 * *",
+		expectError: true,
+	},
+}
+
+func TestFromQuartz(t *testing.T) {
+	for _, tc := range fromQuartzTestCases {
+		ti, err := FromQuartz(tc.expr)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected an error converting %s, got %v", tc.expr, ti)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error converting %s: %v", tc.expr, err)
+			continue
+		}
+		if tc.interval != nil && !reflect.DeepEqual(ti, *tc.interval) {
+			t.Errorf("Converting %s gave %v, expected %v", tc.expr, ti, *tc.interval)
+		}
+		for _, c := range tc.contains {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if !ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to contain %s", tc.expr, c)
+			}
+		}
+		for _, c := range tc.excludes {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to exclude %s", tc.expr, c)
+			}
+		}
+	}
+}
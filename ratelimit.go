@@ -0,0 +1,140 @@
+package gotime
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// A Limit is a rate at which a RateLimiter admits events while its Matcher is active, e.g. 100 a second during
+// business hours.
+type Limit struct {
+	Matcher Matcher
+	// Rate is the number of events admitted each second on average, and Burst the most that may be admitted at once.
+	// A Rate or Burst of zero admits nothing.
+	Rate  float64
+	Burst int
+}
+
+// A RateLimiter is a token bucket whose rate depends on the time, admitting events at the rate of the first of its
+// limits whose Matcher is active and at a default rate when none are. Tokens are added at the rate in force when the
+// limiter was last used, and any above the burst of the new limit are dropped when the limit changes. A RateLimiter is
+// safe for concurrent use.
+type RateLimiter struct {
+	limits   []Limit
+	matchers []TransitionMatcher
+	fallback Limit
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// NewRateLimiter returns a limiter that admits rate events a second, up to burst at once, at times that none of the
+// limits cover. Limits are given in order of precedence, so the first that covers a time decides its rate. The
+// limiter starts with a full bucket.
+func NewRateLimiter(rate float64, burst int, limits ...Limit) *RateLimiter {
+	rl := &RateLimiter{
+		limits:   limits,
+		matchers: make([]TransitionMatcher, len(limits)),
+		fallback: Limit{Rate: rate, Burst: burst},
+	}
+	for i, l := range limits {
+		rl.matchers[i] = asTransitionMatcher(l.Matcher)
+	}
+	return rl
+}
+
+// LimitAt returns the limit in force at t, which is the default limit, with no Matcher, if none of the limits cover t.
+func (rl *RateLimiter) LimitAt(t time.Time) Limit {
+	for i, m := range rl.matchers {
+		if m.ContainsTime(t) {
+			return rl.limits[i]
+		}
+	}
+	return rl.fallback
+}
+
+// Allow reports whether an event may happen now, using up a token if so.
+func (rl *RateLimiter) Allow() bool {
+	return rl.AllowAt(time.Now())
+}
+
+// AllowAt reports whether an event may happen at t, using up a token if so. Times before the last one given are
+// treated as if they were the last.
+func (rl *RateLimiter) AllowAt(t time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.advance(t)
+	if rl.tokens < 1 {
+		return false
+	}
+	rl.tokens--
+	return true
+}
+
+// Wait blocks until an event may happen or ctx is done, returning ctx.Err() in the latter case. It sleeps until a
+// token is due or the limit changes, whichever is sooner, and returns ErrNeverActive if no token will ever be
+// available because the limit admits nothing and never changes.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		now := time.Now()
+		rl.mu.Lock()
+		rl.advance(now)
+		if rl.tokens >= 1 {
+			rl.tokens--
+			rl.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration(math.MaxInt64)
+		if rl.limit.Rate > 0 && rl.limit.Burst > 0 {
+			wait = time.Duration((1 - rl.tokens) / rl.limit.Rate * float64(time.Second))
+		}
+		rl.mu.Unlock()
+		// A change of limit may bring tokens sooner, or be the only way to get any.
+		change, ok := rl.nextChange(now)
+		if ok && change.Sub(now) < wait {
+			wait = change.Sub(now)
+		} else if !ok && wait == time.Duration(math.MaxInt64) {
+			return ErrNeverActive
+		}
+		if wait > maxWait {
+			wait = maxWait
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// advance adds the tokens earned since the limiter was last used and switches to the limit in force at t. rl.mu must
+// be held.
+func (rl *RateLimiter) advance(t time.Time) {
+	limit := rl.LimitAt(t)
+	if rl.last.IsZero() {
+		rl.tokens = float64(limit.Burst)
+	} else if t.After(rl.last) {
+		rl.tokens += t.Sub(rl.last).Seconds() * rl.limit.Rate
+	}
+	if !t.Before(rl.last) {
+		rl.last = t
+	}
+	rl.limit = limit
+	if burst := float64(limit.Burst); rl.tokens > burst {
+		rl.tokens = burst
+	}
+}
+
+// nextChange returns the earliest time after t at which one of the limits starts or stops applying.
+func (rl *RateLimiter) nextChange(t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, m := range rl.matchers {
+		if n, ok := m.NextTransition(t); ok && (!found || n.Before(next)) {
+			next, found = n, true
+		}
+	}
+	return next, found
+}
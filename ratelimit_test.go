@@ -0,0 +1,84 @@
+package gotime
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	businessHours := TimeInterval{
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+	}
+	lunch := TimeInterval{Times: []TimeRange{{StartMinute: 720, EndMinute: 780}}}
+	rl := NewRateLimiter(0.5, 1, Limit{Matcher: lunch, Rate: 0}, Limit{Matcher: businessHours, Rate: 2, Burst: 2})
+	// 2024-07-01 is a Monday.
+	at := func(clock string) time.Time {
+		t, err := time.Parse(time.RFC3339Nano, "2024-07-01T"+clock+"Z")
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	for _, c := range []struct {
+		clock string
+		allow bool
+	}{
+		// Before business hours one event is allowed every two seconds.
+		{"08:59:50", true},
+		{"08:59:50", false},
+		{"08:59:51", false},
+		{"08:59:52", true},
+		// Business hours allow two a second with a burst of two, filled by the tokens earned before nine.
+		{"09:00:00", true},
+		{"09:00:00", true},
+		{"09:00:00", false},
+		{"09:00:01", true},
+		{"09:00:01", true},
+		{"09:00:01", false},
+		{"09:00:01.5", true},
+		// Times going backwards are treated as the last time given.
+		{"09:00:00", false},
+		// Lunch takes precedence over business hours, and allows nothing.
+		{"12:00:00", false},
+		{"12:59:59", false},
+		{"13:00:00", false},
+		{"13:00:00.5", true},
+		{"20:00:00", true},
+		{"20:00:00", false},
+	} {
+		if got := rl.AllowAt(at(c.clock)); got != c.allow {
+			t.Errorf("Expected an event at %s to be allowed: %t, got %t", c.clock, c.allow, got)
+		}
+	}
+	if l := rl.LimitAt(at("12:30:00")); l.Rate != 0 || l.Matcher == nil {
+		t.Errorf("Expected the lunch limit to apply at 12:30, got %+v", l)
+	}
+	if l := rl.LimitAt(at("18:00:00")); l.Rate != 0.5 || l.Burst != 1 || l.Matcher != nil {
+		t.Errorf("Expected the default limit to apply at 18:00, got %+v", l)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	rl := NewRateLimiter(20, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := rl.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if waited := time.Since(start); waited < 90*time.Millisecond || waited > 2*time.Second {
+		t.Errorf("Expected three events at 20 a second to take about 100ms, took %s", waited)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	slow := NewRateLimiter(0.001, 1)
+	slow.Allow()
+	if err := slow.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+	if err := NewRateLimiter(0, 0).Wait(context.Background()); err != ErrNeverActive {
+		t.Errorf("Expected ErrNeverActive from a limiter that admits nothing, got %v", err)
+	}
+}
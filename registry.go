@@ -0,0 +1,182 @@
+package gotime
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A RegistryChange describes a definition in a Registry being added, replaced or removed. Old is nil if the name
+// wasn't registered before, and New is nil if it has been removed.
+type RegistryChange struct {
+	Name string
+	Old  IntervalSet
+	New  IntervalSet
+}
+
+// A Registry holds named sets of intervals that can be looked up and changed while in use, such as the mute windows
+// of a long running service. Sets are shared rather than copied, so they must not be modified once registered. A
+// Registry is safe for concurrent use.
+type Registry struct {
+	mu   sync.RWMutex
+	defs Definitions
+
+	// notify is held while subscribers are called, so that they see changes one at a time and in order.
+	notify      sync.Mutex
+	subscribers map[int]func(RegistryChange)
+	nextID      int
+}
+
+// NewRegistry returns a registry holding the given definitions, which may be nil.
+func NewRegistry(d Definitions) *Registry {
+	defs := make(Definitions, len(d))
+	for name, set := range d {
+		defs[name] = set
+	}
+	return &Registry{defs: defs, subscribers: map[int]func(RegistryChange){}}
+}
+
+// Lookup returns the set registered under name, or false if there isn't one.
+func (r *Registry) Lookup(name string) (IntervalSet, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set, ok := r.defs[name]
+	return set, ok
+}
+
+// Names returns the registered names in order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.defs))
+	for name := range r.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Definitions returns a copy of the registered definitions.
+func (r *Registry) Definitions() Definitions {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d := make(Definitions, len(r.defs))
+	for name, set := range r.defs {
+		d[name] = set
+	}
+	return d
+}
+
+// Register registers set under name, replacing any set already registered under it.
+func (r *Registry) Register(name string, set IntervalSet) {
+	r.update(func(defs Definitions) Definitions {
+		defs[name] = set
+		return defs
+	})
+}
+
+// Remove removes the set registered under name, if there is one.
+func (r *Registry) Remove(name string) {
+	r.update(func(defs Definitions) Definitions {
+		delete(defs, name)
+		return defs
+	})
+}
+
+// Swap replaces all of the registered definitions with d in one step, so that lookups see either the old definitions
+// or the new ones and never a mix, and returns the old definitions. This is how a reloaded document is put in place.
+func (r *Registry) Swap(d Definitions) Definitions {
+	defs := make(Definitions, len(d))
+	for name, set := range d {
+		defs[name] = set
+	}
+	var old Definitions
+	r.update(func(current Definitions) Definitions {
+		old = current
+		return defs
+	})
+	return old
+}
+
+// Subscribe calls fn for every change made to the registry from now on, until the returned function is called. Each
+// call happens after the change is visible to lookups, and calls are made one at a time in the order the changes were
+// made. Replacing a set with an identical one isn't a change. fn is called from the goroutine making the change, so
+// it must not change the registry itself.
+func (r *Registry) Subscribe(fn func(RegistryChange)) (unsubscribe func()) {
+	r.notify.Lock()
+	defer r.notify.Unlock()
+	id := r.nextID
+	r.nextID++
+	r.subscribers[id] = fn
+	return func() {
+		r.notify.Lock()
+		defer r.notify.Unlock()
+		delete(r.subscribers, id)
+	}
+}
+
+// Matcher returns a Matcher for the set registered under name that follows changes to the registry, so that it can be
+// handed to a Gate or HTTPGate once and the schedule changed later. It matches nothing while no set is registered
+// under name.
+func (r *Registry) Matcher(name string) TransitionMatcher {
+	return registryMatcher{r, name}
+}
+
+// update replaces the definitions with the result of fn, which is given a copy of them to modify, and notifies
+// subscribers of the changes.
+func (r *Registry) update(fn func(Definitions) Definitions) {
+	r.notify.Lock()
+	defer r.notify.Unlock()
+	r.mu.Lock()
+	old := r.defs
+	defs := make(Definitions, len(old))
+	for name, set := range old {
+		defs[name] = set
+	}
+	r.defs = fn(defs)
+	r.mu.Unlock()
+	for _, change := range definitionChanges(old, r.defs) {
+		for _, fn := range r.subscribers {
+			fn(change)
+		}
+	}
+}
+
+// definitionChanges returns the changes that turn old into new, in order of name.
+func definitionChanges(old, new Definitions) []RegistryChange {
+	var changes []RegistryChange
+	for name, set := range old {
+		if next, ok := new[name]; !ok || !reflect.DeepEqual(set, next) {
+			changes = append(changes, RegistryChange{Name: name, Old: set, New: next})
+		}
+	}
+	for name, set := range new {
+		if _, ok := old[name]; !ok {
+			changes = append(changes, RegistryChange{Name: name, New: set})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// A registryMatcher matches whatever set is registered under a name at the time it's asked.
+type registryMatcher struct {
+	r    *Registry
+	name string
+}
+
+func (m registryMatcher) ContainsTime(t time.Time) bool {
+	set, _ := m.r.Lookup(m.name)
+	return set.ContainsTime(t)
+}
+
+func (m registryMatcher) NextTransition(t time.Time) (time.Time, bool) {
+	set, _ := m.r.Lookup(m.name)
+	return set.NextTransition(t)
+}
+
+func (m registryMatcher) PreviousTransition(t time.Time) (time.Time, bool) {
+	set, _ := m.r.Lookup(m.name)
+	return set.PreviousTransition(t)
+}
@@ -0,0 +1,86 @@
+package gotime
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	weekdays := IntervalSet{{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}}}
+	weekends := IntervalSet{{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{6, 6}}}}}
+	r := NewRegistry(Definitions{"weekdays": weekdays})
+
+	var changes []RegistryChange
+	unsubscribe := r.Subscribe(func(c RegistryChange) { changes = append(changes, c) })
+	m := r.Matcher("maintenance")
+	saturday := time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC)
+	if m.ContainsTime(saturday) {
+		t.Errorf("Expected an unregistered name to match nothing")
+	}
+
+	r.Register("maintenance", weekends)
+	r.Register("maintenance", weekends)
+	if set, ok := r.Lookup("maintenance"); !ok || !reflect.DeepEqual(set, weekends) {
+		t.Errorf("Expected to look up the registered set, got %v", set)
+	}
+	if !m.ContainsTime(saturday) {
+		t.Errorf("Expected the matcher to follow the registered set")
+	}
+	old := r.Swap(Definitions{"maintenance": weekdays, "weekends": weekends})
+	if !reflect.DeepEqual(old, Definitions{"weekdays": weekdays, "maintenance": weekends}) {
+		t.Errorf("Expected the old definitions from the swap, got %v", old)
+	}
+	if m.ContainsTime(saturday) {
+		t.Errorf("Expected the matcher to follow the swap")
+	}
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"maintenance", "weekends"}) {
+		t.Errorf("Expected the swapped names, got %v", names)
+	}
+	r.Remove("weekends")
+	unsubscribe()
+	r.Remove("maintenance")
+
+	expected := []RegistryChange{
+		{Name: "maintenance", New: weekends},
+		{Name: "maintenance", Old: weekends, New: weekdays},
+		{Name: "weekdays", Old: weekdays},
+		{Name: "weekends", New: weekends},
+		{Name: "weekends", Old: weekends},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes %v, got %v", expected, changes)
+	}
+	if d := r.Definitions(); len(d) != 0 {
+		t.Errorf("Expected no definitions to be left, got %v", d)
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	r := NewRegistry(nil)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := 0
+	r.Subscribe(func(RegistryChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen++
+	})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := string(rune('a' + i))
+			r.Register(name, IntervalSet{{}})
+			if _, ok := r.Lookup(name); !ok {
+				t.Errorf("Expected %s to be registered", name)
+			}
+			r.Matcher(name).ContainsTime(time.Now())
+		}(i)
+	}
+	wg.Wait()
+	if seen != 8 || len(r.Names()) != 8 {
+		t.Errorf("Expected 8 registrations, saw %d changes and %d names", seen, len(r.Names()))
+	}
+}
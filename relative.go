@@ -0,0 +1,121 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A RelativeRange is an inclusive range of days counted from a movable feast, whose date changes from year to year.
+// Begin and End are offsets in days from the feast, so 'easter-2:easter+1' runs from Good Friday to Easter Monday.
+// Easter is the only feast currently understood, and is computed for the Gregorian calendar.
+type RelativeRange struct {
+	Feast string
+	InclusiveRange
+}
+
+// maxFeastOffset bounds the offsets of a RelativeRange so that a day can only be near the feast of its own year or
+// those either side of it.
+const maxFeastOffset = 183
+
+var feasts = map[string]func(year int) time.Time{
+	"easter": easterSunday,
+}
+
+var feastOffsetRE = regexp.MustCompile(`^([a-z]+)\s*(([+-])\s*([0-9]+))?$`)
+
+// UnmarshalYAML implements the Unmarshaller interface for RelativeRange.
+func (r *RelativeRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	components := strings.Split(strings.ToLower(str), ":")
+	if len(components) > 2 {
+		return fmt.Errorf("Couldn't parse relative range %s, invalid format", str)
+	}
+	feast, begin, err := parseFeastOffset(components[0])
+	if err != nil {
+		return err
+	}
+	end := begin
+	if len(components) == 2 {
+		var endFeast string
+		endFeast, end, err = parseFeastOffset(components[1])
+		if err != nil {
+			return err
+		}
+		if endFeast != feast {
+			return fmt.Errorf("Couldn't parse relative range %s, both ends must be relative to the same feast", str)
+		}
+	}
+	if begin > end {
+		return errors.New("Start day cannot be before End day")
+	}
+	r.Feast, r.Begin, r.End = feast, begin, end
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for RelativeRange
+func (r RelativeRange) MarshalYAML() (interface{}, error) {
+	if _, ok := feasts[r.Feast]; !ok {
+		return nil, fmt.Errorf("Unable to convert %s into feast", r.Feast)
+	}
+	if r.Begin == r.End {
+		return interface{}(formatFeastOffset(r.Feast, r.Begin)), nil
+	}
+	return interface{}(formatFeastOffset(r.Feast, r.Begin) + ":" + formatFeastOffset(r.Feast, r.End)), nil
+}
+
+// parseFeastOffset converts a string such as 'easter-2' into the name of the feast and the offset in days from it.
+func parseFeastOffset(in string) (string, int, error) {
+	m := feastOffsetRE.FindStringSubmatch(strings.TrimSpace(in))
+	if m == nil {
+		return "", 0, fmt.Errorf("Couldn't parse relative day %s, invalid format", in)
+	}
+	if _, ok := feasts[m[1]]; !ok {
+		return "", 0, fmt.Errorf("%s is not a known feast", m[1])
+	}
+	offset := 0
+	if m[2] != "" {
+		n, err := strconv.Atoi(m[4])
+		if err != nil {
+			return "", 0, err
+		}
+		offset = n
+		if m[3] == "-" {
+			offset = -n
+		}
+	}
+	if offset < -maxFeastOffset || offset > maxFeastOffset {
+		return "", 0, fmt.Errorf("%s is not a valid relative day: out of range", in)
+	}
+	return m[1], offset, nil
+}
+
+func formatFeastOffset(feast string, offset int) string {
+	if offset == 0 {
+		return feast
+	}
+	return fmt.Sprintf("%s%+d", feast, offset)
+}
+
+// containsDay returns true if the calendar day of the given time falls within the range around the feast of its own
+// year or of either neighbouring year.
+func (r RelativeRange) containsDay(t time.Time) bool {
+	feast, ok := feasts[r.Feast]
+	if !ok {
+		return false
+	}
+	day := date(t.Year(), t.Month(), t.Day())
+	for year := t.Year() - 1; year <= t.Year()+1; year++ {
+		offset := int(day.Sub(feast(year)).Hours() / 24)
+		if offset >= r.Begin && offset <= r.End {
+			return true
+		}
+	}
+	return false
+}
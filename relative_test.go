@@ -0,0 +1,111 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var relativeTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// Good Friday to Easter Monday
+		in: "relative: ['easter-2:easter+1']",
+		interval: TimeInterval{
+			Relative: []RelativeRange{{Feast: "easter", InclusiveRange: InclusiveRange{Begin: -2, End: 1}}},
+		},
+		contains: []string{"2020-04-10T00:00:00Z", "2020-04-13T23:59:59Z", "2024-03-29T12:00:00Z", "2024-04-01T12:00:00Z"},
+		excludes: []string{"2020-04-09T23:59:59Z", "2020-04-14T00:00:00Z", "2024-04-02T12:00:00Z"},
+	},
+	{
+		// Ash Wednesday and Pentecost
+		in: "relative: ['Easter - 46', 'easter+49']",
+		interval: TimeInterval{
+			Relative: []RelativeRange{
+				{Feast: "easter", InclusiveRange: InclusiveRange{Begin: -46, End: -46}},
+				{Feast: "easter", InclusiveRange: InclusiveRange{Begin: 49, End: 49}},
+			},
+		},
+		contains: []string{"2021-02-17T12:00:00Z", "2021-05-23T12:00:00Z"},
+		excludes: []string{"2021-02-18T12:00:00Z", "2021-04-04T12:00:00Z"},
+	},
+	{
+		in:          "relative: ['easter+1:easter-2']",
+		expectError: true,
+	},
+	{
+		in:          "relative: ['christmas+1']",
+		expectError: true,
+	},
+	{
+		in:          "relative: ['easter+200']",
+		expectError: true,
+	},
+}
+
+func TestRelative(t *testing.T) {
+	for _, tc := range relativeTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestRelativeAnalysis(t *testing.T) {
+	easterWeekend := TimeInterval{Relative: []RelativeRange{{Feast: "easter", InclusiveRange: InclusiveRange{Begin: -2, End: 1}}}}
+	if easterWeekend.IsAlwaysActive() || easterWeekend.IsEmpty() {
+		t.Errorf("Expected the Easter weekend to be neither always active nor empty")
+	}
+	// Easter is never earlier than 22 March.
+	earlyMarch := TimeInterval{
+		Relative:    []RelativeRange{{Feast: "easter", InclusiveRange: InclusiveRange{Begin: 0, End: 0}}},
+		Months:      []MonthRange{{InclusiveRange{Begin: 3, End: 3}}},
+		DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 21}}},
+	}
+	if !earlyMarch.IsEmpty() {
+		t.Errorf("Expected Easter Sunday in early March to be empty")
+	}
+	got, err := Intersect(easterWeekend, TimeInterval{Relative: []RelativeRange{{Feast: "easter", InclusiveRange: InclusiveRange{Begin: 0, End: 7}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RelativeRange{{Feast: "easter", InclusiveRange: InclusiveRange{Begin: 0, End: 1}}}
+	if !reflect.DeepEqual(got.Relative, want) {
+		t.Errorf("Intersecting relative ranges: want %+v, got %+v", want, got.Relative)
+	}
+}
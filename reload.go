@@ -0,0 +1,141 @@
+package gotime
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// defaultPollInterval is how often a FileWatcher checks its file if no interval is given.
+const defaultPollInterval = time.Second
+
+// LoadDefinitions reads a YAML document of definitions from a file. Unknown fields are rejected, so that mistakes in a
+// hand edited file aren't silently ignored.
+func LoadDefinitions(path string) (Definitions, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDefinitions(data, path)
+}
+
+// parseDefinitions strictly parses a YAML document of definitions loaded from source.
+func parseDefinitions(data []byte, source string) (Definitions, error) {
+	var d Definitions
+	if err := yaml.UnmarshalStrict(data, &d); err != nil {
+		return nil, fmt.Errorf("Couldn't load definitions from %s: %v", source, err)
+	}
+	return d, nil
+}
+
+// A FileWatcher keeps a Registry in step with a YAML file of definitions, so that schedules such as maintenance windows
+// can be changed without restarting the service. Each time the file changes it is loaded and validated, and its
+// definitions replace those in the registry in one step. A file that can't be loaded or fails validation is reported
+// and leaves the registry as it was.
+//
+// The file is polled rather than watched with filesystem notifications, which keeps working when the file is replaced
+// by renaming another over it, as editors and Kubernetes ConfigMap volumes do.
+type FileWatcher struct {
+	Path     string
+	Registry *Registry
+	// Validate, if set, is called with the definitions loaded from the file before they are swapped in, and rejects
+	// them if it returns an error, e.g. to check that names the service depends on are defined.
+	Validate func(Definitions) error
+	// OnError, if set, is called when the file can't be loaded or its definitions are rejected.
+	OnError func(error)
+	// Interval is how often the file is checked for changes, or every second if zero. If it's negative the file isn't
+	// checked, and is only loaded again when one of the Signals is received.
+	Interval time.Duration
+	// Signals, such as syscall.SIGHUP, make Watch load the file again whenever one of them is received, whether or not
+	// it looks to have changed, as is the convention for daemons.
+	Signals []os.Signal
+}
+
+// Load loads the file and swaps its definitions into the registry if they're valid.
+func (w *FileWatcher) Load() error {
+	d, err := LoadDefinitions(w.Path)
+	if err != nil {
+		return err
+	}
+	if err := validateDefinitions(d, w.Validate, w.Path); err != nil {
+		return err
+	}
+	w.Registry.Swap(d)
+	return nil
+}
+
+// validateDefinitions calls validate, if it's set, on definitions loaded from source.
+func validateDefinitions(d Definitions, validate func(Definitions) error, source string) error {
+	if validate == nil {
+		return nil
+	}
+	if err := validate(d); err != nil {
+		return fmt.Errorf("Definitions in %s are invalid: %v", source, err)
+	}
+	return nil
+}
+
+// Watch loads the file when it is called and then whenever the file changes or one of the Signals is received, until
+// ctx is done, and then returns ctx.Err(). Errors are passed to OnError, and a file that goes missing is reported once
+// rather than every time it's checked.
+func (w *FileWatcher) Watch(ctx context.Context) error {
+	interval := w.Interval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	var signals chan os.Signal
+	if len(w.Signals) > 0 {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, w.Signals...)
+		defer signal.Stop(signals)
+	}
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	last := statFile(w.Path)
+	w.reload()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+			if state := statFile(w.Path); state != last {
+				last = state
+				w.reload()
+			}
+		case <-signals:
+			last = statFile(w.Path)
+			w.reload()
+		}
+	}
+}
+
+// reload loads the file, passing any error to OnError.
+func (w *FileWatcher) reload() {
+	if err := w.Load(); err != nil && w.OnError != nil {
+		w.OnError(err)
+	}
+}
+
+// A fileState is what's checked to decide whether a file has changed.
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,169 @@
+package gotime
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLoadDefinitions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cases := []struct {
+		doc         string
+		names       int
+		expectError bool
+	}{
+		{doc: "business_hours:\n  - weekdays: ['monday:friday']\nweekends:\n  weekdays: ['saturday', 'sunday']\n", names: 2},
+		{doc: "business_hours:\n  - weekdays: ['monday:friday']\n    bogus: true\n", expectError: true},
+		{doc: "business_hours: [", expectError: true},
+	}
+	for _, c := range cases {
+		path := filepath.Join(dir, "schedules.yaml")
+		if err := ioutil.WriteFile(path, []byte(c.doc), 0644); err != nil {
+			t.Fatal(err)
+		}
+		d, err := LoadDefinitions(path)
+		if (err != nil) != c.expectError {
+			t.Errorf("Expected an error loading %q: %t, got %v", c.doc, c.expectError, err)
+		} else if len(d) != c.names {
+			t.Errorf("Expected %d definitions from %q, got %d", c.names, c.doc, len(d))
+		}
+	}
+	if _, err := LoadDefinitions(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Errorf("Expected an error loading a missing file")
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "schedules.yaml")
+	write := func(doc string, age time.Duration) {
+		if err := ioutil.WriteFile(path, []byte(doc), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Make sure each write has a different modification time, whatever the resolution of the filesystem.
+		mod := time.Now().Add(-age)
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var mu sync.Mutex
+	var errs []error
+	registry := NewRegistry(nil)
+	w := &FileWatcher{
+		Path:     path,
+		Registry: registry,
+		Interval: 10 * time.Millisecond,
+		Validate: func(d Definitions) error {
+			if _, ok := d["maintenance"]; !ok {
+				return errors.New("maintenance is not defined")
+			}
+			return nil
+		},
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	}
+	write("maintenance:\n  weekdays: ['sunday']\n", 3*time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Watch(ctx) }()
+
+	eventually := func(what string, cond func() bool) {
+		for deadline := time.Now().Add(2 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %s", what)
+			}
+		}
+	}
+	sunday := time.Date(2024, 7, 7, 12, 0, 0, 0, time.UTC)
+	saturday := sunday.AddDate(0, 0, -1)
+	m := registry.Matcher("maintenance")
+	eventually("the first load", func() bool { return m.ContainsTime(sunday) })
+
+	write("maintenance:\n  weekdays: ['saturday']\n", 2*time.Hour)
+	eventually("the reload", func() bool { return m.ContainsTime(saturday) })
+
+	errCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs)
+	}
+	write("maintenance: [\n", time.Hour)
+	eventually("the parse error", func() bool { return errCount() == 1 })
+	write("other:\n  weekdays: ['sunday']\n", 0)
+	eventually("the validation error", func() bool { return errCount() == 2 })
+	if !m.ContainsTime(saturday) {
+		t.Errorf("Expected invalid files to leave the registry unchanged")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected the watch to end with the context, got %v", err)
+	}
+}
+
+func TestFileWatcherSignals(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "schedules.yaml")
+	if err := ioutil.WriteFile(path, []byte("maintenance:\n  weekdays: ['sunday']\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry(nil)
+	loaded := make(chan RegistryChange, 2)
+	registry.Subscribe(func(c RegistryChange) { loaded <- c })
+	// Polling is turned off, so only the signal causes the file to be loaded again.
+	w := &FileWatcher{Path: path, Registry: registry, Interval: -1, Signals: []os.Signal{syscall.SIGHUP}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Watch(ctx)
+	select {
+	case <-loaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the first load")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("maintenance:\n  weekdays: ['saturday']\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-loaded:
+		t.Fatalf("Expected no reload without polling or a signal, got %v", c)
+	case <-time.After(50 * time.Millisecond):
+	}
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-loaded:
+		if !c.New.ContainsTime(time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected the signal to load the new file, got %v", c.New)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the reload on SIGHUP")
+	}
+}
@@ -0,0 +1,35 @@
+package gotime
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// An HTTPSource is a Source that fetches a document served over HTTP or HTTPS. Requests are conditional on the ETag
+// and Last-Modified headers of the last response, so that an unchanged document isn't downloaded again. An HTTPSource
+// is safe for concurrent use.
+type HTTPSource struct {
+	URL string
+	// Client makes the requests, or http.DefaultClient if nil.
+	Client *http.Client
+
+	mu   sync.Mutex
+	last conditionalGet
+}
+
+// Fetch implements the Source interface for HTTPSource.
+func (s *HTTPSource) Fetch(ctx context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.last.setConditions(req)
+	return s.last.do(s.Client, req.WithContext(ctx))
+}
+
+func (s *HTTPSource) String() string {
+	return s.URL
+}
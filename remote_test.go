@@ -0,0 +1,41 @@
+package gotime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSource(t *testing.T) {
+	modified := "Mon, 01 Jul 2024 09:00:00 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == modified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", modified)
+		w.Write([]byte("maintenance:\n  weekdays: ['sunday']\n"))
+	}))
+	defer server.Close()
+
+	s := &HTTPSource{URL: server.URL + "/schedules.yaml"}
+	if data, err := s.Fetch(context.Background()); err != nil || len(data) == 0 {
+		t.Fatalf("Expected the document from the first fetch, got %q and %v", data, err)
+	}
+	if _, err := s.Fetch(context.Background()); err != ErrNotModified {
+		t.Errorf("Expected ErrNotModified fetching an unchanged document, got %v", err)
+	}
+	if s.String() != server.URL+"/schedules.yaml" {
+		t.Errorf("Expected the source to be named by its URL, got %s", s)
+	}
+	missing := &HTTPSource{URL: server.URL + "/missing.yaml"}
+	if _, err := missing.Fetch(context.Background()); err == nil {
+		t.Errorf("Expected an error fetching a missing document")
+	}
+}
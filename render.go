@@ -0,0 +1,99 @@
+package gotime
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// renderSlotsPerHour is how many cells each hour of the day takes up in the heatlines of RenderWeek.
+const renderSlotsPerHour = 2
+
+// Marks used in rendered calendars for periods that are wholly, partly or not at all active.
+const (
+	markActive   = '#'
+	markPartial  = '+'
+	markInactive = '.'
+)
+
+// RenderWeek writes the week starting on the Monday on or before t, in t's location, as ASCII heatlines: one line per
+// day with a cell for each half hour, marked '#' if m is active for all of it, '+' if for part of it and '.' if not
+// at all. It's meant for reviewing a schedule by eye, e.g.
+//
+//	           00    03    06    09    12    15    18    21
+//	Mon 01 Jul ..................################..............
+func RenderWeek(w io.Writer, m Matcher, t time.Time) error {
+	tm := asTransitionMatcher(m)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	day = day.AddDate(0, 0, -int((day.Weekday()+6)%7))
+	bw := bufio.NewWriter(w)
+	header := strings.Repeat(" ", 11)
+	for h := 0; h < 24; h += 3 {
+		header += fmt.Sprintf("%-*s", 3*renderSlotsPerHour, fmt.Sprintf("%02d", h))
+	}
+	bw.WriteString(strings.TrimRight(header, " ") + "\n")
+	for i := 0; i < 7; i++ {
+		date := day.AddDate(0, 0, i)
+		next := date.AddDate(0, 0, 1)
+		windows := transitionWindows(tm, date, next)
+		bw.WriteString(date.Format("Mon 02 Jan "))
+		for slot := 0; slot < 24*renderSlotsPerHour; slot++ {
+			minute := slot * 60 / renderSlotsPerHour
+			start := time.Date(date.Year(), date.Month(), date.Day(), 0, minute, 0, 0, date.Location())
+			end := time.Date(date.Year(), date.Month(), date.Day(), 0, minute+60/renderSlotsPerHour, 0, 0,
+				date.Location())
+			bw.WriteRune(renderMark(windows, start, end))
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// RenderMonth writes the month containing t, in t's location, as an ASCII calendar with weeks starting on Monday.
+// Each day's number is followed by '#' if m is active all day, '+' if for part of it and '.' if not at all.
+func RenderMonth(w io.Writer, m Matcher, t time.Time) error {
+	tm := asTransitionMatcher(m)
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	after := first.AddDate(0, 1, 0)
+	windows := transitionWindows(tm, first, after)
+	bw := bufio.NewWriter(w)
+	title := first.Format("January 2006")
+	fmt.Fprintf(bw, "%s%s\n", strings.Repeat(" ", (27-len(title))/2), title)
+	bw.WriteString(" Mo  Tu  We  Th  Fr  Sa  Su\n")
+	bw.WriteString(strings.Repeat("    ", int((first.Weekday()+6)%7)))
+	for day := first; day.Before(after); day = day.AddDate(0, 0, 1) {
+		fmt.Fprintf(bw, "%3d%c", day.Day(), renderMark(windows, day, day.AddDate(0, 0, 1)))
+		if day.Weekday() == time.Sunday {
+			bw.WriteString("\n")
+		}
+	}
+	if after.Weekday() != time.Monday {
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// renderMark returns the mark for the period from start to end, given the windows around it in order.
+func renderMark(windows []Window, start, end time.Time) rune {
+	active := activeWithin(windows, start, end)
+	switch {
+	case active <= 0:
+		return markInactive
+	case active >= end.Sub(start):
+		return markActive
+	}
+	return markPartial
+}
+
+// activeWithin returns how much of the period from start to end the windows cover.
+func activeWithin(windows []Window, start, end time.Time) time.Duration {
+	var active time.Duration
+	for _, w := range windows {
+		if w.End.After(start) && w.Start.Before(end) {
+			active += clipWindow(w, start, end).Duration()
+		}
+	}
+	return active
+}
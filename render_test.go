@@ -0,0 +1,68 @@
+package gotime
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	holiday := time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC)
+	businessHours := TimeInterval{
+		Times:    []TimeRange{{StartMinute: 540, EndMinute: 1005}},
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		Except:   []TimeInterval{{Dates: []DateRange{{Begin: holiday, End: holiday}}}},
+	}
+	saturdays := IntervalSet{{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}}}}
+	cases := []struct {
+		render   func(*bytes.Buffer) error
+		expected string
+	}{
+		{
+			render: func(b *bytes.Buffer) error { return RenderWeek(b, businessHours, holiday) },
+			expected: `           00    03    06    09    12    15    18    21
+Mon 01 Jul ..................###############+..............
+Tue 02 Jul ..................###############+..............
+Wed 03 Jul ..................###############+..............
+Thu 04 Jul ................................................
+Fri 05 Jul ..................###############+..............
+Sat 06 Jul ................................................
+Sun 07 Jul ................................................
+`,
+		},
+		{
+			render: func(b *bytes.Buffer) error { return RenderMonth(b, businessHours, holiday) },
+			expected: `         July 2024
+ Mo  Tu  We  Th  Fr  Sa  Su
+  1+  2+  3+  4.  5+  6.  7.
+  8+  9+ 10+ 11+ 12+ 13. 14.
+ 15+ 16+ 17+ 18+ 19+ 20. 21.
+ 22+ 23+ 24+ 25+ 26+ 27. 28.
+ 29+ 30+ 31+
+`,
+		},
+		{
+			render: func(b *bytes.Buffer) error {
+				return RenderMonth(b, saturdays, time.Date(2024, 9, 30, 12, 0, 0, 0, time.UTC))
+			},
+			expected: `      September 2024
+ Mo  Tu  We  Th  Fr  Sa  Su
+                          1.
+  2.  3.  4.  5.  6.  7#  8.
+  9. 10. 11. 12. 13. 14# 15.
+ 16. 17. 18. 19. 20. 21# 22.
+ 23. 24. 25. 26. 27. 28# 29.
+ 30.
+`,
+		},
+	}
+	for _, c := range cases {
+		var b bytes.Buffer
+		if err := c.render(&b); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != c.expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", c.expected, b.String())
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package gotime
+
+import (
+	"errors"
+	"time"
+)
+
+// A Rotation takes turns between a list of shifts, e.g. week A and week B of an on-call roster. Each turn lasts Every
+// periods of Unit, counting from the Anchor date, and the shifts take their turns in order before starting again from
+// the first. A shift only matches during its own turns, and nothing matches before the anchor. Days are compared in
+// the location of each shift.
+type Rotation struct {
+	Anchor time.Time
+	Every  int
+	Unit   CycleUnit
+	Shifts []TimeInterval
+}
+
+type yamlRotation struct {
+	Anchor string         `yaml:"anchor"`
+	Every  string         `yaml:"every"`
+	Shifts []TimeInterval `yaml:"shifts"`
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for Rotation.
+func (r *Rotation) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var y yamlRotation
+	if err := unmarshal(&y); err != nil {
+		return err
+	}
+	// The anchor and length of a turn are written in the same way as a cycle's.
+	var c Cycle
+	if err := c.UnmarshalYAML(func(v interface{}) error {
+		*v.(*yamlCycle) = yamlCycle{Anchor: y.Anchor, Every: y.Every}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(y.Shifts) == 0 {
+		return errors.New("A rotation must have at least one shift")
+	}
+	r.Anchor, r.Every, r.Unit, r.Shifts = c.Anchor, c.Every, c.Unit, y.Shifts
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for Rotation
+func (r Rotation) MarshalYAML() (interface{}, error) {
+	c, err := Cycle{Anchor: r.Anchor, Every: r.Every, Unit: r.Unit}.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	y := c.(yamlCycle)
+	return interface{}(yamlRotation{Anchor: y.Anchor, Every: y.Every, Shifts: r.Shifts}), nil
+}
+
+// Turn returns the index of the shift whose turn it is at t, or -1 if t is before the anchor.
+func (r Rotation) Turn(t time.Time) int {
+	turns := r.turnsSinceAnchor(t)
+	if turns < 0 || len(r.Shifts) == 0 {
+		return -1
+	}
+	return turns % len(r.Shifts)
+}
+
+// turnsSinceAnchor returns the number of whole turns from the anchor date to the date of t, or -1 if t is before the
+// anchor.
+func (r Rotation) turnsSinceAnchor(t time.Time) int {
+	length := r.Every * int(r.Unit)
+	days := Cycle{Anchor: r.Anchor}.daysSinceAnchor(t)
+	if days < 0 || length < 1 {
+		return -1
+	}
+	return days / length
+}
+
+// ContainsTime returns true if the shift whose turn it is at t contains t.
+func (r Rotation) ContainsTime(t time.Time) bool {
+	return r.union().ContainsTime(t)
+}
+
+// NextTransition returns the earliest time after t at which the Rotation either becomes active or stops being active.
+// It returns false if that doesn't happen within the search horizon.
+func (r Rotation) NextTransition(t time.Time) (time.Time, bool) {
+	return r.union().NextTransition(t)
+}
+
+// PreviousTransition returns the latest time at or before t at which the Rotation either became active or stopped
+// being active. It returns false if that didn't happen within the search horizon.
+func (r Rotation) PreviousTransition(t time.Time) (time.Time, bool) {
+	return r.union().PreviousTransition(t)
+}
+
+// NextActiveTime returns t if the Rotation contains it, otherwise the time at which it next becomes active. It returns
+// false if that doesn't happen within the search horizon.
+func (r Rotation) NextActiveTime(t time.Time) (time.Time, bool) {
+	return nextActiveTime(r, t)
+}
+
+// Windows returns the windows between from and to during which the Rotation is active, in chronological order and
+// clipped to the bounds.
+func (r Rotation) Windows(from, to time.Time) []Window {
+	return transitionWindows(r, from, to)
+}
+
+// union returns a Union of each shift limited to its own turns. A turn of several periods is the union of one cycle for
+// each of its periods, so that turns are checked against the day a shift's times start on, as cycles are.
+func (r Rotation) union() Union {
+	var u Union
+	turn := r.Every
+	if turn < 1 || r.Unit < 1 {
+		return u
+	}
+	for i, shift := range r.Shifts {
+		turns := make(IntervalSet, turn)
+		for j := range turns {
+			turns[j] = TimeInterval{
+				Cycle: &Cycle{
+					Anchor: r.Anchor.AddDate(0, 0, (i*turn+j)*int(r.Unit)),
+					Every:  turn * len(r.Shifts),
+					Unit:   r.Unit,
+				},
+				Location: shift.Location,
+			}
+		}
+		if shift.Cycle != nil {
+			// The shift already has a cycle of its own, so its turns have to be checked separately.
+			u = append(u, And(shift, turns))
+			continue
+		}
+		for _, t := range turns {
+			s := shift
+			s.Cycle = t.Cycle
+			u = append(u, s)
+		}
+	}
+	return u
+}
@@ -0,0 +1,124 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var rotationTestCases = []struct {
+	in          string
+	rotation    Rotation
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// Week A covers weekday evenings and week B the weekend, taking turns each week
+		in: `
+anchor: '2024-01-01'
+every: '1w'
+shifts:
+  - weekdays: ['monday:friday']
+    times: [{start_time: '17:00', end_time: '23:00'}]
+  - weekdays: ['saturday', 'sunday']
+`,
+		rotation: Rotation{
+			Anchor: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Every:  1,
+			Unit:   CycleWeeks,
+			Shifts: []TimeInterval{
+				{
+					Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+					Times:    []TimeRange{{StartMinute: 1020, EndMinute: 1380}},
+				},
+				{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange{Begin: 0, End: 0}}}},
+			},
+		},
+		contains: []string{"2024-01-01T18:00:00Z", "2024-01-13T12:00:00Z", "2024-01-14T12:00:00Z", "2024-01-15T18:00:00Z"},
+		excludes: []string{"2024-01-06T12:00:00Z", "2024-01-08T18:00:00Z", "2024-01-20T12:00:00Z", "2023-12-25T18:00:00Z"},
+	},
+	{
+		in:          "{anchor: '2024-01-01', every: '1w', shifts: []}",
+		expectError: true,
+	},
+	{
+		in:          "{anchor: '2024-01-01', shifts: [{}]}",
+		expectError: true,
+	},
+}
+
+func TestRotation(t *testing.T) {
+	for _, tc := range rotationTestCases {
+		var r Rotation
+		err := yaml.Unmarshal([]byte(tc.in), &r)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(r, tc.rotation) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.rotation, r)
+		}
+		for _, ts := range tc.contains {
+			if !r.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if r.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(r)
+		if err != nil {
+			t.Error(err)
+		}
+		var r2 Rotation
+		if err := yaml.Unmarshal(out, &r2); err != nil || !reflect.DeepEqual(r, r2) {
+			t.Errorf("Re-marshalling %s produced a different Rotation: %s", tc.in, out)
+		}
+	}
+}
+
+func TestRotationTransitions(t *testing.T) {
+	// Two week turns between a day shift and a night shift.
+	r := Rotation{
+		Anchor: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Every:  2,
+		Unit:   CycleWeeks,
+		Shifts: []TimeInterval{
+			{Times: []TimeRange{{StartMinute: 480, EndMinute: 1200}}},
+			{Times: []TimeRange{{StartMinute: 1200, EndMinute: 480}}},
+		},
+	}
+	for ts, want := range map[string]int{
+		"2023-12-31T12:00:00Z": -1,
+		"2024-01-01T00:00:00Z": 0,
+		"2024-01-14T23:59:59Z": 0,
+		"2024-01-15T00:00:00Z": 1,
+		"2024-01-29T00:00:00Z": 0,
+	} {
+		if got := r.Turn(mustParseTime(ts)); got != want {
+			t.Errorf("Turn at %s: want %d, got %d", ts, want, got)
+		}
+	}
+	windows := r.Windows(mustParseTime("2024-01-14T00:00:00Z"), mustParseTime("2024-01-16T00:00:00Z"))
+	want := []Window{
+		{Start: mustParseTime("2024-01-14T08:00:00Z"), End: mustParseTime("2024-01-14T20:00:00Z")},
+		{Start: mustParseTime("2024-01-15T20:00:00Z"), End: mustParseTime("2024-01-16T00:00:00Z")},
+	}
+	if !reflect.DeepEqual(windows, want) {
+		t.Errorf("Windows across a change of turn: want %v, got %v", want, windows)
+	}
+	prev, ok := r.PreviousTransition(mustParseTime("2024-01-15T21:00:00Z"))
+	checkTransition(t, "rotation", r, "2024-01-15T20:00:00Z", prev, ok)
+	next, ok := r.NextTransition(mustParseTime("2023-12-01T00:00:00Z"))
+	checkTransition(t, "rotation", r, "2024-01-01T08:00:00Z", next, ok)
+}
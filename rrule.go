@@ -0,0 +1,348 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var rruleByDayRE = regexp.MustCompile(`^([+-]?[0-9]+)?(SU|MO|TU|WE|TH|FR|SA)$`)
+
+// rruleMaxScanDays is how far past DTSTART the occurrences of a rule with a COUNT are looked for.
+const rruleMaxScanDays = 1000 * 366
+
+// FromRRule converts an iCalendar recurrence rule, such as 'FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20241231T235959Z', into a
+// TimeInterval that is active for duration from the start of each occurrence. Occurrences are counted from dtstart,
+// whose location becomes the interval's location and whose time of day is used unless the rule has BYHOUR, BYMINUTE
+// or BYSECOND. The interval only covers the occurrences between dtstart and the end of the rule, which is kept in its
+// absolute ranges. duration may be at most a day, and only a whole day when occurrences start at midnight.
+//
+// DAILY, WEEKLY, MONTHLY and YEARLY rules are understood, with COUNT, UNTIL, INTERVAL, BYDAY, BYMONTHDAY, BYMONTH,
+// BYHOUR, BYMINUTE, BYSECOND and WKST. MONTHLY and YEARLY rules can't have an INTERVAL, and BYDAY may only have
+// ordinals such as '-1FR' for the last Friday when counting within a month. Other rules return an error.
+func FromRRule(rrule string, dtstart time.Time, duration time.Duration) (TimeInterval, error) {
+	parts := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rrule)), "RRULE:"), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return TimeInterval{}, fmt.Errorf("Couldn't parse RRULE %s, invalid format", rrule)
+		}
+		parts[kv[0]] = kv[1]
+	}
+	for key := range parts {
+		switch key {
+		case "FREQ", "COUNT", "UNTIL", "INTERVAL", "BYDAY", "BYMONTHDAY", "BYMONTH", "BYHOUR", "BYMINUTE", "BYSECOND",
+			"WKST":
+		default:
+			return TimeInterval{}, fmt.Errorf("%s is not a supported RRULE part", key)
+		}
+	}
+	if _, ok := parts["COUNT"]; ok && parts["UNTIL"] != "" {
+		return TimeInterval{}, errors.New("Only one of COUNT and UNTIL may be provided")
+	}
+	if duration < time.Second || duration > 24*time.Hour {
+		return TimeInterval{}, fmt.Errorf("%v is not a valid RRULE duration: must be between a second and a day", duration)
+	}
+	loc := dtstart.Location()
+	ti := TimeInterval{Location: &Location{loc}}
+	if err := ti.setRRuleTimes(parts, dtstart, int(duration/time.Second)); err != nil {
+		return TimeInterval{}, err
+	}
+	if err := ti.setRRuleDays(parts, dtstart); err != nil {
+		return TimeInterval{}, err
+	}
+	end := endOfTime
+	if count, ok := parts["COUNT"]; ok {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return TimeInterval{}, fmt.Errorf("%s is not a valid RRULE COUNT", count)
+		}
+		end = ti.rruleCountEnd(dtstart, n).Add(duration)
+	}
+	if until, ok := parts["UNTIL"]; ok {
+		u, err := parseRRuleUntil(until, loc)
+		if err != nil {
+			return TimeInterval{}, err
+		}
+		end = ti.rruleUntilEnd(dtstart, u).Add(duration)
+	}
+	if !dtstart.Before(end) {
+		end = dtstart
+	}
+	ti.Absolute = []AbsoluteRange{{Start: dtstart, End: end}}
+	return ti, nil
+}
+
+// setRRuleTimes sets the time ranges of each occurrence of a rule, lasting for the given number of seconds.
+func (tp *TimeInterval) setRRuleTimes(parts map[string]string, dtstart time.Time, duration int) error {
+	hours, err := rruleInts(parts, "BYHOUR", 0, 23, dtstart.Hour())
+	if err != nil {
+		return err
+	}
+	minutes, err := rruleInts(parts, "BYMINUTE", 0, 59, dtstart.Minute())
+	if err != nil {
+		return err
+	}
+	seconds, err := rruleInts(parts, "BYSECOND", 0, 59, dtstart.Second())
+	if err != nil {
+		return err
+	}
+	var starts []int
+	for _, h := range hours {
+		for _, m := range minutes {
+			for _, s := range seconds {
+				starts = append(starts, (h*60+m)*60+s)
+			}
+		}
+	}
+	sort.Ints(starts)
+	if duration == secondsPerDay {
+		if len(starts) != 1 || starts[0] != 0 {
+			return errors.New("Occurrences lasting a whole day must start at midnight")
+		}
+		return nil
+	}
+	tp.Times = []TimeRange{}
+	for _, start := range starts {
+		end := start + duration
+		if end > secondsPerDay {
+			end -= secondsPerDay
+		}
+		tp.Times = append(tp.Times, timeRangeFromSeconds(start, end))
+	}
+	return nil
+}
+
+// setRRuleDays sets the days on which a rule has occurrences.
+func (tp *TimeInterval) setRRuleDays(parts map[string]string, dtstart time.Time) error {
+	interval := 1
+	if str, ok := parts["INTERVAL"]; ok {
+		n, err := strconv.Atoi(str)
+		if err != nil || n < 1 {
+			return fmt.Errorf("%s is not a valid RRULE INTERVAL", str)
+		}
+		interval = n
+	}
+	wkst := time.Monday
+	if str, ok := parts["WKST"]; ok {
+		if wkst, ok = rruleWeekdays[str]; !ok {
+			return fmt.Errorf("%s is not a valid RRULE WKST", str)
+		}
+	}
+	freq := parts["FREQ"]
+	_, hasByDay := parts["BYDAY"]
+	_, hasByMonthDay := parts["BYMONTHDAY"]
+	_, hasByMonth := parts["BYMONTH"]
+	// Ordinals in BYDAY count within the month, which is only the case in monthly rules and yearly rules by month.
+	ordinals := freq == "MONTHLY" || (freq == "YEARLY" && hasByMonth)
+	switch freq {
+	case "DAILY":
+		if interval > 1 {
+			tp.Cycle = &Cycle{Anchor: dtstart, Every: interval, Unit: CycleDays}
+		}
+	case "WEEKLY":
+		if hasByMonthDay {
+			return errors.New("BYMONTHDAY is not valid in a WEEKLY RRULE")
+		}
+		if interval > 1 {
+			// Weeks are counted from the start of the week that dtstart falls in.
+			anchor := dtstart.AddDate(0, 0, -((int(dtstart.Weekday()) - int(wkst) + 7) % 7))
+			tp.Cycle = &Cycle{Anchor: anchor, Every: interval, Unit: CycleWeeks}
+		}
+		if !hasByDay {
+			parts["BYDAY"] = strings.ToUpper(dtstart.Weekday().String()[:2])
+		}
+	case "MONTHLY", "YEARLY":
+		if interval > 1 {
+			return fmt.Errorf("An INTERVAL is not supported in a %s RRULE", freq)
+		}
+		if !hasByDay && !hasByMonthDay {
+			parts["BYMONTHDAY"] = strconv.Itoa(dtstart.Day())
+			if freq == "YEARLY" && !hasByMonth {
+				parts["BYMONTH"] = strconv.Itoa(int(dtstart.Month()))
+			}
+		}
+	case "":
+		return errors.New("An RRULE must have a FREQ")
+	default:
+		return fmt.Errorf("%s is not a supported RRULE FREQ", freq)
+	}
+	if str, ok := parts["BYDAY"]; ok {
+		if err := tp.setRRuleByDay(str, ordinals, hasByMonthDay); err != nil {
+			return err
+		}
+	}
+	if _, ok := parts["BYMONTHDAY"]; ok {
+		days, err := rruleInts(parts, "BYMONTHDAY", -31, 31, 0)
+		if err != nil {
+			return err
+		}
+		fromStart, fromEnd := make([]bool, cronDayOfMonth.max), make([]bool, cronDayOfMonth.max)
+		for _, day := range days {
+			switch {
+			case day > 0:
+				fromStart[day-1] = true
+			case day < 0:
+				fromEnd[-day-1] = true
+			default:
+				return errors.New("0 is not a valid RRULE BYMONTHDAY: out of range")
+			}
+		}
+		for _, r := range cronRuns(fromStart, cronDayOfMonth.min) {
+			tp.DaysOfMonth = append(tp.DaysOfMonth, DayOfMonthRange{r})
+		}
+		runs := cronRuns(fromEnd, cronDayOfMonth.min)
+		for i := len(runs) - 1; i >= 0; i-- {
+			r := InclusiveRange{Begin: -runs[i].End, End: -runs[i].Begin}
+			tp.DaysOfMonth = append(tp.DaysOfMonth, DayOfMonthRange{r})
+		}
+	}
+	if _, ok := parts["BYMONTH"]; ok {
+		months, err := rruleInts(parts, "BYMONTH", 1, 12, 0)
+		if err != nil {
+			return err
+		}
+		set := make([]bool, 12)
+		for _, month := range months {
+			set[month-1] = true
+		}
+		for _, r := range cronRuns(set, cronMonth.min) {
+			tp.Months = append(tp.Months, MonthRange{r})
+		}
+	}
+	return nil
+}
+
+// setRRuleByDay sets the weekdays, or weekdays of the month, matched by the BYDAY part of a rule.
+func (tp *TimeInterval) setRRuleByDay(spec string, ordinals, hasByMonthDay bool) error {
+	weekdays := make([]bool, 7)
+	plain := false
+	for _, day := range strings.Split(spec, ",") {
+		m := rruleByDayRE.FindStringSubmatch(day)
+		if m == nil {
+			return fmt.Errorf("%s is not a valid RRULE BYDAY", day)
+		}
+		wd := rruleWeekdays[m[2]]
+		if m[1] == "" {
+			weekdays[wd], plain = true, true
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		if !ordinals {
+			return fmt.Errorf("%s is not a supported RRULE BYDAY: ordinals are only supported within a month", day)
+		}
+		if n == 0 || n < -5 || n > 5 {
+			return fmt.Errorf("%s is not a valid RRULE BYDAY: out of range", day)
+		}
+		tp.WeekdaysOfMonth = append(tp.WeekdaysOfMonth, WeekdayOfMonth{N: n, Weekday: wd})
+	}
+	// A day matches the interval's weekdays of the month or its days of the month, but a rule's BYMONTHDAY limits its
+	// BYDAY and a plain weekday in BYDAY adds to those with ordinals, so they can't be mixed.
+	if tp.WeekdaysOfMonth != nil && (plain || hasByMonthDay) {
+		return fmt.Errorf("%s is not a supported RRULE BYDAY: ordinals can't be combined with other days", spec)
+	}
+	if !plain {
+		return nil
+	}
+	for _, r := range cronRuns(weekdays, 0) {
+		tp.Weekdays = append(tp.Weekdays, WeekdayRange{r})
+	}
+	return nil
+}
+
+// rruleCountEnd returns the start of the nth occurrence of the interval from dtstart, ignoring its absolute ranges, or
+// of the last one found if there are fewer.
+func (tp TimeInterval) rruleCountEnd(dtstart time.Time, n int) time.Time {
+	last := dtstart
+	day := dtstart
+	for i := 0; i < rruleMaxScanDays && n > 0; i++ {
+		for _, start := range tp.rruleStarts(day) {
+			if !start.Before(dtstart) && n > 0 {
+				last = start
+				n--
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return last
+}
+
+// rruleUntilEnd returns the start of the last occurrence of the interval between dtstart and until, ignoring its
+// absolute ranges, or dtstart if there are none.
+func (tp TimeInterval) rruleUntilEnd(dtstart, until time.Time) time.Time {
+	until = until.In(dtstart.Location())
+	for day := until; !day.Before(dtstart.AddDate(0, 0, -1)); day = day.AddDate(0, 0, -1) {
+		starts := tp.rruleStarts(day)
+		for i := len(starts) - 1; i >= 0; i-- {
+			if !starts[i].After(until) && !starts[i].Before(dtstart) {
+				return starts[i]
+			}
+		}
+	}
+	return dtstart
+}
+
+// rruleStarts returns the starts of the interval's occurrences on the day of t, in order.
+func (tp TimeInterval) rruleStarts(t time.Time) []time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if !tp.containsDay(midnight) {
+		return nil
+	}
+	if tp.Times == nil {
+		return []time.Time{midnight}
+	}
+	starts := make([]time.Time, len(tp.Times))
+	for i, tr := range tp.Times {
+		starts[i] = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, tr.startSecond(), 0, t.Location())
+	}
+	return starts
+}
+
+// parseRRuleUntil reads the UNTIL part of a rule, which is a UTC time, a floating time in loc or a date, in which case
+// occurrences on that date are included.
+func parseRRuleUntil(str string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", str); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", str, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", str, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%s is not a valid RRULE UNTIL", str)
+}
+
+// rruleInts reads a list of numbers between min and max from a part of a rule, returning def if it isn't there.
+func rruleInts(parts map[string]string, key string, min, max, def int) ([]int, error) {
+	str, ok := parts[key]
+	if !ok {
+		return []int{def}, nil
+	}
+	var values []int
+	for _, v := range strings.Split(str, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid RRULE %s", v, key)
+		}
+		if n < min || n > max {
+			return nil, fmt.Errorf("%s is not a valid RRULE %s: out of range", v, key)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
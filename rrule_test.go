@@ -0,0 +1,155 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var fromRRuleTestCases = []struct {
+	rrule       string
+	dtstart     string
+	duration    time.Duration
+	interval    *TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		rrule:    "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		dtstart:  "2024-01-01T09:00:00Z",
+		duration: 8 * time.Hour,
+		interval: &TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Absolute: []AbsoluteRange{{
+				Start: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+				End:   endOfTime,
+			}},
+			Location: &Location{time.UTC},
+		},
+		contains: []string{"2024-01-05T16:59:59Z", "2030-06-03T09:00:00Z"},
+		excludes: []string{"2024-01-06T10:00:00Z", "2023-12-29T10:00:00Z", "2024-01-02T17:00:00Z"},
+	},
+	{
+		// The last Friday of each month, from 10PM for four hours, four times.
+		rrule:    "RRULE:FREQ=MONTHLY;BYDAY=-1FR;COUNT=4",
+		dtstart:  "2024-01-26T22:00:00Z",
+		duration: 4 * time.Hour,
+		interval: &TimeInterval{
+			Times:           []TimeRange{{StartMinute: 1320, EndMinute: 120}},
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: lastOccurrence, Weekday: time.Friday}},
+			Absolute: []AbsoluteRange{{
+				Start: time.Date(2024, 1, 26, 22, 0, 0, 0, time.UTC),
+				End:   time.Date(2024, 4, 27, 2, 0, 0, 0, time.UTC),
+			}},
+			Location: &Location{time.UTC},
+		},
+		contains: []string{"2024-02-24T01:00:00Z", "2024-04-26T23:00:00Z"},
+		excludes: []string{"2024-05-31T23:00:00Z", "2024-02-16T23:00:00Z"},
+	},
+	{
+		rrule:    "FREQ=YEARLY;UNTIL=20261231",
+		dtstart:  "2024-12-25T00:00:00Z",
+		duration: 24 * time.Hour,
+		interval: &TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 25, End: 25}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 12, End: 12}}},
+			Absolute: []AbsoluteRange{{
+				Start: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, 12, 26, 0, 0, 0, 0, time.UTC),
+			}},
+			Location: &Location{time.UTC},
+		},
+		contains: []string{"2026-12-25T12:00:00Z"},
+		excludes: []string{"2027-12-25T12:00:00Z", "2023-12-25T12:00:00Z"},
+	},
+	{
+		rrule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=SA;BYHOUR=6,18",
+		dtstart:  "2024-01-03T06:00:00Z",
+		duration: time.Hour,
+		contains: []string{"2024-01-06T06:30:00Z", "2024-01-06T18:30:00Z", "2024-01-20T06:30:00Z"},
+		excludes: []string{"2024-01-13T06:30:00Z", "2024-01-06T12:00:00Z"},
+	},
+	{
+		rrule:    "FREQ=DAILY;INTERVAL=3;BYMONTHDAY=1,-1",
+		dtstart:  "2024-01-01T00:00:00Z",
+		duration: time.Minute,
+		contains: []string{"2024-01-31T00:00:30Z"},
+		excludes: []string{"2024-02-01T00:00:30Z", "2024-01-30T00:00:30Z"},
+	},
+	{
+		rrule:       "FREQ=HOURLY",
+		dtstart:     "2024-01-01T00:00:00Z",
+		duration:    time.Minute,
+		expectError: true,
+	},
+	{
+		rrule:       "FREQ=MONTHLY;INTERVAL=2",
+		dtstart:     "2024-01-01T00:00:00Z",
+		duration:    time.Minute,
+		expectError: true,
+	},
+	{
+		rrule:       "FREQ=YEARLY;BYDAY=20MO",
+		dtstart:     "2024-01-01T00:00:00Z",
+		duration:    time.Minute,
+		expectError: true,
+	},
+	{
+		rrule:       "FREQ=MONTHLY;BYDAY=1MO;BYMONTHDAY=1",
+		dtstart:     "2024-01-01T00:00:00Z",
+		duration:    time.Minute,
+		expectError: true,
+	},
+	{
+		rrule:       "FREQ=DAILY;COUNT=2;UNTIL=20240101",
+		dtstart:     "2024-01-01T00:00:00Z",
+		duration:    time.Minute,
+		expectError: true,
+	},
+	{
+		rrule:       "FREQ=DAILY;BYSETPOS=1",
+		dtstart:     "2024-01-01T00:00:00Z",
+		duration:    time.Minute,
+		expectError: true,
+	},
+	{
+		rrule:       "FREQ=DAILY",
+		dtstart:     "2024-01-01T09:00:00Z",
+		duration:    24 * time.Hour,
+		expectError: true,
+	},
+}
+
+func TestFromRRule(t *testing.T) {
+	for _, tc := range fromRRuleTestCases {
+		dtstart, _ := time.Parse(time.RFC3339, tc.dtstart)
+		ti, err := FromRRule(tc.rrule, dtstart, tc.duration)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected an error converting %s, got %v", tc.rrule, ti)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error converting %s: %v", tc.rrule, err)
+			continue
+		}
+		if tc.interval != nil && !reflect.DeepEqual(ti, *tc.interval) {
+			t.Errorf("Converting %s gave %v, expected %v", tc.rrule, ti, *tc.interval)
+		}
+		for _, c := range tc.contains {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if !ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to contain %s", tc.rrule, c)
+			}
+		}
+		for _, c := range tc.excludes {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to exclude %s", tc.rrule, c)
+			}
+		}
+	}
+}
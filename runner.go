@@ -0,0 +1,127 @@
+package gotime
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// A Job is a function that a Runner calls every so often while its Matcher is active.
+type Job struct {
+	Matcher Matcher
+	// Every is how long after one run starts the next is due. The job runs once per window if it is zero.
+	Every time.Duration
+	// Jitter, if set, delays each run by a random amount up to Jitter, to spread out jobs that share a schedule.
+	Jitter time.Duration
+	// Run does the job's work. Its context is cancelled when the window the run started in closes, or the runner
+	// stops, so long runs should watch it.
+	Run func(ctx context.Context)
+}
+
+// A Runner runs jobs within the active windows of their schedules. Each job runs on its own goroutine, starting as
+// soon as its window opens and then on its cadence until the window closes, when it pauses until the next window
+// opens. A run that is due while the previous run is still going is skipped. A Runner is safe for concurrent use.
+type Runner struct {
+	mu   sync.Mutex
+	jobs []Job
+	ctx  context.Context
+	wg   sync.WaitGroup
+}
+
+// NewRunner returns a runner for the given jobs. More may be added with Add.
+func NewRunner(jobs ...Job) *Runner {
+	return &Runner{jobs: jobs}
+}
+
+// Add adds a job to the runner, starting it at once if the runner is running.
+func (r *Runner) Add(job Job) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs = append(r.jobs, job)
+	if r.ctx != nil {
+		r.start(job)
+	}
+}
+
+// Run runs the jobs until ctx is done, then waits for any runs in progress to return and returns ctx.Err(). A job
+// whose schedule never becomes active again is dropped. A Runner may only be run once.
+func (r *Runner) Run(ctx context.Context) error {
+	r.mu.Lock()
+	if r.ctx != nil {
+		r.mu.Unlock()
+		panic("gotime: Runner is already running")
+	}
+	r.ctx = ctx
+	for _, job := range r.jobs {
+		r.start(job)
+	}
+	r.mu.Unlock()
+	<-ctx.Done()
+	r.wg.Wait()
+	return ctx.Err()
+}
+
+// start runs the job on a new goroutine. r.mu must be held.
+func (r *Runner) start(job Job) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		runJob(r.ctx, job)
+	}()
+}
+
+// runJob runs job within the active windows of its schedule until ctx is done or the schedule never becomes active
+// again.
+func runJob(ctx context.Context, job Job) error {
+	m := asTransitionMatcher(job.Matcher)
+	for {
+		if err := waitUntilActive(ctx, m); err != nil {
+			return err
+		}
+		if job.Jitter > 0 {
+			if err := sleep(ctx, time.Duration(rand.Int63n(int64(job.Jitter)))); err != nil {
+				return err
+			}
+		}
+		start := time.Now()
+		window, cancel, err := activeContext(ctx, m)
+		if err != nil {
+			// The window closed during the jitter.
+			continue
+		}
+		job.Run(window)
+		cancel()
+		if job.Every <= 0 {
+			// Wait for the next window.
+			if err := waitUntilInactive(ctx, m); err != nil {
+				return err
+			}
+			continue
+		}
+		wait := job.Every - time.Since(start)
+		if wait < 0 {
+			wait = job.Every - time.Since(start)%job.Every
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// waitUntilInactive blocks until m is inactive or ctx is done, returning ctx.Err() in the latter case.
+func waitUntilInactive(ctx context.Context, m TransitionMatcher) error {
+	for {
+		now := time.Now()
+		if !m.ContainsTime(now) {
+			return nil
+		}
+		wait := maxWait
+		if next, ok := m.NextTransition(now); ok && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package gotime
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunner(t *testing.T) {
+	now := time.Now()
+	window := AbsoluteRange{Start: now.Add(-time.Minute), End: now.Add(300 * time.Millisecond)}
+	ti := TimeInterval{Absolute: []AbsoluteRange{window}}
+
+	var mu sync.Mutex
+	runs := map[string][]time.Time{}
+	record := func(name string) func(context.Context) {
+		return func(ctx context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			runs[name] = append(runs[name], time.Now())
+			if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(window.End) {
+				t.Errorf("Expected %s to run until the window closes at %s, got %s", name, window.End, deadline)
+			}
+		}
+	}
+	r := NewRunner(
+		Job{Matcher: ti, Every: 50 * time.Millisecond, Run: record("cadence")},
+		Job{Matcher: ti, Run: record("once")},
+		Job{Matcher: ti, Every: 100 * time.Millisecond, Jitter: 20 * time.Millisecond, Run: record("jitter")},
+		Job{Matcher: startingIn(time.Hour), Run: record("later")},
+		Job{Matcher: TimeInterval{Weekdays: []WeekdayRange{}}, Run: record("never")},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	go r.Add(Job{Matcher: ti, Run: record("added")})
+	if err := r.Run(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the runner to stop with the context, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for name, times := range runs {
+		for _, at := range times {
+			if at.After(window.End) {
+				t.Errorf("Expected %s to run within the window, ran at %s", name, at)
+			}
+		}
+	}
+	for name, expected := range map[string][2]int{
+		"cadence": {5, 7}, "once": {1, 1}, "jitter": {2, 3}, "added": {1, 1}, "later": {0, 0}, "never": {0, 0},
+	} {
+		if n := len(runs[name]); n < expected[0] || n > expected[1] {
+			t.Errorf("Expected %s to run between %d and %d times, ran %d", name, expected[0], expected[1], n)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package gotime
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The schedule form of an IntervalSet extends the text form of its intervals with shorthand for fields that would
+// otherwise need their name, so that a whole set fits on one line, e.g. 'mon-fri 09:00-17:00; dec 24-26 !; 2025-'.
+// Intervals are separated by semicolons or new lines, and each is a list of space separated terms:
+//
+//	mon-fri, jan-mar, 09:00-17:00   weekdays, months and times, as in the text form
+//	24-26, -7:-1, tue#2, LW         days of the month, including weekdays of the month in the style of cron
+//	2024, 2020-2025, 2025-, -2030   years, where a range with no end runs to 9999 and one with no start from 0
+//	2024-12-24, 2024-12-24..2025-01-02
+//	                                dates, and ranges of dates written with '..' or ':'
+//	Europe/London, UTC              the location of the interval
+//	!                               the interval denies the times it contains, as with mode=deny
+//	field=value                     any other field that has a text form
+//
+// Several values of the same kind may be separated by commas, e.g. '1,15' or 'sat,sun'.
+
+var (
+	scheduleDateRE  = regexp.MustCompile(`^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}(?:(?:\.\.|:)[0-9]{4}-[0-9]{1,2}-[0-9]{1,2})?$`)
+	scheduleYearRE  = regexp.MustCompile(`^(?:[0-9]{4}|[0-9]{4}-[0-9]{4}|[0-9]{4}:[0-9]{4}|[0-9]{4}-|-[0-9]{4})$`)
+	scheduleDayRE   = regexp.MustCompile(`^-?[0-9]{1,2}(?:[-:]-?[0-9]{1,2})?$`)
+	scheduleZoneRE  = regexp.MustCompile(`^(?:[A-Za-z_]+(?:/[A-Za-z0-9_+-]+)+|UTC|utc)$`)
+	scheduleDenyRE  = regexp.MustCompile(`^(.*?)!$`)
+	scheduleFields  = []string{"dates", "years", "days_of_month", "location"}
+	scheduleMatches = map[string]func(string) bool{
+		"dates":         scheduleDateRE.MatchString,
+		"years":         scheduleYearRE.MatchString,
+		"days_of_month": func(item string) bool { return scheduleDayRE.MatchString(item) || isWeekdayOfMonth(item) },
+		"location":      scheduleZoneRE.MatchString,
+	}
+)
+
+// ParseSchedule parses a set of intervals written in the schedule form, such as 'mon-fri 09:00-17:00; dec 24-26 !;
+// 2025-', which is the text form of each interval separated by semicolons, with shorthand for days of the month,
+// years, dates, locations and denying intervals. It is meant for places where a structured document is impractical,
+// such as labels, annotations and command line arguments. Anything written by IntervalSet.MarshalText can be parsed.
+func ParseSchedule(schedule string) (IntervalSet, error) {
+	set := IntervalSet{}
+	for _, line := range strings.Split(schedule, "\n") {
+		for _, part := range strings.Split(line, IntervalSetSeparator) {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			text, err := expandScheduleTerms(strings.Fields(part))
+			if err != nil {
+				return nil, err
+			}
+			var ti TimeInterval
+			if err := ti.UnmarshalText([]byte(text)); err != nil {
+				return nil, fmt.Errorf("%s: %v", strings.TrimSpace(part), err)
+			}
+			set = append(set, ti)
+		}
+	}
+	return set, nil
+}
+
+// expandScheduleTerms returns the text form of an interval written as the given terms of the schedule form.
+func expandScheduleTerms(terms []string) (string, error) {
+	var out []string
+	deny := false
+	for i, term := range terms {
+		// A trailing '!' may be written on its own or at the end of the last term.
+		if m := scheduleDenyRE.FindStringSubmatch(term); m != nil && i == len(terms)-1 {
+			deny = true
+			if term = m[1]; term == "" {
+				continue
+			}
+		}
+		out = append(out, expandScheduleTerm(term))
+	}
+	if deny {
+		for _, term := range out {
+			if strings.HasPrefix(strings.ToLower(term), "mode=") {
+				return "", fmt.Errorf("%s can't be given with !", term)
+			}
+		}
+		out = append(out, "mode=deny")
+	}
+	return strings.Join(out, " "), nil
+}
+
+// expandScheduleTerm returns a term of the schedule form as a term of the text form, naming its field if every value
+// is shorthand for the same one.
+func expandScheduleTerm(term string) string {
+	if strings.Contains(term, "=") {
+		return term
+	}
+	items := strings.Split(term, ",")
+	for _, field := range scheduleFields {
+		matched := true
+		for _, item := range items {
+			if !scheduleMatches[field](item) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		switch field {
+		case "dates":
+			term = strings.Replace(term, "..", ":", -1)
+		case "years":
+			for i, item := range items {
+				switch {
+				case strings.HasSuffix(item, "-"):
+					items[i] = fmt.Sprintf("%s:%d", strings.TrimSuffix(item, "-"), endOfTime.Year())
+				case strings.HasPrefix(item, "-"):
+					items[i] = "0:" + strings.TrimPrefix(item, "-")
+				}
+			}
+			term = strings.Join(items, ",")
+		}
+		return field + "=" + term
+	}
+	return term
+}
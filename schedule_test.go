@@ -0,0 +1,111 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	set, err := ParseSchedule("mon-fri 09:00-17:00; dec 24-26 !; 2025-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := IntervalSet{
+		{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+		},
+		{
+			Mode:        ModeDeny,
+			Months:      []MonthRange{{InclusiveRange{Begin: 12, End: 12}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 24, End: 26}}},
+		},
+		{Years: []YearRange{{InclusiveRange{Begin: 2025, End: 9999}}}},
+	}
+	wantText, _ := want.MarshalText()
+	gotText, _ := set.MarshalText()
+	if string(gotText) != string(wantText) {
+		t.Errorf("Expected %s, got %s", wantText, gotText)
+	}
+	for _, c := range []struct {
+		at    string
+		match bool
+	}{
+		{"2024-12-23T10:00:00Z", true},
+		{"2024-12-24T10:00:00Z", false},
+		{"2024-12-28T10:00:00Z", false},
+		{"2025-12-25T10:00:00Z", true},
+	} {
+		at, err := time.Parse(time.RFC3339, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := set.ContainsTime(at); got != c.match {
+			t.Errorf("Expected the schedule to contain %s: %t, got %t", c.at, c.match, got)
+		}
+	}
+}
+
+func TestParseScheduleTerms(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want string
+	}{
+		{"sat,sun 10:00-14:00 Europe/London", "weekdays=saturday,sunday 10:00-14:00 location=Europe/London"},
+		{"1,15 -7:-1 tue#2 LW", "days_of_month=1,15,-7:-1,tuesday#2,LW"},
+		{"2020-2022,2030- -1999", "years=2020:2022,2030:9999,0:1999"},
+		{"2024-12-24..2025-01-02,2025-03-01 UTC", "dates=2024-12-24:2025-01-02,2025-03-01 location=UTC"},
+		{"jan-mar weeks=1-10 always!", "months=january:march weeks=1:10 mode=deny"},
+		{"mode=deny sat", "mode=deny weekdays=saturday"},
+	} {
+		set, err := ParseSchedule(c.in)
+		if err != nil {
+			t.Errorf("Error parsing %s: %v", c.in, err)
+			continue
+		}
+		var want TimeInterval
+		if err := want.UnmarshalText([]byte(c.want)); err != nil {
+			t.Fatal(err)
+		}
+		wantText, _ := want.MarshalText()
+		gotText, _ := set.MarshalText()
+		if len(set) != 1 || string(gotText) != string(wantText) {
+			t.Errorf("Expected %s to parse as %s, got %s", c.in, wantText, gotText)
+		}
+	}
+}
+
+func TestParseScheduleRoundTrip(t *testing.T) {
+	in := IntervalSet{
+		{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}, Location: mustLoadLocation("Australia/Melbourne")},
+		{},
+		{Dates: []DateRange{{Begin: date(2024, time.December, 24), End: date(2025, time.January, 2)}}},
+	}
+	text, err := in.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := ParseSchedule(string(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := set.MarshalText(); string(got) != string(text) {
+		t.Errorf("Expected %s, got %s", text, got)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, in := range []string{
+		"mon-fri 09:00",
+		"dec 32",
+		"2025-2020",
+		"someday",
+		"mode=allow sat !",
+		"2024-02-30",
+		"Not/AZone",
+	} {
+		if set, err := ParseSchedule(in); err == nil {
+			t.Errorf("Expected an error parsing %s, got %v", in, set)
+		}
+	}
+}
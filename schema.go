@@ -0,0 +1,320 @@
+package gotime
+
+//go:generate go run ./internal/genschema schema/gotime.schema.json
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// JSONSchemaID is the identifier given to the schema returned by JSONSchema.
+const JSONSchemaID = "https://github.com/benridley/gotime/schema/gotime.schema.json"
+
+// A schema is a JSON Schema, or a part of one.
+type schema map[string]interface{}
+
+// JSONSchema returns a JSON Schema (draft 7) describing a YAML or JSON document holding a list of intervals, so that
+// editors and CI can check configuration before it is deployed. A single interval is described by its definitions
+// under #/definitions/interval. The forms that each field's strings may take are given as patterns, though values
+// such as the 31st of February that only fail once parsed are left to Unmarshal. A copy is kept in
+// schema/gotime.schema.json.
+func JSONSchema() ([]byte, error) {
+	return json.MarshalIndent(intervalSetSchema(), "", "  ")
+}
+
+func intervalSetSchema() schema {
+	return schema{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"$id":         JSONSchemaID,
+		"title":       "gotime intervals",
+		"description": "A list of time intervals. A time matches the list if the last interval containing it has mode allow.",
+		"type":        "array",
+		"items":       ref("interval"),
+		"definitions": schema{
+			"interval": schema{
+				"description": "A time interval. Weekday and month names are checked against English unless a locale is set.",
+				"if":          schema{"required": []string{"locale"}},
+				"then":        ref("localisedInterval"),
+				"else":        ref("englishInterval"),
+			},
+			"englishInterval":   intervalFieldsSchema(false),
+			"localisedInterval": intervalFieldsSchema(true),
+			"timeRange":         timeRangeSchema(),
+		},
+	}
+}
+
+// intervalFieldsSchema describes the fields of an interval. If localised is true, weekday and month names may be in
+// any language, and the interval's exceptions inherit this.
+func intervalFieldsSchema(localised bool) schema {
+	weekday := rangeItem("A day of the week by name or number from 0 (Sunday) to 7 (Sunday), or a range of them such "+
+		"as 'monday:friday', optionally with a step such as '/2'. The keywords always, *, never, weekdays and weekend "+
+		"are also understood.", ranges(weekdayPattern, true), 0, 7, "weekdays", "weekday", "weekend", "weekends")
+	weekend := rangeItem("A day of the weekend by name or number, or a range of them.",
+		ranges(weekdayPattern, false), 0, 7)
+	month := rangeItem("A month by name or number, or a range of them such as 'jan:mar' which may wrap around the end "+
+		"of the year, optionally with a step such as '/3'.", ranges(monthPattern, true), 1, 12)
+	fiscalYearStart := schema{
+		"description": "The month in which years and quarters begin.",
+		"anyOf": []schema{
+			{"type": "string", "pattern": "^" + monthPattern + "$"},
+			{"type": "integer", "minimum": 1, "maximum": 12},
+		},
+	}
+	except := schema{
+		"description": "Intervals that each stop this interval from matching the times they contain.",
+		"type":        "array",
+		"items":       ref("interval"),
+	}
+	if localised {
+		for _, s := range []schema{weekday, weekend, month} {
+			delete(s, "anyOf")
+			s["type"] = []string{"string", "integer"}
+		}
+		fiscalYearStart = schema{"description": fiscalYearStart["description"], "type": []string{"string", "integer"}}
+		except["items"] = ref("localisedInterval")
+	}
+	return schema{
+		"type":                 "object",
+		"additionalProperties": false,
+		"not": schema{
+			"description": "repeat is another name for cycle, so only one may be given.",
+			"required":    []string{"cycle", "repeat"},
+		},
+		"properties": schema{
+			"name": schema{
+				"description": "A name for the interval, with no effect on what it matches.",
+				"type":        "string",
+			},
+			"description": schema{
+				"description": "A description of the interval, with no effect on what it matches.",
+				"type":        "string",
+			},
+			"labels": schema{
+				"description":          "Labels for the interval, with no effect on what it matches.",
+				"type":                 "object",
+				"additionalProperties": schema{"type": "string"},
+			},
+			"mode": schema{
+				"description": "Whether the interval adds the times it contains to its list or takes them away.",
+				"type":        "string",
+				"pattern":     "^" + choice(keysOf(modes)) + "$",
+			},
+			"times": schema{
+				"description": "Times of day during which the interval is active.",
+				"type":        "array",
+				"items":       ref("timeRange"),
+			},
+			"weekdays": list(weekday),
+			"days_of_month": list(rangeItem("A day of the month from 1, or counting back from -1 for the last day, or a "+
+				"range of them such as '-7:-1', optionally with a step such as '/2'. The Nth weekday of the month may also "+
+				"be given, e.g. '2nd tuesday', 'last friday', 'tue#2', '5L' or 'LW'.",
+				"(?:"+ranges(`-?(?:[1-9]|[12][0-9]|3[01])`, true)+"|"+weekdayOfMonthPattern+")", -31, 31)),
+			"weekdays_of_month": list(rangeItem("The Nth weekday of the month, e.g. '2nd tuesday' or 'last friday'.",
+				weekdayOfMonthPattern, 0, -1)),
+			"weeks": list(rangeItem("An ISO 8601 week number, or a range of them, optionally with a step.",
+				ranges(`(?:[1-9]|[1-4][0-9]|5[0-3])`, true), 1, 53)),
+			"months": list(month),
+			"quarters": list(rangeItem("A quarter of the year such as 'q1' or 1, or a range of them, optionally with a step.",
+				ranges(`[Qq]?[1-4]`, true), 1, 4)),
+			"years": list(rangeItem("A year, or a range of them such as '2020:2025', optionally with a step.",
+				ranges(`[0-9]+`, true), 0, maxInt)),
+			"relative": list(rangeItem("A day relative to a feast, e.g. 'easter-2', or a range of them such as "+
+				"'easter-2:easter+1'.", ranges(choice(keysOf(feasts))+`(?:\s*[+-]\s*[0-9]+)?`, false), 0, -1)),
+			"dates": list(rangeItem("A date as YYYY-MM-DD, or a range of them such as '2024-12-24:2025-01-02'.",
+				ranges(`[0-9]{4}-[0-9]{2}-[0-9]{2}`, false), 0, -1)),
+			"absolute": schema{
+				"description": "Concrete periods outside of which the interval is never active.",
+				"type":        "array",
+				"items": schema{
+					"type":                 "object",
+					"additionalProperties": false,
+					"required":             []string{"start", "end"},
+					"properties": schema{
+						"start": schema{
+							"description": "The start of the period, which is included in it.",
+							"type":        "string",
+							"format":      "date-time",
+						},
+						"end": schema{
+							"description": "The end of the period, which is excluded from it.",
+							"type":        "string",
+							"format":      "date-time",
+						},
+					},
+				},
+			},
+			"fiscal_year_start": fiscalYearStart,
+			"cycle":             cycleSchema(),
+			"repeat":            cycleSchema(),
+			"calendar": schema{
+				"description":          "Dates in a calendar other than the Gregorian one.",
+				"type":                 "object",
+				"additionalProperties": false,
+				"required":             []string{"system"},
+				"properties": schema{
+					"system": schema{
+						"description": "The name of a registered calendar system, e.g. hebrew or islamic.",
+						"type":        "string",
+					},
+					"dates": list(rangeItem("A date in the calendar as 'month-day', or a month on its own, or a range of "+
+						"them such as '9-25:10-2'.", ranges(`(?:[1-9]|1[0-3])(?:-(?:[1-9]|[12][0-9]|30))?`, false), 0, -1)),
+				},
+			},
+			"holidays": schema{
+				"description": "The name of a registered holiday calendar, e.g. us-federal, uk-bank, au-national or eu-target.",
+				"type":        "string",
+			},
+			"expression": schema{
+				"description": "A condition on the time such as 'day % 2 == 0 && hour >= 9', for rules the other fields " +
+					"can't express.",
+				"type": "string",
+			},
+			"location": schema{"description": "An IANA time zone name such as Australia/Melbourne.", "type": "string"},
+			"locale":   schema{"description": "The language of weekday and month names, e.g. fr or de.", "type": "string"},
+			"weekend":  list(weekend),
+			"coordinates": schema{
+				"description":          "The position used to work out sunrise and sunset, in decimal degrees.",
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": schema{
+					"latitude":  schema{"type": "number", "minimum": -90, "maximum": 90},
+					"longitude": schema{"type": "number", "minimum": -180, "maximum": 180},
+				},
+			},
+			"dst": schema{
+				"description":          "How times of day are matched on days when clocks change.",
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": schema{
+					"gap":     schema{"type": "string", "pattern": "^" + choice(keysOf(gapPolicies)) + "$"},
+					"overlap": schema{"type": "string", "pattern": "^" + choice(keysOf(overlapPolicies)) + "$"},
+				},
+			},
+			"except": except,
+		},
+	}
+}
+
+func timeRangeSchema() schema {
+	clock := `(?:(?:[01][0-9]|2[0-3]):[0-5][0-9](?::[0-5][0-9])?|24:00(?::00)?)`
+	solar := `\s*` + choice(keysOf(solarEvents)) + `(?:\s*[+-].+)?\s*`
+	item := schema{
+		"description": "A time of day as HH:MM or HH:MM:SS, or sunrise or sunset with an optional offset such as '+30m'.",
+		"type":        "string",
+		"pattern":     "^(?:" + clock + "|" + solar + ")$",
+	}
+	return schema{
+		"description":          "A range of times of day, which runs past midnight if it ends before it starts.",
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"start_time", "end_time"},
+		"properties":           schema{"start_time": item, "end_time": item},
+	}
+}
+
+func cycleSchema() schema {
+	return schema{
+		"description":          "Matches one day or week in every N, counting from an anchor date.",
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"anchor", "every"},
+		"properties": schema{
+			"anchor": schema{
+				"description": "The first day of the cycle as YYYY-MM-DD.",
+				"type":        "string",
+				"pattern":     `^[0-9]{4}-[0-9]{2}-[0-9]{2}$`,
+			},
+			"every": schema{
+				"description": "The length of the cycle, e.g. '2w', '3d' or a duration in whole days such as '72h'.",
+				"type":        "string",
+				"pattern":     `^(?:[1-9][0-9]*\s*[dw]|(?:[0-9]+(?:\.[0-9]*)?(?:ns|us|µs|ms|s|m|h))+)$`,
+			},
+		},
+	}
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+var (
+	weekdayPattern        = "(?:" + choice(keysOf(daysOfWeek)) + "|[0-7])"
+	monthPattern          = "(?:" + choice(keysOf(months)) + "|[1-9]|1[0-2])"
+	weekdayOfMonthPattern = "(?:[1-5](?:st|nd|rd|th)\\s+" + weekdayOrAny + "|" + caseless("last") + "\\s+" + weekdayOrAny +
+		"|(?:" + weekdayOrAny + "|[0-7])#[1-5]|[0-7][Ll]|[Ll][Ww])"
+	weekdayOrAny = choice(append(keysOf(daysOfWeek), "weekday"))
+)
+
+// ranges returns a pattern matching a member or a range of two members, followed by a step if stepped is true.
+func ranges(member string, stepped bool) string {
+	pattern := member + "(?::" + member + ")?"
+	if stepped {
+		pattern += `(?:\s*/\s*[1-9][0-9]*)?`
+	}
+	return pattern
+}
+
+// rangeItem describes an item in a list of ranges matching pattern, which may also be written as an integer between
+// min and max unless max is less than min. Any keywords given are understood alongside always, * and never.
+func rangeItem(description, pattern string, min, max int, keywords ...string) schema {
+	keyword := `\s*(?:` + choice(append([]string{"always", "never"}, keywords...)) + `|\*)\s*`
+	str := schema{"type": "string", "pattern": "^(?:" + keyword + "|" + pattern + ")$"}
+	if max < min {
+		str["description"] = description
+		return str
+	}
+	number := schema{"type": "integer", "minimum": min}
+	if max != maxInt {
+		number["maximum"] = max
+	}
+	return schema{"description": description, "anyOf": []schema{str, number}}
+}
+
+func list(item schema) schema {
+	return schema{"type": "array", "items": item}
+}
+
+func ref(definition string) schema {
+	return schema{"$ref": "#/definitions/" + definition}
+}
+
+// choice returns a pattern matching any of the names regardless of case, trying longer names first so that a name
+// isn't matched by one of its abbreviations.
+func choice(names []string) string {
+	sorted := append([]string{}, names...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	for i, name := range sorted {
+		sorted[i] = caseless(name)
+	}
+	return "(?:" + strings.Join(sorted, "|") + ")"
+}
+
+// caseless returns a pattern matching s regardless of case.
+func caseless(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			b.WriteString("[" + string(unicode.ToUpper(r)) + string(unicode.ToLower(r)) + "]")
+		} else {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// keysOf returns the keys of a map with string keys.
+func keysOf(m interface{}) []string {
+	var keys []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	return keys
+}
@@ -0,0 +1,289 @@
+package gotime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var schemaTestCases = []struct {
+	in    string
+	valid bool
+}{
+	{
+		in: `
+- weekdays: ['monday:friday', 'sunday']
+  months: ['january:march']
+  days_of_month: ['-7:-1', '1:31/2', '2nd tuesday', 'LW']
+  years: ['2020:2025', '2030:2035']
+  times:
+    - start_time: '09:00'
+      end_time: '17:00'
+    - start_time: 'sunset-30m'
+      end_time: 'Sunrise'
+  coordinates: {latitude: 51.5, longitude: -0.12}
+`,
+		valid: true,
+	},
+	{
+		in: `
+- weekdays: ['Lundi:vendredi']
+  locale: fr
+  except:
+    - months: ['décembre']
+`,
+		valid: true,
+	},
+	{
+		in: `
+- weekdays: ['weekend', 6, '*']
+  weeks: ['1:2', 53]
+  quarters: ['q1', 3]
+  relative: ['easter-2:easter+1']
+  dates: ['2024-12-24:2025-01-02']
+  fiscal_year_start: july
+  repeat: {anchor: '2024-01-01', every: '72h'}
+  calendar: {system: hebrew, dates: ['9-25:10-2', '13']}
+  absolute: [{start: '2024-06-01T22:00:00Z', end: '2024-06-02T02:00:00Z'}]
+  dst: {gap: shift, overlap: first}
+  mode: deny
+  labels: {team: ops}
+`,
+		valid: true,
+	},
+	{
+		in: `
+- weekday: ['monday:friday']
+`,
+	},
+	{
+		in: `
+- weekdays: ['monday:fry']
+`,
+	},
+	{
+		in: `
+- weekdays: ['lundi']
+  except:
+    - months: ['janvier']
+`,
+	},
+	{
+		in: `
+- times:
+    - start_time: '09:00'
+      end_time: '25:00'
+`,
+	},
+	{
+		in: `
+- days_of_month: ['32']
+`,
+	},
+	{
+		in: `
+- cycle: {anchor: '2024-01-01', every: '2w'}
+  repeat: {anchor: '2024-01-01', every: '2w'}
+`,
+	},
+	{
+		in: `
+- times:
+    - start_time: '09:00'
+`,
+	},
+	{
+		in: `
+- months: [13]
+`,
+	},
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := loadSchema(t)
+	for _, tc := range schemaTestCases {
+		err := validateSchema(s, s, decodeForSchema(t, tc.in))
+		if tc.valid && err != nil {
+			t.Errorf("Expected %s to be valid, got %v", tc.in, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Expected %s to be invalid", tc.in)
+		}
+	}
+}
+
+func TestJSONSchemaAcceptsTestCases(t *testing.T) {
+	s := loadSchema(t)
+	for _, tc := range yamlUnmarshalTestCases {
+		if tc.expectError {
+			continue
+		}
+		if err := validateSchema(s, s, decodeForSchema(t, tc.in)); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", tc.in, err)
+		}
+	}
+}
+
+func TestJSONSchemaArtifact(t *testing.T) {
+	want, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile("schema/gotime.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want)+"\n" {
+		t.Errorf("schema/gotime.schema.json is out of date, run go generate")
+	}
+}
+
+func loadSchema(t *testing.T) map[string]interface{} {
+	out, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(out, &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// decodeForSchema decodes a YAML document into the values it would have as JSON.
+func decodeForSchema(t *testing.T, in string) interface{} {
+	var doc interface{}
+	if err := yamlv3.Unmarshal([]byte(in), &doc); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(out, &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// validateSchema checks v against the parts of JSON Schema used by JSONSchema.
+func validateSchema(root, s map[string]interface{}, v interface{}) error {
+	if r, ok := s["$ref"].(string); ok {
+		def := root["definitions"].(map[string]interface{})[strings.TrimPrefix(r, "#/definitions/")]
+		return validateSchema(root, def.(map[string]interface{}), v)
+	}
+	if cond, ok := s["if"].(map[string]interface{}); ok {
+		branch := "else"
+		if validateSchema(root, cond, v) == nil {
+			branch = "then"
+		}
+		if b, ok := s[branch].(map[string]interface{}); ok {
+			if err := validateSchema(root, b, v); err != nil {
+				return err
+			}
+		}
+	}
+	if not, ok := s["not"].(map[string]interface{}); ok && validateSchema(root, not, v) == nil {
+		return fmt.Errorf("%v matches %v", v, not)
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		var errs []string
+		for _, sub := range anyOf {
+			err := validateSchema(root, sub.(map[string]interface{}), v)
+			if err == nil {
+				errs = nil
+				break
+			}
+			errs = append(errs, err.Error())
+		}
+		if errs != nil {
+			return fmt.Errorf("%v matches none of: %s", v, strings.Join(errs, "; "))
+		}
+	}
+	if typ, ok := s["type"]; ok && !schemaTypeMatches(typ, v) {
+		return fmt.Errorf("%v is not of type %v", v, typ)
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, r := range asSlice(s["required"]) {
+			if _, ok := v[r.(string)]; !ok {
+				return fmt.Errorf("%v is missing %s", v, r)
+			}
+		}
+		props, _ := s["properties"].(map[string]interface{})
+		for k, fv := range v {
+			if p, ok := props[k]; ok {
+				if err := validateSchema(root, p.(map[string]interface{}), fv); err != nil {
+					return fmt.Errorf("%s: %v", k, err)
+				}
+				continue
+			}
+			switch extra := s["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					return fmt.Errorf("%s is not allowed", k)
+				}
+			case map[string]interface{}:
+				if err := validateSchema(root, extra, fv); err != nil {
+					return fmt.Errorf("%s: %v", k, err)
+				}
+			}
+		}
+	case []interface{}:
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateSchema(root, items, item); err != nil {
+					return fmt.Errorf("[%d]: %v", i, err)
+				}
+			}
+		}
+	case string:
+		if p, ok := s["pattern"].(string); ok && !regexp.MustCompile(p).MatchString(v) {
+			return fmt.Errorf("%q does not match %s", v, p)
+		}
+	case float64:
+		if min, ok := s["minimum"].(float64); ok && v < min {
+			return fmt.Errorf("%v is less than %v", v, min)
+		}
+		if max, ok := s["maximum"].(float64); ok && v > max {
+			return fmt.Errorf("%v is more than %v", v, max)
+		}
+	}
+	return nil
+}
+
+func schemaTypeMatches(typ interface{}, v interface{}) bool {
+	if types, ok := typ.([]interface{}); ok {
+		for _, t := range types {
+			if schemaTypeMatches(t, v) {
+				return true
+			}
+		}
+		return false
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return typ == "object"
+	case []interface{}:
+		return typ == "array"
+	case string:
+		return typ == "string"
+	case bool:
+		return typ == "boolean"
+	case float64:
+		return typ == "number" || (typ == "integer" && v == math.Trunc(v))
+	}
+	return false
+}
+
+func asSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
@@ -0,0 +1,69 @@
+package gotime
+
+import "time"
+
+// An IntervalSet contains a time if any one of its intervals does. It can be unmarshalled directly from a YAML list of
+// intervals. When some intervals have ModeDeny, later intervals take precedence over earlier ones: a time is contained
+// if the last interval that contains it allows it, so a set can be open on weekdays, closed on holidays, but open
+// again on a particular holiday.
+type IntervalSet []TimeInterval
+
+// ContainsTime returns true if the last interval in the IntervalSet that contains the given time allows it.
+func (is IntervalSet) ContainsTime(t time.Time) bool {
+	for i := len(is) - 1; i >= 0; i-- {
+		if is[i].ContainsTime(t) {
+			return is[i].Mode == ModeAllow
+		}
+	}
+	return false
+}
+
+// NextTransition returns the earliest time after t at which the IntervalSet either becomes active or stops being
+// active. It returns false if the set never changes state within the search horizon.
+func (is IntervalSet) NextTransition(t time.Time) (time.Time, bool) {
+	return nextCompositeTransition(is, is.matchers(), t)
+}
+
+// PreviousTransition returns the latest time at or before t at which the IntervalSet either became active or stopped
+// being active. It returns false if the set never changed state within the search horizon.
+func (is IntervalSet) PreviousTransition(t time.Time) (time.Time, bool) {
+	return previousCompositeTransition(is, is.matchers(), t)
+}
+
+// NextActiveTime returns t if the IntervalSet contains it, otherwise the time at which the set next becomes active.
+// It returns false if the set doesn't become active within the search horizon.
+func (is IntervalSet) NextActiveTime(t time.Time) (time.Time, bool) {
+	return nextActiveTime(is, t)
+}
+
+// Windows returns the windows during which the IntervalSet is active between from and to, in chronological order.
+// Overlapping windows of the individual intervals are merged and the result is clipped to the bounds.
+func (is IntervalSet) Windows(from, to time.Time) []Window {
+	return transitionWindows(is, from, to)
+}
+
+// Matching returns the intervals in the IntervalSet that contain the given time whatever their mode, in the order they
+// appear in the set, e.g. to report which named window is responsible for a time being active.
+func (is IntervalSet) Matching(t time.Time) []TimeInterval {
+	var matching []TimeInterval
+	for _, ti := range is {
+		if ti.ContainsTime(t) {
+			matching = append(matching, ti)
+		}
+	}
+	return matching
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for IntervalSet, so that it is marshalled as a list of intervals
+// rather than in its text form.
+func (is IntervalSet) MarshalYAML() (interface{}, error) {
+	return []TimeInterval(is), nil
+}
+
+func (is IntervalSet) matchers() []TransitionMatcher {
+	children := make([]TransitionMatcher, len(is))
+	for i, ti := range is {
+		children[i] = ti
+	}
+	return children
+}
@@ -0,0 +1,167 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+var intervalSetTestCases = []struct {
+	in         string
+	set        IntervalSet
+	contains   []string
+	excludes   []string
+	at         string
+	next       string
+	previous   string
+	nextActive string
+	from, to   string
+	windows    [][2]string
+}{
+	{
+		// Business hours plus saturday mornings, overlapping on friday
+		in: `
+---
+- weekdays: ['monday:friday']
+  times:
+    - start_time: '09:00'
+      end_time: '17:00'
+- weekdays: ['friday:saturday']
+  times:
+    - start_time: '07:00'
+      end_time: '12:00'
+`,
+		set: IntervalSet{
+			{
+				Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+				Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			},
+			{
+				Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}},
+				Times:    []TimeRange{{StartMinute: 420, EndMinute: 720}},
+			},
+		},
+		contains:   []string{"2020-07-10T08:00:00Z", "2020-07-10T16:00:00Z", "2020-07-11T11:00:00Z"},
+		excludes:   []string{"2020-07-09T08:00:00Z", "2020-07-11T13:00:00Z"},
+		at:         "2020-07-10T10:00:00Z",
+		next:       "2020-07-10T17:00:00Z",
+		previous:   "2020-07-10T07:00:00Z",
+		nextActive: "2020-07-10T10:00:00Z",
+		from:       "2020-07-10T00:00:00Z",
+		to:         "2020-07-12T00:00:00Z",
+		windows: [][2]string{
+			{"2020-07-10T07:00:00Z", "2020-07-10T17:00:00Z"},
+			{"2020-07-11T07:00:00Z", "2020-07-11T12:00:00Z"},
+		},
+	},
+	{
+		// Open on weekdays, closed on Christmas Day, but open again on Christmas morning
+		in: `
+---
+- weekdays: ['monday:friday']
+- mode: deny
+  months: ['december']
+  days_of_month: ['25']
+- times:
+    - start_time: '09:00'
+      end_time: '12:00'
+  months: ['december']
+  days_of_month: ['25']
+`,
+		set: IntervalSet{
+			{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}},
+			{
+				Mode:        ModeDeny,
+				Months:      []MonthRange{{InclusiveRange{Begin: 12, End: 12}}},
+				DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 25, End: 25}}},
+			},
+			{
+				Times:       []TimeRange{{StartMinute: 540, EndMinute: 720}},
+				Months:      []MonthRange{{InclusiveRange{Begin: 12, End: 12}}},
+				DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 25, End: 25}}},
+			},
+		},
+		contains:   []string{"2020-12-24T15:00:00Z", "2020-12-25T10:00:00Z", "2020-12-28T10:00:00Z"},
+		excludes:   []string{"2020-12-25T08:00:00Z", "2020-12-25T13:00:00Z", "2020-12-26T10:00:00Z"},
+		at:         "2020-12-25T10:00:00Z",
+		next:       "2020-12-25T12:00:00Z",
+		previous:   "2020-12-25T09:00:00Z",
+		nextActive: "2020-12-25T10:00:00Z",
+		from:       "2020-12-24T00:00:00Z",
+		to:         "2020-12-26T00:00:00Z",
+		windows: [][2]string{
+			{"2020-12-24T00:00:00Z", "2020-12-25T00:00:00Z"},
+			{"2020-12-25T09:00:00Z", "2020-12-25T12:00:00Z"},
+		},
+	},
+	{
+		// An empty set never matches
+		in:         `[]`,
+		set:        IntervalSet{},
+		excludes:   []string{"2020-07-10T08:00:00Z"},
+		at:         "2020-07-10T10:00:00Z",
+		next:       "",
+		previous:   "",
+		nextActive: "",
+		from:       "2020-07-10T00:00:00Z",
+		to:         "2020-07-12T00:00:00Z",
+		windows:    nil,
+	},
+}
+
+func TestIntervalSet(t *testing.T) {
+	for _, tc := range intervalSetTestCases {
+		var is IntervalSet
+		if err := yaml.Unmarshal([]byte(tc.in), &is); err != nil {
+			t.Errorf("Received unexpected error: %v when parsing %v", err, tc.in)
+			continue
+		}
+		if !reflect.DeepEqual(is, tc.set) {
+			t.Errorf("Error unmarshalling %s: Want %+v, got %+v", tc.in, tc.set, is)
+		}
+		for _, ts := range tc.contains {
+			if !is.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected set %+v to contain %s", is, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if is.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected set %+v to exclude %s", is, ts)
+			}
+		}
+		at := mustParseTime(tc.at)
+		next, ok := is.NextTransition(at)
+		checkTransition(t, "NextTransition", is, tc.next, next, ok)
+		previous, ok := is.PreviousTransition(at)
+		checkTransition(t, "PreviousTransition", is, tc.previous, previous, ok)
+		nextActive, ok := is.NextActiveTime(at)
+		checkTransition(t, "NextActiveTime", is, tc.nextActive, nextActive, ok)
+
+		var want []Window
+		for _, w := range tc.windows {
+			want = append(want, Window{Start: mustParseTime(w[0]), End: mustParseTime(w[1])})
+		}
+		if got := is.Windows(mustParseTime(tc.from), mustParseTime(tc.to)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Windows for %+v: want %v, got %v", is, want, got)
+		}
+	}
+}
+
+func TestIntervalSetMatching(t *testing.T) {
+	set := IntervalSet{
+		{Name: "business hours", Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}}},
+		{Name: "saturday", Weekdays: []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}}},
+		{Name: "friday", Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 5}}}},
+	}
+	var names []string
+	for _, ti := range set.Matching(mustParseTime("2020-07-10T10:00:00Z")) {
+		names = append(names, ti.Name)
+	}
+	if want := []string{"business hours", "friday"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Intervals matching a Friday: want %v, got %v", want, names)
+	}
+	if got := set.Matching(mustParseTime("2020-07-12T10:00:00Z")); got != nil {
+		t.Errorf("Expected no intervals to match a Sunday, got %v", got)
+	}
+}
@@ -0,0 +1,259 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A SolarEvent is a point in the day fixed by the position of the sun rather than the clock.
+type SolarEvent int
+
+const (
+	// Sunrise is the moment the upper edge of the sun appears over the horizon.
+	Sunrise SolarEvent = iota + 1
+	// Sunset is the moment the upper edge of the sun disappears below the horizon.
+	Sunset
+)
+
+var solarEvents = map[string]SolarEvent{
+	"sunrise": Sunrise,
+	"sunset":  Sunset,
+}
+
+var solarEventsInv = map[SolarEvent]string{
+	Sunrise: "sunrise",
+	Sunset:  "sunset",
+}
+
+// A SolarTime is a time of day given relative to a solar event, e.g. 'sunset+30m' or 'sunrise-1h'. In a TimeRange it
+// takes the place of a time on the clock, and is resolved separately for each day at the interval's Coordinates.
+type SolarTime struct {
+	Event  SolarEvent
+	Offset time.Duration
+}
+
+// Coordinates give the position on Earth used to work out when the sun rises and sets, in decimal degrees with north
+// and east positive.
+type Coordinates struct {
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+}
+
+var solarTimeRE = regexp.MustCompile(`^([a-z]+)\s*([+-].+)?$`)
+
+// UnmarshalYAML implements the Unmarshaller interface for Coordinates.
+func (c *Coordinates) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Coordinates
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	if c.Latitude < -90 || c.Latitude > 90 {
+		return fmt.Errorf("%v is not a valid latitude: out of range", c.Latitude)
+	}
+	if c.Longitude < -180 || c.Longitude > 180 {
+		return fmt.Errorf("%v is not a valid longitude: out of range", c.Longitude)
+	}
+	return nil
+}
+
+// parseSolarTime converts a string such as 'sunset+30m' into a SolarTime, returning false if it doesn't name a solar
+// event.
+func parseSolarTime(in string) (SolarTime, bool, error) {
+	m := solarTimeRE.FindStringSubmatch(strings.ToLower(strings.TrimSpace(in)))
+	if m == nil {
+		return SolarTime{}, false, nil
+	}
+	event, ok := solarEvents[m[1]]
+	if !ok {
+		return SolarTime{}, false, nil
+	}
+	st := SolarTime{Event: event}
+	if m[2] != "" {
+		offset, err := time.ParseDuration(strings.Replace(m[2], " ", "", -1))
+		if err != nil {
+			return SolarTime{}, true, fmt.Errorf("Couldn't parse offset of %s: %v", in, err)
+		}
+		if offset <= -24*time.Hour || offset >= 24*time.Hour {
+			return SolarTime{}, true, fmt.Errorf("%s is not a valid solar time: offset out of range", in)
+		}
+		st.Offset = offset.Truncate(time.Second)
+	}
+	return st, true, nil
+}
+
+func (st SolarTime) String() string {
+	if st.Offset == 0 {
+		return solarEventsInv[st.Event]
+	}
+	sign := "+"
+	if st.Offset < 0 {
+		sign = ""
+	}
+	// Drop the zero units that Duration.String adds, so that an hour is written as 1h rather than 1h0m0s.
+	offset := st.Offset.String()
+	if strings.HasSuffix(offset, "m0s") {
+		offset = offset[:len(offset)-2]
+	}
+	if strings.HasSuffix(offset, "h0m") {
+		offset = offset[:len(offset)-2]
+	}
+	return solarEventsInv[st.Event] + sign + offset
+}
+
+// hasSolarTimes returns true if any of the interval's time ranges start or end relative to the sun.
+func (tp TimeInterval) hasSolarTimes() bool {
+	for _, tr := range tp.Times {
+		if tr.StartSolar != nil || tr.EndSolar != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// onDay returns the interval with any solar times resolved to times on the clock for the calendar day of the given
+// time. Intervals without solar times are returned unchanged.
+func (tp TimeInterval) onDay(day time.Time) TimeInterval {
+	return tp.onDayInto(day, nil)
+}
+
+// solarTimesBuffer is how many time ranges of an interval with solar times can be resolved without allocating.
+const solarTimesBuffer = 4
+
+// onDayInto is onDay, resolving the times into buf if it has room for them, so that callers can avoid allocating.
+func (tp TimeInterval) onDayInto(day time.Time, buf []TimeRange) TimeInterval {
+	if !tp.hasSolarTimes() {
+		return tp
+	}
+	times := buf[:0]
+	if cap(buf) < len(tp.Times) {
+		times = make([]TimeRange, 0, len(tp.Times))
+	}
+	times = times[:len(tp.Times)]
+	for i, tr := range tp.Times {
+		start, end := tr.startSecond(), tr.endSecond()
+		if tr.StartSolar != nil {
+			start = tp.solarSecond(day, *tr.StartSolar)
+		}
+		if tr.EndSolar != nil {
+			end = tp.solarSecond(day, *tr.EndSolar)
+		}
+		if start == secondsPerDay {
+			// A range can't start at the end of the day, so it covers nothing.
+			start, end = 0, 0
+		}
+		if start > end && tr.StartSolar != nil && tr.EndSolar != nil &&
+			!(tr.StartSolar.Event == Sunset && tr.EndSolar.Event == Sunrise) {
+			// Only a range from sunset to sunrise runs overnight. Any other range between two solar times that ends
+			// before it starts, e.g. on a short winter day, covers nothing.
+			start, end = 0, 0
+		}
+		times[i] = timeRangeFromSeconds(start, end)
+	}
+	tp.Times = times
+	return tp
+}
+
+// solarSecond returns the second of the day on the wall clock at which the solar time occurs on the calendar day of
+// the given time, kept within the day. When the sun doesn't rise at all, sunrise and sunset are both taken to be at
+// solar noon, and when it doesn't set they are taken to be the start and end of the day. Without coordinates solar
+// times are taken to be at midnight.
+func (tp TimeInterval) solarSecond(day time.Time, st SolarTime) int {
+	if tp.Coordinates == nil {
+		return 0
+	}
+	rise, set, ok := sunriseSunset(day, *tp.Coordinates)
+	var second int
+	switch {
+	case ok && st.Event == Sunrise:
+		second = wallSecondOn(day, rise.Add(st.Offset))
+	case ok:
+		second = wallSecondOn(day, set.Add(st.Offset))
+	case rise.Equal(set):
+		second = wallSecondOn(day, rise.Add(st.Offset))
+	case st.Event == Sunrise:
+		second = int(st.Offset / time.Second)
+	default:
+		second = secondsPerDay + int(st.Offset/time.Second)
+	}
+	return clamp(second, 0, secondsPerDay)
+}
+
+// wallSecondOn returns the second of the day on the wall clock at which t occurs, measured from the start of the
+// calendar day of day. Times on earlier days give a negative number and times on later days one past the end of the day.
+func wallSecondOn(day, t time.Time) int {
+	t = t.In(day.Location())
+	y1, m1, d1 := day.Date()
+	y2, m2, d2 := t.Date()
+	switch {
+	case y2 < y1 || (y2 == y1 && (m2 < m1 || (m2 == m1 && d2 < d1))):
+		return -1
+	case y2 != y1 || m2 != m1 || d2 != d1:
+		return secondsPerDay + 1
+	}
+	return secondOfDay(t)
+}
+
+// sunriseSunset returns the times of sunrise and sunset on the calendar day of the given time at the given
+// coordinates, using the sunrise equation with a correction for atmospheric refraction. If the sun doesn't rise or set
+// that day it returns false, along with solar noon for both times if the sun stays down, or different times if it stays
+// up.
+func sunriseSunset(day time.Time, c Coordinates) (time.Time, time.Time, bool) {
+	const j2000 = 2451545.0
+	const unixEpoch = 2440587.5
+	rad := math.Pi / 180
+	noon := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, time.UTC)
+	n := float64(noon.Unix())/86400 + unixEpoch - j2000
+	meanNoon := n - c.Longitude/360
+	anomaly := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	center := 1.9148*math.Sin(anomaly*rad) + 0.0200*math.Sin(2*anomaly*rad) + 0.0003*math.Sin(3*anomaly*rad)
+	longitude := math.Mod(anomaly+center+180+102.9372, 360)
+	transit := j2000 + meanNoon + 0.0053*math.Sin(anomaly*rad) - 0.0069*math.Sin(2*longitude*rad)
+	declination := math.Asin(math.Sin(longitude*rad) * math.Sin(23.4397*rad))
+	cosHourAngle := (math.Sin(-0.833*rad) - math.Sin(c.Latitude*rad)*math.Sin(declination)) /
+		(math.Cos(c.Latitude*rad) * math.Cos(declination))
+	fromJulian := func(j float64) time.Time {
+		return time.Unix(int64(math.Round((j-unixEpoch)*86400)), 0)
+	}
+	switch {
+	case cosHourAngle > 1:
+		return fromJulian(transit), fromJulian(transit), false
+	case cosHourAngle < -1:
+		return fromJulian(transit - 0.5), fromJulian(transit + 0.5), false
+	}
+	hourAngle := math.Acos(cosHourAngle) / rad
+	return fromJulian(transit - hourAngle/360), fromJulian(transit + hourAngle/360), true
+}
+
+// errNoCoordinates is returned when an interval uses solar times without saying where the sun should be observed.
+var errNoCoordinates = errors.New("Coordinates must be provided to use sunrise or sunset times")
+
+// unmarshalSolar fills in a TimeRange where at least one end is a solar time. The other end may be a time on the clock.
+func (tr *TimeRange) unmarshalSolar(y yamlTimeRange, start SolarTime, isStartSolar bool, end SolarTime, isEndSolar bool) error {
+	*tr = TimeRange{}
+	if isStartSolar {
+		tr.StartSolar = &start
+	} else {
+		second, err := parseTime(y.StartTime)
+		if err != nil {
+			return err
+		}
+		if second >= secondsPerDay {
+			return errors.New("Start time out of range")
+		}
+		tr.StartMinute, tr.StartSecond = second/60, second%60
+	}
+	if isEndSolar {
+		tr.EndSolar = &end
+	} else {
+		second, err := parseTime(y.EndTime)
+		if err != nil {
+			return err
+		}
+		tr.EndMinute, tr.EndSecond = second/60, second%60
+	}
+	return nil
+}
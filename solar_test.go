@@ -0,0 +1,148 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var solarTestCases = []struct {
+	in          string
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// Daylight in London, sunrise 04:43 and sunset 21:21 BST at midsummer, 08:04 and 15:53 GMT at midwinter
+		in: `
+location: 'Europe/London'
+coordinates: {latitude: 51.5074, longitude: -0.1278}
+times: [{start_time: 'sunrise', end_time: 'sunset+30m'}]
+`,
+		contains: []string{"2020-06-21T03:50:00Z", "2020-06-21T20:45:00Z", "2020-12-21T08:10:00Z", "2020-12-21T16:20:00Z"},
+		excludes: []string{"2020-06-21T03:35:00Z", "2020-06-21T21:00:00Z", "2020-12-21T07:55:00Z", "2020-12-21T16:30:00Z"},
+	},
+	{
+		// From an hour before sunset until 23:00 on the clock
+		in: `
+location: 'Europe/London'
+coordinates: {latitude: 51.5074, longitude: -0.1278}
+times: [{start_time: 'sunset - 1h', end_time: '23:00'}]
+`,
+		contains: []string{"2020-12-21T15:00:00Z", "2020-12-21T22:59:00Z"},
+		excludes: []string{"2020-12-21T14:45:00Z", "2020-12-21T23:00:00Z"},
+	},
+	{
+		// Night runs from sunset into the following morning
+		in: `
+location: 'Europe/London'
+coordinates: {latitude: 51.5074, longitude: -0.1278}
+times: [{start_time: 'sunset', end_time: 'sunrise'}]
+except: [{weekdays: ['saturday', 'sunday']}]
+`,
+		contains: []string{"2020-12-21T17:00:00Z", "2020-12-22T07:30:00Z"},
+		excludes: []string{"2020-12-21T12:00:00Z", "2020-12-22T08:30:00Z", "2020-12-19T17:00:00Z"},
+	},
+	{
+		// The sun never rises at midwinter in Tromsø, and never sets at midsummer
+		in: `
+location: 'Europe/Oslo'
+coordinates: {latitude: 69.6492, longitude: 18.9553}
+times: [{start_time: 'sunrise', end_time: 'sunset'}]
+`,
+		contains: []string{"2020-06-21T00:00:00Z", "2020-06-21T11:00:00Z"},
+		excludes: []string{"2020-12-21T11:00:00Z", "2020-12-21T00:00:00Z"},
+	},
+	{
+		in:          "times: [{start_time: 'sunrise', end_time: 'sunset'}]",
+		expectError: true,
+	},
+	{
+		in:          "{coordinates: {latitude: 91, longitude: 0}, times: [{start_time: 'sunrise', end_time: 'sunset'}]}",
+		expectError: true,
+	},
+	{
+		in:          "{coordinates: {latitude: 0, longitude: 0}, times: [{start_time: 'sunrise+2x', end_time: 'sunset'}]}",
+		expectError: true,
+	},
+}
+
+func TestSolar(t *testing.T) {
+	for _, tc := range solarTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestSunriseSunset(t *testing.T) {
+	// Sunrise and sunset in Sydney on 1 January 2021 are at 05:48 and 20:10 AEDT.
+	sydney := Coordinates{Latitude: -33.8688, Longitude: 151.2093}
+	rise, set, ok := sunriseSunset(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), sydney)
+	if !ok {
+		t.Fatalf("Expected the sun to rise and set in Sydney")
+	}
+	for _, tc := range []struct {
+		got  time.Time
+		want string
+	}{{rise, "2020-12-31T18:48:00Z"}, {set, "2021-01-01T09:10:00Z"}} {
+		if diff := tc.got.Sub(mustParseTime(tc.want)); diff < -2*time.Minute || diff > 2*time.Minute {
+			t.Errorf("Expected %s within two minutes of %s", tc.got.UTC(), tc.want)
+		}
+	}
+}
+
+func TestSolarWindows(t *testing.T) {
+	london, _ := time.LoadLocation("Europe/London")
+	daylight := TimeInterval{
+		Location:    &Location{london},
+		Coordinates: &Coordinates{Latitude: 51.5074, Longitude: -0.1278},
+		Times:       []TimeRange{{StartSolar: &SolarTime{Event: Sunrise}, EndSolar: &SolarTime{Event: Sunset}}},
+	}
+	windows := daylight.Windows(mustParseTime("2020-12-21T00:00:00Z"), mustParseTime("2020-12-23T00:00:00Z"))
+	if len(windows) != 2 {
+		t.Fatalf("Expected two days of daylight, got %v", windows)
+	}
+	for _, w := range windows {
+		if w.Duration() < 7*time.Hour+40*time.Minute || w.Duration() > 8*time.Hour {
+			t.Errorf("Expected about 7 hours 50 minutes of daylight at midwinter, got %v", w)
+		}
+		if !daylight.ContainsTime(w.Start) || daylight.ContainsTime(w.End) {
+			t.Errorf("Window %v doesn't agree with ContainsTime", w)
+		}
+	}
+	if daylight.IsAlwaysActive() || daylight.IsEmpty() {
+		t.Errorf("Expected daylight to be neither always active nor empty")
+	}
+	if _, err := Intersect(daylight, businessHours); err != ErrNotRepresentable {
+		t.Errorf("Expected intersecting solar times to be unrepresentable, got %v", err)
+	}
+}
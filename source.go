@@ -0,0 +1,175 @@
+package gotime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultFetchInterval is how often a Loader fetches its document if no interval is given.
+const defaultFetchInterval = time.Minute
+
+// ErrNotModified is returned by a Source whose document hasn't changed since it was last fetched.
+var ErrNotModified = errors.New("Document not modified")
+
+// A Source fetches a YAML document of definitions from wherever it is kept, such as a web server or a bucket in an
+// object store. Sources that can tell the document hasn't changed since they last fetched it return ErrNotModified,
+// so that it isn't parsed again. Sources that implement fmt.Stringer are named by it in errors.
+type Source interface {
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// A Loader keeps a Registry in step with a document fetched from a Source, for schedules that are managed centrally.
+// The document is fetched periodically, and each new document is validated before its definitions are swapped into
+// the registry. A document that can't be fetched or is rejected leaves the registry with the last good definitions.
+type Loader struct {
+	Source   Source
+	Registry *Registry
+	// Validate, if set, is called with each new document's definitions before they are swapped in, and rejects them if
+	// it returns an error.
+	Validate func(Definitions) error
+	// OnError, if set, is called when Watch can't fetch the document or its definitions are rejected.
+	OnError func(error)
+	// Interval is how often the document is fetched, or every minute if zero.
+	Interval time.Duration
+	// CachePath, if set, is a file in which the last good document is kept, so that a service that restarts while the
+	// source is unavailable can start with the definitions it last had.
+	CachePath string
+}
+
+// Load fetches the document, swapping its definitions into the registry if it has changed and is valid.
+func (l *Loader) Load(ctx context.Context) error {
+	data, err := l.Source.Fetch(ctx)
+	if err == ErrNotModified {
+		return nil
+	}
+	name := "source"
+	if s, ok := l.Source.(fmt.Stringer); ok {
+		name = s.String()
+	}
+	if err != nil {
+		return fmt.Errorf("Couldn't fetch definitions from %s: %v", name, err)
+	}
+	if err := l.swap(data, name); err != nil {
+		return err
+	}
+	if l.CachePath != "" {
+		return writeFileAtomic(l.CachePath, data)
+	}
+	return nil
+}
+
+// Watch fetches the document when it is called and then every Interval until ctx is done, and then returns ctx.Err().
+// Errors are passed to OnError. If the first fetch fails, the definitions in the cache file are loaded instead, if
+// there is one.
+func (l *Loader) Watch(ctx context.Context) error {
+	interval := l.Interval
+	if interval <= 0 {
+		interval = defaultFetchInterval
+	}
+	if err := l.Load(ctx); err != nil {
+		l.report(err)
+		if l.CachePath != "" {
+			l.report(l.loadCache())
+		}
+	}
+	for {
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+		l.report(l.Load(ctx))
+	}
+}
+
+// loadCache swaps the definitions in the cache file into the registry.
+func (l *Loader) loadCache() error {
+	data, err := ioutil.ReadFile(l.CachePath)
+	if err != nil {
+		return err
+	}
+	return l.swap(data, l.CachePath)
+}
+
+// swap parses and validates a document fetched from source and swaps its definitions into the registry.
+func (l *Loader) swap(data []byte, source string) error {
+	d, err := parseDefinitions(data, source)
+	if err != nil {
+		return err
+	}
+	if err := validateDefinitions(d, l.Validate, source); err != nil {
+		return err
+	}
+	l.Registry.Swap(d)
+	return nil
+}
+
+func (l *Loader) report(err error) {
+	if err != nil && l.OnError != nil {
+		l.OnError(err)
+	}
+}
+
+// A conditionalGet makes GET requests that are conditional on the ETag and Last-Modified headers of the last
+// successful response, for sources served over HTTP.
+type conditionalGet struct {
+	etag         string
+	lastModified string
+}
+
+// setConditions makes req conditional on the document having changed since the last response.
+func (c *conditionalGet) setConditions(req *http.Request) {
+	if c.etag != "" {
+		req.Header.Set("If-None-Match", c.etag)
+	}
+	if c.lastModified != "" {
+		req.Header.Set("If-Modified-Since", c.lastModified)
+	}
+}
+
+// do sends req with client, or http.DefaultClient if it's nil, and returns the body of the response. It returns
+// ErrNotModified if the server says the document is unchanged.
+func (c *conditionalGet) do(client *http.Client, req *http.Request) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.etag, c.lastModified = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	return data, nil
+}
+
+// writeFileAtomic writes data to a file by renaming a temporary file over it, so that readers never see it half
+// written.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,92 @@
+package gotime
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mu sync.Mutex
+	doc, etag, status, fetches := "maintenance:\n  weekdays: ['sunday']\n", `"v1"`, http.StatusOK, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(doc))
+	}))
+	defer server.Close()
+	serve := func(d, e string, s int) {
+		mu.Lock()
+		defer mu.Unlock()
+		doc, etag, status = d, e, s
+	}
+
+	registry := NewRegistry(nil)
+	var changes int
+	registry.Subscribe(func(RegistryChange) { changes++ })
+	cache := filepath.Join(dir, "schedules.yaml")
+	l := &Loader{Source: &HTTPSource{URL: server.URL}, Registry: registry, CachePath: cache}
+	sunday := time.Date(2024, 7, 7, 12, 0, 0, 0, time.UTC)
+	m := registry.Matcher("maintenance")
+	ctx := context.Background()
+
+	if err := l.Load(ctx); err != nil || !m.ContainsTime(sunday) {
+		t.Fatalf("Expected the first fetch to load the document, got %v", err)
+	}
+	if err := l.Load(ctx); err != nil || changes != 1 {
+		t.Errorf("Expected an unchanged document to leave the registry alone, got %v and %d changes", err, changes)
+	}
+	serve("maintenance: [\n", `"v2"`, http.StatusOK)
+	if err := l.Load(ctx); err == nil || !m.ContainsTime(sunday) {
+		t.Errorf("Expected an invalid document to keep the last good definitions, got %v", err)
+	}
+	serve("", "", http.StatusInternalServerError)
+	if err := l.Load(ctx); err == nil || !m.ContainsTime(sunday) {
+		t.Errorf("Expected a server error to keep the last good definitions, got %v", err)
+	}
+	if cached, err := LoadDefinitions(cache); err != nil || len(cached) != 1 {
+		t.Errorf("Expected the last good document to be cached, got %v", err)
+	}
+
+	// A new process falls back to the cache while the server is failing.
+	registry = NewRegistry(nil)
+	var errs []error
+	l = &Loader{Source: &HTTPSource{URL: server.URL}, Registry: registry, CachePath: cache, OnError: func(err error) {
+		errs = append(errs, err)
+	}}
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := l.Watch(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the watch to end with the context, got %v", err)
+	}
+	if !registry.Matcher("maintenance").ContainsTime(sunday) || len(errs) != 1 {
+		t.Errorf("Expected the cached definitions after one error, got %v", errs)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches != 5 {
+		t.Errorf("Expected 5 fetches, got %d", fetches)
+	}
+}
@@ -0,0 +1,42 @@
+package gotime
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Value implements the driver.Valuer interface for TimeInterval, storing the interval in a database column as its
+// YAML form, which keeps every field.
+func (tp TimeInterval) Value() (driver.Value, error) {
+	out, err := yaml.Marshal(tp)
+	if err != nil {
+		return nil, err
+	}
+	return string(out), nil
+}
+
+// Scan implements the sql.Scanner interface for TimeInterval, reading an interval stored by Value. Since JSON is also
+// YAML, intervals stored as JSON objects with the same field names, e.g. in a json column, can be read as well. NULL
+// can't be scanned into a TimeInterval, as the empty interval it would leave behind matches every time.
+func (tp *TimeInterval) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case nil:
+		return errors.New("Unable to scan NULL into a TimeInterval")
+	default:
+		return fmt.Errorf("Unable to scan %T into a TimeInterval", src)
+	}
+	var scanned TimeInterval
+	if err := yaml.Unmarshal(data, &scanned); err != nil {
+		return err
+	}
+	*tp = scanned
+	return nil
+}
@@ -0,0 +1,66 @@
+package gotime
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+// Both interfaces must be satisfied for intervals to be stored in and read from database columns.
+var (
+	_ driver.Valuer = TimeInterval{}
+	_ sql.Scanner   = &TimeInterval{}
+)
+
+func TestSQL(t *testing.T) {
+	ti := TimeInterval{
+		Name:     "business hours",
+		Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+		Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		Location: mustLoadLocation("Australia/Melbourne"),
+		Except:   []TimeInterval{{Months: []MonthRange{{InclusiveRange{Begin: 12, End: 12}}}}},
+	}
+	v, err := ti.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, src := range []interface{}{v, []byte(v.(string))} {
+		var scanned TimeInterval
+		if err := scanned.Scan(src); err != nil {
+			t.Errorf("Received unexpected error: %v when scanning %v", err, src)
+			continue
+		}
+		if !reflect.DeepEqual(ti, scanned) {
+			t.Errorf("Scanning %v: want %+v, got %+v", src, ti, scanned)
+		}
+	}
+
+	var fromJSON TimeInterval
+	if err := fromJSON.Scan(`{"weekdays": ["monday:friday"], "times": [{"start_time": "09:00", "end_time": "17:00"}]}`); err != nil {
+		t.Errorf("Received unexpected error: %v when scanning JSON", err)
+	} else if !reflect.DeepEqual(fromJSON.Weekdays, ti.Weekdays) || !reflect.DeepEqual(fromJSON.Times, ti.Times) {
+		t.Errorf("Scanning JSON: got %+v", fromJSON)
+	}
+
+	for _, src := range []interface{}{nil, 42, "weekdays: ['smarch']"} {
+		scanned := ti
+		if err := scanned.Scan(src); err == nil {
+			t.Errorf("Expected error when scanning %v but didn't receive one", src)
+		} else if !reflect.DeepEqual(ti, scanned) {
+			t.Errorf("Expected a failed scan of %v to leave the interval unchanged", src)
+		}
+	}
+}
+
+func TestSQLNeverActive(t *testing.T) {
+	checkNeverActive(t, func(ti TimeInterval) ([]byte, error) {
+		v, err := ti.Value()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(v.(string)), nil
+	}, func(ti *TimeInterval, data []byte) error {
+		return ti.Scan(data)
+	})
+}
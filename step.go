@@ -0,0 +1,73 @@
+package gotime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var stepRE = regexp.MustCompile(`^(.+?)\s*/\s*([1-9][0-9]*)$`)
+
+// stepMembers gives the function used to read the bounds of a stepped range in each field that allows steps.
+var stepMembers = map[string]func(string) (int, error){
+	"weekdays":      (&WeekdayRange{}).memberFromString,
+	"days_of_month": (&DayOfMonthRange{}).memberFromString,
+	"weeks":         (&WeekRange{}).memberFromString,
+	"months":        (&MonthRange{}).memberFromString,
+	"quarters":      (&QuarterRange{}).memberFromString,
+	"years":         (&YearRange{}).memberFromString,
+}
+
+// A steppedRange holds the bounds of a stepped range, read with the member names of its field.
+type steppedRange struct {
+	InclusiveRange
+	member func(string) (int, error)
+}
+
+func (r *steppedRange) memberFromString(in string) (int, error) {
+	return r.member(in)
+}
+
+// expandSteps replaces any stepped ranges in the list of values given for a field, such as '1:31/2', with every value
+// they step through, returning false if there were none. The values are then checked by the field's own range type.
+func expandSteps(field string, list []interface{}) ([]interface{}, bool, error) {
+	member, ok := stepMembers[field]
+	if !ok {
+		return list, false, nil
+	}
+	out := make([]interface{}, 0, len(list))
+	found := false
+	for _, v := range list {
+		str, ok := v.(string)
+		if !ok {
+			out = append(out, v)
+			continue
+		}
+		m := stepRE.FindStringSubmatch(strings.TrimSpace(str))
+		if m == nil {
+			out = append(out, v)
+			continue
+		}
+		step, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, false, err
+		}
+		r := steppedRange{member: member}
+		if err := stringableRangeFromString(strings.TrimSpace(m[1]), &r); err != nil {
+			return nil, false, err
+		}
+		begin, end := r.Begin, r.End
+		if (begin < 0) != (end < 0) {
+			return nil, false, fmt.Errorf("Couldn't parse step %s, a stepped range cannot mix positive and negative values", str)
+		}
+		if begin > end {
+			return nil, false, fmt.Errorf("Couldn't parse step %s, start cannot be after end", str)
+		}
+		for n := begin; n <= end; n += step {
+			out = append(out, strconv.Itoa(n))
+		}
+		found = true
+	}
+	return out, found, nil
+}
@@ -0,0 +1,87 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var stepTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	expectError bool
+}{
+	{
+		in: "days_of_month: ['1:9/2', '20']",
+		interval: TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{
+				{InclusiveRange{Begin: 1, End: 1}},
+				{InclusiveRange{Begin: 3, End: 3}},
+				{InclusiveRange{Begin: 5, End: 5}},
+				{InclusiveRange{Begin: 7, End: 7}},
+				{InclusiveRange{Begin: 9, End: 9}},
+				{InclusiveRange{Begin: 20, End: 20}},
+			},
+		},
+	},
+	{
+		in: "years: ['2020:2026/3']",
+		interval: TimeInterval{
+			Years: []YearRange{
+				{InclusiveRange{Begin: 2020, End: 2020}},
+				{InclusiveRange{Begin: 2023, End: 2023}},
+				{InclusiveRange{Begin: 2026, End: 2026}},
+			},
+		},
+	},
+	{
+		in: "{weekdays: ['monday:friday/2'], months: ['jan:dec / 6'], days_of_month: ['-5:-1/2']}",
+		interval: TimeInterval{
+			Weekdays: []WeekdayRange{
+				{InclusiveRange{Begin: 1, End: 1}},
+				{InclusiveRange{Begin: 3, End: 3}},
+				{InclusiveRange{Begin: 5, End: 5}},
+			},
+			Months: []MonthRange{
+				{InclusiveRange{Begin: 1, End: 1}},
+				{InclusiveRange{Begin: 7, End: 7}},
+			},
+			DaysOfMonth: []DayOfMonthRange{
+				{InclusiveRange{Begin: -5, End: -5}},
+				{InclusiveRange{Begin: -3, End: -3}},
+				{InclusiveRange{Begin: -1, End: -1}},
+			},
+		},
+	},
+	{
+		in:          "days_of_month: ['1:40/2']",
+		expectError: true,
+	},
+	{
+		in:          "days_of_month: ['1:-1/2']",
+		expectError: true,
+	},
+	{
+		in:          "years: ['2040:2020/2']",
+		expectError: true,
+	},
+	{
+		in:          "years: ['2020:2040/0']",
+		expectError: true,
+	},
+}
+
+func TestSteps(t *testing.T) {
+	for _, tc := range stepTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+		} else if err == nil && !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	systemdWeekday = cronField{name: "day of the week", min: 0, max: 6, names: daysOfWeek}
+	systemdYear    = cronField{name: "year", min: 1970, max: 2199}
+	systemdSecond  = cronField{name: "second", min: 0, max: 59}
+)
+
+// systemdShorthands holds the calendar events that systemd's shorthands such as daily stand for.
+var systemdShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// FromOnCalendar converts a systemd calendar event, as given to OnCalendar= in a timer unit, into a TimeInterval that
+// is active during each second at which the timer would elapse. For example, 'Mon..Fri *-*-* 09:00:00' is active for
+// the first second of 9AM on weekdays, while 'Mon..Fri *-*-* 09..16:*:*' covers business hours.
+//
+// An event has an optional list of weekdays, a date as year-month-day or month-day and a time as hour:minute:second
+// or hour:minute, each part of which may be '*', a list, a range written with '..' or repeat with '/'. A date may
+// count back from the end of the month with '~', so '*-*~01' is the last day of the month. A time zone may follow,
+// which becomes the interval's location, and systemd's shorthands such as daily and weekly are also understood. Times
+// default to midnight when left out, and seconds to 0.
+func FromOnCalendar(expr string) (TimeInterval, error) {
+	tokens := strings.Fields(expr)
+	if len(tokens) == 0 {
+		return TimeInterval{}, fmt.Errorf("Couldn't parse calendar event %s, invalid format", expr)
+	}
+	if shorthand, ok := systemdShorthands[strings.ToLower(tokens[0])]; ok {
+		tokens = append(strings.Fields(shorthand), tokens[1:]...)
+	}
+	var ti TimeInterval
+	weekdays, date, clock := "", "*-*-*", "00:00:00"
+	i := 0
+	if i < len(tokens) && isSystemdWeekdays(tokens[i]) {
+		weekdays = strings.ToLower(tokens[i])
+		i++
+	}
+	if i < len(tokens) && !strings.Contains(tokens[i], ":") && strings.ContainsAny(tokens[i], "-~") {
+		date = strings.ToLower(tokens[i])
+		i++
+	}
+	if i < len(tokens) && strings.Contains(tokens[i], ":") {
+		clock = tokens[i]
+		i++
+	}
+	if i < len(tokens) {
+		loc, err := time.LoadLocation(tokens[i])
+		if err != nil {
+			return TimeInterval{}, fmt.Errorf("Couldn't parse calendar event %s, %s is not a valid time zone", expr, tokens[i])
+		}
+		ti.Location = &Location{loc}
+		i++
+	}
+	if i < len(tokens) {
+		return TimeInterval{}, fmt.Errorf("Couldn't parse calendar event %s, invalid format", expr)
+	}
+	if weekdays != "" {
+		set, err := systemdWeekday.parse(systemdRanges(weekdays))
+		if err != nil {
+			return TimeInterval{}, err
+		}
+		for _, r := range cronRuns(set, systemdWeekday.min) {
+			ti.Weekdays = append(ti.Weekdays, WeekdayRange{r})
+		}
+	}
+	if err := ti.setOnCalendarDate(date); err != nil {
+		return TimeInterval{}, err
+	}
+	components := strings.Split(clock, ":")
+	if len(components) == 2 {
+		components = append(components, "00")
+	}
+	if len(components) != 3 {
+		return TimeInterval{}, fmt.Errorf("Couldn't parse calendar event time %s, invalid format", clock)
+	}
+	sets, err := parseCronFields([]string{systemdRanges(components[0]), systemdRanges(components[1]),
+		systemdRanges(components[2])}, []cronField{cronHour, cronMinute, systemdSecond})
+	if err != nil {
+		return TimeInterval{}, err
+	}
+	ti.Times = cronTimes(sets[0], sets[1], sets[2])
+	return ti, nil
+}
+
+// setOnCalendarDate sets the years, months and days of the month matched by the date of a calendar event.
+func (tp *TimeInterval) setOnCalendarDate(date string) error {
+	fromEnd := strings.Contains(date, "~")
+	components := strings.Split(strings.Replace(date, "~", "-", 1), "-")
+	if len(components) == 2 {
+		components = append([]string{"*"}, components...)
+	}
+	if len(components) != 3 {
+		return fmt.Errorf("Couldn't parse calendar event date %s, invalid format", date)
+	}
+	sets, err := parseCronFields([]string{systemdRanges(components[0]), systemdRanges(components[1])},
+		[]cronField{systemdYear, cronMonth})
+	if err != nil {
+		return err
+	}
+	for _, r := range cronRuns(sets[0], systemdYear.min) {
+		tp.Years = append(tp.Years, YearRange{r})
+	}
+	for _, r := range cronRuns(sets[1], cronMonth.min) {
+		tp.Months = append(tp.Months, MonthRange{r})
+	}
+	if fromEnd {
+		return tp.setDaysFromEnd(systemdRanges(components[2]))
+	}
+	days, err := cronDayOfMonth.parse(systemdRanges(components[2]))
+	if err != nil {
+		return err
+	}
+	for _, r := range cronRuns(days, cronDayOfMonth.min) {
+		tp.DaysOfMonth = append(tp.DaysOfMonth, DayOfMonthRange{r})
+	}
+	return nil
+}
+
+// setDaysFromEnd sets the days of the month matched by the part of a calendar event's date after a ~, which counts
+// back from the last day of the month as day 1. Repetition runs towards the end of the month, so '07/1' is each of the
+// last seven days.
+func (tp *TimeInterval) setDaysFromEnd(spec string) error {
+	days := make([]bool, cronDayOfMonth.max)
+	for _, part := range strings.Split(spec, ",") {
+		i := strings.Index(part, "/")
+		if i < 0 {
+			set, err := cronDayOfMonth.parse(part)
+			if err != nil {
+				return err
+			}
+			for day, ok := range set {
+				days[day] = days[day] || ok
+			}
+			continue
+		}
+		first, err := cronDayOfMonth.value(part[:i])
+		if err != nil {
+			return err
+		}
+		step, err := strconv.Atoi(part[i+1:])
+		if err != nil || step < 1 {
+			return fmt.Errorf("Couldn't parse calendar event day %s, invalid step", part)
+		}
+		for day := first; day >= 1; day -= step {
+			days[day-1] = true
+		}
+	}
+	ranges := cronRuns(days, cronDayOfMonth.min)
+	for i := len(ranges) - 1; i >= 0; i-- {
+		r := InclusiveRange{Begin: -ranges[i].End, End: -ranges[i].Begin}
+		tp.DaysOfMonth = append(tp.DaysOfMonth, DayOfMonthRange{r})
+	}
+	return nil
+}
+
+// systemdRanges rewrites the ranges in a part of a calendar event, written as 'a..b', in the form read by cronField.
+func systemdRanges(spec string) string {
+	return strings.Replace(spec, "..", "-", -1)
+}
+
+// isSystemdWeekdays returns true if a token of a calendar event is a list of weekdays, such as 'Mon..Fri' or 'Sat,Sun'.
+func isSystemdWeekdays(token string) bool {
+	for _, part := range strings.Split(strings.ToLower(token), ",") {
+		for _, day := range strings.Split(part, "..") {
+			if _, ok := daysOfWeek[day]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// systemdWeekdayNames holds the names systemd gives the days of the week, from Sunday.
+var systemdWeekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// A systemdDay is the days part of a calendar event: the weekdays it matches and its date.
+type systemdDay struct {
+	weekdays []bool
+	date     string
+	// anyDate is true if the date matches every day, so that times past midnight can move to the following weekday.
+	anyDate bool
+}
+
+// OnCalendar converts the interval into systemd calendar events, as given to OnCalendar= in a timer unit, that between
+// them elapse during each second the interval is active. It is the reverse of FromOnCalendar, so an interval covering
+// business hours gives 'Mon..Fri *-*-* 09..16:*:*'. A timer unit may have several OnCalendar= settings, and more than
+// one event is returned when the interval can't be written as one, such as when a time range doesn't start on the
+// hour. Times, weekdays, days of the month, weekdays of the month, months, quarters, years and the location can be
+// converted, and other fields return an error.
+func (tp TimeInterval) OnCalendar() ([]string, error) {
+	for _, f := range []struct {
+		key   string
+		unset bool
+	}{
+		{"mode", tp.Mode == ModeAllow},
+		{"weeks", tp.Weeks == nil},
+		{"relative days", tp.Relative == nil},
+		{"dates", tp.Dates == nil},
+		{"absolute times", tp.Absolute == nil},
+		{"cycle", tp.Cycle == nil},
+		{"calendar", tp.Calendar == nil},
+		{"holidays", tp.Holidays == nil},
+		{"expression", tp.Expression == nil},
+		{"coordinates", tp.Coordinates == nil},
+		{"dst", tp.DST == DSTPolicy{}},
+		{"except", tp.Except == nil},
+	} {
+		if !f.unset {
+			return nil, fmt.Errorf("Unable to express the %s of an interval as a calendar event", f.key)
+		}
+	}
+	if tp.hasSolarTimes() {
+		return nil, errors.New("Unable to express solar times as a calendar event")
+	}
+	if tp.Years != nil && tp.fiscalStartMonth() != time.January {
+		return nil, errors.New("Unable to express fiscal years as a calendar event")
+	}
+	if tp.IsEmpty() {
+		return nil, errors.New("Unable to express an interval that is never active as a calendar event")
+	}
+	days, err := tp.systemdDays()
+	if err != nil {
+		return nil, err
+	}
+	today, tomorrow := []string{"*:*:*"}, []string(nil)
+	if tp.Times != nil {
+		today, tomorrow = nil, nil
+		for _, tr := range tp.Times {
+			start, end := tr.startSecond(), tr.endSecond()
+			if tr.isOvernight() {
+				tomorrow = append(tomorrow, systemdClocks(0, end)...)
+				end = secondsPerDay
+			}
+			today = append(today, systemdClocks(start, end)...)
+		}
+		today, tomorrow = groupSystemdClocks(today), groupSystemdClocks(tomorrow)
+	}
+	zone := ""
+	if tp.Location != nil {
+		zone = " " + tp.Location.String()
+	}
+	var events []string
+	add := func(weekdays []bool, date string, clocks []string) {
+		active := false
+		for _, ok := range weekdays {
+			active = active || ok
+		}
+		if !active {
+			return
+		}
+		prefix := ""
+		if runs := cronRuns(weekdays, 0); runs != nil {
+			names := make([]string, len(runs))
+			for i, r := range runs {
+				names[i] = systemdWeekdayNames[r.Begin]
+				if r.End != r.Begin {
+					names[i] += ".." + systemdWeekdayNames[r.End]
+				}
+			}
+			prefix = strings.Join(names, ",") + " "
+		}
+		for _, clock := range clocks {
+			events = append(events, prefix+date+" "+clock+zone)
+		}
+	}
+	for _, day := range days {
+		add(day.weekdays, day.date, today)
+		if tomorrow == nil {
+			continue
+		}
+		if !day.anyDate {
+			return nil, errors.New("Unable to express a time range past midnight on particular dates as a calendar event")
+		}
+		// The part of an overnight range after midnight falls on the day after each of the interval's weekdays.
+		next := make([]bool, 7)
+		for wd, ok := range day.weekdays {
+			next[(wd+1)%7] = ok
+		}
+		add(next, day.date, tomorrow)
+	}
+	return events, nil
+}
+
+// systemdDays returns the days parts of the calendar events matching the days the interval is active on.
+func (tp TimeInterval) systemdDays() ([]systemdDay, error) {
+	weekdays := make([]bool, 7)
+	for wd := range weekdays {
+		weekdays[wd] = tp.Weekdays == nil
+	}
+	for _, r := range tp.Weekdays {
+		for wd := r.Begin; wd <= r.End; wd++ {
+			weekdays[wd] = true
+		}
+	}
+	months := make([]bool, 12)
+	for i := range months {
+		month := i + 1
+		months[i] = tp.Months == nil
+		for _, r := range tp.Months {
+			months[i] = months[i] || (month >= r.Begin && month <= r.End)
+		}
+		if tp.Quarters != nil {
+			quarter := (month-int(tp.fiscalStartMonth())+12)%12/3 + 1
+			in := false
+			for _, r := range tp.Quarters {
+				in = in || (quarter >= r.Begin && quarter <= r.End)
+			}
+			months[i] = months[i] && in
+		}
+	}
+	years := "*"
+	if tp.Years != nil {
+		parts := make([]string, len(tp.Years))
+		for i, r := range tp.Years {
+			parts[i] = strconv.Itoa(r.Begin)
+			if r.End != r.Begin {
+				parts[i] += ".." + strconv.Itoa(r.End)
+			}
+		}
+		years = strings.Join(parts, ",")
+	}
+	prefix := years + "-" + systemdList(months, cronMonth.min)
+	if tp.DaysOfMonth == nil && tp.WeekdaysOfMonth == nil {
+		return []systemdDay{{weekdays: weekdays, date: prefix + "-*", anyDate: prefix == "*-*"}}, nil
+	}
+	var days []systemdDay
+	fromStart, fromEnd := make([]bool, cronDayOfMonth.max), make([]bool, cronDayOfMonth.max)
+	var hasFromStart, hasFromEnd bool
+	for _, r := range tp.DaysOfMonth {
+		if (r.Begin < 0) != (r.End < 0) {
+			return nil, fmt.Errorf("Unable to express the days of the month %d to %d as a calendar event", r.Begin, r.End)
+		}
+		for day := r.Begin; day <= r.End; day++ {
+			if day > 0 {
+				fromStart[day-1], hasFromStart = true, true
+			} else {
+				fromEnd[-day-1], hasFromEnd = true, true
+			}
+		}
+	}
+	if hasFromStart {
+		days = append(days, systemdDay{weekdays: weekdays, date: prefix + "-" + systemdList(fromStart, 1)})
+	}
+	if hasFromEnd {
+		days = append(days, systemdDay{weekdays: weekdays, date: prefix + "~" + systemdDaysFromEnd(fromEnd)})
+	}
+	for _, w := range tp.WeekdaysOfMonth {
+		if w.AnyWeekday {
+			return nil, errors.New("Unable to express the nth weekday of the month as a calendar event")
+		}
+		// The nth occurrence of a weekday falls in the nth week of the month, counting from whichever end N does.
+		n := w.N
+		if n < 0 {
+			n = -n
+		}
+		week := make([]bool, cronDayOfMonth.max)
+		for day := 7*(n-1) + 1; day <= 7*n && day <= cronDayOfMonth.max; day++ {
+			week[day-1] = true
+		}
+		only := make([]bool, 7)
+		only[w.Weekday] = weekdays[w.Weekday]
+		date := prefix + "-" + systemdList(week, 1)
+		if w.N < 0 {
+			date = prefix + "~" + systemdDaysFromEnd(week)
+		}
+		days = append(days, systemdDay{weekdays: only, date: date})
+	}
+	return days, nil
+}
+
+// systemdClocks returns the times of calendar events that between them match each second from start up to end.
+func systemdClocks(start, end int) []string {
+	var clocks []string
+	for start < end {
+		hour, minute, second := start/3600, start/60%60, start%60
+		switch {
+		case second != 0 || end-start < 60:
+			// The range starts or ends part way through this minute.
+			last := (start/60+1)*60 - 1
+			if end-1 < last {
+				last = end - 1
+			}
+			clocks = append(clocks, fmt.Sprintf("%02d:%02d:%s", hour, minute, systemdRange(second, last%60, 59)))
+			start = last + 1
+		case minute != 0 || end-start < 3600:
+			// The range starts or ends part way through this hour, but covers whole minutes.
+			last := (start/3600+1)*60 - 1
+			if end/60-1 < last {
+				last = end/60 - 1
+			}
+			clocks = append(clocks, fmt.Sprintf("%02d:%s:*", hour, systemdRange(minute, last%60, 59)))
+			start = (last + 1) * 60
+		default:
+			last := end/3600 - 1
+			clocks = append(clocks, systemdRange(hour, last, 23)+":*:*")
+			start = (last + 1) * 3600
+		}
+	}
+	return clocks
+}
+
+// groupSystemdClocks joins the hours of calendar event times that match the same minutes and seconds, so that
+// '09:*:*' and '13:*:*' become '09,13:*:*'.
+func groupSystemdClocks(clocks []string) []string {
+	var grouped []string
+	index := map[string]int{}
+	for _, clock := range clocks {
+		i := strings.Index(clock, ":")
+		hours, rest := clock[:i], clock[i:]
+		if j, ok := index[rest]; ok {
+			grouped[j] = grouped[j][:strings.Index(grouped[j], ":")] + "," + hours + rest
+			continue
+		}
+		index[rest] = len(grouped)
+		grouped = append(grouped, clock)
+	}
+	return grouped
+}
+
+// systemdRange writes the values from begin to end of a part of a calendar event, which is '*' if they cover every
+// value up to max.
+func systemdRange(begin, end, max int) string {
+	switch {
+	case begin == 0 && end == max:
+		return "*"
+	case begin == end:
+		return fmt.Sprintf("%02d", begin)
+	}
+	return fmt.Sprintf("%02d..%02d", begin, end)
+}
+
+// systemdList writes the values in set, where the first value is offset, as a part of a calendar event.
+func systemdList(set []bool, offset int) string {
+	runs := cronRuns(set, offset)
+	if runs == nil {
+		return "*"
+	}
+	parts := make([]string, len(runs))
+	for i, r := range runs {
+		parts[i] = systemdRange(r.Begin, r.End, -1)
+	}
+	return strings.Join(parts, ",")
+}
+
+// systemdDaysFromEnd writes the days of the month in set, counting back from the last day as day 1, as the part of a
+// calendar event's date after a ~. Runs that reach the last day are written as repetitions, such as '07/1'.
+func systemdDaysFromEnd(set []bool) string {
+	var parts []string
+	for _, r := range cronRuns(set, 1) {
+		if r.Begin == 1 {
+			parts = append(parts, fmt.Sprintf("%02d/1", r.End))
+			continue
+		}
+		for day := r.Begin; day <= r.End; day++ {
+			parts = append(parts, fmt.Sprintf("%02d", day))
+		}
+	}
+	return strings.Join(parts, ",")
+}
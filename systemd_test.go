@@ -0,0 +1,255 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var fromOnCalendarTestCases = []struct {
+	expr        string
+	interval    *TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		expr: "Mon..Fri *-*-* 09:00:00",
+		interval: &TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 540, EndSecond: 1}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+		contains: []string{"2020-07-10T09:00:00Z"},
+		excludes: []string{"2020-07-10T09:00:01Z", "2020-07-11T09:00:00Z"},
+	},
+	{
+		expr: "Mon..Fri 09..16:*:*",
+		interval: &TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+	},
+	{
+		expr: "Tue 2024..2025-12-24,31 00:0/20 Europe/London",
+		interval: &TimeInterval{
+			Times: []TimeRange{
+				{StartMinute: 0, EndMinute: 0, EndSecond: 1},
+				{StartMinute: 20, EndMinute: 20, EndSecond: 1},
+				{StartMinute: 40, EndMinute: 40, EndSecond: 1},
+			},
+			Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 2, End: 2}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 24, End: 24}}, {InclusiveRange{Begin: 31, End: 31}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 12, End: 12}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2024, End: 2025}}},
+			Location:    mustLoadLocation("Europe/London"),
+		},
+		// 24 December 2024 is a Tuesday and 24 December 2025 a Wednesday.
+		contains: []string{"2024-12-24T00:20:00Z", "2024-12-31T00:40:00Z"},
+		excludes: []string{"2025-12-24T00:20:00Z", "2024-12-17T00:20:00Z", "2024-12-24T01:20:00Z"},
+	},
+	{
+		expr: "daily",
+		interval: &TimeInterval{
+			Times: []TimeRange{{StartMinute: 0, EndMinute: 0, EndSecond: 1}},
+		},
+	},
+	{
+		expr: "*-02~03",
+		interval: &TimeInterval{
+			Times:       []TimeRange{{StartMinute: 0, EndMinute: 0, EndSecond: 1}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 2, End: 2}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -3, End: -3}}},
+		},
+		contains: []string{"2024-02-27T00:00:00Z", "2023-02-26T00:00:00Z"},
+	},
+	{
+		// The last Monday in May.
+		expr: "Mon *-05~07/1 12:00",
+		interval: &TimeInterval{
+			Times:       []TimeRange{{StartMinute: 720, EndMinute: 720, EndSecond: 1}},
+			Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}},
+			Months:      []MonthRange{{InclusiveRange{Begin: 5, End: 5}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -7, End: -1}}},
+		},
+		contains: []string{"2020-05-25T12:00:00Z"},
+		excludes: []string{"2020-05-18T12:00:00Z"},
+	},
+	{
+		expr:        "",
+		expectError: true,
+	},
+	{
+		expr:        "Mon..Fri 25:00",
+		expectError: true,
+	},
+	{
+		expr:        "*-13-01",
+		expectError: true,
+	},
+	{
+		expr:        "Mon *-*-* 09:00 Not/AZone",
+		expectError: true,
+	},
+	{
+		expr:        "Fri..Mon",
+		expectError: true,
+	},
+}
+
+func TestFromOnCalendar(t *testing.T) {
+	for _, tc := range fromOnCalendarTestCases {
+		ti, err := FromOnCalendar(tc.expr)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected an error converting %s, got %v", tc.expr, ti)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error converting %s: %v", tc.expr, err)
+			continue
+		}
+		if tc.interval != nil && !reflect.DeepEqual(ti, *tc.interval) {
+			t.Errorf("Converting %s gave %v, expected %v", tc.expr, ti, *tc.interval)
+		}
+		for _, c := range tc.contains {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if !ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to contain %s", tc.expr, c)
+			}
+		}
+		for _, c := range tc.excludes {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if ti.ContainsTime(tm) {
+				t.Errorf("Expected %s to exclude %s", tc.expr, c)
+			}
+		}
+	}
+}
+
+var onCalendarTestCases = []struct {
+	interval    TimeInterval
+	events      []string
+	expectError bool
+}{
+	{
+		interval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+		events: []string{"Mon..Fri *-*-* 09..16:*:*"},
+	},
+	{
+		interval: TimeInterval{},
+		events:   []string{"*-*-* *:*:*"},
+	},
+	{
+		interval: TimeInterval{
+			Times: []TimeRange{{StartMinute: 570, EndMinute: 735, EndSecond: 30}, {StartMinute: 780, EndMinute: 1020}},
+		},
+		events: []string{"*-*-* 09:30..59:*", "*-*-* 10..11,13..16:*:*", "*-*-* 12:00..14:*", "*-*-* 12:15:00..29"},
+	},
+	{
+		interval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}},
+			Location: mustLoadLocation("Europe/London"),
+		},
+		events: []string{"Fri..Sat *-*-* 22..23:*:* Europe/London", "Sun,Sat *-*-* 00..05:*:* Europe/London"},
+	},
+	{
+		interval: TimeInterval{
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 1}}, {InclusiveRange{Begin: -7, End: -1}}},
+			Quarters:    []QuarterRange{{InclusiveRange{Begin: 2, End: 2}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2024, End: 2025}}},
+		},
+		events: []string{"2024..2025-04..06-01 *:*:*", "2024..2025-04..06~07/1 *:*:*"},
+	},
+	{
+		interval: TimeInterval{
+			Times: []TimeRange{{StartMinute: 720, EndMinute: 780}},
+			WeekdaysOfMonth: []WeekdayOfMonth{
+				{N: 2, Weekday: time.Tuesday},
+				{N: lastOccurrence, Weekday: time.Friday},
+				{N: 1, Weekday: time.Sunday},
+			},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+		},
+		events: []string{"Tue *-*-08..14 12:*:*", "Fri *-*~07/1 12:*:*"},
+	},
+	{
+		interval:    TimeInterval{Cycle: &Cycle{Every: 2, Unit: CycleWeeks}},
+		expectError: true,
+	},
+	{
+		interval:    TimeInterval{WeekdaysOfMonth: []WeekdayOfMonth{{N: 1, AnyWeekday: true}}},
+		expectError: true,
+	},
+	{
+		interval: TimeInterval{
+			Times:       []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 1}}},
+		},
+		expectError: true,
+	},
+	{
+		interval:    TimeInterval{Months: []MonthRange{}},
+		expectError: true,
+	},
+}
+
+func TestOnCalendar(t *testing.T) {
+	for _, tc := range onCalendarTestCases {
+		events, err := tc.interval.OnCalendar()
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected an error converting %v, got %v", tc.interval, events)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error converting %v: %v", tc.interval, err)
+			continue
+		}
+		if !reflect.DeepEqual(events, tc.events) {
+			t.Errorf("Converting %v gave %q, expected %q", tc.interval, events, tc.events)
+		}
+	}
+}
+
+func TestOnCalendarRoundTrip(t *testing.T) {
+	for _, tc := range fromOnCalendarTestCases {
+		if tc.expectError || tc.interval == nil {
+			continue
+		}
+		events, err := tc.interval.OnCalendar()
+		if err != nil {
+			t.Errorf("Unexpected error converting %v: %v", *tc.interval, err)
+			continue
+		}
+		var set IntervalSet
+		for _, event := range events {
+			ti, err := FromOnCalendar(event)
+			if err != nil {
+				t.Errorf("Unexpected error converting %s: %v", event, err)
+				continue
+			}
+			set = append(set, ti)
+		}
+		if len(set) == 1 && !reflect.DeepEqual(set[0], *tc.interval) {
+			t.Errorf("Converting %s gave %v, expected %v", events[0], set[0], *tc.interval)
+		}
+		for _, c := range tc.contains {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if !set.ContainsTime(tm) {
+				t.Errorf("Expected %q to contain %s", events, c)
+			}
+		}
+		for _, c := range tc.excludes {
+			tm, _ := time.Parse(time.RFC3339, c)
+			if set.ContainsTime(tm) {
+				t.Errorf("Expected %q to exclude %s", events, c)
+			}
+		}
+	}
+}
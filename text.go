@@ -0,0 +1,469 @@
+package gotime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// The text form of a TimeInterval is a single line of space separated terms, e.g. 'mon-fri 09:00-17:00 jan-mar'.
+// Weekdays, months and times of day may be written on their own, while every other field is written as
+// field=value using its YAML name, e.g. 'days_of_month=1-7 location=Europe/London'. Several values of a field are
+// separated by commas, e.g. 'sat,sun 09:00-12:00,13:00-17:00'. Ranges may be written with '-' or ':' between their
+// ends, but ranges of negative days of the month must use ':', e.g. 'days_of_month=-7:-1'.
+
+// textListFields are the fields that hold lists of values in the text form of a TimeInterval.
+var textListFields = map[string]bool{
+	"weekdays":          true,
+	"days_of_month":     true,
+	"weekdays_of_month": true,
+	"weeks":             true,
+	"months":            true,
+	"quarters":          true,
+	"years":             true,
+	"relative":          true,
+	"dates":             true,
+	"weekend":           true,
+}
+
+// textScalarFields are the fields that hold a single value in the text form of a TimeInterval.
+var textScalarFields = map[string]bool{
+	"mode":              true,
+	"fiscal_year_start": true,
+	"holidays":          true,
+	"location":          true,
+	"locale":            true,
+}
+
+// textBareFields are the fields written without their name in the text form of a TimeInterval.
+var textBareFields = map[string]bool{
+	"weekdays": true,
+	"times":    true,
+	"months":   true,
+}
+
+// textDashRanges are the fields whose ranges may be written with '-' between their ends in text.
+var textDashRanges = map[string]bool{
+	"weekdays":      true,
+	"days_of_month": true,
+	"weeks":         true,
+	"months":        true,
+	"quarters":      true,
+	"years":         true,
+	"weekend":       true,
+}
+
+var textTimeRangeRE = regexp.MustCompile(`^([0-9]{1,2}:[0-9]{2}(?::[0-9]{2})?)-([0-9]{1,2}:[0-9]{2}(?::[0-9]{2})?)$`)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for TimeInterval, parsing the single line text form
+// of an interval, e.g. 'mon-fri 09:00-17:00 jan-mar'. An empty string is an interval that is always active.
+func (tp *TimeInterval) UnmarshalText(text []byte) error {
+	var fields yaml.MapSlice
+	index := make(map[string]int)
+	add := func(key string, values ...interface{}) {
+		i, ok := index[key]
+		if !ok {
+			i = len(fields)
+			index[key] = i
+			fields = append(fields, yaml.MapItem{Key: key, Value: []interface{}{}})
+		}
+		fields[i].Value = append(fields[i].Value.([]interface{}), values...)
+	}
+	for _, term := range strings.Fields(string(text)) {
+		if eq := strings.Index(term, "="); eq >= 0 {
+			key, value := strings.ToLower(term[:eq]), term[eq+1:]
+			switch {
+			case textListFields[key]:
+				add(key, textListValues(key, value)...)
+			case textScalarFields[key]:
+				if _, ok := index[key]; ok {
+					return fmt.Errorf("%s is given more than once", key)
+				}
+				index[key] = len(fields)
+				fields = append(fields, yaml.MapItem{Key: key, Value: value})
+			default:
+				return fmt.Errorf("%s is not a field that can be given as text", key)
+			}
+			continue
+		}
+		key, values, err := classifyTextTerm(term)
+		if err != nil {
+			return err
+		}
+		add(key, values...)
+	}
+	out, err := yaml.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	var parsed TimeInterval
+	if err := yaml.Unmarshal(out, &parsed); err != nil {
+		return err
+	}
+	*tp = parsed
+	return nil
+}
+
+// classifyTextTerm works out which field a term written without a field name belongs to, returning the field and its
+// values.
+func classifyTextTerm(term string) (string, []interface{}, error) {
+	items := strings.Split(term, ",")
+	if textTimeRangeRE.MatchString(items[0]) {
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			m := textTimeRangeRE.FindStringSubmatch(item)
+			if m == nil {
+				return "", nil, fmt.Errorf("Couldn't parse time range %s, invalid format", item)
+			}
+			values[i] = yamlTimeRange{StartTime: m[1], EndTime: m[2]}
+		}
+		return "times", values, nil
+	}
+	for _, key := range []string{"weekdays", "months"} {
+		values := textListValues(key, term)
+		matched := true
+		for _, v := range values {
+			if !isTextName(key, v.(string)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return key, values, nil
+		}
+	}
+	return "", nil, fmt.Errorf("Couldn't parse %s, invalid format", term)
+}
+
+// isTextName returns true if every end of the range is a name or keyword of the field, rather than a number.
+func isTextName(key, value string) bool {
+	for _, end := range strings.Split(strings.ToLower(value), ":") {
+		if keywordValues[end] {
+			continue
+		}
+		if key == "weekdays" && (end == "weekdays" || end == "weekend") {
+			continue
+		}
+		if _, ok := daysOfWeek[end]; key == "weekdays" && ok {
+			continue
+		}
+		if _, ok := months[end]; key == "months" && ok {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// keywordValues are the keywords understood in every list field.
+var keywordValues = map[string]bool{"always": true, "never": true, "*": true}
+
+// textListValues splits the comma separated values of a list field, converting ranges written with '-' into the ':'
+// form understood in YAML.
+func textListValues(key, value string) []interface{} {
+	items := strings.Split(value, ",")
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		if textDashRanges[key] && !strings.Contains(item, ":") && !isWeekdayOfMonth(item) {
+			// A leading '-' belongs to a negative number rather than separating the ends of a range.
+			if dash := strings.Index(item, "-"); dash == 0 {
+				if dash = strings.Index(item[1:], "-"); dash >= 0 {
+					item = item[:dash+1] + ":" + item[dash+2:]
+				}
+			} else if dash > 0 {
+				item = item[:dash] + ":" + item[dash+1:]
+			}
+		}
+		values[i] = item
+	}
+	return values
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for TimeInterval, giving its single line text form.
+// Intervals with fields that have no text form, such as exceptions, labels or solar times, can't be marshalled.
+func (tp TimeInterval) MarshalText() ([]byte, error) {
+	for _, f := range []struct {
+		key   string
+		unset bool
+	}{
+		{"name", tp.Name == ""},
+		{"description", tp.Description == ""},
+		{"labels", tp.Labels == nil},
+		{"absolute", tp.Absolute == nil},
+		{"cycle", tp.Cycle == nil},
+		{"calendar", tp.Calendar == nil},
+		{"expression", tp.Expression == nil},
+		{"coordinates", tp.Coordinates == nil},
+		{"dst", tp.DST == DSTPolicy{}},
+		{"except", tp.Except == nil},
+	} {
+		if !f.unset {
+			return nil, fmt.Errorf("Unable to express the %s of an interval as text", f.key)
+		}
+	}
+	if tp.hasSolarTimes() {
+		return nil, errors.New("Unable to express solar times as text")
+	}
+	var terms []string
+	addList := func(key string, set bool, n int, item func(i int) ([]byte, error)) error {
+		if !set {
+			return nil
+		}
+		if n == 0 && !textListFields[key] {
+			return fmt.Errorf("Unable to express an empty list of %s as text", key)
+		}
+		if n == 0 {
+			// An empty list matches nothing, which is written with the never keyword.
+			terms = append(terms, key+"=never")
+			return nil
+		}
+		values := make([]string, n)
+		for i := range values {
+			text, err := item(i)
+			if err != nil {
+				return err
+			}
+			values[i] = string(text)
+		}
+		bare := textBareFields[key]
+		for _, v := range values {
+			// Numbered weekdays, such as the ISO ranges ending on Sunday 7, would be mistaken for another field.
+			if key != "times" && !isTextName(key, v) {
+				bare = false
+			}
+		}
+		term := strings.Join(values, ",")
+		if !bare {
+			term = key + "=" + term
+		}
+		terms = append(terms, term)
+		return nil
+	}
+	addScalar := func(key string, m yaml.Marshaler) error {
+		text, err := textOf(m)
+		if err != nil {
+			return err
+		}
+		terms = append(terms, key+"="+string(text))
+		return nil
+	}
+	if tp.Mode != ModeAllow {
+		if err := addScalar("mode", tp.Mode); err != nil {
+			return nil, err
+		}
+	}
+	lists := []struct {
+		key  string
+		set  bool
+		n    int
+		item func(i int) ([]byte, error)
+	}{
+		{"weekdays", tp.Weekdays != nil, len(tp.Weekdays), func(i int) ([]byte, error) { return tp.Weekdays[i].MarshalText() }},
+		{"times", tp.Times != nil, len(tp.Times), func(i int) ([]byte, error) { return tp.Times[i].MarshalText() }},
+		{"days_of_month", tp.DaysOfMonth != nil, len(tp.DaysOfMonth), func(i int) ([]byte, error) { return tp.DaysOfMonth[i].MarshalText() }},
+		{"weekdays_of_month", tp.WeekdaysOfMonth != nil, len(tp.WeekdaysOfMonth), func(i int) ([]byte, error) { return tp.WeekdaysOfMonth[i].MarshalText() }},
+		{"weeks", tp.Weeks != nil, len(tp.Weeks), func(i int) ([]byte, error) { return tp.Weeks[i].MarshalText() }},
+		{"months", tp.Months != nil, len(tp.Months), func(i int) ([]byte, error) { return tp.Months[i].MarshalText() }},
+		{"quarters", tp.Quarters != nil, len(tp.Quarters), func(i int) ([]byte, error) { return tp.Quarters[i].MarshalText() }},
+		{"years", tp.Years != nil, len(tp.Years), func(i int) ([]byte, error) { return tp.Years[i].MarshalText() }},
+		{"relative", tp.Relative != nil, len(tp.Relative), func(i int) ([]byte, error) { return tp.Relative[i].MarshalText() }},
+		{"dates", tp.Dates != nil, len(tp.Dates), func(i int) ([]byte, error) { return tp.Dates[i].MarshalText() }},
+	}
+	for _, l := range lists {
+		if err := addList(l.key, l.set, l.n, l.item); err != nil {
+			return nil, err
+		}
+	}
+	if tp.FiscalYearStart != 0 {
+		if err := addScalar("fiscal_year_start", tp.FiscalYearStart); err != nil {
+			return nil, err
+		}
+	}
+	if tp.Holidays != nil {
+		if err := addScalar("holidays", tp.Holidays); err != nil {
+			return nil, err
+		}
+	}
+	if tp.Location != nil {
+		if err := addScalar("location", tp.Location); err != nil {
+			return nil, err
+		}
+	}
+	return []byte(strings.Join(terms, " ")), nil
+}
+
+// textOf returns the YAML string that m is marshalled to.
+func textOf(m yaml.Marshaler) ([]byte, error) {
+	v, err := m.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	str, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("Unable to express %v as text", v)
+	}
+	return []byte(str), nil
+}
+
+// unmarshalText parses text as though it were a YAML string being unmarshalled into u.
+func unmarshalText(text []byte, u yaml.Unmarshaler) error {
+	return u.UnmarshalYAML(func(v interface{}) error {
+		str, ok := v.(*string)
+		if !ok {
+			return fmt.Errorf("Couldn't parse %s, invalid format", text)
+		}
+		*str = string(text)
+		return nil
+	})
+}
+
+// dashRange returns a range written with '-' between its ends in the ':' form understood in YAML.
+func dashRange(key string, text []byte) []byte {
+	return []byte(textListValues(key, string(text))[0].(string))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for WeekdayRange.
+func (r WeekdayRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for WeekdayRange.
+func (r *WeekdayRange) UnmarshalText(text []byte) error {
+	return unmarshalText(dashRange("weekdays", text), r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for DayOfMonthRange.
+func (r DayOfMonthRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for DayOfMonthRange.
+func (r *DayOfMonthRange) UnmarshalText(text []byte) error {
+	return unmarshalText(dashRange("days_of_month", text), r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for MonthRange.
+func (r MonthRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for MonthRange.
+func (r *MonthRange) UnmarshalText(text []byte) error {
+	return unmarshalText(dashRange("months", text), r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for WeekRange.
+func (r WeekRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for WeekRange.
+func (r *WeekRange) UnmarshalText(text []byte) error {
+	return unmarshalText(dashRange("weeks", text), r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for YearRange.
+func (r YearRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for YearRange.
+func (r *YearRange) UnmarshalText(text []byte) error {
+	return unmarshalText(dashRange("years", text), r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for QuarterRange.
+func (r QuarterRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for QuarterRange.
+func (r *QuarterRange) UnmarshalText(text []byte) error {
+	return unmarshalText(dashRange("quarters", text), r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for RelativeRange.
+func (r RelativeRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for RelativeRange.
+func (r *RelativeRange) UnmarshalText(text []byte) error {
+	return unmarshalText(text, r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for DateRange.
+func (r DateRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for DateRange.
+func (r *DateRange) UnmarshalText(text []byte) error {
+	return unmarshalText(text, r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for CalendarDateRange.
+func (r CalendarDateRange) MarshalText() ([]byte, error) {
+	return textOf(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for CalendarDateRange.
+func (r *CalendarDateRange) UnmarshalText(text []byte) error {
+	return unmarshalText(text, r)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for WeekdayOfMonth, using the style of cron so that the
+// text has no spaces, e.g. 'tuesday#2', '5L' or 'LW'.
+func (w WeekdayOfMonth) MarshalText() ([]byte, error) {
+	if w.N == lastOccurrence {
+		if w.AnyWeekday {
+			return []byte("LW"), nil
+		}
+		return []byte(strconv.Itoa(int(w.Weekday)) + "L"), nil
+	}
+	day := "weekday"
+	if !w.AnyWeekday {
+		var ok bool
+		if day, ok = daysOfWeekInv[int(w.Weekday)]; !ok {
+			return nil, fmt.Errorf("Unable to convert %d into weekday string", w.Weekday)
+		}
+	}
+	if _, ok := ordinalSuffixes[w.N]; !ok {
+		return nil, fmt.Errorf("Unable to convert %d into an occurrence within the month", w.N)
+	}
+	return []byte(fmt.Sprintf("%s#%d", day, w.N)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for WeekdayOfMonth.
+func (w *WeekdayOfMonth) UnmarshalText(text []byte) error {
+	return unmarshalText(text, w)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for TimeRange, writing it as 'start-end', e.g.
+// '09:00-17:00'. Ranges relative to sunrise or sunset have no text form.
+func (tr TimeRange) MarshalText() ([]byte, error) {
+	if tr.StartSolar != nil || tr.EndSolar != nil {
+		return nil, errors.New("Unable to express solar times as text")
+	}
+	return []byte(formatTime(tr.startSecond()) + "-" + formatTime(tr.endSecond())), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for TimeRange.
+func (tr *TimeRange) UnmarshalText(text []byte) error {
+	m := textTimeRangeRE.FindStringSubmatch(string(text))
+	if m == nil {
+		return fmt.Errorf("Couldn't parse time range %s, invalid format", text)
+	}
+	return tr.UnmarshalYAML(func(v interface{}) error {
+		y, ok := v.(*yamlTimeRange)
+		if !ok {
+			return fmt.Errorf("Couldn't parse time range %s, invalid format", text)
+		}
+		y.StartTime, y.EndTime = m[1], m[2]
+		return nil
+	})
+}
@@ -0,0 +1,182 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var textTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	out         string
+	expectError bool
+}{
+	{
+		in: "mon-fri 09:00-17:00 jan-mar",
+		interval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Months:   []MonthRange{{InclusiveRange{Begin: 1, End: 3}}},
+		},
+		out: "monday:friday 09:00-17:00 january:march",
+	},
+	{
+		in: "sat,sun 09:00-12:00,13:00-17:30:30 days_of_month=-7:-1,1-7 years=2020-2025",
+		interval: TimeInterval{
+			Times: []TimeRange{
+				{StartMinute: 540, EndMinute: 720},
+				{StartMinute: 780, EndMinute: 1050, EndSecond: 30},
+			},
+			Weekdays:    []WeekdayRange{{InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange{Begin: 0, End: 0}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -7, End: -1}}, {InclusiveRange{Begin: 1, End: 7}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2020, End: 2025}}},
+		},
+		out: "saturday,sunday 09:00-12:00,13:00-17:30:30 days_of_month=-7:-1,1:7 years=2020:2025",
+	},
+	{
+		in: "weekend 22:00-06:00 location=Australia/Melbourne mode=deny",
+		interval: TimeInterval{
+			Mode:     ModeDeny,
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}},
+			Location: mustLoadLocation("Australia/Melbourne"),
+		},
+		out: "mode=deny sunday,saturday 22:00-06:00 location=Australia/Melbourne",
+	},
+	{
+		in:          "days_of_month=tue#2,last_friday weeks=1-2 quarters=q1 fiscal_year_start=july relative=easter-2:easter+1 dates=2024-12-24:2025-01-02",
+		expectError: true,
+	},
+	{
+		in: "days_of_month=tue#2,5L weeks=1-2 quarters=q1 fiscal_year_start=july relative=easter-2:easter+1 dates=2024-12-24:2025-01-02",
+		interval: TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 2, Weekday: time.Tuesday}, {N: lastOccurrence, Weekday: time.Friday}},
+			Weeks:           []WeekRange{{InclusiveRange{Begin: 1, End: 2}}},
+			Quarters:        []QuarterRange{{InclusiveRange{Begin: 1, End: 1}}},
+			Relative:        []RelativeRange{{Feast: "easter", InclusiveRange: InclusiveRange{Begin: -2, End: 1}}},
+			Dates:           []DateRange{{Begin: date(2024, 12, 24), End: date(2025, 1, 2)}},
+			FiscalYearStart: FiscalYearStart(time.July),
+		},
+		out: "weekdays_of_month=tuesday#2,5L weeks=1:2 quarters=1 relative=easter-2:easter+1 dates=2024-12-24:2025-01-02 fiscal_year_start=july",
+	},
+	{
+		in:       "",
+		interval: TimeInterval{},
+		out:      "",
+	},
+	{
+		in:       "weekdays=never",
+		interval: TimeInterval{Weekdays: []WeekdayRange{}},
+		out:      "weekdays=never",
+	},
+	{
+		// ISO numbered ranges ending on Sunday 7 can't be written with names, so keep their field name.
+		in: "weekdays=1-7,6:7 09:00-17:00",
+		interval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 7}}, {InclusiveRange{Begin: 6, End: 7}}},
+		},
+		out: "weekdays=1:7,6:7 09:00-17:00",
+	},
+	{
+		in:          "mon-fri nonsense",
+		expectError: true,
+	},
+	{
+		in:          "labels=team:sre",
+		expectError: true,
+	},
+	{
+		in:          "location=Europe/London location=Europe/Paris",
+		expectError: true,
+	},
+	{
+		in:          "09:00-17:00,lunch",
+		expectError: true,
+	},
+}
+
+func TestText(t *testing.T) {
+	for _, tc := range textTestCases {
+		var ti TimeInterval
+		err := ti.UnmarshalText([]byte(tc.in))
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+		out, err := ti.MarshalText()
+		if err != nil {
+			t.Error(err)
+		}
+		if string(out) != tc.out {
+			t.Errorf("Marshalling %s: want %q, got %q", tc.in, tc.out, out)
+		}
+		var ti2 TimeInterval
+		if err := ti2.UnmarshalText(out); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestTextUnrepresentable(t *testing.T) {
+	for _, ti := range []TimeInterval{
+		{Name: "business hours"},
+		{Except: []TimeInterval{{Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}}}}},
+		{Times: []TimeRange{{StartSolar: &SolarTime{Event: Sunrise}, EndMinute: 720}}, Coordinates: &Coordinates{}},
+		{Times: []TimeRange{}},
+	} {
+		if out, err := ti.MarshalText(); err == nil {
+			t.Errorf("Expected marshalling %+v as text to fail, got %s", ti, out)
+		}
+	}
+}
+
+func TestRangeText(t *testing.T) {
+	var weekdays WeekdayRange
+	if err := weekdays.UnmarshalText([]byte("mon-fri")); err != nil || weekdays != (WeekdayRange{InclusiveRange{Begin: 1, End: 5}}) {
+		t.Errorf("Parsing weekdays mon-fri: got %+v, %v", weekdays, err)
+	}
+	var days DayOfMonthRange
+	if err := days.UnmarshalText([]byte("-7--1")); err != nil || days != (DayOfMonthRange{InclusiveRange{Begin: -7, End: -1}}) {
+		t.Errorf("Parsing days of the month -7--1: got %+v, %v", days, err)
+	}
+	var tr TimeRange
+	if err := tr.UnmarshalText([]byte("22:00-06:00")); err != nil || tr != (TimeRange{StartMinute: 1320, EndMinute: 360}) {
+		t.Errorf("Parsing time range 22:00-06:00: got %+v, %v", tr, err)
+	}
+	if err := tr.UnmarshalText([]byte("9am-5pm")); err == nil {
+		t.Errorf("Expected parsing time range 9am-5pm to fail")
+	}
+	var months MonthRange
+	if err := months.UnmarshalText([]byte("smarch")); err == nil {
+		t.Errorf("Expected parsing month smarch to fail")
+	}
+	for _, tc := range []struct {
+		w    WeekdayOfMonth
+		want string
+	}{
+		{WeekdayOfMonth{N: 2, Weekday: time.Tuesday}, "tuesday#2"},
+		{WeekdayOfMonth{N: lastOccurrence, Weekday: time.Friday}, "5L"},
+		{WeekdayOfMonth{N: lastOccurrence, AnyWeekday: true}, "LW"},
+		{WeekdayOfMonth{N: 1, AnyWeekday: true}, "weekday#1"},
+	} {
+		out, err := tc.w.MarshalText()
+		if err != nil || string(out) != tc.want {
+			t.Errorf("Marshalling %+v: want %s, got %s, %v", tc.w, tc.want, out, err)
+			continue
+		}
+		var w WeekdayOfMonth
+		if err := w.UnmarshalText(out); err != nil || w != tc.w {
+			t.Errorf("Re-parsing %s: want %+v, got %+v, %v", out, tc.w, w, err)
+		}
+	}
+}
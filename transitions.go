@@ -0,0 +1,186 @@
+package gotime
+
+import "time"
+
+// NextTransition returns the earliest time after t at which the TimeInterval either becomes active or stops being
+// active. It returns false if the interval never changes state within the search horizon.
+func (tp TimeInterval) NextTransition(t time.Time) (time.Time, bool) {
+	if tp.IsAlwaysActive() {
+		return time.Time{}, false
+	}
+	var next time.Time
+	found := false
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	tp.walkWindows(t, limit, func(w Window) bool {
+		if w.Start.After(t) {
+			next = w.Start
+		} else {
+			next = w.End
+		}
+		found = true
+		return false
+	})
+	// A window still running when the walk gives up has no end that we know of.
+	if !found || !next.Before(limit) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// PreviousTransition returns the latest time at or before t at which the TimeInterval either became active or stopped
+// being active. It returns false if the interval never changed state within the search horizon.
+func (tp TimeInterval) PreviousTransition(t time.Time) (time.Time, bool) {
+	if tp.Absolute != nil {
+		return previousCompositeTransition(tp, []TransitionMatcher{tp.withoutAbsolute(), absoluteRanges(tp.Absolute)}, t)
+	}
+	if len(tp.Except) > 0 {
+		return previousCompositeTransition(tp, tp.exceptionMatchers(), t)
+	}
+	ranges := tp.mergedTimeRanges()
+	if (len(ranges) == 0 && !tp.hasSolarTimes()) || tp.IsAlwaysActive() {
+		return time.Time{}, false
+	}
+	firstYear, yearBounded := tp.firstYear()
+	years := TimeInterval{Years: tp.Years, FiscalYearStart: tp.FiscalYearStart}
+	months := TimeInterval{Months: tp.Months, Quarters: tp.Quarters, FiscalYearStart: tp.FiscalYearStart}
+	limit := t.AddDate(-maxSearchYears, 0, 0)
+	local := tp.inLocation(t)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	for !day.Before(limit) {
+		if yearBounded && day.Year() < firstYear {
+			break
+		}
+		if !years.containsDay(day) {
+			day = tp.yearStart(day).AddDate(0, 0, -1)
+			continue
+		}
+		if !months.containsDay(day) {
+			day = time.Date(day.Year(), day.Month(), 0, 0, 0, 0, 0, day.Location())
+			continue
+		}
+		windows := tp.dayWindows(day, ranges)
+		for i := len(windows) - 1; i >= 0; i-- {
+			for _, b := range []time.Time{windows[i].End, windows[i].Start} {
+				// Boundaries may be shared with windows on neighbouring days, so check the state really changes.
+				if !b.After(t) && isTransition(tp, b) {
+					return b.In(t.Location()), true
+				}
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()-1, 0, 0, 0, 0, day.Location())
+	}
+	return time.Time{}, false
+}
+
+// NextActiveTime returns t if the TimeInterval contains it, otherwise the time at which the interval next becomes
+// active. It returns false if the interval doesn't become active within the search horizon.
+func (tp TimeInterval) NextActiveTime(t time.Time) (time.Time, bool) {
+	return nextActiveTime(tp, t)
+}
+
+// firstYear returns the first calendar year the interval can be active in, or false if it is not bounded by years or
+// dates.
+func (tp TimeInterval) firstYear() (int, bool) {
+	first, bounded := 0, false
+	for i, yr := range tp.Years {
+		if i == 0 || yr.Begin < first {
+			first = yr.Begin
+		}
+	}
+	if tp.Years != nil {
+		bounded = true
+		if tp.fiscalStartMonth() != time.January {
+			// Fiscal years begin in the calendar year before the one they are named after.
+			first--
+		}
+	}
+	if tp.Dates != nil {
+		firstDate := 0
+		for i, r := range tp.Dates {
+			if i == 0 || r.Begin.Year() < firstDate {
+				firstDate = r.Begin.Year()
+			}
+		}
+		if !bounded || firstDate > first {
+			first = firstDate
+		}
+		bounded = true
+	}
+	return first, bounded
+}
+
+// isTransition returns true if the matcher's state at t differs from its state immediately before t.
+func isTransition(m TransitionMatcher, t time.Time) bool {
+	return m.ContainsTime(t) != m.ContainsTime(t.Add(-time.Nanosecond))
+}
+
+func nextActiveTime(m TransitionMatcher, t time.Time) (time.Time, bool) {
+	if m.ContainsTime(t) {
+		return t, true
+	}
+	return m.NextTransition(t)
+}
+
+// nextCompositeTransition returns the next transition after t of a matcher m whose state is derived from its children,
+// and so can only change when one of them does.
+func nextCompositeTransition(m TransitionMatcher, children []TransitionMatcher, t time.Time) (time.Time, bool) {
+	state := m.ContainsTime(t)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	cur := t
+	for {
+		var next time.Time
+		found := false
+		for _, c := range children {
+			if ct, ok := c.NextTransition(cur); ok && (!found || ct.Before(next)) {
+				next, found = ct, true
+			}
+		}
+		if !found || next.After(limit) {
+			return time.Time{}, false
+		}
+		if m.ContainsTime(next) != state {
+			return next, true
+		}
+		cur = next
+	}
+}
+
+// previousCompositeTransition returns the latest transition at or before t of a matcher m whose state is derived from
+// its children, and so can only change when one of them does.
+func previousCompositeTransition(m TransitionMatcher, children []TransitionMatcher, t time.Time) (time.Time, bool) {
+	limit := t.AddDate(-maxSearchYears, 0, 0)
+	cur := t
+	for {
+		var prev time.Time
+		found := false
+		for _, c := range children {
+			if ct, ok := c.PreviousTransition(cur); ok && (!found || ct.After(prev)) {
+				prev, found = ct, true
+			}
+		}
+		if !found || prev.Before(limit) {
+			return time.Time{}, false
+		}
+		if isTransition(m, prev) {
+			return prev, true
+		}
+		cur = prev.Add(-time.Nanosecond)
+	}
+}
+
+// transitionWindows returns the windows during which m is active between from and to, clipped to those bounds.
+func transitionWindows(m TransitionMatcher, from, to time.Time) []Window {
+	var windows []Window
+	cur := from
+	for cur.Before(to) {
+		next, ok := m.NextTransition(cur)
+		if !ok || next.After(to) {
+			next = to
+		}
+		if m.ContainsTime(cur) {
+			windows = append(windows, Window{Start: cur, End: next})
+		}
+		cur = next
+	}
+	return windows
+}
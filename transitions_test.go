@@ -0,0 +1,113 @@
+package gotime
+
+import (
+	"testing"
+	"time"
+)
+
+var transitionTestCases = []struct {
+	timeInterval TimeInterval
+	at           string
+	next         string
+	previous     string
+	nextActive   string
+}{
+	{
+		// During business hours
+		timeInterval: businessHours,
+		at:           "2020-07-08T12:00:00Z",
+		next:         "2020-07-08T17:00:00Z",
+		previous:     "2020-07-08T09:00:00Z",
+		nextActive:   "2020-07-08T12:00:00Z",
+	},
+	{
+		// Over the weekend
+		timeInterval: businessHours,
+		at:           "2020-07-11T12:00:00Z",
+		next:         "2020-07-13T09:00:00Z",
+		previous:     "2020-07-10T17:00:00Z",
+		nextActive:   "2020-07-13T09:00:00Z",
+	},
+	{
+		// Exactly at a transition
+		timeInterval: businessHours,
+		at:           "2020-07-08T09:00:00Z",
+		next:         "2020-07-08T17:00:00Z",
+		previous:     "2020-07-08T09:00:00Z",
+		nextActive:   "2020-07-08T09:00:00Z",
+	},
+	{
+		// Whole days are not interrupted at midnight
+		timeInterval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}},
+		},
+		at:         "2020-07-12T12:00:00Z",
+		next:       "2020-07-13T00:00:00Z",
+		previous:   "2020-07-11T00:00:00Z",
+		nextActive: "2020-07-12T12:00:00Z",
+	},
+	{
+		// The morning after an overnight range
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 5}}},
+		},
+		at:         "2020-07-11T03:00:00Z",
+		next:       "2020-07-11T06:00:00Z",
+		previous:   "2020-07-10T22:00:00Z",
+		nextActive: "2020-07-11T03:00:00Z",
+	},
+	{
+		// Years in the past and future
+		timeInterval: TimeInterval{
+			Years: []YearRange{{InclusiveRange{Begin: 2022, End: 2022}}},
+		},
+		at:         "2020-07-12T12:00:00Z",
+		next:       "2022-01-01T00:00:00Z",
+		previous:   "",
+		nextActive: "2022-01-01T00:00:00Z",
+	},
+	{
+		timeInterval: TimeInterval{},
+		at:           "2020-07-12T12:00:00Z",
+		next:         "",
+		previous:     "",
+		nextActive:   "2020-07-12T12:00:00Z",
+	},
+	{
+		timeInterval: emptyInterval(),
+		at:           "2020-07-12T12:00:00Z",
+		next:         "",
+		previous:     "",
+		nextActive:   "",
+	},
+}
+
+// checkTransition compares the result of a transition query against an expected timestamp, where an empty string
+// means no transition should have been found.
+func checkTransition(t *testing.T, name string, m interface{}, want string, got time.Time, ok bool) {
+	t.Helper()
+	if want == "" {
+		if ok {
+			t.Errorf("%s for %+v: expected no result, got %v", name, m, got)
+		}
+		return
+	}
+	if !ok {
+		t.Errorf("%s for %+v: want %s, got no result", name, m, want)
+	} else if !got.Equal(mustParseTime(want)) {
+		t.Errorf("%s for %+v: want %s, got %v", name, m, want, got)
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	for _, tc := range transitionTestCases {
+		at := mustParseTime(tc.at)
+		next, ok := tc.timeInterval.NextTransition(at)
+		checkTransition(t, "NextTransition", tc.timeInterval, tc.next, next, ok)
+		previous, ok := tc.timeInterval.PreviousTransition(at)
+		checkTransition(t, "PreviousTransition", tc.timeInterval, tc.previous, previous, ok)
+		nextActive, ok := tc.timeInterval.NextActiveTime(at)
+		checkTransition(t, "NextActiveTime", tc.timeInterval, tc.nextActive, nextActive, ok)
+	}
+}
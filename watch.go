@@ -0,0 +1,95 @@
+package gotime
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks are the functions Watch calls when an interval becomes active and stops being active. Each is passed the time
+// of the transition, which may be some time ago if the process was suspended or busy when it happened. Either may be
+// nil.
+type Hooks struct {
+	OnActivate   func(at time.Time)
+	OnDeactivate func(at time.Time)
+}
+
+// Watch calls the hooks once for each time the interval becomes active or stops being active until ctx is done, and
+// then returns ctx.Err(). If the interval is active when Watch is called, OnActivate is called at once with the time
+// its current window opened, so that the hooks always alternate starting with OnActivate. Hooks are called one at a
+// time from the goroutine that called Watch, and transitions that pass while the process is suspended, or while a hook
+// is running, are caught up on afterwards by calling the hooks for each of them in order.
+func Watch(ctx context.Context, ti TimeInterval, hooks Hooks) error {
+	return watch(ctx, ti, hooks)
+}
+
+func watch(ctx context.Context, m TransitionMatcher, hooks Hooks) error {
+	last := time.Now()
+	if m.ContainsTime(last) {
+		start, ok := m.PreviousTransition(last)
+		if !ok {
+			start = last
+		}
+		hooks.call(true, start)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		now := time.Now()
+		last = hooks.catchUp(m, last, now)
+		wait := maxWait
+		if next, ok := m.NextTransition(last); ok && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// catchUp calls the hooks for each transition after since and at or before now, returning the time of the last.
+func (h Hooks) catchUp(m TransitionMatcher, since, now time.Time) time.Time {
+	for {
+		next, ok := m.NextTransition(since)
+		if !ok || next.After(now) {
+			return since
+		}
+		h.call(m.ContainsTime(next), next)
+		since = next
+	}
+}
+
+func (h Hooks) call(active bool, at time.Time) {
+	if active && h.OnActivate != nil {
+		h.OnActivate(at)
+	} else if !active && h.OnDeactivate != nil {
+		h.OnDeactivate(at)
+	}
+}
+
+// A Transition is an interval becoming active or stopping being active.
+type Transition struct {
+	At     time.Time
+	Active bool
+}
+
+// Transitions returns a channel that receives the interval's transitions as they happen, and is closed once ctx is
+// done. Like Watch, it starts with the opening of the current window if the interval is active, and catches up on
+// transitions that pass while the process is suspended or the receiver is slow, so every transition is received once
+// and in order.
+func Transitions(ctx context.Context, ti TimeInterval) <-chan Transition {
+	ch := make(chan Transition)
+	send := func(active bool) func(time.Time) {
+		return func(at time.Time) {
+			select {
+			case ch <- Transition{At: at, Active: active}:
+			case <-ctx.Done():
+			}
+		}
+	}
+	go func() {
+		defer close(ch)
+		watch(ctx, ti, Hooks{OnActivate: send(true), OnDeactivate: send(false)})
+	}()
+	return ch
+}
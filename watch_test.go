@@ -0,0 +1,90 @@
+package gotime
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recorder returns hooks that record each call as "+" or "-" followed by the time of the transition.
+func recorder(calls *[]string) Hooks {
+	return Hooks{
+		OnActivate:   func(at time.Time) { *calls = append(*calls, "+"+at.Format("15:04")) },
+		OnDeactivate: func(at time.Time) { *calls = append(*calls, "-"+at.Format("15:04")) },
+	}
+}
+
+func TestWatch(t *testing.T) {
+	now := time.Now()
+	ti := TimeInterval{Absolute: []AbsoluteRange{
+		{Start: now.Add(-time.Minute), End: now.Add(100 * time.Millisecond)},
+		{Start: now.Add(200 * time.Millisecond), End: now.Add(300 * time.Millisecond)},
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var calls []string
+	if err := Watch(ctx, ti, recorder(&calls)); err != context.DeadlineExceeded {
+		t.Errorf("Expected the watch to end with the context, got %v", err)
+	}
+	var expected []string
+	for i, at := range []time.Time{now.Add(-time.Minute), now.Add(100 * time.Millisecond),
+		now.Add(200 * time.Millisecond), now.Add(300 * time.Millisecond)} {
+		expected = append(expected, fmt.Sprintf("%s%s", []string{"+", "-"}[i%2], at.Format("15:04")))
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected hooks %v, got %v", expected, calls)
+	}
+}
+
+func TestHooksCatchUp(t *testing.T) {
+	ti := TimeInterval{Times: []TimeRange{{StartMinute: 540, EndMinute: 1020}}}
+	at := func(s string) time.Time {
+		t, _ := time.Parse(time.RFC3339, s)
+		return t
+	}
+	cases := []struct {
+		since, now string
+		expected   []string
+	}{
+		{"2024-07-01T08:00:00Z", "2024-07-01T08:59:59Z", nil},
+		{"2024-07-01T08:00:00Z", "2024-07-01T09:00:00Z", []string{"+09:00"}},
+		{"2024-07-01T09:00:00Z", "2024-07-01T12:00:00Z", nil},
+		// Suspended across several windows.
+		{"2024-07-01T12:00:00Z", "2024-07-03T10:00:00Z", []string{"-17:00", "+09:00", "-17:00", "+09:00"}},
+	}
+	for _, c := range cases {
+		var calls []string
+		last := recorder(&calls).catchUp(ti, at(c.since), at(c.now))
+		if !reflect.DeepEqual(calls, c.expected) {
+			t.Errorf("Expected hooks %v from %s to %s, got %v", c.expected, c.since, c.now, calls)
+		}
+		if len(c.expected) == 0 && !last.Equal(at(c.since)) {
+			t.Errorf("Expected no progress from %s to %s, got %s", c.since, c.now, last)
+		}
+	}
+	// Nil hooks are skipped.
+	Hooks{}.catchUp(ti, at("2024-07-01T08:00:00Z"), at("2024-07-02T08:00:00Z"))
+}
+
+func TestTransitionsChannel(t *testing.T) {
+	now := time.Now()
+	window := AbsoluteRange{Start: now.Add(50 * time.Millisecond), End: now.Add(150 * time.Millisecond)}
+	ti := TimeInterval{Absolute: []AbsoluteRange{window}}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var got []Transition
+	for tr := range Transitions(ctx, ti) {
+		got = append(got, tr)
+	}
+	expected := []Transition{{At: window.Start, Active: true}, {At: window.End, Active: false}}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected transitions %v, got %v", expected, got)
+	}
+	for i := range got {
+		if !got[i].At.Equal(expected[i].At) || got[i].Active != expected[i].Active {
+			t.Errorf("Expected transition %v, got %v", expected[i], got[i])
+		}
+	}
+}
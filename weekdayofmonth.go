@@ -0,0 +1,198 @@
+package gotime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A WeekdayOfMonth matches the Nth occurrence of a day of the week within a month, e.g. the 2nd Tuesday. N runs from 1
+// to 5, or is -1 for the last occurrence in the month. In YAML it is written as '2nd tuesday' or 'last friday', or in
+// the style of cron as 'tuesday#2' or '5L'.
+//
+// If AnyWeekday is set, occurrences of every day from Monday to Friday are counted instead of a single Weekday, so
+// 'last weekday' (cron's 'LW') matches the last Monday to Friday of the month.
+type WeekdayOfMonth struct {
+	N          int
+	Weekday    time.Weekday
+	AnyWeekday bool
+}
+
+// lastOccurrence is the value of N that matches the last occurrence of a weekday in the month.
+const lastOccurrence = -1
+
+var ordinalWeekdayRE = regexp.MustCompile(`^([1-5])(st|nd|rd|th)\s+([a-z]+)$`)
+var lastWeekdayRE = regexp.MustCompile(`^last\s+([a-z]+)$`)
+var cronWeekdayRE = regexp.MustCompile(`^([a-z]+|[0-7])#([1-5])$`)
+var cronLastWeekdayRE = regexp.MustCompile(`^([0-7])l$`)
+
+var ordinalSuffixes = map[int]string{
+	1: "st",
+	2: "nd",
+	3: "rd",
+	4: "th",
+	5: "th",
+}
+
+// UnmarshalYAML implements the Unmarshaller interface for WeekdayOfMonth.
+func (w *WeekdayOfMonth) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	parsed, err := parseWeekdayOfMonth(str)
+	if err != nil {
+		return err
+	}
+	*w = parsed
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for WeekdayOfMonth
+func (w WeekdayOfMonth) MarshalYAML() (interface{}, error) {
+	day := "weekday"
+	if !w.AnyWeekday {
+		var ok bool
+		day, ok = daysOfWeekInv[int(w.Weekday)]
+		if !ok {
+			return nil, fmt.Errorf("Unable to convert %d into weekday string", w.Weekday)
+		}
+	}
+	if w.N == lastOccurrence {
+		return interface{}("last " + day), nil
+	}
+	suffix, ok := ordinalSuffixes[w.N]
+	if !ok {
+		return nil, fmt.Errorf("Unable to convert %d into an occurrence within the month", w.N)
+	}
+	return interface{}(fmt.Sprintf("%d%s %s", w.N, suffix, day)), nil
+}
+
+// parseWeekdayOfMonth converts a string such as '2nd tuesday', 'last friday' or 'tue#2' into a WeekdayOfMonth.
+func parseWeekdayOfMonth(in string) (WeekdayOfMonth, error) {
+	str := strings.ToLower(strings.TrimSpace(in))
+	var n int
+	var day string
+	if m := ordinalWeekdayRE.FindStringSubmatch(str); m != nil {
+		n, _ = strconv.Atoi(m[1])
+		day = m[3]
+		if ordinalSuffixes[n] != m[2] {
+			return WeekdayOfMonth{}, fmt.Errorf("%s is not a valid weekday of the month: mismatched ordinal", in)
+		}
+	} else if m := lastWeekdayRE.FindStringSubmatch(str); m != nil {
+		n, day = lastOccurrence, m[1]
+	} else if m := cronWeekdayRE.FindStringSubmatch(str); m != nil {
+		n, _ = strconv.Atoi(m[2])
+		day = m[1]
+	} else if m := cronLastWeekdayRE.FindStringSubmatch(str); m != nil {
+		n, day = lastOccurrence, m[1]
+	} else if str == "lw" {
+		n, day = lastOccurrence, "weekday"
+	} else {
+		return WeekdayOfMonth{}, fmt.Errorf("Couldn't parse weekday of the month %s, invalid format", in)
+	}
+	if day == "weekday" {
+		return WeekdayOfMonth{N: n, AnyWeekday: true}, nil
+	}
+	var r WeekdayRange
+	wd, err := r.memberFromString(day)
+	if err != nil {
+		return WeekdayOfMonth{}, err
+	}
+	if wd < 0 || wd > 7 {
+		return WeekdayOfMonth{}, fmt.Errorf("%s is not a valid day of the week: out of range", in)
+	}
+	return WeekdayOfMonth{N: n, Weekday: time.Weekday(wd % 7)}, nil
+}
+
+// isWeekdayOfMonth returns true if the string looks like a weekday of the month rather than a day of the month range.
+func isWeekdayOfMonth(in string) bool {
+	str := strings.ToLower(strings.TrimSpace(in))
+	return ordinalWeekdayRE.MatchString(str) || lastWeekdayRE.MatchString(str) || cronWeekdayRE.MatchString(str) ||
+		cronLastWeekdayRE.MatchString(str) || str == "lw"
+}
+
+// containsDay returns true if the calendar day of the given time is the Nth occurrence of the weekday in its month.
+func (w WeekdayOfMonth) containsDay(t time.Time) bool {
+	if !w.AnyWeekday {
+		if t.Weekday() != w.Weekday {
+			return false
+		}
+		if w.N < 0 {
+			return (daysInMonthOf(t)-t.Day())/7+1 == -w.N
+		}
+		return (t.Day()-1)/7+1 == w.N
+	}
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	// Count the days from Monday to Friday between t and whichever end of the month N counts from, including t.
+	first, last := 1, t.Day()
+	if w.N < 0 {
+		first, last = t.Day(), daysInMonthOf(t)
+	}
+	count := 0
+	for day := first; day <= last; day++ {
+		wd := time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday()
+		if wd != time.Saturday && wd != time.Sunday {
+			count++
+		}
+	}
+	if w.N < 0 {
+		return count == -w.N
+	}
+	return count == w.N
+}
+
+// daysInMonthOf returns the number of days in the month of the given time.
+func daysInMonthOf(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// splitWeekdaysOfMonth moves any weekdays of the month given in the days_of_month field of an interval into its
+// weekdays_of_month field, returning false if there were none.
+func splitWeekdaysOfMonth(fields yaml.MapSlice) (yaml.MapSlice, bool) {
+	daysIndex, weekdaysIndex := -1, -1
+	for i, field := range fields {
+		switch key, _ := field.Key.(string); key {
+		case "days_of_month":
+			daysIndex = i
+		case "weekdays_of_month":
+			weekdaysIndex = i
+		}
+	}
+	if daysIndex < 0 {
+		return fields, false
+	}
+	list, ok := fields[daysIndex].Value.([]interface{})
+	if !ok {
+		return fields, false
+	}
+	var days, weekdays []interface{}
+	for _, v := range list {
+		if str, ok := v.(string); ok && isWeekdayOfMonth(str) {
+			weekdays = append(weekdays, v)
+		} else {
+			days = append(days, v)
+		}
+	}
+	if len(weekdays) == 0 {
+		return fields, false
+	}
+	if weekdaysIndex >= 0 {
+		existing, _ := fields[weekdaysIndex].Value.([]interface{})
+		fields[weekdaysIndex].Value = append(existing, weekdays...)
+	} else {
+		fields = append(fields, yaml.MapItem{Key: "weekdays_of_month", Value: weekdays})
+	}
+	if len(days) > 0 {
+		fields[daysIndex].Value = days
+		return fields, true
+	}
+	// Drop the field rather than leave it empty, since an empty list of days of the month would match nothing.
+	return append(fields[:daysIndex], fields[daysIndex+1:]...), true
+}
@@ -0,0 +1,156 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var weekdayOfMonthTestCases = []struct {
+	in          string
+	interval    TimeInterval
+	contains    []string
+	excludes    []string
+	expectError bool
+}{
+	{
+		// Patch Tuesday
+		in: "days_of_month: ['2nd tuesday']",
+		interval: TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 2, Weekday: time.Tuesday}},
+		},
+		contains: []string{"2020-07-14T12:00:00Z", "2020-09-08T00:00:00Z"},
+		excludes: []string{"2020-07-07T12:00:00Z", "2020-07-21T12:00:00Z", "2020-07-15T12:00:00Z"},
+	},
+	{
+		// Weekdays of the month are combined with ranges of days
+		in: "days_of_month: ['1st Mon', '15', 'fri#5']",
+		interval: TimeInterval{
+			DaysOfMonth:     []DayOfMonthRange{{InclusiveRange{Begin: 15, End: 15}}},
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 1, Weekday: time.Monday}, {N: 5, Weekday: time.Friday}},
+		},
+		contains: []string{"2020-07-06T12:00:00Z", "2020-07-15T12:00:00Z", "2020-07-31T12:00:00Z"},
+		excludes: []string{"2020-07-13T12:00:00Z", "2020-07-24T12:00:00Z"},
+	},
+	{
+		in: "{weekdays_of_month: ['3rd wednesday'], days_of_month: ['4th thursday']}",
+		interval: TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: 3, Weekday: time.Wednesday}, {N: 4, Weekday: time.Thursday}},
+		},
+		contains: []string{"2020-11-18T12:00:00Z", "2020-11-26T12:00:00Z"},
+		excludes: []string{"2020-11-19T12:00:00Z"},
+	},
+	{
+		// Last Friday of the month, in both forms
+		in: "days_of_month: ['last friday', '1L']",
+		interval: TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: -1, Weekday: time.Friday}, {N: -1, Weekday: time.Monday}},
+		},
+		contains: []string{"2020-07-31T12:00:00Z", "2020-07-27T12:00:00Z", "2020-02-28T12:00:00Z", "2020-08-31T12:00:00Z"},
+		excludes: []string{"2020-07-24T12:00:00Z", "2020-08-21T12:00:00Z", "2020-08-24T12:00:00Z"},
+	},
+	{
+		// Last weekday of the month falls back from the weekend
+		in: "days_of_month: ['last weekday']",
+		interval: TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: -1, AnyWeekday: true}},
+		},
+		contains: []string{"2020-10-30T12:00:00Z", "2020-07-31T12:00:00Z", "2020-08-31T12:00:00Z"},
+		excludes: []string{"2020-10-31T12:00:00Z", "2020-10-29T12:00:00Z", "2020-08-28T12:00:00Z"},
+	},
+	{
+		in: "days_of_month: ['LW', '1st weekday']",
+		interval: TimeInterval{
+			WeekdaysOfMonth: []WeekdayOfMonth{{N: -1, AnyWeekday: true}, {N: 1, AnyWeekday: true}},
+		},
+		contains: []string{"2020-08-03T12:00:00Z", "2020-07-01T12:00:00Z"},
+		excludes: []string{"2020-08-01T12:00:00Z", "2020-07-02T12:00:00Z"},
+	},
+	{
+		in:          "days_of_month: ['2th tuesday']",
+		expectError: true,
+	},
+	{
+		in:          "days_of_month: ['6th tuesday']",
+		expectError: true,
+	},
+	{
+		in:          "days_of_month: ['2nd funday']",
+		expectError: true,
+	},
+}
+
+func TestWeekdayOfMonth(t *testing.T) {
+	for _, tc := range weekdayOfMonthTestCases {
+		var ti TimeInterval
+		err := yaml.Unmarshal([]byte(tc.in), &ti)
+		if err != nil && !tc.expectError {
+			t.Errorf("Received unexpected error: %v when parsing %s", err, tc.in)
+			continue
+		} else if err == nil && tc.expectError {
+			t.Errorf("Expected error when parsing %s but didn't receive one", tc.in)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(ti, tc.interval) {
+			t.Errorf("Error parsing %s: want %+v, got %+v", tc.in, tc.interval, ti)
+		}
+		for _, ts := range tc.contains {
+			if !ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to contain %s", tc.in, ts)
+			}
+		}
+		for _, ts := range tc.excludes {
+			if ti.ContainsTime(mustParseTime(ts)) {
+				t.Errorf("Expected %s to exclude %s", tc.in, ts)
+			}
+		}
+		out, err := yaml.Marshal(ti)
+		if err != nil {
+			t.Error(err)
+		}
+		var ti2 TimeInterval
+		if err := yaml.Unmarshal(out, &ti2); err != nil || !reflect.DeepEqual(ti, ti2) {
+			t.Errorf("Re-marshalling %s produced a different TimeInterval: %s", tc.in, out)
+		}
+	}
+}
+
+func TestWeekdayOfMonthAnalysis(t *testing.T) {
+	every := TimeInterval{WeekdaysOfMonth: []WeekdayOfMonth{}}
+	for n := 1; n <= 5; n++ {
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			every.WeekdaysOfMonth = append(every.WeekdaysOfMonth, WeekdayOfMonth{N: n, Weekday: wd})
+		}
+	}
+	if !every.IsAlwaysActive() {
+		t.Errorf("Expected every occurrence of every weekday to always be active")
+	}
+	patchTuesday := TimeInterval{WeekdaysOfMonth: []WeekdayOfMonth{{N: 2, Weekday: time.Tuesday}}}
+	if patchTuesday.IsAlwaysActive() || patchTuesday.IsEmpty() {
+		t.Errorf("Expected the 2nd Tuesday to be neither always active nor empty")
+	}
+	mondayTuesday := TimeInterval{
+		Weekdays:        []WeekdayRange{{InclusiveRange{Begin: 1, End: 1}}},
+		WeekdaysOfMonth: []WeekdayOfMonth{{N: 2, Weekday: time.Tuesday}},
+	}
+	if !mondayTuesday.IsEmpty() {
+		t.Errorf("Expected a Monday that is the 2nd Tuesday to be empty")
+	}
+	if !(TimeInterval{WeekdaysOfMonth: []WeekdayOfMonth{}}).IsEmpty() {
+		t.Errorf("Expected an empty list of weekdays of the month to be empty")
+	}
+	if _, err := Intersect(patchTuesday, TimeInterval{DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: 1, End: 10}}}}); err != ErrNotRepresentable {
+		t.Errorf("Expected intersecting weekdays of the month with days of the month to be unrepresentable, got %v", err)
+	}
+	got, err := Intersect(patchTuesday, businessHours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.ContainsTime(mustParseTime("2020-07-14T10:00:00Z")) || got.ContainsTime(mustParseTime("2020-07-13T10:00:00Z")) {
+		t.Errorf("Unexpected intersection of the 2nd Tuesday with business hours: %+v", got)
+	}
+}
@@ -0,0 +1,272 @@
+package gotime
+
+import (
+	"sort"
+	"time"
+)
+
+// maxSearchYears bounds how far open ended searches for active windows will look before giving up. Any interval that is
+// active at all repeats within one Gregorian cycle.
+const maxSearchYears = gregorianCycleYears
+
+// A Window is a continuous period of time during which an interval is active, exclusive of the End time.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the window.
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// Contains returns true if the given time falls within the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Windows returns the windows during which the TimeInterval is active between from and to, in chronological order.
+// Windows that extend past either bound are clipped to it. Days are evaluated in the interval's location if it has
+// one, otherwise in the location of from.
+func (tp TimeInterval) Windows(from, to time.Time) []Window {
+	var windows []Window
+	tp.walkWindows(from, to, func(w Window) bool {
+		windows = append(windows, clipWindow(w, from, to))
+		return true
+	})
+	return windows
+}
+
+// walkWindows calls fn in chronological order for every active window that ends after from and starts before until.
+// Windows that span several days are merged into one, though a window already in progress at the start of the day
+// before from is reported as starting at midnight. Walking stops early if fn returns false.
+func (tp TimeInterval) walkWindows(from, until time.Time, fn func(Window) bool) {
+	if tp.Absolute != nil {
+		// Nothing outside the absolute ranges can be active.
+		first, last := absoluteRanges(tp.Absolute).bounds()
+		if first.After(from) {
+			from = first
+		}
+		if last.Before(until) {
+			until = last
+		}
+	}
+	if !from.Before(until) {
+		return
+	}
+	ranges := tp.mergedTimeRanges()
+	if len(ranges) == 0 && !tp.hasSolarTimes() {
+		return
+	}
+	lastYear, yearBounded := tp.lastYear()
+	years := TimeInterval{Years: tp.Years, FiscalYearStart: tp.FiscalYearStart}
+	months := TimeInterval{Months: tp.Months, Quarters: tp.Quarters, FiscalYearStart: tp.FiscalYearStart}
+
+	// Days are walked in the interval's location, but windows are reported in the caller's.
+	callerLocation := from.Location()
+	from, until = tp.inLocation(from), tp.inLocation(until)
+
+	var pending *Window
+	// emit reports a finished window to fn if it falls within the bounds, returning false if walking should stop.
+	emit := func(w Window) bool {
+		parts := tp.subtractExceptions(w)
+		if tp.Absolute != nil {
+			var clipped []Window
+			for _, part := range parts {
+				clipped = append(clipped, absoluteRanges(tp.Absolute).clip(part)...)
+			}
+			parts = clipped
+		}
+		for _, part := range parts {
+			if !part.End.After(from) {
+				continue
+			}
+			if !fn(Window{Start: part.Start.In(callerLocation), End: part.End.In(callerLocation)}) {
+				return false
+			}
+		}
+		return true
+	}
+	// Start from the day before so that overnight ranges carrying over into the first day are found.
+	day := time.Date(from.Year(), from.Month(), from.Day()-1, 0, 0, 0, 0, from.Location())
+	for day.Before(until) {
+		if yearBounded && day.Year() > lastYear {
+			break
+		}
+		if !years.containsDay(day) {
+			day = tp.yearStart(day).AddDate(1, 0, 0)
+			continue
+		}
+		if !months.containsDay(day) {
+			day = time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, day.Location())
+			continue
+		}
+		if pending != nil && pending.End.Before(day) {
+			if !emit(*pending) {
+				return
+			}
+			pending = nil
+		}
+		for _, w := range tp.dayWindows(day, ranges) {
+			if !w.Start.Before(until) {
+				break
+			}
+			if pending != nil && !w.Start.After(pending.End) {
+				if w.End.After(pending.End) {
+					pending.End = w.End
+				}
+				continue
+			}
+			if pending != nil && !emit(*pending) {
+				return
+			}
+			w := w
+			pending = &w
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+	}
+	if pending != nil {
+		emit(*pending)
+	}
+}
+
+// dayWindows returns the windows that begin on the day starting at the given midnight, in chronological order, given
+// the interval's merged time ranges. Intervals with solar times work out their own ranges for the day, and those with
+// an expression keep only the parts of the windows where it holds.
+func (tp TimeInterval) dayWindows(day time.Time, ranges []secondRange) []Window {
+	if !tp.containsDay(day) {
+		return nil
+	}
+	if e := tp.Expression; e != nil {
+		tp.Expression = nil
+		return e.clipWindows(tp.dayWindows(day, ranges))
+	}
+	if tp.hasSolarTimes() {
+		tp = tp.onDay(day)
+		ranges = tp.mergedTimeRanges()
+	}
+	if isClockChangeDay(day) && tp.Times != nil {
+		return tp.scanDayWindows(day)
+	}
+	windows := make([]Window, 0, len(ranges))
+	for _, tr := range ranges {
+		windows = append(windows, Window{
+			Start: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, tr.start, 0, day.Location()),
+			End:   time.Date(day.Year(), day.Month(), day.Day(), 0, 0, tr.end, 0, day.Location()),
+		})
+	}
+	return windows
+}
+
+// scanDayWindows finds the windows that begin on a day when the clocks change by checking every minute of the day, or
+// every second if the interval's times need it, so that they agree with ContainsTime whatever the interval's DST
+// policy.
+func (tp TimeInterval) scanDayWindows(day time.Time) []Window {
+	next := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+	step := time.Minute
+	for _, tr := range tp.Times {
+		if tr.hasSeconds() {
+			step = time.Second
+		}
+	}
+	var windows []Window
+	var current *Window
+	for t := day; t.Before(next); t = t.Add(step) {
+		if !tp.containsSameDayTime(t) {
+			current = nil
+			continue
+		}
+		if current == nil {
+			windows = append(windows, Window{Start: t})
+			current = &windows[len(windows)-1]
+		}
+		current.End = t.Add(step)
+	}
+	// Overnight ranges that are active at midnight continue into the following day.
+	for _, tr := range tp.Times {
+		if !tr.isOvernight() || len(windows) == 0 || !windows[len(windows)-1].End.Equal(next) {
+			continue
+		}
+		end := time.Date(next.Year(), next.Month(), next.Day(), 0, 0, tr.endSecond(), 0, next.Location())
+		if end.After(windows[len(windows)-1].End) {
+			windows[len(windows)-1].End = end
+		}
+	}
+	return windows
+}
+
+// A secondRange is a range of seconds measured from the start of a day, exclusive of the end second.
+type secondRange struct {
+	start int
+	end   int
+}
+
+// mergedTimeRanges returns the interval's valid time ranges in seconds, sorted by start, with overlapping and adjacent
+// ranges combined. An interval without times is active for the whole day. Overnight ranges are returned with an end
+// beyond the length of the day, measured from the start of the day they begin on.
+func (tp TimeInterval) mergedTimeRanges() []secondRange {
+	if tp.Times == nil {
+		return []secondRange{{start: 0, end: secondsPerDay}}
+	}
+	ranges := make([]secondRange, 0, len(tp.Times))
+	for _, tr := range tp.Times {
+		r := secondRange{start: tr.startSecond(), end: tr.endSecond()}
+		if tr.isOvernight() {
+			r.end += secondsPerDay
+		}
+		if r.start < r.end {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start < ranges[j].start
+	})
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if len(merged) > 0 && r.start <= merged[len(merged)-1].end {
+			if r.end > merged[len(merged)-1].end {
+				merged[len(merged)-1].end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// lastYear returns the final year the interval can be active in, or false if it is not bounded by years or dates.
+func (tp TimeInterval) lastYear() (int, bool) {
+	last, bounded := 0, false
+	for i, yr := range tp.Years {
+		if i == 0 || yr.End > last {
+			last = yr.End
+		}
+	}
+	if tp.Years != nil {
+		bounded = true
+	}
+	if tp.Dates != nil {
+		lastDate := 0
+		for i, r := range tp.Dates {
+			if i == 0 || r.End.Year() > lastDate {
+				lastDate = r.End.Year()
+			}
+		}
+		if !bounded || lastDate < last {
+			last = lastDate
+		}
+		bounded = true
+	}
+	return last, bounded
+}
+
+// clipWindow restricts a window to the given bounds.
+func clipWindow(w Window, from, to time.Time) Window {
+	if w.Start.Before(from) {
+		w.Start = from
+	}
+	if w.End.After(to) {
+		w.End = to
+	}
+	return w
+}
@@ -0,0 +1,132 @@
+package gotime
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var businessHours = TimeInterval{
+	Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+	Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+}
+
+func mustParseTime(ts string) time.Time {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+var windowsTestCases = []struct {
+	timeInterval TimeInterval
+	from         string
+	to           string
+	windows      [][2]string
+}{
+	{
+		// Business hours over a weekend, clipped at both ends
+		timeInterval: businessHours,
+		from:         "2020-07-10T12:00:00Z",
+		to:           "2020-07-13T10:00:00Z",
+		windows: [][2]string{
+			{"2020-07-10T12:00:00Z", "2020-07-10T17:00:00Z"},
+			{"2020-07-13T09:00:00Z", "2020-07-13T10:00:00Z"},
+		},
+	},
+	{
+		// Whole days are merged across midnight
+		timeInterval: TimeInterval{
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange{Begin: 6, End: 6}}},
+		},
+		from: "2020-07-08T00:00:00Z",
+		to:   "2020-07-20T00:00:00Z",
+		windows: [][2]string{
+			{"2020-07-11T00:00:00Z", "2020-07-13T00:00:00Z"},
+			{"2020-07-18T00:00:00Z", "2020-07-20T00:00:00Z"},
+		},
+	},
+	{
+		// Windows start and end part way through a minute
+		timeInterval: TimeInterval{
+			Times: []TimeRange{{StartMinute: 540, StartSecond: 30, EndMinute: 541, EndSecond: 45}},
+		},
+		from: "2020-07-08T00:00:00Z",
+		to:   "2020-07-09T00:00:00Z",
+		windows: [][2]string{
+			{"2020-07-08T09:00:30Z", "2020-07-08T09:01:45Z"},
+		},
+	},
+	{
+		// Overlapping and adjacent time ranges are merged
+		timeInterval: TimeInterval{
+			Times: []TimeRange{{StartMinute: 600, EndMinute: 660}, {StartMinute: 540, EndMinute: 620}, {StartMinute: 660, EndMinute: 720}},
+		},
+		from: "2020-07-08T00:00:00Z",
+		to:   "2020-07-09T00:00:00Z",
+		windows: [][2]string{
+			{"2020-07-08T09:00:00Z", "2020-07-08T12:00:00Z"},
+		},
+	},
+	{
+		// Years outside of the range are skipped
+		timeInterval: TimeInterval{
+			Months:      []MonthRange{{InclusiveRange{Begin: 2, End: 2}}},
+			DaysOfMonth: []DayOfMonthRange{{InclusiveRange{Begin: -1, End: -1}}},
+			Years:       []YearRange{{InclusiveRange{Begin: 2020, End: 2021}}},
+		},
+		from: "2019-01-01T00:00:00Z",
+		to:   "2030-01-01T00:00:00Z",
+		windows: [][2]string{
+			{"2020-02-29T00:00:00Z", "2020-03-01T00:00:00Z"},
+			{"2021-02-28T00:00:00Z", "2021-03-01T00:00:00Z"},
+		},
+	},
+	{
+		// Overnight ranges carry over from the day before from
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 1320, EndMinute: 360}, {StartMinute: 60, EndMinute: 120}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 5, End: 6}}},
+		},
+		from: "2020-07-11T03:00:00Z",
+		to:   "2020-07-13T00:00:00Z",
+		windows: [][2]string{
+			{"2020-07-11T03:00:00Z", "2020-07-11T06:00:00Z"},
+			{"2020-07-11T22:00:00Z", "2020-07-12T06:00:00Z"},
+		},
+	},
+	{
+		// Business hours in Melbourne are reported in the caller's location
+		timeInterval: TimeInterval{
+			Times:    []TimeRange{{StartMinute: 540, EndMinute: 1020}},
+			Weekdays: []WeekdayRange{{InclusiveRange{Begin: 1, End: 5}}},
+			Location: mustLoadLocation("Australia/Melbourne"),
+		},
+		from: "2020-07-10T00:00:00Z",
+		to:   "2020-07-13T00:00:00Z",
+		windows: [][2]string{
+			{"2020-07-10T00:00:00Z", "2020-07-10T07:00:00Z"},
+			{"2020-07-12T23:00:00Z", "2020-07-13T00:00:00Z"},
+		},
+	},
+	{
+		timeInterval: emptyInterval(),
+		from:         "2020-01-01T00:00:00Z",
+		to:           "2021-01-01T00:00:00Z",
+		windows:      nil,
+	},
+}
+
+func TestWindows(t *testing.T) {
+	for _, tc := range windowsTestCases {
+		var want []Window
+		for _, w := range tc.windows {
+			want = append(want, Window{Start: mustParseTime(w[0]), End: mustParseTime(w[1])})
+		}
+		got := tc.timeInterval.Windows(mustParseTime(tc.from), mustParseTime(tc.to))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Windows for %+v between %s and %s: want %v, got %v", tc.timeInterval, tc.from, tc.to, want, got)
+		}
+	}
+}